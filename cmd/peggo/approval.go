@@ -0,0 +1,135 @@
+package peggo
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/knadh/koanf"
+
+	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
+)
+
+const (
+	// approvalModeExact approves exactly the amount being sent.
+	approvalModeExact = "exact"
+	// approvalModeInfinite approves max uint256 once, so later sends don't need
+	// an approval.
+	approvalModeInfinite = "infinite"
+
+	approvalMinedTimeout = 5 * time.Minute
+)
+
+func validateApprovalMode(mode string) error {
+	switch mode {
+	case approvalModeExact, approvalModeInfinite:
+		return nil
+
+	default:
+		return fmt.Errorf(
+			"invalid approval mode: %s; expected one of: %s",
+			mode, strings.Join([]string{approvalModeExact, approvalModeInfinite}, ", "),
+		)
+	}
+}
+
+// approveERC20 makes sure Gravity is allowed to spend amount of the ERC20 token
+// on behalf of the sender, using the given approval mode. Nothing is done if
+// the current allowance already covers the amount.
+func approveERC20(
+	konfig *koanf.Koanf,
+	ethRPC *ethclient.Client,
+	erc20Addr, gravityAddr ethcmn.Address,
+	amount *big.Int,
+	mode string,
+) error {
+	contract, err := wrappers.NewERC20(erc20Addr, ethRPC)
+	if err != nil {
+		return fmt.Errorf("failed to create ERC20 contract instance: %w", err)
+	}
+
+	privKey, err := parseEthPrivKey(konfig)
+	if err != nil {
+		return err
+	}
+
+	owner := ethcrypto.PubkeyToAddress(privKey.PublicKey)
+
+	allowance, err := contract.Allowance(nil, owner, gravityAddr)
+	if err != nil {
+		return fmt.Errorf("failed to get ERC20 allowance: %w", err)
+	}
+
+	if allowance.Cmp(amount) >= 0 {
+		_, _ = fmt.Fprintln(os.Stderr, "Skipping ERC20 contract approval; allowance is sufficient")
+		return nil
+	}
+
+	if mode == approvalModeInfinite {
+		return resetAndApprove(konfig, ethRPC, contract, gravityAddr, allowance, maxUint256)
+	}
+
+	return resetAndApprove(konfig, ethRPC, contract, gravityAddr, allowance, amount)
+}
+
+// resetAndApprove approves amount, resetting the current allowance to zero
+// first, since some tokens (e.g. USDT) refuse to change a non-zero allowance to
+// another non-zero value.
+func resetAndApprove(
+	konfig *koanf.Koanf,
+	ethRPC *ethclient.Client,
+	contract *wrappers.ERC20,
+	spender ethcmn.Address,
+	allowance, amount *big.Int,
+) error {
+	if allowance.Sign() > 0 {
+		if err := sendApproval(konfig, ethRPC, contract, spender, big.NewInt(0)); err != nil {
+			return err
+		}
+	}
+
+	return sendApproval(konfig, ethRPC, contract, spender, amount)
+}
+
+// sendApproval sends an ERC20 approve transaction and waits for it to be mined,
+// so the allowance is in place for the transaction that follows.
+func sendApproval(
+	konfig *koanf.Koanf,
+	ethRPC *ethclient.Client,
+	contract *wrappers.ERC20,
+	spender ethcmn.Address,
+	amount *big.Int,
+) error {
+	auth, err := buildTransactOpts(konfig, ethRPC)
+	if err != nil {
+		return err
+	}
+
+	tx, err := contract.Approve(auth, spender, amount)
+	if err != nil {
+		return fmt.Errorf("failed to approve ERC20 contract: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "Approving ERC20 contract for %s: %s\n", amount.String(), tx.Hash().Hex())
+
+	ctx, cancel := context.WithTimeout(context.Background(), approvalMinedTimeout)
+	defer cancel()
+
+	receipt, err := bind.WaitMined(ctx, ethRPC, tx)
+	if err != nil {
+		return fmt.Errorf("failed to wait for ERC20 approval to be mined: %w", err)
+	}
+
+	if receipt.Status != 1 {
+		return fmt.Errorf("ERC20 approval transaction failed: %s", tx.Hash().Hex())
+	}
+
+	return nil
+}
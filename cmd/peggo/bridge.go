@@ -4,7 +4,6 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"fmt"
-	"math"
 	"math/big"
 	"os"
 	"strconv"
@@ -24,14 +23,14 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/umee-network/peggo/cmd/peggo/client"
+	"github.com/umee-network/peggo/orchestrator/ethereum/verifier"
 	"github.com/umee-network/peggo/orchestrator/relayer"
 	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
 )
 
 var (
 	//nolint: lll
-	maxUint256     = new(big.Int).SetBytes(ethcmn.Hex2Bytes("ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"))
-	halfMaxUint256 = new(big.Int).Div(maxUint256, big.NewInt(2))
+	maxUint256 = new(big.Int).SetBytes(ethcmn.Hex2Bytes("ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"))
 )
 
 func getBridgeCommand() *cobra.Command {
@@ -48,6 +47,7 @@ func getBridgeCommand() *cobra.Command {
 		deployERC20Cmd(),
 		deployERC20RawCmd(),
 		sendToCosmosCmd(),
+		submitCmd(),
 	)
 
 	return cmd
@@ -167,6 +167,11 @@ func deployGravityCmd() *cobra.Command {
 			var gravityIDBytes32 [32]uint8
 			copy(gravityIDBytes32[:], gravityIDBytes)
 
+			contractVerifier, err := newDeploymentVerifier(konfig, ethRPC)
+			if err != nil {
+				return err
+			}
+
 			address, tx, _, err := wrappers.DeployGravity(auth, ethRPC, gravityIDBytes32, validators, powers)
 			if err != nil {
 				return fmt.Errorf("failed deploy Gravity Bridge contract: %w", err)
@@ -187,18 +192,50 @@ Transaction: %s
 				tx.Hash().Hex(),
 			)
 
-			return nil
+			if contractVerifier == nil {
+				return printGravityDeployed(konfig, tx.Hash(), address)
+			}
+
+			ctx, cancel = context.WithTimeout(context.Background(), konfig.Duration(flagReceiptTimeout))
+			defer cancel()
+
+			if _, err := bind.WaitDeployed(ctx, ethRPC, tx); err != nil {
+				return fmt.Errorf("failed to wait for the Gravity Bridge contract deployment: %w", err)
+			}
+
+			if err := printGravityDeployed(konfig, tx.Hash(), address); err != nil {
+				return err
+			}
+
+			// the transaction data is the creation bytecode followed by the constructor arguments
+			constructorArgs := tx.Data()[len(ethcmn.FromHex(wrappers.GravityMetaData.Bin)):]
+
+			return contractVerifier.verify(
+				address,
+				wrappers.GravityMetaData.Bin,
+				verifier.GravityContractName,
+				constructorArgs,
+			)
 		},
 	}
 
+	cmd.Flags().Duration(flagReceiptTimeout, 5*time.Minute, "Maximum time to wait for the deployment receipt")
+	cmd.Flags().AddFlagSet(verifyFlagSet())
+
 	return cmd
 }
 
 func deployERC20Cmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "deploy-erc20 [gravity-addr] [denom-base]",
-		Args:  cobra.ExactArgs(2),
+		Args:  cobra.RangeArgs(1, 2),
 		Short: "Deploy a Cosmos native asset on Ethereum as an ERC20 token",
+		Long: `Deploy a Cosmos native asset on Ethereum as an ERC20 token.
+The token name, symbol and decimals are read from the bank metadata of the denom.
+
+With --denoms-file, every denom listed in the file (a JSON array of denoms, or a
+CSV file with a denom in the first column) that isn't registered as an ERC20 yet
+is deployed sequentially, waiting for each receipt, and a summary is printed.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			konfig, err := parseServerConfig(cmd)
 			if err != nil {
@@ -273,41 +310,43 @@ func deployERC20Cmd() *cobra.Command {
 				return err
 			}
 
-			baseDenom := args[1]
 			bankQuerier := banktypes.NewQueryClient(gRPCConn)
 
-			ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
-			defer cancel()
-
-			resp, err := bankQuerier.DenomMetadata(ctx, &banktypes.QueryDenomMetadataRequest{Denom: baseDenom})
+			contractVerifier, err := newDeploymentVerifier(konfig, ethRPC)
 			if err != nil {
-				return fmt.Errorf("failed to query for bank metadata: %w", err)
+				return err
 			}
 
-			switch {
-			case len(resp.Metadata.Name) == 0:
-				return errors.New("token metadata name cannot be empty")
-
-			case len(resp.Metadata.Symbol) == 0:
-				return errors.New("token metadata symbol cannot be empty")
+			if denomsFile := konfig.String(flagDenomsFile); denomsFile != "" {
+				denoms, err := readDenomsFile(denomsFile)
+				if err != nil {
+					return err
+				}
 
-			case len(resp.Metadata.Display) == 0:
-				return errors.New("token metadata display cannot be empty")
+				return deployERC20s(deployERC20sConfig{
+					ethRPC:          ethRPC,
+					auth:            auth,
+					gravityAddr:     gravityAddr,
+					gravityContract: gravityContract,
+					verifier:        contractVerifier,
+					bankQuerier:     bankQuerier,
+					gravityQuerier:  gravitytypes.NewQueryClient(gRPCConn),
+					receiptTimeout:  konfig.Duration(flagReceiptTimeout),
+					jsonOutput:      isJSONOutput(konfig),
+				}, denoms)
 			}
 
-			var decimals uint8
-			for _, unit := range resp.Metadata.DenomUnits {
-				if unit.Denom == resp.Metadata.Display {
-					if unit.Exponent > math.MaxUint8 {
-						return fmt.Errorf("token exponent too large; %d > %d", unit.Exponent, math.MaxInt8)
-					}
+			if len(args) != 2 {
+				return errors.New("a base denom must be provided unless --denoms-file is set")
+			}
 
-					decimals = uint8(unit.Exponent)
-					break
-				}
+			baseDenom := args[1]
+			metadata, err := queryERC20Metadata(bankQuerier, baseDenom)
+			if err != nil {
+				return err
 			}
 
-			tx, err := gravityContract.DeployERC20(auth, baseDenom, resp.Metadata.Name, resp.Metadata.Symbol, decimals)
+			tx, err := gravityContract.DeployERC20(auth, baseDenom, metadata.name, metadata.symbol, metadata.decimals)
 			if err != nil {
 				return fmt.Errorf("failed deploy Cosmos native ERC20 token: %w", err)
 			}
@@ -320,21 +359,43 @@ Decimals: %d
 Transaction: %s
 `,
 				baseDenom,
-				resp.Metadata.Name,
-				resp.Metadata.Symbol,
-				decimals,
+				metadata.name,
+				metadata.symbol,
+				metadata.decimals,
 				tx.Hash().Hex(),
 			)
 
-			return nil
+			if contractVerifier == nil {
+				return printERC20Deployed(konfig, tx.Hash(), baseDenom, "")
+			}
+
+			ctx, cancel = context.WithTimeout(context.Background(), konfig.Duration(flagReceiptTimeout))
+			defer cancel()
+
+			erc20Addr, err := waitERC20Deployed(ctx, ethRPC, gravityContract, tx, baseDenom)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "ERC20: %s\n", erc20Addr.Hex())
+
+			if err := printERC20Deployed(konfig, tx.Hash(), baseDenom, erc20Addr.Hex()); err != nil {
+				return err
+			}
+
+			return contractVerifier.verifyERC20(gravityAddr, erc20Addr, metadata)
 		},
 	}
 
+	cmd.Flags().String(flagDenomsFile, "", "Deploy the ERC20 tokens of the denoms listed in a JSON or CSV file")
+	cmd.Flags().Duration(flagReceiptTimeout, 5*time.Minute, "Maximum time to wait for each deployment receipt")
+	cmd.Flags().AddFlagSet(verifyFlagSet())
+
 	return cmd
 }
 
 func deployERC20RawCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "deploy-erc20-raw [gravity-addr] [denom-base] [denom-name] [denom-symbol] [denom-decimals]",
 		Short: "Deploy a Cosmos native asset on Ethereum as an ERC20 token using raw input",
 		Long: `Deploy a Cosmos native asset on Ethereum as an ERC20 token using raw input.
@@ -378,6 +439,11 @@ network starting.`,
 				return fmt.Errorf("invalid denom decimals: %w", err)
 			}
 
+			contractVerifier, err := newDeploymentVerifier(konfig, ethRPC)
+			if err != nil {
+				return err
+			}
+
 			tx, err := gravityContract.DeployERC20(auth, denomBase, denomName, denomSymbol, uint8(denomDecimals))
 			if err != nil {
 				return fmt.Errorf("failed deploy Cosmos native ERC20 token: %w", err)
@@ -397,9 +463,57 @@ Transaction: %s
 				tx.Hash().Hex(),
 			)
 
-			return nil
+			if contractVerifier == nil {
+				return printERC20Deployed(konfig, tx.Hash(), denomBase, "")
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), konfig.Duration(flagReceiptTimeout))
+			defer cancel()
+
+			erc20Addr, err := waitERC20Deployed(ctx, ethRPC, gravityContract, tx, denomBase)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "ERC20: %s\n", erc20Addr.Hex())
+
+			if err := printERC20Deployed(konfig, tx.Hash(), denomBase, erc20Addr.Hex()); err != nil {
+				return err
+			}
+
+			return contractVerifier.verifyERC20(gravityAddr, erc20Addr, erc20Metadata{
+				name:     denomName,
+				symbol:   denomSymbol,
+				decimals: uint8(denomDecimals),
+			})
 		},
 	}
+
+	cmd.Flags().Duration(flagReceiptTimeout, 5*time.Minute, "Maximum time to wait for the deployment receipt")
+	cmd.Flags().AddFlagSet(verifyFlagSet())
+
+	return cmd
+}
+
+// printGravityDeployed prints the deployment of the Gravity contract with
+// --output=json.
+func printGravityDeployed(konfig *koanf.Koanf, txHash ethcmn.Hash, address ethcmn.Address) error {
+	if !isJSONOutput(konfig) {
+		return nil
+	}
+
+	return printJSON(txResult{Type: "deploy-gravity", TxHash: txHash.Hex(), Address: address.Hex()})
+}
+
+// printERC20Deployed prints the deployment of an ERC20 with --output=json. The
+// ERC20 address is only known once the tx is mined, hence left empty without
+// --verify.
+func printERC20Deployed(konfig *koanf.Koanf, txHash ethcmn.Hash, denom, erc20Addr string) error {
+	if !isJSONOutput(konfig) {
+		return nil
+	}
+
+	return printJSON(txResult{Type: "deploy-erc20", TxHash: txHash.Hex(), Denom: denom, Address: erc20Addr})
 }
 
 func sendToCosmosCmd() *cobra.Command {
@@ -435,17 +549,6 @@ func sendToCosmosCmd() *cobra.Command {
 
 			tokenAddr := ethcmn.HexToAddress(args[1])
 
-			if konfig.Bool(flagAutoApprove) {
-				if err := approveERC20(konfig, ethRPC, tokenAddr, gravityAddr); err != nil {
-					return err
-				}
-			}
-
-			auth, err := buildTransactOpts(konfig, ethRPC)
-			if err != nil {
-				return err
-			}
-
 			recipientAddr, err := sdk.AccAddressFromBech32(args[2])
 			if err != nil {
 				return fmt.Errorf("failed to Bech32 decode recipient address: %w", err)
@@ -456,6 +559,22 @@ func sendToCosmosCmd() *cobra.Command {
 				return fmt.Errorf("invalid token amount: %s", args[3])
 			}
 
+			if konfig.Bool(flagAutoApprove) {
+				approvalMode := konfig.String(flagApprovalMode)
+				if err := validateApprovalMode(approvalMode); err != nil {
+					return err
+				}
+
+				if err := approveERC20(konfig, ethRPC, tokenAddr, gravityAddr, amount, approvalMode); err != nil {
+					return err
+				}
+			}
+
+			auth, err := buildTransactOpts(konfig, ethRPC)
+			if err != nil {
+				return err
+			}
+
 			tx, err := gravityContract.SendToCosmos(auth, tokenAddr, recipientAddr.String(), amount)
 			if err != nil {
 				return fmt.Errorf("failed to send tokens to Cosmos: %w", err)
@@ -475,21 +594,31 @@ Transaction: %s
 				tx.Hash().Hex(),
 			)
 
+			if isJSONOutput(konfig) {
+				return printJSON(txResult{
+					Type:      "send-to-cosmos",
+					TxHash:    tx.Hash().Hex(),
+					Sender:    auth.From.Hex(),
+					Recipient: recipientAddr.String(),
+					Token:     tokenAddr.Hex(),
+					Amount:    amount.String(),
+				})
+			}
+
 			return nil
 		},
 	}
 
-	cmd.Flags().Bool(flagAutoApprove, true, "Auto approve the ERC20 for Gravity to spend from (using max uint256)")
+	cmd.Flags().Bool(flagAutoApprove, true, "Auto approve the ERC20 for Gravity to spend from if the allowance is too low")
+	cmd.Flags().String(flagApprovalMode, approvalModeExact, "Specify how the ERC20 is approved (exact|infinite)")
 
 	return cmd
 }
 
 func buildTransactOpts(konfig *koanf.Koanf, ethClient *ethclient.Client) (*bind.TransactOpts, error) {
-	ethPrivKeyHexStr := konfig.String(flagEthPK)
-
-	privKey, err := ethcrypto.ToECDSA(ethcmn.FromHex(ethPrivKeyHexStr))
+	privKey, err := parseEthPrivKey(konfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode private key: %w", err)
+		return nil, err
 	}
 
 	publicKey := privKey.Public()
@@ -551,6 +680,25 @@ func buildTransactOpts(konfig *koanf.Koanf, ethClient *ethclient.Client) (*bind.
 	return auth, nil
 }
 
+// parseEthPrivKey parses the Ethereum private key, and zeroes its raw bytes
+// once parsed.
+func parseEthPrivKey(konfig *koanf.Koanf) (*ecdsa.PrivateKey, error) {
+	pkHex := secretValue(konfig, flagEthPK)
+	pkBz, err := decodeHexSecret(pkHex)
+	zeroBytes(pkHex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode private key: %w", err)
+	}
+	defer zeroBytes(pkBz)
+
+	privKey, err := ethcrypto.ToECDSA(pkBz)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode private key: %w", err)
+	}
+
+	return privKey, nil
+}
+
 func getGravityParams(gRPCConn *grpc.ClientConn) (*gravitytypes.Params, error) {
 	gravityQueryClient := gravitytypes.NewQueryClient(gRPCConn)
 
@@ -573,36 +721,3 @@ func getGravityContract(ethRPC *ethclient.Client, gravityAddr ethcmn.Address) (*
 
 	return contract, nil
 }
-
-func approveERC20(konfig *koanf.Koanf, ethRPC *ethclient.Client, erc20Addr, gravityAddr ethcmn.Address) error {
-	contract, err := wrappers.NewERC20(erc20Addr, ethRPC)
-	if err != nil {
-		return fmt.Errorf("failed to create ERC20 contract instance: %w", err)
-	}
-
-	auth, err := buildTransactOpts(konfig, ethRPC)
-	if err != nil {
-		return err
-	}
-
-	// Check if the allowance remaining is greater than half of a Uint256 - it's
-	// as good a test as any. If so, we skip approving Gravity as the spender and
-	// assume it's already approved.
-	allowance, err := contract.Allowance(nil, auth.From, gravityAddr)
-	if err != nil {
-		return fmt.Errorf("failed to get ERC20 allowance: %w", err)
-	}
-
-	if allowance.Cmp(halfMaxUint256) > 0 {
-		_, _ = fmt.Fprintln(os.Stderr, "Skipping ERC20 contract approval")
-		return nil
-	}
-
-	tx, err := contract.Approve(auth, gravityAddr, maxUint256)
-	if err != nil {
-		return fmt.Errorf("failed to approve ERC20 contract: %w", err)
-	}
-
-	_, _ = fmt.Fprintf(os.Stderr, "Approved ERC20 contract: %s\n", tx.Hash().Hex())
-	return nil
-}
@@ -12,10 +12,16 @@ import (
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/tx"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/umee-network/peggo/orchestrator/breaker"
+	"github.com/umee-network/peggo/orchestrator/safemode"
 )
 
 type CosmosClient interface {
@@ -24,6 +30,8 @@ type CosmosClient interface {
 	QueryClient() *grpc.ClientConn
 	SyncBroadcastMsg(msgs ...sdk.Msg) (*sdk.TxResponse, error)
 	AsyncBroadcastMsg(msgs ...sdk.Msg) (*sdk.TxResponse, error)
+	BroadcastMsgWithGas(gas uint64, msgs ...sdk.Msg) (*sdk.TxResponse, error)
+	WaitTx(ctx context.Context, txHash string) (*sdk.TxResponse, error)
 	QueueBroadcastMsg(msgs ...sdk.Msg) error
 	ClientContext() client.Context
 	Close()
@@ -37,24 +45,28 @@ func NewCosmosClient(
 	protoAddr string,
 	options ...CosmosClientOption,
 ) (CosmosClient, error) {
+	opts := defaultCosmosClientOptions()
+	for _, opt := range options {
+		if err := opt(opts); err != nil {
+			err = errors.Wrap(err, "error in a cosmos client option")
+			return nil, err
+		}
+	}
+
 	conn, err := grpc.Dial(
 		protoAddr,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithContextDialer(dialerFunc),
+		grpc.WithChainUnaryInterceptor(
+			circuitBreakerInterceptor(opts.CircuitBreaker),
+			queryTimeoutInterceptor(opts.QueryTimeout),
+		),
 	)
 	if err != nil {
 		err := errors.Wrapf(err, "failed to connect to the gRPC: %s", protoAddr)
 		return nil, err
 	}
 
-	opts := defaultCosmosClientOptions()
-	for _, opt := range options {
-		if err := opt(opts); err != nil {
-			err = errors.Wrap(err, "error in a cosmos client option")
-			return nil, err
-		}
-	}
-
 	txFactory := NewTxFactory(ctx)
 	if len(opts.GasPrices) > 0 {
 		txFactory = txFactory.WithGasPrices(opts.GasPrices)
@@ -90,11 +102,18 @@ func NewCosmosClient(
 }
 
 type cosmosClientOptions struct {
-	GasPrices string
+	GasPrices        string
+	QueryTimeout     time.Duration
+	BroadcastTimeout time.Duration
+	CircuitBreaker   *breaker.Breaker
+	Journal          *safemode.Journal
+	FeeRecorder      FeeRecorder
 }
 
 func defaultCosmosClientOptions() *cosmosClientOptions {
-	return &cosmosClientOptions{}
+	return &cosmosClientOptions{
+		BroadcastTimeout: defaultBroadcastTimeout,
+	}
 }
 
 type CosmosClientOption func(opts *cosmosClientOptions) error
@@ -112,6 +131,114 @@ func OptionGasPrices(gasPrices string) CosmosClientOption {
 	}
 }
 
+// OptionQueryTimeout bounds every gRPC query made through the client. Zero
+// means no timeout.
+func OptionQueryTimeout(timeout time.Duration) CosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		if timeout < 0 {
+			return errors.Errorf("invalid query timeout: %s", timeout)
+		}
+
+		opts.QueryTimeout = timeout
+		return nil
+	}
+}
+
+// OptionBroadcastTimeout sets how long to wait for a broadcasted tx to be
+// included in a block.
+func OptionBroadcastTimeout(timeout time.Duration) CosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		if timeout <= 0 {
+			return errors.Errorf("invalid broadcast timeout: %s", timeout)
+		}
+
+		opts.BroadcastTimeout = timeout
+		return nil
+	}
+}
+
+// OptionCircuitBreaker sends every gRPC query made through the client through
+// the given circuit breaker.
+func OptionCircuitBreaker(b *breaker.Breaker) CosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		opts.CircuitBreaker = b
+		return nil
+	}
+}
+
+// OptionTxJournal records the broadcasted txs in the given journal until
+// they're seen included in a block, so they can be resolved on startup after a
+// crash.
+func OptionTxJournal(journal *safemode.Journal) CosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		opts.Journal = journal
+		return nil
+	}
+}
+
+// FeeRecorder records the fees paid by the txs broadcasted by the client.
+type FeeRecorder interface {
+	AddCosmosFees(fees sdk.Coins)
+}
+
+// OptionFeeRecorder records the fees of every tx accepted by the node. The
+// fees are charged as soon as the tx is included in a block, even if its
+// messages fail, so they're recorded without waiting for it.
+func OptionFeeRecorder(recorder FeeRecorder) CosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		opts.FeeRecorder = recorder
+		return nil
+	}
+}
+
+// circuitBreakerInterceptor fails gRPC calls fast while the breaker is open.
+// Only errors meaning the node could not answer count as failures.
+func circuitBreakerInterceptor(b *breaker.Breaker) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		return b.Do(func() error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}, isEndpointFailure)
+	}
+}
+
+func isEndpointFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+
+	default:
+		return false
+	}
+}
+
+// queryTimeoutInterceptor sets a deadline on gRPC calls. A shorter deadline
+// already set on the context is always respected.
+func queryTimeoutInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
 func (c *cosmosClient) syncNonce() {
 	num, seq, err := c.txFactory.AccountRetriever().GetAccountNumberSequence(c.ctx, c.ctx.GetFromAddress())
 	if err != nil {
@@ -229,6 +356,78 @@ func (c *cosmosClient) AsyncBroadcastMsg(msgs ...sdk.Msg) (*sdk.TxResponse, erro
 	return res, nil
 }
 
+// BroadcastMsgWithGas sends Tx to chain with the given gas limit instead of
+// simulating it, and only waits until it passed CheckTx. This allows to send
+// several txs in a row when each one can only be simulated once the previous
+// ones are executed (e.g. claims). Use WaitTx to wait for its execution.
+func (c *cosmosClient) BroadcastMsgWithGas(gas uint64, msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+	c.syncMux.Lock()
+	defer c.syncMux.Unlock()
+
+	txf := c.txFactory.
+		WithSequence(c.accSeq).
+		WithAccountNumber(c.accNum).
+		WithSimulateAndExecute(false).
+		WithGas(gas)
+
+	res, err := c.broadcastTx(c.ctx, txf, false, msgs...)
+	if err == nil && res.Code != 0 {
+		err = errors.Wrapf(sdkerrors.ABCIError(res.Codespace, res.Code, res.RawLog), "tx %s rejected", res.TxHash)
+	}
+	if err != nil {
+		// The txs sent before may still be pending, so the tx isn't retried.
+		if strings.Contains(err.Error(), "account sequence mismatch") {
+			c.syncNonce()
+		}
+
+		c.logger.Err(err).Int("size", len(msgs)).Uint64("gas", gas).Msg("failed to broadcast tx with gas")
+		return nil, err
+	}
+
+	c.accSeq++
+
+	return res, nil
+}
+
+// WaitTx waits until the tx with the given hash is included in a block and
+// returns an error if its execution failed.
+func (c *cosmosClient) WaitTx(ctx context.Context, txHash string) (*sdk.TxResponse, error) {
+	hash, err := hex.DecodeString(txHash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid tx hash %s", txHash)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.opts.BroadcastTimeout)
+	defer cancel()
+
+	t := time.NewTicker(defaultBroadcastStatusPoll)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrapf(ErrTimedOut, "%s", txHash)
+
+		case <-t.C:
+			// The tx is not found until it's included in a block.
+			resultTx, err := c.ctx.Client.Tx(ctx, hash, false)
+			if err != nil || resultTx.Height == 0 {
+				continue
+			}
+
+			c.untrackJournalTx(txHash)
+
+			res := sdk.NewResponseResultTx(resultTx, nil, "")
+			if res.Code != 0 {
+				err := sdkerrors.ABCIError(res.Codespace, res.Code, res.RawLog)
+				return res, errors.Wrapf(err, "tx %s failed", txHash)
+			}
+
+			return res, nil
+		}
+	}
+}
+
 const (
 	defaultBroadcastStatusPoll = 100 * time.Millisecond
 	defaultBroadcastTimeout    = 60 * time.Second
@@ -277,11 +476,27 @@ func (c *cosmosClient) broadcastTx(
 	}
 
 	res, err := clientCtx.BroadcastTxSync(txBytes)
+	if err == nil && res.Code == 0 {
+		if c.opts.Journal != nil {
+			c.opts.Journal.Add(safemode.Tx{Chain: safemode.ChainCosmos, Hash: res.TxHash, Nonce: txf.Sequence()})
+		}
+		if c.opts.FeeRecorder != nil {
+			c.opts.FeeRecorder.AddCosmosFees(txn.GetTx().GetFee())
+		}
+	}
+
 	if !await || err != nil {
 		return res, err
 	}
 
-	awaitCtx, cancelFn := context.WithTimeout(context.Background(), defaultBroadcastTimeout)
+	// A tx rejected by CheckTx never makes it into a block, so there's nothing
+	// to wait for.
+	if res.Code != 0 {
+		err = errors.Wrapf(sdkerrors.ABCIError(res.Codespace, res.Code, res.RawLog), "tx %s rejected", res.TxHash)
+		return res, err
+	}
+
+	awaitCtx, cancelFn := context.WithTimeout(context.Background(), c.opts.BroadcastTimeout)
 	defer cancelFn()
 
 	txHash, _ := hex.DecodeString(res.TxHash)
@@ -310,6 +525,7 @@ func (c *cosmosClient) broadcastTx(
 				continue
 
 			} else if resultTx.Height > 0 {
+				c.untrackJournalTx(res.TxHash)
 				res = sdk.NewResponseResultTx(resultTx, res.Tx, res.Timestamp)
 				t.Stop()
 				return res, err
@@ -322,6 +538,12 @@ func (c *cosmosClient) broadcastTx(
 
 var ErrTimedOut = errors.New("tx timed out")
 
+func (c *cosmosClient) untrackJournalTx(txHash string) {
+	if c.opts.Journal != nil {
+		c.opts.Journal.Remove(safemode.ChainCosmos, txHash)
+	}
+}
+
 // prepareFactory ensures the account defined by ctx.GetFromAddress() exists and
 // if the account number and/or the account sequence number are zero (not set),
 // they will be queried for and set on the provided Factory. A new Factory with
@@ -0,0 +1,164 @@
+package peggo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/spf13/cobra"
+
+	"github.com/umee-network/peggo/cmd/peggo/client"
+	"github.com/umee-network/peggo/orchestrator/cohort"
+)
+
+// batchTimeoutBlocks returns the number of Ethereum blocks the Gravity module
+// adds to the Ethereum height when setting the timeout of a new batch.
+func batchTimeoutBlocks(params *gravitytypes.Params) uint64 {
+	if params.AverageEthereumBlockTime == 0 {
+		return 0
+	}
+
+	return params.TargetBatchTimeout / params.AverageEthereumBlockTime
+}
+
+func getQueryRelayerCohortCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "relayer-cohort [gravity-addr]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Print the relayers active on the Gravity contract and how fast they relay",
+		Long: `Print the relayers active on the Gravity contract and how fast they relay.
+
+The batches and valset updates executed over the last --blocks Ethereum blocks
+are attributed to the senders of their txs. For each relayer, the following
+values are shown:
+- the number of batches and valset updates it relayed
+- its share of all the relays
+- its median response time, from the batch creation to its execution
+
+The batch creation is estimated from the batch timeout and the Gravity module
+params, so response times are accurate to a few Ethereum blocks.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			konfig, err := parseServerConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			logger, err := getLogger(cmd)
+			if err != nil {
+				return err
+			}
+
+			if !ethcmn.IsHexAddress(args[0]) {
+				return fmt.Errorf("invalid gravity address: %s", args[0])
+			}
+			gravityAddr := ethcmn.HexToAddress(args[0])
+
+			blocks := konfig.Int64(flagBlocks)
+			if blocks <= 0 {
+				return fmt.Errorf("--%s must be positive", flagBlocks)
+			}
+
+			clientCtx, err := client.NewClientContext(konfig.String(flagCosmosChainID), "", nil)
+			if err != nil {
+				return err
+			}
+
+			cosmosGRPC, err := parseURL(logger, konfig, flagCosmosGRPC)
+			if err != nil {
+				return err
+			}
+
+			daemonClient, err := client.NewCosmosClient(clientCtx, logger, cosmosGRPC)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+			defer cancel()
+
+			gRPCConn := daemonClient.QueryClient()
+			waitForService(ctx, gRPCConn)
+
+			gravityParams, err := getGravityParams(gRPCConn)
+			if err != nil {
+				return err
+			}
+
+			ethRPC, err := ethclient.Dial(konfig.String(flagEthRPC))
+			if err != nil {
+				return fmt.Errorf("failed to dial Ethereum RPC node: %w", err)
+			}
+
+			gravityContract, err := getGravityContract(ethRPC, gravityAddr)
+			if err != nil {
+				return err
+			}
+
+			gravityVersion, err := resolveGravityVersion(konfig, ethRPC, gravityAddr)
+			if err != nil {
+				return err
+			}
+
+			scanner := cohort.NewScanner(
+				logger,
+				ethRPC,
+				gravityContract,
+				gravityVersion.ABI,
+				batchTimeoutBlocks(gravityParams),
+			)
+
+			to, err := scanner.LatestHeight(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get the latest Ethereum height: %w", err)
+			}
+
+			var from uint64
+			if to >= uint64(blocks) {
+				from = to - uint64(blocks) + 1
+			}
+
+			relays, err := scanner.Relays(ctx, from, to)
+			if err != nil {
+				return err
+			}
+
+			summary := cohort.Summarize(relays, from, to)
+
+			if isJSONOutput(konfig) {
+				return printJSON(summary)
+			}
+
+			fmt.Printf(
+				"Blocks %d to %d: %d relays by %d relayers, median response %s, p90 response %s\n\n",
+				summary.FromHeight,
+				summary.ToHeight,
+				summary.Relays,
+				summary.ActiveRelayers,
+				summary.MedianResponse,
+				summary.P90Response,
+			)
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+			fmt.Fprintln(w, "RELAYER\tBATCHES\tVALSETS\tSHARE\tMEDIAN RESPONSE")
+			for _, r := range summary.Relayers {
+				fmt.Fprintf(w, "%s\t%d\t%d\t%.1f%%\t%s\n", r.Address, r.Batches, r.Valsets, r.Share*100, r.MedianResponse)
+			}
+
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().String(flagFormat, "text", "Print the relayers in the given format (text|json)")
+	cmd.Flags().Int64(flagBlocks, 7200, "Number of latest Ethereum blocks to analyze")
+	cmd.Flags().String(flagEthRPC, "http://localhost:8545", "Specify the RPC address of an Ethereum node")
+	cmd.Flags().AddFlagSet(cosmosFlagSet())
+	cmd.Flags().AddFlagSet(gravityVersionFlagSet())
+
+	return cmd
+}
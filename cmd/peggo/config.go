@@ -0,0 +1,431 @@
+package peggo
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/knadh/koanf"
+	"github.com/knadh/koanf/parsers/toml"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/umee-network/peggo/orchestrator/invariant"
+	"github.com/umee-network/peggo/orchestrator/pricewatch"
+	"github.com/umee-network/peggo/orchestrator/relayer"
+	"github.com/umee-network/peggo/orchestrator/remoteconfig"
+	"github.com/umee-network/peggo/solwrappers/versions"
+)
+
+const redacted = "<redacted>"
+
+// secretFlags are redacted when printing the effective configuration.
+var secretFlags = map[string]bool{
+	flagCosmosPK:             true,
+	flagCosmosFromPassphrase: true,
+	flagEthPK:                true,
+	flagEthPassphrase:        true,
+	flagSignerToken:          true,
+	flagMetaTxAPIKey:         true,
+	flagAdminToken:           true,
+	flagOracleProviderKeys:   true,
+}
+
+func getConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Commands to inspect the orchestrator configuration",
+	}
+
+	cmd.AddCommand(
+		getConfigValidateCmd(),
+	)
+
+	return cmd
+}
+
+func getConfigValidateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate [gravity-addr]",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "Validate the orchestrator configuration without connecting to any network",
+		Long: `Validate the orchestrator configuration without connecting to any network.
+
+The configuration is loaded from the config file, environment variables and
+flags exactly as the orchestrator command does, the Cosmos and Ethereum keys are
+resolved, and every value is checked, including combinations of them (e.g.
+relaying enabled without a usable Ethereum key). The normalized effective
+configuration is printed as TOML, with secrets redacted, and all the problems
+found are reported at once.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			konfig, err := parseServerConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			logger, err := getLogger(cmd)
+			if err != nil {
+				return err
+			}
+
+			effective := effectiveConfig(konfig, cmd.Flags())
+
+			orchAddress, ethAddress, errs := validateConfig(logger, konfig, args)
+
+			// Show the resolved key addresses rather than how they were given.
+			if orchAddress != nil {
+				effective[flagCosmosFrom] = orchAddress.String()
+			}
+			if ethAddress != (ethcmn.Address{}) {
+				effective[flagEthFrom] = ethAddress.Hex()
+			}
+
+			if isJSONOutput(konfig) {
+				if err := printJSON(effective); err != nil {
+					return fmt.Errorf("failed to print the effective configuration: %w", err)
+				}
+			} else {
+				bz, err := toml.Parser().Marshal(effective)
+				if err != nil {
+					return fmt.Errorf("failed to print the effective configuration: %w", err)
+				}
+
+				if _, err := fmt.Println(string(bz)); err != nil {
+					return err
+				}
+			}
+
+			if err := errs.ErrorOrNil(); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(os.Stderr, "Configuration is valid")
+			return nil
+		},
+	}
+
+	// Validate exactly what the orchestrator would run with.
+	cmd.Flags().AddFlagSet(getOrchestratorCmd().Flags())
+
+	return cmd
+}
+
+// validateConfig checks the orchestrator configuration without connecting to
+// any network. It returns the resolved key addresses, if any, and all the
+// problems found.
+func validateConfig(
+	logger zerolog.Logger,
+	konfig *koanf.Koanf,
+	args []string,
+) (orchAddress sdk.AccAddress, ethAddress ethcmn.Address, errs *multierror.Error) {
+
+	check := func(err error) {
+		if err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+
+	if len(args) > 0 && !ethcmn.IsHexAddress(args[0]) {
+		check(fmt.Errorf("invalid gravity address: %s", args[0]))
+	}
+
+	if konfig.String(flagCosmosChainID) == "" {
+		check(fmt.Errorf("--%s is required", flagCosmosChainID))
+	}
+
+	for _, flag := range []string{flagCosmosGRPC, flagTendermintRPC, flagEthRPC} {
+		if konfig.String(flag) == "" {
+			check(fmt.Errorf("--%s is required", flag))
+			continue
+		}
+
+		if _, err := parseURL(logger, konfig, flag); err != nil {
+			check(fmt.Errorf("invalid --%s: %w", flag, err))
+		}
+	}
+
+	for _, flag := range []string{
+		flagCoinGeckoAPI,
+		flagEthAlchemyWS,
+		flagHeartbeatEndpoint,
+		flagMetaTxEndpoint,
+		flagDenylistURL,
+		flagTopupWebhook,
+		flagRemoteConfigURL,
+		flagRemoteConfigSigURL,
+	} {
+		if v := konfig.String(flag); v != "" {
+			if _, err := url.ParseRequestURI(v); err != nil {
+				check(fmt.Errorf("invalid --%s: %w", flag, err))
+			}
+		}
+	}
+
+	for _, endpoint := range konfig.Strings(flagEthRPCExtra) {
+		if _, err := url.ParseRequestURI(endpoint); err != nil {
+			check(fmt.Errorf("invalid --%s: %w", flagEthRPCExtra, err))
+		}
+	}
+
+	if len(konfig.Strings(flagEthRPCExtra)) > 0 && konfig.Duration(flagEthRPCProbeInterval) <= 0 {
+		check(fmt.Errorf("--%s must be positive when --%s is set", flagEthRPCProbeInterval, flagEthRPCExtra))
+	}
+
+	if _, err := sdk.ParseDecCoins(konfig.String(flagCosmosGasPrices)); err != nil {
+		check(fmt.Errorf("invalid --%s: %w", flagCosmosGasPrices, err))
+	}
+
+	if v := konfig.String(flagCosmosFeeGranter); v != "" {
+		if _, err := sdk.AccAddressFromBech32(v); err != nil {
+			check(fmt.Errorf("invalid --%s: %w", flagCosmosFeeGranter, err))
+		}
+	}
+
+	valsetRelayMode, err := validateRelayValsetsMode(konfig.String(flagValsetRelayMode))
+	check(err)
+
+	relaying := konfig.Bool(flagRelayBatches) || valsetRelayMode != relayer.ValsetRelayModeNone
+
+	// Keys are only resolved locally; Ledger devices are never opened.
+	if konfig.Bool(flagCosmosUseLedger) || konfig.Bool(flagEthUseLedger) {
+		check(fmt.Errorf("cannot use Ledger for orchestrator"))
+	} else {
+		if orchAddress, _, err = initCosmosKeyring(konfig); err != nil {
+			check(fmt.Errorf("failed to initialize Cosmos keyring: %w", err))
+		}
+
+		// The signers holding the Ethereum key are only reached by the orchestrator.
+		switch {
+		case konfig.String(flagSignerSocket) != "":
+			check(validateRemoteSigner(konfig, valsetRelayMode))
+
+		// The threshold signer is only dialed by the commands signing with it,
+		// so the address of its key is only known if set.
+		case konfig.String(flagEthTSSGRPC) != "":
+			check(validateThresholdSigner(konfig))
+
+			if from := konfig.String(flagEthFrom); ethcmn.IsHexAddress(from) {
+				ethAddress = ethcmn.HexToAddress(from)
+			}
+
+		default:
+			ethAddress, _, _, _, err = initEthereumAccountsManager(logger, 0, konfig)
+			switch {
+			case err != nil && relaying:
+				check(fmt.Errorf("relaying is enabled but the Ethereum key can't be loaded: %w", err))
+			case err != nil:
+				check(fmt.Errorf("failed to initialize Ethereum account: %w", err))
+			}
+		}
+
+		// The remote signer has its own signature scheme.
+		if konfig.String(flagSignerSocket) == "" {
+			_, err = newConfirmSigner(konfig, nil, nil)
+			check(err)
+		}
+	}
+
+	check(relayer.ValidateMissingPriceFallback(konfig.String(flagMissingPriceFallback)))
+
+	if konfig.Float64(flagProfitMultiplier) < 0 {
+		check(fmt.Errorf("--%s must not be negative", flagProfitMultiplier))
+	}
+
+	if konfig.Float64(flagBatchDustFeeUSD) < 0 {
+		check(fmt.Errorf("--%s must not be negative", flagBatchDustFeeUSD))
+	}
+
+	if konfig.Int64(flagBatchTargetSize) < 0 {
+		check(fmt.Errorf("--%s must not be negative", flagBatchTargetSize))
+	}
+
+	if konfig.Int64(flagBatchTargetSize) > 0 && konfig.Duration(flagBatchMaxWait) <= 0 {
+		check(fmt.Errorf("--%s must be positive with --%s", flagBatchMaxWait, flagBatchTargetSize))
+	}
+
+	if konfig.String(flagGasAssetSymbol) == "" {
+		check(fmt.Errorf("--%s is required", flagGasAssetSymbol))
+	}
+
+	check(validateSkipEventsBeforeNonce(konfig))
+
+	if len(args) > 0 && ethcmn.IsHexAddress(args[0]) {
+		_, err := contractMigration(konfig, ethcmn.HexToAddress(args[0]))
+		check(err)
+	}
+
+	if konfig.Int64(flagCosmosMaxHeightLag) < 0 {
+		check(fmt.Errorf("--%s must not be negative", flagCosmosMaxHeightLag))
+	}
+
+	if konfig.Float64(flagRelayerLoopMultiplier) <= 0 {
+		check(fmt.Errorf("--%s must be positive", flagRelayerLoopMultiplier))
+	}
+
+	if konfig.Float64(flagRequesterLoopMultiplier) <= 0 {
+		check(fmt.Errorf("--%s must be positive", flagRequesterLoopMultiplier))
+	}
+
+	if konfig.Int(flagCosmosMsgsPerTx) <= 0 {
+		check(fmt.Errorf("--%s must be positive", flagCosmosMsgsPerTx))
+	}
+
+	if konfig.Int(flagClaimsPipelineDepth) <= 0 {
+		check(fmt.Errorf("--%s must be positive", flagClaimsPipelineDepth))
+	}
+
+	if konfig.Float64(flagClaimsGasAdjustment) < 1 {
+		check(fmt.Errorf("--%s must be at least 1", flagClaimsGasAdjustment))
+	}
+
+	if konfig.Int(flagEthStartupScanWorkers) <= 0 {
+		check(fmt.Errorf("--%s must be positive", flagEthStartupScanWorkers))
+	}
+
+	if konfig.Int64(flagEthStartupScanBlocks) < 0 {
+		check(fmt.Errorf("--%s must not be negative", flagEthStartupScanBlocks))
+	}
+
+	for _, addr := range konfig.Strings(flagRelayRewardAddresses) {
+		if !ethcmn.IsHexAddress(addr) {
+			check(fmt.Errorf("invalid --%s address %q", flagRelayRewardAddresses, addr))
+		}
+	}
+
+	if name := konfig.String(flagGravityVersion); name != "" && name != versions.Auto {
+		if _, err := versions.Get(name); err != nil {
+			check(err)
+		}
+	}
+
+	for _, flag := range []string{flagEthHeaderCacheSize, flagEthReceiptCacheSize} {
+		if konfig.Int(flag) <= 0 {
+			check(fmt.Errorf("--%s must be positive", flag))
+		}
+	}
+
+	if konfig.String(flagDenylistURL) != "" && konfig.Duration(flagDenylistRefresh) <= 0 {
+		check(fmt.Errorf("--%s must be positive when --%s is set", flagDenylistRefresh, flagDenylistURL))
+	}
+
+	if konfig.Int64(flagCohortBlocks) > 0 && konfig.Duration(flagCohortInterval) <= 0 {
+		check(fmt.Errorf("--%s must be positive when --%s is set", flagCohortInterval, flagCohortBlocks))
+	}
+
+	if konfig.String(flagRemoteConfigURL) != "" {
+		if _, err := remoteconfig.ParsePublicKey(konfig.String(flagRemoteConfigPubKey)); err != nil {
+			check(fmt.Errorf("invalid --%s: %w", flagRemoteConfigPubKey, err))
+		}
+
+		if konfig.Duration(flagRemoteConfigRefresh) <= 0 {
+			check(fmt.Errorf("--%s must be positive when --%s is set", flagRemoteConfigRefresh, flagRemoteConfigURL))
+		}
+	}
+
+	for _, flag := range []string{flagRelayJitter, flagRelayGracePeriod, flagRelayPivotalTimeout, flagGasPriceSmoothing} {
+		if konfig.Duration(flag) < 0 {
+			check(fmt.Errorf("--%s must not be negative", flag))
+		}
+	}
+
+	if v := konfig.Float64(flagMaxValueConcentration); v < 0 || v > 100 {
+		check(fmt.Errorf("--%s must be between 0 and 100", flagMaxValueConcentration))
+	}
+
+	if konfig.Duration(flagBreakerMaxBackoff) < konfig.Duration(flagBreakerBackoff) {
+		check(fmt.Errorf("--%s must not be lower than --%s", flagBreakerMaxBackoff, flagBreakerBackoff))
+	}
+
+	if konfig.String(flagTopupThreshold) != "" {
+		_, err := newTopupMonitor(konfig, logger, nil, nil, orchAddress, ethAddress)
+		check(err)
+	} else if konfig.String(flagTopupWebhook) != "" || konfig.String(flagTopupSendToEth) != "" {
+		check(fmt.Errorf("top-up actions are set but --%s is empty", flagTopupThreshold))
+	}
+
+	if _, err := parseInstanceLabels(konfig.Strings(flagInstanceLabel)); err != nil {
+		check(err)
+	}
+
+	if _, err := oracleOptions(konfig); err != nil {
+		check(err)
+	}
+
+	if _, _, err := providerLimiters(konfig); err != nil {
+		check(err)
+	}
+
+	if _, err := parseSchedule(konfig, flagAnalyticsSchedule); err != nil {
+		check(err)
+	}
+
+	if _, err := newKeyPolicy(konfig, logger, nil); err != nil {
+		check(err)
+	}
+
+	if interval := konfig.Duration(flagInvariantInterval); interval > 0 {
+		_, err := invariant.NewMonitor(
+			logger,
+			invariant.Config{Interval: interval, Tolerance: konfig.Float64(flagInvariantTolerance)},
+			nil,
+			nil,
+		)
+		check(err)
+	}
+
+	if interval := konfig.Duration(flagOracleWatchdogInterval); interval > 0 {
+		_, err := pricewatch.NewWatchdog(
+			logger,
+			pricewatch.Config{
+				Interval:  interval,
+				Tolerance: konfig.Float64(flagOracleWatchdogTolerance),
+				Sustain:   konfig.Duration(flagOracleWatchdogSustain),
+			},
+			nil,
+			nil,
+		)
+		check(err)
+	}
+
+	return orchAddress, ethAddress, errs
+}
+
+// effectiveConfig returns the value of every flag, normalized to its type and
+// with secrets redacted.
+func effectiveConfig(konfig *koanf.Koanf, flags *pflag.FlagSet) map[string]interface{} {
+	effective := map[string]interface{}{}
+
+	flags.VisitAll(func(f *pflag.Flag) {
+		if secretFlags[f.Name] {
+			set := konfig.String(f.Name) != ""
+			if f.Value.Type() == "stringSlice" {
+				set = len(konfig.Strings(f.Name)) > 0
+			}
+			if set {
+				effective[f.Name] = redacted
+			}
+			return
+		}
+
+		switch f.Value.Type() {
+		case "bool":
+			effective[f.Name] = konfig.Bool(f.Name)
+		case "int", "int64":
+			effective[f.Name] = konfig.Int64(f.Name)
+		case "float64":
+			effective[f.Name] = konfig.Float64(f.Name)
+		case "duration":
+			effective[f.Name] = konfig.Duration(f.Name).String()
+		case "stringSlice":
+			effective[f.Name] = konfig.Strings(f.Name)
+		default:
+			effective[f.Name] = konfig.String(f.Name)
+		}
+	})
+
+	return effective
+}
@@ -0,0 +1,106 @@
+package peggo
+
+import (
+	"fmt"
+	"math/big"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/knadh/koanf"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/gravity"
+	"github.com/umee-network/peggo/orchestrator/ethereum/keystore"
+	"github.com/umee-network/peggo/orchestrator/signer"
+)
+
+// newConfirmSigner returns the signer of valset and batch confirms matching the
+// --confirm-signature-scheme of the deployment.
+func newConfirmSigner(
+	konfig *koanf.Koanf,
+	personalSignFn keystore.PersonalSignFn,
+	typedDataSignFn keystore.TypedDataSignFn,
+) (signer.ConfirmSigner, error) {
+	switch scheme := konfig.String(flagConfirmScheme); scheme {
+	case gravity.SignatureSchemePersonal:
+		return signer.NewLocal(personalSignFn), nil
+
+	case gravity.SignatureSchemeEIP712:
+		scheme, err := typedConfirmSchemeFromConfig(konfig)
+		if err != nil {
+			return nil, err
+		}
+
+		return signer.NewTypedData(typedDataSignFn, scheme), nil
+
+	default:
+		return nil, fmt.Errorf(
+			"invalid --%s %q; expected %s or %s",
+			flagConfirmScheme, scheme, gravity.SignatureSchemePersonal, gravity.SignatureSchemeEIP712,
+		)
+	}
+}
+
+// typedConfirmSchemeFromConfig returns the EIP-712 domain and struct types of
+// typed confirms.
+func typedConfirmSchemeFromConfig(konfig *koanf.Koanf) (gravity.TypedConfirmScheme, error) {
+	domain, err := eip712DomainFromConfig(konfig)
+	if err != nil {
+		return gravity.TypedConfirmScheme{}, err
+	}
+
+	valsetType, err := gravity.ParseValsetConfirmType(konfig.String(flagEIP712ValsetType))
+	if err != nil {
+		return gravity.TypedConfirmScheme{}, fmt.Errorf("invalid --%s: %w", flagEIP712ValsetType, err)
+	}
+
+	batchType, err := gravity.ParseBatchConfirmType(konfig.String(flagEIP712BatchType))
+	if err != nil {
+		return gravity.TypedConfirmScheme{}, fmt.Errorf("invalid --%s: %w", flagEIP712BatchType, err)
+	}
+
+	return gravity.TypedConfirmScheme{
+		Domain:     domain,
+		ValsetType: valsetType,
+		BatchType:  batchType,
+	}, nil
+}
+
+// eip712DomainFromConfig returns the EIP-712 domain of typed confirms. The
+// optional fields are left out of the domain when not set, so they must be set
+// exactly as the Gravity fork defines its domain.
+func eip712DomainFromConfig(konfig *koanf.Koanf) (gravity.EIP712Domain, error) {
+	domain := gravity.EIP712Domain{
+		Name:    konfig.String(flagEIP712Name),
+		Version: konfig.String(flagEIP712Version),
+	}
+
+	if domain.Name == "" {
+		return domain, fmt.Errorf("--%s is required with the %s scheme", flagEIP712Name, gravity.SignatureSchemeEIP712)
+	}
+
+	switch chainID := konfig.Int64(flagEIP712ChainID); {
+	case chainID < 0:
+		return domain, fmt.Errorf("--%s must not be negative", flagEIP712ChainID)
+
+	case chainID > 0:
+		domain.ChainID = big.NewInt(chainID)
+	}
+
+	if contract := konfig.String(flagEIP712Contract); contract != "" {
+		if !ethcmn.IsHexAddress(contract) {
+			return domain, fmt.Errorf("invalid --%s: %s", flagEIP712Contract, contract)
+		}
+
+		domain.VerifyingContract = ethcmn.HexToAddress(contract)
+	}
+
+	if salt := konfig.String(flagEIP712Salt); salt != "" {
+		bz := ethcmn.FromHex(salt)
+		if len(bz) != ethcmn.HashLength {
+			return domain, fmt.Errorf("invalid --%s: expected %d bytes", flagEIP712Salt, ethcmn.HashLength)
+		}
+
+		domain.Salt = ethcmn.BytesToHash(bz)
+	}
+
+	return domain, nil
+}
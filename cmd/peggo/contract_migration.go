@@ -0,0 +1,42 @@
+package peggo
+
+import (
+	"fmt"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/knadh/koanf"
+
+	"github.com/umee-network/peggo/orchestrator"
+)
+
+// contractMigration returns the configured migration from the Gravity contract
+// at gravityAddr to a new one, or nil if none is configured.
+func contractMigration(konfig *koanf.Koanf, gravityAddr ethcmn.Address) (*orchestrator.ContractMigration, error) {
+	block := konfig.Int64(flagMigrationBlock)
+	address := konfig.String(flagMigrationAddress)
+	lastEventNonce := konfig.Int64(flagMigrationLastEventNonce)
+
+	switch {
+	case block == 0 && address == "":
+		return nil, nil
+
+	case block <= 0:
+		return nil, fmt.Errorf("--%s must be positive", flagMigrationBlock)
+
+	case !ethcmn.IsHexAddress(address):
+		return nil, fmt.Errorf("invalid --%s address %q", flagMigrationAddress, address)
+
+	case ethcmn.HexToAddress(address) == gravityAddr:
+		return nil, fmt.Errorf("--%s must differ from the Gravity contract address", flagMigrationAddress)
+
+	case lastEventNonce < 0:
+		return nil, fmt.Errorf("--%s must not be negative", flagMigrationLastEventNonce)
+	}
+
+	return &orchestrator.ContractMigration{
+		Block:          uint64(block),
+		From:           gravityAddr,
+		To:             ethcmn.HexToAddress(address),
+		LastEventNonce: uint64(lastEventNonce),
+	}, nil
+}
@@ -0,0 +1,201 @@
+package peggo
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/knadh/koanf"
+	"github.com/spf13/cobra"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/gravity"
+)
+
+func getDebugCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "debug",
+		Short: "Commands to debug the compatibility of peggo with other orchestrators",
+	}
+
+	cmd.AddCommand(
+		getSignVectorsCmd(),
+	)
+
+	return cmd
+}
+
+// signVector is the checkpoint and confirm signature of a valset or batch, as
+// this peggo would produce them.
+type signVector struct {
+	Type       string `json:"type"`
+	Nonce      uint64 `json:"nonce"`
+	GravityID  string `json:"gravity_id"`
+	Scheme     string `json:"scheme"`
+	Checkpoint string `json:"checkpoint"`
+	Digest     string `json:"digest"`
+	Signer     string `json:"signer"`
+	Signature  string `json:"signature"`
+}
+
+func getSignVectorsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sign-vectors [valset|batch]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Print the checkpoint and confirm signature of a valset or batch",
+		Long: `Print the checkpoint and confirm signature of a valset or batch.
+
+The --from-file holds the valset or batch in the JSON format of the Gravity
+module. The command prints the checkpoint, the digest actually signed (after the
+personal_sign prefix or the EIP-712 encoding of --confirm-signature-scheme) and
+the signature of the configured Ethereum key, exactly as the orchestrator would
+submit them in its confirm. Nothing is sent anywhere, so the output can be
+compared with the one of other orchestrator implementations for the same inputs
+and a throwaway key.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			konfig, err := parseServerConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			logger, err := getLogger(cmd)
+			if err != nil {
+				return err
+			}
+
+			gravityID := konfig.String(flagSignerGravityID)
+			if gravityID == "" {
+				return fmt.Errorf("--%s is required", flagSignerGravityID)
+			}
+
+			bz, err := os.ReadFile(konfig.String(flagFromFile))
+			if err != nil {
+				return fmt.Errorf("failed to read the %s: %w", args[0], err)
+			}
+
+			// The chain ID is only used to sign transactions, which this command never does.
+			ethAddress, _, personalSignFn, typedDataSignFn, err := initEthereumAccountsManager(logger, 0, konfig)
+			if err != nil {
+				return fmt.Errorf("failed to initialize Ethereum account: %w", err)
+			}
+
+			zeroStdinSecrets()
+
+			confirmSigner, err := newConfirmSigner(konfig, personalSignFn, typedDataSignFn)
+			if err != nil {
+				return err
+			}
+
+			vector := signVector{
+				Type:      args[0],
+				GravityID: gravityID,
+				Scheme:    konfig.String(flagConfirmScheme),
+				Signer:    ethAddress.Hex(),
+			}
+
+			var (
+				checkpoint ethcmn.Hash
+				typedData  []byte
+				signature  []byte
+				ctx        = context.Background()
+			)
+
+			switch args[0] {
+			case payloadTypeValset:
+				var valset gravitytypes.Valset
+				if err := unmarshalGravityJSON(bz, &valset); err != nil {
+					return fmt.Errorf("failed to decode the valset: %w", err)
+				}
+
+				vector.Nonce = valset.Nonce
+				checkpoint = gravity.EncodeValsetConfirm(gravityID, valset)
+
+				if vector.Scheme == gravity.SignatureSchemeEIP712 {
+					scheme, err := typedConfirmSchemeFromConfig(konfig)
+					if err != nil {
+						return err
+					}
+
+					typedData = gravity.EncodeTypedValsetConfirm(scheme, gravityID, valset)
+				}
+
+				signature, err = confirmSigner.SignValsetConfirm(ctx, ethAddress, gravityID, valset)
+				if err != nil {
+					return fmt.Errorf("failed to sign the valset confirm: %w", err)
+				}
+
+			case payloadTypeBatch:
+				var batch gravitytypes.OutgoingTxBatch
+				if err := unmarshalGravityJSON(bz, &batch); err != nil {
+					return fmt.Errorf("failed to decode the batch: %w", err)
+				}
+
+				vector.Nonce = batch.BatchNonce
+				checkpoint = gravity.EncodeTxBatchConfirm(gravityID, batch)
+
+				if vector.Scheme == gravity.SignatureSchemeEIP712 {
+					scheme, err := typedConfirmSchemeFromConfig(konfig)
+					if err != nil {
+						return err
+					}
+
+					typedData = gravity.EncodeTypedBatchConfirm(scheme, gravityID, batch)
+				}
+
+				signature, err = confirmSigner.SignBatchConfirm(ctx, ethAddress, gravityID, batch)
+				if err != nil {
+					return fmt.Errorf("failed to sign the batch confirm: %w", err)
+				}
+
+			default:
+				return fmt.Errorf("invalid type %q; expected %s or %s", args[0], payloadTypeValset, payloadTypeBatch)
+			}
+
+			vector.Checkpoint = checkpoint.Hex()
+			vector.Digest = hexutil.Encode(signedDigest(checkpoint, typedData))
+			vector.Signature = hexutil.Encode(signature)
+
+			return printSignVector(konfig, vector)
+		},
+	}
+
+	cmd.Flags().String(flagFromFile, "", "The JSON file holding the valset or batch to sign")
+	cmd.Flags().String(flagSignerGravityID, "", "Specify the Gravity ID the checkpoint commits to")
+	cmd.Flags().AddFlagSet(ethereumKeyOptsFlagSet())
+	cmd.Flags().AddFlagSet(confirmSchemeFlagSet())
+	_ = cmd.MarkFlagRequired(flagFromFile)
+
+	return cmd
+}
+
+// signedDigest returns the hash the confirm signature is over: the keccak256 of
+// the EIP-712 encoding for typed confirms, or of the personal_sign message
+// wrapping the checkpoint otherwise.
+func signedDigest(checkpoint ethcmn.Hash, typedData []byte) []byte {
+	if typedData != nil {
+		return crypto.Keccak256(typedData)
+	}
+
+	return accounts.TextHash(checkpoint.Bytes())
+}
+
+func printSignVector(konfig *koanf.Koanf, vector signVector) error {
+	if isJSONOutput(konfig) {
+		return printJSON(vector)
+	}
+
+	fmt.Printf("type:       %s\n", vector.Type)
+	fmt.Printf("nonce:      %d\n", vector.Nonce)
+	fmt.Printf("gravity id: %s\n", vector.GravityID)
+	fmt.Printf("scheme:     %s\n", vector.Scheme)
+	fmt.Printf("checkpoint: %s\n", vector.Checkpoint)
+	fmt.Printf("digest:     %s\n", vector.Digest)
+	fmt.Printf("signer:     %s\n", vector.Signer)
+	fmt.Printf("signature:  %s\n", vector.Signature)
+
+	return nil
+}
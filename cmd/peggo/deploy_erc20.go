@@ -0,0 +1,346 @@
+package peggo
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
+)
+
+const (
+	deployStatusDeployed   = "deployed"
+	deployStatusRegistered = "registered"
+	deployStatusFailed     = "failed"
+	deployStatusUnverified = "unverified"
+	deployStatusSkipped    = "not attempted"
+)
+
+// errDenomNotRegistered is the error returned by the Gravity module when a denom
+// has no ERC20 yet.
+const errDenomNotRegistered = "not in cosmos-originated ERC20 index"
+
+type erc20Metadata struct {
+	name     string
+	symbol   string
+	decimals uint8
+}
+
+type deployERC20sConfig struct {
+	ethRPC          *ethclient.Client
+	auth            *bind.TransactOpts
+	gravityAddr     ethcmn.Address
+	gravityContract *wrappers.Gravity
+	verifier        *deploymentVerifier
+	bankQuerier     banktypes.QueryClient
+	gravityQuerier  gravitytypes.QueryClient
+	receiptTimeout  time.Duration
+	jsonOutput      bool
+}
+
+type deployERC20Result struct {
+	denom  string
+	status string
+	erc20  string
+	tx     string
+	err    error
+	// pending is set when the transaction wasn't mined within the receipt timeout.
+	pending bool
+}
+
+// queryERC20Metadata returns the ERC20 name, symbol and decimals of a denom from
+// its bank metadata.
+func queryERC20Metadata(bankQuerier banktypes.QueryClient, baseDenom string) (erc20Metadata, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	resp, err := bankQuerier.DenomMetadata(ctx, &banktypes.QueryDenomMetadataRequest{Denom: baseDenom})
+	if err != nil {
+		return erc20Metadata{}, fmt.Errorf("failed to query for bank metadata: %w", err)
+	}
+
+	switch {
+	case len(resp.Metadata.Name) == 0:
+		return erc20Metadata{}, errors.New("token metadata name cannot be empty")
+
+	case len(resp.Metadata.Symbol) == 0:
+		return erc20Metadata{}, errors.New("token metadata symbol cannot be empty")
+
+	case len(resp.Metadata.Display) == 0:
+		return erc20Metadata{}, errors.New("token metadata display cannot be empty")
+	}
+
+	var decimals uint8
+	for _, unit := range resp.Metadata.DenomUnits {
+		if unit.Denom == resp.Metadata.Display {
+			if unit.Exponent > math.MaxUint8 {
+				return erc20Metadata{}, fmt.Errorf("token exponent too large; %d > %d", unit.Exponent, math.MaxInt8)
+			}
+
+			decimals = uint8(unit.Exponent)
+			break
+		}
+	}
+
+	return erc20Metadata{
+		name:     resp.Metadata.Name,
+		symbol:   resp.Metadata.Symbol,
+		decimals: decimals,
+	}, nil
+}
+
+// readDenomsFile reads the denoms to deploy from a JSON array of denoms (.json
+// extension) or a CSV file with a denom in the first column. Empty lines,
+// comments (#), a "denom" header and duplicates are ignored.
+func readDenomsFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open denoms file: %w", err)
+	}
+	defer f.Close()
+
+	var entries []string
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := json.NewDecoder(f).Decode(&entries); err != nil {
+			return nil, fmt.Errorf("failed to decode denoms file: %w", err)
+		}
+	} else {
+		r := csv.NewReader(f)
+		r.Comment = '#'
+		r.FieldsPerRecord = -1
+		r.TrimLeadingSpace = true
+
+		for {
+			record, err := r.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read denoms file: %w", err)
+			}
+
+			entries = append(entries, record[0])
+		}
+	}
+
+	var (
+		denoms = make([]string, 0, len(entries))
+		seen   = make(map[string]struct{}, len(entries))
+	)
+
+	for _, denom := range entries {
+		denom = strings.TrimSpace(denom)
+		if denom == "" || strings.EqualFold(denom, "denom") {
+			continue
+		}
+
+		if _, ok := seen[denom]; ok {
+			continue
+		}
+
+		seen[denom] = struct{}{}
+		denoms = append(denoms, denom)
+	}
+
+	if len(denoms) == 0 {
+		return nil, fmt.Errorf("no denoms found in %s", path)
+	}
+
+	return denoms, nil
+}
+
+// deployERC20s deploys the ERC20 of every denom that isn't registered in the
+// Gravity module yet, one at a time. The nonce is incremented locally after each
+// transaction and the next one is only sent once the receipt of the previous one
+// was received. A summary of all the denoms is printed at the end.
+func deployERC20s(cfg deployERC20sConfig, denoms []string) error {
+	results := make([]deployERC20Result, 0, len(denoms))
+
+	for i, denom := range denoms {
+		result := deployERC20(cfg, denom)
+		results = append(results, result)
+
+		if result.err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to deploy ERC20 for %s: %s\n", denom, result.err)
+		}
+
+		// A transaction that wasn't mined in time keeps its nonce, so sending the
+		// next ones would only queue them behind it.
+		if result.pending {
+			for _, denom := range denoms[i+1:] {
+				results = append(results, deployERC20Result{denom: denom, status: deployStatusSkipped})
+			}
+			break
+		}
+	}
+
+	if cfg.jsonOutput {
+		if err := printDeployERC20JSON(results); err != nil {
+			return err
+		}
+	} else {
+		printDeployERC20Report(results)
+	}
+
+	var failed int
+	for _, result := range results {
+		if result.status != deployStatusDeployed && result.status != deployStatusRegistered {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d ERC20 tokens were not deployed", failed, len(results))
+	}
+
+	return nil
+}
+
+func deployERC20(cfg deployERC20sConfig, denom string) deployERC20Result {
+	result := deployERC20Result{denom: denom, status: deployStatusFailed}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	resp, err := cfg.gravityQuerier.DenomToERC20(ctx, &gravitytypes.QueryDenomToERC20Request{Denom: denom})
+	switch {
+	case err == nil:
+		result.status = deployStatusRegistered
+		result.erc20 = resp.Erc20
+		return result
+
+	case !strings.Contains(err.Error(), errDenomNotRegistered):
+		result.err = fmt.Errorf("failed to query the ERC20 of the denom: %w", err)
+		return result
+	}
+
+	metadata, err := queryERC20Metadata(cfg.bankQuerier, denom)
+	if err != nil {
+		result.err = err
+		return result
+	}
+
+	tx, err := cfg.gravityContract.DeployERC20(cfg.auth, denom, metadata.name, metadata.symbol, metadata.decimals)
+	if err != nil {
+		result.err = fmt.Errorf("failed deploy Cosmos native ERC20 token: %w", err)
+		return result
+	}
+
+	result.tx = tx.Hash().Hex()
+	cfg.auth.Nonce = new(big.Int).Add(cfg.auth.Nonce, big.NewInt(1))
+
+	fmt.Fprintf(os.Stderr, "Deploying ERC20 for %s in transaction %s...\n", denom, result.tx)
+
+	receiptCtx, cancel := context.WithTimeout(context.Background(), cfg.receiptTimeout)
+	defer cancel()
+
+	erc20Addr, err := waitERC20Deployed(receiptCtx, cfg.ethRPC, cfg.gravityContract, tx, denom)
+	if err != nil {
+		result.err = err
+		result.pending = errors.Is(err, context.DeadlineExceeded)
+		return result
+	}
+
+	result.erc20 = erc20Addr.Hex()
+
+	if cfg.verifier == nil {
+		result.status = deployStatusDeployed
+		return result
+	}
+
+	if err := cfg.verifier.verifyERC20(cfg.gravityAddr, erc20Addr, metadata); err != nil {
+		result.status = deployStatusUnverified
+		result.err = err
+		return result
+	}
+
+	result.status = deployStatusDeployed
+	return result
+}
+
+// waitERC20Deployed waits for the receipt of a deployERC20 transaction and
+// returns the address of the deployed ERC20.
+func waitERC20Deployed(
+	ctx context.Context,
+	backend bind.DeployBackend,
+	gravityContract *wrappers.Gravity,
+	tx *ethtypes.Transaction,
+	denom string,
+) (ethcmn.Address, error) {
+	receipt, err := bind.WaitMined(ctx, backend, tx)
+	if err != nil {
+		return ethcmn.Address{}, fmt.Errorf("failed to get the transaction receipt: %w", err)
+	}
+
+	if receipt.Status != ethtypes.ReceiptStatusSuccessful {
+		return ethcmn.Address{}, errors.New("transaction reverted")
+	}
+
+	for _, log := range receipt.Logs {
+		event, err := gravityContract.ParseERC20DeployedEvent(*log)
+		if err != nil || event.CosmosDenom != denom {
+			continue
+		}
+
+		return event.TokenContract, nil
+	}
+
+	return ethcmn.Address{}, errors.New("no ERC20 deployed event in the transaction receipt")
+}
+
+func printDeployERC20Report(results []deployERC20Result) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "DENOM\tSTATUS\tERC20\tTRANSACTION")
+
+	for _, result := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.denom, result.status, orDash(result.erc20), orDash(result.tx))
+	}
+}
+
+func printDeployERC20JSON(results []deployERC20Result) error {
+	type jsonResult struct {
+		Denom  string `json:"denom"`
+		Status string `json:"status"`
+		ERC20  string `json:"erc20,omitempty"`
+		TxHash string `json:"tx_hash,omitempty"`
+		Error  string `json:"error,omitempty"`
+	}
+
+	report := make([]jsonResult, len(results))
+	for i, result := range results {
+		report[i] = jsonResult{Denom: result.denom, Status: result.status, ERC20: result.erc20, TxHash: result.tx}
+		if result.err != nil {
+			report[i].Error = result.err.Error()
+		}
+	}
+
+	return printJSON(report)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
@@ -0,0 +1,240 @@
+package peggo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/umee-network/peggo/cmd/peggo/client"
+	"github.com/umee-network/peggo/orchestrator/exporter"
+	"github.com/umee-network/peggo/orchestrator/lifecycle"
+	"github.com/umee-network/peggo/orchestrator/oracle"
+)
+
+func getExporterCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "exporter [gravity-addr]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Starts a Prometheus exporter of bridge health metrics",
+		Long: `Starts a Prometheus exporter of bridge health metrics.
+
+The exporter doesn't need any keys and never signs or relays anything; it only
+watches both chains and serves the following metrics on /metrics:
+- pending batches per token
+- confirms still missing for pending valsets and batches
+- valset nonce lag between Cosmos and the Gravity contract (checkpoint lag)
+- event nonce lag between the Gravity contract and Cosmos, and the latency of
+  Ethereum events (e.g. deposits) until they're observed on Cosmos
+- with --deposit-slo set, the deposits missing the latency target and the burn
+  rate of the deposit SLO error budget
+- with --oracle-providers set, the amount and USD value of the withdrawals
+  pending in batches per token`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			konfig, err := parseServerConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			logger, err := getLogger(cmd)
+			if err != nil {
+				return err
+			}
+
+			labels, err := parseInstanceLabels(konfig.Strings(flagInstanceLabel))
+			if err != nil {
+				return err
+			}
+			logger = withInstanceLabels(logger, labels)
+
+			if !ethcmn.IsHexAddress(args[0]) {
+				return fmt.Errorf("invalid gravity address: %s", args[0])
+			}
+			gravityAddr := ethcmn.HexToAddress(args[0])
+
+			clientCtx, err := client.NewClientContext(konfig.String(flagCosmosChainID), "", nil)
+			if err != nil {
+				return err
+			}
+
+			cosmosGRPC, err := parseURL(logger, konfig, flagCosmosGRPC)
+			if err != nil {
+				return err
+			}
+
+			daemonClient, err := client.NewCosmosClient(
+				clientCtx,
+				logger,
+				cosmosGRPC,
+				client.OptionQueryTimeout(konfig.Duration(flagCosmosQueryTimeout)),
+			)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			gRPCConn := daemonClient.QueryClient()
+			waitForService(ctx, gRPCConn)
+
+			ethRPC, err := ethclient.Dial(konfig.String(flagEthRPC))
+			if err != nil {
+				return fmt.Errorf("failed to dial Ethereum RPC node: %w", err)
+			}
+
+			gravityContract, err := getGravityContract(ethRPC, gravityAddr)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel = context.WithCancel(context.Background())
+			// listen for and trap any OS signal to gracefully shutdown and exit
+			trapSignal(cancel)
+
+			registry := prometheus.NewRegistry()
+			registerer := prometheus.WrapRegistererWith(labels, registry)
+
+			var exporterOpts []exporter.Option
+
+			if target := konfig.Duration(flagDepositSLO); target > 0 {
+				slo := exporter.DepositSLO{
+					Target:    target,
+					Objective: konfig.Float64(flagDepositSLOObjective),
+					Window:    konfig.Duration(flagDepositSLOWindow),
+				}
+				if err := slo.Validate(); err != nil {
+					return err
+				}
+
+				exporterOpts = append(exporterOpts, exporter.OptionDepositSLO(slo))
+			}
+
+			if providers := konfig.Strings(flagOracleProviders); len(providers) > 0 {
+				lifecycleMetrics, err := lifecycle.NewMetrics(registerer)
+				if err != nil {
+					return err
+				}
+
+				oracleOpts, err := oracleOptions(konfig)
+				if err != nil {
+					return err
+				}
+
+				limiters, providerKeys, err := providerLimiters(konfig)
+				if err != nil {
+					return err
+				}
+				oracleOpts = append(
+					oracleOpts,
+					oracle.OptionProviderLimiters(limiters),
+					oracle.OptionProviderKeys(providerKeys),
+				)
+
+				alertOpts, err := priceAlertOptions(konfig, logger)
+				if err != nil {
+					return err
+				}
+				oracleOpts = append(oracleOpts, alertOpts...)
+
+				o, err := oracle.New(
+					ctx,
+					logger.With().Str("module", "oracle").Logger(),
+					stringsToProviderName(providers),
+					append(
+						oracleOpts,
+						oracle.OptionRegisterer(registerer),
+						oracle.OptionLifecycleMetrics(lifecycleMetrics),
+						oracle.OptionEthCaller(ethRPC),
+					)...,
+				)
+				if err != nil {
+					return err
+				}
+				defer o.Stop()
+
+				symbolRetriever := newCoinGecko(logger, konfig, limiters, providerKeys)
+
+				exporterOpts = append(
+					exporterOpts,
+					exporter.OptionTokenPricer(exporter.NewTokenPricer(symbolRetriever, o, ethRPC)),
+				)
+			}
+
+			e, err := exporter.New(
+				logger,
+				konfig.Duration(flagExporterInterval),
+				registerer,
+				gravitytypes.NewQueryClient(gRPCConn),
+				clientCtx.InterfaceRegistry,
+				gravityContract,
+				ethRPC,
+				tmservice.NewServiceClient(gRPCConn),
+				exporterOpts...,
+			)
+			if err != nil {
+				return err
+			}
+
+			g, errCtx := errgroup.WithContext(ctx)
+
+			g.Go(func() error {
+				return e.Start(errCtx)
+			})
+
+			g.Go(func() error {
+				return serveMetrics(errCtx, konfig.String(flagExporterListenAddr), registry)
+			})
+
+			return g.Wait()
+		},
+	}
+
+	cmd.Flags().String(flagExporterListenAddr, ":9300", "Address to serve the Prometheus metrics on")
+	cmd.Flags().Duration(flagExporterInterval, 30*time.Second, "Time between metric updates")
+	cmd.Flags().Duration(flagDepositSLO, 0, "Latency deposits should be observed on Cosmos within (0 disables the SLO)")
+	cmd.Flags().Float64(flagDepositSLOObjective, 0.99, "Share of deposits that should meet the deposit SLO latency")
+	cmd.Flags().Duration(flagDepositSLOWindow, time.Hour, "Window the deposit SLO burn rate is computed over")
+	cmd.Flags().Duration(flagCosmosQueryTimeout, 30*time.Second, "Timeout for Cosmos gRPC queries (0 means no timeout)")
+	cmd.Flags().String(flagEthRPC, "http://localhost:8545", "Specify the RPC address of an Ethereum node")
+	cmd.Flags().StringSlice(flagOracleProviders, nil, "Specify the (optional) oracle providers used for USD value metrics")
+	cmd.Flags().StringSlice(flagInstanceLabel, nil, "Set (optional) key=value labels added to metrics, logs and webhooks")
+	cmd.Flags().StringSlice(flagOracleSymbolAliases, nil, "Set (optional) symbols priced as another one (e.g. WETH=ETH)")
+	cmd.Flags().String(flagDeviationThreshold, "", "Set the (optional) standard deviations from the mean above which a provider price is filtered out") //nolint: lll
+	cmd.Flags().StringSlice(flagDeviationThresholds, nil, "Set (optional) deviation thresholds per symbol (e.g. USDC=0.5)")
+	cmd.Flags().Duration(flagOracleTickInterval, oracle.DefaultTickInterval, "Time between oracle price updates")
+	cmd.Flags().Duration(flagOraclePriceMaxAge, 0, "Age after which an oracle price is refused as stale (0 disables it)")
+	cmd.Flags().Duration(flagOracleInterpolationGap, time.Minute, "Time an unreported oracle price is held for")
+	cmd.Flags().String(flagOracleAggregation, oracle.AggregationTVWAP, "Oracle price aggregation: tvwap, vwap or median")
+	cmd.Flags().Int(flagOracleComputeWorkers, oracle.DefaultComputeWorkers, "Max number of oracle prices computed at once")
+	cmd.Flags().Duration(flagOracleFetchTimeout, oracle.DefaultFetchTimeout, "Time the oracle waits for each provider (0 waits for all)") //nolint: lll
+	cmd.Flags().Int(flagOracleBreakerFailures, 5, "Failed fetches in a row sidelining an oracle provider (0 disables it)")
+	cmd.Flags().Duration(flagOracleBreakerBackoff, 30*time.Second, "Time a failing oracle provider is first sidelined for")
+	cmd.Flags().Duration(flagOracleBreakerMaxBackoff, 10*time.Minute, "Max time a failing oracle provider is sidelined")
+	cmd.Flags().String(flagOraclePairsSchedule, "@every 24h", "Schedule (cron or @every) of oracle pair reloads")
+	cmd.Flags().String(flagOracleReconcileSchedule, "@every 5m", "Schedule (cron or @every) of oracle subscription checks")
+	cmd.Flags().Bool(flagOracleCandleBackfill, true, "Backfill the candles of new oracle pairs over REST")
+	cmd.Flags().Duration(flagOracleCandleFreshness, time.Minute, "Half-life of the TVWAP weight of oracle candles over a minute old (0 disables it)") //nolint: lll
+	cmd.Flags().String(flagOracleAlertWebhook, "", "Set an (optional) URL to POST to when oracle prices move or disappear")
+	cmd.Flags().String(flagOracleAlertMove, "0.1", "Relative oracle price move between two ticks raising an alert")
+	cmd.Flags().StringSlice(flagOracleProviderWeights, nil, "Set (optional) oracle provider weights (e.g. mexc=0.3)")
+	cmd.Flags().StringSlice(flagOracleProviderLimits, nil, "Set (optional) oracle provider and CoinGecko rate limits in requests per second (e.g. coingecko=0.5:5)") //nolint: lll
+	cmd.Flags().StringSlice(flagOracleProviderKeys, nil, "Set (optional) provider API keys (e.g. coingecko=CG-xxx)")
+	cmd.Flags().String(flagOracleOsmosisGRPC, "", "Set the (optional) Osmosis gRPC address of the osmosispool provider")
+	cmd.Flags().StringSlice(flagOracleOsmosisPools, nil, "Set the Osmosis pools of the osmosispool provider (e.g. UMEE/USD=1110:uumee-ibc:uusdc-ibc)") //nolint: lll
+	cmd.Flags().StringSlice(flagOracleUniswapV3Pools, nil, "Set the Uniswap v3 pools of the uniswapv3 provider (e.g. WETH/USD=0x88e6...:0xc02a...)")   //nolint: lll
+	cmd.Flags().String(flagOracleStaticFile, "", "Set the .json or .toml prices file of the staticfile provider")
+	cmd.Flags().Bool(flagOracleStaticWatch, false, "Reload the prices file of the staticfile provider whenever it changes")
+	cmd.Flags().String(flagOracleDepegThreshold, "0.02", "Filter out the prices quoted in a stablecoin deviating from 1 USD by more than this (0 disables it)") //nolint: lll
+	cmd.Flags().String(flagCoinGeckoAPI, "https://api.coingecko.com/api/v3", "Specify the coingecko API endpoint")
+	cmd.Flags().AddFlagSet(cosmosFlagSet())
+
+	return cmd
+}
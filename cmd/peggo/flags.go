@@ -3,13 +3,19 @@ package peggo
 import (
 	"fmt"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	"github.com/knadh/koanf"
 	"github.com/rs/zerolog"
 	"github.com/spf13/pflag"
 
+	"github.com/umee-network/peggo/orchestrator/ethereum/gravity"
+	"github.com/umee-network/peggo/orchestrator/store"
+
 	umeeparams "github.com/umee-network/umee/v3/app/params"
 )
 
@@ -32,6 +38,7 @@ const (
 	flagCosmosPK                = "cosmos-pk"
 	flagCosmosUseLedger         = "cosmos-use-ledger"
 	flagCosmosFeeGranter        = "cosmos-fee-granter"
+	flagCosmosFeeTokens         = "cosmos-fee-tokens"
 	flagCosmosMsgsPerTx         = "cosmos-msgs-per-tx"
 	flagEthKeystoreDir          = "eth-keystore-dir"
 	flagEthFrom                 = "eth-from"
@@ -49,6 +56,7 @@ const (
 	flagEthGasPrice             = "eth-gas-price"
 	flagEthGasLimit             = "eth-gas-limit"
 	flagAutoApprove             = "auto-approve"
+	flagApprovalMode            = "approval-mode"
 	flagEthBlocksPerLoop        = "eth-blocks-per-loop"
 	flagEthPendingTXWait        = "eth-pending-tx-wait"
 	flagProfitMultiplier        = "profit-multiplier"
@@ -59,8 +67,176 @@ const (
 	flagGcpLogProjectName       = "gcp-log-project-name"
 	flagGcpLogMoniker           = "gcp-log-moniker"
 	flagGcpLogLevel             = "gcp-log-level"
+	flagHome                    = "home"
+	flagEthMaxInFlightTxs       = "eth-max-inflight-txs"
+	flagReason                  = "reason"
+	flagPriceDeviationMax       = "relayer-price-deviation-max"
+	flagPriceDeviationWindow    = "relayer-price-deviation-window"
+	flagPriceDeviationMargin    = "relayer-price-deviation-margin"
+	flagMissingPriceWait        = "relayer-missing-price-wait"
+	flagMissingPriceFallback    = "relayer-missing-price-fallback"
+	flagHeartbeatEndpoint       = "heartbeat-endpoint"
+	flagHeartbeatInterval       = "heartbeat-interval"
+	flagHeartbeatMoniker        = "heartbeat-moniker"
+	flagEthReadTimeout          = "eth-read-timeout"
+	flagEthBroadcastTimeout     = "eth-broadcast-timeout"
+	flagCosmosQueryTimeout      = "cosmos-query-timeout"
+	flagCosmosBroadcastTimeout  = "cosmos-broadcast-timeout"
+	flagInvariantInterval       = "invariant-check-interval"
+	flagInvariantTolerance      = "invariant-tolerance"
+	flagDenylistFile            = "relayer-denylist-file"
+	flagDenylistURL             = "relayer-denylist-url"
+	flagDenylistRefresh         = "relayer-denylist-refresh"
+	flagFromHeight              = "from-height"
+	flagToHeight                = "to-height"
+	flagConfig                  = "config"
+	flagOutput                  = "output"
+	flagTopupThreshold          = "eth-topup-threshold"
+	flagTopupInterval           = "eth-topup-interval"
+	flagTopupCooldown           = "eth-topup-cooldown"
+	flagTopupWebhook            = "eth-topup-webhook"
+	flagTopupSendToEth          = "eth-topup-send-to-eth"
+	flagTopupBridgeFee          = "eth-topup-bridge-fee"
+	flagBreakerMaxFailures      = "breaker-max-failures"
+	flagBreakerBackoff          = "breaker-backoff"
+	flagBreakerMaxBackoff       = "breaker-max-backoff"
+	flagExporterListenAddr      = "listen-addr"
+	flagExporterInterval        = "interval"
+	flagDepositSLO              = "deposit-slo"
+	flagDepositSLOObjective     = "deposit-slo-objective"
+	flagDepositSLOWindow        = "deposit-slo-window"
+	flagSignerSocket            = "signer-socket"
+	flagSignerToken             = "signer-token"
+	flagSignerGravityID         = "gravity-id"
+	flagBatchDustFeeUSD         = "batch-dust-fee-usd"
+	flagOracleWarmupTimeout     = "oracle-warmup-timeout"
+	flagGasAssetSymbol          = "gas-asset-symbol"
+	flagGasAssetCoinID          = "gas-asset-coin-id"
+	flagDenomsFile              = "denoms-file"
+	flagReceiptTimeout          = "receipt-timeout"
+	flagVerifyExplorerURL       = "verify-explorer-url"
+	flagVerifyExplorerKey       = "verify-explorer-api-key"
+	flagVerifySourceFile        = "verify-source-file"
+	flagVerifyCompiler          = "verify-compiler-version"
+	flagVerifyDeployment        = "verify"
+	flagSkipEventsBeforeNonce   = "skip-events-before-nonce"
+	flagSkipEventsConfirm       = "confirm-skip-events-before-nonce"
+	flagCosmosReferenceRPC      = "cosmos-reference-rpc"
+	flagCosmosMaxHeightLag      = "cosmos-max-height-lag"
+	flagHeight                  = "height"
+	flagNonce                   = "nonce"
+	flagExport                  = "export"
+	flagFromFile                = "from-file"
+	flagConfirmScheme           = "confirm-signature-scheme"
+	flagEIP712Name              = "eip712-domain-name"
+	flagEIP712Version           = "eip712-domain-version"
+	flagEIP712ChainID           = "eip712-domain-chain-id"
+	flagEIP712Contract          = "eip712-domain-verifying-contract"
+	flagEIP712Salt              = "eip712-domain-salt"
+	flagEIP712ValsetType        = "eip712-valset-type"
+	flagEIP712BatchType         = "eip712-batch-type"
+	flagClaimsPipelineDepth     = "cosmos-claims-pipeline-depth"
+	flagClaimsGasAdjustment     = "cosmos-claims-gas-adjustment"
+	flagMetricsListenAddr       = "metrics-listen-addr"
+	flagEthHeaderCacheSize      = "eth-header-cache-size"
+	flagEthReceiptCacheSize     = "eth-receipt-cache-size"
+	flagGravityVersion          = "gravity-contract-version"
+	flagRelayOnlyWhenPivotal    = "relayer-only-when-pivotal"
+	flagRelayPivotalTimeout     = "relayer-pivotal-timeout"
+	flagRelayJitter             = "relayer-jitter"
+	flagPolicyMaxSpend          = "eth-policy-max-spend-per-hour"
+	flagPolicyMaxTxs            = "eth-policy-max-txs-per-hour"
+	flagPolicyContracts         = "eth-policy-allowed-contracts"
+	flagPolicyWebhook           = "eth-policy-webhook"
+	flagDryRun                  = "dry-run"
+	flagOracleSymbolAliases     = "oracle-symbol-aliases"
+	flagSentryDSN               = "sentry-dsn"
+	flagRelayRewardAddresses    = "relayer-reward-addresses"
+	flagDeviationThreshold      = "oracle-deviation-threshold"
+	flagDeviationThresholds     = "oracle-deviation-thresholds"
+	flagGasPriceSmoothing       = "relayer-gas-price-smoothing"
+	flagOracleTickInterval      = "oracle-tick-interval"
+	flagOraclePriceMaxAge       = "oracle-price-max-age"
+	flagEthAccessLists          = "eth-access-lists"
+	flagMaxValueConcentration   = "relayer-max-value-concentration"
+	flagOracleAggregation       = "oracle-aggregation"
+	flagInstanceLabel           = "instance-label"
+	flagOracleProviderWeights   = "oracle-provider-weights"
+	flagMetaTxEndpoint          = "relayer-meta-tx-endpoint"
+	flagMetaTxAPIKey            = "relayer-meta-tx-api-key"
+	flagEthRPCExtra             = "eth-rpc-extra"
+	flagEthRPCProbeInterval     = "eth-rpc-probe-interval"
+	flagOracleOsmosisGRPC       = "oracle-osmosis-grpc"
+	flagOracleOsmosisPools      = "oracle-osmosis-pools"
+	flagOracleComputeWorkers    = "oracle-compute-workers"
+	flagOracleFetchTimeout      = "oracle-fetch-timeout"
+	flagOracleUniswapV3Pools    = "oracle-uniswap-v3-pools"
+	flagOracleWatchdogInterval  = "oracle-watchdog-interval"
+	flagOracleWatchdogTolerance = "oracle-watchdog-tolerance"
+	flagOracleWatchdogSustain   = "oracle-watchdog-sustain"
+	flagEthTSSGRPC              = "eth-tss-grpc"
+	flagEthTSSKeyID             = "eth-tss-key-id"
+	flagEthTSSCA                = "eth-tss-tls-ca"
+	flagOracleStaticFile        = "oracle-static-prices-file"
+	flagOracleStaticWatch       = "oracle-static-prices-watch"
+	flagEthStartupScanWorkers   = "eth-startup-scan-workers"
+	flagEthStartupScanBlocks    = "eth-startup-scan-blocks"
+	flagWindows                 = "windows"
+	flagBlocks                  = "blocks"
+	flagOracleDepegThreshold    = "oracle-stablecoin-depeg-threshold"
+	flagSafeModeTimeout         = "safe-mode-timeout"
+	flagSkipSafeMode            = "skip-safe-mode"
+	flagAnalyticsDir            = "analytics-dir"
+	flagAnalyticsFormat         = "analytics-format"
+	flagAnalyticsWebhook        = "analytics-webhook"
+	flagAnalyticsInterval       = "analytics-interval"
+	flagAnalyticsStartHeight    = "analytics-start-height"
+	flagAnalyticsConfirmations  = "analytics-confirmations"
+	flagOracleListenAddr        = "oracle-listen-addr"
+	flagAdminListenAddr         = "admin-listen-addr"
+	flagAdminToken              = "admin-token"
+	flagPriceSmoothing          = "relayer-price-smoothing"
+	flagOracleSmoothingAlpha    = "oracle-smoothing-alpha"
+	flagMigrationBlock          = "gravity-migration-block"
+	flagMigrationAddress        = "gravity-migration-address"
+	flagMigrationLastEventNonce = "gravity-migration-last-event-nonce"
+	flagOracleBreakerFailures   = "oracle-breaker-max-failures"
+	flagOracleBreakerBackoff    = "oracle-breaker-backoff"
+	flagOracleBreakerMaxBackoff = "oracle-breaker-max-backoff"
+	flagBatchTargetSize         = "batch-target-size"
+	flagBatchMaxWait            = "batch-max-wait"
+	flagOraclePairsSchedule     = "oracle-pairs-reload-schedule"
+	flagOracleReconcileSchedule = "oracle-reconcile-schedule"
+	flagTopupSchedule           = "eth-topup-schedule"
+	flagAnalyticsSchedule       = "analytics-schedule"
+	flagRemoteConfigURL         = "remote-config-url"
+	flagRemoteConfigSigURL      = "remote-config-signature-url"
+	flagRemoteConfigPubKey      = "remote-config-pubkey"
+	flagRemoteConfigRefresh     = "remote-config-refresh"
+	flagCohortBlocks            = "relayer-cohort-blocks"
+	flagCohortInterval          = "relayer-cohort-interval"
+	flagOracleCandleBackfill    = "oracle-candle-backfill"
+	flagOracleInterpolationGap  = "oracle-interpolation-gap"
+	flagSchemaOut               = "out"
+	flagOracleAlertWebhook      = "oracle-alert-webhook"
+	flagOracleAlertMove         = "oracle-alert-move-threshold"
+	flagConfirmSync             = "confirm-sync"
+	flagOracleProviderLimits    = "oracle-provider-limits"
+	flagOracleProviderKeys      = "oracle-provider-keys"
+	flagOracleCandleFreshness   = "oracle-candle-freshness-half-life"
+	flagRelayGracePeriod        = "relayer-coordination-grace"
 )
 
+// defaultHome returns the default directory used to persist local peggo state.
+func defaultHome() string {
+	userHome, err := os.UserHomeDir()
+	if err != nil {
+		return ".peggo"
+	}
+
+	return filepath.Join(userHome, ".peggo")
+}
+
 func cosmosFlagSet() *pflag.FlagSet {
 	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
 
@@ -100,6 +276,23 @@ func ethereumKeyOptsFlagSet() *pflag.FlagSet {
 	fs.String(flagEthPassphrase, "", "Specify the passphrase to unlock the private key from armor; If empty then STDIN is used")              //nolint: lll
 
 	fs.Bool(flagEthUseLedger, false, "Use the Ethereum app on hardware ledger to sign transactions")
+	fs.String(flagEthTSSGRPC, "", "Set an (optional) gRPC address of a threshold signing service holding the Ethereum key")
+	fs.String(flagEthTSSKeyID, "", "Specify the ID of the Ethereum key on the threshold signing service")
+	fs.String(flagEthTSSCA, "", "Set an (optional) CA certificate to reach the threshold signing service over TLS")
+	return fs
+}
+
+func confirmSchemeFlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+
+	fs.String(flagConfirmScheme, gravity.SignatureSchemePersonal, "Specify how valset and batch confirms are signed (personal|eip712)") //nolint: lll
+	fs.String(flagEIP712Name, "", "Specify the EIP-712 domain name of typed confirms")
+	fs.String(flagEIP712Version, "", "Specify the EIP-712 domain version of typed confirms")
+	fs.Int64(flagEIP712ChainID, 0, "Specify the (optional) EIP-712 domain chain ID of typed confirms")
+	fs.String(flagEIP712Contract, "", "Specify the (optional) EIP-712 domain verifying contract of typed confirms")
+	fs.String(flagEIP712Salt, "", "Specify the (optional) EIP-712 domain salt (32 bytes hex) of typed confirms")
+	fs.String(flagEIP712ValsetType, gravity.DefaultValsetConfirmType, "Specify the EIP-712 struct type of typed valset confirms") //nolint: lll
+	fs.String(flagEIP712BatchType, gravity.DefaultBatchConfirmType, "Specify the EIP-712 struct type of typed batch confirms")    //nolint: lll
 	return fs
 }
 
@@ -109,6 +302,8 @@ func ethereumOptsFlagSet() *pflag.FlagSet {
 	fs.String(flagEthRPC, "http://localhost:8545", "Specify the RPC address of an Ethereum node")
 	fs.Float64(flagEthGasAdjustment, float64(1.3), "Specify a gas price adjustment for Ethereum transactions")
 	fs.Float64(flagEthGasLimitAdjustment, float64(1.2), "Specify a gas limit adjustment for Ethereum transactions")
+	fs.Duration(flagEthReadTimeout, 60*time.Second, "Timeout for Ethereum RPC reads (0 means no timeout)")
+	fs.Duration(flagEthBroadcastTimeout, 10*time.Second, "Timeout for Ethereum tx broadcasts (0 means no timeout)")
 
 	return fs
 }
@@ -137,3 +332,23 @@ func parseURL(logger zerolog.Logger, konfig *koanf.Koanf, flag string) (string,
 	}
 	return endpoint, nil
 }
+
+// openStore opens the local state store located in the home directory and
+// migrates it to the schema of this release.
+func openStore(konfig *koanf.Koanf) (*store.Store, error) {
+	s, err := store.New(konfig.String(flagHome))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open local state store: %w", err)
+	}
+
+	results, err := store.Migrate(s, store.Migrations, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate local state store: %w", err)
+	}
+
+	for _, r := range results {
+		fmt.Fprintf(os.Stderr, "Migrated local state to schema version %d: %s\n", r.Version, r.Description)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,16 @@
+package peggo
+
+import (
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+)
+
+// gravityCodec decodes the messages of the Gravity module from the proto3 JSON
+// its CLI and REST return, where the 64-bit integers are strings (e.g.
+// "nonce": "5"), which encoding/json rejects.
+var gravityCodec = codec.NewProtoCodec(codectypes.NewInterfaceRegistry())
+
+// unmarshalGravityJSON decodes a message of the Gravity module from its JSON.
+func unmarshalGravityJSON(bz []byte, msg codec.ProtoMarshaler) error {
+	return gravityCodec.UnmarshalJSON(bz, msg)
+}
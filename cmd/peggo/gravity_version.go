@@ -0,0 +1,85 @@
+package peggo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/knadh/koanf"
+	"github.com/spf13/pflag"
+
+	"github.com/umee-network/peggo/solwrappers/versions"
+)
+
+func gravityVersionFlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+
+	fs.String(
+		flagGravityVersion,
+		versions.Auto,
+		fmt.Sprintf("The Gravity.sol version of the deployment (%s|%s)", versions.Auto, strings.Join(versions.Names(), "|")),
+	)
+
+	return fs
+}
+
+// resolveGravityVersion returns the configured Gravity.sol version or, if set
+// to auto, the one detected from the code deployed at gravityAddr.
+func resolveGravityVersion(
+	konfig *koanf.Koanf,
+	caller bind.ContractCaller,
+	gravityAddr ethcmn.Address,
+) (versions.Version, error) {
+	name := konfig.String(flagGravityVersion)
+	if name != versions.Auto {
+		return versions.Get(name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	code, err := caller.CodeAt(ctx, gravityAddr, nil)
+	if err != nil {
+		return versions.Version{}, fmt.Errorf("failed to get the Gravity contract code: %w", err)
+	}
+
+	version, err := versions.Detect(code)
+	if err != nil {
+		return versions.Version{}, fmt.Errorf("failed to detect the Gravity.sol version: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Detected Gravity.sol version: %s\n", version.Name)
+
+	return version, nil
+}
+
+// validateMetaTx checks that relaying batches through a meta-transaction service
+// makes sense with the given Gravity.sol version. If it pays the batch fees to
+// the sender of the submitBatch tx, the service's account gets them: the fees
+// can't be checked against the reward addresses, nor be counted as our revenue
+// in the batch profitability, so only relaying regardless of fees is allowed.
+func validateMetaTx(version versions.Version, rewardAddresses []string, profitMultiplier float64) error {
+	if version.DirectedRewards() {
+		return nil
+	}
+
+	if len(rewardAddresses) > 0 {
+		return fmt.Errorf(
+			"--%s can't be set with --%s, as Gravity.sol %s pays the batch fees to the relay service",
+			flagRelayRewardAddresses, flagMetaTxEndpoint, version.Name,
+		)
+	}
+
+	if profitMultiplier != 0 {
+		return fmt.Errorf(
+			"--%s must be 0 with --%s, as Gravity.sol %s pays the batch fees to the relay service",
+			flagProfitMultiplier, flagMetaTxEndpoint, version.Name,
+		)
+	}
+
+	return nil
+}
@@ -2,13 +2,13 @@ package peggo
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"fmt"
 	"io"
 	"math/big"
 	"os"
 	"path/filepath"
-	"strings"
 	"syscall"
 
 	"github.com/cosmos/cosmos-sdk/codec"
@@ -27,7 +27,9 @@ import (
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	"github.com/umee-network/peggo/orchestrator/ethereum/keystore"
+	"github.com/umee-network/peggo/orchestrator/signer"
 	"golang.org/x/term"
+	"google.golang.org/grpc/credentials"
 
 	umeeapp "github.com/umee-network/umee/v3/app"
 )
@@ -53,10 +55,14 @@ func initCosmosKeyring(konfig *koanf.Koanf) (sdk.AccAddress, keyring.Keyring, er
 			return emptyCosmosAddress, nil, errors.New("cannot use ledger with raw private key")
 		}
 
-		pkBz, err := hexToBytes(cosmosPK)
+		pkHex := secretValue(konfig, flagCosmosPK)
+		pkBz, err := decodeHexSecret(pkHex)
+		zeroBytes(pkHex)
 		if err != nil {
 			return emptyCosmosAddress, nil, fmt.Errorf("failed to hex decode cosmos private key: %w", err)
 		}
+		// the keyring keeps its own (armored) copy of the key
+		defer zeroBytes(pkBz)
 
 		cosmosAccPk := &secp256k1.PrivKey{
 			Key: pkBz,
@@ -100,7 +106,9 @@ func initCosmosKeyring(konfig *koanf.Koanf) (sdk.AccAddress, keyring.Keyring, er
 
 		var passReader io.Reader
 		if len(cosmosPassphrase) > 0 {
-			passReader = newPassReader(cosmosPassphrase)
+			// the keyring may ask for the passphrase again, so the reader
+			// keeps it for the life of the process
+			passReader = newPassReader(secretValue(konfig, flagCosmosFromPassphrase))
 		} else {
 			passReader = os.Stdin
 		}
@@ -179,6 +187,26 @@ func initCosmosKeyring(konfig *koanf.Koanf) (sdk.AccAddress, keyring.Keyring, er
 	}
 }
 
+// validateThresholdSigner checks the threshold signer settings without dialing
+// it: only the commands signing with it connect to it.
+func validateThresholdSigner(konfig *koanf.Koanf) error {
+	if konfig.String(flagEthTSSKeyID) == "" {
+		return fmt.Errorf("--%s is required with --%s", flagEthTSSKeyID, flagEthTSSGRPC)
+	}
+
+	if caFile := konfig.String(flagEthTSSCA); caFile != "" {
+		if _, err := credentials.NewClientTLSFromFile(caFile, ""); err != nil {
+			return fmt.Errorf("invalid --%s: %w", flagEthTSSCA, err)
+		}
+	}
+
+	if from := konfig.String(flagEthFrom); from != "" && !ethcmn.IsHexAddress(from) {
+		return fmt.Errorf("invalid --%s address %q", flagEthFrom, from)
+	}
+
+	return nil
+}
+
 func initEthereumAccountsManager(
 	logger zerolog.Logger,
 	ethChainID uint64,
@@ -187,12 +215,14 @@ func initEthereumAccountsManager(
 	ethcmn.Address,
 	bind.SignerFn,
 	keystore.PersonalSignFn,
+	keystore.TypedDataSignFn,
 	error,
 ) {
 	var (
 		signerFn          bind.SignerFn
 		ethKeyFromAddress ethcmn.Address
 		personalSignFn    keystore.PersonalSignFn
+		typedDataSignFn   keystore.TypedDataSignFn
 	)
 
 	ethUseLedger := konfig.Bool(flagEthUseLedger)
@@ -200,21 +230,49 @@ func initEthereumAccountsManager(
 	ethPrivKey := konfig.String(flagEthPK)
 	ethKeystoreDir := konfig.String(flagEthKeystoreDir)
 	ethPassphrase := konfig.String(flagEthPassphrase)
+	ethTSSGRPC := konfig.String(flagEthTSSGRPC)
 
 	switch {
+	case len(ethTSSGRPC) > 0:
+		tssClient, err := signer.DialThreshold(
+			context.Background(),
+			ethTSSGRPC,
+			konfig.String(flagEthTSSCA),
+			konfig.String(flagEthTSSKeyID),
+			signerTimeout,
+		)
+		if err != nil {
+			return emptyEthAddress, nil, nil, nil, fmt.Errorf("failed to initialize threshold signer: %w", err)
+		}
+
+		if len(ethKeyFrom) > 0 && ethcmn.HexToAddress(ethKeyFrom) != tssClient.Address() {
+			return emptyEthAddress, nil, nil, nil, errors.New("from address does not match address of the threshold key")
+		}
+
+		logger.Info().
+			Str("address", tssClient.Address().Hex()).
+			Str("endpoint", ethTSSGRPC).
+			Msg("using threshold signer for the Ethereum key")
+
+		return tssClient.Address(),
+			tssClient.SignerFn(ethChainID),
+			tssClient.PersonalSignFn(),
+			tssClient.TypedDataSignFn(),
+			nil
+
 	case ethUseLedger:
 		if len(ethKeyFrom) == 0 {
-			return emptyEthAddress, nil, nil, errors.New("cannot use Ledger without from address specified")
+			return emptyEthAddress, nil, nil, nil, errors.New("cannot use Ledger without from address specified")
 		}
 
 		ethKeyFromAddress = ethcmn.HexToAddress(ethKeyFrom)
 		if ethKeyFromAddress == (ethcmn.Address{}) {
-			return emptyEthAddress, nil, nil, fmt.Errorf("failed to parse Ethereum from address %s", ethKeyFrom)
+			return emptyEthAddress, nil, nil, nil, fmt.Errorf("failed to parse Ethereum from address %s", ethKeyFrom)
 		}
 
 		ledgerBackend, err := usbwallet.NewLedgerHub()
 		if err != nil {
-			return emptyEthAddress, nil, nil, fmt.Errorf("failed to connect with Ethereum app on Ledger device")
+			return emptyEthAddress, nil, nil, nil, fmt.Errorf("failed to connect with Ethereum app on Ledger device")
 		}
 
 		signerFn = func(from ethcmn.Address, tx *ethtypes.Transaction) (*ethtypes.Transaction, error) {
@@ -271,125 +329,188 @@ func initEthereumAccountsManager(
 			return nil, errors.Errorf("account %s not found on Ledger", from.String())
 		}
 
-		return ethKeyFromAddress, signerFn, personalSignFn, nil
+		typedDataSignFn = func(from ethcmn.Address, typedData []byte) (sig []byte, err error) {
+			acc := accounts.Account{
+				Address: from,
+			}
+
+			wallets := ledgerBackend.Wallets()
+			for _, w := range wallets {
+				if err := w.Open(""); err != nil {
+					return nil, fmt.Errorf("failed to connect to wallet on Ledger device: %w", err)
+				}
+
+				if !w.Contains(acc) {
+					if err := w.Close(); err != nil {
+						return nil, fmt.Errorf("failedt to disconnect the wallet on Ledger device: %w", err)
+					}
+
+					continue
+				}
+
+				sig, err = w.SignData(acc, accounts.MimetypeTypedData, typedData)
+				_ = w.Close()
+				return sig, err
+			}
+
+			return nil, errors.Errorf("account %s not found on Ledger", from.String())
+		}
+
+		return ethKeyFromAddress, signerFn, personalSignFn, typedDataSignFn, nil
 
 	case len(ethPrivKey) > 0:
-		ethPk, err := ethcrypto.ToECDSA(ethcmn.FromHex(ethPrivKey))
+		ethPk, err := parseEthPrivKey(konfig)
 		if err != nil {
-			return emptyEthAddress, nil, nil, fmt.Errorf("failed to hex-decode Ethereum ECDSA Private Key: %w", err)
+			return emptyEthAddress, nil, nil, nil, fmt.Errorf("failed to hex-decode Ethereum ECDSA Private Key: %w", err)
 		}
 
 		ethAddressFromPk := ethcrypto.PubkeyToAddress(ethPk.PublicKey)
 
 		if len(ethKeyFrom) > 0 {
 			if !ethcmn.IsHexAddress(ethKeyFrom) {
-				return emptyEthAddress, nil, nil, fmt.Errorf("invalid eth-from address: %s", ethKeyFrom)
+				return emptyEthAddress, nil, nil, nil, fmt.Errorf("invalid eth-from address: %s", ethKeyFrom)
 			}
 
 			addr := ethcmn.HexToAddress(ethKeyFrom)
 			if addr == (ethcmn.Address{}) {
-				return emptyEthAddress, nil, nil, fmt.Errorf("failed to parse Ethereum from address: %s", ethKeyFrom)
+				return emptyEthAddress, nil, nil, nil, fmt.Errorf("failed to parse Ethereum from address: %s", ethKeyFrom)
 			} else if addr != ethAddressFromPk {
-				return emptyEthAddress, nil, nil, errors.New("from address does not match address from Ethereum ECDSA private key")
+				return emptyEthAddress, nil, nil, nil, errors.New(
+					"from address does not match address from Ethereum ECDSA private key",
+				)
 			}
 		}
 
 		txOpts, err := bind.NewKeyedTransactorWithChainID(ethPk, new(big.Int).SetUint64(ethChainID))
 		if err != nil {
-			return emptyEthAddress, nil, nil, fmt.Errorf("failed to init NewKeyedTransactorWithChainID: %w", err)
+			return emptyEthAddress, nil, nil, nil, fmt.Errorf("failed to init NewKeyedTransactorWithChainID: %w", err)
 		}
 
 		personalSignFn, err := keystore.PrivateKeyPersonalSignFn(ethPk)
 		if err != nil {
-			return emptyEthAddress, nil, nil, fmt.Errorf("failed to init PrivateKeyPersonalSignFn: %w", err)
+			return emptyEthAddress, nil, nil, nil, fmt.Errorf("failed to init PrivateKeyPersonalSignFn: %w", err)
 		}
 
-		return txOpts.From, txOpts.Signer, personalSignFn, nil
+		typedDataSignFn, err := keystore.PrivateKeyTypedDataSignFn(ethPk)
+		if err != nil {
+			return emptyEthAddress, nil, nil, nil, fmt.Errorf("failed to init PrivateKeyTypedDataSignFn: %w", err)
+		}
+
+		return txOpts.From, txOpts.Signer, personalSignFn, typedDataSignFn, nil
 
 	case len(ethKeystoreDir) > 0:
 		if len(ethKeyFrom) == 0 {
-			return emptyEthAddress, nil, nil, errors.New("cannot use Ethereum keystore without from address specified")
+			return emptyEthAddress, nil, nil, nil, errors.New("cannot use Ethereum keystore without from address specified")
 		}
 
 		if !ethcmn.IsHexAddress(ethKeyFrom) {
-			return emptyEthAddress, nil, nil, fmt.Errorf("invalid eth-from address: %s", ethKeyFrom)
+			return emptyEthAddress, nil, nil, nil, fmt.Errorf("invalid eth-from address: %s", ethKeyFrom)
 		}
 
 		ethKeyFromAddress = ethcmn.HexToAddress(ethKeyFrom)
 		if ethKeyFromAddress == (ethcmn.Address{}) {
-			return emptyEthAddress, nil, nil, fmt.Errorf("failed to parse Ethereum from address: %s", ethKeyFrom)
+			return emptyEthAddress, nil, nil, nil, fmt.Errorf("failed to parse Ethereum from address: %s", ethKeyFrom)
 		}
 
 		if info, err := os.Stat(ethKeystoreDir); err != nil || !info.IsDir() {
-			return emptyEthAddress, nil, nil, fmt.Errorf("failed to locate Ethereum keystore dir: %w", err)
+			return emptyEthAddress, nil, nil, nil, fmt.Errorf("failed to locate Ethereum keystore dir: %w", err)
 		}
 
 		ks, err := keystore.New(logger, ethKeystoreDir)
 		if err != nil {
-			return emptyEthAddress, nil, nil, fmt.Errorf("failed to load Ethereum keystore: %w", err)
+			return emptyEthAddress, nil, nil, nil, fmt.Errorf("failed to load Ethereum keystore: %w", err)
 		}
 
-		var pass string
+		var passphrase []byte
 		if len(ethPassphrase) > 0 {
-			pass = ethPassphrase
+			passphrase = secretValue(konfig, flagEthPassphrase)
 		} else {
-			pass, err = ethPassFromStdin()
+			passphrase, err = ethPassFromStdin()
 			if err != nil {
-				return emptyEthAddress, nil, nil, err
+				return emptyEthAddress, nil, nil, nil, err
 			}
 		}
 
-		signerFn, err := ks.SignerFn(ethChainID, ethKeyFromAddress, pass)
+		// the passphrase is only copied into a string when it's handed to the
+		// keystore, once
+		ethPk, err := ks.PrivateKey(ethKeyFromAddress, string(passphrase))
+		zeroBytes(passphrase)
 		if err != nil {
-			return emptyEthAddress, nil, nil, fmt.Errorf("failed to load key for %s: %w", ethKeyFromAddress, err)
+			return emptyEthAddress, nil, nil, nil, fmt.Errorf("failed to load key for %s: %w", ethKeyFromAddress, err)
 		}
 
-		personalSignFn, err := ks.PersonalSignFn(ethKeyFromAddress, pass)
+		if ethcrypto.PubkeyToAddress(ethPk.PublicKey) != ethKeyFromAddress {
+			return emptyEthAddress, nil, nil, nil, fmt.Errorf("failed to load key for %s: account key address mismatch",
+				ethKeyFromAddress)
+		}
+
+		txOpts, err := bind.NewKeyedTransactorWithChainID(ethPk, new(big.Int).SetUint64(ethChainID))
+		if err != nil {
+			return emptyEthAddress, nil, nil, nil, fmt.Errorf("failed to init NewKeyedTransactorWithChainID: %w", err)
+		}
+
+		personalSignFn, err := keystore.PrivateKeyPersonalSignFn(ethPk)
 		if err != nil {
-			return emptyEthAddress, nil, nil, fmt.Errorf("failed to load key for %s: %w", ethKeyFromAddress, err)
+			return emptyEthAddress, nil, nil, nil, fmt.Errorf("failed to init PrivateKeyPersonalSignFn: %w", err)
 		}
 
-		return ethKeyFromAddress, signerFn, personalSignFn, nil
+		typedDataSignFn, err := keystore.PrivateKeyTypedDataSignFn(ethPk)
+		if err != nil {
+			return emptyEthAddress, nil, nil, nil, fmt.Errorf("failed to init PrivateKeyTypedDataSignFn: %w", err)
+		}
+
+		return ethKeyFromAddress, txOpts.Signer, personalSignFn, typedDataSignFn, nil
 
 	default:
-		return emptyEthAddress, nil, nil, errors.New("insufficient ethereum key details provided")
+		return emptyEthAddress, nil, nil, nil, errors.New("insufficient ethereum key details provided")
 	}
 }
 
-func ethPassFromStdin() (string, error) {
+func ethPassFromStdin() ([]byte, error) {
 	fmt.Fprintln(os.Stderr, "Passphrase for Ethereum account: ")
 	bytePassword, err := term.ReadPassword(syscall.Stdin)
 	if err != nil {
-		return "", fmt.Errorf("failed to read password from STDIN: %w", err)
+		return nil, fmt.Errorf("failed to read password from STDIN: %w", err)
 	}
 
-	password := string(bytePassword)
-	return strings.TrimSpace(password), nil
+	return bytes.TrimSpace(bytePassword), nil
 }
 
 var _ io.Reader = (*passReader)(nil)
 
+// passReader answers every passphrase prompt of the keyring with pass, without
+// copying it into a string.
 type passReader struct {
-	pass string
-	buf  *bytes.Buffer
+	pass []byte
+	off  int // offset in the current line, the passphrase followed by a newline
 }
 
-func newPassReader(pass string) io.Reader {
-	return &passReader{
-		pass: pass,
-		buf:  new(bytes.Buffer),
-	}
+func newPassReader(pass []byte) io.Reader {
+	return &passReader{pass: pass}
 }
 
 func (r *passReader) Read(p []byte) (n int, err error) {
-	n, err = r.buf.Read(p)
-	if err == io.EOF || n == 0 {
-		r.buf.WriteString(r.pass + "\n")
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	// the line is repeated for each prompt
+	if r.off > len(r.pass) {
+		r.off = 0
+	}
+
+	if r.off < len(r.pass) {
+		n = copy(p, r.pass[r.off:])
+		r.off += n
+	}
 
-		n, err = r.buf.Read(p)
+	if n < len(p) && r.off == len(r.pass) {
+		p[n] = '\n'
+		n++
+		r.off++
 	}
 
-	return
+	return n, nil
 }
 
 // keyringForPrivKey creates a temporary in-mem keyring for a PrivKey.
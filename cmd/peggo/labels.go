@@ -0,0 +1,55 @@
+package peggo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// labelNameRe matches the label names valid in Prometheus, which are also safe
+// as log fields and JSON keys.
+var labelNameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// parseInstanceLabels parses instance labels in the key=value format (e.g.
+// region=eu-west-1).
+func parseInstanceLabels(values []string) (map[string]string, error) {
+	labels := make(map[string]string, len(values))
+
+	for _, v := range values {
+		key, value, ok := strings.Cut(v, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid --%s %q; expected key=value (e.g. region=eu-west-1)", flagInstanceLabel, v)
+		}
+
+		if !labelNameRe.MatchString(key) || strings.HasPrefix(key, "__") {
+			return nil, fmt.Errorf("invalid --%s key %q; expected letters, digits and underscores", flagInstanceLabel, key)
+		}
+
+		if _, ok := labels[key]; ok {
+			return nil, fmt.Errorf("duplicate --%s key %q", flagInstanceLabel, key)
+		}
+
+		labels[key] = value
+	}
+
+	return labels, nil
+}
+
+// withInstanceLabels returns a logger adding the instance labels to every log
+// event.
+func withInstanceLabels(logger zerolog.Logger, labels map[string]string) zerolog.Logger {
+	if len(labels) == 0 {
+		return logger
+	}
+
+	fields := make(map[string]interface{}, len(labels))
+	for key, value := range labels {
+		fields[key] = value
+	}
+
+	return logger.With().Fields(fields).Logger()
+}
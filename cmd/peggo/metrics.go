@@ -0,0 +1,55 @@
+package peggo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/sync/errgroup"
+)
+
+// serveMetrics serves the metrics of registry on addr until ctx is done.
+func serveMetrics(ctx context.Context, addr string, registry *prometheus.Registry) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	return serveHTTP(ctx, addr, "metrics", "/metrics", mux)
+}
+
+// serveHTTP serves handler on addr until ctx is done, then shuts it down
+// gracefully. The name and path are only used to log where it's served.
+func serveHTTP(ctx context.Context, addr, name, path string, handler http.Handler) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	g, errCtx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		fmt.Fprintf(os.Stderr, "Serving %s on %s%s\n", name, srv.Addr, path)
+
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("failed to serve %s: %w", name, err)
+		}
+
+		return nil
+	})
+
+	g.Go(func() error {
+		<-errCtx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		return srv.Shutdown(shutdownCtx)
+	})
+
+	return g.Wait()
+}
@@ -0,0 +1,62 @@
+package peggo
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+
+	"github.com/umee-network/peggo/orchestrator/oracle"
+)
+
+// reloadOracleProviders reconfigures the oracle providers, with their rate
+// limits and weights, from the reloaded configuration on every SIGHUP, until
+// ctx is done, and reloads their available pairs. A reload failing leaves the
+// providers unchanged.
+func reloadOracleProviders(ctx context.Context, logger zerolog.Logger, cmd *cobra.Command, o *oracle.Oracle) error {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+	defer signal.Stop(sigCh)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-sigCh:
+			konfig, err := reloadServerConfig(cmd)
+			if err != nil {
+				logger.Err(err).Msg("failed to reload the config; oracle providers unchanged")
+				continue
+			}
+
+			// the limits and weights are checked against the new providers
+			limits, _, err := providerLimits(konfig)
+			if err != nil {
+				logger.Err(err).Msg("invalid oracle provider limits; oracle providers unchanged")
+				continue
+			}
+
+			weights, err := providerWeights(konfig)
+			if err != nil {
+				logger.Err(err).Msg("invalid oracle provider weights; oracle providers unchanged")
+				continue
+			}
+
+			providers := konfig.Strings(flagOracleProviders)
+			if err := o.SetProviders(ctx, stringsToProviderName(providers)); err != nil {
+				logger.Err(err).Msg("failed to reconfigure the oracle providers; unchanged")
+				continue
+			}
+
+			o.SetProviderLimits(limits)
+			o.SetProviderWeights(weights)
+
+			logger.Info().Strs("providers", providers).Msg("oracle providers reconfigured")
+			o.ReloadAvailablePairs()
+		}
+	}
+}
@@ -5,36 +5,76 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"cloud.google.com/go/logging"
 	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdkclient "github.com/cosmos/cosmos-sdk/client"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
 	ethrpc "github.com/ethereum/go-ethereum/rpc"
 	"github.com/knadh/koanf"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
+	"github.com/shopspring/decimal"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 
 	umeepfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	umeeparams "github.com/umee-network/umee/v3/app/params"
+	oracletypes "github.com/umee-network/umee/v3/x/oracle/types"
 
 	"github.com/umee-network/peggo/cmd/peggo/client"
 	"github.com/umee-network/peggo/orchestrator"
+	"github.com/umee-network/peggo/orchestrator/admin"
+	"github.com/umee-network/peggo/orchestrator/analytics"
+	"github.com/umee-network/peggo/orchestrator/breaker"
+	"github.com/umee-network/peggo/orchestrator/cache"
+	"github.com/umee-network/peggo/orchestrator/cohort"
 	"github.com/umee-network/peggo/orchestrator/coingecko"
 	"github.com/umee-network/peggo/orchestrator/cosmos"
+	"github.com/umee-network/peggo/orchestrator/crash"
 	"github.com/umee-network/peggo/orchestrator/ethereum/committer"
 	gravity "github.com/umee-network/peggo/orchestrator/ethereum/gravity"
+	"github.com/umee-network/peggo/orchestrator/ethereum/keystore"
 	"github.com/umee-network/peggo/orchestrator/ethereum/provider"
+	"github.com/umee-network/peggo/orchestrator/heartbeat"
+	"github.com/umee-network/peggo/orchestrator/invariant"
+	"github.com/umee-network/peggo/orchestrator/lifecycle"
+	"github.com/umee-network/peggo/orchestrator/metatx"
 	"github.com/umee-network/peggo/orchestrator/oracle"
+	"github.com/umee-network/peggo/orchestrator/policy"
+	"github.com/umee-network/peggo/orchestrator/pricealert"
+	"github.com/umee-network/peggo/orchestrator/priceserver"
+	"github.com/umee-network/peggo/orchestrator/pricewatch"
 	"github.com/umee-network/peggo/orchestrator/relayer"
+	"github.com/umee-network/peggo/orchestrator/remoteconfig"
+	"github.com/umee-network/peggo/orchestrator/safemode"
+	"github.com/umee-network/peggo/orchestrator/schedule"
+	"github.com/umee-network/peggo/orchestrator/signer"
+	"github.com/umee-network/peggo/orchestrator/store"
+	"github.com/umee-network/peggo/orchestrator/topup"
+	"github.com/umee-network/peggo/orchestrator/totals"
 	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
+	"github.com/umee-network/peggo/solwrappers/versions"
 )
 
+// ethCacheConfirmations is how far behind the latest Ethereum block a block
+// must be for its header and receipts to be cached. It matches the most
+// conservative block delay of the Ethereum oracle, so no reorg can invalidate
+// a cached entry.
+const ethCacheConfirmations = 96
+
 func getOrchestratorCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "orchestrator [gravity-addr]",
@@ -46,283 +86,1944 @@ func getOrchestratorCmd() *cobra.Command {
 				return err
 			}
 
-			logger, err := getLogger(cmd)
+			logger, labels, err := newOrchestratorLogger(cmd, konfig)
+			if err != nil {
+				return err
+			}
+			defer crash.Repanic(context.Background(), logger)
+
+			if err := validateOrchestratorConfig(konfig); err != nil {
+				return err
+			}
+
+			if !ethcmn.IsHexAddress(args[0]) {
+				return fmt.Errorf("invalid gravity address: %s", args[0])
+			}
+			gravityAddr := ethcmn.HexToAddress(args[0])
+
+			orchAddress, clientCtx, tmRPC, err := newOrchestratorClientContext(konfig, logger)
+			if err != nil {
+				return err
+			}
+
+			localStore, err := openStore(konfig)
+			if err != nil {
+				return err
+			}
+
+			journal, err := safemode.NewJournal(logger, localStore)
+			if err != nil {
+				return err
+			}
+
+			metrics, err := newOrchestratorMetrics(konfig, labels)
+			if err != nil {
+				return err
+			}
+
+			// The totals are restored before the Cosmos client is created, as they
+			// also count the fees of its txs. They're kept up to date even without
+			// metrics, so they're complete once metrics are enabled.
+			metricsTotals, err := totals.New(logger, localStore, metrics.registerer)
+			if err != nil {
+				return err
+			}
+
+			cosmosFeeTokens, err := parseFeeTokens(konfig.Strings(flagCosmosFeeTokens))
 			if err != nil {
 				return err
 			}
 
-			cosmosUseLedger := konfig.Bool(flagCosmosUseLedger)
-			ethUseLedger := konfig.Bool(flagEthUseLedger)
-			if cosmosUseLedger || ethUseLedger {
-				return fmt.Errorf("cannot use Ledger for orchestrator")
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			daemonClient, gravityParams, err := newOrchestratorCosmosClient(
+				ctx,
+				konfig,
+				logger,
+				clientCtx,
+				journal,
+				metricsTotals,
+			)
+			if err != nil {
+				return err
 			}
 
-			orchAddress, cosmosKeyring, err := initCosmosKeyring(konfig)
+			gRPCConn := daemonClient.QueryClient()
+			gravityQuerier := gravitytypes.NewQueryClient(gRPCConn)
+
+			migration, err := contractMigration(konfig, gravityAddr)
 			if err != nil {
-				return fmt.Errorf("failed to initialize Cosmos keyring: %w", err)
+				return err
+			}
+
+			gravityAddrs := []ethcmn.Address{gravityAddr}
+			if migration != nil {
+				gravityAddrs = append(gravityAddrs, migration.To)
 			}
 
-			clientCtx, err := client.NewClientContext(konfig.String(flagCosmosChainID), orchAddress.String(), cosmosKeyring)
+			orchSigner, err := initOrchestratorSigner(ctx, konfig, logger, gravityParams.BridgeChainId, gravityAddrs)
 			if err != nil {
 				return err
 			}
 
-			tmRPCEndpoint, err := parseURL(logger, konfig, flagTendermintRPC)
+			ethProvider, ethRouter, ethClient, err := newEthProvider(konfig, logger, metrics.cache)
 			if err != nil {
 				return err
 			}
-			cosmosGRPC, err := parseURL(logger, konfig, flagCosmosGRPC)
+
+			// Nothing is signed until the txs left in flight by a crashed process are
+			// resolved, so the committer and the Cosmos client sync their nonces from
+			// the chains afterwards.
+			err = startSafeMode(konfig, logger, journal, ethProvider, orchSigner.ethAddress, clientCtx, orchAddress)
 			if err != nil {
 				return err
 			}
+			defer journal.Stop()
+
+			ethCommitter, err := newEthCommitter(konfig, logger, orchSigner, ethProvider, journal, gravityParams.BridgeChainId)
+			if err != nil {
+				return err
+			}
+
+			gravityBroadcaster := newGravityBroadcaster(
+				konfig,
+				logger,
+				gravityQuerier,
+				daemonClient,
+				orchSigner,
+				metrics,
+				metricsTotals,
+			)
+
+			deployment, err := newGravityDeployment(konfig, logger, ethCommitter, gravityAddr, migration, metrics)
+			if err != nil {
+				return err
+			}
+
+			limiters, providerKeys, err := providerLimiters(konfig)
+			if err != nil {
+				return err
+			}
+
+			// the remote config may set the symbols of some tokens
+			symbolRetriever := relayer.NewTokenSymbols(newCoinGecko(logger, konfig, limiters, providerKeys))
+
+			ctx, cancel = context.WithCancel(context.Background())
+			// listen for and trap any OS signal to gracefully shutdown and exit
+			trapSignal(cancel)
+
+			o, err := newOrchestratorOracle(
+				ctx,
+				konfig,
+				logger,
+				limiters,
+				providerKeys,
+				localStore,
+				metrics,
+				ethProvider,
+				cosmosFeeTokens,
+			)
+			if err != nil {
+				return err
+			}
+			defer o.Stop()
+
+			metricsTotals.SetValuer(o, strings.ToUpper(konfig.String(flagGasAssetSymbol)), cosmosFeeTokens)
+
+			relayer, relayerServices, err := newRelayer(
+				ctx,
+				konfig,
+				logger,
+				gRPCConn,
+				deployment,
+				o,
+				symbolRetriever,
+				localStore,
+				metricsTotals,
+				gravityParams,
+				orchSigner,
+			)
+			if err != nil {
+				return err
+			}
+
+			logger = logger.With().
+				Str("relayer_orchestrator_addr", orchAddress.String()).
+				Str("relayer_ethereum_addr", orchSigner.ethAddress.String()).
+				Logger()
+
+			// at this point we already setup price-feeder logs, so we don't need them on gcloud
+			logger = handleGCPLogging(ctx, konfig, logger)
+
+			orch, err := newOrchestrator(
+				konfig,
+				logger,
+				gravityQuerier,
+				gravityBroadcaster,
+				deployment,
+				orchSigner,
+				relayer,
+				gravityParams,
+				symbolRetriever,
+				o,
+				tmRPC,
+				metrics,
+				migration,
+			)
+			if err != nil {
+				return err
+			}
+
+			services := orchestratorServices(cmd, konfig, logger, metrics, metricsTotals, orch, o, deployment, ethRouter)
+			services = append(services, relayerServices...)
+
+			monitors, err := monitorServices(
+				konfig,
+				logger,
+				labels,
+				localStore,
+				metrics,
+				orchAddress,
+				orchSigner,
+				tmRPC,
+				gRPCConn,
+				daemonClient,
+				ethClient,
+				ethCommitter.Provider(),
+				deployment,
+				gravityParams,
+				o,
+			)
+			if err != nil {
+				return err
+			}
+			services = append(services, monitors...)
+
+			g, errCtx := errgroup.WithContext(ctx)
+			for _, s := range services {
+				s := s
+				g.Go(func() error {
+					return s(errCtx)
+				})
+			}
+
+			return g.Wait()
+		},
+	}
+
+	cmd.Flags().AddFlagSet(gcpLogFlagSet())
+	cmd.Flags().AddFlagSet(orchestratorFlagSet())
+	cmd.Flags().AddFlagSet(cosmosClientFlagSet())
+	cmd.Flags().AddFlagSet(ethEndpointsFlagSet())
+	cmd.Flags().AddFlagSet(signingFlagSet())
+	cmd.Flags().AddFlagSet(oracleFlagSet())
+	cmd.Flags().AddFlagSet(relayerFlagSet())
+	cmd.Flags().AddFlagSet(adminFlagSet())
+	cmd.Flags().AddFlagSet(monitorsFlagSet())
+	cmd.Flags().AddFlagSet(cosmosFlagSet())
+	cmd.Flags().AddFlagSet(cosmosKeyringFlagSet())
+	cmd.Flags().AddFlagSet(ethereumKeyOptsFlagSet())
+	cmd.Flags().AddFlagSet(ethereumOptsFlagSet())
+	cmd.Flags().AddFlagSet(confirmSchemeFlagSet())
+	cmd.Flags().AddFlagSet(gravityVersionFlagSet())
+
+	return cmd
+}
+
+func gcpLogFlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+
+	fs.String(flagGcpLogProjectName, "", "Set an the Google Cloud Project for logging")
+	fs.String(flagGcpLogMoniker, "", "Specify your moniker to be identified in logs")
+	fs.String(flagGcpLogLevel, zerolog.InfoLevel.String(), "Specify the log level to send to Google Cloud")
+
+	return fs
+}
+
+func orchestratorFlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+
+	fs.Int64(flagEthBlocksPerLoop, 2000, "Number of Ethereum blocks to process per orchestrator loop")
+	fs.Int(flagEthStartupScanWorkers, 1, "Number of Ethereum block chunks scanned at once at startup")
+	fs.Bool(flagConfirmSync, true, "Sign all the missing valset and batch confirms at startup, before the loops")
+	fs.Int64(flagEthStartupScanBlocks, 0, "Number of Ethereum blocks per startup scan chunk (0 to use eth-blocks-per-loop)") //nolint: lll
+	fs.String(flagCoinGeckoAPI, "https://api.coingecko.com/api/v3", "Specify the coingecko API endpoint")
+	fs.Bool(flagEthMergePause, false, "Pause some messages related to the adaptation of the Gravity Bridge to the merge") //nolint: lll
+	fs.Int64(flagMigrationBlock, 0, "Ethereum block from which the new Gravity contract is used (0 disables it)")
+	fs.String(flagMigrationAddress, "", "Address of the new Gravity contract used from --gravity-migration-block")
+	fs.Int64(flagMigrationLastEventNonce, 0, "Last event nonce of the old Gravity contract (0 reads it)")
+	fs.Float64(flagBatchDustFeeUSD, 1.0, "USD fee value under which a batch of a single transfer is not requested (0 disables it)") //nolint: lll
+	fs.Int64(flagBatchTargetSize, 0, "Unbatched transfers to wait for before requesting a batch (0 disables it)")
+	fs.Duration(flagBatchMaxWait, 30*time.Minute, "Maximum time to wait for --batch-target-size transfers")
+	fs.Float64(flagRequesterLoopMultiplier, 60.0, "Multiplier for the batch requester loop duration (in Cosmos blocks)") //nolint: lll
+	fs.Int64(flagBridgeStartHeight, 0, "Set an (optional) height to wait for the bridge to be available")
+	fs.Int64(flagSkipEventsBeforeNonce, 0, "Disaster recovery only: never claim the Ethereum events with a lower nonce (0 disables it)") //nolint: lll
+	fs.Int64(flagSkipEventsConfirm, 0, "Confirm --skip-events-before-nonce by repeating its value")
+	fs.String(flagMetricsListenAddr, "", "Set an (optional) address to serve Prometheus metrics on (e.g. :9301)")
+	fs.StringSlice(flagInstanceLabel, nil, "Set (optional) key=value labels added to metrics, logs and webhooks")
+	fs.String(flagSentryDSN, "", "Set an (optional) Sentry DSN to report panics and fatal errors to")
+	fs.Duration(flagSafeModeTimeout, 10*time.Minute, "Time to wait for the txs left in flight by a crash to be resolved on startup") //nolint: lll
+	fs.Bool(flagSkipSafeMode, false, "Start without resolving the txs left in flight by a crash (may corrupt nonces)")               //nolint: lll
+
+	return fs
+}
+
+func cosmosClientFlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+
+	fs.String(flagCosmosFeeGranter, "", "Set an (optional) fee granter address that will pay for Cosmos fees (feegrant must exist)") //nolint: lll
+	fs.StringSlice(
+		flagCosmosFeeTokens,
+		[]string{umeeparams.BondDenom + "=UMEE:6"},
+		"Set the tokens the Cosmos fees are valued in USD with, as denom=SYMBOL:decimals",
+	)
+	fs.String(flagCosmosReferenceRPC, "", "Set an (optional) reference Tendermint RPC to check the Cosmos node against before sending claims") //nolint: lll
+	fs.Int64(flagCosmosMaxHeightLag, 5, "Maximum number of blocks the Cosmos node may lag behind the reference")
+	fs.Int(flagCosmosMsgsPerTx, 10, "Set a maximum number of messages to send per transaction (used for claims)")
+	fs.Int(flagClaimsPipelineDepth, 4, "Set a maximum number of claim transactions sent without waiting for the previous ones") //nolint: lll
+	fs.Float64(flagClaimsGasAdjustment, cosmos.DefaultClaimsGasAdjustment, "Gas adjustment of pipelined claims")
+	fs.Duration(flagCosmosQueryTimeout, 30*time.Second, "Timeout for Cosmos gRPC queries (0 means no timeout)")
+	fs.Duration(flagCosmosBroadcastTimeout, 60*time.Second, "Time to wait for a broadcasted Cosmos tx to be included in a block") //nolint: lll
+
+	return fs
+}
+
+func ethEndpointsFlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+
+	fs.String(flagEthAlchemyWS, "", "Specify the Alchemy websocket endpoint")
+	fs.StringSlice(flagEthRPCExtra, nil, "Set (optional) extra Ethereum RPC addresses to route calls to")
+	fs.Duration(flagEthRPCProbeInterval, 15*time.Second, "Time between Ethereum RPC endpoint health probes")
+	fs.Int(flagEthHeaderCacheSize, 1024, "Maximum number of confirmed Ethereum block headers kept in memory")
+	fs.Int(flagEthReceiptCacheSize, 4096, "Maximum number of confirmed Ethereum tx receipts kept in memory")
+	fs.Int(flagBreakerMaxFailures, 5, "Consecutive failures before an endpoint is sidelined (0 disables)")
+	fs.Duration(flagBreakerBackoff, 5*time.Second, "Time a failing endpoint is sidelined before being probed")
+	fs.Duration(flagBreakerMaxBackoff, 5*time.Minute, "Maximum time a failing endpoint is sidelined")
+
+	return fs
+}
+
+func signingFlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+
+	fs.String(flagPolicyMaxSpend, "", "Set an (optional) maximum ETH (e.g. 0.5) the signed txs may cost per hour")
+	fs.Int(flagPolicyMaxTxs, 0, "Set a maximum number of txs signed within an hour (0 means no limit)")
+	fs.StringSlice(flagPolicyContracts, nil, "Set the (optional) contracts txs may be sent to, besides the Gravity contract") //nolint: lll
+	fs.String(flagPolicyWebhook, "", "Set an (optional) URL to POST to when signing a tx violates the key usage policy")      //nolint: lll
+	fs.String(flagSignerSocket, "", "Set an (optional) Unix socket of a peggo signer holding the Ethereum key")
+	fs.String(flagSignerToken, "", "Specify the token shared with the peggo signer")
+
+	return fs
+}
+
+func oracleFlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+
+	defaultProviders := []string{
+		umeepfprovider.ProviderOsmosis.String(),
+		umeepfprovider.ProviderHuobi.String(),
+		umeepfprovider.ProviderOkx.String(),
+		umeepfprovider.ProviderCoinbase.String(),
+		umeepfprovider.ProviderBitget.String(),
+		umeepfprovider.ProviderMexc.String(),
+		umeepfprovider.ProviderCrypto.String(),
+	}
+
+	allProviders := append([]string{
+		umeepfprovider.ProviderKraken.String(),
+		umeepfprovider.ProviderGate.String(),
+		umeepfprovider.ProviderMock.String(),
+		umeepfprovider.ProviderBinance.String(),
+		oracle.ProviderOsmosisPool.String(),
+		oracle.ProviderUniswapV3.String(),
+	}, defaultProviders...)
+
+	fs.StringSlice(flagOracleProviders, defaultProviders,
+		fmt.Sprintf("Specify the providers to use in the oracle, options \"%s\"", strings.Join(allProviders, ",")))
+	fs.StringSlice(flagOracleSymbolAliases, nil, "Set (optional) symbols priced as another one (e.g. WETH=ETH)")
+	fs.String(flagDeviationThreshold, "", "Set the (optional) standard deviations from the mean above which a provider price is filtered out") //nolint: lll
+	fs.StringSlice(flagDeviationThresholds, nil, "Set (optional) deviation thresholds per symbol (e.g. USDC=0.5)")
+	fs.Duration(flagOracleTickInterval, oracle.DefaultTickInterval, "Time between oracle price updates")
+	fs.Duration(flagOraclePriceMaxAge, 0, "Age after which an oracle price is refused as stale (0 disables it)")
+	fs.Duration(flagOracleInterpolationGap, time.Minute, "Time an unreported oracle price is held for")
+	fs.String(flagOracleAggregation, oracle.AggregationTVWAP, "Oracle price aggregation: tvwap, vwap or median")
+	fs.Int(flagOracleComputeWorkers, oracle.DefaultComputeWorkers, "Max number of oracle prices computed at once")
+	fs.Duration(flagOracleFetchTimeout, oracle.DefaultFetchTimeout, "Time the oracle waits for each provider (0 waits for all)") //nolint: lll
+	fs.Int(flagOracleBreakerFailures, 5, "Failed fetches in a row sidelining an oracle provider (0 disables it)")
+	fs.Duration(flagOracleBreakerBackoff, 30*time.Second, "Time a failing oracle provider is first sidelined for")
+	fs.Duration(flagOracleBreakerMaxBackoff, 10*time.Minute, "Max time a failing oracle provider is sidelined")
+	fs.String(flagOraclePairsSchedule, "@every 24h", "Schedule (cron or @every) of oracle pair reloads")
+	fs.String(flagOracleReconcileSchedule, "@every 5m", "Schedule (cron or @every) of oracle subscription checks")
+	fs.Bool(flagOracleCandleBackfill, true, "Backfill the candles of new oracle pairs over REST")
+	fs.Duration(flagOracleCandleFreshness, time.Minute, "Half-life of the TVWAP weight of oracle candles over a minute old (0 disables it)") //nolint: lll
+	fs.String(flagOracleAlertWebhook, "", "Set an (optional) URL to POST to when oracle prices move or disappear")
+	fs.String(flagOracleAlertMove, "0.1", "Relative oracle price move between two ticks raising an alert")
+	fs.StringSlice(flagOracleProviderWeights, nil, "Set (optional) oracle provider weights (e.g. mexc=0.3)")
+	fs.StringSlice(flagOracleProviderLimits, nil, "Set (optional) oracle provider and CoinGecko rate limits in requests per second (e.g. coingecko=0.5:5)") //nolint: lll
+	fs.StringSlice(flagOracleProviderKeys, nil, "Set (optional) provider API keys (e.g. coingecko=CG-xxx)")
+	fs.String(flagOracleOsmosisGRPC, "", "Set the (optional) Osmosis gRPC address of the osmosispool provider")
+	fs.StringSlice(flagOracleOsmosisPools, nil, "Set the Osmosis pools of the osmosispool provider (e.g. UMEE/USD=1110:uumee-ibc:uusdc-ibc)") //nolint: lll
+	fs.StringSlice(flagOracleUniswapV3Pools, nil, "Set the Uniswap v3 pools of the uniswapv3 provider (e.g. WETH/USD=0x88e6...:0xc02a...)")   //nolint: lll
+	fs.String(flagOracleStaticFile, "", "Set the .json or .toml prices file of the staticfile provider")
+	fs.Bool(flagOracleStaticWatch, false, "Reload the prices file of the staticfile provider whenever it changes")
+	fs.String(flagOracleDepegThreshold, "0.02", "Filter out the prices quoted in a stablecoin deviating from 1 USD by more than this (0 disables it)")    //nolint: lll
+	fs.String(flagOracleListenAddr, "", "Set an (optional) address to serve the oracle prices on (e.g. 127.0.0.1:9302)")                                  //nolint: lll
+	fs.Duration(flagOracleWarmupTimeout, 2*time.Minute, "Maximum time the relayer and batch requester wait at startup for oracle prices (0 disables it)") //nolint: lll
+	fs.String(flagOracleSmoothingAlpha, "0.2", "Weight (0, 1] of each new price in the oracle price moving average")                                      //nolint: lll
+
+	return fs
+}
+
+func relayerFlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+
+	fs.String(flagValsetRelayMode, relayer.ValsetRelayModeNone.String(), "Set an (optional) relaying mode for valset updates to Ethereum. Possible values: none, minimum, all") //nolint: lll
+	fs.Bool(flagRelayBatches, false, "Relay transaction batches to Ethereum")
+	fs.Duration(flagEthPendingTXWait, 20*time.Minute, "Time for a pending tx to be considered stale")
+	fs.Float64(flagProfitMultiplier, 1.0, "Multiplier to apply to relayer profit")
+	fs.String(flagGasAssetSymbol, oracle.SymbolETH, "Symbol of the EVM chain's gas asset (e.g. BNB, MATIC)")
+	fs.Float64(flagPriceDeviationMax, 0, "Maximum price move (e.g. 0.1 for 10%) of ETH or a fee token within the deviation window before profit-marginal relays are suspended (0 disables it)") //nolint: lll
+	fs.Duration(flagPriceDeviationWindow, 15*time.Minute, "Time window in which price moves are tracked for the price deviation circuit breaker")                                               //nolint: lll
+	fs.Float64(flagPriceDeviationMargin, 0.5, "Extra profit margin (e.g. 0.5 for 50%) required to relay a batch while prices are unstable")                                                     //nolint: lll
+	fs.Duration(flagGasPriceSmoothing, 0, "Gas price moving average window for profitability (0 disables it)")
+	fs.Duration(flagPriceSmoothing, 0, "Oracle price moving average window for profitability (0 disables it)")
+	fs.Duration(flagMissingPriceWait, 10*time.Second, "Time to wait for a missing oracle price once subscribed")
+	fs.String(flagMissingPriceFallback, relayer.MissingPriceFallbackDeny, "Whether to relay batches whose prices are still missing after waiting (deny|allow)") //nolint: lll
+	fs.String(flagDenylistFile, "", "Set an (optional) file listing Ethereum addresses not to relay transfers to")
+	fs.String(flagDenylistURL, "", "Set an (optional) URL listing Ethereum addresses not to relay transfers to")
+	fs.Duration(flagDenylistRefresh, time.Hour, "Time between denylist reloads")
+	fs.String(flagRemoteConfigURL, "", "Set an (optional) URL of a signed config of denylist and symbol aliases")
+	fs.String(flagRemoteConfigSigURL, "", "URL of the remote config signature (default: the config URL + .sig)")
+	fs.String(flagRemoteConfigPubKey, "", "Hex encoded ed25519 public key the remote config must be signed with")
+	fs.Duration(flagRemoteConfigRefresh, time.Hour, "Time between remote config fetches")
+	fs.Duration(flagRelayJitter, 0, "Maximum random delay before relaying a batch (0 disables it)")
+	fs.Duration(flagRelayGracePeriod, 0, "Time a batch is left to its preferred relayer before relaying it (0 disables it)") //nolint: lll
+	fs.Float64(flagMaxValueConcentration, 0, "Max % of batch value going to one fresh address (0 disables it)")
+	fs.String(flagMetaTxEndpoint, "", "Set an (optional) HTTPS relay service to post signed batches to instead of broadcasting them") //nolint: lll
+	fs.String(flagMetaTxAPIKey, "", "Set the (optional) API key of the meta-transaction relay service")
+	fs.StringSlice(flagRelayRewardAddresses, nil, "Set the (optional) addresses batch fees must be paid to")
+	fs.Bool(flagRelayOnlyWhenPivotal, false, "Only relay batches and valset updates our confirm is required for")
+	fs.Duration(flagRelayPivotalTimeout, 15*time.Minute, "Time after which an update nobody relayed is relayed even if not pivotal (0 disables it)") //nolint: lll
+	fs.Float64(flagRelayerLoopMultiplier, 3.0, "Multiplier for the relayer loop duration (in ETH blocks)")
+	fs.Bool(flagEthAccessLists, false, "Relay Ethereum txs with an EIP-2930 access list when it lowers their gas")
+	fs.Int(flagEthMaxInFlightTxs, 0, "Set a maximum number of relayed Ethereum txs waiting to be mined at the same time (0 means no limit)") //nolint: lll
+
+	return fs
+}
+
+func adminFlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+
+	fs.String(flagAdminListenAddr, "", "Set an (optional) address to serve the admin API on (e.g. 127.0.0.1:9303)") //nolint: lll
+	fs.String(flagAdminToken, "", "Specify the token authenticating the admin API requests (min. 16 characters)")
+
+	return fs
+}
+
+func monitorsFlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+
+	fs.String(flagHeartbeatEndpoint, "", "Set an (optional) HTTPS endpoint to periodically send signed status heartbeats to") //nolint: lll
+	fs.Duration(flagHeartbeatInterval, time.Minute, "Time between status heartbeats")
+	fs.String(flagHeartbeatMoniker, "", "Specify your moniker to be identified in status heartbeats")
+	fs.Float64(flagInvariantTolerance, 0.001, "Relative difference (e.g. 0.001 for 0.1%) tolerated between Gravity contract balances and bridged supply") //nolint: lll
+	fs.Duration(flagInvariantInterval, 0, "Time between Gravity balance vs. bridged supply checks (0 to disable)")
+	fs.Duration(flagOracleWatchdogInterval, 0, "Time between comparisons of the oracle prices with the chain's x/oracle exchange rates (0 to disable)")        //nolint: lll
+	fs.Float64(flagOracleWatchdogTolerance, 0.05, "Relative difference (e.g. 0.05 for 5%) tolerated between the oracle prices and the chain's exchange rates") //nolint: lll
+	fs.Duration(flagOracleWatchdogSustain, 10*time.Minute, "Sustained price divergence that raises an alert")
+	fs.String(flagTopupThreshold, "", "Set an (optional) ETH balance (e.g. 0.5) that triggers top-up actions")
+	fs.Duration(flagTopupInterval, 5*time.Minute, "Time between ETH balance checks for top-ups")
+	fs.String(flagTopupSchedule, "", "Set an (optional) schedule (cron or @every) of the ETH balance checks")
+	fs.Duration(flagTopupCooldown, time.Hour, "Minimum time between two top-ups")
+	fs.String(flagTopupWebhook, "", "Set an (optional) URL to POST to when a top-up is triggered")
+	fs.String(flagTopupSendToEth, "", "Set an (optional) amount (e.g. 1gravity0x...) to bridge to self on top-up")
+	fs.String(flagTopupBridgeFee, "", "Set the bridge fee of the top-up SendToEth (defaults to zero)")
+	fs.String(flagAnalyticsDir, "", "Set an (optional) directory to write daily bridge usage summaries to")
+	fs.String(flagAnalyticsFormat, analytics.FormatJSON, "Format of the daily bridge usage files (json|csv)")
+	fs.String(flagAnalyticsWebhook, "", "Set an (optional) URL to POST daily bridge usage summaries to")
+	fs.Duration(flagAnalyticsInterval, 5*time.Minute, "Time between bridge usage aggregations")
+	fs.String(flagAnalyticsSchedule, "", "Set an (optional) schedule (cron or @every) of the aggregations")
+	fs.Int64(flagAnalyticsStartHeight, 0, "Ethereum height to start aggregating bridge usage from on the first run (0 starts from the latest)") //nolint: lll
+	fs.Int64(flagAnalyticsConfirmations, 96, "Number of Ethereum blocks a transfer must be buried under to be counted in bridge usage")         //nolint: lll
+	fs.Int64(flagCohortBlocks, 7200, "Ethereum blocks the relayer cohort metrics cover (0 disables them)")
+	fs.Duration(flagCohortInterval, time.Hour, "Time between relayer cohort metrics updates")
+
+	return fs
+}
+
+// service runs an orchestrator subsystem until its context is done or it fails.
+type service func(context.Context) error
+
+// newOrchestratorLogger returns the logger of the orchestrator with the instance
+// labels, reporting the panics and fatal errors to the crash sink, and the
+// labels themselves.
+func newOrchestratorLogger(cmd *cobra.Command, konfig *koanf.Koanf) (zerolog.Logger, map[string]string, error) {
+	logger, err := getLogger(cmd)
+	if err != nil {
+		return zerolog.Logger{}, nil, err
+	}
+
+	labels, err := parseInstanceLabels(konfig.Strings(flagInstanceLabel))
+	if err != nil {
+		return zerolog.Logger{}, nil, err
+	}
+	logger = withInstanceLabels(logger, labels)
+
+	if err := setCrashSink(konfig); err != nil {
+		return zerolog.Logger{}, nil, err
+	}
+
+	return logger.Hook(crash.Hook{Logger: logger}), labels, nil
+}
+
+// setCrashSink reports the panics and fatal errors to Sentry, if configured.
+func setCrashSink(konfig *koanf.Koanf) error {
+	dsn := konfig.String(flagSentryDSN)
+	if dsn == "" {
+		return nil
+	}
+
+	sink, err := crash.NewSentrySink(dsn, Version)
+	if err != nil {
+		return err
+	}
+
+	crash.SetSink(sink)
+	return nil
+}
+
+// validateOrchestratorConfig checks the flags that can't be checked one by one.
+func validateOrchestratorConfig(konfig *koanf.Koanf) error {
+	if konfig.Bool(flagCosmosUseLedger) || konfig.Bool(flagEthUseLedger) {
+		return fmt.Errorf("cannot use Ledger for orchestrator")
+	}
+
+	if err := validateSkipEventsBeforeNonce(konfig); err != nil {
+		return err
+	}
+
+	if konfig.String(flagSignerSocket) == "" {
+		return nil
+	}
+
+	valsetRelayMode, err := validateRelayValsetsMode(konfig.String(flagValsetRelayMode))
+	if err != nil {
+		return err
+	}
+
+	return validateRemoteSigner(konfig, valsetRelayMode)
+}
+
+// newOrchestratorClientContext returns the orchestrator's Cosmos address and a
+// client context connected to the Tendermint RPC.
+func newOrchestratorClientContext(
+	konfig *koanf.Koanf,
+	logger zerolog.Logger,
+) (sdk.AccAddress, sdkclient.Context, *rpchttp.HTTP, error) {
+	orchAddress, cosmosKeyring, err := initCosmosKeyring(konfig)
+	if err != nil {
+		return nil, sdkclient.Context{}, nil, fmt.Errorf("failed to initialize Cosmos keyring: %w", err)
+	}
+
+	clientCtx, err := client.NewClientContext(konfig.String(flagCosmosChainID), orchAddress.String(), cosmosKeyring)
+	if err != nil {
+		return nil, sdkclient.Context{}, nil, err
+	}
+
+	tmRPCEndpoint, err := parseURL(logger, konfig, flagTendermintRPC)
+	if err != nil {
+		return nil, sdkclient.Context{}, nil, err
+	}
+
+	tmRPC, err := rpchttp.New(tmRPCEndpoint, "/websocket")
+	if err != nil {
+		return nil, sdkclient.Context{}, nil, fmt.Errorf("failed to create Tendermint RPC client: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Connected to Tendermint RPC: %s\n", tmRPCEndpoint)
+
+	var feeGranter sdk.AccAddress
+	if v := konfig.String(flagCosmosFeeGranter); len(v) > 0 {
+		feeGranter, err = sdk.AccAddressFromBech32(v)
+		if err != nil {
+			return nil, sdkclient.Context{}, nil, fmt.Errorf("failed to parse fee granter address: %w", err)
+		}
+	}
+
+	clientCtx = clientCtx.WithClient(tmRPC).WithNodeURI(tmRPCEndpoint).WithFeeGranterAddress(feeGranter)
+
+	return orchAddress, clientCtx, tmRPC, nil
+}
+
+// newEndpointBreaker returns the circuit breaker of an endpoint, shared by
+// every loop using it.
+func newEndpointBreaker(konfig *koanf.Koanf, logger zerolog.Logger, name string) *breaker.Breaker {
+	return breaker.New(
+		logger,
+		name,
+		konfig.Int(flagBreakerMaxFailures),
+		konfig.Duration(flagBreakerBackoff),
+		konfig.Duration(flagBreakerMaxBackoff),
+	)
+}
+
+// newOrchestratorCosmosClient returns the client of the Cosmos gRPC, recording
+// its txs in the journal and their fees in the totals, once the node is up, and
+// the Gravity params.
+func newOrchestratorCosmosClient(
+	ctx context.Context,
+	konfig *koanf.Koanf,
+	logger zerolog.Logger,
+	clientCtx sdkclient.Context,
+	journal *safemode.Journal,
+	metricsTotals *totals.Totals,
+) (client.CosmosClient, *gravitytypes.Params, error) {
+	cosmosGRPC, err := parseURL(logger, konfig, flagCosmosGRPC)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	daemonClient, err := client.NewCosmosClient(
+		clientCtx,
+		logger,
+		cosmosGRPC,
+		client.OptionGasPrices(konfig.String(flagCosmosGasPrices)),
+		client.OptionQueryTimeout(konfig.Duration(flagCosmosQueryTimeout)),
+		client.OptionBroadcastTimeout(konfig.Duration(flagCosmosBroadcastTimeout)),
+		client.OptionCircuitBreaker(newEndpointBreaker(konfig, logger, flagCosmosGRPC)),
+		client.OptionTxJournal(journal),
+		client.OptionFeeRecorder(metricsTotals),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := waitForCosmosNode(ctx, logger, daemonClient.QueryClient()); err != nil {
+		return nil, nil, err
+	}
+
+	gravityParams, err := getGravityParams(daemonClient.QueryClient())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query for Gravity params: %w", err)
+	}
+
+	return daemonClient, gravityParams, nil
+}
+
+// waitForCosmosNode waits for the Cosmos gRPC service and checks the node runs
+// a umee release whose gravity API is supported.
+func waitForCosmosNode(ctx context.Context, logger zerolog.Logger, gRPCConn *grpc.ClientConn) error {
+	// TODO: Clean this up to be more ergonomic and clean. We can probably
+	// encapsulate all of this into a single utility function that gracefully
+	// checks for the gRPC status/health.
+	//
+	// Ref: https://github.com/umee-network/peggo/issues/2
+	fmt.Fprintln(os.Stderr, "Waiting for cosmos gRPC service...")
+	time.Sleep(time.Second)
+
+	waitForService(ctx, gRPCConn)
+
+	nodeVersion, err := cosmos.Handshake(ctx, gRPCConn)
+	if err != nil {
+		return err
+	}
+
+	logger.Info().Str("version", nodeVersion.String()).Msg("connected to cosmos node")
+	if nodeVersion.Newer {
+		logger.Warn().
+			Str("version", nodeVersion.String()).
+			Int("max_umee_major", cosmos.MaxUmeeMajor).
+			Msg("the cosmos node runs a newer umee than this release was tested with; its gravity API matches")
+	}
+
+	return nil
+}
+
+// orchestratorSigner is the Ethereum account of the orchestrator and the
+// functions signing with its key.
+type orchestratorSigner struct {
+	ethAddress     ethcmn.Address
+	signerFn       bind.SignerFn
+	personalSignFn keystore.PersonalSignFn
+	confirmSigner  signer.ConfirmSigner
+}
+
+// initOrchestratorSigner returns the Ethereum account of the orchestrator, its
+// signing functions and the signer of the valset and batch confirms, held by a
+// remote peggo signer if one is configured. The txs it signs are bound by the
+// key usage policy, if any.
+func initOrchestratorSigner(
+	ctx context.Context,
+	konfig *koanf.Koanf,
+	logger zerolog.Logger,
+	ethChainID uint64,
+	gravityAddrs []ethcmn.Address,
+) (orchestratorSigner, error) {
+	var s orchestratorSigner
+	if socket := konfig.String(flagSignerSocket); socket != "" {
+		ethAddress, signerFn, personalSignFn, remoteSigner, err := initRemoteSigner(ctx, konfig)
+		if err != nil {
+			return orchestratorSigner{}, err
+		}
+
+		fmt.Fprintf(os.Stderr, "Connected to signer: %s\n", socket)
+		s = orchestratorSigner{
+			ethAddress:     ethAddress,
+			signerFn:       signerFn,
+			personalSignFn: personalSignFn,
+			confirmSigner:  remoteSigner,
+		}
+	} else {
+		ethAddress, signerFn, personalSignFn, typedDataSignFn, err := initEthereumAccountsManager(
+			logger,
+			ethChainID,
+			konfig,
+		)
+		if err != nil {
+			return orchestratorSigner{}, fmt.Errorf("failed to initialize Ethereum account: %w", err)
+		}
+
+		confirmSigner, err := newConfirmSigner(konfig, personalSignFn, typedDataSignFn)
+		if err != nil {
+			return orchestratorSigner{}, err
+		}
+
+		s = orchestratorSigner{
+			ethAddress:     ethAddress,
+			signerFn:       signerFn,
+			personalSignFn: personalSignFn,
+			confirmSigner:  confirmSigner,
+		}
+	}
+
+	// the keys are loaded, the secrets read from STDIN aren't needed anymore
+	zeroStdinSecrets()
+
+	keyPolicy, err := newKeyPolicy(konfig, logger, gravityAddrs)
+	if err != nil {
+		return orchestratorSigner{}, err
+	}
+	if keyPolicy != nil {
+		s.signerFn = keyPolicy.SignerFn(s.signerFn)
+	}
+
+	return s, nil
+}
+
+// orchestratorMetrics is the metrics registry of the orchestrator and the
+// metrics of its claims, caches and transfer lifecycle. All are nil when
+// metrics are disabled.
+type orchestratorMetrics struct {
+	registry   *prometheus.Registry
+	registerer prometheus.Registerer
+	claims     *cosmos.ClaimsMetrics
+	cache      *cache.Metrics
+	lifecycle  *lifecycle.Metrics
+}
+
+// newOrchestratorMetrics returns the orchestrator metrics, labeled with the
+// instance labels, if a metrics listen address is set.
+func newOrchestratorMetrics(konfig *koanf.Koanf, labels map[string]string) (orchestratorMetrics, error) {
+	if konfig.String(flagMetricsListenAddr) == "" {
+		return orchestratorMetrics{}, nil
+	}
+
+	registry := prometheus.NewRegistry()
+	registerer := prometheus.WrapRegistererWith(labels, registry)
+
+	claimsMetrics, err := cosmos.NewClaimsMetrics(registerer)
+	if err != nil {
+		return orchestratorMetrics{}, err
+	}
+
+	cacheMetrics, err := cache.NewMetrics(registerer)
+	if err != nil {
+		return orchestratorMetrics{}, err
+	}
+
+	lifecycleMetrics, err := lifecycle.NewMetrics(registerer)
+	if err != nil {
+		return orchestratorMetrics{}, err
+	}
+
+	return orchestratorMetrics{
+		registry:   registry,
+		registerer: registerer,
+		claims:     claimsMetrics,
+		cache:      cacheMetrics,
+		lifecycle:  lifecycleMetrics,
+	}, nil
+}
+
+// newEthEndpoint returns an Ethereum endpoint with its own timeouts and
+// circuit breaker.
+func newEthEndpoint(
+	konfig *koanf.Koanf,
+	logger zerolog.Logger,
+	name string,
+	rpcClient *ethrpc.Client,
+) provider.Endpoint {
+	return provider.Endpoint{
+		Name: name,
+		Provider: provider.WithCircuitBreaker(
+			provider.WithTimeouts(
+				provider.NewEVMProvider(rpcClient),
+				konfig.Duration(flagEthReadTimeout),
+				konfig.Duration(flagEthBroadcastTimeout),
+			),
+			newEndpointBreaker(konfig, logger, name),
+		),
+		RPC: rpcClient,
+	}
+}
+
+// newEthProvider returns the provider of the Ethereum RPC, caching confirmed
+// blocks, and a plain client of the main endpoint. With extra endpoints, the
+// calls are routed by a router to be started.
+func newEthProvider(
+	konfig *koanf.Koanf,
+	logger zerolog.Logger,
+	cacheMetrics *cache.Metrics,
+) (provider.EVMProviderWithRet, *provider.Router, *ethclient.Client, error) {
+	ethRPCEndpoint := konfig.String(flagEthRPC)
+	ethRPC, err := ethrpc.Dial(ethRPCEndpoint)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to dial Ethereum RPC node: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Connected to Ethereum RPC: %s\n", ethRPCEndpoint)
+
+	var (
+		ethEndpoint provider.EVMProviderWithRet
+		ethRouter   *provider.Router
+	)
+	if extraEndpoints := konfig.Strings(flagEthRPCExtra); len(extraEndpoints) > 0 {
+		endpoints := []provider.Endpoint{newEthEndpoint(konfig, logger, flagEthRPC, ethRPC)}
+		for i, endpoint := range extraEndpoints {
+			extraRPC, err := ethrpc.Dial(endpoint)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("failed to dial Ethereum RPC node %s: %w", endpoint, err)
+			}
+
+			fmt.Fprintf(os.Stderr, "Connected to Ethereum RPC: %s\n", endpoint)
+			endpoints = append(endpoints, newEthEndpoint(konfig, logger, fmt.Sprintf("%s-%d", flagEthRPC, i+1), extraRPC))
+		}
+
+		ethRouter, err = provider.NewRouter(logger, endpoints, konfig.Duration(flagEthRPCProbeInterval))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		ethEndpoint = ethRouter
+	} else {
+		ethEndpoint = newEthEndpoint(konfig, logger, flagEthRPC, ethRPC).Provider
+	}
+
+	ethProvider := provider.WithCache(
+		ethEndpoint,
+		provider.CacheConfig{
+			Headers:       konfig.Int(flagEthHeaderCacheSize),
+			Receipts:      konfig.Int(flagEthReceiptCacheSize),
+			Confirmations: ethCacheConfirmations,
+			Metrics:       cacheMetrics,
+		},
+	)
+
+	return ethProvider, ethRouter, ethclient.NewClient(ethRPC), nil
+}
+
+// newEthCommitter returns the committer sending the orchestrator's Ethereum
+// txs, recording them in the journal.
+func newEthCommitter(
+	konfig *koanf.Koanf,
+	logger zerolog.Logger,
+	s orchestratorSigner,
+	ethProvider provider.EVMProviderWithRet,
+	journal *safemode.Journal,
+	ethChainID uint64,
+) (committer.EVMCommitter, error) {
+	committerOpts := []committer.EVMCommitterOption{
+		committer.OptionMaxInFlightTxs(konfig.Int(flagEthMaxInFlightTxs)),
+		committer.TxBroadcastTimeout(konfig.Duration(flagEthBroadcastTimeout)),
+		committer.OptionTxJournal(journal),
+	}
+	if konfig.Bool(flagEthAccessLists) {
+		committerOpts = append(committerOpts, committer.OptionAccessLists(ethChainID))
+	}
+
+	ethCommitter, err := committer.NewEthCommitter(
+		logger,
+		s.ethAddress,
+		konfig.Float64(flagEthGasAdjustment),
+		konfig.Float64(flagEthGasLimitAdjustment),
+		s.signerFn,
+		ethProvider,
+		committerOpts...,
+	)
+	if err != nil && err != grpc.ErrServerStopped {
+		return nil, fmt.Errorf("failed to create Ethereum committer: %w", err)
+	}
+
+	return ethCommitter, nil
+}
+
+// newGravityBroadcaster returns the client broadcasting the orchestrator's
+// claims and confirms to the Gravity module.
+func newGravityBroadcaster(
+	konfig *koanf.Koanf,
+	logger zerolog.Logger,
+	gravityQuerier gravitytypes.QueryClient,
+	daemonClient client.CosmosClient,
+	s orchestratorSigner,
+	metrics orchestratorMetrics,
+	metricsTotals *totals.Totals,
+) cosmos.GravityBroadcastClient {
+	broadcasterOpts := []cosmos.BroadcastClientOption{
+		cosmos.OptionConfirmSigner(s.confirmSigner),
+		cosmos.OptionClaimsPipelineDepth(konfig.Int(flagClaimsPipelineDepth)),
+		cosmos.OptionClaimsGasAdjustment(konfig.Float64(flagClaimsGasAdjustment)),
+	}
+	if metrics.claims != nil {
+		broadcasterOpts = append(
+			broadcasterOpts,
+			cosmos.OptionClaimsMetrics(metrics.claims),
+			cosmos.OptionClaimsTotals(metricsTotals),
+		)
+	}
+
+	return cosmos.NewGravityBroadcastClient(
+		logger,
+		gravityQuerier,
+		daemonClient,
+		s.signerFn,
+		s.personalSignFn,
+		konfig.Int(flagCosmosMsgsPerTx),
+		broadcasterOpts...,
+	)
+}
+
+// gravityDeployment is the Gravity contract the orchestrator runs against, its
+// bindings and the Gravity.sol version it was deployed from.
+type gravityDeployment struct {
+	address  ethcmn.Address
+	contract gravity.Contract
+	bindings *wrappers.Gravity
+	version  versions.Version
+}
+
+// newGravityDeployment returns the Gravity contract at the given address,
+// switching to the new contract at the migration block, if any.
+func newGravityDeployment(
+	konfig *koanf.Koanf,
+	logger zerolog.Logger,
+	ethCommitter committer.EVMCommitter,
+	gravityAddr ethcmn.Address,
+	migration *orchestrator.ContractMigration,
+	metrics orchestratorMetrics,
+) (gravityDeployment, error) {
+	ethGravity, err := wrappers.NewGravity(gravityAddr, ethCommitter.Provider())
+	if err != nil {
+		return gravityDeployment{}, fmt.Errorf("failed to create a new instance of Gravity: %w", err)
+	}
+
+	gravityVersion, err := resolveGravityVersion(konfig, ethCommitter.Provider(), gravityAddr)
+	if err != nil {
+		return gravityDeployment{}, err
+	}
+
+	contractOpts := []gravity.ContractOption{
+		gravity.OptionCacheMetrics(metrics.cache),
+		gravity.OptionLifecycleMetrics(metrics.lifecycle),
+		gravity.OptionVersion(gravityVersion),
+	}
+
+	// a version with directed rewards pays the batch fees to the first
+	// reward address instead of our Ethereum account
+	if addrs := konfig.Strings(flagRelayRewardAddresses); gravityVersion.DirectedRewards() && len(addrs) > 0 {
+		contractOpts = append(contractOpts, gravity.OptionRewardRecipient(ethcmn.HexToAddress(addrs[0])))
+	}
+
+	if migration != nil {
+		contractOpts = append(contractOpts, gravity.OptionMigration(gravity.Migration{
+			Block:   migration.Block,
+			Address: migration.To,
+		}))
+	}
+
+	gravityContract, err := gravity.NewGravityContract(
+		logger,
+		ethCommitter,
+		gravityAddr,
+		ethGravity,
+		contractOpts...,
+	)
+	if err != nil {
+		return gravityDeployment{}, fmt.Errorf("failed to create Ethereum committer: %w", err)
+	}
+
+	return gravityDeployment{
+		address:  gravityAddr,
+		contract: gravityContract,
+		bindings: ethGravity,
+		version:  gravityVersion,
+	}, nil
+}
+
+// newOrchestratorOracle returns the oracle pricing the batches and the cost of
+// operation, subscribed to the gas asset and the Cosmos fee tokens.
+func newOrchestratorOracle(
+	ctx context.Context,
+	konfig *koanf.Koanf,
+	logger zerolog.Logger,
+	limiters map[umeepfprovider.Name]*oracle.Limiter,
+	providerKeys map[umeepfprovider.Name]string,
+	st *store.Store,
+	metrics orchestratorMetrics,
+	ethCaller bind.ContractCaller,
+	feeTokens map[string]totals.Token,
+) (*oracle.Oracle, error) {
+	oracleOpts, err := oracleOptions(konfig)
+	if err != nil {
+		return nil, err
+	}
+	oracleOpts = append(oracleOpts, oracle.OptionProviderLimiters(limiters), oracle.OptionProviderKeys(providerKeys))
+
+	alertOpts, err := priceAlertOptions(konfig, logger)
+	if err != nil {
+		return nil, err
+	}
+	oracleOpts = append(oracleOpts, alertOpts...)
+
+	// the prices are kept over the smoothing window to be averaged
+	if window := konfig.Duration(flagPriceSmoothing); window > 0 {
+		alpha, err := sdk.NewDecFromStr(konfig.String(flagOracleSmoothingAlpha))
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s: %w", flagOracleSmoothingAlpha, err)
+		}
+
+		if err := oracle.ValidateSmoothingAlpha(alpha); err != nil {
+			return nil, err
+		}
+
+		oracleOpts = append(oracleOpts, oracle.OptionPriceHistory(window), oracle.OptionSmoothingAlpha(alpha))
+	}
+
+	o, err := oracle.New(
+		ctx,
+		logger.With().Str("module", "oracle").Logger(),
+		stringsToProviderName(konfig.Strings(flagOracleProviders)),
+		append(
+			oracleOpts,
+			oracle.OptionStore(st),
+			oracle.OptionLifecycleMetrics(metrics.lifecycle),
+			oracle.OptionRegisterer(metrics.registerer),
+			oracle.OptionEthCaller(ethCaller),
+		)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// the cost of operation is valued at the oracle prices
+	symbols := []string{strings.ToUpper(konfig.String(flagGasAssetSymbol))}
+	for _, token := range feeTokens {
+		symbols = append(symbols, token.Symbol)
+	}
+
+	for _, symbol := range symbols {
+		if err := o.SubscribeSymbols(symbol); err != nil {
+			o.Stop()
+			return nil, err
+		}
+	}
+
+	return o, nil
+}
+
+// newRelayer returns the relayer of valsets and batches to Ethereum, and the
+// services refreshing its denylist and remote config, if any.
+func newRelayer(
+	ctx context.Context,
+	konfig *koanf.Koanf,
+	logger zerolog.Logger,
+	gRPCConn *grpc.ClientConn,
+	deployment gravityDeployment,
+	o *oracle.Oracle,
+	symbolRetriever *relayer.TokenSymbols,
+	st *store.Store,
+	metricsTotals *totals.Totals,
+	gravityParams *gravitytypes.Params,
+	s orchestratorSigner,
+) (relayer.GravityRelayer, []service, error) {
+	valsetRelayMode, err := validateRelayValsetsMode(konfig.String(flagValsetRelayMode))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	relayerOpts, err := relayerOptions(konfig, gRPCConn, o, symbolRetriever, st, metricsTotals, deployment.version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var services []service
+
+	denylist, err := newDenylist(konfig, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	if denylist != nil {
+		relayerOpts = append(relayerOpts, relayer.SetDenylist(denylist))
+		services = append(services, func(ctx context.Context) error {
+			return denylist.Start(ctx, konfig.Duration(flagDenylistRefresh))
+		})
+	}
+
+	remoteConfig, remoteDenylist, err := newRemoteConfig(ctx, konfig, logger, st, o, denylist, symbolRetriever)
+	if err != nil {
+		return nil, nil, err
+	}
+	if remoteDenylist != nil {
+		relayerOpts = append(relayerOpts, relayer.SetDenylist(remoteDenylist))
+	}
+	if remoteConfig != nil {
+		services = append(services, func(ctx context.Context) error {
+			return remoteConfig.Start(ctx, konfig.Duration(flagRemoteConfigRefresh))
+		})
+	}
+
+	metaTxOpt, err := metaTxOption(konfig, gravityParams.BridgeChainId, deployment.version, s.ethAddress, s.personalSignFn)
+	if err != nil {
+		return nil, nil, err
+	}
+	if metaTxOpt != nil {
+		relayerOpts = append(relayerOpts, metaTxOpt)
+	}
+
+	// gravityParams.AverageEthereumBlockTime is in milliseconds.
+	averageEthBlockTime := time.Duration(gravityParams.AverageEthereumBlockTime) * time.Millisecond
+
+	gravityRelayer := relayer.NewGravityRelayer(
+		logger,
+		gravitytypes.NewQueryClient(gRPCConn),
+		deployment.contract,
+		valsetRelayMode,
+		konfig.Bool(flagRelayBatches),
+		loopDuration(averageEthBlockTime, konfig.Float64(flagRelayerLoopMultiplier)),
+		konfig.Duration(flagEthPendingTXWait),
+		konfig.Float64(flagProfitMultiplier),
+		relayerOpts...,
+	)
+
+	return gravityRelayer, services, nil
+}
+
+// relayerOptions returns the relayer options set by the flags.
+func relayerOptions(
+	konfig *koanf.Koanf,
+	gRPCConn *grpc.ClientConn,
+	o *oracle.Oracle,
+	symbolRetriever *relayer.TokenSymbols,
+	st *store.Store,
+	metricsTotals *totals.Totals,
+	gravityVersion versions.Version,
+) ([]func(relayer.GravityRelayer), error) {
+	missingPriceFallback := konfig.String(flagMissingPriceFallback)
+	if err := relayer.ValidateMissingPriceFallback(missingPriceFallback); err != nil {
+		return nil, err
+	}
+
+	// the addresses were validated with the config
+	var rewardAddresses []ethcmn.Address
+	for _, addr := range konfig.Strings(flagRelayRewardAddresses) {
+		rewardAddresses = append(rewardAddresses, ethcmn.HexToAddress(addr))
+	}
+
+	return []func(relayer.GravityRelayer){
+		relayer.SetSymbolRetriever(symbolRetriever),
+		relayer.SetOracle(o),
+		relayer.SetStore(st),
+		relayer.SetPriceBreaker(
+			konfig.Float64(flagPriceDeviationMax),
+			konfig.Duration(flagPriceDeviationWindow),
+			konfig.Float64(flagPriceDeviationMargin),
+		),
+		relayer.SetGasPriceSmoothing(konfig.Duration(flagGasPriceSmoothing)),
+		relayer.SetPriceSmoothing(konfig.Duration(flagPriceSmoothing)),
+		relayer.SetMissingPricePolicy(konfig.Duration(flagMissingPriceWait), missingPriceFallback),
+		relayer.SetStakingQueryClient(stakingtypes.NewQueryClient(gRPCConn)),
+		relayer.SetGasAssetSymbol(konfig.String(flagGasAssetSymbol)),
+		relayer.SetRelayOnlyWhenPivotal(
+			konfig.Bool(flagRelayOnlyWhenPivotal),
+			konfig.Duration(flagRelayPivotalTimeout),
+		),
+		relayer.SetRelayJitter(konfig.Duration(flagRelayJitter)),
+		relayer.SetRelayGracePeriod(konfig.Duration(flagRelayGracePeriod)),
+		relayer.SetMaxValueConcentration(konfig.Float64(flagMaxValueConcentration)),
+		relayer.SetTotals(metricsTotals),
+		relayer.SetRewardAddresses(rewardAddresses),
+		relayer.SetGravityVersion(gravityVersion),
+	}, nil
+}
+
+// newDenylist returns the denylist read from the configured file or URL, or nil
+// if there's none.
+func newDenylist(konfig *koanf.Koanf, logger zerolog.Logger) (*relayer.Denylist, error) {
+	file, url := konfig.String(flagDenylistFile), konfig.String(flagDenylistURL)
+	if file == "" && url == "" {
+		return nil, nil
+	}
+
+	return relayer.NewDenylist(logger, file, url)
+}
+
+// newRemoteConfig returns the fetcher of the remote config, or nil if there's
+// none. The remote denylist is merged into the local one; without a local one,
+// a remote-only denylist is returned for the relayer to use.
+func newRemoteConfig(
+	ctx context.Context,
+	konfig *koanf.Koanf,
+	logger zerolog.Logger,
+	st *store.Store,
+	o *oracle.Oracle,
+	denylist *relayer.Denylist,
+	symbolRetriever *relayer.TokenSymbols,
+) (*remoteconfig.Fetcher, *relayer.Denylist, error) {
+	if konfig.String(flagRemoteConfigURL) == "" {
+		return nil, nil, nil
+	}
+
+	var remoteDenylist *relayer.Denylist
+	if denylist == nil {
+		remoteDenylist = relayer.NewRemoteDenylist(logger)
+		denylist = remoteDenylist
+	}
+
+	fetcher, err := newRemoteConfigFetcher(ctx, logger, konfig, st, o, denylist, symbolRetriever)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return fetcher, remoteDenylist, nil
+}
+
+// metaTxOption returns the relayer option posting the signed batches to the
+// meta-transaction relay service, or nil if there's none.
+func metaTxOption(
+	konfig *koanf.Koanf,
+	ethChainID uint64,
+	gravityVersion versions.Version,
+	ethAddress ethcmn.Address,
+	personalSignFn keystore.PersonalSignFn,
+) (func(relayer.GravityRelayer), error) {
+	endpoint := konfig.String(flagMetaTxEndpoint)
+	if endpoint == "" {
+		return nil, nil
+	}
+
+	if konfig.Bool(flagRelayBatches) {
+		err := validateMetaTx(
+			gravityVersion,
+			konfig.Strings(flagRelayRewardAddresses),
+			konfig.Float64(flagProfitMultiplier),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	metaTxClient, err := metatx.NewClient(metatx.Config{
+		Endpoint:   endpoint,
+		APIKey:     konfig.String(flagMetaTxAPIKey),
+		ChainID:    ethChainID,
+		EthAddress: ethAddress,
+	}, personalSignFn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create meta-transaction client: %w", err)
+	}
+
+	return relayer.SetMetaTxSubmitter(metaTxClient), nil
+}
+
+// loopDuration returns the duration of a loop running every multiplier blocks.
+func loopDuration(averageBlockTime time.Duration, multiplier float64) time.Duration {
+	blockTimeF64 := float64(averageBlockTime.Milliseconds())
+
+	// Here we cast the float64 to a Duration (int64); as we are dealing with ms, we'll lose as much as 1ms.
+	return time.Duration(blockTimeF64*multiplier) * time.Millisecond
+}
+
+// newOrchestrator returns the orchestrator running the Ethereum oracle, signer,
+// batch requester and relayer loops.
+func newOrchestrator(
+	konfig *koanf.Koanf,
+	logger zerolog.Logger,
+	gravityQuerier gravitytypes.QueryClient,
+	gravityBroadcaster cosmos.GravityBroadcastClient,
+	deployment gravityDeployment,
+	s orchestratorSigner,
+	gravityRelayer relayer.GravityRelayer,
+	gravityParams *gravitytypes.Params,
+	symbolRetriever *relayer.TokenSymbols,
+	o *oracle.Oracle,
+	tmRPC *rpchttp.HTTP,
+	metrics orchestratorMetrics,
+	migration *orchestrator.ContractMigration,
+) (orchestrator.GravityOrchestrator, error) {
+	orchOpts, err := orchestratorOptions(konfig, logger, tmRPC, metrics, migration)
+	if err != nil {
+		return nil, err
+	}
+
+	// gravityParams.AverageBlockTime and gravityParams.AverageEthereumBlockTime are in milliseconds.
+	averageCosmosBlockTime := time.Duration(gravityParams.AverageBlockTime) * time.Millisecond
+	averageEthBlockTime := time.Duration(gravityParams.AverageEthereumBlockTime) * time.Millisecond
+
+	// Run the requester loop every approximately 60 Cosmos blocks (around 5m by default) to allow time to
+	// receive new transactions. Running this faster will cause a lot of small batches and lots of messages
+	// going around the network. We need to keep in mind that this call is going to be made by all the
+	// validators. This loop is configurable so it can be adjusted for E2E tests.
+	return orchestrator.NewGravityOrchestrator(
+		logger,
+		gravityQuerier,
+		gravityBroadcaster,
+		deployment.contract,
+		s.ethAddress,
+		s.signerFn,
+		s.personalSignFn,
+		gravityRelayer,
+		averageCosmosBlockTime,
+		averageEthBlockTime,
+		loopDuration(averageCosmosBlockTime, konfig.Float64(flagRequesterLoopMultiplier)),
+		konfig.Int64(flagEthBlocksPerLoop),
+		konfig.Int64(flagBridgeStartHeight),
+		symbolRetriever,
+		o,
+		konfig.Bool(flagEthMergePause),
+		orchOpts...,
+	), nil
+}
+
+// orchestratorOptions returns the orchestrator options set by the flags.
+func orchestratorOptions(
+	konfig *koanf.Koanf,
+	logger zerolog.Logger,
+	tmRPC *rpchttp.HTTP,
+	metrics orchestratorMetrics,
+	migration *orchestrator.ContractMigration,
+) ([]func(orchestrator.GravityOrchestrator), error) {
+	orchOpts := []func(orchestrator.GravityOrchestrator){
+		orchestrator.SetBatchDustThreshold(konfig.Float64(flagBatchDustFeeUSD)),
+		orchestrator.SetBatchTiming(uint64(konfig.Int64(flagBatchTargetSize)), konfig.Duration(flagBatchMaxWait)),
+		orchestrator.SetOracleWarmup(konfig.Duration(flagOracleWarmupTimeout)),
+		orchestrator.SetSkipEventsBeforeNonce(uint64(konfig.Int64(flagSkipEventsBeforeNonce))),
+		orchestrator.SetCacheMetrics(metrics.cache),
+		orchestrator.SetStartupScan(
+			konfig.Int(flagEthStartupScanWorkers),
+			uint64(konfig.Int64(flagEthStartupScanBlocks)),
+		),
+		orchestrator.SetConfirmSync(konfig.Bool(flagConfirmSync)),
+	}
+
+	if migration != nil {
+		orchOpts = append(orchOpts, orchestrator.SetContractMigration(*migration))
+	}
+
+	if konfig.String(flagCosmosReferenceRPC) != "" {
+		referenceEndpoint, err := parseURL(logger, konfig, flagCosmosReferenceRPC)
+		if err != nil {
+			return nil, err
+		}
+
+		referenceRPC, err := rpchttp.New(referenceEndpoint, "/websocket")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create reference Tendermint RPC client: %w", err)
+		}
+
+		orchOpts = append(orchOpts, orchestrator.SetCosmosHeightChecker(
+			cosmos.NewHeightLagChecker(logger, tmRPC, referenceRPC, konfig.Int64(flagCosmosMaxHeightLag)),
+		))
+	}
+
+	return orchOpts, nil
+}
+
+// orchestratorServices returns the services every orchestrator runs: its loops,
+// the oracle provider reloads and the totals, plus the metrics and oracle
+// servers, the pending txs subscription and the Ethereum router when enabled.
+func orchestratorServices(
+	cmd *cobra.Command,
+	konfig *koanf.Koanf,
+	logger zerolog.Logger,
+	metrics orchestratorMetrics,
+	metricsTotals *totals.Totals,
+	orch orchestrator.GravityOrchestrator,
+	o *oracle.Oracle,
+	deployment gravityDeployment,
+	ethRouter *provider.Router,
+) []service {
+	services := []service{
+		func(ctx context.Context) error { return startOrchestrator(ctx, logger, orch) },
+		func(ctx context.Context) error { return reloadOracleProviders(ctx, logger, cmd, o) },
+		func(ctx context.Context) error {
+			metricsTotals.Run(ctx)
+			return nil
+		},
+	}
+
+	if metrics.registry != nil {
+		services = append(services, func(ctx context.Context) error {
+			return serveMetrics(ctx, konfig.String(flagMetricsListenAddr), metrics.registry)
+		})
+	}
+
+	if addr := konfig.String(flagOracleListenAddr); addr != "" {
+		services = append(services, func(ctx context.Context) error {
+			return serveHTTP(ctx, addr, "oracle prices", "/prices", priceserver.NewHandler(logger, o))
+		})
+	}
+
+	// If we have the alchemy WS endpoint, start listening for txs against the Gravity Bridge contract.
+	if alchemyWS := konfig.String(flagEthAlchemyWS); alchemyWS != "" {
+		services = append(services, func(ctx context.Context) error {
+			return deployment.contract.SubscribeToPendingTxs(ctx, alchemyWS)
+		})
+	}
+
+	if ethRouter != nil {
+		services = append(services, ethRouter.Start)
+	}
+
+	return services
+}
+
+// monitorServices returns the optional services watching the orchestrator and
+// the bridge that are enabled.
+func monitorServices(
+	konfig *koanf.Koanf,
+	logger zerolog.Logger,
+	labels map[string]string,
+	st *store.Store,
+	metrics orchestratorMetrics,
+	orchAddress sdk.AccAddress,
+	s orchestratorSigner,
+	tmRPC *rpchttp.HTTP,
+	gRPCConn *grpc.ClientConn,
+	daemonClient client.CosmosClient,
+	ethClient *ethclient.Client,
+	ethProvider provider.EVMProvider,
+	deployment gravityDeployment,
+	gravityParams *gravitytypes.Params,
+	o *oracle.Oracle,
+) ([]service, error) {
+	bankQuerier := banktypes.NewQueryClient(gRPCConn)
+
+	adminService, err := newAdminService(konfig, logger, st)
+	if err != nil {
+		return nil, err
+	}
+
+	heartbeatService, err := newHeartbeatService(
+		konfig,
+		logger,
+		labels,
+		orchAddress,
+		s.ethAddress,
+		s.personalSignFn,
+		tmRPC,
+		gravitytypes.NewQueryClient(gRPCConn),
+		bankQuerier,
+		ethClient,
+		deployment.bindings,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	analyticsService, err := newAnalyticsService(
+		konfig,
+		logger,
+		labels,
+		st,
+		ethProvider,
+		deployment.bindings,
+		deployment.version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cohortService, err := newCohortService(
+		konfig,
+		logger,
+		metrics.registerer,
+		ethProvider,
+		deployment.bindings,
+		deployment.version,
+		gravityParams,
+		s.ethAddress,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	topupService, err := newTopupService(konfig, logger, ethClient, daemonClient, orchAddress, s.ethAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	invariantService, err := newInvariantService(konfig, logger, deployment.address, bankQuerier, ethProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	watchdogService, err := newPriceWatchdogService(konfig, logger, oracletypes.NewQueryClient(gRPCConn), o)
+	if err != nil {
+		return nil, err
+	}
+
+	// the optional services are nil when disabled
+	var services []service
+	for _, svc := range []service{
+		adminService,
+		heartbeatService,
+		analyticsService,
+		cohortService,
+		topupService,
+		invariantService,
+		watchdogService,
+	} {
+		if svc != nil {
+			services = append(services, svc)
+		}
+	}
+
+	return services, nil
+}
+
+// newAdminService returns the service serving the admin API, or nil if it's
+// disabled.
+func newAdminService(konfig *koanf.Koanf, logger zerolog.Logger, st *store.Store) (service, error) {
+	addr := konfig.String(flagAdminListenAddr)
+	if addr == "" {
+		return nil, nil
+	}
+
+	adminHandler, err := admin.NewHandler(logger, st, konfig.String(flagAdminToken))
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) error {
+		return serveHTTP(ctx, addr, "admin API", admin.PathBatchApprovals, adminHandler)
+	}, nil
+}
+
+// newHeartbeatService returns the service publishing the status heartbeats, or
+// nil if it's disabled.
+func newHeartbeatService(
+	konfig *koanf.Koanf,
+	logger zerolog.Logger,
+	labels map[string]string,
+	orchAddress sdk.AccAddress,
+	ethAddress ethcmn.Address,
+	personalSignFn keystore.PersonalSignFn,
+	tmRPC *rpchttp.HTTP,
+	gravityQuerier gravitytypes.QueryClient,
+	bankQuerier banktypes.QueryClient,
+	ethClient *ethclient.Client,
+	ethGravity *wrappers.Gravity,
+) (service, error) {
+	endpoint := konfig.String(flagHeartbeatEndpoint)
+	if endpoint == "" {
+		return nil, nil
+	}
+
+	publisher, err := heartbeat.NewPublisher(
+		logger,
+		heartbeat.Config{
+			Endpoint:            endpoint,
+			Interval:            konfig.Duration(flagHeartbeatInterval),
+			Moniker:             konfig.String(flagHeartbeatMoniker),
+			OrchestratorAddress: orchAddress,
+			EthAddress:          ethAddress,
+			Labels:              labels,
+		},
+		personalSignFn,
+		tmRPC,
+		gravityQuerier,
+		bankQuerier,
+		ethClient,
+		ethGravity,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create heartbeat publisher: %w", err)
+	}
+
+	return publisher.Start, nil
+}
+
+// newAnalyticsService returns the service aggregating the bridge usage, or nil
+// if it's disabled.
+func newAnalyticsService(
+	konfig *koanf.Koanf,
+	logger zerolog.Logger,
+	labels map[string]string,
+	st *store.Store,
+	ethProvider provider.EVMProvider,
+	ethGravity *wrappers.Gravity,
+	gravityVersion versions.Version,
+) (service, error) {
+	if konfig.String(flagAnalyticsDir) == "" && konfig.String(flagAnalyticsWebhook) == "" {
+		return nil, nil
+	}
 
-			cosmosGasPrices := konfig.String(flagCosmosGasPrices)
+	analyticsSchedule, err := parseSchedule(konfig, flagAnalyticsSchedule)
+	if err != nil {
+		return nil, err
+	}
 
-			tmRPC, err := rpchttp.New(tmRPCEndpoint, "/websocket")
-			if err != nil {
-				return fmt.Errorf("failed to create Tendermint RPC client: %w", err)
-			}
+	aggregator, err := analytics.New(
+		logger,
+		analytics.Config{
+			Interval:      konfig.Duration(flagAnalyticsInterval),
+			Schedule:      analyticsSchedule,
+			Confirmations: uint64(konfig.Int64(flagAnalyticsConfirmations)),
+			StartHeight:   uint64(konfig.Int64(flagAnalyticsStartHeight)),
+			Dir:           konfig.String(flagAnalyticsDir),
+			Format:        konfig.String(flagAnalyticsFormat),
+			WebhookURL:    konfig.String(flagAnalyticsWebhook),
+			Labels:        labels,
+		},
+		st,
+		analytics.NewEthereumSource(logger, ethProvider, ethGravity, gravityVersion.ABI),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create analytics aggregator: %w", err)
+	}
 
-			fmt.Fprintf(os.Stderr, "Connected to Tendermint RPC: %s\n", tmRPCEndpoint)
+	return aggregator.Start, nil
+}
 
-			var feeGranter sdk.AccAddress
-			if v := konfig.String(flagCosmosFeeGranter); len(v) > 0 {
-				feeGranter, err = sdk.AccAddressFromBech32(v)
-				if err != nil {
-					return fmt.Errorf("failed to parse fee granter address: %w", err)
-				}
-			}
+// newCohortService returns the service exporting the relayer cohort metrics, or
+// nil if they're disabled. The cohort is only exported as metrics.
+func newCohortService(
+	konfig *koanf.Koanf,
+	logger zerolog.Logger,
+	registerer prometheus.Registerer,
+	ethProvider provider.EVMProvider,
+	ethGravity *wrappers.Gravity,
+	gravityVersion versions.Version,
+	gravityParams *gravitytypes.Params,
+	ethAddress ethcmn.Address,
+) (service, error) {
+	blocks := konfig.Int64(flagCohortBlocks)
+	if registerer == nil || blocks <= 0 {
+		return nil, nil
+	}
 
-			clientCtx = clientCtx.WithClient(tmRPC).WithNodeURI(tmRPCEndpoint).WithFeeGranterAddress(feeGranter)
+	monitor, err := cohort.NewMonitor(
+		logger,
+		cohort.NewScanner(
+			logger,
+			ethProvider,
+			ethGravity,
+			gravityVersion.ABI,
+			batchTimeoutBlocks(gravityParams),
+		),
+		uint64(blocks),
+		ethAddress,
+		registerer,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create relayer cohort monitor: %w", err)
+	}
 
-			daemonClient, err := client.NewCosmosClient(clientCtx, logger, cosmosGRPC, client.OptionGasPrices(cosmosGasPrices))
-			if err != nil {
-				return err
-			}
+	return func(ctx context.Context) error {
+		return monitor.Start(ctx, konfig.Duration(flagCohortInterval))
+	}, nil
+}
 
-			// TODO: Clean this up to be more ergonomic and clean. We can probably
-			// encapsulate all of this into a single utility function that gracefully
-			// checks for the gRPC status/health.
-			//
-			// Ref: https://github.com/umee-network/peggo/issues/2
-			fmt.Fprintln(os.Stderr, "Waiting for cosmos gRPC service...")
-			time.Sleep(time.Second)
+// newTopupService returns the service running the top-up actions, or nil if no
+// threshold is set.
+func newTopupService(
+	konfig *koanf.Koanf,
+	logger zerolog.Logger,
+	ethClient topup.BalanceClient,
+	broadcaster topup.MsgBroadcaster,
+	orchAddress sdk.AccAddress,
+	ethAddress ethcmn.Address,
+) (service, error) {
+	if konfig.String(flagTopupThreshold) == "" {
+		return nil, nil
+	}
 
-			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
-			defer cancel()
+	monitor, err := newTopupMonitor(konfig, logger, ethClient, broadcaster, orchAddress, ethAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create top-up monitor: %w", err)
+	}
 
-			gRPCConn := daemonClient.QueryClient()
-			waitForService(ctx, gRPCConn)
+	return monitor.Start, nil
+}
 
-			gravityQuerier := gravitytypes.NewQueryClient(gRPCConn)
+// newInvariantService returns the service checking the Gravity balances against
+// the bridged supply, or nil if it's disabled.
+func newInvariantService(
+	konfig *koanf.Koanf,
+	logger zerolog.Logger,
+	gravityAddr ethcmn.Address,
+	bankQuerier banktypes.QueryClient,
+	ethProvider provider.EVMProvider,
+) (service, error) {
+	interval := konfig.Duration(flagInvariantInterval)
+	if interval <= 0 {
+		return nil, nil
+	}
 
-			gravityParams, err := getGravityParams(gRPCConn)
-			if err != nil {
-				return fmt.Errorf("failed to query for Gravity params: %w", err)
-			}
+	monitor, err := invariant.NewMonitor(
+		logger,
+		invariant.Config{
+			Interval:       interval,
+			Tolerance:      konfig.Float64(flagInvariantTolerance),
+			GravityAddress: gravityAddr,
+		},
+		bankQuerier,
+		invariant.NewTokenBalancer(ethProvider),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invariant monitor: %w", err)
+	}
 
-			ethChainID := gravityParams.BridgeChainId
-			ethKeyFromAddress, signerFn, personalSignFn, err := initEthereumAccountsManager(logger, ethChainID, konfig)
-			if err != nil {
-				return fmt.Errorf("failed to initialize Ethereum account: %w", err)
-			}
+	return monitor.Start, nil
+}
 
-			ethRPCEndpoint := konfig.String(flagEthRPC)
-			ethRPC, err := ethrpc.Dial(ethRPCEndpoint)
-			if err != nil {
-				return fmt.Errorf("failed to dial Ethereum RPC node: %w", err)
-			}
+// newPriceWatchdogService returns the service comparing the oracle prices with
+// the chain's exchange rates, or nil if it's disabled.
+func newPriceWatchdogService(
+	konfig *koanf.Koanf,
+	logger zerolog.Logger,
+	oracleQuerier oracletypes.QueryClient,
+	o *oracle.Oracle,
+) (service, error) {
+	interval := konfig.Duration(flagOracleWatchdogInterval)
+	if interval <= 0 {
+		return nil, nil
+	}
 
-			fmt.Fprintf(os.Stderr, "Connected to Ethereum RPC: %s\n", ethRPCEndpoint)
-			ethProvider := provider.NewEVMProvider(ethRPC)
+	watchdog, err := pricewatch.NewWatchdog(
+		logger,
+		pricewatch.Config{
+			Interval:  interval,
+			Tolerance: konfig.Float64(flagOracleWatchdogTolerance),
+			Sustain:   konfig.Duration(flagOracleWatchdogSustain),
+		},
+		oracleQuerier,
+		o,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create price watchdog: %w", err)
+	}
 
-			ethGasPriceAdjustment := konfig.Float64(flagEthGasAdjustment)
-			ethGasLimitAdjustment := konfig.Float64(flagEthGasLimitAdjustment)
-			ethCommitter, err := committer.NewEthCommitter(
-				logger,
-				ethKeyFromAddress,
-				ethGasPriceAdjustment,
-				ethGasLimitAdjustment,
-				signerFn,
-				ethProvider,
-			)
-			if err != nil && err != grpc.ErrServerStopped {
-				return fmt.Errorf("failed to create Ethereum committer: %w", err)
-			}
+	return watchdog.Start, nil
+}
 
-			gravityBroadcaster := cosmos.NewGravityBroadcastClient(
-				logger,
-				gravityQuerier,
-				daemonClient,
-				signerFn,
-				personalSignFn,
-				konfig.Int(flagCosmosMsgsPerTx),
-			)
+// startSafeMode resolves the txs left in flight by the previous process when it
+// exited uncleanly, unless safe mode is skipped, and then marks the journal as
+// running.
+func startSafeMode(
+	konfig *koanf.Koanf,
+	logger zerolog.Logger,
+	journal *safemode.Journal,
+	ethClient safemode.EthereumClient,
+	ethAddress ethcmn.Address,
+	clientCtx sdkclient.Context,
+	orchAddress sdk.AccAddress,
+) error {
+	pending := journal.Pending()
+
+	switch {
+	case !journal.Unclean() || len(pending) == 0:
+
+	case konfig.Bool(flagSkipSafeMode):
+		logger.Warn().
+			Int("txs", len(pending)).
+			Msg("skipping safe mode; ignoring the txs left in flight by the previous process")
 
-			if !ethcmn.IsHexAddress(args[0]) {
-				return fmt.Errorf("invalid gravity address: %s", args[0])
-			}
-			gravityAddr := ethcmn.HexToAddress(args[0])
+	default:
+		cosmosSequence := func(context.Context) (uint64, error) {
+			_, seq, err := clientCtx.AccountRetriever.GetAccountNumberSequence(clientCtx, orchAddress)
+			return seq, err
+		}
 
-			ethGravity, err := wrappers.NewGravity(gravityAddr, ethCommitter.Provider())
-			if err != nil {
-				return fmt.Errorf("failed to create a new instance of Gravity: %w", err)
-			}
+		resolver, err := safemode.NewResolver(
+			logger,
+			safemode.Config{
+				Timeout:         konfig.Duration(flagSafeModeTimeout),
+				EthereumAddress: ethAddress,
+			},
+			journal,
+			ethClient,
+			clientCtx.Client,
+			cosmosSequence,
+		)
+		if err != nil {
+			return err
+		}
 
-			gravityContract, err := gravity.NewGravityContract(logger, ethCommitter, gravityAddr, ethGravity)
-			if err != nil {
-				return fmt.Errorf("failed to create Ethereum committer: %w", err)
-			}
+		if _, err := resolver.Resolve(context.Background()); err != nil {
+			return fmt.Errorf(
+				"safe mode: %w; resolve them manually and restart, or restart with --%s",
+				err,
+				flagSkipSafeMode,
+			)
+		}
+	}
 
-			symbolRetriever := coingecko.NewCoingecko(logger, &coingecko.Config{
-				BaseURL: konfig.String(flagCoinGeckoAPI),
-			})
+	return journal.Start()
+}
 
-			// gravityParams.AverageBlockTime and gravityParams.AverageEthereumBlockTime are in milliseconds.
-			averageCosmosBlockTime := time.Duration(gravityParams.AverageBlockTime) * time.Millisecond
-			averageEthBlockTime := time.Duration(gravityParams.AverageEthereumBlockTime) * time.Millisecond
+// newKeyPolicy returns the key usage policy enforced on the txs signed by the
+// orchestrator, or nil if no limit is set. The Gravity contracts are always
+// allowed.
+func newKeyPolicy(konfig *koanf.Koanf, logger zerolog.Logger, gravityAddrs []ethcmn.Address) (*policy.Policy, error) {
+	labels, err := parseInstanceLabels(konfig.Strings(flagInstanceLabel))
+	if err != nil {
+		return nil, err
+	}
 
-			// We multiply the relayer loop multiplier by the ETH block time.
-			// gravityParams.AverageEthereumBlockTime is in milliseconds.
-			ethBlockTimeF64 := float64(averageEthBlockTime.Milliseconds())
-			relayerLoopMultiplier := konfig.Float64(flagRelayerLoopMultiplier)
+	config := policy.Config{
+		MaxTxsPerHour: konfig.Int(flagPolicyMaxTxs),
+		WebhookURL:    konfig.String(flagPolicyWebhook),
+		Labels:        labels,
+	}
 
-			// Here we cast the float64 to a Duration (int64); as we are dealing with ms, we'll lose as much as 1ms.
-			relayerLoopDuration := time.Duration(ethBlockTimeF64*relayerLoopMultiplier) * time.Millisecond
+	if maxSpend := konfig.String(flagPolicyMaxSpend); maxSpend != "" {
+		amount, err := decimal.NewFromString(maxSpend)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key usage policy maximum spend: %w", err)
+		}
 
-			valsetRelayMode, err := validateRelayValsetsMode(konfig.String(flagValsetRelayMode))
-			if err != nil {
-				return err
-			}
+		config.MaxSpendPerHour = amount.Shift(18).BigInt()
+	}
 
-			ctx, cancel = context.WithCancel(context.Background())
-			// listen for and trap any OS signal to gracefully shutdown and exit
-			trapSignal(cancel)
+	if contracts := konfig.Strings(flagPolicyContracts); len(contracts) > 0 {
+		config.AllowedContracts = append([]ethcmn.Address{}, gravityAddrs...)
 
-			providers := konfig.Strings(flagOracleProviders)
-			o, err := oracle.New(ctx, logger.With().Str("module", "oracle").Logger(), stringsToProviderName(providers))
-			if err != nil {
-				return err
+		for _, contract := range contracts {
+			if !ethcmn.IsHexAddress(contract) {
+				return nil, fmt.Errorf("invalid key usage policy contract: %s", contract)
 			}
 
-			if err := o.SubscribeSymbols(oracle.SymbolETH); err != nil {
-				return err
-			}
+			config.AllowedContracts = append(config.AllowedContracts, ethcmn.HexToAddress(contract))
+		}
+	}
 
-			relayer := relayer.NewGravityRelayer(
-				logger,
-				gravityQuerier,
-				gravityContract,
-				valsetRelayMode,
-				konfig.Bool(flagRelayBatches),
-				relayerLoopDuration,
-				konfig.Duration(flagEthPendingTXWait),
-				konfig.Float64(flagProfitMultiplier),
-				relayer.SetSymbolRetriever(symbolRetriever),
-				relayer.SetOracle(o),
-			)
+	if config.MaxSpendPerHour == nil && config.MaxTxsPerHour == 0 && config.AllowedContracts == nil {
+		if config.WebhookURL != "" {
+			return nil, fmt.Errorf("--%s is set but no key usage limit is", flagPolicyWebhook)
+		}
 
-			logger = logger.With().
-				Str("relayer_orchestrator_addr", orchAddress.String()).
-				Str("relayer_ethereum_addr", ethKeyFromAddress.String()).
-				Logger()
+		return nil, nil
+	}
 
-			// at this point we already setup price-feeder logs, so we don't need them on gcloud
-			logger = handleGCPLogging(ctx, konfig, logger)
+	return policy.New(logger, config)
+}
 
-			// Run the requester loop every approximately 60 Cosmos blocks (around 5m by default) to allow time to
-			// receive new transactions. Running this faster will cause a lot of small batches and lots of messages
-			// going around the network. We need to keep in mind that this call is going to be made by all the
-			// validators. This loop is configurable so it can be adjusted for E2E tests.
+// newTopupMonitor returns a monitor that runs the configured top-up actions when
+// the orchestrator's ETH balance drops below the threshold.
+func newTopupMonitor(
+	konfig *koanf.Koanf,
+	logger zerolog.Logger,
+	ethClient topup.BalanceClient,
+	broadcaster topup.MsgBroadcaster,
+	orchAddress sdk.AccAddress,
+	ethAddress ethcmn.Address,
+) (*topup.Monitor, error) {
+	threshold, err := decimal.NewFromString(konfig.String(flagTopupThreshold))
+	if err != nil {
+		return nil, fmt.Errorf("invalid top-up threshold: %w", err)
+	}
 
-			cosmosBlockTimeF64 := float64(averageCosmosBlockTime.Milliseconds())
-			requesterLoopMultiplier := konfig.Float64(flagRequesterLoopMultiplier)
+	labels, err := parseInstanceLabels(konfig.Strings(flagInstanceLabel))
+	if err != nil {
+		return nil, err
+	}
 
-			// Here we cast the float64 to a Duration (int64); as we are dealing with ms, we'll lose as much as 1ms.
-			batchRequesterLoopDuration := time.Duration(cosmosBlockTimeF64*requesterLoopMultiplier) * time.Millisecond
+	checkSchedule, err := parseSchedule(konfig, flagTopupSchedule)
+	if err != nil {
+		return nil, err
+	}
 
-			orch := orchestrator.NewGravityOrchestrator(
-				logger,
-				gravityQuerier,
-				gravityBroadcaster,
-				gravityContract,
-				ethKeyFromAddress,
-				signerFn,
-				personalSignFn,
-				relayer,
-				averageCosmosBlockTime,
-				averageEthBlockTime,
-				batchRequesterLoopDuration,
-				konfig.Int64(flagEthBlocksPerLoop),
-				konfig.Int64(flagBridgeStartHeight),
-				symbolRetriever,
-				o,
-				konfig.Bool(flagEthMergePause),
-			)
+	config := topup.Config{
+		Interval:            konfig.Duration(flagTopupInterval),
+		Schedule:            checkSchedule,
+		Threshold:           threshold.Shift(18).BigInt(),
+		Cooldown:            konfig.Duration(flagTopupCooldown),
+		EthAddress:          ethAddress,
+		OrchestratorAddress: orchAddress,
+		WebhookURL:          konfig.String(flagTopupWebhook),
+		Labels:              labels,
+	}
 
-			g, errCtx := errgroup.WithContext(ctx)
+	if amount := konfig.String(flagTopupSendToEth); amount != "" {
+		if config.SendToEthAmount, err = sdk.ParseCoinNormalized(amount); err != nil {
+			return nil, fmt.Errorf("invalid top-up SendToEth amount: %w", err)
+		}
+	}
 
-			g.Go(func() error {
-				return startOrchestrator(errCtx, logger, orch)
-			})
+	if fee := konfig.String(flagTopupBridgeFee); fee != "" {
+		if config.SendToEthBridgeFee, err = sdk.ParseCoinNormalized(fee); err != nil {
+			return nil, fmt.Errorf("invalid top-up bridge fee: %w", err)
+		}
+	}
 
-			// If we have the alchemy WS endpoint, start listening for txs against the Gravity Bridge contract.
-			alchemyWS := konfig.String(flagEthAlchemyWS)
-			if alchemyWS != "" {
-				g.Go(func() error {
-					return gravityContract.SubscribeToPendingTxs(errCtx, alchemyWS)
-				})
-			}
+	return topup.NewMonitor(logger, config, ethClient, broadcaster)
+}
 
-			return g.Wait()
-		},
+// priceAlertOptions returns the oracle options sending the price alerts to the
+// configured webhook, if any.
+func priceAlertOptions(konfig *koanf.Koanf, logger zerolog.Logger) ([]oracle.Option, error) {
+	webhookURL := konfig.String(flagOracleAlertWebhook)
+	if webhookURL == "" {
+		return nil, nil
 	}
 
-	// GCP logging flags
-	cmd.Flags().String(flagGcpLogProjectName, "", "Set an the Google Cloud Project for logging")
-	cmd.Flags().String(flagGcpLogMoniker, "", "Specify your moniker to be identified in logs")
-	cmd.Flags().String(flagGcpLogLevel, zerolog.InfoLevel.String(), "Specify the log level to send to Google Cloud")
+	threshold, err := sdk.NewDecFromStr(konfig.String(flagOracleAlertMove))
+	if err != nil || !threshold.IsPositive() {
+		return nil, fmt.Errorf("invalid --%s; expected a positive number", flagOracleAlertMove)
+	}
 
-	// Orch flags
-	cmd.Flags().String(flagValsetRelayMode, relayer.ValsetRelayModeNone.String(), "Set an (optional) relaying mode for valset updates to Ethereum. Possible values: none, minimum, all") //nolint: lll
-	cmd.Flags().Bool(flagRelayBatches, false, "Relay transaction batches to Ethereum")
-	cmd.Flags().Int64(flagEthBlocksPerLoop, 2000, "Number of Ethereum blocks to process per orchestrator loop")
-	cmd.Flags().String(flagCoinGeckoAPI, "https://api.coingecko.com/api/v3", "Specify the coingecko API endpoint")
-	cmd.Flags().Bool(flagEthMergePause, false, "Pause some messages related to the adaptation of the Gravity Bridge to the merge") //nolint: lll
+	labels, err := parseInstanceLabels(konfig.Strings(flagInstanceLabel))
+	if err != nil {
+		return nil, err
+	}
 
-	defaultProviders := []string{
-		umeepfprovider.ProviderOsmosis.String(),
-		umeepfprovider.ProviderHuobi.String(),
-		umeepfprovider.ProviderOkx.String(),
-		umeepfprovider.ProviderCoinbase.String(),
-		umeepfprovider.ProviderBitget.String(),
-		umeepfprovider.ProviderMexc.String(),
-		umeepfprovider.ProviderCrypto.String(),
+	webhook, err := pricealert.NewWebhook(logger, pricealert.Config{WebhookURL: webhookURL, Labels: labels})
+	if err != nil {
+		return nil, err
 	}
 
-	allProviders := append([]string{
-		umeepfprovider.ProviderKraken.String(),
-		umeepfprovider.ProviderGate.String(),
-		umeepfprovider.ProviderMock.String(),
-		umeepfprovider.ProviderBinance.String(),
-	}, defaultProviders...)
+	return []oracle.Option{oracle.OptionPriceMoveThreshold(threshold), oracle.OptionPriceAlertHook(webhook.Hook)}, nil
+}
 
-	cmd.Flags().StringSlice(flagOracleProviders, defaultProviders,
-		fmt.Sprintf("Specify the providers to use in the oracle, options \"%s\"", strings.Join(allProviders, ",")))
-	cmd.Flags().Duration(flagEthPendingTXWait, 20*time.Minute, "Time for a pending tx to be considered stale")
-	cmd.Flags().String(flagEthAlchemyWS, "", "Specify the Alchemy websocket endpoint")
-	cmd.Flags().Float64(flagProfitMultiplier, 1.0, "Multiplier to apply to relayer profit")
-	cmd.Flags().Float64(flagRelayerLoopMultiplier, 3.0, "Multiplier for the relayer loop duration (in ETH blocks)")
-	cmd.Flags().Float64(flagRequesterLoopMultiplier, 60.0, "Multiplier for the batch requester loop duration (in Cosmos blocks)")             //nolint: lll
-	cmd.Flags().String(flagCosmosFeeGranter, "", "Set an (optional) fee granter address that will pay for Cosmos fees (feegrant must exist)") //nolint: lll
-	cmd.Flags().Int64(flagBridgeStartHeight, 0, "Set an (optional) height to wait for the bridge to be available")
-	cmd.Flags().Int(flagCosmosMsgsPerTx, 10, "Set a maximum number of messages to send per transaction (used for claims)")
-	cmd.Flags().AddFlagSet(cosmosFlagSet())
-	cmd.Flags().AddFlagSet(cosmosKeyringFlagSet())
-	cmd.Flags().AddFlagSet(ethereumKeyOptsFlagSet())
-	cmd.Flags().AddFlagSet(ethereumOptsFlagSet())
+// parseFeeTokens parses the tokens the Cosmos fee denoms are valued with, given
+// as denom=SYMBOL:decimals (e.g. uumee=UMEE:6).
+func parseFeeTokens(values []string) (map[string]totals.Token, error) {
+	tokens := make(map[string]totals.Token, len(values))
 
-	return cmd
+	for _, v := range values {
+		denom, token, _ := strings.Cut(v, "=")
+		symbol, decimals, ok := strings.Cut(token, ":")
+		denom, symbol = strings.TrimSpace(denom), strings.ToUpper(strings.TrimSpace(symbol))
+
+		n, err := strconv.ParseUint(strings.TrimSpace(decimals), 10, 8)
+		if !ok || err != nil || sdk.ValidateDenom(denom) != nil || symbol == "" {
+			return nil, fmt.Errorf("invalid --%s %q; expected denom=SYMBOL:decimals (e.g. uumee=UMEE:6)", flagCosmosFeeTokens, v)
+		}
+
+		tokens[denom] = totals.Token{Symbol: symbol, Decimals: uint8(n)}
+	}
+
+	return tokens, nil
 }
 
 func trapSignal(cancel context.CancelFunc) {
@@ -431,6 +2132,25 @@ func validateRelayValsetsMode(mode string) (relayer.ValsetRelayMode, error) {
 	}
 }
 
+// validateSkipEventsBeforeNonce checks that skipping unclaimed events, which
+// can't be undone, was confirmed by repeating the nonce.
+func validateSkipEventsBeforeNonce(konfig *koanf.Koanf) error {
+	nonce := konfig.Int64(flagSkipEventsBeforeNonce)
+
+	switch {
+	case nonce < 0:
+		return fmt.Errorf("--%s must not be negative", flagSkipEventsBeforeNonce)
+
+	case nonce > 0 && konfig.Int64(flagSkipEventsConfirm) != nonce:
+		return fmt.Errorf(
+			"--%s skips Ethereum events that will never be claimed; confirm it with --%s=%d",
+			flagSkipEventsBeforeNonce, flagSkipEventsConfirm, nonce,
+		)
+	}
+
+	return nil
+}
+
 func stringsToProviderName(providersName []string) []umeepfprovider.Name {
 	names := make([]umeepfprovider.Name, len(providersName))
 	for i, name := range providersName {
@@ -439,3 +2159,258 @@ func stringsToProviderName(providersName []string) []umeepfprovider.Name {
 
 	return names
 }
+
+// oracleOptions returns the oracle options shared by the commands running one.
+func oracleOptions(konfig *koanf.Koanf) ([]oracle.Option, error) {
+	symbolAliases, err := oracle.ParseSymbolAliases(konfig.Strings(flagOracleSymbolAliases))
+	if err != nil {
+		return nil, err
+	}
+
+	deviationThresholds, err := oracle.ParseDeviationThresholds(konfig.Strings(flagDeviationThresholds))
+	if err != nil {
+		return nil, err
+	}
+
+	weights, err := providerWeights(konfig)
+	if err != nil {
+		return nil, err
+	}
+
+	providers := map[string]struct{}{}
+	for _, name := range konfig.Strings(flagOracleProviders) {
+		providers[name] = struct{}{}
+	}
+
+	osmosisPools, err := oracle.ParseOsmosisPools(konfig.Strings(flagOracleOsmosisPools))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := providers[oracle.ProviderOsmosisPool.String()]; ok {
+		if konfig.String(flagOracleOsmosisGRPC) == "" || len(osmosisPools) == 0 {
+			return nil, fmt.Errorf(
+				"the %s provider requires --%s and --%s",
+				oracle.ProviderOsmosisPool, flagOracleOsmosisGRPC, flagOracleOsmosisPools,
+			)
+		}
+	}
+
+	uniswapV3Pools, err := oracle.ParseUniswapV3Pools(konfig.Strings(flagOracleUniswapV3Pools))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := providers[oracle.ProviderUniswapV3.String()]; ok && len(uniswapV3Pools) == 0 {
+		return nil, fmt.Errorf("the %s provider requires --%s", oracle.ProviderUniswapV3, flagOracleUniswapV3Pools)
+	}
+
+	staticFile := konfig.String(flagOracleStaticFile)
+	if _, ok := providers[oracle.ProviderStaticFile.String()]; ok {
+		if staticFile == "" {
+			return nil, fmt.Errorf("the %s provider requires --%s", oracle.ProviderStaticFile, flagOracleStaticFile)
+		}
+
+		if _, err := oracle.LoadStaticPrices(staticFile); err != nil {
+			return nil, err
+		}
+	}
+
+	opts := []oracle.Option{
+		oracle.OptionSymbolAliases(symbolAliases),
+		oracle.OptionDeviationThresholds(deviationThresholds),
+		oracle.OptionProviderWeights(weights),
+	}
+
+	if len(osmosisPools) > 0 {
+		opts = append(opts, oracle.OptionOsmosisPools(konfig.String(flagOracleOsmosisGRPC), osmosisPools))
+	}
+
+	if len(uniswapV3Pools) > 0 {
+		opts = append(opts, oracle.OptionUniswapV3Pools(uniswapV3Pools))
+	}
+
+	if staticFile != "" {
+		opts = append(opts, oracle.OptionStaticPricesFile(staticFile, konfig.Bool(flagOracleStaticWatch)))
+	}
+
+	tickInterval := konfig.Duration(flagOracleTickInterval)
+	if tickInterval < oracle.MinTickInterval {
+		return nil, fmt.Errorf("--%s must be at least %s", flagOracleTickInterval, oracle.MinTickInterval)
+	}
+
+	maxAge := konfig.Duration(flagOraclePriceMaxAge)
+	if maxAge < 0 {
+		return nil, fmt.Errorf("--%s must not be negative", flagOraclePriceMaxAge)
+	}
+
+	interpolationGap := konfig.Duration(flagOracleInterpolationGap)
+	if interpolationGap < 0 {
+		return nil, fmt.Errorf("--%s must not be negative", flagOracleInterpolationGap)
+	}
+
+	candleFreshness := konfig.Duration(flagOracleCandleFreshness)
+	if candleFreshness < 0 {
+		return nil, fmt.Errorf("--%s must not be negative", flagOracleCandleFreshness)
+	}
+
+	aggregation := konfig.String(flagOracleAggregation)
+	if err := oracle.ValidateAggregation(aggregation); err != nil {
+		return nil, err
+	}
+
+	computeWorkers := konfig.Int(flagOracleComputeWorkers)
+	if computeWorkers < 1 {
+		return nil, fmt.Errorf("--%s must be positive", flagOracleComputeWorkers)
+	}
+
+	fetchTimeout := konfig.Duration(flagOracleFetchTimeout)
+	if fetchTimeout < 0 {
+		return nil, fmt.Errorf("--%s must not be negative", flagOracleFetchTimeout)
+	}
+
+	breakerFailures := konfig.Int(flagOracleBreakerFailures)
+	if breakerFailures < 0 {
+		return nil, fmt.Errorf("--%s must not be negative", flagOracleBreakerFailures)
+	}
+
+	opts = append(
+		opts,
+		oracle.OptionTickInterval(tickInterval),
+		oracle.OptionPriceMaxAge(maxAge),
+		oracle.OptionInterpolationGap(interpolationGap),
+		oracle.OptionAggregation(aggregation),
+		oracle.OptionComputeWorkers(computeWorkers),
+		oracle.OptionFetchTimeout(fetchTimeout),
+		oracle.OptionCandleBackfill(konfig.Bool(flagOracleCandleBackfill)),
+		oracle.OptionCandleFreshness(candleFreshness),
+		oracle.OptionProviderBreaker(
+			breakerFailures,
+			konfig.Duration(flagOracleBreakerBackoff),
+			konfig.Duration(flagOracleBreakerMaxBackoff),
+		),
+	)
+
+	for flag, option := range map[string]func(schedule.Schedule) oracle.Option{
+		flagOraclePairsSchedule:     oracle.OptionPairsReloadSchedule,
+		flagOracleReconcileSchedule: oracle.OptionReconcileSchedule,
+	} {
+		s, err := parseSchedule(konfig, flag)
+		if err != nil {
+			return nil, err
+		}
+
+		if s != nil {
+			opts = append(opts, option(s))
+		}
+	}
+
+	depegThreshold, err := sdk.NewDecFromStr(konfig.String(flagOracleDepegThreshold))
+	if err != nil || depegThreshold.IsNegative() || depegThreshold.GTE(sdk.OneDec()) {
+		return nil, fmt.Errorf("invalid --%s; expected a number in [0, 1)", flagOracleDepegThreshold)
+	}
+	opts = append(opts, oracle.OptionStablecoinDepegThreshold(depegThreshold))
+
+	if v := konfig.String(flagDeviationThreshold); v != "" {
+		threshold, err := sdk.NewDecFromStr(v)
+		if err != nil || !threshold.IsPositive() {
+			return nil, fmt.Errorf("invalid --%s %q; expected a positive number", flagDeviationThreshold, v)
+		}
+
+		opts = append(opts, oracle.OptionDeviationThreshold(threshold))
+	}
+
+	return opts, nil
+}
+
+// providerLimiters returns the rate limiters of the oracle providers and the
+// CoinGecko API, to be shared by everything querying them, and their API keys.
+func providerLimiters(
+	konfig *koanf.Koanf,
+) (map[umeepfprovider.Name]*oracle.Limiter, map[umeepfprovider.Name]string, error) {
+	limits, keys, err := providerLimits(konfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return oracle.NewProviderLimiters(limits), keys, nil
+}
+
+// providerLimits returns the rate limits of the oracle providers and the
+// CoinGecko API, and their API keys.
+func providerLimits(
+	konfig *koanf.Koanf,
+) (map[umeepfprovider.Name]oracle.ProviderLimit, map[umeepfprovider.Name]string, error) {
+	limits, err := oracle.ParseProviderLimits(konfig.Strings(flagOracleProviderLimits))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid --%s: %w", flagOracleProviderLimits, err)
+	}
+
+	keys, err := oracle.ParseProviderKeys(konfig.Strings(flagOracleProviderKeys))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid --%s: %w", flagOracleProviderKeys, err)
+	}
+
+	providers := map[umeepfprovider.Name]struct{}{oracle.ProviderCoinGecko: {}}
+	for _, name := range konfig.Strings(flagOracleProviders) {
+		providers[umeepfprovider.Name(name)] = struct{}{}
+	}
+	for name := range limits {
+		if _, ok := providers[name]; !ok {
+			return nil, nil, fmt.Errorf("invalid --%s: %s isn't an oracle provider", flagOracleProviderLimits, name)
+		}
+	}
+	for name := range keys {
+		// the exchange providers query public endpoints without any key
+		if _, ok := keyedProviders[name]; !ok {
+			return nil, nil, fmt.Errorf("invalid --%s: %s doesn't take an API key", flagOracleProviderKeys, name)
+		}
+	}
+
+	return limits, keys, nil
+}
+
+// keyedProviders are the providers taking an API key.
+var keyedProviders = map[umeepfprovider.Name]struct{}{
+	oracle.ProviderCoinGecko:   {},
+	oracle.ProviderOsmosisPool: {},
+}
+
+// providerWeights returns the weights of the oracle providers.
+func providerWeights(konfig *koanf.Koanf) (map[umeepfprovider.Name]sdk.Dec, error) {
+	weights, err := oracle.ParseProviderWeights(konfig.Strings(flagOracleProviderWeights))
+	if err != nil {
+		return nil, err
+	}
+
+	providers := map[string]struct{}{}
+	for _, name := range konfig.Strings(flagOracleProviders) {
+		providers[name] = struct{}{}
+	}
+	for name := range weights {
+		if _, ok := providers[string(name)]; !ok {
+			return nil, fmt.Errorf("invalid --%s: %s isn't an oracle provider", flagOracleProviderWeights, name)
+		}
+	}
+
+	return weights, nil
+}
+
+// newCoinGecko returns a CoinGecko client using the API key and sharing the
+// rate limiter of the CoinGecko provider limits.
+func newCoinGecko(
+	logger zerolog.Logger,
+	konfig *koanf.Koanf,
+	limiters map[umeepfprovider.Name]*oracle.Limiter,
+	keys map[umeepfprovider.Name]string,
+) *coingecko.CoinGecko {
+	config := &coingecko.Config{
+		BaseURL: konfig.String(flagCoinGeckoAPI),
+		APIKey:  keys[oracle.ProviderCoinGecko],
+	}
+	if limiter, ok := limiters[oracle.ProviderCoinGecko]; ok {
+		config.Limiter = limiter
+	}
+
+	return coingecko.NewCoingecko(logger, config)
+}
@@ -0,0 +1,57 @@
+package peggo
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/knadh/koanf"
+)
+
+// The formats commands print their results in, set globally with --output.
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+func validateOutput(output string) error {
+	switch output {
+	case outputText, outputJSON:
+		return nil
+
+	default:
+		return fmt.Errorf("invalid --%s: %s; expected %s or %s", flagOutput, output, outputText, outputJSON)
+	}
+}
+
+// isJSONOutput returns true if the command prints its result as JSON, either
+// with the global --output flag or the --format flag of the commands that had
+// one before it.
+func isJSONOutput(konfig *koanf.Koanf) bool {
+	return konfig.String(flagOutput) == outputJSON || konfig.String(flagFormat) == outputJSON
+}
+
+// txResult is the result of the commands sending an Ethereum transaction with
+// --output=json.
+type txResult struct {
+	Type        string `json:"type,omitempty"`
+	TxHash      string `json:"tx_hash"`
+	BlockNumber uint64 `json:"block_number,omitempty"`
+	Address     string `json:"address,omitempty"`
+	Sender      string `json:"sender,omitempty"`
+	Recipient   string `json:"recipient,omitempty"`
+	Token       string `json:"token,omitempty"`
+	Amount      string `json:"amount,omitempty"`
+	Denom       string `json:"denom,omitempty"`
+}
+
+// printJSON prints the value as a single line of JSON on stdout. Progress and
+// human-readable messages go to stderr, so stdout can be parsed as is.
+func printJSON(v interface{}) error {
+	bz, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Println(string(bz))
+	return err
+}
@@ -1,14 +1,18 @@
 package peggo
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
 
 	"github.com/knadh/koanf"
+	"github.com/knadh/koanf/parsers/toml"
 	"github.com/knadh/koanf/providers/env"
+	"github.com/knadh/koanf/providers/file"
 	"github.com/knadh/koanf/providers/posflag"
+	"github.com/knadh/koanf/providers/rawbytes"
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
 )
@@ -21,18 +25,33 @@ func NewRootCmd() *cobra.Command {
 
 Inputs in the CLI commands can be provided via flags or environment variables. If
 using the later, prefix the environment variable with PEGGO_ and the named of the
-flag (e.g. PEGGO_COSMOS_PK).`,
+flag (e.g. PEGGO_COSMOS_PK).
+
+With --output=json, query and tx commands print their results (e.g. transaction
+hashes and raw responses) as JSON on stdout instead.`,
 	}
 
 	cmd.PersistentFlags().String(flagLogLevel, zerolog.InfoLevel.String(), "logging level")
 	cmd.PersistentFlags().String(flagLogFormat, logLevelText, "logging format (text|json)")
+	cmd.PersistentFlags().String(flagHome, defaultHome(), "Directory used to persist local peggo state")
+	cmd.PersistentFlags().String(flagConfig, "", "Path to an (optional) TOML config file, keyed by flag name (- reads it from STDIN)") //nolint: lll
+	cmd.PersistentFlags().String(flagOutput, outputText, "Print command results in the given format (text|json)")
 	cmd.PersistentFlags().String(flagSvcWaitTimeout, "1m", "Standard wait timeout for external services (e.g. Cosmos daemon gRPC connection)") //nolint: lll
 
 	cmd.AddCommand(
 		getOrchestratorCmd(),
+		getExporterCmd(),
+		getConfigCmd(),
+		getSignerCmd(),
 		getBridgeCommand(),
 		getQueryCmd(),
 		getTxCmd(),
+		getRelayerCmd(),
+		getStateCmd(),
+		getSimulateCmd(),
+		getDebugCmd(),
+		getStatusCmd(),
+		getSchemaCmd(),
 		getVersionCmd(),
 	)
 
@@ -75,29 +94,98 @@ func getLogger(cmd *cobra.Command) (zerolog.Logger, error) {
 //
 // - flags
 // - environment variables
-// - configuration file (TOML) (TODO)
+// - configuration file (TOML), read from STDIN if its path is "-"
+//
+// The secret flags set to "-" (see stdinSecretFlags) are read from STDIN.
 func parseServerConfig(cmd *cobra.Command) (*koanf.Koanf, error) {
 	konfig := koanf.New(".")
 
 	// load from file first (if provided)
-	// TODO: Support config files if/when needed.
-	// if configPath := ctx.String(config.ConfigPath); len(configPath) != 0 {
-	// 	if err := konfig.Load(file.Provider(configPath), toml.Parser()); err != nil {
-	// 		return nil, err
-	// 	}
-	// }
+	configPath, err := cmd.Flags().GetString(flagConfig)
+	if err != nil {
+		return nil, err
+	}
 
-	// load from environment variables
-	if err := konfig.Load(env.Provider("PEGGO_", ".", func(s string) string {
-		return strings.ReplaceAll(strings.ToLower(strings.TrimPrefix(s, "PEGGO_")), "_", "-")
-	}), nil); err != nil {
+	switch {
+	case configPath == stdinValue:
+		bz, err := readStdinConfig(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+
+		err = konfig.Load(rawbytes.Provider(bz), toml.Parser())
+		zeroBytes(bz)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config from STDIN: %w", err)
+		}
+
+	case len(configPath) != 0:
+		if err := konfig.Load(file.Provider(configPath), toml.Parser()); err != nil {
+			return nil, fmt.Errorf("failed to load config file: %w", err)
+		}
+	}
+
+	if err := loadEnvAndFlags(konfig, cmd); err != nil {
 		return nil, err
 	}
 
-	// finally, load from command line flags
-	if err := konfig.Load(posflag.Provider(cmd.Flags(), ".", konfig), nil); err != nil {
+	// the output may be set by the config file or PEGGO_OUTPUT too
+	if err := validateOutput(konfig.String(flagOutput)); err != nil {
+		return nil, err
+	}
+
+	// the secrets set to "-" are read from STDIN, unless it held the config
+	for _, flag := range stdinSecretFlags {
+		if configPath == stdinValue && konfig.String(flag) == stdinValue {
+			return nil, fmt.Errorf("--%s can't be read from STDIN along with the config; set it in the config instead", flag)
+		}
+	}
+
+	if err := readStdinSecrets(os.Stdin, konfig); err != nil {
 		return nil, err
 	}
 
 	return konfig, nil
 }
+
+// reloadServerConfig parses the configuration again, like parseServerConfig,
+// e.g. after the config file changed. The secrets are left out, as STDIN can't
+// be read again; so is a config read from STDIN.
+func reloadServerConfig(cmd *cobra.Command) (*koanf.Koanf, error) {
+	konfig := koanf.New(".")
+
+	configPath, err := cmd.Flags().GetString(flagConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case configPath == stdinValue:
+		return nil, errors.New("a config read from STDIN can't be reloaded")
+
+	case len(configPath) != 0:
+		if err := konfig.Load(file.Provider(configPath), toml.Parser()); err != nil {
+			return nil, fmt.Errorf("failed to load config file: %w", err)
+		}
+	}
+
+	if err := loadEnvAndFlags(konfig, cmd); err != nil {
+		return nil, err
+	}
+
+	return konfig, nil
+}
+
+// loadEnvAndFlags loads the environment variables, then the command line flags,
+// taking precedence over the config file.
+func loadEnvAndFlags(konfig *koanf.Koanf, cmd *cobra.Command) error {
+	// load from environment variables
+	if err := konfig.Load(env.Provider("PEGGO_", ".", func(s string) string {
+		return strings.ReplaceAll(strings.ToLower(strings.TrimPrefix(s, "PEGGO_")), "_", "-")
+	}), nil); err != nil {
+		return err
+	}
+
+	// finally, load from command line flags
+	return konfig.Load(posflag.Provider(cmd.Flags(), ".", konfig), nil)
+}
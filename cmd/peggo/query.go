@@ -1,7 +1,32 @@
 package peggo
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"gopkg.in/yaml.v2"
+
+	"github.com/umee-network/peggo/cmd/peggo/client"
+	"github.com/umee-network/peggo/orchestrator/ethereum/gravity"
+	"github.com/umee-network/peggo/orchestrator/relayer"
 )
 
 func getQueryCmd() *cobra.Command {
@@ -11,8 +36,593 @@ func getQueryCmd() *cobra.Command {
 		Short:   "Query commands that can get state info from Gravity",
 	}
 
-	// TODO: Add commands. Injective's Peggo doesn't have any at the moment.
-	cmd.AddCommand()
+	cmd.AddCommand(
+		getQueryNoncesCmd(),
+		getQueryMissingConfirmsCmd(),
+		getQueryValsetCmd(),
+		getQueryRelayStatsCmd(),
+		getQueryRelayerCohortCmd(),
+	)
+
+	return cmd
+}
+
+type noncesInfo struct {
+	Orchestrator           string `json:"orchestrator" yaml:"orchestrator"`
+	EthAddress             string `json:"eth_address" yaml:"eth_address"`
+	ChainNextEventNonce    uint64 `json:"chain_next_event_nonce" yaml:"chain_next_event_nonce"`
+	EthLastEventNonce      uint64 `json:"eth_last_event_nonce" yaml:"eth_last_event_nonce"`
+	EthAccountNoncePending uint64 `json:"eth_account_nonce_pending" yaml:"eth_account_nonce_pending"`
+	EthAccountNonceLatest  uint64 `json:"eth_account_nonce_latest" yaml:"eth_account_nonce_latest"`
+	CosmosAccountSequence  uint64 `json:"cosmos_account_sequence" yaml:"cosmos_account_sequence"`
+}
+
+func getQueryNoncesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "nonces [gravity-addr] [orchestrator-addr]",
+		Args:  cobra.ExactArgs(2),
+		Short: "Print the event, Ethereum account and Cosmos account nonces of an orchestrator",
+		Long: `Print the event, Ethereum account and Cosmos account nonces of an orchestrator.
+
+The following values are shown:
+- the event nonce the chain expects next from the orchestrator
+- the last event nonce emitted by the Gravity Bridge contract on Ethereum
+- the pending and latest transaction nonces of the orchestrator's Ethereum account
+- the sequence of the orchestrator's Cosmos account`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			konfig, err := parseServerConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			logger, err := getLogger(cmd)
+			if err != nil {
+				return err
+			}
+
+			if !ethcmn.IsHexAddress(args[0]) {
+				return fmt.Errorf("invalid gravity address: %s", args[0])
+			}
+			gravityAddr := ethcmn.HexToAddress(args[0])
+
+			orchAddr, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return fmt.Errorf("failed to Bech32 decode orchestrator address: %w", err)
+			}
+
+			clientCtx, err := client.NewClientContext(konfig.String(flagCosmosChainID), "", nil)
+			if err != nil {
+				return err
+			}
+
+			cosmosGRPC, err := parseURL(logger, konfig, flagCosmosGRPC)
+			if err != nil {
+				return err
+			}
+
+			daemonClient, err := client.NewCosmosClient(clientCtx, logger, cosmosGRPC)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			gRPCConn := daemonClient.QueryClient()
+			waitForService(ctx, gRPCConn)
+
+			gravityQuerier := gravitytypes.NewQueryClient(gRPCConn)
+
+			delegateKeys, err := gravityQuerier.GetDelegateKeyByOrchestrator(
+				ctx,
+				&gravitytypes.QueryDelegateKeysByOrchestratorAddress{OrchestratorAddress: orchAddr.String()},
+			)
+			if err != nil {
+				return fmt.Errorf("failed to query for the orchestrator delegate keys: %w", err)
+			}
+			ethAddr := ethcmn.HexToAddress(delegateKeys.EthAddress)
+
+			lastEventNonce, err := gravityQuerier.LastEventNonceByAddr(
+				ctx,
+				&gravitytypes.QueryLastEventNonceByAddrRequest{Address: orchAddr.String()},
+			)
+			if err != nil {
+				return fmt.Errorf("failed to query for the last event nonce: %w", err)
+			}
+
+			accRes, err := authtypes.NewQueryClient(gRPCConn).Account(
+				ctx,
+				&authtypes.QueryAccountRequest{Address: orchAddr.String()},
+			)
+			if err != nil {
+				return fmt.Errorf("failed to query for the orchestrator account: %w", err)
+			}
+
+			var acc authtypes.AccountI
+			if err := clientCtx.InterfaceRegistry.UnpackAny(accRes.Account, &acc); err != nil {
+				return fmt.Errorf("failed to unpack the orchestrator account: %w", err)
+			}
+
+			ethRPC, err := ethclient.Dial(konfig.String(flagEthRPC))
+			if err != nil {
+				return fmt.Errorf("failed to dial Ethereum RPC node: %w", err)
+			}
+
+			gravityContract, err := getGravityContract(ethRPC, gravityAddr)
+			if err != nil {
+				return err
+			}
+
+			ethLastEventNonce, err := gravityContract.StateLastEventNonce(&bind.CallOpts{Context: ctx})
+			if err != nil {
+				return fmt.Errorf("failed to get the last event nonce from the Gravity contract: %w", err)
+			}
+
+			pendingNonce, err := ethRPC.PendingNonceAt(ctx, ethAddr)
+			if err != nil {
+				return fmt.Errorf("failed to get the pending Ethereum account nonce: %w", err)
+			}
+
+			latestNonce, err := ethRPC.NonceAt(ctx, ethAddr, nil)
+			if err != nil {
+				return fmt.Errorf("failed to get the latest Ethereum account nonce: %w", err)
+			}
+
+			nonces := noncesInfo{
+				Orchestrator:           orchAddr.String(),
+				EthAddress:             ethAddr.Hex(),
+				ChainNextEventNonce:    lastEventNonce.EventNonce + 1,
+				EthLastEventNonce:      ethLastEventNonce.Uint64(),
+				EthAccountNoncePending: pendingNonce,
+				EthAccountNonceLatest:  latestNonce,
+				CosmosAccountSequence:  acc.GetSequence(),
+			}
+
+			if isJSONOutput(konfig) {
+				return printJSON(nonces)
+			}
+
+			bz, err := yaml.Marshal(&nonces)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Println(string(bz))
+			return err
+		},
+	}
+
+	cmd.Flags().String(flagFormat, "text", "Print the nonces in the given format (text|json)")
+	cmd.Flags().String(flagEthRPC, "http://localhost:8545", "Specify the RPC address of an Ethereum node")
+	cmd.Flags().AddFlagSet(cosmosFlagSet())
+
+	return cmd
+}
+
+type missingConfirmsInfo struct {
+	Kind          string                   `json:"kind" yaml:"kind"`
+	Nonce         uint64                   `json:"nonce" yaml:"nonce"`
+	TokenContract string                   `json:"token_contract,omitempty" yaml:"token_contract,omitempty"`
+	MissingPower  uint64                   `json:"missing_power" yaml:"missing_power"`
+	TotalPower    uint64                   `json:"total_power" yaml:"total_power"`
+	Missing       []relayer.MissingConfirm `json:"missing" yaml:"missing"`
+}
+
+func getQueryMissingConfirmsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "missing-confirms",
+		Args:  cobra.NoArgs,
+		Short: "Print the validators that haven't signed the pending valsets and batches",
+		Long: `Print the validators that haven't signed the pending valsets and batches.
+
+The latest valsets and all the batches not yet executed on Ethereum are checked.
+Valsets must be signed by their own members and batches by the members of the
+current valset. Each missing validator is shown with its moniker, operator
+address, Ethereum address and (normalized) bridge power.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			konfig, err := parseServerConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			logger, err := getLogger(cmd)
+			if err != nil {
+				return err
+			}
+
+			clientCtx, err := client.NewClientContext(konfig.String(flagCosmosChainID), "", nil)
+			if err != nil {
+				return err
+			}
+
+			cosmosGRPC, err := parseURL(logger, konfig, flagCosmosGRPC)
+			if err != nil {
+				return err
+			}
+
+			daemonClient, err := client.NewCosmosClient(clientCtx, logger, cosmosGRPC)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			gRPCConn := daemonClient.QueryClient()
+			waitForService(ctx, gRPCConn)
+
+			gravityQuerier := gravitytypes.NewQueryClient(gRPCConn)
+			stakingQuerier := stakingtypes.NewQueryClient(gRPCConn)
+
+			report := []missingConfirmsInfo{}
+
+			valsets, err := gravityQuerier.LastValsetRequests(ctx, &gravitytypes.QueryLastValsetRequestsRequest{})
+			if err != nil {
+				return fmt.Errorf("failed to query for the latest valsets: %w", err)
+			}
+
+			for _, valset := range valsets.Valsets {
+				confirms, err := gravityQuerier.ValsetConfirmsByNonce(
+					ctx,
+					&gravitytypes.QueryValsetConfirmsByNonceRequest{Nonce: valset.Nonce},
+				)
+				if err != nil {
+					return fmt.Errorf("failed to query for the confirms of valset %d: %w", valset.Nonce, err)
+				}
+
+				signers := make([]string, len(confirms.Confirms))
+				for i, c := range confirms.Confirms {
+					signers[i] = c.EthAddress
+				}
+
+				info := missingConfirms(ctx, gravityQuerier, stakingQuerier, valset, signers)
+				if len(info.Missing) > 0 {
+					info.Kind = "valset"
+					info.Nonce = valset.Nonce
+					report = append(report, info)
+				}
+			}
+
+			currentValset, err := gravityQuerier.CurrentValset(ctx, &gravitytypes.QueryCurrentValsetRequest{})
+			if err != nil {
+				return fmt.Errorf("failed to query for the current valset: %w", err)
+			}
+
+			batches, err := gravityQuerier.OutgoingTxBatches(ctx, &gravitytypes.QueryOutgoingTxBatchesRequest{})
+			if err != nil {
+				return fmt.Errorf("failed to query for the pending batches: %w", err)
+			}
+
+			for _, batch := range batches.Batches {
+				confirms, err := gravityQuerier.BatchConfirms(
+					ctx,
+					&gravitytypes.QueryBatchConfirmsRequest{Nonce: batch.BatchNonce, ContractAddress: batch.TokenContract},
+				)
+				if err != nil {
+					return fmt.Errorf("failed to query for the confirms of batch %d: %w", batch.BatchNonce, err)
+				}
+
+				signers := make([]string, len(confirms.Confirms))
+				for i, c := range confirms.Confirms {
+					signers[i] = c.EthSigner
+				}
+
+				info := missingConfirms(ctx, gravityQuerier, stakingQuerier, currentValset.Valset, signers)
+				if len(info.Missing) > 0 {
+					info.Kind = "batch"
+					info.Nonce = batch.BatchNonce
+					info.TokenContract = batch.TokenContract
+					report = append(report, info)
+				}
+			}
+
+			if isJSONOutput(konfig) {
+				return printJSON(report)
+			}
+
+			bz, err := yaml.Marshal(report)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Println(string(bz))
+			return err
+		},
+	}
+
+	cmd.Flags().String(flagFormat, "text", "Print the report in the given format (text|json)")
+	cmd.Flags().AddFlagSet(cosmosFlagSet())
+
+	return cmd
+}
+
+type valsetSnapshot struct {
+	Height       int64                  `json:"height" yaml:"height"`
+	GravityID    string                 `json:"gravity_id" yaml:"gravity_id"`
+	Nonce        uint64                 `json:"nonce" yaml:"nonce"`
+	Members      []valsetSnapshotMember `json:"members" yaml:"members"`
+	TotalPower   uint64                 `json:"total_power" yaml:"total_power"`
+	RewardAmount string                 `json:"reward_amount" yaml:"reward_amount"`
+	RewardToken  string                 `json:"reward_token" yaml:"reward_token"`
+	Checkpoint   string                 `json:"checkpoint" yaml:"checkpoint"`
+}
+
+type valsetSnapshotMember struct {
+	EthereumAddress string `json:"ethereum_address" yaml:"ethereum_address"`
+	Power           uint64 `json:"power" yaml:"power"`
+}
+
+func getQueryValsetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "valset",
+		Args:  cobra.NoArgs,
+		Short: "Print a validator set and its checkpoint hash",
+		Long: `Print a validator set and its checkpoint hash.
+
+The current valset is shown by default, or the valset request with the given
+--nonce. With --height, both the valset and the Gravity ID used to compute the
+checkpoint are queried at that Cosmos height (the node must not have pruned it).
+The checkpoint is the hash the Gravity Bridge contract stores for the valset,
+which is needed to recover the contract manually. --export also writes the
+snapshot as JSON to the given file.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			konfig, err := parseServerConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			logger, err := getLogger(cmd)
+			if err != nil {
+				return err
+			}
+
+			clientCtx, err := client.NewClientContext(konfig.String(flagCosmosChainID), "", nil)
+			if err != nil {
+				return err
+			}
+
+			cosmosGRPC, err := parseURL(logger, konfig, flagCosmosGRPC)
+			if err != nil {
+				return err
+			}
+
+			daemonClient, err := client.NewCosmosClient(clientCtx, logger, cosmosGRPC)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			gRPCConn := daemonClient.QueryClient()
+			waitForService(ctx, gRPCConn)
+
+			height := konfig.Int64(flagHeight)
+			if height < 0 {
+				return fmt.Errorf("invalid height: %d", height)
+			}
+
+			if height > 0 {
+				ctx = metadata.AppendToOutgoingContext(ctx, grpctypes.GRPCBlockHeightHeader, strconv.FormatInt(height, 10))
+			}
+
+			gravityQuerier := gravitytypes.NewQueryClient(gRPCConn)
+
+			// the height the query was served at is returned in the header
+			var header metadata.MD
+
+			var valset *gravitytypes.Valset
+			if nonce := konfig.Int64(flagNonce); nonce > 0 {
+				resp, err := gravityQuerier.ValsetRequest(
+					ctx,
+					&gravitytypes.QueryValsetRequestRequest{Nonce: uint64(nonce)},
+					grpc.Header(&header),
+				)
+				if err != nil {
+					return fmt.Errorf("failed to query for valset %d: %w", nonce, err)
+				}
+
+				valset = resp.Valset
+			} else {
+				resp, err := gravityQuerier.CurrentValset(
+					ctx,
+					&gravitytypes.QueryCurrentValsetRequest{},
+					grpc.Header(&header),
+				)
+				if err != nil {
+					return fmt.Errorf("failed to query for the current valset: %w", err)
+				}
+
+				valset = &resp.Valset
+			}
+
+			if valset == nil {
+				return errors.New("valset not found")
+			}
+
+			params, err := gravityQuerier.Params(ctx, &gravitytypes.QueryParamsRequest{})
+			if err != nil {
+				return fmt.Errorf("failed to query for the Gravity params: %w", err)
+			}
+
+			if values := header.Get(grpctypes.GRPCBlockHeightHeader); len(values) > 0 {
+				if height, err = strconv.ParseInt(values[0], 10, 64); err != nil {
+					return fmt.Errorf("invalid height in the response header: %w", err)
+				}
+			}
+
+			snapshot := newValsetSnapshot(height, params.Params.GravityId, *valset)
+
+			if file := konfig.String(flagExport); file != "" {
+				bz, err := json.MarshalIndent(snapshot, "", "  ")
+				if err != nil {
+					return err
+				}
+
+				if err := os.WriteFile(file, bz, 0o600); err != nil {
+					return fmt.Errorf("failed to export the valset: %w", err)
+				}
+			}
+
+			if isJSONOutput(konfig) {
+				return printJSON(snapshot)
+			}
+
+			bz, err := yaml.Marshal(&snapshot)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Println(string(bz))
+			return err
+		},
+	}
+
+	cmd.Flags().String(flagFormat, "text", "Print the valset in the given format (text|json)")
+	cmd.Flags().Int64(flagHeight, 0, "Cosmos height to query the valset at (0 for the latest)")
+	cmd.Flags().Int64(flagNonce, 0, "Nonce of the valset request to query (0 for the current valset)")
+	cmd.Flags().String(flagExport, "", "Write the valset snapshot as JSON to the given file")
+	cmd.Flags().AddFlagSet(cosmosFlagSet())
+
+	return cmd
+}
+
+func newValsetSnapshot(height int64, gravityID string, valset gravitytypes.Valset) valsetSnapshot {
+	snapshot := valsetSnapshot{
+		Height:       height,
+		GravityID:    gravityID,
+		Nonce:        valset.Nonce,
+		Members:      make([]valsetSnapshotMember, len(valset.Members)),
+		RewardAmount: valset.RewardAmount.String(),
+		RewardToken:  valset.RewardToken,
+		Checkpoint:   gravity.EncodeValsetConfirm(gravityID, valset).Hex(),
+	}
+
+	for i, member := range valset.Members {
+		snapshot.Members[i] = valsetSnapshotMember{
+			EthereumAddress: ethcmn.HexToAddress(member.EthereumAddress).Hex(),
+			Power:           member.Power,
+		}
+		snapshot.TotalPower += member.Power
+	}
+
+	return snapshot
+}
+
+func missingConfirms(
+	ctx context.Context,
+	gravityQuerier gravitytypes.QueryClient,
+	stakingQuerier stakingtypes.QueryClient,
+	valset gravitytypes.Valset,
+	signers []string,
+) missingConfirmsInfo {
+	var info missingConfirmsInfo
+
+	for _, m := range valset.Members {
+		info.TotalPower += m.Power
+	}
+
+	members, _ := gravity.MissingSigners(valset, signers)
+	info.Missing = relayer.ResolveMissingConfirms(ctx, gravityQuerier, stakingQuerier, members)
+
+	for _, m := range info.Missing {
+		info.MissingPower += m.Power
+	}
+
+	return info
+}
+
+type relayStatsWindow struct {
+	Window string `json:"window"`
+	relayer.RelayStats
+}
+
+func getQueryRelayStatsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "relay-stats [denom]",
+		Args:  cobra.RangeArgs(0, 1),
+		Short: "Print statistics of the batches relayed by this orchestrator",
+		Long: `Print statistics of the batches relayed by this orchestrator.
+
+The statistics are computed from the relay history kept in the peggo home
+directory, for each of the --windows. The batches can be filtered by Cosmos
+denom or token contract. For each window, the following values are shown:
+- the number of batches relayed
+- their average number of transfers, fees, gas used and profit
+- the share of the mined txs that succeeded
+
+Fees and gas costs are valued in USD at the oracle prices when relaying. A
+failed tx still pays its gas, but earns no fees.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			konfig, err := parseServerConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			var denom string
+			if len(args) > 0 {
+				denom = args[0]
+			}
+
+			windows := konfig.Strings(flagWindows)
+			if len(windows) == 0 {
+				return errors.New("at least one window is required")
+			}
+
+			durations := make([]time.Duration, len(windows))
+			for i, w := range windows {
+				d, err := time.ParseDuration(strings.TrimSpace(w))
+				if err != nil || d <= 0 {
+					return fmt.Errorf("invalid window: %s", w)
+				}
+				durations[i] = d
+			}
+
+			s, err := openStore(konfig)
+			if err != nil {
+				return err
+			}
+
+			records, err := relayer.GetRelayHistory(s)
+			if err != nil {
+				return fmt.Errorf("failed to read relay history: %w", err)
+			}
+
+			now := time.Now()
+			stats := make([]relayStatsWindow, len(durations))
+			for i, d := range durations {
+				stats[i] = relayStatsWindow{
+					Window:     d.String(),
+					RelayStats: relayer.ComputeRelayStats(records, denom, now.Add(-d)),
+				}
+			}
+
+			if isJSONOutput(konfig) {
+				return printJSON(stats)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+			fmt.Fprintln(w, "WINDOW\tBATCHES\tAVG TXS\tAVG FEES (USD)\tAVG GAS\tAVG PROFIT (USD)\tSUCCESS RATE\tPENDING")
+			for _, st := range stats {
+				fmt.Fprintf(w, "%s\t%d\t%.1f\t%s\t%d\t%s\t%.1f%%\t%d\n",
+					st.Window,
+					st.Batches,
+					st.AvgTxs,
+					st.AvgFeesUSD.StringFixed(2),
+					st.AvgGasUsed,
+					st.AvgProfitUSD.StringFixed(2),
+					st.SuccessRate*100,
+					st.Pending,
+				)
+			}
+
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().String(flagFormat, "text", "Print the statistics in the given format (text|json)")
+	cmd.Flags().StringSlice(flagWindows, []string{"24h", "168h", "720h"}, "Set the time windows to summarize")
 
 	return cmd
 }
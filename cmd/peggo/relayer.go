@@ -0,0 +1,219 @@
+package peggo
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/knadh/koanf"
+	"github.com/spf13/cobra"
+
+	"github.com/umee-network/peggo/orchestrator/admin"
+	"github.com/umee-network/peggo/orchestrator/relayer"
+	"github.com/umee-network/peggo/orchestrator/store"
+)
+
+func getRelayerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "relayer",
+		Short: "Commands to control the relayer of a running or future orchestrator",
+		Long: `Commands to control the relayer of a running or future orchestrator.
+
+The relayer state is persisted in the peggo home directory, so it survives
+restarts and is picked up by a running orchestrator on its next relayer loop.
+Pausing the relayer does not stop claim and confirm signing.`,
+	}
+
+	cmd.AddCommand(
+		getRelayerPauseCmd(),
+		getRelayerResumeCmd(),
+		getRelayerStatusCmd(),
+		getRelayerApproveBatchCmd(),
+	)
+
+	return cmd
+}
+
+func getRelayerPauseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pause",
+		Args:  cobra.NoArgs,
+		Short: "Pause relaying of batches and valsets to Ethereum",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			konfig, err := parseServerConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			s, err := openStore(konfig)
+			if err != nil {
+				return err
+			}
+
+			if err := relayer.SetPauseState(s, true, konfig.String(flagReason)); err != nil {
+				return fmt.Errorf("failed to pause relayer: %w", err)
+			}
+
+			fmt.Fprintln(os.Stderr, "Relaying paused")
+			return printPauseState(konfig, s)
+		},
+	}
+
+	cmd.Flags().String(flagReason, "", "Set an (optional) reason for pausing, shown in the orchestrator logs")
+
+	return cmd
+}
+
+func getRelayerResumeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "resume",
+		Args:  cobra.NoArgs,
+		Short: "Resume relaying of batches and valsets to Ethereum",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			konfig, err := parseServerConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			s, err := openStore(konfig)
+			if err != nil {
+				return err
+			}
+
+			if err := relayer.SetPauseState(s, false, ""); err != nil {
+				return fmt.Errorf("failed to resume relayer: %w", err)
+			}
+
+			fmt.Fprintln(os.Stderr, "Relaying resumed")
+			return printPauseState(konfig, s)
+		},
+	}
+}
+
+func getRelayerStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Args:  cobra.NoArgs,
+		Short: "Print whether relaying is paused",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			konfig, err := parseServerConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			s, err := openStore(konfig)
+			if err != nil {
+				return err
+			}
+
+			state, err := relayer.GetPauseState(s)
+			if err != nil {
+				return fmt.Errorf("failed to read relayer state: %w", err)
+			}
+
+			if isJSONOutput(konfig) {
+				return printJSON(state)
+			}
+
+			if !state.Paused {
+				fmt.Println("Relaying: active")
+				return nil
+			}
+
+			fmt.Printf("Relaying: paused since %s\n", state.UpdatedAt.Format(time.RFC3339))
+			if len(state.Reason) > 0 {
+				fmt.Printf("Reason: %s\n", state.Reason)
+			}
+
+			return nil
+		},
+	}
+}
+
+func getRelayerApproveBatchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "approve-batch [token-contract] [batch-nonce]",
+		Args:  cobra.ExactArgs(2),
+		Short: "Approve relaying a batch held by --relayer-max-value-concentration",
+		Long: `Approve relaying a batch held by --relayer-max-value-concentration.
+
+The approval is sent to the admin API of the running orchestrator, so it must
+run with --admin-listen-addr and --admin-token. Approvals are dropped once the
+batch is relayed or times out.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			konfig, err := parseServerConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			if !ethcmn.IsHexAddress(args[0]) {
+				return fmt.Errorf("invalid token contract address: %s", args[0])
+			}
+
+			nonce, err := strconv.ParseUint(args[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid batch nonce: %w", err)
+			}
+
+			baseURL, err := adminURL(konfig.String(flagAdminListenAddr))
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), statusTimeout)
+			defer cancel()
+
+			client := admin.NewClient(baseURL, konfig.String(flagAdminToken))
+			approvals, err := client.ApproveBatch(ctx, ethcmn.HexToAddress(args[0]), nonce)
+			if err != nil {
+				return fmt.Errorf("failed to approve batch: %w", err)
+			}
+
+			fmt.Fprintf(os.Stderr, "Batch %d of %s approved\n", nonce, ethcmn.HexToAddress(args[0]).Hex())
+
+			if isJSONOutput(konfig) {
+				return printJSON(approvals)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flagAdminListenAddr, "127.0.0.1:9303", "Address the orchestrator serves the admin API on")
+	cmd.Flags().String(flagAdminToken, "", "Specify the token authenticating the admin API requests")
+
+	return cmd
+}
+
+// adminURL returns the base URL of the admin API served on addr. A listen
+// address without a host is reached on the loopback interface.
+func adminURL(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid --%s: %w", flagAdminListenAddr, err)
+	}
+
+	if host == "" {
+		host = "127.0.0.1"
+	}
+
+	return "http://" + net.JoinHostPort(host, port), nil
+}
+
+// printPauseState prints the relaying pause state with --output=json.
+func printPauseState(konfig *koanf.Koanf, s *store.Store) error {
+	if !isJSONOutput(konfig) {
+		return nil
+	}
+
+	state, err := relayer.GetPauseState(s)
+	if err != nil {
+		return fmt.Errorf("failed to read relayer state: %w", err)
+	}
+
+	return printJSON(state)
+}
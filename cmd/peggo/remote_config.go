@@ -0,0 +1,83 @@
+package peggo
+
+import (
+	"context"
+	"fmt"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/knadh/koanf"
+	"github.com/rs/zerolog"
+
+	"github.com/umee-network/peggo/orchestrator/oracle"
+	"github.com/umee-network/peggo/orchestrator/relayer"
+	"github.com/umee-network/peggo/orchestrator/remoteconfig"
+	"github.com/umee-network/peggo/orchestrator/store"
+)
+
+// newRemoteConfigFetcher returns a fetcher of the --remote-config-url, which
+// adds its symbol aliases to the --oracle-symbol-aliases of the oracle, its
+// denylist to the local one and sets the symbols of its tokens. A document is
+// either fully applied or not at all.
+func newRemoteConfigFetcher(
+	ctx context.Context,
+	logger zerolog.Logger,
+	konfig *koanf.Koanf,
+	st *store.Store,
+	o *oracle.Oracle,
+	denylist *relayer.Denylist,
+	tokenSymbols *relayer.TokenSymbols,
+) (*remoteconfig.Fetcher, error) {
+	publicKey, err := remoteconfig.ParsePublicKey(konfig.String(flagRemoteConfigPubKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s: %w", flagRemoteConfigPubKey, err)
+	}
+
+	localAliases := konfig.Strings(flagOracleSymbolAliases)
+
+	apply := func(doc remoteconfig.Document) error {
+		aliases, err := oracle.ParseSymbolAliases(append(append([]string{}, localAliases...), doc.SymbolAliases...))
+		if err != nil {
+			return err
+		}
+
+		addrs := make([]ethcmn.Address, 0, len(doc.Denylist))
+		for _, addr := range doc.Denylist {
+			if !ethcmn.IsHexAddress(addr) {
+				return fmt.Errorf("invalid denylist address %q", addr)
+			}
+
+			addrs = append(addrs, ethcmn.HexToAddress(addr))
+		}
+
+		symbols := make(map[ethcmn.Address]string, len(doc.TokenSymbols))
+		for addr, symbol := range doc.TokenSymbols {
+			if !ethcmn.IsHexAddress(addr) {
+				return fmt.Errorf("invalid token address %q", addr)
+			}
+
+			if symbol == "" {
+				return fmt.Errorf("no symbol for token %s", addr)
+			}
+
+			symbols[ethcmn.HexToAddress(addr)] = symbol
+		}
+
+		o.SetSymbolAliases(aliases)
+		denylist.SetRemote(addrs)
+		tokenSymbols.SetSymbols(symbols)
+
+		return nil
+	}
+
+	return remoteconfig.NewFetcher(
+		ctx,
+		logger,
+		remoteconfig.Config{
+			URL:          konfig.String(flagRemoteConfigURL),
+			SignatureURL: konfig.String(flagRemoteConfigSigURL),
+			PublicKey:    publicKey,
+		},
+		st,
+		apply,
+	)
+}
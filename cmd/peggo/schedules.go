@@ -0,0 +1,24 @@
+package peggo
+
+import (
+	"fmt"
+
+	"github.com/knadh/koanf"
+
+	"github.com/umee-network/peggo/orchestrator/schedule"
+)
+
+// parseSchedule returns the schedule set with a flag, or nil if it's empty.
+func parseSchedule(konfig *koanf.Koanf, flag string) (schedule.Schedule, error) {
+	spec := konfig.String(flag)
+	if spec == "" {
+		return nil, nil
+	}
+
+	s, err := schedule.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s: %w", flag, err)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,71 @@
+package peggo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	payloadv1 "github.com/umee-network/peggo/orchestrator/payload/v1"
+)
+
+func getSchemaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema [payload]",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "Print the JSON schema of a webhook or API payload",
+		Long: `Print the JSON schema of a webhook or API payload.
+
+Every payload peggo sends to a webhook or serves over its HTTP APIs is versioned
+and named in the X-Peggo-Schema header, e.g. peggo.v1.policy_alert. Within a
+version, fields may be added but are never removed, renamed, retyped or made
+optional. Without a payload, the command lists the schema names. With --out,
+the schemas of all payloads are written to the given directory instead.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			konfig, err := parseServerConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			if out := konfig.String(flagSchemaOut); out != "" {
+				if err := os.MkdirAll(out, 0o755); err != nil {
+					return err
+				}
+
+				for _, name := range payloadv1.Names() {
+					bz, err := payloadv1.Schema(name)
+					if err != nil {
+						return err
+					}
+
+					if err := os.WriteFile(filepath.Join(out, name+".json"), bz, 0o644); err != nil {
+						return fmt.Errorf("failed to write the schema of %s: %w", name, err)
+					}
+				}
+
+				return nil
+			}
+
+			if len(args) == 0 {
+				for _, name := range payloadv1.Names() {
+					fmt.Println(name)
+				}
+
+				return nil
+			}
+
+			bz, err := payloadv1.Schema(args[0])
+			if err != nil {
+				return err
+			}
+
+			_, err = os.Stdout.Write(bz)
+			return err
+		},
+	}
+
+	cmd.Flags().String(flagSchemaOut, "", "Write the schemas of all payloads to the given directory")
+
+	return cmd
+}
@@ -0,0 +1,189 @@
+package peggo
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/knadh/koanf"
+	"github.com/spf13/cobra"
+
+	"github.com/umee-network/peggo/cmd/peggo/client"
+	"github.com/umee-network/peggo/orchestrator/ethereum/keystore"
+	"github.com/umee-network/peggo/orchestrator/relayer"
+	"github.com/umee-network/peggo/orchestrator/signer"
+)
+
+const signerTimeout = 30 * time.Second
+
+func getSignerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "signer",
+		Args:  cobra.NoArgs,
+		Short: "Starts a signing daemon holding the orchestrator's Ethereum key",
+		Long: `Starts a signing daemon holding the orchestrator's Ethereum key.
+
+The signer only signs valset and batch confirms, which it hashes itself, for
+requests carrying the shared token (preferably set with $PEGGO_SIGNER_TOKEN). It
+listens on a Unix socket only accessible by its user. Each valset or batch is
+fetched by nonce from the Gravity module through --cosmos-grpc, which should be
+the signer's own node, and only signed as stored there, so an orchestrator host
+that is compromised can't get anything else signed. Run the orchestrator with
+--signer-socket to use it; the socket can be forwarded to another host (e.g.
+with SSH), so the network-facing orchestrator doesn't need the keys.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			konfig, err := parseServerConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			logger, err := getLogger(cmd)
+			if err != nil {
+				return err
+			}
+
+			if konfig.Bool(flagEthUseLedger) {
+				return fmt.Errorf("cannot use Ledger for the signer")
+			}
+
+			if konfig.String(flagEthTSSGRPC) != "" {
+				return fmt.Errorf("cannot use a threshold signing service for the signer")
+			}
+
+			// The chain ID is only used to sign transactions, which the signer never does.
+			ethAddress, _, personalSignFn, typedDataSignFn, err := initEthereumAccountsManager(logger, 0, konfig)
+			if err != nil {
+				return fmt.Errorf("failed to initialize Ethereum account: %w", err)
+			}
+
+			zeroStdinSecrets()
+
+			confirmSigner, err := newConfirmSigner(konfig, personalSignFn, typedDataSignFn)
+			if err != nil {
+				return err
+			}
+
+			clientCtx, err := client.NewClientContext(konfig.String(flagCosmosChainID), "", nil)
+			if err != nil {
+				return err
+			}
+
+			cosmosGRPC, err := parseURL(logger, konfig, flagCosmosGRPC)
+			if err != nil {
+				return err
+			}
+
+			daemonClient, err := client.NewCosmosClient(clientCtx, logger, cosmosGRPC)
+			if err != nil {
+				return err
+			}
+
+			waitCtx, waitCancel := context.WithTimeout(context.Background(), time.Minute)
+			defer waitCancel()
+
+			gRPCConn := daemonClient.QueryClient()
+			waitForService(waitCtx, gRPCConn)
+
+			gravityParams, err := getGravityParams(gRPCConn)
+			if err != nil {
+				return err
+			}
+
+			// the Gravity ID is the module's, unless the one set differs
+			gravityID := konfig.String(flagSignerGravityID)
+			switch {
+			case gravityID == "":
+				gravityID = gravityParams.GravityId
+
+			case gravityID != gravityParams.GravityId:
+				return fmt.Errorf(
+					"--%s %s doesn't match the Gravity ID of the module: %s",
+					flagSignerGravityID, gravityID, gravityParams.GravityId,
+				)
+			}
+
+			server, err := signer.NewServer(
+				logger,
+				konfig.String(flagSignerSocket),
+				konfig.String(flagSignerToken),
+				confirmSigner,
+				signer.NewGravitySource(gravitytypes.NewQueryClient(gRPCConn)),
+				ethAddress,
+				gravityID,
+			)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			// listen for and trap any OS signal to gracefully shutdown and exit
+			trapSignal(cancel)
+
+			return server.Start(ctx)
+		},
+	}
+
+	cmd.Flags().String(flagSignerSocket, defaultSignerSocket(), "Specify the Unix socket to serve signing requests on")
+	cmd.Flags().String(flagSignerToken, "", "Specify the token shared with the orchestrator (at least 16 characters)")
+	cmd.Flags().String(flagSignerGravityID, "", "Set an (optional) Gravity ID, checked against the module's; confirms for any other are refused") //nolint: lll
+	cmd.Flags().String(flagCosmosChainID, "", "The chain ID of the cosmos network")
+	cmd.Flags().String(flagCosmosGRPC, "tcp://localhost:9090", "The gRPC endpoint of the cosmos node the valsets and batches are fetched from") //nolint: lll
+	cmd.Flags().AddFlagSet(ethereumKeyOptsFlagSet())
+	cmd.Flags().AddFlagSet(confirmSchemeFlagSet())
+
+	return cmd
+}
+
+// initRemoteSigner connects to the signer at --signer-socket. Only confirms can
+// be signed with it; the returned sign functions always fail.
+func initRemoteSigner(ctx context.Context, konfig *koanf.Koanf) (
+	ethcmn.Address,
+	bind.SignerFn,
+	keystore.PersonalSignFn,
+	*signer.Client,
+	error,
+) {
+	client := signer.NewClient(konfig.String(flagSignerSocket), konfig.String(flagSignerToken), signerTimeout)
+
+	ethAddress, err := client.Address(ctx)
+	if err != nil {
+		return emptyEthAddress, nil, nil, nil, fmt.Errorf("failed to get the Ethereum address from the signer: %w", err)
+	}
+
+	signerFn := func(ethcmn.Address, *ethtypes.Transaction) (*ethtypes.Transaction, error) {
+		return nil, signer.ErrRemoteKey
+	}
+
+	personalSignFn := func(ethcmn.Address, []byte) ([]byte, error) {
+		return nil, signer.ErrRemoteKey
+	}
+
+	return ethAddress, signerFn, personalSignFn, client, nil
+}
+
+// validateRemoteSigner checks that nothing but confirms needs to be signed by
+// the orchestrator when it uses a remote signer.
+func validateRemoteSigner(konfig *koanf.Koanf, valsetRelayMode relayer.ValsetRelayMode) error {
+	if konfig.String(flagSignerToken) == "" {
+		return fmt.Errorf("--%s is required with --%s", flagSignerToken, flagSignerSocket)
+	}
+
+	if konfig.Bool(flagRelayBatches) || valsetRelayMode != relayer.ValsetRelayModeNone {
+		return fmt.Errorf("cannot relay with a remote signer; disable relaying")
+	}
+
+	if konfig.String(flagHeartbeatEndpoint) != "" {
+		return fmt.Errorf("cannot send signed heartbeats with a remote signer")
+	}
+
+	return nil
+}
+
+func defaultSignerSocket() string {
+	return filepath.Join(defaultHome(), "signer.sock")
+}
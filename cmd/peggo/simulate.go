@@ -0,0 +1,342 @@
+package peggo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+
+	"github.com/umee-network/peggo/orchestrator/coingecko"
+	"github.com/umee-network/peggo/orchestrator/ethereum/gravity"
+	"github.com/umee-network/peggo/orchestrator/ethereum/util"
+	"github.com/umee-network/peggo/orchestrator/relayer"
+	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
+)
+
+// simulateBlocksPerQuery is the number of Ethereum blocks queried for batch
+// events at once.
+const simulateBlocksPerQuery = 2000
+
+func getSimulateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Simulate orchestrator behavior offline",
+	}
+
+	cmd.AddCommand(
+		getSimulateRelayerCmd(),
+	)
+
+	return cmd
+}
+
+func getSimulateRelayerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "relayer [gravity-addr]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Replay historical batches through the relayer profitability config",
+		Long: `Replay historical batches through the relayer profitability config.
+
+Every batch executed on the Gravity contract between the given Ethereum heights
+is evaluated with the gas it used, the gas price it paid and the CoinGecko USD
+prices at the time it was relayed. The report shows which batches the current
+config would have relayed and at what P&L, so thresholds can be tuned offline.
+
+Only batches someone relayed can be replayed. The price deviation circuit
+breaker and missing price fallbacks are not simulated.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			konfig, err := parseServerConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			logger, err := getLogger(cmd)
+			if err != nil {
+				return err
+			}
+
+			if !ethcmn.IsHexAddress(args[0]) {
+				return fmt.Errorf("invalid Gravity contract address: %s", args[0])
+			}
+			gravityAddr := ethcmn.HexToAddress(args[0])
+
+			fromHeight := uint64(konfig.Int64(flagFromHeight))
+			toHeight := uint64(konfig.Int64(flagToHeight))
+
+			ctx := context.Background()
+
+			ethRPC, err := ethclient.Dial(konfig.String(flagEthRPC))
+			if err != nil {
+				return fmt.Errorf("failed to dial Ethereum RPC node: %w", err)
+			}
+
+			if toHeight == 0 {
+				header, err := ethRPC.HeaderByNumber(ctx, nil)
+				if err != nil {
+					return fmt.Errorf("failed to get the latest Ethereum header: %w", err)
+				}
+				toHeight = header.Number.Uint64()
+			}
+
+			if fromHeight > toHeight {
+				return fmt.Errorf("invalid height range: %d > %d", fromHeight, toHeight)
+			}
+
+			limiters, providerKeys, err := providerLimiters(konfig)
+			if err != nil {
+				return err
+			}
+
+			batches, err := getHistoricalBatches(
+				ctx,
+				logger,
+				ethRPC,
+				gravityAddr,
+				fromHeight,
+				toHeight,
+				newCoinGecko(logger, konfig, limiters, providerKeys),
+				konfig.String(flagGasAssetCoinID),
+			)
+			if err != nil {
+				return err
+			}
+
+			report := relayer.SimulateProfitability(batches, konfig.Float64(flagProfitMultiplier))
+
+			if isJSONOutput(konfig) {
+				return printJSON(report)
+			}
+
+			return printSimulationReport(report)
+		},
+	}
+
+	cmd.Flags().Int64(flagFromHeight, 0, "Ethereum height to start replaying batches from")
+	cmd.Flags().Int64(flagToHeight, 0, "Ethereum height to stop replaying batches at (0 means the latest height)")
+	cmd.Flags().Float64(flagProfitMultiplier, 1.0, "Multiplier to apply to relayer profit")
+	cmd.Flags().String(flagCoinGeckoAPI, "https://api.coingecko.com/api/v3", "Specify the coingecko API endpoint")
+	cmd.Flags().String(flagGasAssetCoinID, coingecko.EthereumCoinID, "CoinGecko ID of the gas asset (e.g. binancecoin)")
+	cmd.Flags().StringSlice(flagOracleProviderLimits, nil, "Set (optional) CoinGecko rate limits (e.g. coingecko=0.5:5)")
+	cmd.Flags().StringSlice(flagOracleProviderKeys, nil, "Set (optional) provider API keys (e.g. coingecko=CG-xxx)")
+	cmd.Flags().String(flagFormat, "text", "Print the report in the given format (text|json)")
+	cmd.Flags().String(flagEthRPC, "http://localhost:8545", "Specify the RPC address of an Ethereum node")
+
+	return cmd
+}
+
+// getHistoricalBatches returns the batches executed on the Gravity contract
+// between two Ethereum heights, with the gas they used and the USD prices at
+// the time they were relayed.
+func getHistoricalBatches(
+	ctx context.Context,
+	logger zerolog.Logger,
+	ethRPC *ethclient.Client,
+	gravityAddr ethcmn.Address,
+	fromHeight, toHeight uint64,
+	coinGecko *coingecko.CoinGecko,
+	gasAssetCoinID string,
+) ([]relayer.HistoricalBatch, error) {
+	gravityContract, err := getGravityContract(ethRPC, gravityAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	gravityABI, err := abi.JSON(strings.NewReader(wrappers.GravityABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Gravity ABI: %w", err)
+	}
+	submitBatch := gravityABI.Methods["submitBatch"]
+
+	var (
+		batches  []relayer.HistoricalBatch
+		headers  = map[uint64]*ethtypes.Header{}
+		decimals = map[ethcmn.Address]uint8{}
+	)
+
+	for start := fromHeight; start <= toHeight; start += simulateBlocksPerQuery {
+		end := start + simulateBlocksPerQuery - 1
+		if end > toHeight {
+			end = toHeight
+		}
+
+		iter, err := gravityContract.FilterTransactionBatchExecutedEvent(
+			&bind.FilterOpts{Start: start, End: &end, Context: ctx},
+			nil,
+			nil,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter batch events: %w", err)
+		}
+
+		for iter.Next() {
+			ev := iter.Event
+
+			tx, _, err := ethRPC.TransactionByHash(ctx, ev.Raw.TxHash)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get tx %s: %w", ev.Raw.TxHash, err)
+			}
+
+			data := tx.Data()
+			if len(data) < 4 || !bytes.Equal(data[:4], submitBatch.ID) {
+				logger.Warn().Str("tx_hash", ev.Raw.TxHash.Hex()).Msg("batch not relayed through submitBatch; skipping")
+				continue
+			}
+
+			batch, err := gravity.DecodeSubmitBatch(submitBatch, data[4:])
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode tx %s: %w", ev.Raw.TxHash, err)
+			}
+
+			receipt, err := ethRPC.TransactionReceipt(ctx, ev.Raw.TxHash)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get receipt of tx %s: %w", ev.Raw.TxHash, err)
+			}
+
+			header, ok := headers[ev.Raw.BlockNumber]
+			if !ok {
+				header, err = ethRPC.HeaderByNumber(ctx, new(big.Int).SetUint64(ev.Raw.BlockNumber))
+				if err != nil {
+					return nil, fmt.Errorf("failed to get header %d: %w", ev.Raw.BlockNumber, err)
+				}
+				headers[ev.Raw.BlockNumber] = header
+			}
+
+			gasPrice, err := util.EffectiveGasPrice(tx, header)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get gas price of tx %s: %w", ev.Raw.TxHash, err)
+			}
+
+			tokenDecimals, ok := decimals[ev.Token]
+			if !ok {
+				erc20, err := wrappers.NewERC20(ev.Token, ethRPC)
+				if err != nil {
+					return nil, fmt.Errorf("failed to create ERC20 contract instance: %w", err)
+				}
+
+				tokenDecimals, err = erc20.Decimals(&bind.CallOpts{Context: ctx})
+				if err != nil {
+					return nil, fmt.Errorf("failed to get decimals of %s: %w", ev.Token, err)
+				}
+				decimals[ev.Token] = tokenDecimals
+			}
+
+			batches = append(batches, relayer.HistoricalBatch{
+				Batch:    batch,
+				TxHash:   ev.Raw.TxHash,
+				Time:     time.Unix(int64(header.Time), 0).UTC(),
+				Decimals: tokenDecimals,
+				GasUsed:  receipt.GasUsed,
+				GasPrice: gasPrice,
+			})
+		}
+
+		if err := iter.Error(); err != nil {
+			return nil, fmt.Errorf("failed to iterate batch events: %w", err)
+		}
+	}
+
+	return withHistoricalPrices(logger, batches, coinGecko, gasAssetCoinID)
+}
+
+// withHistoricalPrices sets the gas asset and token USD prices at the time each
+// batch was relayed. Batches whose prices can't be found are left out.
+func withHistoricalPrices(
+	logger zerolog.Logger,
+	batches []relayer.HistoricalBatch,
+	coinGecko *coingecko.CoinGecko,
+	gasAssetCoinID string,
+) ([]relayer.HistoricalBatch, error) {
+	if len(batches) == 0 {
+		return nil, nil
+	}
+
+	// Pad the range so the first and last batches have a price before them.
+	from := batches[0].Time.Add(-time.Hour)
+	to := batches[len(batches)-1].Time.Add(time.Hour)
+
+	gasAssetPrices, err := coinGecko.GetCoinPriceHistory(gasAssetCoinID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenPrices := map[ethcmn.Address]coingecko.PriceHistory{}
+	result := make([]relayer.HistoricalBatch, 0, len(batches))
+
+	for _, b := range batches {
+		token := ethcmn.HexToAddress(b.Batch.TokenContract)
+
+		prices, ok := tokenPrices[token]
+		if !ok {
+			prices, err = coinGecko.GetTokenPriceHistory(token, from, to)
+			if err != nil {
+				logger.Warn().Err(err).Str("token_contract", token.Hex()).Msg("failed to get token price history")
+			}
+			tokenPrices[token] = prices
+		}
+
+		gasAssetPrice, ok := gasAssetPrices.At(b.Time)
+		if !ok {
+			logger.Warn().Uint64("batch_nonce", b.Batch.BatchNonce).Msg("gas asset price missing; skipping batch")
+			continue
+		}
+
+		tokenPrice, ok := prices.At(b.Time)
+		if !ok {
+			logger.Warn().
+				Uint64("batch_nonce", b.Batch.BatchNonce).
+				Str("token_contract", token.Hex()).
+				Msg("token price missing; skipping batch")
+			continue
+		}
+
+		b.GasAssetPrice = gasAssetPrice
+		b.TokenPrice = tokenPrice
+		result = append(result, b)
+	}
+
+	return result, nil
+}
+
+func printSimulationReport(report relayer.SimulationReport) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "TIME\tTOKEN\tNONCE\tFEES (USD)\tCOST (USD)\tRELAY\tTX")
+	for _, b := range report.Batches {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%t\t%s\n",
+			b.Time.Format(time.RFC3339),
+			b.Batch.TokenContract,
+			b.Batch.BatchNonce,
+			b.FeesUSD.StringFixed(2),
+			b.CostUSD.StringFixed(2),
+			b.Relay,
+			b.TxHash.Hex(),
+		)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Printf(
+		"\nProfit multiplier: %v\nRelayed: %d, skipped: %d\nFees: %s USD, cost: %s USD, P&L: %s USD\n",
+		report.ProfitMultiplier,
+		report.Relayed,
+		report.Skipped,
+		report.FeesUSD.StringFixed(2),
+		report.CostUSD.StringFixed(2),
+		report.PnLUSD.StringFixed(2),
+	)
+	return err
+}
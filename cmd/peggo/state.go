@@ -0,0 +1,112 @@
+package peggo
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/umee-network/peggo/orchestrator/store"
+)
+
+func getStateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: "Commands to manage the local peggo state",
+		Long: `Commands to manage the local peggo state.
+
+The local state is persisted in the peggo home directory. Its schema is
+versioned, and every command using it migrates it to the schema of the running
+release first.`,
+	}
+
+	cmd.AddCommand(
+		getStateMigrateCmd(),
+	)
+
+	return cmd
+}
+
+// migrationReport is the result of the migrate command with --output=json.
+type migrationReport struct {
+	FromVersion int                `json:"from_version"`
+	DryRun      bool               `json:"dry_run"`
+	Migrations  []appliedMigration `json:"migrations"`
+}
+
+type appliedMigration struct {
+	Version     int      `json:"version"`
+	Description string   `json:"description"`
+	ChangedKeys []string `json:"changed_keys"`
+}
+
+func getStateMigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Args:  cobra.NoArgs,
+		Short: "Migrate the local state to the schema of this release",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			konfig, err := parseServerConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			s, err := store.New(konfig.String(flagHome))
+			if err != nil {
+				return fmt.Errorf("failed to open local state store: %w", err)
+			}
+
+			version, err := s.SchemaVersion()
+			if err != nil {
+				return err
+			}
+
+			dryRun := konfig.Bool(flagDryRun)
+
+			results, err := store.Migrate(s, store.Migrations, dryRun)
+			if err != nil {
+				return err
+			}
+
+			if isJSONOutput(konfig) {
+				report := migrationReport{FromVersion: version, DryRun: dryRun, Migrations: []appliedMigration{}}
+				for _, r := range results {
+					report.Migrations = append(report.Migrations, appliedMigration{
+						Version:     r.Version,
+						Description: r.Description,
+						ChangedKeys: r.ChangedKeys,
+					})
+				}
+
+				return printJSON(report)
+			}
+
+			if len(results) == 0 {
+				fmt.Fprintf(os.Stderr, "Local state is up to date (schema version %d)\n", version)
+				return nil
+			}
+
+			for _, r := range results {
+				changed := "no keys changed"
+				if len(r.ChangedKeys) > 0 {
+					changed = "changed keys: " + strings.Join(r.ChangedKeys, ", ")
+				}
+
+				fmt.Printf("%d: %s (%s)\n", r.Version, r.Description, changed)
+			}
+
+			if dryRun {
+				fmt.Fprintf(os.Stderr, "Dry run; the local state is still at schema version %d\n", version)
+				return nil
+			}
+
+			fmt.Fprintf(os.Stderr, "Migrated local state to schema version %d\n", results[len(results)-1].Version)
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool(flagDryRun, false, "Preview the migrations and the keys they would change without applying them")
+
+	return cmd
+}
@@ -0,0 +1,120 @@
+package peggo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/umee-network/peggo/orchestrator/priceserver"
+)
+
+const statusTimeout = 10 * time.Second
+
+func getStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Args:  cobra.NoArgs,
+		Short: "Print the health of the oracle providers of a running orchestrator",
+		Long: `Print the health of the oracle providers of a running orchestrator.
+
+The status is read from the endpoint the orchestrator serves its oracle prices
+on, so it must run with --oracle-listen-addr. For each provider, the following
+values are shown:
+- whether it's connected, i.e. ticking and not sidelined by its circuit breaker
+- when it last sent a new candle
+- its number of subscribed and available pairs
+- the number of ticks it failed to get prices and of reconnections`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			konfig, err := parseServerConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			endpoint, err := oracleStatusURL(konfig.String(flagOracleListenAddr))
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), statusTimeout)
+			defer cancel()
+
+			providers, err := getProviderStatus(ctx, endpoint)
+			if err != nil {
+				return err
+			}
+
+			if isJSONOutput(konfig) {
+				return printJSON(providers)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+			fmt.Fprintln(w, "PROVIDER\tCONNECTED\tSIDELINED\tLAST MESSAGE\tSUBSCRIBED\tAVAILABLE\tFETCH ERRORS\tRECONNECTS")
+			for _, p := range providers.Providers {
+				fmt.Fprintf(w, "%s\t%t\t%t\t%s\t%d\t%d\t%d\t%d\n",
+					p.Name,
+					p.Connected,
+					p.Sidelined,
+					p.LastMessage.Format(time.RFC3339),
+					p.SubscribedPairs,
+					p.AvailablePairs,
+					p.FetchErrors,
+					p.Reconnects,
+				)
+			}
+
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().String(flagOracleListenAddr, "127.0.0.1:9302", "Address the orchestrator serves the oracle prices on")
+
+	return cmd
+}
+
+// oracleStatusURL returns the URL of the provider status served on addr. A
+// listen address without a host is reached on the loopback interface.
+func oracleStatusURL(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid --%s: %w", flagOracleListenAddr, err)
+	}
+
+	if host == "" {
+		host = "127.0.0.1"
+	}
+
+	return "http://" + net.JoinHostPort(host, port) + "/providers", nil
+}
+
+func getProviderStatus(ctx context.Context, endpoint string) (priceserver.ProvidersResponse, error) {
+	var res priceserver.ProvidersResponse
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return res, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return res, fmt.Errorf("failed to reach the orchestrator: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return res, fmt.Errorf("failed to get the provider status: %s", resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return res, fmt.Errorf("failed to decode the provider status: %w", err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,147 @@
+package peggo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/knadh/koanf"
+)
+
+const (
+	// stdinValue is the value of --config, or of a secret flag, read from STDIN.
+	stdinValue = "-"
+	// maxStdinConfigSize bounds the size of a config file read from STDIN.
+	maxStdinConfigSize = 1 << 20
+	// maxStdinSecretSize bounds the size of each secret read from STDIN.
+	maxStdinSecretSize = 4 << 10
+)
+
+// stdinSecretFlags are the secret flags that can be read from STDIN, one line
+// each, in this order.
+var stdinSecretFlags = []string{flagCosmosPK, flagCosmosFromPassphrase, flagEthPK, flagEthPassphrase}
+
+// stdinSecrets holds the secrets read from STDIN, by flag name. They never go
+// through the config, so they can be zeroed once used.
+var stdinSecrets = map[string][]byte{}
+
+// readStdinConfig reads a config file from r, failing if it's larger than
+// maxStdinConfigSize.
+func readStdinConfig(r io.Reader) ([]byte, error) {
+	bz, err := io.ReadAll(io.LimitReader(r, maxStdinConfigSize+1))
+	if err != nil {
+		zeroBytes(bz)
+		return nil, fmt.Errorf("failed to read config from STDIN: %w", err)
+	}
+
+	if len(bz) > maxStdinConfigSize {
+		zeroBytes(bz)
+		return nil, fmt.Errorf("config read from STDIN is larger than %d bytes", maxStdinConfigSize)
+	}
+
+	return bz, nil
+}
+
+// readStdinSecrets reads a line from r for each secret flag set to "-", in the
+// order of stdinSecretFlags, and keeps it in stdinSecrets.
+func readStdinSecrets(r io.Reader, konfig *koanf.Koanf) error {
+	for _, flag := range stdinSecretFlags {
+		if konfig.String(flag) != stdinValue {
+			continue
+		}
+
+		secret, err := readSecretLine(r)
+		if err != nil {
+			return fmt.Errorf("failed to read --%s from STDIN: %w", flag, err)
+		}
+
+		stdinSecrets[flag] = secret
+	}
+
+	return nil
+}
+
+// readSecretLine reads a line from r, a byte at a time so nothing is buffered
+// past it, failing if it's longer than maxStdinSecretSize. Surrounding spaces
+// are trimmed.
+func readSecretLine(r io.Reader) ([]byte, error) {
+	buf := make([]byte, 0, maxStdinSecretSize)
+	b := make([]byte, 1)
+
+	for {
+		n, err := r.Read(b)
+		if n == 1 {
+			if b[0] == '\n' {
+				break
+			}
+
+			if len(buf) == maxStdinSecretSize {
+				zeroBytes(buf)
+				return nil, fmt.Errorf("longer than %d bytes", maxStdinSecretSize)
+			}
+
+			buf = append(buf, b[0])
+			continue
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			zeroBytes(buf)
+			return nil, err
+		}
+	}
+
+	secret := bytes.TrimSpace(buf)
+	if len(secret) == 0 {
+		return nil, errors.New("empty secret")
+	}
+
+	return secret, nil
+}
+
+// secretValue returns the value of a secret flag, read from STDIN if it's set
+// to "-". It's a copy, as a command may need a secret more than once (e.g. to
+// approve an ERC20 and then send it), which the caller should zero once used,
+// see zeroBytes.
+func secretValue(konfig *koanf.Koanf, flag string) []byte {
+	if secret, ok := stdinSecrets[flag]; ok {
+		return append([]byte(nil), secret...)
+	}
+
+	return []byte(konfig.String(flag))
+}
+
+// decodeHexSecret decodes a hex secret, with or without 0x prefix, without
+// copying it into a string.
+func decodeHexSecret(secret []byte) ([]byte, error) {
+	secret = bytes.TrimPrefix(bytes.TrimSpace(secret), []byte("0x"))
+
+	decoded := make([]byte, hex.DecodedLen(len(secret)))
+	if _, err := hex.Decode(decoded, secret); err != nil {
+		zeroBytes(decoded)
+		return nil, err
+	}
+
+	return decoded, nil
+}
+
+// zeroBytes overwrites b with zeros.
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// zeroStdinSecrets zeroes the secrets read from STDIN, once the keys they hold
+// are loaded.
+func zeroStdinSecrets() {
+	for flag, secret := range stdinSecrets {
+		zeroBytes(secret)
+		delete(stdinSecrets, flag)
+	}
+}
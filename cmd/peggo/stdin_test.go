@@ -0,0 +1,145 @@
+package peggo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingReader returns its data, then err.
+type failingReader struct {
+	data []byte
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, r.err
+	}
+
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestReadSecretLine(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    io.Reader
+		expected string
+		rest     string
+		err      bool
+	}{
+		{
+			name:     "LF",
+			input:    strings.NewReader("secret\nnext\n"),
+			expected: "secret",
+			rest:     "next\n",
+		},
+		{
+			name:     "CRLF",
+			input:    strings.NewReader("secret\r\nnext\r\n"),
+			expected: "secret",
+			rest:     "next\r\n",
+		},
+		{
+			name:     "surrounding spaces",
+			input:    strings.NewReader("  secret \t\n"),
+			expected: "secret",
+		},
+		{
+			name:     "EOF without newline",
+			input:    strings.NewReader("secret"),
+			expected: "secret",
+		},
+		{
+			name:  "EOF before any byte",
+			input: strings.NewReader(""),
+			err:   true,
+		},
+		{
+			name:  "empty line",
+			input: strings.NewReader(" \r\nsecret\n"),
+			err:   true,
+		},
+		{
+			name:     "max size",
+			input:    strings.NewReader(strings.Repeat("a", maxStdinSecretSize) + "\n"),
+			expected: strings.Repeat("a", maxStdinSecretSize),
+		},
+		{
+			name:  "over max size",
+			input: strings.NewReader(strings.Repeat("a", maxStdinSecretSize+1) + "\n"),
+			err:   true,
+		},
+		{
+			name:  "read error",
+			input: &failingReader{data: []byte("sec"), err: errors.New("broken pipe")},
+			err:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			secret, err := readSecretLine(tc.input)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(secret))
+
+			// nothing is read past the line
+			if r, ok := tc.input.(*strings.Reader); ok {
+				rest, err := io.ReadAll(r)
+				require.NoError(t, err)
+				assert.Equal(t, tc.rest, string(rest))
+			}
+		})
+	}
+}
+
+func TestZeroBytes(t *testing.T) {
+	b := []byte("secret")
+	zeroBytes(b)
+	assert.Equal(t, make([]byte, 6), b)
+
+	zeroBytes(nil)
+}
+
+func TestZeroStdinSecrets(t *testing.T) {
+	cosmosPK := []byte("cosmos")
+	ethPK := []byte("ethereum")
+	stdinSecrets[flagCosmosPK] = cosmosPK
+	stdinSecrets[flagEthPK] = ethPK
+
+	// the values handed out are copies, left to their caller
+	value := secretValue(nil, flagEthPK)
+
+	zeroStdinSecrets()
+
+	assert.Empty(t, stdinSecrets)
+	assert.Equal(t, make([]byte, len(cosmosPK)), cosmosPK)
+	assert.Equal(t, make([]byte, len(ethPK)), ethPK)
+	assert.Equal(t, "ethereum", string(value))
+}
+
+func TestPassReader(t *testing.T) {
+	r := newPassReader([]byte("pass"))
+
+	// every prompt reads the passphrase line again, whatever the read size
+	buf := make([]byte, 3)
+	var read bytes.Buffer
+	for read.Len() < 10 {
+		n, err := r.Read(buf)
+		require.NoError(t, err)
+		read.Write(buf[:n])
+	}
+	assert.Equal(t, "pass\npass\n", read.String())
+}
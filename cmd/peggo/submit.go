@@ -0,0 +1,317 @@
+package peggo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/spf13/cobra"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/gravity"
+)
+
+const (
+	payloadTypeBatch  = "batch"
+	payloadTypeValset = "valset"
+)
+
+// submitPayload is a fully specified batch or valset update, along with the
+// valset currently stored in the Gravity contract and the confirms signed by
+// its members.
+type submitPayload struct {
+	Type           string
+	CurrentValset  gravitytypes.Valset
+	Batch          *gravitytypes.OutgoingTxBatch
+	BatchConfirms  []gravitytypes.MsgConfirmBatch
+	Valset         *gravitytypes.Valset
+	ValsetConfirms []gravitytypes.MsgValsetConfirm
+}
+
+// submitPayloadJSON is the file holding a submitPayload, whose messages are in
+// the proto3 JSON of the Gravity module.
+type submitPayloadJSON struct {
+	Type           string            `json:"type"`
+	CurrentValset  json.RawMessage   `json:"current_valset"`
+	Batch          json.RawMessage   `json:"batch"`
+	BatchConfirms  []json.RawMessage `json:"batch_confirms"`
+	Valset         json.RawMessage   `json:"valset"`
+	ValsetConfirms []json.RawMessage `json:"valset_confirms"`
+}
+
+func submitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "submit [gravity-addr]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Submit a batch or valset update, signatures included, to the Gravity Bridge contract",
+		Long: `Submit a batch or valset update, signatures included, to the Gravity Bridge contract.
+
+This is meant for manual recovery when the relayer can't relay a batch or valset
+update whose confirms exist on-chain. The payload file holds the "type" (batch or
+valset), the "current_valset" stored in the contract and either the "batch" and
+its "batch_confirms" or the new "valset" and its "valset_confirms", in the JSON
+format of the Gravity module. The current valset is checked against the contract
+checkpoint, the signatures against the current valset and the batch or valset
+nonce against the last one on Ethereum before submitting.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			konfig, err := parseServerConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			logger, err := getLogger(cmd)
+			if err != nil {
+				return err
+			}
+
+			if !ethcmn.IsHexAddress(args[0]) {
+				return fmt.Errorf("invalid gravity address: %s", args[0])
+			}
+
+			payload, err := readSubmitPayload(konfig.String(flagFromFile))
+			if err != nil {
+				return err
+			}
+
+			ethRPC, err := ethclient.Dial(konfig.String(flagEthRPC))
+			if err != nil {
+				return fmt.Errorf("failed to dial Ethereum RPC node: %w", err)
+			}
+
+			gravityAddr := ethcmn.HexToAddress(args[0])
+			gravityContract, err := getGravityContract(ethRPC, gravityAddr)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			callOpts := &bind.CallOpts{Context: ctx}
+
+			gravityIDBytes, err := gravityContract.StateGravityId(callOpts)
+			if err != nil {
+				return fmt.Errorf("failed to get the Gravity ID: %w", err)
+			}
+
+			checkpoint, err := gravityContract.StateLastValsetCheckpoint(callOpts)
+			if err != nil {
+				return fmt.Errorf("failed to get the last valset checkpoint: %w", err)
+			}
+
+			gravityID := string(bytes.TrimRight(gravityIDBytes[:], "\x00"))
+			if gravity.EncodeValsetConfirm(gravityID, payload.CurrentValset) != ethcmn.Hash(checkpoint) {
+				return fmt.Errorf(
+					"current valset %d doesn't match the checkpoint stored in the contract",
+					payload.CurrentValset.Nonce,
+				)
+			}
+
+			gravityVersion, err := resolveGravityVersion(konfig, ethRPC, gravityAddr)
+			if err != nil {
+				return err
+			}
+
+			// the committer is only needed to send transactions, which is done below
+			contract, err := gravity.NewGravityContract(
+				logger,
+				nil,
+				gravityAddr,
+				gravityContract,
+				gravity.OptionVersion(gravityVersion),
+			)
+			if err != nil {
+				return err
+			}
+
+			var txData []byte
+
+			switch payload.Type {
+			case payloadTypeBatch:
+				lastBatchNonce, err := gravityContract.LastBatchNonce(
+					callOpts,
+					ethcmn.HexToAddress(payload.Batch.TokenContract),
+				)
+				if err != nil {
+					return fmt.Errorf("failed to get the last batch nonce: %w", err)
+				}
+
+				if payload.Batch.BatchNonce <= lastBatchNonce.Uint64() {
+					return fmt.Errorf(
+						"batch %d was already superseded by batch %d",
+						payload.Batch.BatchNonce, lastBatchNonce.Uint64(),
+					)
+				}
+
+				txData, err = contract.EncodeTransactionBatch(
+					ctx,
+					payload.CurrentValset,
+					*payload.Batch,
+					payload.BatchConfirms,
+				)
+				if err != nil {
+					return err
+				}
+
+			case payloadTypeValset:
+				lastValsetNonce, err := gravityContract.StateLastValsetNonce(callOpts)
+				if err != nil {
+					return fmt.Errorf("failed to get the last valset nonce: %w", err)
+				}
+
+				if payload.Valset.Nonce <= lastValsetNonce.Uint64() {
+					return fmt.Errorf(
+						"valset %d was already superseded by valset %d",
+						payload.Valset.Nonce, lastValsetNonce.Uint64(),
+					)
+				}
+
+				txData, err = contract.EncodeValsetUpdate(
+					ctx,
+					payload.CurrentValset,
+					*payload.Valset,
+					payload.ValsetConfirms,
+				)
+				if err != nil {
+					return err
+				}
+			}
+
+			if txData == nil {
+				return errors.New("the confirms don't hold enough valid signatures of the current valset")
+			}
+
+			auth, err := buildTransactOpts(konfig, ethRPC)
+			if err != nil {
+				return err
+			}
+
+			boundContract := bind.NewBoundContract(gravityAddr, abi.ABI{}, ethRPC, ethRPC, ethRPC)
+
+			tx, err := boundContract.RawTransact(auth, txData)
+			if err != nil {
+				return fmt.Errorf("failed to submit the %s: %w", payload.Type, err)
+			}
+
+			fmt.Fprintf(os.Stderr, "Submitted the %s in transaction %s\n", payload.Type, tx.Hash().Hex())
+
+			ctx, cancel = context.WithTimeout(context.Background(), konfig.Duration(flagReceiptTimeout))
+			defer cancel()
+
+			receipt, err := bind.WaitMined(ctx, ethRPC, tx)
+			if err != nil {
+				return fmt.Errorf("failed to get the transaction receipt: %w", err)
+			}
+
+			if receipt.Status != ethtypes.ReceiptStatusSuccessful {
+				return errors.New("transaction reverted")
+			}
+
+			fmt.Fprintf(os.Stderr, "Transaction mined in block %d\n", receipt.BlockNumber.Uint64())
+
+			if isJSONOutput(konfig) {
+				return printJSON(txResult{
+					Type:        payload.Type,
+					TxHash:      tx.Hash().Hex(),
+					BlockNumber: receipt.BlockNumber.Uint64(),
+				})
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flagFromFile, "", "The JSON file holding the batch or valset update to submit")
+	cmd.Flags().Duration(flagReceiptTimeout, 5*time.Minute, "Maximum time to wait for the transaction receipt")
+	cmd.Flags().AddFlagSet(gravityVersionFlagSet())
+	_ = cmd.MarkFlagRequired(flagFromFile)
+
+	return cmd
+}
+
+func readSubmitPayload(path string) (submitPayload, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return submitPayload{}, fmt.Errorf("failed to read the payload: %w", err)
+	}
+
+	return decodeSubmitPayload(bz)
+}
+
+func decodeSubmitPayload(bz []byte) (submitPayload, error) {
+	var (
+		raw     submitPayloadJSON
+		payload submitPayload
+	)
+
+	if err := json.Unmarshal(bz, &raw); err != nil {
+		return payload, fmt.Errorf("failed to decode the payload: %w", err)
+	}
+
+	payload.Type = raw.Type
+
+	switch payload.Type {
+	case payloadTypeBatch:
+		if len(raw.Batch) == 0 || len(raw.BatchConfirms) == 0 {
+			return payload, errors.New("a batch payload requires the batch and its confirms")
+		}
+
+		payload.Batch = &gravitytypes.OutgoingTxBatch{}
+		if err := unmarshalGravityJSON(raw.Batch, payload.Batch); err != nil {
+			return payload, fmt.Errorf("failed to decode the batch: %w", err)
+		}
+
+		payload.BatchConfirms = make([]gravitytypes.MsgConfirmBatch, len(raw.BatchConfirms))
+		for i, confirm := range raw.BatchConfirms {
+			if err := unmarshalGravityJSON(confirm, &payload.BatchConfirms[i]); err != nil {
+				return payload, fmt.Errorf("failed to decode the batch confirm %d: %w", i, err)
+			}
+		}
+
+	case payloadTypeValset:
+		if len(raw.Valset) == 0 || len(raw.ValsetConfirms) == 0 {
+			return payload, errors.New("a valset payload requires the new valset and its confirms")
+		}
+
+		payload.Valset = &gravitytypes.Valset{}
+		if err := unmarshalGravityJSON(raw.Valset, payload.Valset); err != nil {
+			return payload, fmt.Errorf("failed to decode the valset: %w", err)
+		}
+
+		payload.ValsetConfirms = make([]gravitytypes.MsgValsetConfirm, len(raw.ValsetConfirms))
+		for i, confirm := range raw.ValsetConfirms {
+			if err := unmarshalGravityJSON(confirm, &payload.ValsetConfirms[i]); err != nil {
+				return payload, fmt.Errorf("failed to decode the valset confirm %d: %w", i, err)
+			}
+		}
+
+	default:
+		return payload, fmt.Errorf(
+			"invalid payload type %q; expected %s or %s",
+			payload.Type, payloadTypeBatch, payloadTypeValset,
+		)
+	}
+
+	if len(raw.CurrentValset) == 0 {
+		return payload, errors.New("the payload requires the current valset")
+	}
+
+	if err := unmarshalGravityJSON(raw.CurrentValset, &payload.CurrentValset); err != nil {
+		return payload, fmt.Errorf("failed to decode the current valset: %w", err)
+	}
+
+	if len(payload.CurrentValset.Members) == 0 {
+		return payload, errors.New("the payload requires the current valset")
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,114 @@
+package peggo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/gravity"
+)
+
+// The messages below are in the proto3 JSON the Gravity module's CLI and REST
+// return, with the 64-bit integers as strings.
+const (
+	moduleValsetJSON = `{
+		"nonce": "0",
+		"members": [{"power": "6667", "ethereum_address": "0xc783df8a850f42e7F7e57013759C285caa701eB6"}],
+		"height": "0",
+		"reward_amount": "0",
+		"reward_token": "0x0000000000000000000000000000000000000000"
+	}`
+	moduleBatchJSON = `{
+		"batch_nonce": "1",
+		"batch_timeout": "2111",
+		"transactions": [{
+			"id": "1",
+			"sender": "umee12flmaejjvzdtz58s4m5avx30wm8uffe7kw02mh",
+			"dest_address": "0x9FC9C2DfBA3b6cF204C37a5F690619772b926e39",
+			"erc20_token": {"contract": "0x835973768750b3ED2D5c3EF5AdcD5eDb44d12aD4", "amount": "1"},
+			"erc20_fee": {"contract": "0x835973768750b3ED2D5c3EF5AdcD5eDb44d12aD4", "amount": "1"}
+		}],
+		"token_contract": "0x835973768750b3ED2D5c3EF5AdcD5eDb44d12aD4",
+		"block": "12"
+	}`
+)
+
+func TestDecodeSubmitPayload(t *testing.T) {
+	payload, err := decodeSubmitPayload([]byte(`{
+		"type": "batch",
+		"current_valset": ` + moduleValsetJSON + `,
+		"batch": ` + moduleBatchJSON + `,
+		"batch_confirms": [{
+			"nonce": "1",
+			"token_contract": "0x835973768750b3ED2D5c3EF5AdcD5eDb44d12aD4",
+			"eth_signer": "0xc783df8a850f42e7F7e57013759C285caa701eB6",
+			"orchestrator": "umee12flmaejjvzdtz58s4m5avx30wm8uffe7kw02mh",
+			"signature": "0xabcd"
+		}]
+	}`))
+	require.NoError(t, err)
+
+	require.Len(t, payload.CurrentValset.Members, 1)
+	assert.Equal(t, uint64(6667), payload.CurrentValset.Members[0].Power)
+
+	require.NotNil(t, payload.Batch)
+	assert.Equal(t, uint64(1), payload.Batch.BatchNonce)
+	assert.Equal(t, uint64(2111), payload.Batch.BatchTimeout)
+	assert.Equal(t, uint64(12), payload.Batch.Block)
+	require.Len(t, payload.Batch.Transactions, 1)
+	assert.Equal(t, "1", payload.Batch.Transactions[0].Erc20Token.Amount.String())
+
+	require.Len(t, payload.BatchConfirms, 1)
+	assert.Equal(t, uint64(1), payload.BatchConfirms[0].Nonce)
+	assert.Equal(t, "0xabcd", payload.BatchConfirms[0].Signature)
+
+	// checkpoints from the Gravity.sol tests, for the "foo" Gravity ID
+	assert.Equal(
+		t,
+		"0x89731c26bab12cf0cb5363ef9abab6f9bd5496cf758a2309311c7946d54bca85",
+		gravity.EncodeValsetConfirm("foo", payload.CurrentValset).Hex(),
+	)
+	assert.Equal(
+		t,
+		"0xa3a7ee0a363b8ad2514e7ee8f110d7449c0d88f3b0913c28c1751e6e0079a9b2",
+		gravity.EncodeTxBatchConfirm("foo", *payload.Batch).Hex(),
+	)
+}
+
+func TestDecodeSubmitPayloadValset(t *testing.T) {
+	payload, err := decodeSubmitPayload([]byte(`{
+		"type": "valset",
+		"current_valset": ` + moduleValsetJSON + `,
+		"valset": ` + moduleValsetJSON + `,
+		"valset_confirms": [{
+			"nonce": "0",
+			"orchestrator": "umee12flmaejjvzdtz58s4m5avx30wm8uffe7kw02mh",
+			"eth_address": "0xc783df8a850f42e7F7e57013759C285caa701eB6",
+			"signature": "0xabcd"
+		}]
+	}`))
+	require.NoError(t, err)
+
+	require.NotNil(t, payload.Valset)
+	assert.Equal(t, uint64(6667), payload.Valset.Members[0].Power)
+	require.Len(t, payload.ValsetConfirms, 1)
+	assert.Equal(t, "0xc783df8a850f42e7F7e57013759C285caa701eB6", payload.ValsetConfirms[0].EthAddress)
+}
+
+func TestDecodeSubmitPayloadInvalid(t *testing.T) {
+	testCases := map[string]string{
+		"type":           `{"type": "logic_call", "current_valset": ` + moduleValsetJSON + `}`,
+		"no confirms":    `{"type": "batch", "current_valset": ` + moduleValsetJSON + `, "batch": ` + moduleBatchJSON + `}`,
+		"no valset":      `{"type": "batch", "batch": ` + moduleBatchJSON + `, "batch_confirms": [{"nonce": "1"}]}`,
+		"invalid nonce":  `{"type": "batch", "current_valset": ` + moduleValsetJSON + `, "batch": {"batch_nonce": "x"}, "batch_confirms": [{"nonce": "1"}]}`, //nolint: lll
+		"invalid format": `[]`,
+	}
+
+	for name, payload := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := decodeSubmitPayload([]byte(payload))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -2,27 +2,14 @@ package peggo
 
 import (
 	"context"
-	"encoding/hex"
 	"fmt"
 	"os"
-	"strings"
 	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/connectivity"
 )
 
-func hexToBytes(str string) ([]byte, error) {
-	str = strings.TrimPrefix(str, "0x")
-
-	data, err := hex.DecodeString(str)
-	if err != nil {
-		return nil, err
-	}
-
-	return data, nil
-}
-
 // waitForService awaits an active connection to a gRPC service.
 func waitForService(ctx context.Context, clientconn *grpc.ClientConn) {
 	for {
@@ -0,0 +1,141 @@
+package peggo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/knadh/koanf"
+	"github.com/spf13/pflag"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/verifier"
+	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
+)
+
+const (
+	sourceVerificationTimeout = 5 * time.Minute
+	sourceVerificationPoll    = 5 * time.Second
+)
+
+// deploymentVerifier checks the code of deployed contracts against the embedded
+// artifacts and, if an explorer is configured, submits their sources to it.
+type deploymentVerifier struct {
+	codeReader      verifier.CodeReader
+	explorer        *verifier.Explorer
+	standardJSON    string
+	compilerVersion string
+}
+
+func verifyFlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+
+	fs.Bool(flagVerifyDeployment, false, "Wait for the deployment receipt and check the deployed bytecode")
+	fs.String(flagVerifyExplorerURL, "", "Etherscan compatible API (e.g. Etherscan, Blockscout) to submit the contract sources to") //nolint: lll
+	fs.String(flagVerifyExplorerKey, "", "The API key of the explorer")
+	fs.String(flagVerifySourceFile, "", "The solc standard JSON input of the contracts, required to submit their sources") //nolint: lll
+	fs.String(flagVerifyCompiler, verifier.DefaultCompilerVersion, "The solc version the contracts were compiled with")
+
+	return fs
+}
+
+// newDeploymentVerifier returns the verifier of the deployed contracts, or nil
+// if --verify isn't set, the deploy commands then returning once the deployment
+// tx is sent.
+func newDeploymentVerifier(konfig *koanf.Koanf, codeReader verifier.CodeReader) (*deploymentVerifier, error) {
+	explorerURL := konfig.String(flagVerifyExplorerURL)
+
+	if !konfig.Bool(flagVerifyDeployment) {
+		if explorerURL != "" {
+			return nil, fmt.Errorf("--%s requires --%s", flagVerifyExplorerURL, flagVerifyDeployment)
+		}
+
+		return nil, nil
+	}
+
+	v := &deploymentVerifier{
+		codeReader:      codeReader,
+		compilerVersion: konfig.String(flagVerifyCompiler),
+	}
+
+	if explorerURL == "" {
+		return v, nil
+	}
+
+	sourceFile := konfig.String(flagVerifySourceFile)
+	if sourceFile == "" {
+		return nil, fmt.Errorf("--%s is required to submit the sources to an explorer", flagVerifySourceFile)
+	}
+
+	standardJSON, err := os.ReadFile(sourceFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the contracts sources: %w", err)
+	}
+
+	explorer, err := verifier.NewExplorer(explorerURL, konfig.String(flagVerifyExplorerKey))
+	if err != nil {
+		return nil, err
+	}
+
+	v.explorer = explorer
+	v.standardJSON = string(standardJSON)
+
+	return v, nil
+}
+
+// verify checks the code deployed at address against the creation bytecode and
+// submits the sources of the contract to the explorer, if any.
+func (v *deploymentVerifier) verify(
+	address ethcmn.Address,
+	creationBin string,
+	contractName string,
+	constructorArgs []byte,
+) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if err := verifier.VerifyDeployedCode(ctx, v.codeReader, address, creationBin); err != nil {
+		return fmt.Errorf("deployed bytecode verification failed: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Deployed bytecode of %s matches the embedded artifact\n", address.Hex())
+
+	if v.explorer == nil {
+		return nil
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), sourceVerificationTimeout)
+	defer cancel()
+
+	err := v.explorer.Verify(ctx, verifier.SourceRequest{
+		Address:         address.Hex(),
+		ContractName:    contractName,
+		CompilerVersion: v.compilerVersion,
+		StandardJSON:    v.standardJSON,
+		ConstructorArgs: constructorArgs,
+	}, sourceVerificationPoll)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Sources of %s verified on the explorer\n", address.Hex())
+
+	return nil
+}
+
+// verifyERC20 verifies an ERC20 deployed by the Gravity contract.
+func (v *deploymentVerifier) verifyERC20(gravityAddr, address ethcmn.Address, metadata erc20Metadata) error {
+	erc20ABI, err := wrappers.CosmosERC20MetaData.GetAbi()
+	if err != nil {
+		return err
+	}
+
+	// packing the constructor arguments only, hence the empty method name
+	args, err := erc20ABI.Pack("", gravityAddr, metadata.name, metadata.symbol, metadata.decimals)
+	if err != nil {
+		return fmt.Errorf("failed to pack the ERC20 constructor arguments: %w", err)
+	}
+
+	return v.verify(address, wrappers.CosmosERC20MetaData.Bin, verifier.CosmosERC20ContractName, args)
+}
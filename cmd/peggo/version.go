@@ -1,7 +1,6 @@
 package peggo
 
 import (
-	"encoding/json"
 	"fmt"
 	"runtime"
 
@@ -41,21 +40,16 @@ func getVersionCmd() *cobra.Command {
 				Go:      fmt.Sprintf("%s %s/%s", runtime.Version(), runtime.GOOS, runtime.GOARCH),
 			}
 
-			format, err := cmd.Flags().GetString(flagFormat)
+			konfig, err := parseServerConfig(cmd)
 			if err != nil {
 				return err
 			}
 
-			var bz []byte
-
-			switch format {
-			case "json":
-				bz, err = json.Marshal(verInfo)
-
-			default:
-				bz, err = yaml.Marshal(&verInfo)
+			if isJSONOutput(konfig) {
+				return printJSON(verInfo)
 			}
 
+			bz, err := yaml.Marshal(&verInfo)
 			if err != nil {
 				return err
 			}
@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	client "github.com/cosmos/cosmos-sdk/client"
@@ -55,6 +56,26 @@ func (mr *MockCosmosClientMockRecorder) AsyncBroadcastMsg(arg0 ...interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AsyncBroadcastMsg", reflect.TypeOf((*MockCosmosClient)(nil).AsyncBroadcastMsg), arg0...)
 }
 
+// BroadcastMsgWithGas mocks base method.
+func (m *MockCosmosClient) BroadcastMsgWithGas(arg0 uint64, arg1 ...types.Msg) (*types.TxResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "BroadcastMsgWithGas", varargs...)
+	ret0, _ := ret[0].(*types.TxResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BroadcastMsgWithGas indicates an expected call of BroadcastMsgWithGas.
+func (mr *MockCosmosClientMockRecorder) BroadcastMsgWithGas(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BroadcastMsgWithGas", reflect.TypeOf((*MockCosmosClient)(nil).BroadcastMsgWithGas), varargs...)
+}
+
 // CanSignTransactions mocks base method.
 func (m *MockCosmosClient) CanSignTransactions() bool {
 	m.ctrl.T.Helper()
@@ -159,3 +180,18 @@ func (mr *MockCosmosClientMockRecorder) SyncBroadcastMsg(arg0 ...interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncBroadcastMsg", reflect.TypeOf((*MockCosmosClient)(nil).SyncBroadcastMsg), arg0...)
 }
+
+// WaitTx mocks base method.
+func (m *MockCosmosClient) WaitTx(arg0 context.Context, arg1 string) (*types.TxResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitTx", arg0, arg1)
+	ret0, _ := ret[0].(*types.TxResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WaitTx indicates an expected call of WaitTx.
+func (mr *MockCosmosClientMockRecorder) WaitTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitTx", reflect.TypeOf((*MockCosmosClient)(nil).WaitTx), arg0, arg1)
+}
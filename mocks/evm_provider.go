@@ -68,6 +68,22 @@ func (mr *MockEVMProviderWithRetMockRecorder) CodeAt(arg0, arg1, arg2 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CodeAt", reflect.TypeOf((*MockEVMProviderWithRet)(nil).CodeAt), arg0, arg1, arg2)
 }
 
+// CreateAccessList mocks base method.
+func (m *MockEVMProviderWithRet) CreateAccessList(arg0 context.Context, arg1 ethereum.CallMsg) (*types.AccessList, uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccessList", arg0, arg1)
+	ret0, _ := ret[0].(*types.AccessList)
+	ret1, _ := ret[1].(uint64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateAccessList indicates an expected call of CreateAccessList.
+func (mr *MockEVMProviderWithRetMockRecorder) CreateAccessList(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccessList", reflect.TypeOf((*MockEVMProviderWithRet)(nil).CreateAccessList), arg0, arg1)
+}
+
 // EstimateGas mocks base method.
 func (m *MockEVMProviderWithRet) EstimateGas(arg0 context.Context, arg1 ethereum.CallMsg) (uint64, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,141 @@
+// Package admin serves the operator actions on a running orchestrator, e.g.
+// approving a batch held by the relayer, over HTTP authenticated with a shared
+// token, and provides the client the CLI uses to call them.
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog"
+
+	"github.com/umee-network/peggo/orchestrator/relayer"
+	"github.com/umee-network/peggo/orchestrator/store"
+)
+
+const (
+	// PathBatchApprovals lists (GET) and approves (POST) the batches held by
+	// the value concentration guardrail.
+	PathBatchApprovals = "/v1/batch-approvals"
+
+	// MinTokenLength is the minimum length of the token authenticating the
+	// admin requests.
+	MinTokenLength = 16
+
+	maxRequestSize = 1 << 16
+)
+
+type (
+	// BatchApprovalRequest approves the batch of a token contract with the
+	// given nonce.
+	BatchApprovalRequest struct {
+		TokenContract string `json:"token_contract"`
+		BatchNonce    uint64 `json:"batch_nonce"`
+	}
+
+	// BatchApprovalsResponse lists the approved batches.
+	BatchApprovalsResponse struct {
+		Approvals []relayer.BatchApproval `json:"approvals"`
+	}
+
+	errorResponse struct {
+		Error string `json:"error"`
+	}
+
+	handler struct {
+		logger zerolog.Logger
+		store  *store.Store
+		token  string
+	}
+)
+
+// NewHandler returns the HTTP handler of the admin API, acting on the local
+// store of the orchestrator. Every request must carry the token:
+//
+//   - GET /v1/batch-approvals returns the approved batches
+//   - POST /v1/batch-approvals approves a batch held by the relayer
+func NewHandler(logger zerolog.Logger, s *store.Store, token string) (http.Handler, error) {
+	if len(token) < MinTokenLength {
+		return nil, fmt.Errorf("admin token must be at least %d characters long", MinTokenLength)
+	}
+
+	h := &handler{
+		logger: logger.With().Str("module", "admin").Logger(),
+		store:  s,
+		token:  token,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathBatchApprovals, h.handleBatchApprovals)
+
+	return h.authenticate(mux), nil
+}
+
+func (h *handler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+			h.logger.Warn().Str("path", r.URL.Path).Msg("refused unauthenticated admin request")
+			writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "unauthorized"})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *handler) handleBatchApprovals(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req BatchApprovalRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid request"})
+			return
+		}
+
+		if !ethcmn.IsHexAddress(req.TokenContract) {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid token contract address"})
+			return
+		}
+
+		tokenContract := ethcmn.HexToAddress(req.TokenContract)
+		if err := relayer.ApproveBatch(h.store, tokenContract, req.BatchNonce); err != nil {
+			h.logger.Err(err).Msg("failed to approve batch")
+			writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to approve batch"})
+			return
+		}
+
+		h.logger.Info().
+			Str("token_contract", tokenContract.Hex()).
+			Uint64("batch_nonce", req.BatchNonce).
+			Msg("batch approved")
+
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+
+	approvals, err := relayer.GetBatchApprovals(h.store)
+	if err != nil {
+		h.logger.Err(err).Msg("failed to read batch approvals")
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to read batch approvals"})
+		return
+	}
+
+	if approvals == nil {
+		approvals = []relayer.BatchApproval{}
+	}
+
+	writeJSON(w, http.StatusOK, BatchApprovalsResponse{Approvals: approvals})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
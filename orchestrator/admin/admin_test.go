@@ -0,0 +1,62 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umee-network/peggo/orchestrator/store"
+)
+
+func TestBatchApprovals(t *testing.T) {
+	const token = "0123456789abcdef"
+
+	st, err := store.New(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = NewHandler(zerolog.Nop(), st, "short")
+	assert.Error(t, err)
+
+	handler, err := NewHandler(zerolog.Nop(), st, token)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	ctx := context.Background()
+	tokenContract := ethcmn.HexToAddress("0x0000000000000000000000000000000000000005")
+
+	client := NewClient(srv.URL, token)
+
+	approvals, err := client.BatchApprovals(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, approvals)
+
+	approvals, err = client.ApproveBatch(ctx, tokenContract, 7)
+	require.NoError(t, err)
+	require.Len(t, approvals, 1)
+	assert.Equal(t, tokenContract.Hex(), approvals[0].TokenContract)
+	assert.Equal(t, uint64(7), approvals[0].BatchNonce)
+
+	_, err = NewClient(srv.URL, "0123456789abcdeX").ApproveBatch(ctx, tokenContract, 8)
+	assert.ErrorContains(t, err, "unauthorized")
+
+	req, err := http.NewRequest(http.MethodDelete, srv.URL+PathBatchApprovals, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	approvals, err = client.BatchApprovals(ctx)
+	require.NoError(t, err)
+	assert.Len(t, approvals, 1)
+}
@@ -0,0 +1,86 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/umee-network/peggo/orchestrator/relayer"
+)
+
+// Client calls the admin API of a running orchestrator.
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient returns a client of the admin API served at baseURL, e.g.
+// http://127.0.0.1:9303.
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		baseURL:    baseURL,
+		token:      token,
+		httpClient: &http.Client{},
+	}
+}
+
+// ApproveBatch approves the batch of tokenContract with the given nonce, and
+// returns all the approved batches.
+func (c *Client) ApproveBatch(
+	ctx context.Context,
+	tokenContract ethcmn.Address,
+	batchNonce uint64,
+) ([]relayer.BatchApproval, error) {
+	var resp BatchApprovalsResponse
+	err := c.do(ctx, http.MethodPost, PathBatchApprovals, BatchApprovalRequest{
+		TokenContract: tokenContract.Hex(),
+		BatchNonce:    batchNonce,
+	}, &resp)
+
+	return resp.Approvals, err
+}
+
+// BatchApprovals returns the approved batches.
+func (c *Client) BatchApprovals(ctx context.Context) ([]relayer.BatchApproval, error) {
+	var resp BatchApprovalsResponse
+	err := c.do(ctx, http.MethodGet, PathBatchApprovals, nil, &resp)
+
+	return resp.Approvals, err
+}
+
+func (c *Client) do(ctx context.Context, method, path string, req, resp interface{}) error {
+	var body bytes.Buffer
+	if req != nil {
+		if err := json.NewEncoder(&body).Encode(req); err != nil {
+			return errors.Wrap(err, "failed to encode admin request")
+		}
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, &body)
+	if err != nil {
+		return err
+	}
+
+	httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return errors.Wrap(err, "failed to reach the orchestrator admin API")
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		var errResp errorResponse
+		_ = json.NewDecoder(httpResp.Body).Decode(&errResp)
+		return fmt.Errorf("orchestrator refused admin request (%s): %s", httpResp.Status, errResp.Error)
+	}
+
+	return errors.Wrap(json.NewDecoder(httpResp.Body).Decode(resp), "failed to decode admin response")
+}
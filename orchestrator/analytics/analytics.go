@@ -0,0 +1,357 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/umee-network/peggo/orchestrator/loops"
+	payloadv1 "github.com/umee-network/peggo/orchestrator/payload/v1"
+	"github.com/umee-network/peggo/orchestrator/schedule"
+	"github.com/umee-network/peggo/orchestrator/store"
+)
+
+// stateStoreKey is the store key holding the last scanned Ethereum block and
+// the days not yet summarized.
+const stateStoreKey = "analytics_state"
+
+const (
+	// blocksPerScan bounds the number of Ethereum blocks scanned per query.
+	blocksPerScan = 2000
+
+	dateLayout  = "2006-01-02"
+	maxRespTime = 15 * time.Second
+)
+
+// Transfer kinds.
+const (
+	KindDeposit    = "deposit"
+	KindWithdrawal = "withdrawal"
+)
+
+type (
+	// Transfer is a deposit to, or a withdrawal from, the Gravity contract.
+	Transfer struct {
+		Kind          string
+		TokenContract string
+		// Addresses are the sender and receiver(s) of the transfer, on either
+		// chain.
+		Addresses []string
+		Amount    *big.Int
+		Time      time.Time
+	}
+
+	// Source returns the bridge transfers in a range of Ethereum blocks.
+	Source interface {
+		LatestHeight(ctx context.Context) (uint64, error)
+		BlockTime(ctx context.Context, height uint64) (time.Time, error)
+		Transfers(ctx context.Context, from, to uint64) ([]Transfer, error)
+	}
+
+	// Config defines how often bridge usage is aggregated and where the daily
+	// summaries are written. At least one of Dir or WebhookURL must be set.
+	Config struct {
+		Interval time.Duration
+		// Schedule runs the aggregation instead of every Interval when set,
+		// e.g. once a day to write the summaries.
+		Schedule schedule.Schedule
+		// Confirmations is the number of blocks an Ethereum block must be
+		// buried under before its transfers are counted.
+		Confirmations uint64
+		// StartHeight is the Ethereum height to start aggregating from on the
+		// first run. Zero starts from the latest height.
+		StartHeight uint64
+
+		// Dir receives a file per day, in Format (json or csv).
+		Dir    string
+		Format string
+		// WebhookURL receives a JSON POST per day.
+		WebhookURL string
+		// Labels are added to the summaries (see payloadv1.Labels).
+		Labels payloadv1.Labels
+	}
+
+	// DailySummary summarizes the bridge usage of a UTC day.
+	DailySummary = payloadv1.DailySummary
+
+	// TokenSummary is the payloadv1 token summary, exported for the callers of
+	// this package.
+	TokenSummary = payloadv1.TokenSummary
+
+	// Aggregator scans the bridge transfers and writes a summary of each UTC
+	// day once its blocks are all scanned.
+	Aggregator struct {
+		logger zerolog.Logger
+		config Config
+		store  *store.Store
+		source Source
+		client *http.Client
+		state  state
+	}
+
+	state struct {
+		// Height is the last scanned Ethereum height, and BlockTime the time
+		// of its block.
+		Height    uint64               `json:"height"`
+		BlockTime time.Time            `json:"block_time"`
+		Days      map[string]*dayState `json:"days"`
+	}
+
+	dayState struct {
+		Partial bool                   `json:"partial,omitempty"`
+		Tokens  map[string]*tokenState `json:"tokens"`
+	}
+
+	tokenState struct {
+		Deposits         int             `json:"deposits"`
+		DepositVolume    *big.Int        `json:"deposit_volume"`
+		Withdrawals      int             `json:"withdrawals"`
+		WithdrawalVolume *big.Int        `json:"withdrawal_volume"`
+		Addresses        map[string]bool `json:"addresses"`
+	}
+)
+
+// New returns an aggregator resuming from the state persisted in st.
+func New(logger zerolog.Logger, config Config, st *store.Store, source Source) (*Aggregator, error) {
+	if config.Schedule == nil {
+		if config.Interval <= 0 {
+			return nil, fmt.Errorf("invalid analytics interval: %s", config.Interval)
+		}
+
+		config.Schedule = schedule.Every(config.Interval)
+	}
+
+	if config.Dir == "" && config.WebhookURL == "" {
+		return nil, errors.New("analytics require an output directory or a webhook")
+	}
+
+	if config.Dir != "" {
+		switch config.Format {
+		case FormatJSON, FormatCSV:
+		default:
+			return nil, fmt.Errorf("invalid analytics format: %s", config.Format)
+		}
+	}
+
+	a := &Aggregator{
+		logger: logger.With().Str("module", "analytics").Logger(),
+		config: config,
+		store:  st,
+		source: source,
+		client: &http.Client{Timeout: maxRespTime},
+	}
+
+	if _, err := st.Get(stateStoreKey, &a.state); err != nil {
+		return nil, errors.Wrap(err, "failed to load analytics state")
+	}
+
+	if a.state.Days == nil {
+		a.state.Days = map[string]*dayState{}
+	}
+
+	return a, nil
+}
+
+// Start aggregates the new transfers on its schedule until the context is
+// done. Failures are logged and retried on the next run.
+func (a *Aggregator) Start(ctx context.Context) error {
+	return loops.RunSchedule(ctx, a.logger, a.config.Schedule, func() error {
+		if err := a.update(ctx); err != nil {
+			a.logger.Err(err).Msg("failed to update bridge usage analytics")
+		}
+
+		return nil
+	})
+}
+
+// update scans the blocks confirmed since the last update and writes the
+// summaries of the days before the last scanned block. A summary that fails to be written is
+// retried on the next update.
+func (a *Aggregator) update(ctx context.Context) error {
+	latest, err := a.source.LatestHeight(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the latest Ethereum height")
+	}
+
+	if latest <= a.config.Confirmations {
+		return nil
+	}
+	latest -= a.config.Confirmations
+
+	if a.state.Height == 0 {
+		if err := a.start(ctx, latest); err != nil {
+			return err
+		}
+	}
+
+	for from := a.state.Height + 1; from <= latest; from += blocksPerScan {
+		to := from + blocksPerScan - 1
+		if to > latest {
+			to = latest
+		}
+
+		transfers, err := a.source.Transfers(ctx, from, to)
+		if err != nil {
+			return errors.Wrapf(err, "failed to scan blocks %d to %d", from, to)
+		}
+
+		blockTime, err := a.source.BlockTime(ctx, to)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the time of block %d", to)
+		}
+
+		for _, t := range transfers {
+			a.add(t)
+		}
+
+		a.state.Height = to
+		a.state.BlockTime = blockTime
+		a.persist()
+	}
+
+	a.flush(ctx)
+
+	return nil
+}
+
+// start sets the height to scan from on the first run. The day of the latest
+// block is partial, unless starting from the configured height.
+func (a *Aggregator) start(ctx context.Context, latest uint64) error {
+	if a.config.StartHeight > 0 {
+		a.state.Height = a.config.StartHeight - 1
+		return nil
+	}
+
+	blockTime, err := a.source.BlockTime(ctx, latest)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the time of block %d", latest)
+	}
+
+	a.state.Height = latest
+	a.state.BlockTime = blockTime
+	a.day(blockTime.UTC().Format(dateLayout)).Partial = true
+
+	return nil
+}
+
+func (a *Aggregator) day(date string) *dayState {
+	day, ok := a.state.Days[date]
+	if !ok {
+		day = &dayState{Tokens: map[string]*tokenState{}}
+		a.state.Days[date] = day
+	}
+
+	return day
+}
+
+// add counts a transfer in the day it happened.
+func (a *Aggregator) add(t Transfer) {
+	day := a.day(t.Time.UTC().Format(dateLayout))
+
+	token := strings.ToLower(t.TokenContract)
+	ts, ok := day.Tokens[token]
+	if !ok {
+		ts = &tokenState{
+			DepositVolume:    new(big.Int),
+			WithdrawalVolume: new(big.Int),
+			Addresses:        map[string]bool{},
+		}
+		day.Tokens[token] = ts
+	}
+
+	switch t.Kind {
+	case KindDeposit:
+		ts.Deposits++
+		ts.DepositVolume.Add(ts.DepositVolume, t.Amount)
+
+	case KindWithdrawal:
+		ts.Withdrawals++
+		ts.WithdrawalVolume.Add(ts.WithdrawalVolume, t.Amount)
+	}
+
+	for _, addr := range t.Addresses {
+		ts.Addresses[strings.ToLower(addr)] = true
+	}
+}
+
+// flush writes the summaries of the days before the last scanned block, oldest
+// first, and drops them from the state once written. The scanner lags behind
+// the chain head, so a day may be over before all of its blocks are scanned.
+func (a *Aggregator) flush(ctx context.Context) {
+	if a.state.BlockTime.IsZero() {
+		return
+	}
+	scanned := a.state.BlockTime.UTC().Format(dateLayout)
+
+	dates := make([]string, 0, len(a.state.Days))
+	for date := range a.state.Days {
+		if date < scanned {
+			dates = append(dates, date)
+		}
+	}
+	sort.Strings(dates)
+
+	for _, date := range dates {
+		summary := a.state.Days[date].summary(date, a.config.Labels)
+
+		if err := a.write(ctx, summary); err != nil {
+			a.logger.Err(err).Str("date", date).Msg("failed to write bridge usage summary")
+			return
+		}
+
+		a.logger.Info().
+			Str("date", date).
+			Int("tokens", len(summary.Tokens)).
+			Int("unique_addresses", summary.UniqueAddresses).
+			Msg("wrote bridge usage summary")
+
+		delete(a.state.Days, date)
+		a.persist()
+	}
+}
+
+func (a *Aggregator) persist() {
+	if err := a.store.Set(stateStoreKey, a.state); err != nil {
+		a.logger.Err(err).Msg("failed to persist analytics state")
+	}
+}
+
+// summary returns the summary of the day, with its tokens sorted by contract.
+func (d *dayState) summary(date string, labels payloadv1.Labels) DailySummary {
+	summary := DailySummary{
+		Date:    date,
+		Partial: d.Partial,
+		Tokens:  []TokenSummary{},
+		Labels:  labels,
+	}
+
+	addresses := map[string]bool{}
+	for token, ts := range d.Tokens {
+		summary.Tokens = append(summary.Tokens, TokenSummary{
+			TokenContract:    token,
+			Deposits:         ts.Deposits,
+			DepositVolume:    ts.DepositVolume.String(),
+			Withdrawals:      ts.Withdrawals,
+			WithdrawalVolume: ts.WithdrawalVolume.String(),
+			UniqueAddresses:  len(ts.Addresses),
+		})
+
+		for addr := range ts.Addresses {
+			addresses[addr] = true
+		}
+	}
+
+	sort.Slice(summary.Tokens, func(i, j int) bool {
+		return summary.Tokens[i].TokenContract < summary.Tokens[j].TokenContract
+	})
+	summary.UniqueAddresses = len(addresses)
+
+	return summary
+}
@@ -0,0 +1,296 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umee-network/peggo/orchestrator/store"
+)
+
+type mockSource struct {
+	latest     uint64
+	blockTimes map[uint64]time.Time
+	transfers  map[uint64][]Transfer
+}
+
+func (m *mockSource) LatestHeight(context.Context) (uint64, error) {
+	return m.latest, nil
+}
+
+func (m *mockSource) BlockTime(_ context.Context, height uint64) (time.Time, error) {
+	return m.blockTimes[height], nil
+}
+
+func (m *mockSource) Transfers(_ context.Context, from, to uint64) ([]Transfer, error) {
+	var transfers []Transfer
+	for h := from; h <= to; h++ {
+		transfers = append(transfers, m.transfers[h]...)
+	}
+
+	return transfers, nil
+}
+
+func TestAggregator(t *testing.T) {
+	s, err := store.New(t.TempDir())
+	require.NoError(t, err)
+
+	day1 := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+
+	source := &mockSource{
+		latest:     12,
+		blockTimes: map[uint64]time.Time{10: day2},
+		transfers: map[uint64][]Transfer{
+			1: {{
+				Kind:          KindDeposit,
+				TokenContract: "0xAAAA",
+				Addresses:     []string{"0x01", "umee1a"},
+				Amount:        big.NewInt(100),
+				Time:          day1,
+			}},
+			2: {
+				{
+					Kind:          KindWithdrawal,
+					TokenContract: "0xaaaa",
+					Addresses:     []string{"0x01"},
+					Amount:        big.NewInt(40),
+					Time:          day1,
+				},
+				{
+					Kind:          KindWithdrawal,
+					TokenContract: "0xbbbb",
+					Addresses:     []string{"0x02"},
+					Amount:        big.NewInt(7),
+					Time:          day1,
+				},
+			},
+			// confirmed, but on a day not over yet
+			8: {{
+				Kind:          KindDeposit,
+				TokenContract: "0xaaaa",
+				Addresses:     []string{"0x03", "umee1b"},
+				Amount:        big.NewInt(5),
+				Time:          day2,
+			}},
+			// not confirmed yet
+			11: {{
+				Kind:          KindDeposit,
+				TokenContract: "0xaaaa",
+				Addresses:     []string{"0x04", "umee1c"},
+				Amount:        big.NewInt(1),
+				Time:          day2,
+			}},
+		},
+	}
+
+	dir := t.TempDir()
+	config := Config{
+		Interval:      time.Minute,
+		Confirmations: 2,
+		StartHeight:   1,
+		Dir:           dir,
+		Format:        FormatJSON,
+	}
+
+	a, err := New(zerolog.Nop(), config, s, source)
+	require.NoError(t, err)
+
+	require.NoError(t, a.update(context.Background()))
+
+	bz, err := os.ReadFile(filepath.Join(dir, SummaryFileName("2022-10-01", FormatJSON)))
+	require.NoError(t, err)
+
+	var summary DailySummary
+	require.NoError(t, json.Unmarshal(bz, &summary))
+	assert.Equal(t, DailySummary{
+		Date: "2022-10-01",
+		Tokens: []TokenSummary{
+			{
+				TokenContract:    "0xaaaa",
+				Deposits:         1,
+				DepositVolume:    "100",
+				Withdrawals:      1,
+				WithdrawalVolume: "40",
+				UniqueAddresses:  2,
+			},
+			{
+				TokenContract:    "0xbbbb",
+				DepositVolume:    "0",
+				Withdrawals:      1,
+				WithdrawalVolume: "7",
+				UniqueAddresses:  1,
+			},
+		},
+		UniqueAddresses: 3,
+	}, summary)
+
+	// the next day is resumed from the store
+	a, err = New(zerolog.Nop(), config, s, source)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), a.state.Height)
+	assert.Len(t, a.state.Days, 1)
+	assert.Equal(t, 1, a.state.Days["2022-10-02"].Tokens["0xaaaa"].Deposits)
+}
+
+func TestAggregatorLagging(t *testing.T) {
+	s, err := store.New(t.TempDir())
+	require.NoError(t, err)
+
+	day1 := time.Date(2022, 10, 1, 23, 0, 0, 0, time.UTC)
+	day2 := day1.Add(2 * time.Hour)
+
+	source := &mockSource{
+		latest:     5,
+		blockTimes: map[uint64]time.Time{3: day1, 5: day1, 7: day2},
+		transfers: map[uint64][]Transfer{
+			1: {{
+				Kind:          KindDeposit,
+				TokenContract: "0xaaaa",
+				Addresses:     []string{"0x01"},
+				Amount:        big.NewInt(1),
+				Time:          day1,
+			}},
+			// confirmed after the day is over
+			5: {{
+				Kind:          KindDeposit,
+				TokenContract: "0xaaaa",
+				Addresses:     []string{"0x02"},
+				Amount:        big.NewInt(2),
+				Time:          day1,
+			}},
+		},
+	}
+
+	dir := t.TempDir()
+	a, err := New(
+		zerolog.Nop(),
+		Config{Interval: time.Minute, Confirmations: 2, StartHeight: 1, Dir: dir, Format: FormatJSON},
+		s,
+		source,
+	)
+	require.NoError(t, err)
+
+	// the scanned blocks are still in the first day
+	require.NoError(t, a.update(context.Background()))
+	assert.Contains(t, a.state.Days, "2022-10-01")
+	assert.NoFileExists(t, filepath.Join(dir, SummaryFileName("2022-10-01", FormatJSON)))
+
+	source.latest = 9
+	require.NoError(t, a.update(context.Background()))
+	assert.Empty(t, a.state.Days)
+
+	bz, err := os.ReadFile(filepath.Join(dir, SummaryFileName("2022-10-01", FormatJSON)))
+	require.NoError(t, err)
+
+	var summary DailySummary
+	require.NoError(t, json.Unmarshal(bz, &summary))
+	require.Len(t, summary.Tokens, 1)
+	assert.Equal(t, 2, summary.Tokens[0].Deposits)
+	assert.Equal(t, "3", summary.Tokens[0].DepositVolume)
+}
+
+func TestAggregatorFirstRun(t *testing.T) {
+	s, err := store.New(t.TempDir())
+	require.NoError(t, err)
+
+	blockTime := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	a, err := New(
+		zerolog.Nop(),
+		Config{Interval: time.Minute, Dir: t.TempDir(), Format: FormatCSV},
+		s,
+		&mockSource{latest: 100, blockTimes: map[uint64]time.Time{100: blockTime}},
+	)
+	require.NoError(t, err)
+	require.NoError(t, a.update(context.Background()))
+
+	// only the blocks after the latest one are scanned, so its day is partial
+	assert.Equal(t, uint64(100), a.state.Height)
+	assert.True(t, a.state.Days["2022-10-01"].Partial)
+}
+
+func TestWriteCSV(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, writeFile(dir, FormatCSV, DailySummary{
+		Date:    "2022-10-01",
+		Partial: true,
+		Tokens: []TokenSummary{{
+			TokenContract:    "0xaaaa",
+			Deposits:         2,
+			DepositVolume:    "100",
+			Withdrawals:      1,
+			WithdrawalVolume: "40",
+			UniqueAddresses:  3,
+		}},
+	}))
+
+	bz, err := os.ReadFile(filepath.Join(dir, SummaryFileName("2022-10-01", FormatCSV)))
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		"date,partial,token_contract,deposits,deposit_volume,withdrawals,withdrawal_volume,unique_addresses\n"+
+			"2022-10-01,true,0xaaaa,2,100,1,40,3\n",
+		string(bz),
+	)
+}
+
+func TestWebhook(t *testing.T) {
+	var received []DailySummary
+	status := http.StatusInternalServerError
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var summary DailySummary
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&summary))
+		received = append(received, summary)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	s, err := store.New(t.TempDir())
+	require.NoError(t, err)
+
+	a, err := New(
+		zerolog.Nop(),
+		Config{Interval: time.Minute, WebhookURL: server.URL, Labels: map[string]string{"env": "test"}},
+		s,
+		&mockSource{},
+	)
+	require.NoError(t, err)
+
+	a.state.BlockTime = time.Date(2022, 10, 2, 0, 0, 0, 0, time.UTC)
+	a.day("2022-10-01")
+
+	// a failed summary is kept to be retried
+	a.flush(context.Background())
+	assert.Len(t, a.state.Days, 1)
+
+	status = http.StatusOK
+	a.flush(context.Background())
+	assert.Empty(t, a.state.Days)
+
+	require.Len(t, received, 2)
+	assert.Equal(t, "2022-10-01", received[1].Date)
+	assert.EqualValues(t, map[string]string{"env": "test"}, received[1].Labels)
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	s, err := store.New(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = New(zerolog.Nop(), Config{Interval: time.Minute}, s, &mockSource{})
+	assert.Error(t, err)
+
+	_, err = New(zerolog.Nop(), Config{Interval: time.Minute, Dir: "out", Format: "xml"}, s, &mockSource{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,163 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	payloadv1 "github.com/umee-network/peggo/orchestrator/payload/v1"
+)
+
+// Output formats of the summary files.
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+const (
+	dirPerm  = 0o755
+	filePerm = 0o644
+)
+
+// csvHeader is the header of the CSV summary files, which hold a row per token.
+var csvHeader = []string{
+	"date",
+	"partial",
+	"token_contract",
+	"deposits",
+	"deposit_volume",
+	"withdrawals",
+	"withdrawal_volume",
+	"unique_addresses",
+}
+
+// write writes the summary to the output directory and the webhook. Writing a
+// summary again overwrites the file, so it can be retried.
+func (a *Aggregator) write(ctx context.Context, summary DailySummary) error {
+	if a.config.Dir != "" {
+		if err := writeFile(a.config.Dir, a.config.Format, summary); err != nil {
+			return err
+		}
+	}
+
+	if a.config.WebhookURL != "" {
+		if err := a.callWebhook(ctx, summary); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SummaryFileName returns the name of the file holding the summary of a date.
+func SummaryFileName(date, format string) string {
+	return fmt.Sprintf("bridge-usage-%s.%s", date, format)
+}
+
+// writeFile writes the summary to its file in dir. The file is written
+// atomically, so readers never observe a partially written summary.
+func writeFile(dir, format string, summary DailySummary) error {
+	var (
+		bz  []byte
+		err error
+	)
+
+	switch format {
+	case FormatCSV:
+		bz, err = encodeCSV(summary)
+	default:
+		bz, err = json.MarshalIndent(summary, "", "  ")
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to encode bridge usage summary")
+	}
+
+	if err := os.MkdirAll(dir, dirPerm); err != nil {
+		return errors.Wrap(err, "failed to create analytics directory")
+	}
+
+	path := filepath.Join(dir, SummaryFileName(summary.Date, format))
+
+	tmp, err := os.CreateTemp(dir, ".bridge-usage-*")
+	if err != nil {
+		return errors.Wrap(err, "failed to create bridge usage summary file")
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(bz); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "failed to write %s", path)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return errors.Wrapf(err, "failed to write %s", path)
+	}
+
+	if err := os.Chmod(tmp.Name(), filePerm); err != nil {
+		return errors.Wrapf(err, "failed to write %s", path)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func encodeCSV(summary DailySummary) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := csv.NewWriter(&buf)
+	if err := w.Write(csvHeader); err != nil {
+		return nil, err
+	}
+
+	for _, t := range summary.Tokens {
+		if err := w.Write([]string{
+			summary.Date,
+			strconv.FormatBool(summary.Partial),
+			t.TokenContract,
+			strconv.Itoa(t.Deposits),
+			t.DepositVolume,
+			strconv.Itoa(t.Withdrawals),
+			t.WithdrawalVolume,
+			strconv.Itoa(t.UniqueAddresses),
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+
+	return buf.Bytes(), w.Error()
+}
+
+func (a *Aggregator) callWebhook(ctx context.Context, summary DailySummary) error {
+	body, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.config.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(payloadv1.HeaderSchema, payloadv1.SchemaDailySummary)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to call analytics webhook")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("analytics webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,198 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/gravity"
+	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
+)
+
+type (
+	// EthClient defines the Ethereum RPC methods used to date and decode the
+	// bridge transfers.
+	EthClient interface {
+		HeaderByNumber(ctx context.Context, number *big.Int) (*ethtypes.Header, error)
+		TransactionByHash(ctx context.Context, hash ethcmn.Hash) (*ethtypes.Transaction, bool, error)
+	}
+
+	// GravityFilterer defines the Gravity contract event filters used to find
+	// the bridge transfers.
+	GravityFilterer interface {
+		FilterSendToCosmosEvent(
+			opts *bind.FilterOpts,
+			tokenContract []ethcmn.Address,
+			sender []ethcmn.Address,
+		) (*wrappers.GravitySendToCosmosEventIterator, error)
+		FilterTransactionBatchExecutedEvent(
+			opts *bind.FilterOpts,
+			batchNonce []*big.Int,
+			token []ethcmn.Address,
+		) (*wrappers.GravityTransactionBatchExecutedEventIterator, error)
+	}
+
+	ethereumSource struct {
+		logger      zerolog.Logger
+		ethClient   EthClient
+		filterer    GravityFilterer
+		submitBatch abi.Method
+	}
+)
+
+// NewEthereumSource returns a Source of the deposits (SendToCosmos events) and
+// withdrawals (the transfers of the executed batches) of the Gravity contract,
+// whose submitBatch calls are decoded with gravityABI.
+func NewEthereumSource(
+	logger zerolog.Logger,
+	ethClient EthClient,
+	filterer GravityFilterer,
+	gravityABI abi.ABI,
+) Source {
+	return &ethereumSource{
+		logger:      logger.With().Str("module", "analytics").Logger(),
+		ethClient:   ethClient,
+		filterer:    filterer,
+		submitBatch: gravityABI.Methods["submitBatch"],
+	}
+}
+
+func (s *ethereumSource) LatestHeight(ctx context.Context) (uint64, error) {
+	header, err := s.ethClient.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	return header.Number.Uint64(), nil
+}
+
+func (s *ethereumSource) BlockTime(ctx context.Context, height uint64) (time.Time, error) {
+	header, err := s.ethClient.HeaderByNumber(ctx, new(big.Int).SetUint64(height))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(int64(header.Time), 0).UTC(), nil
+}
+
+func (s *ethereumSource) Transfers(ctx context.Context, from, to uint64) ([]Transfer, error) {
+	blockTimes := map[uint64]time.Time{}
+	blockTime := func(height uint64) (time.Time, error) {
+		if t, ok := blockTimes[height]; ok {
+			return t, nil
+		}
+
+		header, err := s.ethClient.HeaderByNumber(ctx, new(big.Int).SetUint64(height))
+		if err != nil {
+			return time.Time{}, errors.Wrapf(err, "failed to get header %d", height)
+		}
+
+		t := time.Unix(int64(header.Time), 0).UTC()
+		blockTimes[height] = t
+
+		return t, nil
+	}
+
+	opts := &bind.FilterOpts{Start: from, End: &to, Context: ctx}
+
+	var transfers []Transfer
+
+	depositIter, err := s.filterer.FilterSendToCosmosEvent(opts, nil, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to filter SendToCosmos events")
+	}
+	defer depositIter.Close()
+
+	for depositIter.Next() {
+		ev := depositIter.Event
+
+		t, err := blockTime(ev.Raw.BlockNumber)
+		if err != nil {
+			return nil, err
+		}
+
+		transfers = append(transfers, Transfer{
+			Kind:          KindDeposit,
+			TokenContract: ev.TokenContract.Hex(),
+			Addresses:     []string{ev.Sender.Hex(), ev.Destination},
+			Amount:        ev.Amount,
+			Time:          t,
+		})
+	}
+
+	if err := depositIter.Error(); err != nil {
+		return nil, errors.Wrap(err, "failed to iterate SendToCosmos events")
+	}
+
+	batchIter, err := s.filterer.FilterTransactionBatchExecutedEvent(opts, nil, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to filter TransactionBatchExecuted events")
+	}
+	defer batchIter.Close()
+
+	for batchIter.Next() {
+		ev := batchIter.Event
+
+		withdrawals, err := s.batchTransfers(ctx, ev.Raw.TxHash)
+		if err != nil {
+			return nil, err
+		}
+
+		t, err := blockTime(ev.Raw.BlockNumber)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range withdrawals {
+			withdrawals[i].Time = t
+		}
+
+		transfers = append(transfers, withdrawals...)
+	}
+
+	if err := batchIter.Error(); err != nil {
+		return nil, errors.Wrap(err, "failed to iterate TransactionBatchExecuted events")
+	}
+
+	return transfers, nil
+}
+
+// batchTransfers returns the withdrawals of the batch executed by a tx, decoded
+// from its submitBatch call.
+func (s *ethereumSource) batchTransfers(ctx context.Context, txHash ethcmn.Hash) ([]Transfer, error) {
+	tx, _, err := s.ethClient.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get tx %s", txHash)
+	}
+
+	data := tx.Data()
+	if len(data) < 4 || !bytes.Equal(data[:4], s.submitBatch.ID) {
+		s.logger.Warn().Str("tx_hash", txHash.Hex()).Msg("batch not executed through submitBatch; skipping")
+		return nil, nil
+	}
+
+	batch, err := gravity.DecodeSubmitBatch(s.submitBatch, data[4:])
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode tx %s", txHash)
+	}
+
+	transfers := make([]Transfer, 0, len(batch.Transactions))
+	for _, tx := range batch.Transactions {
+		transfers = append(transfers, Transfer{
+			Kind:          KindWithdrawal,
+			TokenContract: batch.TokenContract,
+			Addresses:     []string{tx.DestAddress},
+			Amount:        tx.Erc20Token.Amount.BigInt(),
+		})
+	}
+
+	return transfers, nil
+}
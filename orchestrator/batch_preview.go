@@ -0,0 +1,140 @@
+package orchestrator
+
+import (
+	"context"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/shopspring/decimal"
+)
+
+// BatchPreview is the composition of the batch a MsgRequestBatch would create
+// for a token. The chain only exposes the count and fees of the transfers that
+// would be batched, not their amounts.
+type BatchPreview struct {
+	TokenContract string
+	Denom         string
+	TxCount       uint64
+	TotalFees     string
+	// TotalFeesUSD is only set when the fee token could be priced.
+	TotalFeesUSD *decimal.Decimal
+}
+
+// MarshalZerologObject implements zerolog.LogObjectMarshaler.
+func (b BatchPreview) MarshalZerologObject(e *zerolog.Event) {
+	e.Str("token_contract", b.TokenContract).
+		Str("denom", b.Denom).
+		Uint64("tx_count", b.TxCount).
+		Str("total_fees", b.TotalFees)
+
+	if b.TotalFeesUSD != nil {
+		e.Str("total_fees_usd", b.TotalFeesUSD.StringFixed(2))
+	}
+}
+
+// SetBatchDustThreshold returns the orchestrator option skipping the requests
+// of single transfer batches whose fees are worth less than usd.
+func SetBatchDustThreshold(usd float64) func(GravityOrchestrator) {
+	return func(o GravityOrchestrator) { o.SetBatchDustThreshold(usd) }
+}
+
+// SetBatchDustThreshold sets the USD fee value under which a batch of a single
+// transfer is considered dust and not requested. Zero disables it.
+func (p *gravityOrchestrator) SetBatchDustThreshold(usd float64) {
+	p.batchDustThresholdUSD = decimal.NewFromFloat(usd)
+}
+
+// previewBatch returns the composition of the batch that would be created for
+// the given unbatched transfers. The fees are priced with the given symbols and
+// decimals or, for a single transfer that may be dust, looked up on demand.
+func (p *gravityOrchestrator) previewBatch(
+	ctx context.Context,
+	denom string,
+	fees gravitytypes.BatchFees,
+	symbols map[string]string,
+	decimals map[string]uint8,
+) BatchPreview {
+	preview := BatchPreview{
+		TokenContract: fees.Token,
+		Denom:         denom,
+		TxCount:       fees.TxCount,
+		TotalFees:     fees.TotalFees.String(),
+	}
+
+	symbol, okSymbol := symbols[fees.Token]
+	tokenDecimals, okDecimals := decimals[fees.Token]
+
+	if !okSymbol || !okDecimals {
+		if !p.needsDustCheck(fees) {
+			return preview
+		}
+
+		var err error
+		if symbol, tokenDecimals, err = p.tokenSymbolAndDecimals(ctx, fees.Token); err != nil {
+			p.logger.Debug().Err(err).Str("token_contract", fees.Token).Msg("failed to price batch fees")
+			return preview
+		}
+	}
+
+	feesUSD, err := p.usdValue(fees.TotalFees, tokenDecimals, symbol)
+	if err != nil {
+		// Subscribe, so the price is there on the next loop.
+		if err := p.oracle.SubscribeSymbols(symbol); err != nil {
+			p.logger.Debug().Err(err).Str("symbol", symbol).Msg("failed to subscribe to oracle symbol")
+		}
+
+		p.logger.Debug().Err(err).Str("token_contract", fees.Token).Msg("failed to price batch fees")
+		return preview
+	}
+	preview.TotalFeesUSD = &feesUSD
+
+	return preview
+}
+
+// needsDustCheck reports whether the batch could be dust, i.e. a single
+// transfer while the dust threshold is enabled.
+func (p *gravityOrchestrator) needsDustCheck(fees gravitytypes.BatchFees) bool {
+	return fees.TxCount == 1 && p.batchDustThresholdUSD.IsPositive()
+}
+
+// isDust reports whether the batch would be a single transfer whose fee is
+// worth less than the dust threshold. Batches whose fees can't be priced are
+// never considered dust.
+func (p *gravityOrchestrator) isDust(preview BatchPreview) bool {
+	return preview.TxCount == 1 &&
+		p.batchDustThresholdUSD.IsPositive() &&
+		preview.TotalFeesUSD != nil &&
+		preview.TotalFeesUSD.LessThan(p.batchDustThresholdUSD)
+}
+
+func (p *gravityOrchestrator) tokenSymbolAndDecimals(ctx context.Context, token string) (string, uint8, error) {
+	symbol, err := p.symbolRetriever.GetTokenSymbol(ethcmn.HexToAddress(token))
+	if err != nil {
+		return "", 0, errors.Wrap(err, "failed to get token symbol")
+	}
+
+	decimals, err := p.gravityContract.GetERC20Decimals(
+		ctx,
+		ethcmn.HexToAddress(token),
+		p.gravityContract.FromAddress(),
+	)
+	if err != nil {
+		return "", 0, errors.Wrap(err, "failed to get token decimals")
+	}
+
+	return symbol, decimals, nil
+}
+
+// usdValue returns the USD value of an amount of a token, expressed in its
+// smallest unit, at the oracle price.
+func (p *gravityOrchestrator) usdValue(amount sdk.Int, decimals uint8, symbol string) (decimal.Decimal, error) {
+	value, err := p.oracle.ConvertValue(amount, decimals, symbol)
+	if err != nil {
+		return decimal.Decimal{}, errors.Wrapf(err, "failed to get %s value", symbol)
+	}
+
+	return decimal.NewFromString(value.String())
+}
@@ -0,0 +1,59 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewBatch(t *testing.T) {
+	token := "0x0000000000000000000000000000000000000001"
+	symbols := map[string]string{token: "USDC"}
+	decimals := map[string]uint8{token: 6}
+
+	orch := gravityOrchestrator{
+		logger: zerolog.Nop(),
+		oracle: &warmingOracle{prices: map[string]sdk.Dec{"USDC": sdk.MustNewDecFromStr("2")}},
+	}
+	orch.SetBatchDustThreshold(1)
+
+	// a single transfer paying 0.25 tokens (0.5 USD) of fees
+	preview := orch.previewBatch(
+		context.Background(),
+		"gravity0x01",
+		types.BatchFees{Token: token, TotalFees: sdk.NewInt(250_000), TxCount: 1},
+		symbols,
+		decimals,
+	)
+	require.NotNil(t, preview.TotalFeesUSD)
+	assert.Equal(t, "0.5", preview.TotalFeesUSD.String())
+	assert.Equal(t, "250000", preview.TotalFees)
+	assert.True(t, orch.isDust(preview))
+
+	// the same fees paid by two transfers
+	preview = orch.previewBatch(
+		context.Background(),
+		"gravity0x01",
+		types.BatchFees{Token: token, TotalFees: sdk.NewInt(250_000), TxCount: 2},
+		symbols,
+		decimals,
+	)
+	assert.False(t, orch.isDust(preview))
+
+	// fees that can't be priced are never dust
+	orch.SetBatchDustThreshold(0)
+	preview = orch.previewBatch(
+		context.Background(),
+		"gravity0x02",
+		types.BatchFees{Token: "0x0000000000000000000000000000000000000002", TotalFees: sdk.NewInt(1), TxCount: 1},
+		symbols,
+		decimals,
+	)
+	assert.Nil(t, preview.TotalFeesUSD)
+	assert.False(t, orch.isDust(preview))
+}
@@ -0,0 +1,68 @@
+package orchestrator
+
+import (
+	"time"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+)
+
+// SetBatchTiming returns the orchestrator option holding batch requests until
+// maxSize transfers are unbatched or maxWait has elapsed since the batch could
+// first be requested.
+func SetBatchTiming(maxSize uint64, maxWait time.Duration) func(GravityOrchestrator) {
+	return func(o GravityOrchestrator) { o.SetBatchTiming(maxSize, maxWait) }
+}
+
+// SetBatchTiming delays the request of a batch, once it's profitable, until
+// maxSize transfers of its token are unbatched or maxWait has elapsed, so the
+// relaying gas is amortized over more transfers. A maxSize of zero disables it.
+func (p *gravityOrchestrator) SetBatchTiming(maxSize uint64, maxWait time.Duration) {
+	p.batchTargetSize = maxSize
+	p.batchMaxWait = maxWait
+	p.batchWaitingSince = make(map[string]time.Time)
+}
+
+// waitForFullerBatch reports whether the batch request of the given unbatched
+// transfers should wait for more of them, and how long it has waited so far.
+// The wait of a token starts the first time it's asked about, and lasts until
+// the batch is requested (see batchRequested).
+func (p *gravityOrchestrator) waitForFullerBatch(fees gravitytypes.BatchFees) (bool, time.Duration) {
+	if p.batchTargetSize == 0 || fees.TxCount >= p.batchTargetSize {
+		return false, 0
+	}
+
+	now := p.now()
+
+	since, ok := p.batchWaitingSince[fees.Token]
+	if !ok {
+		since = now
+		p.batchWaitingSince[fees.Token] = since
+	}
+
+	waited := now.Sub(since)
+	return waited < p.batchMaxWait, waited
+}
+
+// batchRequested resets the wait of the token whose batch was requested.
+func (p *gravityOrchestrator) batchRequested(token string) {
+	delete(p.batchWaitingSince, token)
+}
+
+// pruneBatchWaits resets the wait of the tokens without unbatched transfers
+// anymore, e.g. because another validator requested their batch.
+func (p *gravityOrchestrator) pruneBatchWaits(unbatched []gravitytypes.BatchFees) {
+	if len(p.batchWaitingSince) == 0 {
+		return
+	}
+
+	tokens := make(map[string]struct{}, len(unbatched))
+	for _, fees := range unbatched {
+		tokens[fees.Token] = struct{}{}
+	}
+
+	for token := range p.batchWaitingSince {
+		if _, ok := tokens[token]; !ok {
+			delete(p.batchWaitingSince, token)
+		}
+	}
+}
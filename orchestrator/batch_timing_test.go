@@ -0,0 +1,57 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitForFullerBatch(t *testing.T) {
+	token := "0x0000000000000000000000000000000000000001"
+	now := time.Unix(1_700_000_000, 0)
+
+	orch := gravityOrchestrator{
+		logger: zerolog.Nop(),
+		now:    func() time.Time { return now },
+	}
+
+	// disabled by default
+	wait, _ := orch.waitForFullerBatch(types.BatchFees{Token: token, TxCount: 1})
+	assert.False(t, wait)
+
+	orch.SetBatchTiming(10, 30*time.Minute)
+
+	wait, waited := orch.waitForFullerBatch(types.BatchFees{Token: token, TxCount: 3})
+	assert.True(t, wait)
+	assert.Zero(t, waited)
+
+	// the target size is reached
+	wait, _ = orch.waitForFullerBatch(types.BatchFees{Token: token, TxCount: 10})
+	assert.False(t, wait)
+
+	now = now.Add(20 * time.Minute)
+	wait, waited = orch.waitForFullerBatch(types.BatchFees{Token: token, TxCount: 4})
+	assert.True(t, wait)
+	assert.Equal(t, 20*time.Minute, waited)
+
+	// the max wait elapsed
+	now = now.Add(10 * time.Minute)
+	wait, _ = orch.waitForFullerBatch(types.BatchFees{Token: token, TxCount: 4})
+	assert.False(t, wait)
+
+	// the wait restarts once the batch is requested
+	orch.batchRequested(token)
+	wait, waited = orch.waitForFullerBatch(types.BatchFees{Token: token, TxCount: 1})
+	assert.True(t, wait)
+	assert.Zero(t, waited)
+
+	// or once another validator requested it
+	now = now.Add(time.Hour)
+	orch.pruneBatchWaits(nil)
+	wait, waited = orch.waitForFullerBatch(types.BatchFees{Token: token, TxCount: 1})
+	assert.True(t, wait)
+	assert.Zero(t, waited)
+}
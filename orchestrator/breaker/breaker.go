@@ -0,0 +1,189 @@
+package breaker
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ErrOpen is returned instead of calling an endpoint whose breaker is open.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the state of a circuit breaker.
+type State int
+
+// Circuit breaker states
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+// String gets the string representation of the state.
+func (s State) String() string {
+	return [...]string{"closed", "open", "half-open"}[s]
+}
+
+// Breaker is a circuit breaker around a single external endpoint, meant to be
+// shared by every loop using that endpoint. After maxFailures consecutive
+// failures it opens and calls fail fast with ErrOpen. Once the backoff elapses
+// a single probe call is let through (half-open): if it succeeds the breaker
+// closes, otherwise it opens again with twice the backoff, up to maxBackoff.
+type Breaker struct {
+	logger      zerolog.Logger
+	maxFailures int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	now         func() time.Time
+
+	mtx      sync.Mutex
+	state    State
+	failures int
+	backoff  time.Duration
+	openedAt time.Time
+	probing  bool
+
+	onStateChange func(State)
+}
+
+// New returns a closed circuit breaker for the named endpoint. A maxFailures of
+// zero disables the breaker.
+func New(logger zerolog.Logger, name string, maxFailures int, minBackoff, maxBackoff time.Duration) *Breaker {
+	if maxBackoff < minBackoff {
+		maxBackoff = minBackoff
+	}
+
+	return &Breaker{
+		logger:      logger.With().Str("module", "breaker").Str("endpoint", name).Logger(),
+		maxFailures: maxFailures,
+		minBackoff:  minBackoff,
+		maxBackoff:  maxBackoff,
+		now:         time.Now,
+		backoff:     minBackoff,
+	}
+}
+
+// State returns the current state of the breaker.
+func (b *Breaker) State() State {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	return b.state
+}
+
+// OnStateChange sets a function called with the new state on every transition,
+// e.g. to export it. It is called with the breaker locked, so it must not call
+// the breaker.
+func (b *Breaker) OnStateChange(fn func(State)) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.onStateChange = fn
+}
+
+// Do calls fn unless the breaker is open. isFailure decides which errors count
+// as the endpoint failing, as opposed to the endpoint answering with an error.
+func (b *Breaker) Do(fn func() error, isFailure func(error) bool) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+
+	err := fn()
+	b.record(err != nil && isFailure(err))
+
+	return err
+}
+
+func (b *Breaker) allow() error {
+	if b == nil || b.maxFailures <= 0 {
+		return nil
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.backoff {
+			return fmt.Errorf("%w; retrying in %s", ErrOpen, b.backoff-b.now().Sub(b.openedAt))
+		}
+
+		b.setState(StateHalfOpen)
+		b.probing = true
+		b.logger.Info().Msg("circuit breaker half-open; probing endpoint")
+		return nil
+
+	case StateHalfOpen:
+		// Only one probe at a time.
+		if b.probing {
+			return ErrOpen
+		}
+
+		b.probing = true
+		return nil
+
+	default:
+		return nil
+	}
+}
+
+func (b *Breaker) record(failed bool) {
+	if b == nil || b.maxFailures <= 0 {
+		return
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if !failed {
+		if b.state != StateClosed {
+			b.logger.Info().Msg("circuit breaker closed; endpoint recovered")
+		}
+
+		b.setState(StateClosed)
+		b.failures = 0
+		b.backoff = b.minBackoff
+		b.probing = false
+		return
+	}
+
+	switch b.state {
+	case StateHalfOpen:
+		b.backoff *= 2
+		if b.backoff > b.maxBackoff {
+			b.backoff = b.maxBackoff
+		}
+		b.open()
+
+	case StateClosed:
+		b.failures++
+		if b.failures >= b.maxFailures {
+			b.open()
+		}
+	}
+}
+
+func (b *Breaker) open() {
+	b.setState(StateOpen)
+	b.openedAt = b.now()
+	b.probing = false
+
+	b.logger.Warn().
+		Int("failures", b.failures).
+		Dur("backoff", b.backoff).
+		Msg("circuit breaker open; endpoint sidelined")
+}
+
+func (b *Breaker) setState(state State) {
+	if state == b.state {
+		return
+	}
+
+	b.state = state
+	if b.onStateChange != nil {
+		b.onStateChange(state)
+	}
+}
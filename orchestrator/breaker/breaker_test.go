@@ -0,0 +1,87 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+	b := New(zerolog.Nop(), "test", 2, time.Second, 3*time.Second)
+	b.now = func() time.Time { return now }
+
+	errDown := errors.New("connection refused")
+	errApp := errors.New("execution reverted")
+	isFailure := func(err error) bool { return err == errDown }
+
+	fail := func() error { return errDown }
+	ok := func() error { return nil }
+
+	// application errors don't count as failures
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, errApp, b.Do(func() error { return errApp }, isFailure))
+	}
+	assert.Equal(t, StateClosed, b.State())
+
+	assert.Equal(t, errDown, b.Do(fail, isFailure))
+	assert.Equal(t, errDown, b.Do(fail, isFailure))
+	assert.Equal(t, StateOpen, b.State())
+
+	// calls fail fast while open
+	called := false
+	err := b.Do(func() error { called = true; return nil }, isFailure)
+	assert.ErrorIs(t, err, ErrOpen)
+	assert.False(t, called)
+
+	// a failed probe doubles the backoff
+	now = now.Add(time.Second)
+	assert.Equal(t, errDown, b.Do(fail, isFailure))
+	assert.Equal(t, StateOpen, b.State())
+
+	now = now.Add(time.Second)
+	assert.ErrorIs(t, b.Do(ok, isFailure), ErrOpen)
+
+	// the backoff is capped
+	now = now.Add(time.Second)
+	assert.Equal(t, errDown, b.Do(fail, isFailure))
+	now = now.Add(3 * time.Second)
+
+	// a successful probe closes the breaker
+	require.NoError(t, b.Do(ok, isFailure))
+	assert.Equal(t, StateClosed, b.State())
+}
+
+func TestBreakerDisabled(t *testing.T) {
+	b := New(zerolog.Nop(), "test", 0, time.Second, time.Second)
+
+	for i := 0; i < 10; i++ {
+		_ = b.Do(func() error { return errors.New("down") }, func(error) bool { return true })
+	}
+	assert.Equal(t, StateClosed, b.State())
+}
+
+func TestBreakerOnStateChange(t *testing.T) {
+	now := time.Now()
+	b := New(zerolog.Nop(), "test", 1, time.Second, time.Second)
+	b.now = func() time.Time { return now }
+
+	var states []State
+	b.OnStateChange(func(s State) { states = append(states, s) })
+
+	always := func(error) bool { return true }
+	fail := func() error { return errors.New("down") }
+
+	_ = b.Do(fail, always)
+	_ = b.Do(fail, always) // fails fast, no transition
+
+	now = now.Add(time.Second)
+	require.NoError(t, b.Do(func() error { return nil }, always))
+	require.NoError(t, b.Do(func() error { return nil }, always))
+
+	assert.Equal(t, []State{StateOpen, StateHalfOpen, StateClosed}, states)
+}
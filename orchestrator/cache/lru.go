@@ -0,0 +1,186 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type (
+	// LRU is a cache of a fixed number of entries, which evicts the least
+	// recently used entry once full. It is safe for concurrent use.
+	LRU[K comparable, V any] struct {
+		mtx     sync.Mutex
+		size    int
+		order   *list.List // front is the most recently used
+		entries map[K]*list.Element
+		metrics lruMetrics
+	}
+
+	// Metrics counts the hits, misses and evictions of the caches using them,
+	// labeled by cache name.
+	Metrics struct {
+		hits      *prometheus.CounterVec
+		misses    *prometheus.CounterVec
+		evictions *prometheus.CounterVec
+		entries   *prometheus.GaugeVec
+	}
+
+	// Option configures optional LRU settings.
+	Option func(*lruOptions)
+
+	lruOptions struct {
+		metrics *Metrics
+		name    string
+	}
+
+	lruMetrics struct {
+		hits      prometheus.Counter
+		misses    prometheus.Counter
+		evictions prometheus.Counter
+		entries   prometheus.Gauge
+	}
+
+	lruEntry[K comparable, V any] struct {
+		key   K
+		value V
+	}
+)
+
+// NewMetrics returns the cache metrics registered with registerer.
+func NewMetrics(registerer prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		hits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "peggo",
+			Subsystem: "cache",
+			Name:      "hits_total",
+			Help:      "Number of lookups found in the cache.",
+		}, []string{"cache"}),
+		misses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "peggo",
+			Subsystem: "cache",
+			Name:      "misses_total",
+			Help:      "Number of lookups not found in the cache.",
+		}, []string{"cache"}),
+		evictions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "peggo",
+			Subsystem: "cache",
+			Name:      "evictions_total",
+			Help:      "Number of entries evicted from the cache to stay within its size.",
+		}, []string{"cache"}),
+		entries: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "peggo",
+			Subsystem: "cache",
+			Name:      "entries",
+			Help:      "Number of entries in the cache.",
+		}, []string{"cache"}),
+	}
+
+	for _, c := range []prometheus.Collector{m.hits, m.misses, m.evictions, m.entries} {
+		if err := registerer.Register(c); err != nil {
+			return nil, errors.Wrap(err, "failed to register metric")
+		}
+	}
+
+	return m, nil
+}
+
+// OptionMetrics exports the cache hits, misses and evictions with the given
+// metrics, under the given cache name. A nil m disables them.
+func OptionMetrics(m *Metrics, name string) Option {
+	return func(o *lruOptions) {
+		o.metrics = m
+		o.name = name
+	}
+}
+
+// New returns an LRU cache of up to size entries (at least one).
+func New[K comparable, V any](size int, options ...Option) *LRU[K, V] {
+	if size < 1 {
+		size = 1
+	}
+
+	var opts lruOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
+	c := &LRU[K, V]{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[K]*list.Element, size),
+	}
+
+	if opts.metrics != nil {
+		c.metrics = lruMetrics{
+			hits:      opts.metrics.hits.WithLabelValues(opts.name),
+			misses:    opts.metrics.misses.WithLabelValues(opts.name),
+			evictions: opts.metrics.evictions.WithLabelValues(opts.name),
+			entries:   opts.metrics.entries.WithLabelValues(opts.name),
+		}
+	}
+
+	return c
+}
+
+// Get returns the value cached for key, if any, and marks it as the most
+// recently used.
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.metrics.inc(c.metrics.misses)
+
+		var zero V
+		return zero, false
+	}
+
+	c.metrics.inc(c.metrics.hits)
+	c.order.MoveToFront(elem)
+
+	return elem.Value.(*lruEntry[K, V]).value, true
+}
+
+// Add caches value for key, evicting the least recently used entry if the
+// cache is full.
+func (c *LRU[K, V]) Add(key K, value V) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*lruEntry[K, V]).value = value
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value})
+
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry[K, V]).key)
+		c.metrics.inc(c.metrics.evictions)
+	}
+
+	if c.metrics.entries != nil {
+		c.metrics.entries.Set(float64(c.order.Len()))
+	}
+}
+
+// Len returns the number of cached entries.
+func (c *LRU[K, V]) Len() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	return c.order.Len()
+}
+
+func (m lruMetrics) inc(counter prometheus.Counter) {
+	if counter != nil {
+		counter.Inc()
+	}
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU(t *testing.T) {
+	c := New[string, int](2)
+
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	// "b" is now the least recently used entry
+	c.Add("c", 3)
+	assert.Equal(t, 2, c.Len())
+
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+
+	v, ok = c.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+
+	// updating an entry doesn't evict anything
+	c.Add("a", 10)
+	assert.Equal(t, 2, c.Len())
+
+	v, ok = c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 10, v)
+}
+
+func TestLRUMetrics(t *testing.T) {
+	m, err := NewMetrics(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	c := New[int, int](1, OptionMetrics(m, "test"))
+
+	c.Add(1, 1)
+	c.Add(2, 2)
+	c.Get(1)
+	c.Get(2)
+	c.Get(2)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.hits.WithLabelValues("test")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.misses.WithLabelValues("test")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.evictions.WithLabelValues("test")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.entries.WithLabelValues("test")))
+}
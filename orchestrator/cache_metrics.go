@@ -0,0 +1,15 @@
+package orchestrator
+
+import "github.com/umee-network/peggo/orchestrator/cache"
+
+// SetCacheMetrics returns the orchestrator option exporting the cache hits and
+// misses to m.
+func SetCacheMetrics(m *cache.Metrics) func(GravityOrchestrator) {
+	return func(o GravityOrchestrator) { o.SetCacheMetrics(m) }
+}
+
+// SetCacheMetrics exports the hits and misses of the orchestrator caches. It
+// must be set before the caches are first used.
+func (p *gravityOrchestrator) SetCacheMetrics(m *cache.Metrics) {
+	p.cacheMetrics = m
+}
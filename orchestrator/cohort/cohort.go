@@ -0,0 +1,336 @@
+package cohort
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/gravity"
+	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
+)
+
+// Relayed tx types.
+const (
+	RelayBatch  = "batch"
+	RelayValset = "valset"
+)
+
+type (
+	// EthClient defines the Ethereum RPC methods used to find who relayed the
+	// Gravity txs and when.
+	EthClient interface {
+		HeaderByNumber(ctx context.Context, number *big.Int) (*ethtypes.Header, error)
+		TransactionByHash(ctx context.Context, hash ethcmn.Hash) (*ethtypes.Transaction, bool, error)
+	}
+
+	// GravityFilterer defines the Gravity contract event filters used to find
+	// the relayed batches and valset updates.
+	GravityFilterer interface {
+		FilterTransactionBatchExecutedEvent(
+			opts *bind.FilterOpts,
+			batchNonce []*big.Int,
+			token []ethcmn.Address,
+		) (*wrappers.GravityTransactionBatchExecutedEventIterator, error)
+		FilterValsetUpdatedEvent(
+			opts *bind.FilterOpts,
+			newValsetNonce []*big.Int,
+		) (*wrappers.GravityValsetUpdatedEventIterator, error)
+	}
+
+	// Relay is a batch or valset update executed on the Gravity contract.
+	Relay struct {
+		Type    string
+		Nonce   uint64
+		Relayer ethcmn.Address
+		Height  uint64
+		Time    time.Time
+		// Response is the time from the batch creation to its execution, zero
+		// if unknown (e.g. for valset updates).
+		Response time.Duration
+	}
+
+	// Scanner finds the relays of a range of Ethereum blocks.
+	Scanner struct {
+		logger      zerolog.Logger
+		ethClient   EthClient
+		filterer    GravityFilterer
+		submitBatch abi.Method
+		// timeoutBlocks is the number of blocks the Gravity module adds to the
+		// Ethereum height when setting the timeout of a new batch.
+		timeoutBlocks uint64
+	}
+)
+
+// NewScanner returns a scanner of the relays of the Gravity contract, whose
+// submitBatch calls are decoded with gravityABI. The Gravity module sets the
+// timeout of a batch timeoutBlocks after the Ethereum height it was created
+// at, i.e. its TargetBatchTimeout divided by its AverageEthereumBlockTime, so
+// the batch creation height is estimated from its timeout.
+func NewScanner(
+	logger zerolog.Logger,
+	ethClient EthClient,
+	filterer GravityFilterer,
+	gravityABI abi.ABI,
+	timeoutBlocks uint64,
+) *Scanner {
+	return &Scanner{
+		logger:        logger.With().Str("module", "cohort").Logger(),
+		ethClient:     ethClient,
+		filterer:      filterer,
+		submitBatch:   gravityABI.Methods["submitBatch"],
+		timeoutBlocks: timeoutBlocks,
+	}
+}
+
+// LatestHeight returns the latest Ethereum height.
+func (s *Scanner) LatestHeight(ctx context.Context) (uint64, error) {
+	header, err := s.ethClient.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	return header.Number.Uint64(), nil
+}
+
+// Relays returns the batches and valset updates executed between the given
+// heights, included, oldest first.
+func (s *Scanner) Relays(ctx context.Context, from, to uint64) ([]Relay, error) {
+	blockTimes := map[uint64]time.Time{}
+	blockTime := func(height uint64) (time.Time, error) {
+		if t, ok := blockTimes[height]; ok {
+			return t, nil
+		}
+
+		header, err := s.ethClient.HeaderByNumber(ctx, new(big.Int).SetUint64(height))
+		if err != nil {
+			return time.Time{}, errors.Wrapf(err, "failed to get header %d", height)
+		}
+
+		t := time.Unix(int64(header.Time), 0).UTC()
+		blockTimes[height] = t
+
+		return t, nil
+	}
+
+	relay := func(relayType string, nonce *big.Int, log ethtypes.Log) (Relay, *ethtypes.Transaction, error) {
+		tx, _, err := s.ethClient.TransactionByHash(ctx, log.TxHash)
+		if err != nil {
+			return Relay{}, nil, errors.Wrapf(err, "failed to get tx %s", log.TxHash)
+		}
+
+		sender, err := ethtypes.Sender(ethtypes.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			return Relay{}, nil, errors.Wrapf(err, "failed to get the sender of tx %s", log.TxHash)
+		}
+
+		t, err := blockTime(log.BlockNumber)
+		if err != nil {
+			return Relay{}, nil, err
+		}
+
+		return Relay{
+			Type:    relayType,
+			Nonce:   nonce.Uint64(),
+			Relayer: sender,
+			Height:  log.BlockNumber,
+			Time:    t,
+		}, tx, nil
+	}
+
+	opts := &bind.FilterOpts{Start: from, End: &to, Context: ctx}
+
+	var relays []Relay
+
+	batchIter, err := s.filterer.FilterTransactionBatchExecutedEvent(opts, nil, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to filter TransactionBatchExecuted events")
+	}
+	defer batchIter.Close()
+
+	for batchIter.Next() {
+		ev := batchIter.Event
+
+		r, tx, err := relay(RelayBatch, ev.BatchNonce, ev.Raw)
+		if err != nil {
+			return nil, err
+		}
+
+		if created, ok := s.batchCreationHeight(tx); ok && created <= r.Height {
+			createdAt, err := blockTime(created)
+			if err != nil {
+				return nil, err
+			}
+
+			r.Response = r.Time.Sub(createdAt)
+		}
+
+		relays = append(relays, r)
+	}
+
+	if err := batchIter.Error(); err != nil {
+		return nil, errors.Wrap(err, "failed to iterate TransactionBatchExecuted events")
+	}
+
+	valsetIter, err := s.filterer.FilterValsetUpdatedEvent(opts, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to filter ValsetUpdated events")
+	}
+	defer valsetIter.Close()
+
+	for valsetIter.Next() {
+		ev := valsetIter.Event
+
+		// the first valset is set by the contract deployment, not relayed
+		if ev.NewValsetNonce.Sign() == 0 {
+			continue
+		}
+
+		r, _, err := relay(RelayValset, ev.NewValsetNonce, ev.Raw)
+		if err != nil {
+			return nil, err
+		}
+
+		relays = append(relays, r)
+	}
+
+	if err := valsetIter.Error(); err != nil {
+		return nil, errors.Wrap(err, "failed to iterate ValsetUpdated events")
+	}
+
+	sort.SliceStable(relays, func(i, j int) bool { return relays[i].Height < relays[j].Height })
+
+	return relays, nil
+}
+
+// batchCreationHeight estimates the Ethereum height a batch was created at
+// from the timeout of its submitBatch call.
+func (s *Scanner) batchCreationHeight(tx *ethtypes.Transaction) (uint64, bool) {
+	data := tx.Data()
+	if s.timeoutBlocks == 0 || len(data) < 4 || !bytes.Equal(data[:4], s.submitBatch.ID) {
+		return 0, false
+	}
+
+	batch, err := gravity.DecodeSubmitBatch(s.submitBatch, data[4:])
+	if err != nil {
+		s.logger.Debug().Err(err).Str("tx_hash", tx.Hash().Hex()).Msg("failed to decode batch")
+		return 0, false
+	}
+
+	if batch.BatchTimeout < s.timeoutBlocks {
+		return 0, false
+	}
+
+	return batch.BatchTimeout - s.timeoutBlocks, true
+}
+
+// RelayerStats summarizes the relays of a relayer.
+type RelayerStats struct {
+	Address        string        `json:"address"`
+	Batches        int           `json:"batches"`
+	Valsets        int           `json:"valsets"`
+	Share          float64       `json:"share"`
+	MedianResponse time.Duration `json:"median_response"`
+}
+
+// Summary summarizes the relays of a range of Ethereum blocks: how many
+// relayers are active, how often each relays, and how fast the batches are
+// relayed after their creation.
+type Summary struct {
+	FromHeight     uint64         `json:"from_height"`
+	ToHeight       uint64         `json:"to_height"`
+	Relays         int            `json:"relays"`
+	ActiveRelayers int            `json:"active_relayers"`
+	MedianResponse time.Duration  `json:"median_response"`
+	P90Response    time.Duration  `json:"p90_response"`
+	Relayers       []RelayerStats `json:"relayers"`
+}
+
+// Summarize summarizes the relays found between the given heights. The
+// relayers are sorted by number of relays, most active first.
+func Summarize(relays []Relay, from, to uint64) Summary {
+	summary := Summary{
+		FromHeight: from,
+		ToHeight:   to,
+		Relays:     len(relays),
+		Relayers:   []RelayerStats{},
+	}
+
+	var (
+		byRelayer = map[ethcmn.Address]*RelayerStats{}
+		responses = map[ethcmn.Address][]time.Duration{}
+		all       []time.Duration
+	)
+
+	for _, r := range relays {
+		stats, ok := byRelayer[r.Relayer]
+		if !ok {
+			stats = &RelayerStats{Address: r.Relayer.Hex()}
+			byRelayer[r.Relayer] = stats
+		}
+
+		switch r.Type {
+		case RelayBatch:
+			stats.Batches++
+		case RelayValset:
+			stats.Valsets++
+		}
+
+		if r.Response > 0 {
+			responses[r.Relayer] = append(responses[r.Relayer], r.Response)
+			all = append(all, r.Response)
+		}
+	}
+
+	for relayer, stats := range byRelayer {
+		stats.Share = float64(stats.Batches+stats.Valsets) / float64(len(relays))
+		stats.MedianResponse = quantile(responses[relayer], 0.5)
+		summary.Relayers = append(summary.Relayers, *stats)
+	}
+
+	sort.Slice(summary.Relayers, func(i, j int) bool {
+		a, b := summary.Relayers[i], summary.Relayers[j]
+		if a.Batches+a.Valsets != b.Batches+b.Valsets {
+			return a.Batches+a.Valsets > b.Batches+b.Valsets
+		}
+
+		return strings.ToLower(a.Address) < strings.ToLower(b.Address)
+	})
+
+	summary.ActiveRelayers = len(summary.Relayers)
+	summary.MedianResponse = quantile(all, 0.5)
+	summary.P90Response = quantile(all, 0.9)
+
+	return summary
+}
+
+// quantile returns the nearest-rank quantile of the durations, zero if there
+// are none.
+func quantile(durations []time.Duration, q float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+
+	return sorted[rank]
+}
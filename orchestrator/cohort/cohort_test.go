@@ -0,0 +1,53 @@
+package cohort
+
+import (
+	"testing"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	alice := ethcmn.HexToAddress("0x8589427373D6D84E98730D7795D8f6f8731FDA16")
+	bob := ethcmn.HexToAddress("0x722122dF12D4e14e13Ac3b6895a86e84145b6967")
+
+	relays := []Relay{
+		{Type: RelayBatch, Relayer: alice, Response: time.Minute},
+		{Type: RelayBatch, Relayer: alice, Response: 3 * time.Minute},
+		{Type: RelayValset, Relayer: alice},
+		{Type: RelayBatch, Relayer: bob, Response: 10 * time.Minute},
+	}
+
+	summary := Summarize(relays, 100, 200)
+
+	assert.Equal(t, uint64(100), summary.FromHeight)
+	assert.Equal(t, uint64(200), summary.ToHeight)
+	assert.Equal(t, 4, summary.Relays)
+	assert.Equal(t, 2, summary.ActiveRelayers)
+	assert.Equal(t, 3*time.Minute, summary.MedianResponse)
+	assert.Equal(t, 10*time.Minute, summary.P90Response)
+
+	assert.Equal(t, []RelayerStats{
+		{Address: alice.Hex(), Batches: 2, Valsets: 1, Share: 0.75, MedianResponse: time.Minute},
+		{Address: bob.Hex(), Batches: 1, Share: 0.25, MedianResponse: 10 * time.Minute},
+	}, summary.Relayers)
+}
+
+func TestSummarizeEmpty(t *testing.T) {
+	summary := Summarize(nil, 100, 200)
+
+	assert.Zero(t, summary.ActiveRelayers)
+	assert.Zero(t, summary.MedianResponse)
+	assert.Empty(t, summary.Relayers)
+}
+
+func TestQuantile(t *testing.T) {
+	durations := []time.Duration{5, 1, 4, 2, 3, 10, 9, 8, 7, 6}
+
+	assert.Equal(t, time.Duration(5), quantile(durations, 0.5))
+	assert.Equal(t, time.Duration(9), quantile(durations, 0.9))
+	assert.Equal(t, time.Duration(1), quantile(durations, 0))
+	assert.Equal(t, time.Duration(10), quantile(durations, 1))
+	assert.Zero(t, quantile(nil, 0.5))
+}
@@ -0,0 +1,133 @@
+package cohort
+
+import (
+	"context"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+
+	"github.com/umee-network/peggo/orchestrator/loops"
+)
+
+// Monitor periodically summarizes the relays of the latest Ethereum blocks and
+// exports the summary as metrics.
+type Monitor struct {
+	logger  zerolog.Logger
+	scanner *Scanner
+	blocks  uint64
+	self    ethcmn.Address
+
+	activeRelayers prometheus.Gauge
+	relays         prometheus.Gauge
+	response       *prometheus.GaugeVec
+	share          prometheus.Gauge
+}
+
+// NewMonitor returns a monitor of the relays of the last blocks Ethereum
+// blocks, whose metrics are registered with registerer. self is the address
+// of this relayer, whose share of the relays is exported.
+func NewMonitor(
+	logger zerolog.Logger,
+	scanner *Scanner,
+	blocks uint64,
+	self ethcmn.Address,
+	registerer prometheus.Registerer,
+) (*Monitor, error) {
+	if blocks == 0 {
+		return nil, errors.New("relayer cohort window must be positive")
+	}
+
+	m := &Monitor{
+		logger:  logger.With().Str("module", "cohort").Logger(),
+		scanner: scanner,
+		blocks:  blocks,
+		self:    self,
+		activeRelayers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "peggo_relayer_cohort_active_relayers",
+			Help: "Number of distinct addresses that relayed batches or valset updates over the cohort window.",
+		}),
+		relays: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "peggo_relayer_cohort_relays",
+			Help: "Number of batches and valset updates relayed over the cohort window.",
+		}),
+		response: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "peggo_relayer_cohort_response_seconds",
+			Help: "Time from the creation of a batch to its execution on Ethereum over the cohort window, by quantile.",
+		}, []string{"quantile"}),
+		share: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "peggo_relayer_cohort_share",
+			Help: "Share of the relays over the cohort window sent by this relayer.",
+		}),
+	}
+
+	if registerer != nil {
+		for _, c := range []prometheus.Collector{m.activeRelayers, m.relays, m.response, m.share} {
+			if err := registerer.Register(c); err != nil {
+				return nil, errors.Wrap(err, "failed to register metric")
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// Update summarizes the relays of the last blocks and updates the metrics.
+func (m *Monitor) Update(ctx context.Context) (Summary, error) {
+	to, err := m.scanner.LatestHeight(ctx)
+	if err != nil {
+		return Summary{}, errors.Wrap(err, "failed to get the latest Ethereum height")
+	}
+
+	var from uint64
+	if to >= m.blocks {
+		from = to - m.blocks + 1
+	}
+
+	relays, err := m.scanner.Relays(ctx, from, to)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	summary := Summarize(relays, from, to)
+
+	share := 0.0
+	for _, r := range summary.Relayers {
+		if ethcmn.HexToAddress(r.Address) == m.self {
+			share = r.Share
+		}
+	}
+
+	m.activeRelayers.Set(float64(summary.ActiveRelayers))
+	m.relays.Set(float64(summary.Relays))
+	m.response.WithLabelValues("0.5").Set(summary.MedianResponse.Seconds())
+	m.response.WithLabelValues("0.9").Set(summary.P90Response.Seconds())
+	m.share.Set(share)
+
+	return summary, nil
+}
+
+// Start updates the summary every interval until the context is done. A
+// failed update is logged and the metrics keep the previous summary.
+func (m *Monitor) Start(ctx context.Context, interval time.Duration) error {
+	return loops.RunLoop(ctx, m.logger, interval, func() error {
+		summary, err := m.Update(ctx)
+		if err != nil {
+			m.logger.Err(err).Msg("failed to summarize the relayer cohort")
+			return nil
+		}
+
+		m.logger.Info().
+			Uint64("from_height", summary.FromHeight).
+			Uint64("to_height", summary.ToHeight).
+			Int("relays", summary.Relays).
+			Int("active_relayers", summary.ActiveRelayers).
+			Dur("median_response", summary.MedianResponse).
+			Dur("p90_response", summary.P90Response).
+			Msg("relayer cohort summarized")
+
+		return nil
+	})
+}
@@ -1,6 +1,7 @@
 package coingecko
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -34,6 +35,17 @@ type (
 	// Config wraps the config variable to get CoinGecko information.
 	Config struct {
 		BaseURL string
+		// APIKey is sent with every request, as a Pro API key on the Pro API
+		// (pro-api.coingecko.com) and as a Demo API key otherwise.
+		APIKey string
+		// Limiter, if set, rate limits the requests. It can be shared with the
+		// other clients of the API.
+		Limiter Limiter
+	}
+
+	// Limiter rate limits the requests, e.g. an *oracle.Limiter.
+	Limiter interface {
+		Wait(ctx context.Context) error
 	}
 
 	// CoinInfo wraps the coin information received from a contract address.
@@ -106,7 +118,7 @@ func (cp *CoinGecko) requestCoinSymbol(erc20Contract ethcmn.Address) (string, er
 		cp.logger.Err(err).Msg("failed to create HTTP request for coin info")
 	}
 
-	resp, err := cp.client.Do(req)
+	resp, err := cp.do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to fetch coin info from %s: %w", reqURL, err)
 	}
@@ -128,6 +140,28 @@ func (cp *CoinGecko) requestCoinSymbol(erc20Contract ethcmn.Address) (string, er
 	return strings.ToUpper(coinInfo.Symbol), nil
 }
 
+// do sends a request with the API key, once the rate limit allows it.
+func (cp *CoinGecko) do(req *http.Request) (*http.Response, error) {
+	if cp.config.Limiter != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), maxRespTime)
+		defer cancel()
+
+		if err := cp.config.Limiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("rate limited: %w", err)
+		}
+	}
+
+	if cp.config.APIKey != "" {
+		header := "x-cg-demo-api-key"
+		if strings.HasPrefix(req.URL.Host, "pro-api.") {
+			header = "x-cg-pro-api-key"
+		}
+		req.Header.Set(header, cp.config.APIKey)
+	}
+
+	return cp.client.Do(req)
+}
+
 func checkCoingeckoConfig(cfg *Config) *Config {
 	if cfg == nil {
 		cfg = &Config{}
@@ -1,11 +1,13 @@
 package coingecko
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/rs/zerolog"
@@ -64,3 +66,53 @@ func TestCheckCoingeckoConfig(t *testing.T) {
 	assert.NotNil(t, checkCoingeckoConfig(nil))
 	assert.NotNil(t, checkCoingeckoConfig(&Config{BaseURL: ""}))
 }
+
+func TestGetCoinPriceHistory(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/coins/binancecoin/market_chart/range", r.URL.Path)
+		assert.Equal(t, "usd", r.URL.Query().Get("vs_currency"))
+		fmt.Fprint(w, `{"prices": [[1660003200000, 1700.5], [1659999600000, 1690.25]]}`)
+	}))
+	defer svr.Close()
+
+	coinGecko := NewCoingecko(logger, &Config{BaseURL: svr.URL})
+	history, err := coinGecko.GetCoinPriceHistory("binancecoin", time.Unix(1659999600, 0), time.Unix(1660003200, 0))
+	assert.Nil(t, err)
+	assert.Len(t, history, 2)
+
+	price, ok := history.At(time.Unix(1660000000, 0))
+	assert.True(t, ok)
+	assert.Equal(t, "1690.25", price.String())
+
+	price, ok = history.At(time.Unix(1660010000, 0))
+	assert.True(t, ok)
+	assert.Equal(t, "1700.5", price.String())
+
+	_, ok = PriceHistory{}.At(time.Now())
+	assert.False(t, ok)
+}
+
+// countingLimiter counts the requests it let through.
+type countingLimiter struct {
+	waits int
+}
+
+func (l *countingLimiter) Wait(context.Context) error {
+	l.waits++
+	return nil
+}
+
+func TestAPIKeyAndLimiter(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "CG-secret", r.Header.Get("x-cg-demo-api-key"))
+		fmt.Fprint(w, `{"symbol": "umee"}`)
+	}))
+	defer svr.Close()
+
+	limiter := &countingLimiter{}
+	coinGecko := NewCoingecko(logger, &Config{BaseURL: svr.URL, APIKey: "CG-secret", Limiter: limiter})
+
+	_, err := coinGecko.requestCoinSymbol(ethcmn.HexToAddress("0xc0a4Df35568F116C370E6a6A6022Ceb908eedDaC"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, limiter.waits)
+}
@@ -0,0 +1,109 @@
+package coingecko
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+)
+
+type (
+	// PricePoint is the USD price of a coin at a given time.
+	PricePoint struct {
+		Time  time.Time
+		Price decimal.Decimal
+	}
+
+	// PriceHistory holds the USD prices of a coin over a time range, sorted by
+	// time.
+	PriceHistory []PricePoint
+
+	// marketChart wraps the response of the market chart range endpoints.
+	//
+	// Ref : https://api.coingecko.com/api/v3/coins/ethereum/market_chart/range?vs_currency=usd&from=${FROM}&to=${TO}
+	marketChart struct {
+		Prices [][2]float64 `json:"prices"`
+		Error  string       `json:"error"`
+	}
+)
+
+// At returns the last known price at the given time. If the time is before the
+// first point, the first price is returned.
+func (h PriceHistory) At(t time.Time) (decimal.Decimal, bool) {
+	if len(h) == 0 {
+		return decimal.Decimal{}, false
+	}
+
+	i := sort.Search(len(h), func(i int) bool { return h[i].Time.After(t) })
+	if i == 0 {
+		return h[0].Price, true
+	}
+
+	return h[i-1].Price, true
+}
+
+// GetCoinPriceHistory returns the USD prices of a coin, by its CoinGecko ID
+// (e.g. ethereum or binancecoin), between from and to.
+func (cp *CoinGecko) GetCoinPriceHistory(coinID string, from, to time.Time) (PriceHistory, error) {
+	u, err := urlJoin(cp.config.BaseURL, "coins", coinID, "market_chart", "range")
+	if err != nil {
+		return nil, err
+	}
+
+	return cp.requestPriceHistory(u.String(), from, to)
+}
+
+// GetTokenPriceHistory returns the USD prices of an ERC20 token between from and
+// to.
+func (cp *CoinGecko) GetTokenPriceHistory(erc20Contract ethcmn.Address, from, to time.Time) (PriceHistory, error) {
+	u, err := urlJoin(cp.config.BaseURL, "coins", EthereumCoinID, "contract", erc20Contract.Hex(), "market_chart", "range")
+	if err != nil {
+		return nil, err
+	}
+
+	return cp.requestPriceHistory(u.String(), from, to)
+}
+
+func (cp *CoinGecko) requestPriceHistory(reqURL string, from, to time.Time) (PriceHistory, error) {
+	req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	q := req.URL.Query()
+	q.Set("vs_currency", "usd")
+	q.Set("from", strconv.FormatInt(from.Unix(), 10))
+	q.Set("to", strconv.FormatInt(to.Unix(), 10))
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := cp.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch price history from %s: %w", reqURL, err)
+	}
+	defer resp.Body.Close()
+
+	var chart marketChart
+	if err := json.NewDecoder(resp.Body).Decode(&chart); err != nil {
+		return nil, fmt.Errorf("failed to parse response body from %s: %w", reqURL, err)
+	}
+
+	if len(chart.Error) > 0 {
+		return nil, fmt.Errorf("price history request failed: %s", chart.Error)
+	}
+
+	history := make(PriceHistory, 0, len(chart.Prices))
+	for _, p := range chart.Prices {
+		history = append(history, PricePoint{
+			Time:  time.UnixMilli(int64(p[0])).UTC(),
+			Price: decimal.NewFromFloat(p[1]),
+		})
+	}
+
+	sort.Slice(history, func(i, j int) bool { return history[i].Time.Before(history[j].Time) })
+	return history, nil
+}
@@ -0,0 +1,121 @@
+package orchestrator
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/avast/retry-go"
+	"github.com/rs/zerolog"
+)
+
+// SetConfirmSync returns the orchestrator option signing the missed confirms at
+// startup.
+func SetConfirmSync(enabled bool) func(GravityOrchestrator) {
+	return func(o GravityOrchestrator) { o.SetConfirmSync(enabled) }
+}
+
+// SetConfirmSync makes the orchestrator sign the confirms of all the valsets
+// and batches it missed at startup, before starting its loops.
+func (p *gravityOrchestrator) SetConfirmSync(enabled bool) {
+	p.confirmSync = enabled
+}
+
+// syncConfirms signs and sends the confirms of all the valsets and batches
+// waiting for this orchestrator's signature, e.g. the ones requested while it
+// was down for maintenance, so the window where it could be slashed for them
+// is as short as possible. It never fails: the confirms it couldn't send are
+// left to the signer loop.
+func (p *gravityOrchestrator) syncConfirms(ctx context.Context) {
+	logger := p.logger.With().Str("loop", "ConfirmSync").Logger()
+
+	var gravityID string
+	if err := retry.Do(func() (err error) {
+		gravityID, err = p.gravityContract.GetGravityID(ctx, p.gravityContract.FromAddress())
+		return err
+	}, retry.Context(ctx), retry.Attempts(3)); err != nil {
+		logger.Err(err).Msg("failed to get GravityID from Ethereum contract; skipping the confirm sync")
+		return
+	}
+
+	p.sendPendingConfirms(ctx, logger, gravityID)
+}
+
+// sendPendingConfirms queries, in one pass, the valsets and batches this
+// orchestrator hasn't signed and sends their confirms, oldest first, logging
+// the progress.
+func (p *gravityOrchestrator) sendPendingConfirms(ctx context.Context, logger zerolog.Logger, gravityID string) {
+	start := time.Now()
+	address := p.gravityBroadcastClient.AccFromAddress().String()
+
+	var valsets []types.Valset
+	valsetsResp, err := p.cosmosQueryClient.LastPendingValsetRequestByAddr(
+		ctx,
+		&types.QueryLastPendingValsetRequestByAddrRequest{Address: address},
+	)
+	if err != nil {
+		logger.Err(err).Msg("failed to get the unsigned valsets")
+	} else if valsetsResp != nil {
+		valsets = valsetsResp.Valsets
+	}
+
+	var batches []types.OutgoingTxBatch
+	batchesResp, err := p.cosmosQueryClient.LastPendingBatchRequestByAddr(
+		ctx,
+		&types.QueryLastPendingBatchRequestByAddrRequest{Address: address},
+	)
+	if err != nil {
+		logger.Err(err).Msg("failed to get the unsigned batches")
+	} else if batchesResp != nil {
+		batches = batchesResp.Batch
+	}
+
+	total := len(valsets) + len(batches)
+	if total == 0 {
+		logger.Info().Msg("no missing confirms")
+		return
+	}
+
+	sort.Slice(valsets, func(i, j int) bool { return valsets[i].Nonce < valsets[j].Nonce })
+	sort.Slice(batches, func(i, j int) bool { return batches[i].BatchNonce < batches[j].BatchNonce })
+
+	logger.Info().Int("valsets", len(valsets)).Int("batches", len(batches)).Msg("sending missing confirms")
+
+	sent, failed := 0, 0
+	progress := func(confirmType string, nonce uint64, err error) {
+		event, msg := logger.Info(), "sent missing confirm"
+		if err != nil {
+			failed++
+			event, msg = logger.Warn().Err(err), "failed to send missing confirm"
+		} else {
+			sent++
+		}
+
+		event.Str("type", confirmType).Uint64("nonce", nonce).Int("done", sent+failed).Int("total", total).Msg(msg)
+	}
+
+	for _, valset := range valsets {
+		if ctx.Err() != nil {
+			return
+		}
+
+		err := p.gravityBroadcastClient.SendValsetConfirm(ctx, p.ethFrom, gravityID, valset)
+		progress("valset", valset.Nonce, err)
+	}
+
+	for _, batch := range batches {
+		if ctx.Err() != nil {
+			return
+		}
+
+		err := p.gravityBroadcastClient.SendBatchConfirm(ctx, p.ethFrom, gravityID, batch)
+		progress("batch", batch.BatchNonce, err)
+	}
+
+	logger.Info().
+		Int("sent", sent).
+		Int("failed", failed).
+		Dur("duration", time.Since(start)).
+		Msg("missing confirms synced; the signer loop retries the failed ones")
+}
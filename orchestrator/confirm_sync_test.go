@@ -0,0 +1,108 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/umee-network/peggo/mocks"
+	sidechain "github.com/umee-network/peggo/orchestrator/cosmos"
+)
+
+// confirmRecorder records the confirms sent, failing the ones of failNonce.
+type confirmRecorder struct {
+	sidechain.GravityBroadcastClient
+	failNonce uint64
+	sent      []string
+}
+
+func (r *confirmRecorder) AccFromAddress() sdk.AccAddress {
+	return sdk.AccAddress("orchestrator")
+}
+
+func (r *confirmRecorder) SendValsetConfirm(_ context.Context, _ ethcmn.Address, _ string, valset types.Valset) error {
+	if valset.Nonce == r.failNonce {
+		return errors.New("account sequence mismatch")
+	}
+
+	r.sent = append(r.sent, fmt.Sprintf("valset %d", valset.Nonce))
+	return nil
+}
+
+func (r *confirmRecorder) SendBatchConfirm(
+	_ context.Context,
+	_ ethcmn.Address,
+	_ string,
+	batch types.OutgoingTxBatch,
+) error {
+	if batch.BatchNonce == r.failNonce {
+		return errors.New("account sequence mismatch")
+	}
+
+	r.sent = append(r.sent, fmt.Sprintf("batch %d", batch.BatchNonce))
+	return nil
+}
+
+func TestSendPendingConfirms(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	address := sdk.AccAddress("orchestrator").String()
+
+	mockQClient := mocks.NewMockQueryClient(mockCtrl)
+	mockQClient.EXPECT().
+		LastPendingValsetRequestByAddr(gomock.Any(), &types.QueryLastPendingValsetRequestByAddrRequest{Address: address}).
+		Return(&types.QueryLastPendingValsetRequestByAddrResponse{
+			Valsets: []types.Valset{{Nonce: 7}, {Nonce: 5}},
+		}, nil)
+	mockQClient.EXPECT().
+		LastPendingBatchRequestByAddr(gomock.Any(), &types.QueryLastPendingBatchRequestByAddrRequest{Address: address}).
+		Return(&types.QueryLastPendingBatchRequestByAddrResponse{
+			Batch: []types.OutgoingTxBatch{{BatchNonce: 3}, {BatchNonce: 2}},
+		}, nil)
+
+	recorder := &confirmRecorder{failNonce: 7}
+	orch := gravityOrchestrator{
+		logger:                 zerolog.Nop(),
+		cosmosQueryClient:      mockQClient,
+		gravityBroadcastClient: recorder,
+	}
+
+	// oldest first, and the valset failing to confirm doesn't hold back the
+	// batches
+	orch.sendPendingConfirms(context.Background(), zerolog.Nop(), "gravity-id")
+	assert.Equal(t, []string{"valset 5", "batch 2", "batch 3"}, recorder.sent)
+}
+
+func TestSendPendingConfirmsQueryError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockQClient := mocks.NewMockQueryClient(mockCtrl)
+	mockQClient.EXPECT().
+		LastPendingValsetRequestByAddr(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("connection refused"))
+	mockQClient.EXPECT().
+		LastPendingBatchRequestByAddr(gomock.Any(), gomock.Any()).
+		Return(&types.QueryLastPendingBatchRequestByAddrResponse{
+			Batch: []types.OutgoingTxBatch{{BatchNonce: 2}},
+		}, nil)
+
+	recorder := &confirmRecorder{}
+	orch := gravityOrchestrator{
+		logger:                 zerolog.Nop(),
+		cosmosQueryClient:      mockQClient,
+		gravityBroadcastClient: recorder,
+	}
+
+	orch.sendPendingConfirms(context.Background(), zerolog.Nop(), "gravity-id")
+	assert.Equal(t, []string{"batch 2"}, recorder.sent)
+}
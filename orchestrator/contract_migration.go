@@ -0,0 +1,167 @@
+package orchestrator
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
+)
+
+// firstEventNonce is the nonce of the valset event emitted by the Gravity
+// contract constructor.
+const firstEventNonce = 1
+
+type (
+	// ContractMigration is a planned switch of the bridge from a Gravity
+	// contract to a new one.
+	ContractMigration struct {
+		// Block is the first Ethereum block whose events are read from the new
+		// contract.
+		Block uint64
+		// From and To are the addresses of the old and new contracts.
+		From, To ethcmn.Address
+		// LastEventNonce is the nonce of the last event of the old contract.
+		// Zero reads it from the old contract at the block before the migration,
+		// which needs an archive node once that block is pruned.
+		LastEventNonce uint64
+	}
+
+	// contractRange is a block range whose events are read from a Gravity
+	// contract.
+	contractRange struct {
+		address    ethcmn.Address
+		start, end uint64
+		// migrated is set for the range of the new contract, whose event
+		// nonces are shifted by nonceOffset to continue those of the old one.
+		migrated    bool
+		nonceOffset uint64
+	}
+)
+
+// SetContractMigration returns the orchestrator option switching the Ethereum
+// oracle to a new Gravity contract at the given block.
+func SetContractMigration(m ContractMigration) func(GravityOrchestrator) {
+	return func(o GravityOrchestrator) { o.SetContractMigration(m) }
+}
+
+// SetContractMigration makes the Ethereum oracle read the events of the old
+// Gravity contract up to the migration block, and those of the new one from
+// then on. The event nonces of the new contract are shifted to continue the
+// nonces of the old one; its constructor event, which restates the valset, is
+// not claimed.
+func (p *gravityOrchestrator) SetContractMigration(m ContractMigration) {
+	p.contractMigration = &m
+}
+
+// contractRanges splits the block range between the old and new Gravity
+// contracts around the migration block.
+func (p *gravityOrchestrator) contractRanges(ctx context.Context, start, end uint64) ([]contractRange, error) {
+	m := p.contractMigration
+	if m == nil {
+		return []contractRange{{address: p.gravityContract.Address(), start: start, end: end}}, nil
+	}
+
+	var ranges []contractRange
+	if start < m.Block {
+		oldEnd := end
+		if oldEnd >= m.Block {
+			oldEnd = m.Block - 1
+		}
+
+		ranges = append(ranges, contractRange{address: m.From, start: start, end: oldEnd})
+	}
+
+	if end >= m.Block {
+		offset, err := p.migrationNonceOffset(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		newStart := start
+		if newStart < m.Block {
+			newStart = m.Block
+		}
+
+		ranges = append(ranges, contractRange{
+			address:     m.To,
+			start:       newStart,
+			end:         end,
+			migrated:    true,
+			nonceOffset: offset,
+		})
+	}
+
+	return ranges, nil
+}
+
+// lastEventSource returns the Gravity contract that emitted the event of the
+// given nonce, and its nonce within that contract.
+func (p *gravityOrchestrator) lastEventSource(ctx context.Context, nonce uint64) (ethcmn.Address, uint64, error) {
+	m := p.contractMigration
+	if m == nil {
+		return p.gravityContract.Address(), nonce, nil
+	}
+
+	offset, err := p.migrationNonceOffset(ctx)
+	if err != nil {
+		return ethcmn.Address{}, 0, err
+	}
+
+	if nonce > offset+firstEventNonce {
+		return m.To, nonce - offset, nil
+	}
+
+	return m.From, nonce, nil
+}
+
+// migrationNonceOffset returns the offset added to the event nonces of the new
+// Gravity contract: its first event after the constructor one then follows the
+// last event of the old contract.
+func (p *gravityOrchestrator) migrationNonceOffset(ctx context.Context) (uint64, error) {
+	m := p.contractMigration
+
+	if m.LastEventNonce == 0 {
+		caller, err := wrappers.NewGravityCaller(m.From, p.ethProvider)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to init Gravity caller")
+		}
+
+		nonce, err := caller.StateLastEventNonce(&bind.CallOpts{
+			Context:     ctx,
+			BlockNumber: new(big.Int).SetUint64(m.Block - 1),
+		})
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to get the last event nonce of the old Gravity contract")
+		}
+
+		m.LastEventNonce = nonce.Uint64()
+		p.logger.Info().
+			Uint64("last_event_nonce", m.LastEventNonce).
+			Str("contract", m.From.Hex()).
+			Msg("read the last event nonce of the old Gravity contract")
+	}
+
+	if m.LastEventNonce < firstEventNonce {
+		return 0, errors.Errorf("invalid last event nonce %d of the old Gravity contract", m.LastEventNonce)
+	}
+
+	return m.LastEventNonce - firstEventNonce, nil
+}
+
+// eventNonce returns the nonce of an event of the range's contract, shifted by
+// the range offset, and false if the event must not be claimed.
+func (r contractRange) eventNonce(nonce *big.Int) (*big.Int, bool) {
+	if !r.migrated {
+		return nonce, true
+	}
+
+	if nonce.Uint64() <= firstEventNonce {
+		return nil, false
+	}
+
+	return new(big.Int).Add(nonce, new(big.Int).SetUint64(r.nonceOffset)), true
+}
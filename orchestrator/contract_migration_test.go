@@ -0,0 +1,66 @@
+package orchestrator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContractMigration(t *testing.T) {
+	oldAddr := ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
+	newAddr := ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e03e53415d37aa96045")
+
+	p := &gravityOrchestrator{}
+	p.SetContractMigration(ContractMigration{
+		Block:          100,
+		From:           oldAddr,
+		To:             newAddr,
+		LastEventNonce: 42,
+	})
+
+	ctx := context.Background()
+
+	ranges, err := p.contractRanges(ctx, 10, 50)
+	require.NoError(t, err)
+	assert.Equal(t, []contractRange{{address: oldAddr, start: 10, end: 50}}, ranges)
+
+	ranges, err = p.contractRanges(ctx, 90, 110)
+	require.NoError(t, err)
+	assert.Equal(t, []contractRange{
+		{address: oldAddr, start: 90, end: 99},
+		{address: newAddr, start: 100, end: 110, migrated: true, nonceOffset: 41},
+	}, ranges)
+
+	ranges, err = p.contractRanges(ctx, 100, 110)
+	require.NoError(t, err)
+	assert.Equal(t, []contractRange{
+		{address: newAddr, start: 100, end: 110, migrated: true, nonceOffset: 41},
+	}, ranges)
+
+	// the constructor event of the new contract isn't claimed, the next one
+	// follows the last event of the old contract
+	_, ok := ranges[0].eventNonce(big.NewInt(1))
+	assert.False(t, ok)
+
+	nonce, ok := ranges[0].eventNonce(big.NewInt(2))
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(43), nonce)
+
+	nonce, ok = contractRange{address: oldAddr}.eventNonce(big.NewInt(1))
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(1), nonce)
+
+	addr, nonce64, err := p.lastEventSource(ctx, 42)
+	require.NoError(t, err)
+	assert.Equal(t, oldAddr, addr)
+	assert.Equal(t, uint64(42), nonce64)
+
+	addr, nonce64, err = p.lastEventSource(ctx, 43)
+	require.NoError(t, err)
+	assert.Equal(t, newAddr, addr)
+	assert.Equal(t, uint64(2), nonce64)
+}
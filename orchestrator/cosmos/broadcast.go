@@ -3,16 +3,19 @@ package cosmos
 import (
 	"context"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	"github.com/umee-network/peggo/cmd/peggo/client"
-	gravity "github.com/umee-network/peggo/orchestrator/ethereum/gravity"
 	"github.com/umee-network/peggo/orchestrator/ethereum/keystore"
+	"github.com/umee-network/peggo/orchestrator/signer"
+	"github.com/umee-network/peggo/orchestrator/totals"
 	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
 )
 
@@ -55,14 +58,22 @@ type GravityBroadcastClient interface {
 
 type (
 	gravityBroadcastClient struct {
-		logger            zerolog.Logger
-		daemonQueryClient types.QueryClient
-		broadcastClient   client.CosmosClient
-		ethSignerFn       keystore.SignerFn
-		ethPersonalSignFn keystore.PersonalSignFn
-		msgsPerTx         int
+		logger              zerolog.Logger
+		daemonQueryClient   types.QueryClient
+		broadcastClient     client.CosmosClient
+		ethSignerFn         keystore.SignerFn
+		ethPersonalSignFn   keystore.PersonalSignFn
+		confirmSigner       signer.ConfirmSigner
+		msgsPerTx           int
+		pipelineDepth       int
+		claimsGasAdjustment float64
+		claimsMetrics       *ClaimsMetrics
+		claimsTotals        *totals.Totals
 	}
 
+	// BroadcastClientOption configures optional GravityBroadcastClient settings.
+	BroadcastClientOption func(*gravityBroadcastClient)
+
 	// sortableEvent exists with the only purpose to make a nicer sortable slice
 	// for Ethereum events. It is only used in SendEthereumClaims.
 	sortableEvent struct {
@@ -81,14 +92,32 @@ func NewGravityBroadcastClient(
 	ethSignerFn keystore.SignerFn,
 	ethPersonalSignFn keystore.PersonalSignFn,
 	msgsPerTx int,
+	options ...BroadcastClientOption,
 ) GravityBroadcastClient {
-	return &gravityBroadcastClient{
-		logger:            logger.With().Str("module", "gravity_broadcast_client").Logger(),
-		daemonQueryClient: queryClient,
-		broadcastClient:   broadcastClient,
-		ethSignerFn:       ethSignerFn,
-		ethPersonalSignFn: ethPersonalSignFn,
-		msgsPerTx:         msgsPerTx,
+	s := &gravityBroadcastClient{
+		logger:              logger.With().Str("module", "gravity_broadcast_client").Logger(),
+		daemonQueryClient:   queryClient,
+		broadcastClient:     broadcastClient,
+		ethSignerFn:         ethSignerFn,
+		ethPersonalSignFn:   ethPersonalSignFn,
+		confirmSigner:       signer.NewLocal(ethPersonalSignFn),
+		msgsPerTx:           msgsPerTx,
+		pipelineDepth:       1,
+		claimsGasAdjustment: DefaultClaimsGasAdjustment,
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// OptionConfirmSigner signs valset and batch confirms with the given signer
+// (e.g. a remote signing server) instead of the personal sign function.
+func OptionConfirmSigner(confirmSigner signer.ConfirmSigner) BroadcastClientOption {
+	return func(s *gravityBroadcastClient) {
+		s.confirmSigner = confirmSigner
 	}
 }
 
@@ -103,8 +132,7 @@ func (s *gravityBroadcastClient) SendValsetConfirm(
 	valset types.Valset,
 ) error {
 
-	confirmHash := gravity.EncodeValsetConfirm(gravityID, valset)
-	signature, err := s.ethPersonalSignFn(ethFrom, confirmHash.Bytes())
+	signature, err := s.confirmSigner.SignValsetConfirm(ctx, ethFrom, gravityID, valset)
 	if err != nil {
 		err = errors.New("failed to sign validator address")
 		return err
@@ -146,8 +174,7 @@ func (s *gravityBroadcastClient) SendBatchConfirm(
 	batch types.OutgoingTxBatch,
 ) error {
 
-	confirmHash := gravity.EncodeTxBatchConfirm(gravityID, batch)
-	signature, err := s.ethPersonalSignFn(ethFrom, confirmHash.Bytes())
+	signature, err := s.confirmSigner.SignBatchConfirm(ctx, ethFrom, gravityID, batch)
 	if err != nil {
 		err = errors.New("failed to sign validator address")
 		return err
@@ -225,7 +252,7 @@ func (s *gravityBroadcastClient) SendEthereumClaims(
 		}
 	}
 
-	return s.broadcastEthereumEvents(allevents)
+	return s.broadcastEthereumEvents(ctx, allevents)
 }
 
 func (s *gravityBroadcastClient) SendRequestBatch(
@@ -254,7 +281,7 @@ func (s *gravityBroadcastClient) SendRequestBatch(
 	return nil
 }
 
-func (s *gravityBroadcastClient) broadcastEthereumEvents(events []sortableEvent) error {
+func (s *gravityBroadcastClient) broadcastEthereumEvents(ctx context.Context, events []sortableEvent) error {
 	msgs := []sdk.Msg{}
 
 	// Use SliceStable so we always get the same order
@@ -339,35 +366,22 @@ func (s *gravityBroadcastClient) broadcastEthereumEvents(events []sortableEvent)
 		Int("num_total_claims", len(events)).
 		Msg("oracle observed events; sending claims")
 
-	// We send the messages in batches, so that we don't hit any limits
-	msgSets := splitMsgs(msgs, s.msgsPerTx)
-
-	for _, msgSet := range msgSets {
-		txResponse, err := s.broadcastClient.SyncBroadcastMsg(msgSet...)
-		if err != nil {
-			s.logger.Err(err).Msg("broadcasting multiple claims failed")
-			return err
-		}
-
-		s.logger.Info().
-			Str("tx_hash", txResponse.TxHash).
-			Int("total_claims", len(events)).
-			Int("claims_sent", len(msgSet)).
-			Msg("oracle sent set of claims successfully")
-	}
-
-	return nil
+	return s.broadcastClaims(ctx, msgs)
 }
 
-func splitMsgs(buf []sdk.Msg, lim int) [][]sdk.Msg {
-	var chunk []sdk.Msg
-	chunks := make([][]sdk.Msg, 0, len(buf)/lim+1)
-	for len(buf) >= lim {
-		chunk, buf = buf[:lim], buf[lim:]
-		chunks = append(chunks, chunk)
+// isTxLimitError returns true if the error means the tx is too big, in gas or
+// in bytes, to be included in a block.
+func isTxLimitError(err error) bool {
+	if errors.Is(err, sdkerrors.ErrOutOfGas) || errors.Is(err, sdkerrors.ErrTxTooLarge) {
+		return true
 	}
-	if len(buf) > 0 {
-		chunks = append(chunks, buf)
+
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"out of gas", "max gas", "block gas", "tx too large"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
 	}
-	return chunks
+
+	return false
 }
@@ -2,6 +2,7 @@ package cosmos
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 	"os"
 	"testing"
@@ -12,7 +13,10 @@ import (
 
 	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 
@@ -385,6 +389,253 @@ func TestSendEthereumClaimsIgnoreNonSequentialNonces(t *testing.T) {
 	)
 }
 
+func TestSendEthereumClaimsSplitsOnGasLimit(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockCosmos := mocks.NewMockCosmosClient(mockCtrl)
+	mockCosmos.EXPECT().FromAddress().Return(sdk.AccAddress{}).AnyTimes()
+
+	// Only txs with up to 2 claims fit in a block.
+	var sentNonces []uint64
+	mockCosmos.EXPECT().SyncBroadcastMsg(gomock.Any()).DoAndReturn(
+		func(msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+			if len(msgs) > 2 {
+				return nil, errors.Wrap(sdkerrors.ErrOutOfGas, "out of gas in location: WriteFlat")
+			}
+			for _, msg := range msgs {
+				sentNonces = append(sentNonces, msg.(types.EthereumClaim).GetEventNonce())
+			}
+			return &sdk.TxResponse{}, nil
+		},
+	).Times(5)
+
+	s := gravityBroadcastClient{
+		daemonQueryClient: nil,
+		broadcastClient:   mockCosmos,
+		msgsPerTx:         4,
+	}
+
+	var deposits []*wrappers.GravitySendToCosmosEvent
+	for i := int64(7); i > 0; i-- {
+		deposits = append(deposits, &wrappers.GravitySendToCosmosEvent{
+			EventNonce: big.NewInt(i),
+			Amount:     big.NewInt(123),
+		})
+	}
+
+	err := s.SendEthereumClaims(context.Background(),
+		0,
+		deposits,
+		nil,
+		nil,
+		nil,
+		time.Microsecond,
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5, 6, 7}, sentNonces)
+}
+
+func TestSendEthereumClaimsPipelined(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockCosmos := mocks.NewMockCosmosClient(mockCtrl)
+	mockCosmos.EXPECT().FromAddress().Return(sdk.AccAddress{}).AnyTimes()
+
+	var sentNonces []uint64
+	record := func(msgs []sdk.Msg) {
+		for _, msg := range msgs {
+			sentNonces = append(sentNonces, msg.(types.EthereumClaim).GetEventNonce())
+		}
+	}
+
+	// Only the first tx is simulated.
+	mockCosmos.EXPECT().SyncBroadcastMsg(gomock.Any()).DoAndReturn(
+		func(msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+			record(msgs)
+			return &sdk.TxResponse{TxHash: "sync", GasWanted: 300000, GasUsed: 200000}, nil
+		},
+	).Times(1)
+
+	// The gas used per claim, adjusted, and raised by the heavier txs executed.
+	expectedGas := []uint64{300000, 300000, 360000}
+
+	var inFlight int
+	mockCosmos.EXPECT().BroadcastMsgWithGas(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(gas uint64, msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+			assert.Equal(t, expectedGas[0], gas)
+			expectedGas = expectedGas[1:]
+			inFlight++
+			assert.LessOrEqual(t, inFlight, 2)
+			record(msgs)
+			return &sdk.TxResponse{TxHash: fmt.Sprintf("tx%d", len(sentNonces))}, nil
+		},
+	).Times(3)
+
+	mockCosmos.EXPECT().WaitTx(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(context.Context, string) (*sdk.TxResponse, error) {
+			inFlight--
+			return &sdk.TxResponse{GasUsed: 240000}, nil
+		},
+	).Times(3)
+
+	registry := prometheus.NewRegistry()
+	metrics, err := NewClaimsMetrics(registry)
+	assert.Nil(t, err)
+
+	s := NewGravityBroadcastClient(
+		zerolog.Nop(),
+		nil,
+		mockCosmos,
+		nil,
+		nil,
+		2,
+		OptionClaimsPipelineDepth(2),
+		OptionClaimsMetrics(metrics),
+	)
+
+	var deposits []*wrappers.GravitySendToCosmosEvent
+	for i := int64(8); i > 0; i-- {
+		deposits = append(deposits, &wrappers.GravitySendToCosmosEvent{
+			EventNonce: big.NewInt(i),
+			Amount:     big.NewInt(123),
+		})
+	}
+
+	err = s.SendEthereumClaims(context.Background(),
+		0,
+		deposits,
+		nil,
+		nil,
+		nil,
+		time.Microsecond,
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5, 6, 7, 8}, sentNonces)
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.pending))
+}
+
+func TestSendEthereumClaimsPipelineFailure(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	testCases := []struct {
+		name           string
+		broadcastFails string // the nonce whose broadcast fails, if any
+		waitFails      map[string]bool
+		sent           []uint64
+		pending        float64
+	}{
+		{
+			// the tx in flight is still awaited and counted
+			name:           "broadcast",
+			broadcastFails: "3",
+			sent:           []uint64{1, 2},
+			pending:        4,
+		},
+		{
+			// the txs in flight are awaited, and those executed counted
+			name:      "execution",
+			waitFails: map[string]bool{"3": true, "4": true},
+			sent:      []uint64{1, 2, 3, 4, 5, 6},
+			pending:   2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			mockCosmos := mocks.NewMockCosmosClient(mockCtrl)
+			mockCosmos.EXPECT().FromAddress().Return(sdk.AccAddress{}).AnyTimes()
+
+			var (
+				sent    []uint64
+				waited  []string
+				pending = map[string]bool{}
+			)
+
+			mockCosmos.EXPECT().SyncBroadcastMsg(gomock.Any()).DoAndReturn(
+				func(msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+					sent = append(sent, msgs[0].(types.EthereumClaim).GetEventNonce())
+					return &sdk.TxResponse{TxHash: "1", GasUsed: 100000}, nil
+				},
+			)
+
+			// four pipelined txs at most, one claim each
+			mockCosmos.EXPECT().BroadcastMsgWithGas(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ uint64, msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+					txHash := fmt.Sprint(msgs[0].(types.EthereumClaim).GetEventNonce())
+					if txHash == tc.broadcastFails {
+						return nil, errFailed
+					}
+
+					sent = append(sent, msgs[0].(types.EthereumClaim).GetEventNonce())
+					pending[txHash] = true
+					return &sdk.TxResponse{TxHash: txHash}, nil
+				},
+			).AnyTimes()
+
+			mockCosmos.EXPECT().WaitTx(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, txHash string) (*sdk.TxResponse, error) {
+					assert.True(t, pending[txHash])
+					delete(pending, txHash)
+					waited = append(waited, txHash)
+
+					if tc.waitFails[txHash] {
+						return nil, errFailed
+					}
+					return &sdk.TxResponse{GasUsed: 100000}, nil
+				},
+			).AnyTimes()
+
+			registry := prometheus.NewRegistry()
+			metrics, err := NewClaimsMetrics(registry)
+			assert.Nil(t, err)
+
+			s := NewGravityBroadcastClient(
+				zerolog.Nop(),
+				nil,
+				mockCosmos,
+				nil,
+				nil,
+				1,
+				OptionClaimsPipelineDepth(4),
+				OptionClaimsMetrics(metrics),
+			)
+
+			var deposits []*wrappers.GravitySendToCosmosEvent
+			for i := int64(6); i > 0; i-- {
+				deposits = append(deposits, &wrappers.GravitySendToCosmosEvent{
+					EventNonce: big.NewInt(i),
+					Amount:     big.NewInt(123),
+				})
+			}
+
+			err = s.SendEthereumClaims(context.Background(), 0, deposits, nil, nil, nil, time.Microsecond)
+			assert.ErrorIs(t, err, errFailed)
+			assert.Equal(t, tc.sent, sent)
+			// every tx sent was awaited, and no claim was sent after the failure
+			assert.Empty(t, pending)
+			assert.Equal(t, tc.pending, testutil.ToFloat64(metrics.pending))
+		})
+	}
+}
+
+func TestClaimsProgressETA(t *testing.T) {
+	start := time.Now()
+	progress := claimsProgress{start: start, total: 560}
+
+	assert.Equal(t, time.Duration(0), progress.eta(start.Add(time.Minute)))
+
+	progress.claimed = 120
+	assert.Equal(t, 220*time.Second, progress.eta(start.Add(time.Minute)))
+
+	progress.claimed = 560
+	assert.Equal(t, time.Duration(0), progress.eta(start.Add(time.Minute)))
+}
+
 func TestSendRequestBatch(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
@@ -0,0 +1,284 @@
+package cosmos
+
+import (
+	"context"
+	"math"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/umee-network/peggo/orchestrator/totals"
+)
+
+// DefaultClaimsGasAdjustment is the default factor applied to the gas used per
+// claim by the pipelined claim txs, the same as for simulated txs.
+const DefaultClaimsGasAdjustment = 1.5
+
+type (
+	// ClaimsMetrics exports the progress of the claims being sent, e.g. while
+	// catching up after a downtime.
+	ClaimsMetrics struct {
+		pending prometheus.Gauge
+		eta     prometheus.Gauge
+	}
+
+	claimsInFlight struct {
+		txHash string
+		claims int
+	}
+
+	claimsProgress struct {
+		start   time.Time
+		total   int
+		claimed int
+	}
+)
+
+// NewClaimsMetrics returns the claims metrics registered with registerer.
+func NewClaimsMetrics(registerer prometheus.Registerer) (*ClaimsMetrics, error) {
+	m := &ClaimsMetrics{
+		pending: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "peggo",
+			Subsystem: "orchestrator",
+			Name:      "claims_pending",
+			Help:      "Number of observed Ethereum events not claimed yet.",
+		}),
+		eta: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "peggo",
+			Subsystem: "orchestrator",
+			Name:      "claims_eta_seconds",
+			Help:      "Estimated time until all the observed Ethereum events are claimed (0 when unknown or done).",
+		}),
+	}
+
+	for _, c := range []prometheus.Collector{m.pending, m.eta} {
+		if err := registerer.Register(c); err != nil {
+			return nil, errors.Wrap(err, "failed to register metric")
+		}
+	}
+
+	return m, nil
+}
+
+func (m *ClaimsMetrics) set(pending int, eta time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.pending.Set(float64(pending))
+	m.eta.Set(eta.Seconds())
+}
+
+// OptionClaimsPipelineDepth allows up to depth claim txs to be sent without
+// waiting for the previous ones to be executed. Only the first tx of a round is
+// simulated, the next ones reuse its gas per claim.
+func OptionClaimsPipelineDepth(depth int) BroadcastClientOption {
+	return func(s *gravityBroadcastClient) {
+		if depth > 1 {
+			s.pipelineDepth = depth
+		}
+	}
+}
+
+// OptionClaimsGasAdjustment sets the factor applied to the gas used per claim
+// to get the gas limit of the pipelined claim txs, as claims of the same round
+// don't all cost the same (e.g. a batch executed with more txs).
+func OptionClaimsGasAdjustment(adjustment float64) BroadcastClientOption {
+	return func(s *gravityBroadcastClient) {
+		if adjustment >= 1 {
+			s.claimsGasAdjustment = adjustment
+		}
+	}
+}
+
+// OptionClaimsMetrics exports the claims progress with the given metrics.
+func OptionClaimsMetrics(m *ClaimsMetrics) BroadcastClientOption {
+	return func(s *gravityBroadcastClient) {
+		s.claimsMetrics = m
+	}
+}
+
+// OptionClaimsTotals counts the claims sent in the persisted totals.
+func OptionClaimsTotals(t *totals.Totals) BroadcastClientOption {
+	return func(s *gravityBroadcastClient) {
+		s.claimsTotals = t
+	}
+}
+
+// eta extrapolates the time left to send the remaining claims from the time
+// taken by the ones already claimed.
+func (p claimsProgress) eta(now time.Time) time.Duration {
+	if p.claimed == 0 || p.claimed >= p.total {
+		return 0
+	}
+
+	perClaim := now.Sub(p.start) / time.Duration(p.claimed)
+	return perClaim * time.Duration(p.total-p.claimed)
+}
+
+// broadcastClaims sends the claims, sorted by nonce, in txs of up to msgsPerTx
+// claims. The Gravity module only accepts claims in nonce order, so a tx can
+// only be simulated once the previous ones were executed. The first tx is thus
+// sent and awaited, then the gas it used per claim, times claimsGasAdjustment,
+// is used to send the next ones without simulation, up to pipelineDepth txs at
+// a time. The gas per claim is raised whenever an executed tx used more. Any
+// failure ends the round: no other tx is sent, the ones in flight are awaited
+// and counted if executed, and the next round resumes after the last claim
+// executed.
+//
+// If a tx still doesn't fit in a block (e.g. after a long downtime there are
+// many heavy claims), the number of claims per tx is halved and the next tx is
+// simulated again, in nonce order, within the same round.
+func (s *gravityBroadcastClient) broadcastClaims(ctx context.Context, msgs []sdk.Msg) error {
+	var (
+		limit       = s.msgsPerTx
+		pending     = msgs
+		inFlight    []claimsInFlight
+		gasPerClaim uint64
+		progress    = claimsProgress{start: time.Now(), total: len(msgs)}
+	)
+
+	s.claimsMetrics.set(len(msgs), 0)
+
+	for len(pending) > 0 || len(inFlight) > 0 {
+		pipelined := s.pipelineDepth > 1 && gasPerClaim > 0
+
+		// Wait for the oldest tx if the pipeline is full, if there's nothing left
+		// to send or before a tx is simulated.
+		if len(inFlight) > 0 && (!pipelined || len(pending) == 0 || len(inFlight) >= s.pipelineDepth) {
+			oldest := inFlight[0]
+
+			txResponse, err := s.broadcastClient.WaitTx(ctx, oldest.txHash)
+			if err != nil {
+				s.logger.Err(err).Str("tx_hash", oldest.txHash).Msg("pipelined claims failed")
+				s.drainClaims(ctx, inFlight[1:], &progress)
+				return err
+			}
+
+			if used := gasUsedPerClaim(txResponse, oldest.claims); used > gasPerClaim {
+				gasPerClaim = used
+			}
+
+			inFlight = inFlight[1:]
+			progress.claimed += oldest.claims
+			s.claimsTotals.AddClaims(oldest.claims)
+			s.reportClaimsProgress(progress, oldest.txHash)
+
+			continue
+		}
+
+		n := limit
+		if n > len(pending) {
+			n = len(pending)
+		}
+		msgSet := pending[:n]
+
+		if !pipelined {
+			txResponse, err := s.broadcastClient.SyncBroadcastMsg(msgSet...)
+			if err != nil {
+				if n > 1 && isTxLimitError(err) {
+					limit = n / 2
+
+					s.logger.Warn().
+						Err(err).
+						Int("claims", n).
+						Int("new_claims_per_tx", limit).
+						Msg("claims don't fit in a single tx; splitting them")
+
+					continue
+				}
+
+				s.logger.Err(err).Msg("broadcasting multiple claims failed")
+				return err
+			}
+
+			pending = pending[n:]
+			progress.claimed += n
+			s.claimsTotals.AddClaims(n)
+			s.reportClaimsProgress(progress, txResponse.TxHash)
+
+			gasPerClaim = gasUsedPerClaim(txResponse, n)
+
+			continue
+		}
+
+		gas := uint64(math.Ceil(float64(gasPerClaim*uint64(n)) * s.claimsGasAdjustment))
+
+		txResponse, err := s.broadcastClient.BroadcastMsgWithGas(gas, msgSet...)
+		if err != nil {
+			if n > 1 && isTxLimitError(err) {
+				limit = n / 2
+				// the next tx is simulated again once the pipeline is drained
+				gasPerClaim = 0
+
+				s.logger.Warn().
+					Err(err).
+					Int("claims", n).
+					Int("new_claims_per_tx", limit).
+					Msg("claims don't fit in a single tx; splitting them")
+
+				continue
+			}
+
+			s.logger.Err(err).Msg("broadcasting pipelined claims failed")
+			s.drainClaims(ctx, inFlight, &progress)
+			return err
+		}
+
+		pending = pending[n:]
+		inFlight = append(inFlight, claimsInFlight{txHash: txResponse.TxHash, claims: n})
+	}
+
+	return nil
+}
+
+// drainClaims awaits the txs still in flight once a round failed, counting the
+// claims of those executed anyway.
+func (s *gravityBroadcastClient) drainClaims(
+	ctx context.Context,
+	inFlight []claimsInFlight,
+	progress *claimsProgress,
+) {
+	for _, tx := range inFlight {
+		if _, err := s.broadcastClient.WaitTx(ctx, tx.txHash); err != nil {
+			s.logger.Err(err).Str("tx_hash", tx.txHash).Msg("pipelined claims failed")
+			continue
+		}
+
+		progress.claimed += tx.claims
+		s.claimsTotals.AddClaims(tx.claims)
+		s.reportClaimsProgress(*progress, tx.txHash)
+	}
+}
+
+// gasUsedPerClaim returns the gas an executed tx of the given number of claims
+// used per claim, rounded up. It falls back to the gas wanted when the gas used
+// isn't reported.
+func gasUsedPerClaim(txResponse *sdk.TxResponse, claims int) uint64 {
+	if txResponse == nil || claims == 0 {
+		return 0
+	}
+
+	gas := txResponse.GasUsed
+	if gas <= 0 {
+		gas = txResponse.GasWanted
+	}
+	if gas <= 0 {
+		return 0
+	}
+
+	return (uint64(gas) + uint64(claims) - 1) / uint64(claims)
+}
+
+func (s *gravityBroadcastClient) reportClaimsProgress(progress claimsProgress, txHash string) {
+	eta := progress.eta(time.Now())
+	s.claimsMetrics.set(progress.total-progress.claimed, eta)
+
+	s.logger.Info().
+		Str("tx_hash", txHash).
+		Int("claims_pending", progress.total-progress.claimed).
+		Str("eta", eta.Round(time.Second).String()).
+		Msgf("claimed %d/%d", progress.claimed, progress.total)
+}
@@ -0,0 +1,187 @@
+package cosmos
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	reflection "github.com/cosmos/cosmos-sdk/server/grpc/reflection/v2alpha1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The umee major versions whose gravity module API this release was built and
+// tested against. The queries and txs are always encoded with the gravity module
+// types peggo is built with: older majors are refused, and newer ones are only
+// accepted if their reflection service shows the same gravity API.
+const (
+	MinUmeeMajor = 3
+	MaxUmeeMajor = 3
+)
+
+// gravityQueryService is the gravity module query service peggo relies on.
+const gravityQueryService = "gravity.v1.Query"
+
+// ErrUnsupportedNode is returned when the Cosmos node runs an application or
+// gravity module version this release can't talk to.
+var ErrUnsupportedNode = errors.New("unsupported cosmos node")
+
+// requiredMsgs are the gravity module messages peggo sends.
+var requiredMsgs = []sdk.Msg{
+	&types.MsgValsetConfirm{},
+	&types.MsgConfirmBatch{},
+	&types.MsgRequestBatch{},
+	&types.MsgSendToCosmosClaim{},
+	&types.MsgBatchSendToEthClaim{},
+	&types.MsgERC20DeployedClaim{},
+	&types.MsgValsetUpdatedClaim{},
+}
+
+// NodeVersion is the version of the application run by a Cosmos node.
+type NodeVersion struct {
+	AppName          string
+	Version          string
+	CosmosSDKVersion string
+
+	// Newer is set when the node runs an umee major above MaxUmeeMajor, which
+	// was accepted as it exposes the gravity API peggo uses.
+	Newer bool
+}
+
+// Handshake gets the application version of the Cosmos node and checks, using
+// its reflection service, that it exposes the gravity module query service and
+// messages peggo uses. It returns an ErrUnsupportedNode error naming the
+// supported umee versions otherwise, rather than letting queries and txs fail
+// later with cryptic unmarshal errors. It doesn't adapt the codecs to the node
+// version. The checks the node doesn't support (e.g. an unparsable version or
+// no reflection service) are skipped, except on a newer major, whose API can
+// only be accepted once checked.
+func Handshake(ctx context.Context, conn grpc.ClientConnInterface) (NodeVersion, error) {
+	info, err := tmservice.NewServiceClient(conn).GetNodeInfo(ctx, &tmservice.GetNodeInfoRequest{})
+	if err != nil {
+		return NodeVersion{}, fmt.Errorf("failed to get the cosmos node version: %w", err)
+	}
+
+	var version NodeVersion
+	if v := info.ApplicationVersion; v != nil {
+		version = NodeVersion{
+			AppName:          v.AppName,
+			Version:          v.Version,
+			CosmosSDKVersion: v.CosmosSdkVersion,
+		}
+	}
+
+	major, ok := majorVersion(version.Version)
+	if ok && major < MinUmeeMajor {
+		return version, version.unsupported("")
+	}
+	version.Newer = ok && major > MaxUmeeMajor
+
+	client := reflection.NewReflectionServiceClient(conn)
+
+	queries, err := client.GetQueryServicesDescriptor(ctx, &reflection.GetQueryServicesDescriptorRequest{})
+	switch {
+	case status.Code(err) == codes.Unimplemented && version.Newer:
+		return version, version.unsupported("no reflection service to check its gravity API with")
+
+	case status.Code(err) == codes.Unimplemented:
+		return version, nil
+
+	case err != nil:
+		return version, fmt.Errorf("failed to get the cosmos node query services: %w", err)
+	}
+
+	if !hasQueryService(queries.Queries, gravityQueryService) {
+		return version, version.unsupported(fmt.Sprintf("no %s service", gravityQueryService))
+	}
+
+	txs, err := client.GetTxDescriptor(ctx, &reflection.GetTxDescriptorRequest{})
+	if err != nil {
+		return version, fmt.Errorf("failed to get the cosmos node messages: %w", err)
+	}
+
+	if missing := missingMsgs(txs.Tx); len(missing) > 0 {
+		return version, version.unsupported("missing messages " + strings.Join(missing, ", "))
+	}
+
+	return version, nil
+}
+
+func (v NodeVersion) String() string {
+	s := strings.TrimSpace(v.AppName + " " + v.Version)
+	if s == "" {
+		s = "unknown"
+	}
+
+	if v.CosmosSDKVersion != "" {
+		s += " (cosmos-sdk " + v.CosmosSDKVersion + ")"
+	}
+
+	return s
+}
+
+func (v NodeVersion) unsupported(reason string) error {
+	supported := fmt.Sprintf("v%d", MinUmeeMajor)
+	if MaxUmeeMajor != MinUmeeMajor {
+		supported += fmt.Sprintf("–v%d", MaxUmeeMajor)
+	}
+
+	if reason != "" {
+		reason = "; " + reason
+	}
+
+	return fmt.Errorf(
+		"%w: this peggo release supports umee %s, the node runs %s%s",
+		ErrUnsupportedNode, supported, v, reason,
+	)
+}
+
+// majorVersion returns the major version of a version such as v3.3.0 or 3.3.0.
+func majorVersion(version string) (int, bool) {
+	major, _, _ := strings.Cut(strings.TrimPrefix(version, "v"), ".")
+
+	n, err := strconv.Atoi(major)
+	if err != nil {
+		return 0, false
+	}
+
+	return n, true
+}
+
+func hasQueryService(queries *reflection.QueryServicesDescriptor, name string) bool {
+	if queries == nil {
+		return false
+	}
+
+	for _, q := range queries.QueryServices {
+		if q.Fullname == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func missingMsgs(tx *reflection.TxDescriptor) []string {
+	supported := map[string]struct{}{}
+	if tx != nil {
+		for _, msg := range tx.Msgs {
+			supported[msg.MsgTypeUrl] = struct{}{}
+		}
+	}
+
+	var missing []string
+	for _, msg := range requiredMsgs {
+		if _, ok := supported[sdk.MsgTypeURL(msg)]; !ok {
+			missing = append(missing, sdk.MsgTypeURL(msg))
+		}
+	}
+
+	return missing
+}
@@ -0,0 +1,115 @@
+package cosmos
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	reflection "github.com/cosmos/cosmos-sdk/server/grpc/reflection/v2alpha1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeNodeConn struct {
+	version       string
+	noReflection  bool
+	queryServices []string
+	msgs          []sdk.Msg
+}
+
+func (c *fakeNodeConn) Invoke(_ context.Context, method string, _, reply interface{}, _ ...grpc.CallOption) error {
+	switch r := reply.(type) {
+	case *tmservice.GetNodeInfoResponse:
+		r.ApplicationVersion = &tmservice.VersionInfo{AppName: "umeed", Version: c.version}
+
+	case *reflection.GetQueryServicesDescriptorResponse:
+		if c.noReflection {
+			return status.Error(codes.Unimplemented, "unknown service")
+		}
+
+		r.Queries = &reflection.QueryServicesDescriptor{}
+		for _, name := range c.queryServices {
+			r.Queries.QueryServices = append(r.Queries.QueryServices, &reflection.QueryServiceDescriptor{Fullname: name})
+		}
+
+	case *reflection.GetTxDescriptorResponse:
+		r.Tx = &reflection.TxDescriptor{}
+		for _, msg := range c.msgs {
+			r.Tx.Msgs = append(r.Tx.Msgs, &reflection.MsgDescriptor{MsgTypeUrl: sdk.MsgTypeURL(msg)})
+		}
+
+	default:
+		return status.Errorf(codes.Unimplemented, "unexpected method %s", method)
+	}
+
+	return nil
+}
+
+func (c *fakeNodeConn) NewStream(
+	context.Context,
+	*grpc.StreamDesc,
+	string,
+	...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestHandshake(t *testing.T) {
+	ctx := context.Background()
+
+	conn := &fakeNodeConn{
+		version:       "v3.3.0",
+		queryServices: []string{"cosmos.bank.v1beta1.Query", gravityQueryService},
+		msgs:          requiredMsgs,
+	}
+
+	version, err := Handshake(ctx, conn)
+	require.NoError(t, err)
+	assert.Equal(t, "umeed v3.3.0", version.String())
+
+	assert.False(t, version.Newer)
+
+	// older major version
+	conn.version = "v2.0.0"
+	_, err = Handshake(ctx, conn)
+	assert.ErrorIs(t, err, ErrUnsupportedNode)
+	assert.Contains(t, err.Error(), "this peggo release supports umee v3")
+
+	// newer major version exposing the same gravity API
+	conn.version = "v5.0.0"
+	version, err = Handshake(ctx, conn)
+	require.NoError(t, err)
+	assert.True(t, version.Newer)
+
+	// whose API can't be checked
+	conn.noReflection = true
+	_, err = Handshake(ctx, conn)
+	assert.ErrorIs(t, err, ErrUnsupportedNode)
+	conn.noReflection = false
+
+	// unknown versions only rely on the reflection service
+	conn.version = "HEAD-abc123"
+	_, err = Handshake(ctx, conn)
+	assert.NoError(t, err)
+
+	// missing gravity messages
+	conn.msgs = requiredMsgs[1:]
+	_, err = Handshake(ctx, conn)
+	assert.ErrorIs(t, err, ErrUnsupportedNode)
+	assert.Contains(t, err.Error(), sdk.MsgTypeURL(requiredMsgs[0]))
+
+	// missing gravity query service
+	conn.queryServices = []string{"cosmos.bank.v1beta1.Query"}
+	_, err = Handshake(ctx, conn)
+	assert.ErrorIs(t, err, ErrUnsupportedNode)
+
+	// no reflection service
+	conn.noReflection = true
+	_, err = Handshake(ctx, conn)
+	assert.NoError(t, err)
+}
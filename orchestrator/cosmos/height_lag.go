@@ -0,0 +1,119 @@
+package cosmos
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+// ErrForked is returned when the primary and reference nodes have a different
+// block at the same height.
+var ErrForked = errors.New("cosmos node is on a different chain than the reference node")
+
+// HeightClient defines the Tendermint RPC methods used to compare the block
+// height and hash of two nodes.
+type HeightClient interface {
+	Status(ctx context.Context) (*ctypes.ResultStatus, error)
+	Block(ctx context.Context, height *int64) (*ctypes.ResultBlock, error)
+}
+
+// HeightLagChecker compares the Cosmos node the orchestrator is connected to
+// against a reference node, so claims aren't signed based on stale or forked
+// Cosmos state.
+type HeightLagChecker struct {
+	logger    zerolog.Logger
+	primary   HeightClient
+	reference HeightClient
+	maxLag    int64
+}
+
+// NewHeightLagChecker returns a checker allowing the primary node to be at most
+// maxLag blocks behind the reference one.
+func NewHeightLagChecker(
+	logger zerolog.Logger,
+	primary HeightClient,
+	reference HeightClient,
+	maxLag int64,
+) *HeightLagChecker {
+	return &HeightLagChecker{
+		logger:    logger.With().Str("module", "height_lag_checker").Logger(),
+		primary:   primary,
+		reference: reference,
+		maxLag:    maxLag,
+	}
+}
+
+// Check returns an error if the primary node is more than maxLag blocks behind
+// the reference node, or if their blocks differ at the latest common height.
+// The reference node being unreachable is only logged, so an outage of the
+// reference doesn't stop the orchestrator.
+func (c *HeightLagChecker) Check(ctx context.Context) error {
+	primaryStatus, err := c.primary.Status(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the cosmos node status")
+	}
+
+	referenceStatus, err := c.reference.Status(ctx)
+	if err != nil {
+		c.logger.Warn().Err(err).Msg("failed to get the reference node status; skipping height check")
+		return nil
+	}
+
+	height := primaryStatus.SyncInfo.LatestBlockHeight
+	referenceHeight := referenceStatus.SyncInfo.LatestBlockHeight
+
+	if lag := referenceHeight - height; lag > c.maxLag {
+		return fmt.Errorf(
+			"cosmos node is lagging %d blocks behind the reference node (height %d, reference height %d)",
+			lag, height, referenceHeight,
+		)
+	}
+
+	commonHeight := height
+	if referenceHeight < commonHeight {
+		commonHeight = referenceHeight
+	}
+
+	hash, err := blockHash(ctx, c.primary, primaryStatus, commonHeight)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the cosmos node block at height %d", commonHeight)
+	}
+
+	referenceHash, err := blockHash(ctx, c.reference, referenceStatus, commonHeight)
+	if err != nil {
+		c.logger.Warn().Err(err).Int64("height", commonHeight).
+			Msg("failed to get the reference node block; skipping fork check")
+		return nil
+	}
+
+	if !bytes.Equal(hash, referenceHash) {
+		return errors.Wrapf(ErrForked, "block %d is %s, reference block is %s", commonHeight, hash, referenceHash)
+	}
+
+	return nil
+}
+
+// blockHash returns the hash of the block at the given height, avoiding a query
+// if it is the latest block of the status.
+func blockHash(
+	ctx context.Context,
+	client HeightClient,
+	status *ctypes.ResultStatus,
+	height int64,
+) (tmbytes.HexBytes, error) {
+	if status.SyncInfo.LatestBlockHeight == height {
+		return status.SyncInfo.LatestBlockHash, nil
+	}
+
+	block, err := client.Block(ctx, &height)
+	if err != nil {
+		return nil, err
+	}
+
+	return block.BlockID.Hash, nil
+}
@@ -0,0 +1,115 @@
+package cosmos
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+type fakeHeightClient struct {
+	height int64
+	hashes map[int64]tmbytes.HexBytes
+	err    error
+}
+
+func (c *fakeHeightClient) Status(context.Context) (*ctypes.ResultStatus, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return &ctypes.ResultStatus{SyncInfo: ctypes.SyncInfo{
+		LatestBlockHeight: c.height,
+		LatestBlockHash:   c.hashes[c.height],
+	}}, nil
+}
+
+func (c *fakeHeightClient) Block(_ context.Context, height *int64) (*ctypes.ResultBlock, error) {
+	hash, ok := c.hashes[*height]
+	if !ok {
+		return nil, errors.New("block not found")
+	}
+
+	return &ctypes.ResultBlock{BlockID: tmtypes.BlockID{Hash: hash}}, nil
+}
+
+func TestHeightLagChecker(t *testing.T) {
+	chain := map[int64]tmbytes.HexBytes{
+		100: {0x01},
+		101: {0x02},
+		102: {0x03},
+		110: {0x04},
+	}
+	fork := map[int64]tmbytes.HexBytes{
+		100: {0x01},
+		101: {0x12},
+		102: {0x13},
+	}
+
+	testCases := []struct {
+		name      string
+		primary   *fakeHeightClient
+		reference *fakeHeightClient
+		err       string
+	}{
+		{
+			name:      "in sync",
+			primary:   &fakeHeightClient{height: 102, hashes: chain},
+			reference: &fakeHeightClient{height: 102, hashes: chain},
+		},
+		{
+			name:      "lagging within the limit",
+			primary:   &fakeHeightClient{height: 100, hashes: chain},
+			reference: &fakeHeightClient{height: 102, hashes: chain},
+		},
+		{
+			name:      "ahead of the reference",
+			primary:   &fakeHeightClient{height: 102, hashes: chain},
+			reference: &fakeHeightClient{height: 101, hashes: chain},
+		},
+		{
+			name:      "lagging",
+			primary:   &fakeHeightClient{height: 102, hashes: chain},
+			reference: &fakeHeightClient{height: 110, hashes: chain},
+			err:       "lagging 8 blocks",
+		},
+		{
+			name:      "forked",
+			primary:   &fakeHeightClient{height: 102, hashes: fork},
+			reference: &fakeHeightClient{height: 101, hashes: chain},
+			err:       ErrForked.Error(),
+		},
+		{
+			name:      "primary unreachable",
+			primary:   &fakeHeightClient{err: errors.New("connection refused")},
+			reference: &fakeHeightClient{height: 102, hashes: chain},
+			err:       "connection refused",
+		},
+		{
+			name:      "reference unreachable",
+			primary:   &fakeHeightClient{height: 102, hashes: chain},
+			reference: &fakeHeightClient{err: errors.New("connection refused")},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			checker := NewHeightLagChecker(zerolog.Nop(), tc.primary, tc.reference, 2)
+
+			err := checker.Check(context.Background())
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package orchestrator
+
+import "context"
+
+// CosmosHeightChecker checks that the Cosmos node the orchestrator relies on is
+// in sync, e.g. against a reference node.
+type CosmosHeightChecker interface {
+	Check(ctx context.Context) error
+}
+
+// SetCosmosHeightChecker returns the orchestrator option holding the Ethereum
+// claims while the checker fails.
+func SetCosmosHeightChecker(checker CosmosHeightChecker) func(GravityOrchestrator) {
+	return func(o GravityOrchestrator) { o.SetCosmosHeightChecker(checker) }
+}
+
+// SetCosmosHeightChecker sets the checker run before sending Ethereum claims;
+// no claim is sent while it fails. Nil disables it.
+func (p *gravityOrchestrator) SetCosmosHeightChecker(checker CosmosHeightChecker) {
+	p.cosmosHeightChecker = checker
+}
+
+// cosmosInSync returns whether claims can be sent based on the state of the
+// Cosmos node. Failures are logged.
+func (p *gravityOrchestrator) cosmosInSync(ctx context.Context) bool {
+	if p.cosmosHeightChecker == nil {
+		return true
+	}
+
+	if err := p.cosmosHeightChecker.Check(ctx); err != nil {
+		p.logger.Error().Err(err).Msg("refusing to send Ethereum claims; the Cosmos node is out of sync")
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,184 @@
+package crash
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// sendTimeout bounds the time spent shipping a report, as the process may be
+// about to exit.
+const sendTimeout = 5 * time.Second
+
+// Report levels.
+const (
+	LevelPanic = "panic"
+	LevelFatal = "fatal"
+)
+
+type (
+	// Report is a panic or fatal error, along with its stack trace and the
+	// context it happened in (e.g. the loop name and its nonce state).
+	Report struct {
+		Level   string            `json:"level"`
+		Message string            `json:"message"`
+		Stack   string            `json:"stack"`
+		Fields  map[string]string `json:"fields,omitempty"`
+		Time    time.Time         `json:"time"`
+	}
+
+	// Sink ships the reports somewhere they can be aggregated, e.g. Sentry.
+	Sink interface {
+		Send(ctx context.Context, report Report) error
+	}
+
+	// fields are the report fields of a context. They're shared with the
+	// contexts derived from it until new fields are added.
+	fields struct {
+		mtx    sync.RWMutex
+		values map[string]string
+	}
+
+	fieldsKey struct{}
+)
+
+var (
+	sinkMtx sync.RWMutex
+	sink    Sink
+)
+
+// SetSink sets the sink every report is shipped to. Reports are only logged
+// when it's nil.
+func SetSink(s Sink) {
+	sinkMtx.Lock()
+	defer sinkMtx.Unlock()
+
+	sink = s
+}
+
+// WithFields returns a context whose reports include the given key/value pairs
+// on top of the fields of ctx.
+func WithFields(ctx context.Context, keyvals ...string) context.Context {
+	f := &fields{values: Fields(ctx)}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		f.values[keyvals[i]] = keyvals[i+1]
+	}
+
+	return context.WithValue(ctx, fieldsKey{}, f)
+}
+
+// SetField sets a field of the reports of ctx, e.g. the last nonce processed
+// by a loop. It's a no-op if ctx has no fields, see WithFields.
+func SetField(ctx context.Context, key, value string) {
+	f, ok := ctx.Value(fieldsKey{}).(*fields)
+	if !ok {
+		return
+	}
+
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	f.values[key] = value
+}
+
+// Fields returns a copy of the report fields of ctx.
+func Fields(ctx context.Context) map[string]string {
+	values := map[string]string{}
+
+	f, ok := ctx.Value(fieldsKey{}).(*fields)
+	if !ok {
+		return values
+	}
+
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+
+	for k, v := range f.values {
+		values[k] = v
+	}
+
+	return values
+}
+
+// Recovered reports the value r returned by recover(), with the stack of the
+// calling goroutine and the fields of ctx.
+func Recovered(ctx context.Context, logger zerolog.Logger, r interface{}) Report {
+	report := Report{
+		Level:   LevelPanic,
+		Message: fmt.Sprint(r),
+		Stack:   string(debug.Stack()),
+		Fields:  Fields(ctx),
+		Time:    time.Now().UTC(),
+	}
+
+	send(logger, report)
+
+	return report
+}
+
+// Repanic reports a panic of the calling goroutine, if any, and panics again.
+// It must be deferred, e.g. at the start of a command, to report the panics
+// that aren't recovered anywhere else before the process crashes.
+func Repanic(ctx context.Context, logger zerolog.Logger) {
+	if r := recover(); r != nil {
+		Recovered(ctx, logger, r)
+		panic(r)
+	}
+}
+
+// Hook reports the fatal log events, which exit the process right after. The
+// fields of the log event itself aren't available to hooks, only its message.
+type Hook struct {
+	Logger zerolog.Logger
+}
+
+// Run implements zerolog.Hook.
+func (h Hook) Run(_ *zerolog.Event, level zerolog.Level, msg string) {
+	if level != zerolog.FatalLevel {
+		return
+	}
+
+	send(h.Logger, Report{
+		Level:   LevelFatal,
+		Message: msg,
+		Stack:   string(debug.Stack()),
+		Fields:  map[string]string{},
+		Time:    time.Now().UTC(),
+	})
+}
+
+func send(logger zerolog.Logger, report Report) {
+	keys := make([]string, 0, len(report.Fields))
+	for k := range report.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	event := logger.Error().
+		Str("crash_level", report.Level).
+		Str("stack", report.Stack)
+	for _, k := range keys {
+		event = event.Str(k, report.Fields[k])
+	}
+	event.Msg("crash: " + report.Message)
+
+	sinkMtx.RLock()
+	s := sink
+	sinkMtx.RUnlock()
+
+	if s == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	if err := s.Send(ctx, report); err != nil {
+		logger.Err(err).Msg("failed to ship crash report")
+	}
+}
@@ -0,0 +1,123 @@
+package crash
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSink struct {
+	reports []Report
+}
+
+func (m *mockSink) Send(_ context.Context, report Report) error {
+	m.reports = append(m.reports, report)
+	return nil
+}
+
+func TestFields(t *testing.T) {
+	ctx := WithFields(context.Background(), "loop", "RelayerMainLoop")
+	SetField(ctx, "valset_nonce", "12")
+
+	child := WithFields(ctx, "batch_nonce", "3")
+	SetField(child, "valset_nonce", "13")
+
+	assert.Equal(t, map[string]string{"loop": "RelayerMainLoop", "valset_nonce": "12"}, Fields(ctx))
+	assert.Equal(t, map[string]string{
+		"loop":         "RelayerMainLoop",
+		"valset_nonce": "13",
+		"batch_nonce":  "3",
+	}, Fields(child))
+
+	// no-op without fields
+	SetField(context.Background(), "loop", "none")
+	assert.Empty(t, Fields(context.Background()))
+}
+
+func TestRecovered(t *testing.T) {
+	sink := &mockSink{}
+	SetSink(sink)
+	defer SetSink(nil)
+
+	ctx := WithFields(context.Background(), "loop", "EthOracleMainLoop")
+
+	func() {
+		defer func() {
+			Recovered(ctx, zerolog.Nop(), recover())
+		}()
+
+		panic("boom")
+	}()
+
+	require.Len(t, sink.reports, 1)
+	assert.Equal(t, LevelPanic, sink.reports[0].Level)
+	assert.Equal(t, "boom", sink.reports[0].Message)
+	assert.Equal(t, map[string]string{"loop": "EthOracleMainLoop"}, sink.reports[0].Fields)
+	assert.Contains(t, sink.reports[0].Stack, "TestRecovered")
+
+	assert.PanicsWithValue(t, "again", func() {
+		defer Repanic(ctx, zerolog.Nop())
+		panic("again")
+	})
+	assert.Len(t, sink.reports, 2)
+
+	// only fatal log events are reported by the hook
+	hook := Hook{Logger: zerolog.Nop()}
+	hook.Run(nil, zerolog.ErrorLevel, "error")
+	hook.Run(nil, zerolog.FatalLevel, "fatal")
+
+	require.Len(t, sink.reports, 3)
+	assert.Equal(t, LevelFatal, sink.reports[2].Level)
+	assert.Equal(t, "fatal", sink.reports[2].Message)
+}
+
+func TestSentrySink(t *testing.T) {
+	var (
+		path, auth string
+		event      map[string]interface{}
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		auth = r.Header.Get("X-Sentry-Auth")
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+	}))
+	defer server.Close()
+
+	dsn := strings.Replace(server.URL, "://", "://key@", 1) + "/sentry/42"
+
+	sink, err := NewSentrySink(dsn, "v1.2.3")
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Send(context.Background(), Report{
+		Level:   LevelPanic,
+		Message: "boom",
+		Stack:   "stack",
+		Fields:  map[string]string{"loop": "RelayerMainLoop"},
+	}))
+
+	assert.Equal(t, "/sentry/api/42/store/", path)
+	assert.Contains(t, auth, "sentry_key=key")
+	assert.Contains(t, auth, "sentry_client=peggo/v1.2.3")
+	assert.Equal(t, "boom", event["message"])
+	assert.Equal(t, "fatal", event["level"])
+	assert.Equal(t, "v1.2.3", event["release"])
+	assert.Equal(t, map[string]interface{}{"loop": "RelayerMainLoop"}, event["tags"])
+
+	for _, invalid := range []string{
+		"not a dsn",
+		"ftp://key@sentry.example.com/42",
+		"https://sentry.example.com/42",
+		"https://key@sentry.example.com/",
+	} {
+		_, err := NewSentrySink(invalid, "")
+		assert.Error(t, err, invalid)
+	}
+}
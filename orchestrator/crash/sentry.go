@@ -0,0 +1,125 @@
+package crash
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// SentrySink ships the reports to the store endpoint of a Sentry-compatible
+// server (e.g. Sentry or GlitchTip).
+type SentrySink struct {
+	client   *http.Client
+	endpoint string
+	auth     string
+	release  string
+}
+
+type sentryEvent struct {
+	EventID   string            `json:"event_id"`
+	Timestamp string            `json:"timestamp"`
+	Level     string            `json:"level"`
+	Platform  string            `json:"platform"`
+	Logger    string            `json:"logger"`
+	Release   string            `json:"release,omitempty"`
+	Message   string            `json:"message"`
+	Tags      map[string]string `json:"tags,omitempty"`
+	Extra     map[string]string `json:"extra"`
+}
+
+// NewSentrySink returns a sink for the given DSN, in the
+// {scheme}://{public_key}@{host}/{path}{project_id} format. The release is
+// attached to every event.
+func NewSentrySink(dsn, release string) (*SentrySink, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sentry DSN: %w", err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid sentry DSN scheme %q", u.Scheme)
+	}
+
+	if u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("invalid sentry DSN: missing public key")
+	}
+
+	dir, project := path.Split(strings.TrimSuffix(u.Path, "/"))
+	if project == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid sentry DSN: missing host or project ID")
+	}
+
+	endpoint := url.URL{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+		Path:   path.Join(dir, "api", project, "store") + "/",
+	}
+
+	return &SentrySink{
+		client:   &http.Client{},
+		endpoint: endpoint.String(),
+		auth: fmt.Sprintf(
+			"Sentry sentry_version=7, sentry_client=peggo/%s, sentry_key=%s",
+			release, u.User.Username(),
+		),
+		release: release,
+	}, nil
+}
+
+// Send implements Sink.
+func (s *SentrySink) Send(ctx context.Context, report Report) error {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+
+	level := "error"
+	if report.Level == LevelFatal || report.Level == LevelPanic {
+		level = "fatal"
+	}
+
+	body, err := json.Marshal(sentryEvent{
+		EventID:   hex.EncodeToString(id),
+		Timestamp: report.Time.Format("2006-01-02T15:04:05"),
+		Level:     level,
+		Platform:  "go",
+		Logger:    "peggo",
+		Release:   s.release,
+		Message:   report.Message,
+		Tags:      report.Fields,
+		Extra: map[string]string{
+			"crash_level": report.Level,
+			"stack":       report.Stack,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected sentry response status: %s", resp.Status)
+	}
+
+	return nil
+}
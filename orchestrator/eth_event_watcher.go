@@ -33,192 +33,246 @@ func (p *gravityOrchestrator) CheckForEvents(
 		return currentBlock, nil
 	}
 
+	// the events are scanned again from the same block once the node is in sync
+	if !p.cosmosInSync(ctx) {
+		return startingBlock, nil
+	}
+
 	if (currentBlock - startingBlock) > p.ethBlocksPerLoop {
 		currentBlock = startingBlock + p.ethBlocksPerLoop
 	}
 
-	gravityFilterer, err := wrappers.NewGravityFilterer(p.gravityContract.Address(), p.ethProvider)
+	ranges, err := p.contractRanges(ctx, startingBlock, currentBlock)
 	if err != nil {
-		err = errors.Wrap(err, "failed to init Gravity events filterer")
 		return 0, err
 	}
 
-	var erc20DeployedEvents []*wrappers.GravityERC20DeployedEvent
+	var (
+		erc20DeployedEvents            []*wrappers.GravityERC20DeployedEvent
+		sendToCosmosEvents             []*wrappers.GravitySendToCosmosEvent
+		transactionBatchExecutedEvents []*wrappers.GravityTransactionBatchExecutedEvent
+		valsetUpdatedEvents            []*wrappers.GravityValsetUpdatedEvent
+	)
+
+	for _, r := range ranges {
+		events, err := p.scanEvents(r)
+		if err != nil {
+			return 0, err
+		}
+
+		erc20DeployedEvents = append(erc20DeployedEvents, events.erc20Deployed...)
+		sendToCosmosEvents = append(sendToCosmosEvents, events.sendToCosmos...)
+		transactionBatchExecutedEvents = append(transactionBatchExecutedEvents, events.transactionBatchExecuted...)
+		valsetUpdatedEvents = append(valsetUpdatedEvents, events.valsetUpdated...)
+	}
+
+	// note that starting block overlaps with our last checked block, because we have to deal with
+	// the possibility that the relayer was killed after relaying only one of multiple events in a single
+	// block, so we also need this routine so make sure we don't send in the first event in this hypothetical
+	// multi event block again. In theory we only send all events for every block and that will pass of fail
+	// atomically but lets not take that risk.
+	lastEventResp, err := p.cosmosQueryClient.LastEventNonceByAddr(ctx, &types.QueryLastEventNonceByAddrRequest{
+		Address: p.gravityBroadcastClient.AccFromAddress().String(),
+	})
+
+	if err != nil {
+		err = errors.New("failed to query last claim event from backend")
+		return 0, err
+	}
+
+	if lastEventResp == nil {
+		return 0, errors.New("no last event response returned")
+	}
+
+	lastEventNonce := p.lastEventNonce(lastEventResp.EventNonce)
+
+	deposits := filterSendToCosmosEventsByNonce(sendToCosmosEvents, lastEventNonce)
+	withdraws := filterTransactionBatchExecutedEventsByNonce(
+		transactionBatchExecutedEvents,
+		lastEventNonce,
+	)
+	valsetUpdates := filterValsetUpdateEventsByNonce(valsetUpdatedEvents, lastEventNonce)
+	deployedERC20Updates := filterERC20DeployedEventsByNonce(erc20DeployedEvents, lastEventNonce)
+
+	if len(deposits) > 0 || len(withdraws) > 0 || len(valsetUpdates) > 0 || len(deployedERC20Updates) > 0 {
+
+		if err := p.gravityBroadcastClient.SendEthereumClaims(
+			ctx,
+			lastEventNonce,
+			deposits,
+			withdraws,
+			valsetUpdates,
+			deployedERC20Updates,
+			p.cosmosBlockTime,
+		); err != nil {
+			err = errors.Wrap(err, "failed to send ethereum claims to Cosmos chain")
+			return 0, err
+		}
+	}
+
+	return currentBlock, nil
+}
+
+// ethEvents are the events of a Gravity contract claimed on Cosmos.
+type ethEvents struct {
+	erc20Deployed            []*wrappers.GravityERC20DeployedEvent
+	sendToCosmos             []*wrappers.GravitySendToCosmosEvent
+	transactionBatchExecuted []*wrappers.GravityTransactionBatchExecutedEvent
+	valsetUpdated            []*wrappers.GravityValsetUpdatedEvent
+}
+
+// scanEvents returns the events of the range's contract within its blocks, with
+// their nonces shifted by the range offset.
+func (p *gravityOrchestrator) scanEvents(r contractRange) (ethEvents, error) {
+	gravityFilterer, err := wrappers.NewGravityFilterer(r.address, p.ethProvider)
+	if err != nil {
+		err = errors.Wrap(err, "failed to init Gravity events filterer")
+		return ethEvents{}, err
+	}
+
+	var events ethEvents
 	{
 		iter, err := gravityFilterer.FilterERC20DeployedEvent(&bind.FilterOpts{
-			Start: startingBlock,
-			End:   &currentBlock,
+			Start: r.start,
+			End:   &r.end,
 		}, nil)
 		if err != nil {
 			p.logger.Err(err).
-				Uint64("start", startingBlock).
-				Uint64("end", currentBlock).
+				Uint64("start", r.start).
+				Uint64("end", r.end).
 				Msg("failed to scan past ERC20Deployed events from Ethereum")
 
 			if !isUnknownBlockErr(err) {
 				err = errors.Wrap(err, "failed to scan past ERC20Deployed events from Ethereum")
-				return 0, err
+				return ethEvents{}, err
 			} else if iter == nil {
-				return 0, errors.New("no iterator returned")
+				return ethEvents{}, errors.New("no iterator returned")
 			}
 		}
 
 		for iter.Next() {
-			erc20DeployedEvents = append(erc20DeployedEvents, iter.Event)
+			if nonce, ok := r.eventNonce(iter.Event.EventNonce); ok {
+				iter.Event.EventNonce = nonce
+				events.erc20Deployed = append(events.erc20Deployed, iter.Event)
+			}
 		}
 
 		iter.Close()
 	}
 
 	p.logger.Debug().
-		Uint64("start", startingBlock).
-		Uint64("end", currentBlock).
-		Int("num_events", len(erc20DeployedEvents)).
+		Uint64("start", r.start).
+		Uint64("end", r.end).
+		Int("num_events", len(events.erc20Deployed)).
 		Msg("scanned ERC20Deployed events from Ethereum")
 
-	var sendToCosmosEvents []*wrappers.GravitySendToCosmosEvent
 	{
 
 		iter, err := gravityFilterer.FilterSendToCosmosEvent(&bind.FilterOpts{
-			Start: startingBlock,
-			End:   &currentBlock,
+			Start: r.start,
+			End:   &r.end,
 		}, nil, nil)
 		if err != nil {
 			p.logger.Err(err).
-				Uint64("start", startingBlock).
-				Uint64("end", currentBlock).
+				Uint64("start", r.start).
+				Uint64("end", r.end).
 				Msg("failed to scan past SendToCosmos events from Ethereum")
 
 			if !isUnknownBlockErr(err) {
 				err = errors.Wrap(err, "failed to scan past SendToCosmos events from Ethereum")
-				return 0, err
+				return ethEvents{}, err
 			} else if iter == nil {
-				return 0, errors.New("no iterator returned")
+				return ethEvents{}, errors.New("no iterator returned")
 			}
 		}
 
 		for iter.Next() {
-			sendToCosmosEvents = append(sendToCosmosEvents, iter.Event)
+			if nonce, ok := r.eventNonce(iter.Event.EventNonce); ok {
+				iter.Event.EventNonce = nonce
+				events.sendToCosmos = append(events.sendToCosmos, iter.Event)
+			}
 		}
 
 		iter.Close()
 	}
 
 	p.logger.Debug().
-		Uint64("start", startingBlock).
-		Uint64("end", currentBlock).
-		Int("num_events", len(sendToCosmosEvents)).
+		Uint64("start", r.start).
+		Uint64("end", r.end).
+		Int("num_events", len(events.sendToCosmos)).
 		Msg("scanned SendToCosmos events from Ethereum")
 
-	var transactionBatchExecutedEvents []*wrappers.GravityTransactionBatchExecutedEvent
 	{
 		iter, err := gravityFilterer.FilterTransactionBatchExecutedEvent(&bind.FilterOpts{
-			Start: startingBlock,
-			End:   &currentBlock,
+			Start: r.start,
+			End:   &r.end,
 		}, nil, nil)
 		if err != nil {
 			p.logger.Err(err).
-				Uint64("start", startingBlock).
-				Uint64("end", currentBlock).
+				Uint64("start", r.start).
+				Uint64("end", r.end).
 				Msg("failed to scan past TransactionBatchExecuted events from Ethereum")
 
 			if !isUnknownBlockErr(err) {
 				err = errors.Wrap(err, "failed to scan past TransactionBatchExecuted events from Ethereum")
-				return 0, err
+				return ethEvents{}, err
 			} else if iter == nil {
-				return 0, errors.New("no iterator returned")
+				return ethEvents{}, errors.New("no iterator returned")
 			}
 		}
 
 		for iter.Next() {
-			transactionBatchExecutedEvents = append(transactionBatchExecutedEvents, iter.Event)
+			if nonce, ok := r.eventNonce(iter.Event.EventNonce); ok {
+				iter.Event.EventNonce = nonce
+				events.transactionBatchExecuted = append(events.transactionBatchExecuted, iter.Event)
+			}
 		}
 
 		iter.Close()
 	}
 
 	p.logger.Debug().
-		Uint64("start", startingBlock).
-		Uint64("end", currentBlock).
-		Int("num_events", len(transactionBatchExecutedEvents)).
+		Uint64("start", r.start).
+		Uint64("end", r.end).
+		Int("num_events", len(events.transactionBatchExecuted)).
 		Msg("scanned TransactionBatchExecuted events from Ethereum")
 
-	var valsetUpdatedEvents []*wrappers.GravityValsetUpdatedEvent
 	{
 		iter, err := gravityFilterer.FilterValsetUpdatedEvent(&bind.FilterOpts{
-			Start: startingBlock,
-			End:   &currentBlock,
+			Start: r.start,
+			End:   &r.end,
 		}, nil)
 		if err != nil {
 			p.logger.Err(err).
-				Uint64("start", startingBlock).
-				Uint64("end", currentBlock).
+				Uint64("start", r.start).
+				Uint64("end", r.end).
 				Msg("failed to scan past ValsetUpdatedEvent events from Ethereum")
 
 			if !isUnknownBlockErr(err) {
 				err = errors.Wrap(err, "failed to scan past ValsetUpdatedEvent events from Ethereum")
-				return 0, err
+				return ethEvents{}, err
 			} else if iter == nil {
-				return 0, errors.New("no iterator returned")
+				return ethEvents{}, errors.New("no iterator returned")
 			}
 		}
 
 		for iter.Next() {
-			valsetUpdatedEvents = append(valsetUpdatedEvents, iter.Event)
+			if nonce, ok := r.eventNonce(iter.Event.EventNonce); ok {
+				iter.Event.EventNonce = nonce
+				events.valsetUpdated = append(events.valsetUpdated, iter.Event)
+			}
 		}
 
 		iter.Close()
 	}
 
 	p.logger.Debug().
-		Uint64("start", startingBlock).
-		Uint64("end", currentBlock).
-		Int("num_events", len(valsetUpdatedEvents)).
+		Uint64("start", r.start).
+		Uint64("end", r.end).
+		Int("num_events", len(events.valsetUpdated)).
 		Msg("scanned ValsetUpdatedEvents events from Ethereum")
 
-	// note that starting block overlaps with our last checked block, because we have to deal with
-	// the possibility that the relayer was killed after relaying only one of multiple events in a single
-	// block, so we also need this routine so make sure we don't send in the first event in this hypothetical
-	// multi event block again. In theory we only send all events for every block and that will pass of fail
-	// atomically but lets not take that risk.
-	lastEventResp, err := p.cosmosQueryClient.LastEventNonceByAddr(ctx, &types.QueryLastEventNonceByAddrRequest{
-		Address: p.gravityBroadcastClient.AccFromAddress().String(),
-	})
-
-	if err != nil {
-		err = errors.New("failed to query last claim event from backend")
-		return 0, err
-	}
-
-	if lastEventResp == nil {
-		return 0, errors.New("no last event response returned")
-	}
-
-	deposits := filterSendToCosmosEventsByNonce(sendToCosmosEvents, lastEventResp.EventNonce)
-	withdraws := filterTransactionBatchExecutedEventsByNonce(
-		transactionBatchExecutedEvents,
-		lastEventResp.EventNonce,
-	)
-	valsetUpdates := filterValsetUpdateEventsByNonce(valsetUpdatedEvents, lastEventResp.EventNonce)
-	deployedERC20Updates := filterERC20DeployedEventsByNonce(erc20DeployedEvents, lastEventResp.EventNonce)
-
-	if len(deposits) > 0 || len(withdraws) > 0 || len(valsetUpdates) > 0 || len(deployedERC20Updates) > 0 {
-
-		if err := p.gravityBroadcastClient.SendEthereumClaims(
-			ctx,
-			lastEventResp.EventNonce,
-			deposits,
-			withdraws,
-			valsetUpdates,
-			deployedERC20Updates,
-			p.cosmosBlockTime,
-		); err != nil {
-			err = errors.Wrap(err, "failed to send ethereum claims to Cosmos chain")
-			return 0, err
-		}
-	}
-
-	return currentBlock, nil
+	return events, nil
 }
 
 func filterSendToCosmosEventsByNonce(
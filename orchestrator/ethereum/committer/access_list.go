@@ -0,0 +1,110 @@
+package committer
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// maxAccessLists bounds the access lists kept between EstimateGas and SendTx,
+// since most estimated txs (e.g. unprofitable batches) are never sent.
+const maxAccessLists = 64
+
+// estimateWithAccessList estimates the gas used by msg with an EIP-2930 access
+// list. If the access list makes the tx cheaper than gasCost, it's kept for
+// SendTx and the lower gas is returned, so the savings are accounted for when
+// checking the tx profitability. Otherwise gasCost is returned.
+func (e *ethCommitter) estimateWithAccessList(ctx context.Context, msg ethereum.CallMsg, gasCost uint64) uint64 {
+	key := accessListKey(*msg.To, msg.Data)
+	e.storeAccessList(key, nil)
+
+	accessList, gasUsed, err := e.evmProvider.CreateAccessList(ctx, msg)
+	if err != nil {
+		e.logger.Debug().Err(err).Msg("failed to create access list; estimating without it")
+		return gasCost
+	}
+
+	if accessList == nil || len(*accessList) == 0 || gasUsed >= gasCost {
+		return gasCost
+	}
+
+	msg.AccessList = *accessList
+	accessListGasCost, err := e.evmProvider.EstimateGas(ctx, msg)
+	if err != nil {
+		e.logger.Debug().Err(err).Msg("failed to estimate gas with access list; estimating without it")
+		return gasCost
+	}
+
+	if accessListGasCost >= gasCost {
+		return gasCost
+	}
+
+	e.logger.Debug().
+		Uint64("gas_cost", accessListGasCost).
+		Uint64("gas_saved", gasCost-accessListGasCost).
+		Msg("using access list")
+
+	e.storeAccessList(key, *accessList)
+
+	return accessListGasCost
+}
+
+// newTx returns a legacy tx, or an EIP-2930 one if an access list was kept for
+// the recipient and data when estimating its gas.
+func (e *ethCommitter) newTx(
+	nonce uint64,
+	recipient ethcmn.Address,
+	gasLimit uint64,
+	gasPrice *big.Int,
+	txData []byte,
+	accessList types.AccessList,
+) *types.Transaction {
+	if accessList == nil {
+		return types.NewTransaction(nonce, recipient, nil, gasLimit, gasPrice, txData)
+	}
+
+	return types.NewTx(&types.AccessListTx{
+		ChainID:    e.committerOpts.ChainID,
+		Nonce:      nonce,
+		GasPrice:   gasPrice,
+		Gas:        gasLimit,
+		To:         &recipient,
+		Data:       txData,
+		AccessList: accessList,
+	})
+}
+
+func (e *ethCommitter) accessList(recipient ethcmn.Address, txData []byte) types.AccessList {
+	if e.committerOpts.ChainID == nil {
+		return nil
+	}
+
+	e.accessListsMtx.Lock()
+	defer e.accessListsMtx.Unlock()
+
+	return e.accessLists[accessListKey(recipient, txData)]
+}
+
+func (e *ethCommitter) storeAccessList(key ethcmn.Hash, accessList types.AccessList) {
+	e.accessListsMtx.Lock()
+	defer e.accessListsMtx.Unlock()
+
+	if accessList == nil {
+		delete(e.accessLists, key)
+		return
+	}
+
+	if e.accessLists == nil || len(e.accessLists) >= maxAccessLists {
+		e.accessLists = make(map[ethcmn.Hash]types.AccessList)
+	}
+
+	e.accessLists[key] = accessList
+}
+
+func accessListKey(recipient ethcmn.Address, txData []byte) ethcmn.Hash {
+	return crypto.Keccak256Hash(recipient.Bytes(), txData)
+}
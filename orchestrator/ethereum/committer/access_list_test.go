@@ -0,0 +1,89 @@
+package committer
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umee-network/peggo/mocks"
+)
+
+func TestAccessLists(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := context.Background()
+	gravityAddress := ethcmn.HexToAddress("0x1")
+	txData := []byte{1, 2, 3}
+	accessList := types.AccessList{{
+		Address:     ethcmn.HexToAddress("0x2"),
+		StorageKeys: []ethcmn.Hash{ethcmn.HexToHash("0x3")},
+	}}
+
+	mockEvmProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+	mockEvmProvider.EXPECT().PendingNonceAt(gomock.Any(), ethcmn.Address{}).Return(uint64(0), nil)
+	mockEvmProvider.EXPECT().SuggestGasPrice(gomock.Any()).Return(big.NewInt(100), nil).Times(2)
+	mockEvmProvider.EXPECT().CreateAccessList(gomock.Any(), gomock.Any()).Return(&accessList, uint64(90000), nil).Times(2)
+
+	var sent []*types.Transaction
+	signer := func(_ ethcmn.Address, tx *types.Transaction) (*types.Transaction, error) {
+		sent = append(sent, tx)
+		return tx, nil
+	}
+
+	ethCommitter, err := NewEthCommitter(
+		zerolog.Nop(),
+		ethcmn.Address{},
+		1.0,
+		1.0,
+		signer,
+		mockEvmProvider,
+		OptionAccessLists(1),
+	)
+	require.NoError(t, err)
+
+	// the access list makes the tx cheaper
+	gomock.InOrder(
+		mockEvmProvider.EXPECT().EstimateGas(gomock.Any(), gomock.Any()).Return(uint64(100000), nil),
+		mockEvmProvider.EXPECT().EstimateGas(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, msg ethereum.CallMsg) (uint64, error) {
+				assert.Equal(t, accessList, msg.AccessList)
+				return 95000, nil
+			}),
+	)
+
+	gasCost, _, err := ethCommitter.EstimateGas(ctx, gravityAddress, txData)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(95000), gasCost)
+
+	mockEvmProvider.EXPECT().SendTransactionWithRet(gomock.Any(), gomock.Any()).
+		Return(ethcmn.HexToHash("0x4"), nil).
+		Times(2)
+
+	_, err = ethCommitter.SendTx(ctx, gravityAddress, txData, gasCost, big.NewInt(100))
+	require.NoError(t, err)
+	require.Len(t, sent, 1)
+	assert.Equal(t, uint8(types.AccessListTxType), sent[0].Type())
+	assert.Equal(t, accessList, sent[0].AccessList())
+	assert.Equal(t, big.NewInt(1), sent[0].ChainId())
+
+	// the access list makes the tx more expensive
+	mockEvmProvider.EXPECT().EstimateGas(gomock.Any(), gomock.Any()).Return(uint64(80000), nil)
+
+	gasCost, _, err = ethCommitter.EstimateGas(ctx, gravityAddress, txData)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(80000), gasCost)
+
+	_, err = ethCommitter.SendTx(ctx, gravityAddress, txData, gasCost, big.NewInt(100))
+	require.NoError(t, err)
+	require.Len(t, sent, 2)
+	assert.Equal(t, uint8(types.LegacyTxType), sent[1].Type())
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/shopspring/decimal"
 
 	"github.com/umee-network/peggo/orchestrator/ethereum/provider"
+	"github.com/umee-network/peggo/orchestrator/safemode"
 )
 
 // EVMCommitter defines an interface for submitting transactions
@@ -35,9 +36,12 @@ type EVMCommitter interface {
 type EVMCommitterOption func(o *options) error
 
 type options struct {
-	GasPrice   decimal.Decimal
-	GasLimit   uint64
-	RPCTimeout time.Duration
+	GasPrice       decimal.Decimal
+	GasLimit       uint64
+	RPCTimeout     time.Duration
+	MaxInFlightTxs int
+	ChainID        *big.Int
+	Journal        *safemode.Journal
 }
 
 func defaultOptions() *options {
@@ -94,9 +98,47 @@ func OptionGasLimit(limit uint64) EVMCommitterOption {
 	}
 }
 
+// TxBroadcastTimeout sets the timeout for broadcasting a transaction. Zero means
+// no timeout.
 func TxBroadcastTimeout(dur time.Duration) EVMCommitterOption {
 	return func(o *options) error {
 		o.RPCTimeout = dur
 		return nil
 	}
 }
+
+// OptionMaxInFlightTxs limits the number of sent transactions that can be waiting
+// to be mined at the same time. Zero means no limit.
+func OptionMaxInFlightTxs(limit int) EVMCommitterOption {
+	return func(o *options) error {
+		if limit < 0 {
+			return errors.Errorf("invalid max in-flight txs: %d", limit)
+		}
+
+		o.MaxInFlightTxs = limit
+		return nil
+	}
+}
+
+// OptionAccessLists makes the committer estimate the gas of transactions with an
+// EIP-2930 access list as well, and send them with it when it makes them
+// cheaper. The chain ID is needed to sign the typed transactions.
+func OptionAccessLists(chainID uint64) EVMCommitterOption {
+	return func(o *options) error {
+		if chainID == 0 {
+			return errors.New("invalid chain ID for access lists: 0")
+		}
+
+		o.ChainID = new(big.Int).SetUint64(chainID)
+		return nil
+	}
+}
+
+// OptionTxJournal records the sent transactions in the given journal until
+// they're seen mined, so they can be resolved on startup after a crash.
+func OptionTxJournal(journal *safemode.Journal) EVMCommitterOption {
+	return func(o *options) error {
+		o.Journal = journal
+		return nil
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"math/big"
 	"strings"
+	"sync"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -13,6 +14,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/umee-network/peggo/orchestrator/ethereum/provider"
 	"github.com/umee-network/peggo/orchestrator/ethereum/util"
+	"github.com/umee-network/peggo/orchestrator/safemode"
 )
 
 // NewEthCommitter returns an instance of EVMCommitter, which
@@ -60,8 +62,19 @@ type ethCommitter struct {
 	ethGasLimitAdjustment float64
 	evmProvider           provider.EVMProviderWithRet
 	nonceCache            util.NonceCache
+
+	inFlightMtx      sync.Mutex
+	inFlightTxs      []ethcmn.Hash
+	inFlightReserved int // slots reserved by the txs being signed and sent
+
+	accessListsMtx sync.Mutex
+	accessLists    map[ethcmn.Hash]types.AccessList
 }
 
+// ErrMaxInFlightTxs is returned by SendTx when the amount of sent transactions
+// that were not mined yet reached the configured limit.
+var ErrMaxInFlightTxs = errors.New("max in-flight transactions reached")
+
 func (e *ethCommitter) FromAddress() ethcmn.Address {
 	return e.fromAddress
 }
@@ -102,6 +115,9 @@ func (e *ethCommitter) EstimateGas(
 	msg := ethereum.CallMsg{From: opts.From, To: &recipient, GasPrice: gasPrice, Value: nil, Data: txData}
 
 	gasCost, err = e.evmProvider.EstimateGas(ctx, msg)
+	if err == nil && e.committerOpts.ChainID != nil {
+		gasCost = e.estimateWithAccessList(ctx, msg, gasCost)
+	}
 
 	// Estimated gas cost may not be accurate, so we multiply the result by the gas limit adjustment factor.
 	gasCost = uint64(float64(gasCost) * e.ethGasLimitAdjustment)
@@ -116,6 +132,10 @@ func (e *ethCommitter) SendTx(
 	gasCost uint64,
 	gasPrice *big.Int,
 ) (txHash ethcmn.Hash, err error) {
+	if err := e.reserveInFlightTx(ctx); err != nil {
+		return ethcmn.Hash{}, err
+	}
+
 	opts := &bind.TransactOpts{
 		From:   e.fromAddress,
 		Signer: e.fromSigner,
@@ -125,6 +145,8 @@ func (e *ethCommitter) SendTx(
 		Context:  ctx, // with RPC timeout
 	}
 
+	accessList := e.accessList(recipient, txData)
+
 	resyncNonces := func(from ethcmn.Address) {
 		e.nonceCache.Sync(from, func() (uint64, error) {
 			nonce, err := e.evmProvider.PendingNonceAt(context.TODO(), from)
@@ -142,11 +164,14 @@ func (e *ethCommitter) SendTx(
 
 		for {
 			opts.Nonce = big.NewInt(nonce)
-			var cancel context.CancelFunc
-			opts.Context, cancel = context.WithTimeout(ctx, e.committerOpts.RPCTimeout)
-			defer cancel()
+			opts.Context = ctx
+			if e.committerOpts.RPCTimeout > 0 {
+				var cancel context.CancelFunc
+				opts.Context, cancel = context.WithTimeout(ctx, e.committerOpts.RPCTimeout)
+				defer cancel()
+			}
 
-			tx := types.NewTransaction(opts.Nonce.Uint64(), recipient, nil, opts.GasLimit, opts.GasPrice, txData)
+			tx := e.newTx(opts.Nonce.Uint64(), recipient, opts.GasLimit, opts.GasPrice, txData, accessList)
 			signedTx, err := opts.Signer(opts.From, tx)
 			if err != nil {
 				err := errors.Wrap(err, "failed to sign transaction")
@@ -213,8 +238,100 @@ func (e *ethCommitter) SendTx(
 			}
 		}
 	}); err != nil {
+		e.releaseInFlightTx(nil)
 		return ethcmn.Hash{}, err
 	}
 
+	if journal := e.committerOpts.Journal; journal != nil {
+		journal.Add(safemode.Tx{Chain: safemode.ChainEthereum, Hash: txHash.Hex(), Nonce: opts.Nonce.Uint64()})
+	}
+
+	e.releaseInFlightTx(&txHash)
+
 	return txHash, nil
 }
+
+// reserveInFlightTx drops the tracked transactions that were already mined or
+// dropped from the mempool, then reserves a slot for the one about to be sent,
+// or returns ErrMaxInFlightTxs if the remaining ones and the other reserved
+// slots still reach the configured limit. The slot is held until
+// releaseInFlightTx, so concurrent senders can't go over the limit.
+func (e *ethCommitter) reserveInFlightTx(ctx context.Context) error {
+	limit := e.committerOpts.MaxInFlightTxs
+	if limit == 0 {
+		return nil
+	}
+
+	settled := e.settledInFlightTxs(ctx)
+
+	e.inFlightMtx.Lock()
+	defer e.inFlightMtx.Unlock()
+
+	pending := e.inFlightTxs[:0]
+	for _, txHash := range e.inFlightTxs {
+		if _, ok := settled[txHash]; !ok {
+			pending = append(pending, txHash)
+		}
+	}
+	e.inFlightTxs = pending
+
+	if len(e.inFlightTxs)+e.inFlightReserved >= limit {
+		return errors.Wrapf(
+			ErrMaxInFlightTxs,
+			"%d txs waiting to be mined, %d being sent",
+			len(e.inFlightTxs), e.inFlightReserved,
+		)
+	}
+
+	e.inFlightReserved++
+
+	return nil
+}
+
+// settledInFlightTxs returns the tracked transactions that were mined or are no
+// longer known by the node. The node is queried without holding the lock.
+func (e *ethCommitter) settledInFlightTxs(ctx context.Context) map[ethcmn.Hash]struct{} {
+	e.inFlightMtx.Lock()
+	tracked := make([]ethcmn.Hash, len(e.inFlightTxs))
+	copy(tracked, e.inFlightTxs)
+	e.inFlightMtx.Unlock()
+
+	settled := make(map[ethcmn.Hash]struct{})
+	for _, txHash := range tracked {
+		if receipt, err := e.evmProvider.TransactionReceipt(ctx, txHash); err == nil && receipt != nil {
+			e.untrackJournalTx(txHash)
+			settled[txHash] = struct{}{}
+			continue
+		}
+
+		if _, _, err := e.evmProvider.TransactionByHash(ctx, txHash); errors.Is(err, ethereum.NotFound) {
+			e.logger.Debug().Str("tx_hash", txHash.Hex()).Msg("in-flight tx is no longer known by the node")
+			e.untrackJournalTx(txHash)
+			settled[txHash] = struct{}{}
+		}
+	}
+
+	return settled
+}
+
+// releaseInFlightTx releases a slot reserved by reserveInFlightTx, tracking the
+// transaction sent in it, if any.
+func (e *ethCommitter) releaseInFlightTx(txHash *ethcmn.Hash) {
+	if e.committerOpts.MaxInFlightTxs == 0 {
+		return
+	}
+
+	e.inFlightMtx.Lock()
+	defer e.inFlightMtx.Unlock()
+
+	e.inFlightReserved--
+	if txHash != nil {
+		e.inFlightTxs = append(e.inFlightTxs, *txHash)
+	}
+}
+
+func (e *ethCommitter) untrackJournalTx(txHash ethcmn.Hash) {
+	if journal := e.committerOpts.Journal; journal != nil {
+		journal.Remove(safemode.ChainEthereum, txHash.Hex())
+	}
+}
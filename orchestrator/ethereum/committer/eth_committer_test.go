@@ -0,0 +1,89 @@
+package committer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umee-network/peggo/mocks"
+)
+
+func TestMaxInFlightTxs(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := context.Background()
+	gravityAddress := ethcmn.HexToAddress("0x1")
+
+	mockEvmProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+	mockEvmProvider.EXPECT().PendingNonceAt(gomock.Any(), ethcmn.Address{}).Return(uint64(0), nil)
+
+	signing := make(chan struct{})
+	unblock := make(chan struct{})
+	signer := func(_ ethcmn.Address, tx *types.Transaction) (*types.Transaction, error) {
+		signing <- struct{}{}
+		<-unblock
+		return tx, nil
+	}
+
+	ethCommitter, err := NewEthCommitter(
+		zerolog.Nop(),
+		ethcmn.Address{},
+		1.0,
+		1.0,
+		signer,
+		mockEvmProvider,
+		OptionMaxInFlightTxs(1),
+	)
+	require.NoError(t, err)
+
+	mockEvmProvider.EXPECT().SendTransactionWithRet(gomock.Any(), gomock.Any()).
+		Return(ethcmn.HexToHash("0x2"), nil)
+
+	done := make(chan error)
+	go func() {
+		_, err := ethCommitter.SendTx(ctx, gravityAddress, []byte{1}, 21000, big.NewInt(100))
+		done <- err
+	}()
+
+	// the slot is reserved while the first tx is being signed and sent
+	<-signing
+	_, err = ethCommitter.SendTx(ctx, gravityAddress, []byte{2}, 21000, big.NewInt(100))
+	assert.ErrorIs(t, err, ErrMaxInFlightTxs)
+
+	close(unblock)
+	require.NoError(t, <-done)
+
+	// then held by the tx until it's mined
+	mockEvmProvider.EXPECT().TransactionReceipt(gomock.Any(), ethcmn.HexToHash("0x2")).Return(nil, ethereum.NotFound)
+	mockEvmProvider.EXPECT().TransactionByHash(gomock.Any(), ethcmn.HexToHash("0x2")).Return(nil, true, nil)
+
+	_, err = ethCommitter.SendTx(ctx, gravityAddress, []byte{2}, 21000, big.NewInt(100))
+	assert.ErrorIs(t, err, ErrMaxInFlightTxs)
+
+	// a tx failing to be sent releases its slot
+	mockEvmProvider.EXPECT().TransactionReceipt(gomock.Any(), ethcmn.HexToHash("0x2")).
+		Return(&types.Receipt{}, nil)
+	mockEvmProvider.EXPECT().SendTransactionWithRet(gomock.Any(), gomock.Any()).
+		Return(ethcmn.Hash{}, errors.New("insufficient funds"))
+
+	go func() { <-signing }()
+	_, err = ethCommitter.SendTx(ctx, gravityAddress, []byte{3}, 21000, big.NewInt(100))
+	assert.Error(t, err)
+
+	mockEvmProvider.EXPECT().SendTransactionWithRet(gomock.Any(), gomock.Any()).
+		Return(ethcmn.HexToHash("0x3"), nil)
+
+	go func() { <-signing }()
+	_, err = ethCommitter.SendTx(ctx, gravityAddress, []byte{4}, 21000, big.NewInt(100))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,65 @@
+package gravity
+
+import (
+	"context"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
+)
+
+// Migration is a planned switch of the bridge to a new Gravity contract.
+type Migration struct {
+	// Block is the first Ethereum block handled by the new contract.
+	Block uint64
+	// Address is the address of the new contract.
+	Address ethcmn.Address
+}
+
+// OptionMigration makes the contract act on the Gravity contract at the given
+// address once the Ethereum chain reaches the migration block. The nonces and
+// the gravity ID are then read from the new contract, and the batches and
+// valset updates are sent to it.
+func OptionMigration(m Migration) ContractOption {
+	return func(o *contractOptions) {
+		o.migration = &m
+	}
+}
+
+// gravity returns the wrapper of the contract in use.
+func (s *gravityContract) gravity() *wrappers.Gravity {
+	if s.migrated.Load() {
+		return s.nextGravity
+	}
+
+	return s.ethGravity
+}
+
+// checkMigration switches to the new contract once the Ethereum chain reaches
+// the migration block. The switch is never undone.
+func (s *gravityContract) checkMigration(ctx context.Context) error {
+	if s.migration == nil || s.migrated.Load() {
+		return nil
+	}
+
+	header, err := s.EVMCommitter.Provider().HeaderByNumber(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to get latest header")
+	}
+
+	if header.Number.Uint64() < s.migration.Block {
+		return nil
+	}
+
+	if s.migrated.CompareAndSwap(false, true) {
+		close(s.switched)
+		s.logger.Warn().
+			Uint64("migration_block", s.migration.Block).
+			Str("from", s.gravityAddress.Hex()).
+			Str("to", s.migration.Address.Hex()).
+			Msg("switched to the new Gravity contract")
+	}
+
+	return nil
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"math/big"
 	"strings"
-	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
@@ -14,12 +14,21 @@ import (
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 
+	"github.com/umee-network/peggo/orchestrator/cache"
 	"github.com/umee-network/peggo/orchestrator/ethereum/committer"
+	"github.com/umee-network/peggo/orchestrator/lifecycle"
 	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
+	"github.com/umee-network/peggo/solwrappers/versions"
 )
 
-// gravityPowerToPass is a mirror of constant_powerThreshold in Gravity.sol
-const gravityPowerToPass int64 = 2863311530
+const (
+	// gravityPowerToPass is a mirror of constant_powerThreshold in Gravity.sol
+	gravityPowerToPass int64 = 2863311530
+
+	// erc20CacheSize bounds the number of ERC20 tokens whose metadata is kept
+	// in memory.
+	erc20CacheSize = 1024
+)
 
 var (
 	gravityABI, _ = abi.JSON(strings.NewReader(wrappers.GravityABI))
@@ -95,11 +104,64 @@ type gravityContract struct {
 
 	logger             zerolog.Logger
 	gravityAddress     ethcmn.Address
+	gravityABI         abi.ABI
+	rewardRecipientArg string
+	rewardRecipient    ethcmn.Address
 	ethGravity         *wrappers.Gravity
 	pendingTxInputList PendingTxInputList
+	lifecycle          *lifecycle.Tracker
+
+	migration   *Migration
+	nextGravity *wrappers.Gravity
+	migrated    atomic.Bool
+	// switched is closed once the contract is migrated.
+	switched chan struct{}
+
+	erc20DecimalCache *cache.LRU[ethcmn.Address, uint8]
+	erc20SymbolCache  *cache.LRU[ethcmn.Address, string]
+}
+
+// ContractOption configures optional gravityContract settings.
+type ContractOption func(*contractOptions)
+
+type contractOptions struct {
+	cacheMetrics     *cache.Metrics
+	lifecycleMetrics *lifecycle.Metrics
+	version          *versions.Version
+	rewardRecipient  *ethcmn.Address
+	migration        *Migration
+}
+
+// OptionVersion encodes the batches and valset updates with the ABI of the given
+// Gravity.sol version instead of the latest one.
+func OptionVersion(v versions.Version) ContractOption {
+	return func(o *contractOptions) {
+		o.version = &v
+	}
+}
+
+// OptionRewardRecipient pays the fees of the submitted batches to the given
+// address instead of the sender of the tx, on a Gravity.sol version with
+// directed rewards (see versions.Version.RewardRecipientArg).
+func OptionRewardRecipient(addr ethcmn.Address) ContractOption {
+	return func(o *contractOptions) {
+		o.rewardRecipient = &addr
+	}
+}
 
-	mtx               sync.Mutex
-	erc20DecimalCache map[string]uint8
+// OptionCacheMetrics exports the hits and misses of the ERC20 metadata caches.
+func OptionCacheMetrics(m *cache.Metrics) ContractOption {
+	return func(o *contractOptions) {
+		o.cacheMetrics = m
+	}
+}
+
+// OptionLifecycleMetrics exports the number of Ethereum subscriptions opened by
+// the contract with the given metrics.
+func OptionLifecycleMetrics(m *lifecycle.Metrics) ContractOption {
+	return func(o *contractOptions) {
+		o.lifecycleMetrics = m
+	}
 }
 
 func NewGravityContract(
@@ -107,16 +169,63 @@ func NewGravityContract(
 	ethCommitter committer.EVMCommitter,
 	gravityAddress ethcmn.Address,
 	ethGravity *wrappers.Gravity,
+	options ...ContractOption,
 ) (Contract, error) {
-	return &gravityContract{
+	var opts contractOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
+	version := versions.Latest()
+	if opts.version != nil {
+		version = *opts.version
+	}
+
+	rewardRecipient := ethCommitter.FromAddress()
+	if opts.rewardRecipient != nil {
+		rewardRecipient = *opts.rewardRecipient
+	}
+
+	contract := &gravityContract{
 		logger:         logger.With().Str("module", "gravity_contract").Logger(),
 		EVMCommitter:   ethCommitter,
 		gravityAddress: gravityAddress,
+		gravityABI:     version.ABI,
 		ethGravity:     ethGravity,
-	}, nil
+		lifecycle:      lifecycle.NewTracker("gravity_contract", opts.lifecycleMetrics),
+		erc20DecimalCache: cache.New[ethcmn.Address, uint8](
+			erc20CacheSize,
+			cache.OptionMetrics(opts.cacheMetrics, "erc20_decimals"),
+		),
+		erc20SymbolCache: cache.New[ethcmn.Address, string](
+			erc20CacheSize,
+			cache.OptionMetrics(opts.cacheMetrics, "erc20_symbol"),
+		),
+		rewardRecipientArg: version.RewardRecipientArg,
+		rewardRecipient:    rewardRecipient,
+	}
+
+	if opts.migration != nil {
+		nextGravity, err := wrappers.NewGravity(opts.migration.Address, ethCommitter.Provider())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create a new instance of Gravity")
+		}
+
+		contract.migration = opts.migration
+		contract.nextGravity = nextGravity
+		contract.switched = make(chan struct{})
+	}
+
+	return contract, nil
 }
 
+// Address returns the address of the Gravity contract in use; the new one once
+// migrated.
 func (s *gravityContract) Address() ethcmn.Address {
+	if s.migrated.Load() {
+		return s.migration.Address
+	}
+
 	return s.gravityAddress
 }
 
@@ -127,7 +236,11 @@ func (s *gravityContract) GetTxBatchNonce(
 	callerAddress ethcmn.Address,
 ) (*big.Int, error) {
 
-	nonce, err := s.ethGravity.LastBatchNonce(&bind.CallOpts{
+	if err := s.checkMigration(ctx); err != nil {
+		return nil, err
+	}
+
+	nonce, err := s.gravity().LastBatchNonce(&bind.CallOpts{
 		From:    callerAddress,
 		Context: ctx,
 	}, erc20ContractAddress)
@@ -145,7 +258,11 @@ func (s *gravityContract) GetValsetNonce(
 	callerAddress ethcmn.Address,
 ) (*big.Int, error) {
 
-	nonce, err := s.ethGravity.StateLastValsetNonce(&bind.CallOpts{
+	if err := s.checkMigration(ctx); err != nil {
+		return nil, err
+	}
+
+	nonce, err := s.gravity().StateLastValsetNonce(&bind.CallOpts{
 		From:    callerAddress,
 		Context: ctx,
 	})
@@ -163,7 +280,11 @@ func (s *gravityContract) GetGravityID(
 	callerAddress ethcmn.Address,
 ) (string, error) {
 
-	gravityID, err := s.ethGravity.StateGravityId(&bind.CallOpts{
+	if err := s.checkMigration(ctx); err != nil {
+		return "", err
+	}
+
+	gravityID, err := s.gravity().StateGravityId(&bind.CallOpts{
 		From:    callerAddress,
 		Context: ctx,
 	})
@@ -182,6 +303,10 @@ func (s *gravityContract) GetERC20Symbol(
 	callerAddress ethcmn.Address,
 ) (symbol string, err error) {
 
+	if symbol, ok := s.erc20SymbolCache.Get(erc20ContractAddress); ok {
+		return symbol, nil
+	}
+
 	erc20Wrapper, err := wrappers.NewERC20(erc20ContractAddress, s.EVMCommitter.Provider())
 	if err != nil {
 		err = errors.Wrap(err, "failed to get ERC20 wrapper")
@@ -199,6 +324,7 @@ func (s *gravityContract) GetERC20Symbol(
 		return "", err
 	}
 
+	s.erc20SymbolCache.Add(erc20ContractAddress, symbol)
 	return symbol, nil
 }
 
@@ -208,12 +334,7 @@ func (s *gravityContract) GetERC20Decimals(
 	callerAddr ethcmn.Address,
 ) (uint8, error) {
 
-	s.mtx.Lock()
-	defer s.mtx.Unlock()
-
-	tokenAddrStr := tokenAddr.String()
-
-	if d, ok := s.erc20DecimalCache[tokenAddrStr]; ok {
+	if d, ok := s.erc20DecimalCache.Get(tokenAddr); ok {
 		return d, nil
 	}
 
@@ -232,11 +353,7 @@ func (s *gravityContract) GetERC20Decimals(
 		return 0, errors.Wrap(err, "ERC20 'decimals' call failed")
 	}
 
-	if s.erc20DecimalCache == nil {
-		s.erc20DecimalCache = map[string]uint8{}
-	}
-
-	s.erc20DecimalCache[tokenAddrStr] = decimals
+	s.erc20DecimalCache.Add(tokenAddr, decimals)
 	return decimals, nil
 }
 
@@ -260,3 +377,25 @@ func sigToVRS(sigHex string) (v uint8, r, s ethcmn.Hash) {
 func isEnoughPower(total *big.Int) bool {
 	return total.Cmp(big.NewInt(gravityPowerToPass)) == 1
 }
+
+// MissingSigners returns the members of the valset that aren't among the given
+// Ethereum signers, and whether the power that did sign passes the contract's
+// power threshold.
+func MissingSigners(valset types.Valset, signers []string) (missing []types.BridgeValidator, enoughPower bool) {
+	signed := make(map[string]bool, len(signers))
+	for _, signer := range signers {
+		signed[signer] = true
+	}
+
+	power := new(big.Int)
+	for _, m := range valset.Members {
+		if signed[m.EthereumAddress] {
+			power.Add(power, new(big.Int).SetUint64(m.Power))
+			continue
+		}
+
+		missing = append(missing, m)
+	}
+
+	return missing, isEnoughPower(power)
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
 	"github.com/ethereum/go-ethereum"
 	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -247,3 +248,24 @@ func TestAddress(t *testing.T) {
 
 	assert.Equal(t, ethcmn.Address{}, gravityContract.Address())
 }
+
+func TestMissingSigners(t *testing.T) {
+	valset := types.Valset{
+		Members: []types.BridgeValidator{
+			{Power: 2000000000, EthereumAddress: "0x0000000000000000000000000000000000000001"},
+			{Power: 1000000000, EthereumAddress: "0x0000000000000000000000000000000000000002"},
+			{Power: 1294967295, EthereumAddress: "0x0000000000000000000000000000000000000003"},
+		},
+	}
+
+	missing, ok := MissingSigners(valset, []string{
+		"0x0000000000000000000000000000000000000001",
+		"0x0000000000000000000000000000000000000002",
+	})
+	assert.True(t, ok)
+	assert.Equal(t, []types.BridgeValidator{valset.Members[2]}, missing)
+
+	missing, ok = MissingSigners(valset, []string{"0x0000000000000000000000000000000000000001"})
+	assert.False(t, ok)
+	assert.Equal(t, valset.Members[1:], missing)
+}
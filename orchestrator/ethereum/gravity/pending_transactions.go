@@ -9,6 +9,9 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// pendingTxsOwner owns the Alchemy pending transactions subscription.
+const pendingTxsOwner = "alchemy_pending_txs"
+
 // PendingTxInput contains the data of a pending transaction and the time we first saw it.
 type PendingTxInput struct {
 	InputData    hexutil.Bytes
@@ -67,11 +70,11 @@ func (s *gravityContract) IsPendingTxInput(txData []byte, pendingTxWaitDuration
 	return false
 }
 
+// SubscribeToPendingTxs listens for the pending transactions sent to the
+// Gravity contract in use until ctx is done or the subscription fails. Once the
+// contract is migrated, the subscription is renewed on the new contract. The
+// websocket connection is closed on return.
 func (s *gravityContract) SubscribeToPendingTxs(ctx context.Context, alchemyWebsocketURL string) error {
-	args := map[string]interface{}{
-		"address": s.gravityAddress.Hex(),
-	}
-
 	wsClient, err := rpc.Dial(alchemyWebsocketURL)
 	if err != nil {
 		s.logger.Fatal().
@@ -81,23 +84,76 @@ func (s *gravityContract) SubscribeToPendingTxs(ctx context.Context, alchemyWebs
 		return err
 	}
 
+	conn := s.lifecycle.Track(pendingTxsOwner, wsClient.Close)
+	defer conn.Close()
+
+	// a nil channel never fires: there's nothing to switch to without a
+	// migration, or once it happened
+	switched := s.switched
+	if s.migrated.Load() {
+		switched = nil
+	}
+
+	for {
+		resubscribe, err := s.receivePendingTxs(ctx, wsClient, alchemyWebsocketURL, switched)
+		if err != nil || !resubscribe {
+			return err
+		}
+
+		switched = nil
+		s.logger.Info().
+			Str("address", s.Address().Hex()).
+			Msg("resubscribing to the pending transactions of the new Gravity contract")
+	}
+}
+
+// receivePendingTxs subscribes to the pending transactions sent to the Gravity
+// contract in use and adds them to the pending list. It returns true when the
+// contract is switched, so the caller subscribes again on the new address.
+func (s *gravityContract) receivePendingTxs(
+	ctx context.Context,
+	wsClient *rpc.Client,
+	alchemyWebsocketURL string,
+	switched <-chan struct{},
+) (bool, error) {
+	args := map[string]interface{}{
+		"address": s.Address().Hex(),
+	}
+
 	ch := make(chan *RPCTransaction)
-	_, err = wsClient.EthSubscribe(ctx, ch, "alchemy_filteredNewFullPendingTransactions", args)
+	sub, err := wsClient.EthSubscribe(ctx, ch, "alchemy_filteredNewFullPendingTransactions", args)
 	if err != nil {
+		if ctx.Err() != nil {
+			return false, nil
+		}
+
 		s.logger.Fatal().
 			AnErr("err", err).
 			Str("endpoint", alchemyWebsocketURL).
 			Msg("Failed to subscribe to pending transactions")
-		return err
+		return false, err
 	}
+	defer sub.Unsubscribe()
 
 	for {
 		select {
 		case pendingTransaction := <-ch:
 			s.pendingTxInputList.AddPendingTxInput(pendingTransaction)
 
+		case <-switched:
+			return true, nil
+
+		case err := <-sub.Err():
+			// pending txs are only used to avoid relaying twice; the relayer
+			// keeps running without them
+			s.logger.Error().
+				Err(err).
+				Str("endpoint", alchemyWebsocketURL).
+				Msg("pending transactions subscription ended")
+			return false, nil
+
 		case <-ctx.Done():
-			return nil
+			return false, nil
 		}
 	}
 }
@@ -1,15 +1,22 @@
 package gravity
 
 import (
+	"context"
+	"math/big"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/golang/mock/gomock"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/umee-network/peggo/mocks"
 	"github.com/umee-network/peggo/orchestrator/ethereum/committer"
 	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
@@ -86,25 +93,65 @@ func TestIsPendingTxInput(t *testing.T) {
 
 }
 
-// TODO: check if we can actually test this. Maybe move the Fatal call to the caller.
-// func TestSubscribeToPendingTxs(t *testing.T) {
-// 	mockCtrl := gomock.NewController(t)
-// 	defer mockCtrl.Finish()
-// 	mockEvmProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
-// 	mockEvmProvider.EXPECT().PendingNonceAt(gomock.Any(), ethcmn.HexToAddress("0x0")).Return(uint64(0), nil)
+// pendingTxsService serves the Alchemy pending transactions subscription and
+// records the address filter of every subscription.
+type pendingTxsService struct {
+	addresses chan string
+}
+
+func (p *pendingTxsService) Alchemy_filteredNewFullPendingTransactions( // nolint: revive,stylecheck
+	ctx context.Context,
+	args map[string]interface{},
+) (*rpc.Subscription, error) {
+	notifier, _ := rpc.NotifierFromContext(ctx)
+	sub := notifier.CreateSubscription()
+	p.addresses <- args["address"].(string)
+	return sub, nil
+}
+
+func TestSubscribeToPendingTxsMigration(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockEvmProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+	mockEvmProvider.EXPECT().PendingNonceAt(gomock.Any(), ethcmn.Address{}).Return(uint64(0), nil)
+	mockEvmProvider.EXPECT().HeaderByNumber(gomock.Any(), gomock.Nil()).
+		Return(&ethtypes.Header{Number: big.NewInt(100)}, nil)
 
-// 	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
-// 	ethCommitter, _ := committer.NewEthCommitter(
-// 		logger,
-// 		ethcmn.Address{},
-// 		1.0,
-// 		nil,
-// 		mockEvmProvider,
-// 	)
-// 	gravityContract, _ := NewGravityContract(logger, ethCommitter, ethcmn.Address{})
+	service := &pendingTxsService{addresses: make(chan string, 2)}
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", service))
+	httpServer := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer httpServer.Close()
+	defer server.Stop()
 
-// 	err := gravityContract.SubscribeToPendingTxs(context.Background(), "invalidURL")
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+	ethCommitter, _ := committer.NewEthCommitter(logger, ethcmn.Address{}, 1.0, 1.0, nil, mockEvmProvider)
+
+	oldAddress := ethcmn.HexToAddress("0x1")
+	newAddress := ethcmn.HexToAddress("0x2")
+	ethGravity, _ := wrappers.NewGravity(oldAddress, ethCommitter.Provider())
+	contract, err := NewGravityContract(
+		logger,
+		ethCommitter,
+		oldAddress,
+		ethGravity,
+		OptionMigration(Migration{Block: 100, Address: newAddress}),
+	)
+	require.NoError(t, err)
+	gravityContract := contract.(*gravityContract)
 
-// 	assert.NotNil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- gravityContract.SubscribeToPendingTxs(ctx, "ws"+strings.TrimPrefix(httpServer.URL, "http"))
+	}()
 
-// }
+	assert.Equal(t, oldAddress.Hex(), <-service.addresses)
+
+	// the subscription follows the contract once migrated
+	require.NoError(t, gravityContract.checkMigration(ctx))
+	assert.Equal(t, newAddress.Hex(), <-service.addresses)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
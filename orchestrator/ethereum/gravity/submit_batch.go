@@ -5,6 +5,8 @@ import (
 	"math/big"
 
 	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 
@@ -63,16 +65,21 @@ func (s *gravityContract) EncodeTransactionBatch(
 		})
 	}
 
-	txData, err := gravityABI.Pack("submitBatch",
-		currentValsetArs,
-		sigArray,
-		amounts,
-		destinations,
-		fees,
-		batchNonce,
-		ethcmn.HexToAddress(batch.TokenContract),
-		batchTimeout,
-	)
+	args := map[string]interface{}{
+		"_currentValset": currentValsetArs,
+		"_sigs":          sigArray,
+		"_amounts":       amounts,
+		"_destinations":  destinations,
+		"_fees":          fees,
+		"_batchNonce":    batchNonce,
+		"_tokenContract": ethcmn.HexToAddress(batch.TokenContract),
+		"_batchTimeout":  batchTimeout,
+	}
+	if s.rewardRecipientArg != "" {
+		args[s.rewardRecipientArg] = s.rewardRecipient
+	}
+
+	txData, err := packNamed(s.gravityABI, "submitBatch", args)
 	if err != nil {
 		s.logger.Err(err).Msg("ABI Pack (Gravity submitBatch) method")
 		return nil, err
@@ -81,6 +88,27 @@ func (s *gravityContract) EncodeTransactionBatch(
 	return txData, nil
 }
 
+// packNamed packs a call of the method with the arguments given by name, so
+// the versions adding arguments to a method can be packed the same way.
+func packNamed(contractABI abi.ABI, name string, args map[string]interface{}) ([]byte, error) {
+	method, ok := contractABI.Methods[name]
+	if !ok {
+		return nil, errors.Errorf("no %s method", name)
+	}
+
+	values := make([]interface{}, 0, len(method.Inputs))
+	for _, input := range method.Inputs {
+		value, ok := args[input.Name]
+		if !ok {
+			return nil, errors.Errorf("no value for the %s argument of %s", input.Name, name)
+		}
+
+		values = append(values, value)
+	}
+
+	return contractABI.Pack(name, values...)
+}
+
 func getBatchCheckpointValues(batch types.OutgoingTxBatch) (
 	amounts []*big.Int,
 	destinations []ethcmn.Address,
@@ -157,3 +185,39 @@ func checkAndRepackSigs(valset types.Valset, confirms []genericConfirm) (*Repack
 	err = ErrInsufficientVotingPowerToPass
 	return sigs, err
 }
+
+// DecodeSubmitBatch rebuilds a batch from the input of a submitBatch call,
+// without its method ID.
+func DecodeSubmitBatch(method abi.Method, input []byte) (types.OutgoingTxBatch, error) {
+	args, err := method.Inputs.Unpack(input)
+	if err != nil {
+		return types.OutgoingTxBatch{}, err
+	}
+
+	amounts := *abi.ConvertType(args[2], new([]*big.Int)).(*[]*big.Int)
+	destinations := *abi.ConvertType(args[3], new([]ethcmn.Address)).(*[]ethcmn.Address)
+	fees := *abi.ConvertType(args[4], new([]*big.Int)).(*[]*big.Int)
+	batchNonce := *abi.ConvertType(args[5], new(*big.Int)).(**big.Int)
+	tokenContract := *abi.ConvertType(args[6], new(ethcmn.Address)).(*ethcmn.Address)
+	batchTimeout := *abi.ConvertType(args[7], new(*big.Int)).(**big.Int)
+
+	if len(amounts) != len(destinations) || len(amounts) != len(fees) {
+		return types.OutgoingTxBatch{}, errors.New("malformed batch")
+	}
+
+	batch := types.OutgoingTxBatch{
+		BatchNonce:    batchNonce.Uint64(),
+		BatchTimeout:  batchTimeout.Uint64(),
+		TokenContract: tokenContract.Hex(),
+	}
+
+	for i := range amounts {
+		batch.Transactions = append(batch.Transactions, types.OutgoingTransferTx{
+			DestAddress: destinations[i].Hex(),
+			Erc20Token:  types.ERC20Token{Contract: tokenContract.Hex(), Amount: sdk.NewIntFromBigInt(amounts[i])},
+			Erc20Fee:    types.ERC20Token{Contract: tokenContract.Hex(), Amount: sdk.NewIntFromBigInt(fees[i])},
+		})
+	}
+
+	return batch, nil
+}
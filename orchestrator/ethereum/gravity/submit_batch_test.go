@@ -4,20 +4,24 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"math/big"
 	"os"
 	"testing"
 
 	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/golang/mock/gomock"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/umee-network/peggo/mocks"
 	"github.com/umee-network/peggo/orchestrator/ethereum/committer"
 	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
+	"github.com/umee-network/peggo/solwrappers/versions"
 )
 
 func TestEncodeTransactionBatch(t *testing.T) {
@@ -164,3 +168,68 @@ func TestCheckBatchSigsAndRepack(t *testing.T) {
 	assert.Equal(t, []*big.Int{big.NewInt(1111111111), big.NewInt(2212121212), big.NewInt(123456)}, repackedSigs.powers)
 
 }
+
+func TestEncodeTransactionBatchRewardRecipient(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockEvmProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+	mockEvmProvider.EXPECT().PendingNonceAt(gomock.Any(), gomock.Any()).Return(uint64(0), nil).AnyTimes()
+
+	from := ethcmn.HexToAddress("0x3")
+	ethCommitter, err := committer.NewEthCommitter(zerolog.Nop(), from, 1.0, 1.0, nil, mockEvmProvider)
+	require.NoError(t, err)
+
+	// v2 adds the address the batch fees are paid to to the submitBatch args
+	var entries []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(wrappers.GravityMetaData.ABI), &entries))
+	for _, entry := range entries {
+		if entry["name"] == "submitBatch" {
+			entry["inputs"] = append(entry["inputs"].([]interface{}), map[string]interface{}{
+				"internalType": "address",
+				"name":         "_rewardRecipient",
+				"type":         "address",
+			})
+		}
+	}
+	bz, err := json.Marshal(entries)
+	require.NoError(t, err)
+
+	version, err := versions.New("v2", &bind.MetaData{ABI: string(bz)}, "_rewardRecipient")
+	require.NoError(t, err)
+
+	valset := types.Valset{
+		Nonce:        1,
+		Members:      []types.BridgeValidator{{EthereumAddress: ethcmn.HexToAddress("0x0").Hex(), Power: 4294967295}},
+		RewardAmount: sdk.NewInt(0),
+	}
+	confirms := []types.MsgConfirmBatch{{
+		EthSigner: ethcmn.HexToAddress("0x0").Hex(),
+		Signature: "0xaae54ee7e285fbb0275279143abc4c554e5314e7b417ecac83a5984a964facbaad68866a2841c3e83ddf125a2985566261c4014f9f960ec60253aebcda9513a9b4",
+	}}
+	batch := types.OutgoingTxBatch{BatchNonce: 1, BatchTimeout: 100, TokenContract: ethcmn.HexToAddress("0x1").Hex()}
+
+	for _, tc := range []struct {
+		name     string
+		options  []ContractOption
+		expected ethcmn.Address
+	}{
+		{"sender", []ContractOption{OptionVersion(version)}, from},
+		{
+			"reward recipient",
+			[]ContractOption{OptionVersion(version), OptionRewardRecipient(ethcmn.HexToAddress("0x4"))},
+			ethcmn.HexToAddress("0x4"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			gravityContract, err := NewGravityContract(zerolog.Nop(), ethCommitter, ethcmn.Address{}, nil, tc.options...)
+			require.NoError(t, err)
+
+			txData, err := gravityContract.EncodeTransactionBatch(context.Background(), valset, batch, confirms)
+			require.NoError(t, err)
+
+			recipient, err := version.RewardRecipient(txData)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, recipient)
+		})
+	}
+}
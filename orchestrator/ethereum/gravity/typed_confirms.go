@@ -0,0 +1,270 @@
+package gravity
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// SignatureSchemePersonal signs the confirm checkpoints with personal_sign,
+	// as the upstream Gravity contract expects.
+	SignatureSchemePersonal = "personal"
+	// SignatureSchemeEIP712 signs the confirm checkpoints as EIP-712 typed data,
+	// as used by some Gravity forks.
+	SignatureSchemeEIP712 = "eip712"
+)
+
+const (
+	// DefaultValsetConfirmType and DefaultBatchConfirmType are the EIP-712 struct
+	// types of the typed confirms unless a deployment sets its own, wrapping the
+	// same checkpoints as personal_sign.
+	DefaultValsetConfirmType = "ValsetConfirm(bytes32 checkpoint)"
+	DefaultBatchConfirmType  = "BatchConfirm(bytes32 checkpoint)"
+)
+
+type typedFieldKind int
+
+const (
+	typedFieldBytes32 typedFieldKind = iota
+	typedFieldAddress
+	typedFieldUint
+)
+
+// typedField is a value a typed confirm can hold; the uint ones need at least
+// minBits.
+type typedField struct {
+	kind    typedFieldKind
+	minBits int
+}
+
+var (
+	// valsetConfirmFields and batchConfirmFields are the fields the typed confirm
+	// types can be made of, by name.
+	valsetConfirmFields = map[string]typedField{
+		"checkpoint":   {kind: typedFieldBytes32},
+		"gravityId":    {kind: typedFieldBytes32},
+		"nonce":        {kind: typedFieldUint, minBits: 64},
+		"rewardAmount": {kind: typedFieldUint, minBits: 256},
+		"rewardToken":  {kind: typedFieldAddress},
+	}
+	batchConfirmFields = map[string]typedField{
+		"checkpoint":    {kind: typedFieldBytes32},
+		"gravityId":     {kind: typedFieldBytes32},
+		"nonce":         {kind: typedFieldUint, minBits: 64},
+		"batchTimeout":  {kind: typedFieldUint, minBits: 64},
+		"tokenContract": {kind: typedFieldAddress},
+	}
+
+	encodeTypeRegexp = regexp.MustCompile(`^([A-Za-z_$][A-Za-z0-9_$]*)\((.*)\)$`)
+	uintTypeRegexp   = regexp.MustCompile(`^uint([1-9][0-9]*)$`)
+)
+
+// TypedConfirmScheme defines the EIP-712 typed confirms of a deployment: the
+// domain separator and the struct types of the valset and batch confirms.
+type TypedConfirmScheme struct {
+	Domain     EIP712Domain
+	ValsetType TypedConfirmType
+	BatchType  TypedConfirmType
+}
+
+// TypedConfirmType is the EIP-712 struct type of a typed confirm, parsed from
+// its encodeType (e.g. "ValsetConfirm(bytes32 checkpoint)"), the way the fork's
+// contract declares its type hash.
+type TypedConfirmType struct {
+	encodeType string
+	fields     []string
+}
+
+// ParseValsetConfirmType parses the type of valset confirms. Its fields are
+// taken from checkpoint, gravityId (bytes32), nonce, rewardAmount (uint) and
+// rewardToken (address), in any order.
+func ParseValsetConfirmType(encodeType string) (TypedConfirmType, error) {
+	return parseTypedConfirmType(encodeType, valsetConfirmFields)
+}
+
+// ParseBatchConfirmType parses the type of batch confirms. Its fields are taken
+// from checkpoint, gravityId (bytes32), nonce, batchTimeout (uint) and
+// tokenContract (address), in any order.
+func ParseBatchConfirmType(encodeType string) (TypedConfirmType, error) {
+	return parseTypedConfirmType(encodeType, batchConfirmFields)
+}
+
+func parseTypedConfirmType(encodeType string, known map[string]typedField) (TypedConfirmType, error) {
+	match := encodeTypeRegexp.FindStringSubmatch(strings.TrimSpace(encodeType))
+	if match == nil {
+		return TypedConfirmType{}, fmt.Errorf("invalid EIP-712 type %q; expected Name(type field,...)", encodeType)
+	}
+
+	var (
+		members []string
+		fields  []string
+		seen    = map[string]struct{}{}
+	)
+
+	for _, member := range strings.Split(match[2], ",") {
+		parts := strings.Fields(member)
+		if len(parts) != 2 {
+			return TypedConfirmType{}, fmt.Errorf("invalid EIP-712 type %q: invalid field %q", encodeType, member)
+		}
+
+		typ, name := parts[0], parts[1]
+
+		field, ok := known[name]
+		if !ok {
+			return TypedConfirmType{}, fmt.Errorf("invalid EIP-712 type %q: unknown field %s", encodeType, name)
+		}
+
+		if _, ok := seen[name]; ok {
+			return TypedConfirmType{}, fmt.Errorf("invalid EIP-712 type %q: duplicate field %s", encodeType, name)
+		}
+		seen[name] = struct{}{}
+
+		if !field.accepts(typ) {
+			return TypedConfirmType{}, fmt.Errorf("invalid EIP-712 type %q: %s can't be a %s", encodeType, name, typ)
+		}
+
+		members = append(members, typ+" "+name)
+		fields = append(fields, name)
+	}
+
+	return TypedConfirmType{
+		encodeType: match[1] + "(" + strings.Join(members, ",") + ")",
+		fields:     fields,
+	}, nil
+}
+
+// accepts returns whether the field can be encoded as the given Solidity type.
+func (f typedField) accepts(typ string) bool {
+	switch f.kind {
+	case typedFieldBytes32:
+		return typ == "bytes32"
+
+	case typedFieldAddress:
+		return typ == "address"
+
+	default:
+		match := uintTypeRegexp.FindStringSubmatch(typ)
+		if match == nil {
+			return false
+		}
+
+		bits, _ := strconv.Atoi(match[1])
+		return bits%8 == 0 && bits >= f.minBits && bits <= 256
+	}
+}
+
+// String returns the canonical encodeType, whose keccak256 hash is the type hash.
+func (t TypedConfirmType) String() string {
+	return t.encodeType
+}
+
+// hashStruct returns the EIP-712 hashStruct of the confirm, given its field
+// values encoded in 32 bytes.
+func (t TypedConfirmType) hashStruct(values map[string][]byte) []byte {
+	parts := make([][]byte, 0, len(t.fields)+1)
+	parts = append(parts, crypto.Keccak256([]byte(t.encodeType)))
+
+	for _, name := range t.fields {
+		parts = append(parts, values[name])
+	}
+
+	return crypto.Keccak256(parts...)
+}
+
+// EIP712Domain defines the domain separator parameters of a deployment using
+// EIP-712 typed confirms. The chain ID, verifying contract and salt are only
+// part of the domain when set.
+type EIP712Domain struct {
+	Name              string
+	Version           string
+	ChainID           *big.Int
+	VerifyingContract ethcmn.Address
+	Salt              ethcmn.Hash
+}
+
+// Separator returns the EIP-712 domain separator.
+func (d EIP712Domain) Separator() ethcmn.Hash {
+	fields := []string{"string name", "string version"}
+	values := [][]byte{
+		crypto.Keccak256([]byte(d.Name)),
+		crypto.Keccak256([]byte(d.Version)),
+	}
+
+	if d.ChainID != nil {
+		fields = append(fields, "uint256 chainId")
+		values = append(values, math.U256Bytes(new(big.Int).Set(d.ChainID)))
+	}
+
+	if d.VerifyingContract != (ethcmn.Address{}) {
+		fields = append(fields, "address verifyingContract")
+		values = append(values, ethcmn.LeftPadBytes(d.VerifyingContract.Bytes(), 32))
+	}
+
+	if d.Salt != (ethcmn.Hash{}) {
+		fields = append(fields, "bytes32 salt")
+		values = append(values, d.Salt.Bytes())
+	}
+
+	typeHash := crypto.Keccak256([]byte("EIP712Domain(" + strings.Join(fields, ",") + ")"))
+
+	return crypto.Keccak256Hash(append([][]byte{typeHash}, values...)...)
+}
+
+// EncodeTypedValsetConfirm returns the EIP-712 encoding ("\x19\x01" ||
+// domainSeparator || hashStruct) of a valset confirm, whose keccak256 hash is
+// what gets signed.
+func EncodeTypedValsetConfirm(scheme TypedConfirmScheme, gravityID string, valset types.Valset) []byte {
+	rewardAmount := big.NewInt(0)
+	if !valset.RewardAmount.IsNil() {
+		rewardAmount = valset.RewardAmount.BigInt()
+	}
+
+	structHash := scheme.ValsetType.hashStruct(map[string][]byte{
+		"checkpoint":   EncodeValsetConfirm(gravityID, valset).Bytes(),
+		"gravityId":    gravityIDBytes32(gravityID),
+		"nonce":        math.U256Bytes(new(big.Int).SetUint64(valset.Nonce)),
+		"rewardAmount": math.U256Bytes(rewardAmount),
+		"rewardToken":  ethcmn.LeftPadBytes(ethcmn.HexToAddress(valset.RewardToken).Bytes(), 32),
+	})
+
+	return encodeTypedConfirm(scheme.Domain, structHash)
+}
+
+// EncodeTypedBatchConfirm returns the EIP-712 encoding of a batch confirm, like
+// EncodeTypedValsetConfirm.
+func EncodeTypedBatchConfirm(scheme TypedConfirmScheme, gravityID string, batch types.OutgoingTxBatch) []byte {
+	structHash := scheme.BatchType.hashStruct(map[string][]byte{
+		"checkpoint":    EncodeTxBatchConfirm(gravityID, batch).Bytes(),
+		"gravityId":     gravityIDBytes32(gravityID),
+		"nonce":         math.U256Bytes(new(big.Int).SetUint64(batch.BatchNonce)),
+		"batchTimeout":  math.U256Bytes(new(big.Int).SetUint64(batch.BatchTimeout)),
+		"tokenContract": ethcmn.LeftPadBytes(ethcmn.HexToAddress(batch.TokenContract).Bytes(), 32),
+	})
+
+	return encodeTypedConfirm(scheme.Domain, structHash)
+}
+
+// gravityIDBytes32 returns the Gravity ID as the bytes32 of the checkpoints.
+func gravityIDBytes32(gravityID string) []byte {
+	var bz [32]byte
+	copy(bz[:], gravityID)
+
+	return bz[:]
+}
+
+func encodeTypedConfirm(domain EIP712Domain, structHash []byte) []byte {
+	typedData := make([]byte, 0, 66)
+	typedData = append(typedData, 0x19, 0x01)
+	typedData = append(typedData, domain.Separator().Bytes()...)
+	typedData = append(typedData, structHash...)
+
+	return typedData
+}
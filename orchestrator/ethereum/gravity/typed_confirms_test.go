@@ -0,0 +1,188 @@
+package gravity
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeTypedValsetConfirm(t *testing.T) {
+	gravityID := "defaultgravityid"
+
+	valset := types.Valset{
+		Nonce: 5,
+		Members: []types.BridgeValidator{
+			{Power: 1, EthereumAddress: "0x02fa1b44e2EF8436e6f35D5F56607769c658c225"},
+		},
+		RewardAmount: sdk.NewInt(2),
+	}
+
+	valsetType, err := ParseValsetConfirmType(DefaultValsetConfirmType)
+	require.NoError(t, err)
+
+	scheme := TypedConfirmScheme{
+		Domain: EIP712Domain{
+			Name:              "Gravity",
+			Version:           "1",
+			ChainID:           big.NewInt(5),
+			VerifyingContract: ethcmn.HexToAddress("0x4884e2a214dc5040f52a41c3f21c765283170b6e"),
+			Salt:              ethcmn.HexToHash("0x01"),
+		},
+		ValsetType: valsetType,
+	}
+
+	// the encoding must match the one of the go-ethereum typed data signer
+	typedData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": []apitypes.Type{
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+				{Name: "salt", Type: "bytes32"},
+			},
+			"ValsetConfirm": []apitypes.Type{
+				{Name: "checkpoint", Type: "bytes32"},
+			},
+		},
+		PrimaryType: "ValsetConfirm",
+		Domain: apitypes.TypedDataDomain{
+			Name:              scheme.Domain.Name,
+			Version:           scheme.Domain.Version,
+			ChainId:           math.NewHexOrDecimal256(5),
+			VerifyingContract: scheme.Domain.VerifyingContract.Hex(),
+			Salt:              scheme.Domain.Salt.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"checkpoint": EncodeValsetConfirm(gravityID, valset).Bytes(),
+		},
+	}
+
+	expectedHash, _, err := apitypes.TypedDataAndHash(typedData)
+	require.NoError(t, err)
+
+	encoded := EncodeTypedValsetConfirm(scheme, gravityID, valset)
+	assert.Len(t, encoded, 66)
+	assert.Equal(t, expectedHash, crypto.Keccak256(encoded))
+}
+
+func TestEncodeTypedBatchConfirmCustomType(t *testing.T) {
+	gravityID := "defaultgravityid"
+	tokenContract := "0x835973768750b3ED2D5c3EF5AdcD5eDb44d12aD4"
+
+	batch := types.OutgoingTxBatch{
+		BatchNonce:    7,
+		BatchTimeout:  2111,
+		TokenContract: tokenContract,
+	}
+
+	// a fork's own struct name and field layout, spaces aside
+	batchType, err := ParseBatchConfirmType(
+		"SubmitBatch(bytes32 gravityId, uint64 nonce, address tokenContract, uint256 batchTimeout, bytes32 checkpoint)",
+	)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		"SubmitBatch(bytes32 gravityId,uint64 nonce,address tokenContract,uint256 batchTimeout,bytes32 checkpoint)",
+		batchType.String(),
+	)
+
+	scheme := TypedConfirmScheme{
+		Domain:    EIP712Domain{Name: "Gravity", Version: "2"},
+		BatchType: batchType,
+	}
+
+	var gravityIDBytes [32]byte
+	copy(gravityIDBytes[:], gravityID)
+
+	typedData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": []apitypes.Type{
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+			},
+			"SubmitBatch": []apitypes.Type{
+				{Name: "gravityId", Type: "bytes32"},
+				{Name: "nonce", Type: "uint64"},
+				{Name: "tokenContract", Type: "address"},
+				{Name: "batchTimeout", Type: "uint256"},
+				{Name: "checkpoint", Type: "bytes32"},
+			},
+		},
+		PrimaryType: "SubmitBatch",
+		Domain:      apitypes.TypedDataDomain{Name: "Gravity", Version: "2"},
+		Message: apitypes.TypedDataMessage{
+			"gravityId":     gravityIDBytes[:],
+			"nonce":         math.NewHexOrDecimal256(7),
+			"tokenContract": tokenContract,
+			"batchTimeout":  math.NewHexOrDecimal256(2111),
+			"checkpoint":    EncodeTxBatchConfirm(gravityID, batch).Bytes(),
+		},
+	}
+
+	expectedHash, _, err := apitypes.TypedDataAndHash(typedData)
+	require.NoError(t, err)
+	assert.Equal(t, expectedHash, crypto.Keccak256(EncodeTypedBatchConfirm(scheme, gravityID, batch)))
+}
+
+func TestParseTypedConfirmType(t *testing.T) {
+	testCases := map[string]string{
+		"no parentheses":  "ValsetConfirm",
+		"no field":        "ValsetConfirm()",
+		"unknown field":   "ValsetConfirm(bytes32 digest)",
+		"batch field":     "ValsetConfirm(address tokenContract)",
+		"duplicate field": "ValsetConfirm(bytes32 checkpoint,bytes32 checkpoint)",
+		"wrong type":      "ValsetConfirm(bytes checkpoint)",
+		"too narrow":      "ValsetConfirm(uint32 nonce)",
+		"narrow amount":   "ValsetConfirm(uint128 rewardAmount)",
+		"not a uint":      "ValsetConfirm(uint+64 nonce)",
+		"nested":          "ValsetConfirm(Valset valset)",
+	}
+
+	for name, encodeType := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseValsetConfirmType(encodeType)
+			assert.Error(t, err)
+		})
+	}
+
+	valsetType, err := ParseValsetConfirmType("Confirm(uint256 nonce,address rewardToken,uint256 rewardAmount)")
+	require.NoError(t, err)
+	assert.Equal(t, "Confirm(uint256 nonce,address rewardToken,uint256 rewardAmount)", valsetType.String())
+}
+
+func TestEIP712DomainSeparator(t *testing.T) {
+	// only the fields that are set are part of the domain
+	domain := EIP712Domain{Name: "Gravity", Version: "1"}
+
+	expected := crypto.Keccak256Hash(
+		crypto.Keccak256([]byte("EIP712Domain(string name,string version)")),
+		crypto.Keccak256([]byte("Gravity")),
+		crypto.Keccak256([]byte("1")),
+	)
+	assert.Equal(t, expected, domain.Separator())
+
+	domain.ChainID = big.NewInt(1)
+	assert.NotEqual(t, expected, domain.Separator())
+
+	// the example domain of the EIP-712 specification
+	domain = EIP712Domain{
+		Name:              "Ether Mail",
+		Version:           "1",
+		ChainID:           big.NewInt(1),
+		VerifyingContract: ethcmn.HexToAddress("0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"),
+	}
+	assert.Equal(
+		t,
+		"0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f",
+		domain.Separator().Hex(),
+	)
+}
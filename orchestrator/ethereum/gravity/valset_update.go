@@ -65,7 +65,7 @@ func (s *gravityContract) EncodeValsetUpdate(
 		})
 	}
 
-	txData, err := gravityABI.Pack("updateValset",
+	txData, err := s.gravityABI.Pack("updateValset",
 		newValsetArgs,
 		currentValsetArgs,
 		sigArray,
@@ -25,6 +25,7 @@ type KeyCache interface {
 	UnsetKey(account ethcmn.Address, password string)
 	SignerFn(chainID uint64, account ethcmn.Address, password string) (SignerFn, error)
 	PersonalSignFn(account ethcmn.Address, password string) (PersonalSignFn, error)
+	TypedDataSignFn(account ethcmn.Address, password string) (TypedDataSignFn, error)
 }
 
 func NewKeyCache() KeyCache {
@@ -141,6 +142,28 @@ func (k *keyCache) PersonalSignFn(account ethcmn.Address, password string) (Pers
 	return signFn, nil
 }
 
+func (k *keyCache) TypedDataSignFn(account ethcmn.Address, password string) (TypedDataSignFn, error) {
+	key, err := k.PrivateKey(account, password)
+	if err != nil {
+		return nil, err
+	}
+
+	keyAddress := crypto.PubkeyToAddress(key.PublicKey)
+	if keyAddress != account {
+		return nil, errors.New("account key address mismatch")
+	}
+
+	signFn := func(from ethcmn.Address, typedData []byte) (sig []byte, err error) {
+		if from != keyAddress {
+			return nil, errors.New("from address mismatch")
+		}
+
+		return crypto.Sign(crypto.Keccak256(typedData), key)
+	}
+
+	return signFn, nil
+}
+
 var hashSep = []byte("-")
 
 func hashAccountPass(account ethcmn.Address, password string) []byte {
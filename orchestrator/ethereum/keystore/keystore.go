@@ -19,12 +19,17 @@ import (
 
 type PersonalSignFn func(account ethcmn.Address, data []byte) (sig []byte, err error)
 
+// TypedDataSignFn signs the keccak256 hash of EIP-712 encoded typed data
+// ("\x19\x01" || domainSeparator || hashStruct).
+type TypedDataSignFn func(account ethcmn.Address, typedData []byte) (sig []byte, err error)
+
 type SignerFn = bind.SignerFn
 
 type EthKeyStore interface {
 	PrivateKey(account ethcmn.Address, password string) (*ecdsa.PrivateKey, error)
 	SignerFn(chainID uint64, account ethcmn.Address, password string) (SignerFn, error)
 	PersonalSignFn(account ethcmn.Address, password string) (PersonalSignFn, error)
+	TypedDataSignFn(account ethcmn.Address, password string) (TypedDataSignFn, error)
 	UnsetKey(account ethcmn.Address, password string)
 	Accounts() []ethcmn.Address
 	AddPath(keystorePath string) error
@@ -67,6 +72,10 @@ func (ks *keyStore) PersonalSignFn(account ethcmn.Address, password string) (Per
 	return ks.cache.PersonalSignFn(account, password)
 }
 
+func (ks *keyStore) TypedDataSignFn(account ethcmn.Address, password string) (TypedDataSignFn, error) {
+	return ks.cache.TypedDataSignFn(account, password)
+}
+
 func (ks *keyStore) UnsetKey(account ethcmn.Address, password string) {
 	ks.cache.UnsetKey(account, password)
 }
@@ -197,3 +206,17 @@ func PrivateKeyPersonalSignFn(privKey *ecdsa.PrivateKey) (PersonalSignFn, error)
 
 	return signFn, nil
 }
+
+func PrivateKeyTypedDataSignFn(privKey *ecdsa.PrivateKey) (TypedDataSignFn, error) {
+	keyAddress := crypto.PubkeyToAddress(privKey.PublicKey)
+
+	signFn := func(from ethcmn.Address, typedData []byte) (sig []byte, err error) {
+		if from != keyAddress {
+			return nil, errors.New("from address mismatch")
+		}
+
+		return crypto.Sign(crypto.Keccak256(typedData), privKey)
+	}
+
+	return signFn, nil
+}
@@ -0,0 +1,194 @@
+package provider
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+
+	"github.com/umee-network/peggo/orchestrator/breaker"
+)
+
+// breakerProvider sends every call to the underlying provider through a circuit
+// breaker, so a dying node is sidelined for all loops at once.
+type breakerProvider struct {
+	EVMProviderWithRet
+
+	breaker *breaker.Breaker
+}
+
+// WithCircuitBreaker wraps a provider so its calls go through the given circuit
+// breaker.
+func WithCircuitBreaker(p EVMProviderWithRet, b *breaker.Breaker) EVMProviderWithRet {
+	return &breakerProvider{
+		EVMProviderWithRet: p,
+		breaker:            b,
+	}
+}
+
+// IsEndpointFailure returns true if the error means the node could not answer,
+// as opposed to the node answering with an error (e.g. a reverted call).
+func IsEndpointFailure(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, ethereum.NotFound) || errors.Is(err, ErrVMExecution) {
+		return false
+	}
+
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= 500 || httpErr.StatusCode == 429
+	}
+
+	return true
+}
+
+func (p *breakerProvider) do(fn func() error) error {
+	return p.breaker.Do(fn, IsEndpointFailure)
+}
+
+func (p *breakerProvider) CodeAt(
+	ctx context.Context,
+	contract ethcmn.Address,
+	blockNumber *big.Int,
+) (res []byte, err error) {
+	err = p.do(func() error {
+		res, err = p.EVMProviderWithRet.CodeAt(ctx, contract, blockNumber)
+		return err
+	})
+	return res, err
+}
+
+func (p *breakerProvider) CallContract(
+	ctx context.Context,
+	call ethereum.CallMsg,
+	blockNumber *big.Int,
+) (res []byte, err error) {
+	err = p.do(func() error {
+		res, err = p.EVMProviderWithRet.CallContract(ctx, call, blockNumber)
+		return err
+	})
+	return res, err
+}
+
+func (p *breakerProvider) FilterLogs(ctx context.Context, query ethereum.FilterQuery) (res []types.Log, err error) {
+	err = p.do(func() error {
+		res, err = p.EVMProviderWithRet.FilterLogs(ctx, query)
+		return err
+	})
+	return res, err
+}
+
+func (p *breakerProvider) SubscribeFilterLogs(
+	ctx context.Context,
+	query ethereum.FilterQuery,
+	ch chan<- types.Log,
+) (res ethereum.Subscription, err error) {
+	err = p.do(func() error {
+		res, err = p.EVMProviderWithRet.SubscribeFilterLogs(ctx, query, ch)
+		return err
+	})
+	return res, err
+}
+
+func (p *breakerProvider) PendingNonceAt(ctx context.Context, account ethcmn.Address) (res uint64, err error) {
+	err = p.do(func() error {
+		res, err = p.EVMProviderWithRet.PendingNonceAt(ctx, account)
+		return err
+	})
+	return res, err
+}
+
+func (p *breakerProvider) PendingCodeAt(ctx context.Context, account ethcmn.Address) (res []byte, err error) {
+	err = p.do(func() error {
+		res, err = p.EVMProviderWithRet.PendingCodeAt(ctx, account)
+		return err
+	})
+	return res, err
+}
+
+func (p *breakerProvider) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (res uint64, err error) {
+	err = p.do(func() error {
+		res, err = p.EVMProviderWithRet.EstimateGas(ctx, msg)
+		return err
+	})
+	return res, err
+}
+
+func (p *breakerProvider) SuggestGasPrice(ctx context.Context) (res *big.Int, err error) {
+	err = p.do(func() error {
+		res, err = p.EVMProviderWithRet.SuggestGasPrice(ctx)
+		return err
+	})
+	return res, err
+}
+
+func (p *breakerProvider) SuggestGasTipCap(ctx context.Context) (res *big.Int, err error) {
+	err = p.do(func() error {
+		res, err = p.EVMProviderWithRet.SuggestGasTipCap(ctx)
+		return err
+	})
+	return res, err
+}
+
+func (p *breakerProvider) CreateAccessList(
+	ctx context.Context,
+	msg ethereum.CallMsg,
+) (accessList *types.AccessList, gasUsed uint64, err error) {
+	err = p.do(func() error {
+		accessList, gasUsed, err = p.EVMProviderWithRet.CreateAccessList(ctx, msg)
+		return err
+	})
+	return accessList, gasUsed, err
+}
+
+func (p *breakerProvider) TransactionByHash(
+	ctx context.Context,
+	hash ethcmn.Hash,
+) (tx *types.Transaction, isPending bool, err error) {
+	err = p.do(func() error {
+		tx, isPending, err = p.EVMProviderWithRet.TransactionByHash(ctx, hash)
+		return err
+	})
+	return tx, isPending, err
+}
+
+func (p *breakerProvider) TransactionReceipt(ctx context.Context, txHash ethcmn.Hash) (res *types.Receipt, err error) {
+	err = p.do(func() error {
+		res, err = p.EVMProviderWithRet.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return res, err
+}
+
+func (p *breakerProvider) HeaderByNumber(ctx context.Context, number *big.Int) (res *types.Header, err error) {
+	err = p.do(func() error {
+		res, err = p.EVMProviderWithRet.HeaderByNumber(ctx, number)
+		return err
+	})
+	return res, err
+}
+
+func (p *breakerProvider) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return p.do(func() error {
+		return p.EVMProviderWithRet.SendTransaction(ctx, tx)
+	})
+}
+
+func (p *breakerProvider) SendTransactionWithRet(
+	ctx context.Context,
+	tx *types.Transaction,
+) (txHash ethcmn.Hash, err error) {
+	err = p.do(func() error {
+		txHash, err = p.EVMProviderWithRet.SendTransactionWithRet(ctx, tx)
+		return err
+	})
+	return txHash, err
+}
@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/umee-network/peggo/orchestrator/cache"
+)
+
+// CacheConfig defines the sizes of the provider caches. Only the blocks at
+// least Confirmations blocks behind the latest known header are cached, so a
+// reorg can't leave stale entries behind.
+type CacheConfig struct {
+	Headers       int
+	Receipts      int
+	Confirmations uint64
+	Metrics       *cache.Metrics
+}
+
+// cacheProvider caches the headers and receipts of confirmed blocks, which the
+// orchestrator loops otherwise fetch over and over.
+type cacheProvider struct {
+	EVMProviderWithRet
+
+	confirmations uint64
+	latest        uint64 // atomic
+	headers       *cache.LRU[uint64, *types.Header]
+	receipts      *cache.LRU[ethcmn.Hash, *types.Receipt]
+}
+
+// WithCache wraps a provider so the headers and receipts of confirmed blocks
+// are served from bounded in-memory caches.
+func WithCache(p EVMProviderWithRet, cfg CacheConfig) EVMProviderWithRet {
+	return &cacheProvider{
+		EVMProviderWithRet: p,
+		confirmations:      cfg.Confirmations,
+		headers: cache.New[uint64, *types.Header](
+			cfg.Headers,
+			cache.OptionMetrics(cfg.Metrics, "eth_headers"),
+		),
+		receipts: cache.New[ethcmn.Hash, *types.Receipt](
+			cfg.Receipts,
+			cache.OptionMetrics(cfg.Metrics, "eth_receipts"),
+		),
+	}
+}
+
+// confirmed returns whether the block is far enough behind the latest known
+// header to be cached.
+func (p *cacheProvider) confirmed(number *big.Int) bool {
+	if number == nil || !number.IsUint64() {
+		return false
+	}
+
+	latest := atomic.LoadUint64(&p.latest)
+	return latest >= p.confirmations && number.Uint64() <= latest-p.confirmations
+}
+
+func (p *cacheProvider) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number != nil && number.IsUint64() {
+		if header, ok := p.headers.Get(number.Uint64()); ok {
+			return header, nil
+		}
+	}
+
+	header, err := p.EVMProviderWithRet.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	if number == nil {
+		// the latest header only ever moves forward here, even if the node
+		// serving it lags behind
+		for {
+			latest := atomic.LoadUint64(&p.latest)
+			if header.Number.Uint64() <= latest ||
+				atomic.CompareAndSwapUint64(&p.latest, latest, header.Number.Uint64()) {
+				break
+			}
+		}
+
+		return header, nil
+	}
+
+	if p.confirmed(header.Number) {
+		p.headers.Add(header.Number.Uint64(), header)
+	}
+
+	return header, nil
+}
+
+func (p *cacheProvider) TransactionReceipt(ctx context.Context, txHash ethcmn.Hash) (*types.Receipt, error) {
+	if receipt, ok := p.receipts.Get(txHash); ok {
+		return receipt, nil
+	}
+
+	receipt, err := p.EVMProviderWithRet.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.confirmed(receipt.BlockNumber) {
+		p.receipts.Add(txHash, receipt)
+	}
+
+	return receipt, nil
+}
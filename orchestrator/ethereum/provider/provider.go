@@ -12,10 +12,15 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// ErrVMExecution is returned when the node answered but the call failed during
+// its execution (e.g. it reverted).
+var ErrVMExecution = errors.New("vm execution failed")
+
 type EVMProvider interface {
 	bind.ContractCaller
 	bind.ContractFilterer
@@ -29,6 +34,10 @@ type EVMProvider interface {
 	SendTransaction(ctx context.Context, tx *types.Transaction) error
 	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
 	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+
+	// CreateAccessList returns the EIP-2930 access list of a call and the gas
+	// it uses with that access list.
+	CreateAccessList(ctx context.Context, msg ethereum.CallMsg) (*types.AccessList, uint64, error)
 }
 
 type EVMProviderWithRet interface {
@@ -68,6 +77,22 @@ func (p *evmProviderWithRet) SendTransactionWithRet(
 	return txHash, nil
 }
 
+func (p *evmProviderWithRet) CreateAccessList(
+	ctx context.Context,
+	msg ethereum.CallMsg,
+) (*types.AccessList, uint64, error) {
+	accessList, gasUsed, vmErr, err := gethclient.New(p.rc).CreateAccessList(ctx, msg)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if vmErr != "" {
+		return nil, 0, errors.Wrap(ErrVMExecution, vmErr)
+	}
+
+	return accessList, gasUsed, nil
+}
+
 type TransactFunc func(opts *bind.TransactOpts, contract *ethcmn.Address, input []byte) (*types.Transaction, error)
 
 func TransactFn(p EVMProviderWithRet, contractAddress ethcmn.Address, txHashOut *ethcmn.Hash) TransactFunc {
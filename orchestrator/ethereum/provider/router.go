@@ -0,0 +1,402 @@
+package provider
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/umee-network/peggo/orchestrator/loops"
+)
+
+const (
+	// latencyWeight is the weight of the latest call in the latency moving
+	// average of an endpoint.
+	latencyWeight = 0.3
+	// maxRouterFailures is the number of consecutive failures after which an
+	// endpoint is unhealthy until it answers again.
+	maxRouterFailures = 3
+	// maxHeightLag is the number of blocks an endpoint can be behind the highest
+	// one before it's unhealthy.
+	maxHeightLag = 3
+)
+
+type (
+	// Endpoint is an EVM RPC endpoint of a Router.
+	Endpoint struct {
+		Name     string
+		Provider EVMProviderWithRet
+		// RPC is used to probe the mempool visibility of the endpoint; without it,
+		// the mempool is considered not visible.
+		RPC *rpc.Client
+	}
+
+	// Router routes the calls between several EVM endpoints based on their
+	// continuously measured health: reads (e.g. log scans) go to the fastest
+	// healthy endpoint, and broadcasts, along with the reads depending on the
+	// mempool, to the fastest healthy one with mempool visibility.
+	Router struct {
+		logger        zerolog.Logger
+		endpoints     []*routedEndpoint
+		probeInterval time.Duration
+
+		mtx         sync.Mutex
+		lastRead    string
+		lastMempool string
+	}
+
+	routedEndpoint struct {
+		Endpoint
+
+		mtx      sync.Mutex
+		latency  time.Duration // moving average of the call latencies
+		failures int           // consecutive endpoint failures
+		height   uint64        // latest height seen by the probes
+		mempool  bool          // whether the endpoint exposes its mempool
+	}
+)
+
+// NewRouter returns a provider routing the calls between the given endpoints,
+// the first one being preferred until the others are measured. Their health is
+// probed every probeInterval once the router is started.
+func NewRouter(logger zerolog.Logger, endpoints []Endpoint, probeInterval time.Duration) (*Router, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("no EVM endpoints to route to")
+	}
+
+	if probeInterval <= 0 {
+		return nil, errors.Errorf("invalid EVM endpoint probe interval: %s", probeInterval)
+	}
+
+	r := &Router{
+		logger:        logger.With().Str("module", "evm_router").Logger(),
+		probeInterval: probeInterval,
+	}
+	for _, e := range endpoints {
+		r.endpoints = append(r.endpoints, &routedEndpoint{Endpoint: e})
+	}
+
+	return r, nil
+}
+
+// Start probes the health of the endpoints every probe interval until the
+// context is done.
+func (r *Router) Start(ctx context.Context) error {
+	return loops.RunLoop(ctx, r.logger, r.probeInterval, func() error {
+		r.probe(ctx)
+		return nil
+	})
+}
+
+// probe measures the latency and height of every endpoint and whether it
+// exposes its mempool.
+func (r *Router) probe(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for _, e := range r.endpoints {
+		e := e
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, r.probeInterval)
+			defer cancel()
+
+			var header *types.Header
+			err := e.observe(func() (err error) {
+				header, err = e.Provider.HeaderByNumber(ctx, nil)
+				return err
+			})
+			if err != nil {
+				r.logger.Debug().Err(err).Str("endpoint", e.Name).Msg("EVM endpoint probe failed")
+				return
+			}
+
+			mempool := false
+			if e.RPC != nil {
+				var status map[string]interface{}
+				mempool = e.RPC.CallContext(ctx, &status, "txpool_status") == nil
+			}
+
+			e.mtx.Lock()
+			e.height = header.Number.Uint64()
+			e.mempool = mempool
+			e.mtx.Unlock()
+		}()
+	}
+
+	wg.Wait()
+}
+
+// observe runs the call and records its latency and whether the endpoint
+// failed to answer.
+func (e *routedEndpoint) observe(fn func() error) error {
+	start := time.Now()
+	err := fn()
+	elapsed := time.Since(start)
+
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	if err != nil && IsEndpointFailure(err) {
+		e.failures++
+		return err
+	}
+
+	e.failures = 0
+	if e.latency == 0 {
+		e.latency = elapsed
+	} else {
+		e.latency = time.Duration(latencyWeight*float64(elapsed) + (1-latencyWeight)*float64(e.latency))
+	}
+
+	return err
+}
+
+// pick returns the endpoints ordered by preference: healthy ones first, by
+// latency, then unhealthy ones by failures. With needsMempool, the healthy
+// endpoints exposing their mempool come before the other healthy ones.
+func (r *Router) pick(needsMempool bool) []*routedEndpoint {
+	type candidate struct {
+		e        *routedEndpoint
+		healthy  bool
+		mempool  bool
+		latency  time.Duration
+		failures int
+		height   uint64
+	}
+
+	var bestHeight uint64
+	candidates := make([]candidate, len(r.endpoints))
+	for i, e := range r.endpoints {
+		e.mtx.Lock()
+		candidates[i] = candidate{e: e, mempool: e.mempool, latency: e.latency, failures: e.failures, height: e.height}
+		e.mtx.Unlock()
+
+		if candidates[i].height > bestHeight {
+			bestHeight = candidates[i].height
+		}
+	}
+
+	for i, c := range candidates {
+		candidates[i].healthy = c.failures < maxRouterFailures && c.height+maxHeightLag >= bestHeight
+	}
+
+	less := func(a, b candidate) bool {
+		if a.healthy != b.healthy {
+			return a.healthy
+		}
+		if !a.healthy {
+			return a.failures < b.failures
+		}
+		if needsMempool && a.mempool != b.mempool {
+			return a.mempool
+		}
+
+		return a.latency < b.latency
+	}
+
+	// insertion sort, keeping the configured order between equal endpoints
+	for i := 1; i < len(candidates); i++ {
+		for j := i; j > 0 && less(candidates[j], candidates[j-1]); j-- {
+			candidates[j], candidates[j-1] = candidates[j-1], candidates[j]
+		}
+	}
+
+	ordered := make([]*routedEndpoint, len(candidates))
+	for i, c := range candidates {
+		ordered[i] = c.e
+	}
+
+	r.logRoute(needsMempool, ordered[0].Name)
+
+	return ordered
+}
+
+// logRoute logs when the endpoint preferred for reads or for the calls needing
+// the mempool changes.
+func (r *Router) logRoute(mempool bool, name string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	last := &r.lastRead
+	if mempool {
+		last = &r.lastMempool
+	}
+
+	if *last == name {
+		return
+	}
+
+	if *last != "" {
+		r.logger.Info().Str("endpoint", name).Bool("mempool", mempool).Msg("routing EVM calls to another endpoint")
+	}
+	*last = name
+}
+
+// read runs an idempotent call on the preferred endpoint, retrying on the next
+// one if the endpoint failed to answer.
+func (r *Router) read(needsMempool bool, fn func(p EVMProviderWithRet) error) error {
+	ordered := r.pick(needsMempool)
+
+	var err error
+	for i, e := range ordered {
+		if i == 2 {
+			break
+		}
+
+		err = e.observe(func() error { return fn(e.Provider) })
+		if err == nil || !IsEndpointFailure(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// broadcast sends a transaction through the preferred endpoint only, as the
+// transaction may have been received despite the failure.
+func (r *Router) broadcast(fn func(p EVMProviderWithRet) error) error {
+	e := r.pick(true)[0]
+	return e.observe(func() error { return fn(e.Provider) })
+}
+
+func (r *Router) CodeAt(ctx context.Context, contract ethcmn.Address, blockNumber *big.Int) (res []byte, err error) {
+	err = r.read(false, func(p EVMProviderWithRet) error {
+		res, err = p.CodeAt(ctx, contract, blockNumber)
+		return err
+	})
+	return res, err
+}
+
+func (r *Router) CallContract(
+	ctx context.Context,
+	call ethereum.CallMsg,
+	blockNumber *big.Int,
+) (res []byte, err error) {
+	err = r.read(false, func(p EVMProviderWithRet) error {
+		res, err = p.CallContract(ctx, call, blockNumber)
+		return err
+	})
+	return res, err
+}
+
+func (r *Router) FilterLogs(ctx context.Context, query ethereum.FilterQuery) (logs []types.Log, err error) {
+	err = r.read(false, func(p EVMProviderWithRet) error {
+		logs, err = p.FilterLogs(ctx, query)
+		return err
+	})
+	return logs, err
+}
+
+func (r *Router) SubscribeFilterLogs(
+	ctx context.Context,
+	query ethereum.FilterQuery,
+	ch chan<- types.Log,
+) (sub ethereum.Subscription, err error) {
+	err = r.read(false, func(p EVMProviderWithRet) error {
+		sub, err = p.SubscribeFilterLogs(ctx, query, ch)
+		return err
+	})
+	return sub, err
+}
+
+func (r *Router) PendingNonceAt(ctx context.Context, account ethcmn.Address) (nonce uint64, err error) {
+	err = r.read(true, func(p EVMProviderWithRet) error {
+		nonce, err = p.PendingNonceAt(ctx, account)
+		return err
+	})
+	return nonce, err
+}
+
+func (r *Router) PendingCodeAt(ctx context.Context, account ethcmn.Address) (code []byte, err error) {
+	err = r.read(true, func(p EVMProviderWithRet) error {
+		code, err = p.PendingCodeAt(ctx, account)
+		return err
+	})
+	return code, err
+}
+
+func (r *Router) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (gas uint64, err error) {
+	err = r.read(false, func(p EVMProviderWithRet) error {
+		gas, err = p.EstimateGas(ctx, msg)
+		return err
+	})
+	return gas, err
+}
+
+func (r *Router) SuggestGasPrice(ctx context.Context) (price *big.Int, err error) {
+	err = r.read(true, func(p EVMProviderWithRet) error {
+		price, err = p.SuggestGasPrice(ctx)
+		return err
+	})
+	return price, err
+}
+
+func (r *Router) SuggestGasTipCap(ctx context.Context) (tip *big.Int, err error) {
+	err = r.read(true, func(p EVMProviderWithRet) error {
+		tip, err = p.SuggestGasTipCap(ctx)
+		return err
+	})
+	return tip, err
+}
+
+func (r *Router) TransactionByHash(
+	ctx context.Context,
+	hash ethcmn.Hash,
+) (tx *types.Transaction, isPending bool, err error) {
+	err = r.read(true, func(p EVMProviderWithRet) error {
+		tx, isPending, err = p.TransactionByHash(ctx, hash)
+		return err
+	})
+	return tx, isPending, err
+}
+
+func (r *Router) TransactionReceipt(ctx context.Context, txHash ethcmn.Hash) (receipt *types.Receipt, err error) {
+	err = r.read(false, func(p EVMProviderWithRet) error {
+		receipt, err = p.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return receipt, err
+}
+
+func (r *Router) HeaderByNumber(ctx context.Context, number *big.Int) (header *types.Header, err error) {
+	err = r.read(false, func(p EVMProviderWithRet) error {
+		header, err = p.HeaderByNumber(ctx, number)
+		return err
+	})
+	return header, err
+}
+
+func (r *Router) CreateAccessList(
+	ctx context.Context,
+	msg ethereum.CallMsg,
+) (accessList *types.AccessList, gas uint64, err error) {
+	err = r.read(false, func(p EVMProviderWithRet) error {
+		accessList, gas, err = p.CreateAccessList(ctx, msg)
+		return err
+	})
+	return accessList, gas, err
+}
+
+func (r *Router) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return r.broadcast(func(p EVMProviderWithRet) error {
+		return p.SendTransaction(ctx, tx)
+	})
+}
+
+func (r *Router) SendTransactionWithRet(ctx context.Context, tx *types.Transaction) (txHash ethcmn.Hash, err error) {
+	err = r.broadcast(func(p EVMProviderWithRet) error {
+		txHash, err = p.SendTransactionWithRet(ctx, tx)
+		return err
+	})
+	return txHash, err
+}
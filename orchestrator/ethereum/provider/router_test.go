@@ -0,0 +1,235 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umee-network/peggo/mocks"
+)
+
+// endpointState is the measured health of a routed endpoint.
+type endpointState struct {
+	name     string
+	latency  time.Duration
+	failures int
+	height   uint64
+	mempool  bool
+}
+
+func newTestRouter(t *testing.T, states ...endpointState) *Router {
+	var endpoints []Endpoint
+	for _, s := range states {
+		endpoints = append(endpoints, Endpoint{Name: s.name})
+	}
+
+	r, err := NewRouter(zerolog.Nop(), endpoints, time.Second)
+	require.NoError(t, err)
+
+	for i, s := range states {
+		e := r.endpoints[i]
+		e.latency, e.failures, e.height, e.mempool = s.latency, s.failures, s.height, s.mempool
+	}
+
+	return r
+}
+
+func names(endpoints []*routedEndpoint) []string {
+	var ordered []string
+	for _, e := range endpoints {
+		ordered = append(ordered, e.Name)
+	}
+
+	return ordered
+}
+
+func TestRouterPick(t *testing.T) {
+	testCases := []struct {
+		name         string
+		states       []endpointState
+		needsMempool bool
+		expected     []string
+	}{
+		{
+			name:     "unmeasured endpoints keep the configured order",
+			states:   []endpointState{{name: "a"}, {name: "b"}, {name: "c"}},
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name: "healthy endpoints by latency",
+			states: []endpointState{
+				{name: "a", latency: 30 * time.Millisecond, height: 100},
+				{name: "b", latency: 10 * time.Millisecond, height: 100},
+				{name: "c", latency: 20 * time.Millisecond, height: 100},
+			},
+			expected: []string{"b", "c", "a"},
+		},
+		{
+			name: "failing endpoints last, by failures",
+			states: []endpointState{
+				{name: "a", latency: 10 * time.Millisecond, failures: maxRouterFailures + 1, height: 100},
+				{name: "b", latency: 10 * time.Millisecond, failures: maxRouterFailures, height: 100},
+				{name: "c", latency: 50 * time.Millisecond, failures: maxRouterFailures - 1, height: 100},
+			},
+			expected: []string{"c", "b", "a"},
+		},
+		{
+			name: "lagging endpoint last",
+			states: []endpointState{
+				{name: "a", latency: 10 * time.Millisecond, height: 100 - maxHeightLag - 1},
+				{name: "b", latency: 50 * time.Millisecond, height: 100},
+			},
+			expected: []string{"b", "a"},
+		},
+		{
+			name: "endpoint within the height lag stays healthy",
+			states: []endpointState{
+				{name: "a", latency: 10 * time.Millisecond, height: 100 - maxHeightLag},
+				{name: "b", latency: 50 * time.Millisecond, height: 100},
+			},
+			expected: []string{"a", "b"},
+		},
+		{
+			name: "reads ignore the mempool",
+			states: []endpointState{
+				{name: "a", latency: 10 * time.Millisecond, height: 100},
+				{name: "b", latency: 50 * time.Millisecond, height: 100, mempool: true},
+			},
+			expected: []string{"a", "b"},
+		},
+		{
+			name: "mempool calls prefer the endpoints exposing it",
+			states: []endpointState{
+				{name: "a", latency: 10 * time.Millisecond, height: 100},
+				{name: "b", latency: 50 * time.Millisecond, height: 100, mempool: true},
+			},
+			needsMempool: true,
+			expected:     []string{"b", "a"},
+		},
+		{
+			name: "mempool calls prefer a healthy endpoint to a lagging one with a mempool",
+			states: []endpointState{
+				{name: "a", latency: 10 * time.Millisecond, height: 100},
+				{name: "b", latency: 10 * time.Millisecond, height: 90, mempool: true},
+			},
+			needsMempool: true,
+			expected:     []string{"a", "b"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newTestRouter(t, tc.states...)
+			assert.Equal(t, tc.expected, names(r.pick(tc.needsMempool)))
+		})
+	}
+}
+
+func TestRouterReadFailover(t *testing.T) {
+	endpointErr := errors.New("connection refused")
+
+	testCases := []struct {
+		name     string
+		errs     []error // returned by each endpoint, in the configured order
+		expected error
+		called   []bool
+	}{
+		{
+			name:   "first endpoint answers",
+			errs:   []error{nil, nil, nil},
+			called: []bool{true, false, false},
+		},
+		{
+			name:   "endpoint failure retried on the next endpoint",
+			errs:   []error{endpointErr, nil, nil},
+			called: []bool{true, true, false},
+		},
+		{
+			name:     "call error not retried",
+			errs:     []error{ethereum.NotFound, nil, nil},
+			expected: ethereum.NotFound,
+			called:   []bool{true, false, false},
+		},
+		{
+			name:     "at most two endpoints tried",
+			errs:     []error{endpointErr, endpointErr, nil},
+			expected: endpointErr,
+			called:   []bool{true, true, false},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			var endpoints []Endpoint
+			for i, err := range tc.errs {
+				p := mocks.NewMockEVMProviderWithRet(mockCtrl)
+				if tc.called[i] {
+					p.EXPECT().HeaderByNumber(gomock.Any(), gomock.Nil()).
+						Return(&types.Header{Number: big.NewInt(100)}, err)
+				}
+				endpoints = append(endpoints, Endpoint{Name: string(rune('a' + i)), Provider: p})
+			}
+
+			r, err := NewRouter(zerolog.Nop(), endpoints, time.Second)
+			require.NoError(t, err)
+
+			_, err = r.HeaderByNumber(context.Background(), nil)
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+}
+
+func TestRouterRecovery(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	endpointErr := errors.New("connection refused")
+	primary := mocks.NewMockEVMProviderWithRet(mockCtrl)
+	backup := mocks.NewMockEVMProviderWithRet(mockCtrl)
+
+	r, err := NewRouter(zerolog.Nop(), []Endpoint{
+		{Name: "primary", Provider: primary},
+		{Name: "backup", Provider: backup},
+	}, time.Second)
+	require.NoError(t, err)
+
+	// the backup is much slower, so it's only used while the primary is down
+	r.endpoints[1].latency = time.Hour
+
+	// the primary fails until it's unhealthy, each read failing over to the
+	// backup
+	primary.EXPECT().HeaderByNumber(gomock.Any(), gomock.Nil()).Return(nil, endpointErr).Times(maxRouterFailures)
+	backup.EXPECT().HeaderByNumber(gomock.Any(), gomock.Nil()).
+		Return(&types.Header{Number: big.NewInt(100)}, nil).Times(maxRouterFailures + 1)
+
+	for i := 0; i < maxRouterFailures+1; i++ {
+		header, err := r.HeaderByNumber(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(100), header.Number.Uint64())
+	}
+	assert.Equal(t, []string{"backup", "primary"}, names(r.pick(false)))
+
+	// a successful probe brings the primary back, but it's lagging behind
+	primary.EXPECT().HeaderByNumber(gomock.Any(), gomock.Nil()).Return(&types.Header{Number: big.NewInt(90)}, nil)
+	backup.EXPECT().HeaderByNumber(gomock.Any(), gomock.Nil()).Return(&types.Header{Number: big.NewInt(100)}, nil)
+	r.probe(context.Background())
+	assert.Equal(t, []string{"backup", "primary"}, names(r.pick(false)))
+
+	// once caught up, the primary is preferred again
+	primary.EXPECT().HeaderByNumber(gomock.Any(), gomock.Nil()).Return(&types.Header{Number: big.NewInt(101)}, nil)
+	backup.EXPECT().HeaderByNumber(gomock.Any(), gomock.Nil()).Return(&types.Header{Number: big.NewInt(101)}, nil)
+	r.probe(context.Background())
+	assert.Equal(t, []string{"primary", "backup"}, names(r.pick(false)))
+}
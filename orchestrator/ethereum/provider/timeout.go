@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// timeoutProvider bounds every call to the underlying provider, using separate
+// timeouts for reads and for broadcasts. A shorter deadline already set on the
+// context is always respected.
+type timeoutProvider struct {
+	EVMProviderWithRet
+
+	readTimeout      time.Duration
+	broadcastTimeout time.Duration
+}
+
+// WithTimeouts wraps a provider so reads (calls, logs, receipts, etc.) and
+// broadcasts are bounded by their own timeouts. A zero timeout leaves that
+// class of calls unbounded.
+func WithTimeouts(p EVMProviderWithRet, readTimeout, broadcastTimeout time.Duration) EVMProviderWithRet {
+	return &timeoutProvider{
+		EVMProviderWithRet: p,
+		readTimeout:        readTimeout,
+		broadcastTimeout:   broadcastTimeout,
+	}
+}
+
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+func (p *timeoutProvider) CodeAt(ctx context.Context, contract ethcmn.Address, blockNumber *big.Int) ([]byte, error) {
+	ctx, cancel := withTimeout(ctx, p.readTimeout)
+	defer cancel()
+
+	return p.EVMProviderWithRet.CodeAt(ctx, contract, blockNumber)
+}
+
+func (p *timeoutProvider) CallContract(
+	ctx context.Context,
+	call ethereum.CallMsg,
+	blockNumber *big.Int,
+) ([]byte, error) {
+	ctx, cancel := withTimeout(ctx, p.readTimeout)
+	defer cancel()
+
+	return p.EVMProviderWithRet.CallContract(ctx, call, blockNumber)
+}
+
+func (p *timeoutProvider) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	ctx, cancel := withTimeout(ctx, p.readTimeout)
+	defer cancel()
+
+	return p.EVMProviderWithRet.FilterLogs(ctx, query)
+}
+
+func (p *timeoutProvider) PendingNonceAt(ctx context.Context, account ethcmn.Address) (uint64, error) {
+	ctx, cancel := withTimeout(ctx, p.readTimeout)
+	defer cancel()
+
+	return p.EVMProviderWithRet.PendingNonceAt(ctx, account)
+}
+
+func (p *timeoutProvider) PendingCodeAt(ctx context.Context, account ethcmn.Address) ([]byte, error) {
+	ctx, cancel := withTimeout(ctx, p.readTimeout)
+	defer cancel()
+
+	return p.EVMProviderWithRet.PendingCodeAt(ctx, account)
+}
+
+func (p *timeoutProvider) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	ctx, cancel := withTimeout(ctx, p.readTimeout)
+	defer cancel()
+
+	return p.EVMProviderWithRet.EstimateGas(ctx, msg)
+}
+
+func (p *timeoutProvider) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	ctx, cancel := withTimeout(ctx, p.readTimeout)
+	defer cancel()
+
+	return p.EVMProviderWithRet.SuggestGasPrice(ctx)
+}
+
+func (p *timeoutProvider) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	ctx, cancel := withTimeout(ctx, p.readTimeout)
+	defer cancel()
+
+	return p.EVMProviderWithRet.SuggestGasTipCap(ctx)
+}
+
+func (p *timeoutProvider) CreateAccessList(
+	ctx context.Context,
+	msg ethereum.CallMsg,
+) (*types.AccessList, uint64, error) {
+	ctx, cancel := withTimeout(ctx, p.readTimeout)
+	defer cancel()
+
+	return p.EVMProviderWithRet.CreateAccessList(ctx, msg)
+}
+
+func (p *timeoutProvider) TransactionByHash(
+	ctx context.Context,
+	hash ethcmn.Hash,
+) (tx *types.Transaction, isPending bool, err error) {
+	ctx, cancel := withTimeout(ctx, p.readTimeout)
+	defer cancel()
+
+	return p.EVMProviderWithRet.TransactionByHash(ctx, hash)
+}
+
+func (p *timeoutProvider) TransactionReceipt(ctx context.Context, txHash ethcmn.Hash) (*types.Receipt, error) {
+	ctx, cancel := withTimeout(ctx, p.readTimeout)
+	defer cancel()
+
+	return p.EVMProviderWithRet.TransactionReceipt(ctx, txHash)
+}
+
+func (p *timeoutProvider) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	ctx, cancel := withTimeout(ctx, p.readTimeout)
+	defer cancel()
+
+	return p.EVMProviderWithRet.HeaderByNumber(ctx, number)
+}
+
+func (p *timeoutProvider) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	ctx, cancel := withTimeout(ctx, p.broadcastTimeout)
+	defer cancel()
+
+	return p.EVMProviderWithRet.SendTransaction(ctx, tx)
+}
+
+func (p *timeoutProvider) SendTransactionWithRet(
+	ctx context.Context,
+	tx *types.Transaction,
+) (txHash ethcmn.Hash, err error) {
+	ctx, cancel := withTimeout(ctx, p.broadcastTimeout)
+	defer cancel()
+
+	return p.EVMProviderWithRet.SendTransactionWithRet(ctx, tx)
+}
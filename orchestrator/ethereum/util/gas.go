@@ -0,0 +1,23 @@
+package util
+
+import (
+	"math/big"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// EffectiveGasPrice returns the price per gas a mined tx paid, from the base fee
+// of its block. Receipts only carry it from go-ethereum v1.11 on, and legacy
+// txs, or blocks before London, simply pay their gas price.
+func EffectiveGasPrice(tx *ethtypes.Transaction, header *ethtypes.Header) (*big.Int, error) {
+	if header.BaseFee == nil {
+		return tx.GasPrice(), nil
+	}
+
+	tip, err := tx.EffectiveGasTip(header.BaseFee)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).Add(header.BaseFee, tip), nil
+}
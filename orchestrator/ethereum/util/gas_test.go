@@ -0,0 +1,36 @@
+package util
+
+import (
+	"math/big"
+	"testing"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectiveGasPrice(t *testing.T) {
+	legacy := ethtypes.NewTx(&ethtypes.LegacyTx{GasPrice: big.NewInt(50)})
+	dynamic := ethtypes.NewTx(&ethtypes.DynamicFeeTx{GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(50)})
+
+	price, err := EffectiveGasPrice(legacy, &ethtypes.Header{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), price.Int64())
+
+	price, err = EffectiveGasPrice(legacy, &ethtypes.Header{BaseFee: big.NewInt(30)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), price.Int64())
+
+	// the tip is paid on top of the base fee
+	price, err = EffectiveGasPrice(dynamic, &ethtypes.Header{BaseFee: big.NewInt(30)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(32), price.Int64())
+
+	// up to the fee cap
+	price, err = EffectiveGasPrice(dynamic, &ethtypes.Header{BaseFee: big.NewInt(49)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), price.Int64())
+
+	_, err = EffectiveGasPrice(dynamic, &ethtypes.Header{BaseFee: big.NewInt(51)})
+	assert.Error(t, err)
+}
@@ -0,0 +1,76 @@
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// CodeReader defines the Ethereum RPC method used to get the deployed code of a
+// contract.
+type CodeReader interface {
+	CodeAt(ctx context.Context, contract ethcmn.Address, blockNumber *big.Int) ([]byte, error)
+}
+
+// VerifyDeployedCode checks that the runtime code deployed at an address was
+// produced by the given creation bytecode (e.g. GravityMetaData.Bin).
+func VerifyDeployedCode(ctx context.Context, client CodeReader, address ethcmn.Address, creationBin string) error {
+	code, err := client.CodeAt(ctx, address, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the deployed code")
+	}
+
+	if len(code) == 0 {
+		return fmt.Errorf("no code deployed at %s", address.Hex())
+	}
+
+	return MatchRuntimeCode(ethcmn.FromHex(creationBin), code)
+}
+
+// MatchRuntimeCode checks that runtime is the code returned by the constructor of
+// the creation bytecode. The solc creation bytecode ends with the runtime code,
+// where immutable variables are left zeroed and only filled in at deployment,
+// so only zero bytes are allowed to differ.
+func MatchRuntimeCode(creation, runtime []byte) error {
+	if len(runtime) == 0 || len(runtime) > len(creation) {
+		return fmt.Errorf(
+			"deployed code size (%d bytes) doesn't match the artifact (%d bytes)",
+			len(runtime), len(creation),
+		)
+	}
+
+	expected := creation[len(creation)-len(runtime):]
+
+	// the metadata hash appended by solc identifies the sources and settings, so
+	// a mismatch there is reported explicitly
+	if expectedMeta, meta := metadata(expected), metadata(runtime); !bytes.Equal(expectedMeta, meta) {
+		return fmt.Errorf("deployed code metadata 0x%x doesn't match the artifact 0x%x", meta, expectedMeta)
+	}
+
+	for i := range runtime {
+		if runtime[i] != expected[i] && expected[i] != 0 {
+			return fmt.Errorf("deployed code differs from the artifact at byte %d", i)
+		}
+	}
+
+	return nil
+}
+
+// metadata returns the CBOR encoded metadata solc appends to the runtime code,
+// including the 2 bytes of its length, or nil if the code has none.
+func metadata(code []byte) []byte {
+	if len(code) < 2 {
+		return nil
+	}
+
+	size := int(code[len(code)-2])<<8 | int(code[len(code)-1])
+	if size+2 > len(code) {
+		return nil
+	}
+
+	return code[len(code)-size-2:]
+}
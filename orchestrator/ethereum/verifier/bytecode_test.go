@@ -0,0 +1,52 @@
+package verifier
+
+import (
+	"testing"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchRuntimeCode(t *testing.T) {
+	// constructor, then the runtime code with a zeroed immutable and the solc
+	// metadata (0xa1 0x00 with its 2 bytes length)
+	constructor := ethcmn.FromHex("0x6080604052f3fe")
+	runtime := ethcmn.FromHex("0x6080604052" + "7f0000000000" + "5b" + "a1000002")
+	creation := append(append([]byte{}, constructor...), runtime...)
+
+	withImmutable := ethcmn.CopyBytes(runtime)
+	withImmutable[7] = 0x42
+
+	otherOpcode := ethcmn.CopyBytes(runtime)
+	otherOpcode[11] = 0x5a
+
+	otherMetadata := ethcmn.CopyBytes(runtime)
+	otherMetadata[12] = 0xa2
+
+	testCases := []struct {
+		name    string
+		runtime []byte
+		err     string
+	}{
+		{"same code", runtime, ""},
+		{"immutable set", withImmutable, ""},
+		{"different opcode", otherOpcode, "differs from the artifact at byte 11"},
+		{"different metadata", otherMetadata, "metadata"},
+		{"larger code", append(creation, 0x00), "size"},
+		{"no code", nil, "size"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := MatchRuntimeCode(creation, tc.runtime)
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package verifier
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	maxRespTime = 30 * time.Second
+
+	// DefaultCompilerVersion is the solc version the embedded contracts were
+	// compiled with.
+	DefaultCompilerVersion = "v0.8.10+commit.fc410830"
+
+	// GravityContractName and CosmosERC20ContractName are the fully qualified
+	// names of the embedded contracts in the Gravity Bridge sources.
+	GravityContractName     = "contracts/Gravity.sol:Gravity"
+	CosmosERC20ContractName = "contracts/CosmosToken.sol:CosmosERC20"
+
+	statusPending = "Pending in queue"
+)
+
+// ErrAlreadyVerified is returned by Submit when the explorer already has the
+// sources of the contract.
+var ErrAlreadyVerified = errors.New("contract source code already verified")
+
+type (
+	// Explorer submits source code verifications to an Etherscan compatible API,
+	// which Blockscout also implements.
+	Explorer struct {
+		client *http.Client
+		apiURL string
+		apiKey string
+	}
+
+	// SourceRequest defines the contract to verify, with its sources in the solc
+	// standard JSON input format.
+	SourceRequest struct {
+		Address         string
+		ContractName    string
+		CompilerVersion string
+		StandardJSON    string
+		ConstructorArgs []byte
+	}
+
+	explorerResponse struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Result  string `json:"result"`
+	}
+)
+
+// NewExplorer returns a client of the explorer API at apiURL, e.g.
+// https://api.etherscan.io/api.
+func NewExplorer(apiURL, apiKey string) (*Explorer, error) {
+	u, err := url.Parse(apiURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid explorer API URL: %s", apiURL)
+	}
+
+	return &Explorer{
+		client: &http.Client{Timeout: maxRespTime},
+		apiURL: apiURL,
+		apiKey: apiKey,
+	}, nil
+}
+
+// Submit submits the sources of a contract and returns the GUID of the
+// verification, to be checked with Status.
+func (e *Explorer) Submit(ctx context.Context, req SourceRequest) (string, error) {
+	form := url.Values{
+		"apikey":                {e.apiKey},
+		"module":                {"contract"},
+		"action":                {"verifysourcecode"},
+		"contractaddress":       {req.Address},
+		"sourceCode":            {req.StandardJSON},
+		"codeformat":            {"solidity-standard-json-input"},
+		"contractname":          {req.ContractName},
+		"compilerversion":       {req.CompilerVersion},
+		"constructorArguements": {hex.EncodeToString(req.ConstructorArgs)}, // sic
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, e.apiURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := e.do(httpReq)
+	switch {
+	case err == nil:
+		return resp.Result, nil
+
+	case resp != nil && alreadyVerified(resp.Result):
+		return "", ErrAlreadyVerified
+
+	default:
+		return "", errors.Wrap(err, "failed to submit source verification")
+	}
+}
+
+// Status returns whether the verification with the given GUID is done. An
+// error is returned if the explorer rejected the sources.
+func (e *Explorer) Status(ctx context.Context, guid string) (bool, error) {
+	query := url.Values{
+		"apikey": {e.apiKey},
+		"module": {"contract"},
+		"action": {"checkverifystatus"},
+		"guid":   {guid},
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, e.apiURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := e.do(httpReq)
+	switch {
+	case err == nil, resp != nil && alreadyVerified(resp.Result):
+		return true, nil
+
+	case resp != nil && resp.Result == statusPending:
+		return false, nil
+
+	default:
+		return false, errors.Wrap(err, "source verification failed")
+	}
+}
+
+// Verify submits the sources of a contract and waits until the explorer
+// verified them or ctx is done.
+func (e *Explorer) Verify(ctx context.Context, req SourceRequest, pollInterval time.Duration) error {
+	guid, err := e.Submit(ctx, req)
+	if errors.Is(err, ErrAlreadyVerified) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "source verification %s still pending", guid)
+
+		case <-time.After(pollInterval):
+		}
+
+		done, err := e.Status(ctx, guid)
+		if err != nil {
+			return err
+		}
+
+		if done {
+			return nil
+		}
+	}
+}
+
+// do sends a request and decodes the explorer response. The response is also
+// returned on failure, when it could be decoded.
+func (e *Explorer) do(req *http.Request) (*explorerResponse, error) {
+	httpResp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", httpResp.StatusCode)
+	}
+
+	var resp explorerResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return nil, errors.Wrap(err, "failed to decode response")
+	}
+
+	if resp.Status != "1" {
+		return &resp, fmt.Errorf("%s: %s", resp.Message, resp.Result)
+	}
+
+	return &resp, nil
+}
+
+func alreadyVerified(result string) bool {
+	return strings.Contains(strings.ToLower(result), "already verified")
+}
@@ -0,0 +1,110 @@
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplorerVerify(t *testing.T) {
+	var statusChecks int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "key", r.Form.Get("apikey"))
+
+		switch r.Form.Get("action") {
+		case "verifysourcecode":
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "0x0000000000000000000000000000000000000001", r.Form.Get("contractaddress"))
+			assert.Equal(t, GravityContractName, r.Form.Get("contractname"))
+			assert.Equal(t, "0102", r.Form.Get("constructorArguements"))
+			fmt.Fprint(w, `{"status":"1","message":"OK","result":"guid"}`)
+
+		case "checkverifystatus":
+			assert.Equal(t, "guid", r.Form.Get("guid"))
+
+			statusChecks++
+			if statusChecks == 1 {
+				fmt.Fprint(w, `{"status":"0","message":"NOTOK","result":"Pending in queue"}`)
+				return
+			}
+
+			fmt.Fprint(w, `{"status":"1","message":"OK","result":"Pass - Verified"}`)
+		}
+	}))
+	defer server.Close()
+
+	explorer, err := NewExplorer(server.URL, "key")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = explorer.Verify(ctx, SourceRequest{
+		Address:         "0x0000000000000000000000000000000000000001",
+		ContractName:    GravityContractName,
+		CompilerVersion: DefaultCompilerVersion,
+		StandardJSON:    "{}",
+		ConstructorArgs: []byte{0x01, 0x02},
+	}, time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, 2, statusChecks)
+}
+
+func TestExplorerVerifyErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		submit   string
+		status   string
+		expected string
+	}{
+		{
+			name:   "already verified",
+			submit: `{"status":"0","message":"NOTOK","result":"Contract source code already verified"}`,
+		},
+		{
+			name:     "rejected submission",
+			submit:   `{"status":"0","message":"NOTOK","result":"Invalid API Key"}`,
+			expected: "Invalid API Key",
+		},
+		{
+			name:     "failed verification",
+			submit:   `{"status":"1","message":"OK","result":"guid"}`,
+			status:   `{"status":"0","message":"NOTOK","result":"Fail - Unable to verify"}`,
+			expected: "Unable to verify",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("action") == "checkverifystatus" {
+					fmt.Fprint(w, tc.status)
+					return
+				}
+
+				fmt.Fprint(w, tc.submit)
+			}))
+			defer server.Close()
+
+			explorer, err := NewExplorer(server.URL, "key")
+			require.NoError(t, err)
+
+			err = explorer.Verify(context.Background(), SourceRequest{}, time.Millisecond)
+			if tc.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package orchestrator
+
+// SetSkipEventsBeforeNonce returns the orchestrator option ignoring the
+// Ethereum events with a nonce lower than the given one.
+func SetSkipEventsBeforeNonce(nonce uint64) func(GravityOrchestrator) {
+	return func(o GravityOrchestrator) { o.SetSkipEventsBeforeNonce(nonce) }
+}
+
+// SetSkipEventsBeforeNonce makes the Ethereum oracle ignore the events with a
+// nonce lower than the given one, as if they had already been claimed. It is
+// meant for disaster recovery, when the chain moved past old events that can
+// no longer be claimed (e.g. via governance). Zero disables it.
+func (p *gravityOrchestrator) SetSkipEventsBeforeNonce(nonce uint64) {
+	p.skipEventsBeforeNonce = nonce
+}
+
+// lastEventNonce returns the nonce of the last event to consider as claimed: the
+// nonce claimed by this orchestrator or, if it's lower, the last skipped one.
+func (p *gravityOrchestrator) lastEventNonce(claimed uint64) uint64 {
+	if p.skipEventsBeforeNonce == 0 || claimed+1 >= p.skipEventsBeforeNonce {
+		return claimed
+	}
+
+	return p.skipEventsBeforeNonce - 1
+}
@@ -0,0 +1,32 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLastEventNonce(t *testing.T) {
+	testCases := []struct {
+		name     string
+		skip     uint64
+		claimed  uint64
+		expected uint64
+	}{
+		{"disabled", 0, 5, 5},
+		{"never claimed", 0, 0, 0},
+		{"skips the events before the nonce", 100, 5, 99},
+		{"skips from scratch", 100, 0, 99},
+		{"next event is the first one kept", 100, 99, 99},
+		{"already past the nonce", 100, 150, 150},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			orch := &gravityOrchestrator{}
+			orch.SetSkipEventsBeforeNonce(tc.skip)
+
+			assert.Equal(t, tc.expected, orch.lastEventNonce(tc.claimed))
+		})
+	}
+}
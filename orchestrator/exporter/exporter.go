@@ -0,0 +1,529 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/gravity"
+	"github.com/umee-network/peggo/orchestrator/loops"
+)
+
+const (
+	namespace = "peggo"
+
+	// attestationsLimit is how many of the latest attestations are fetched at
+	// once. The query has no pagination, so older attestations are fetched by
+	// nonce.
+	attestationsLimit = 100
+)
+
+type (
+	// GravityCaller defines the Gravity contract methods used by the exporter.
+	GravityCaller interface {
+		StateLastEventNonce(opts *bind.CallOpts) (*big.Int, error)
+		StateLastValsetNonce(opts *bind.CallOpts) (*big.Int, error)
+	}
+
+	// EthHeaderGetter gets the Ethereum headers the event times are read from.
+	EthHeaderGetter interface {
+		HeaderByNumber(ctx context.Context, number *big.Int) (*ethtypes.Header, error)
+	}
+
+	// CosmosBlockGetter gets the Cosmos blocks the attestation times are read
+	// from, e.g. a tmservice.ServiceClient.
+	CosmosBlockGetter interface {
+		GetBlockByHeight(
+			ctx context.Context,
+			in *tmservice.GetBlockByHeightRequest,
+			opts ...grpc.CallOption,
+		) (*tmservice.GetBlockByHeightResponse, error)
+	}
+
+	// Exporter watches both chains and exports bridge health metrics. It
+	// doesn't sign or relay anything.
+	Exporter struct {
+		logger         zerolog.Logger
+		interval       time.Duration
+		gravityQuerier gravitytypes.QueryClient
+		unpacker       codectypes.AnyUnpacker
+		gravityCaller  GravityCaller
+		ethHeaders     EthHeaderGetter
+		cosmosBlocks   CosmosBlockGetter
+		tokenPricer    TokenPricer
+
+		pendingBatches        *prometheus.GaugeVec
+		pendingWithdrawals    *prometheus.GaugeVec
+		pendingWithdrawalsUSD *prometheus.GaugeVec
+		unsignedConfirms      *prometheus.GaugeVec
+		valsetNonceLag        prometheus.Gauge
+		eventNonceLag         prometheus.Gauge
+		depositLatency        prometheus.Histogram
+		claimLatency          *prometheus.HistogramVec
+		updateErrors          prometheus.Counter
+		depositSLO            *depositSLO
+
+		// Ethereum event nonces not yet observed on Cosmos, and when the exporter
+		// first saw them, and the last event nonce whose latency was observed.
+		// Events emitted or observed before the first update are not tracked.
+		eventsInitialized bool
+		lastEthEventNonce uint64
+		lastObservedNonce uint64
+		pendingEvents     map[uint64]time.Time
+	}
+
+	// observedEvent is an Ethereum event whose attestation was observed.
+	observedEvent struct {
+		claimType    gravitytypes.ClaimType
+		ethHeight    uint64 // Ethereum block the event was emitted in
+		cosmosHeight uint64 // Cosmos block the attestation was created in
+	}
+)
+
+// New returns an exporter whose metrics are registered with the given
+// registerer. The USD value metrics are only exported with a TokenPricer.
+func New(
+	logger zerolog.Logger,
+	interval time.Duration,
+	registerer prometheus.Registerer,
+	gravityQuerier gravitytypes.QueryClient,
+	unpacker codectypes.AnyUnpacker,
+	gravityCaller GravityCaller,
+	ethHeaders EthHeaderGetter,
+	cosmosBlocks CosmosBlockGetter,
+	options ...Option,
+) (*Exporter, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid exporter interval: %s", interval)
+	}
+
+	e := &Exporter{
+		logger:         logger.With().Str("module", "exporter").Logger(),
+		interval:       interval,
+		gravityQuerier: gravityQuerier,
+		unpacker:       unpacker,
+		gravityCaller:  gravityCaller,
+		ethHeaders:     ethHeaders,
+		cosmosBlocks:   cosmosBlocks,
+		pendingEvents:  map[uint64]time.Time{},
+
+		pendingBatches: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "pending_batches",
+			Help:      "Number of batches not yet executed on Ethereum.",
+		}, []string{"token_contract"}),
+		pendingWithdrawals: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "pending_withdrawals",
+			Help:      "Amount (in whole tokens, fees included) of the withdrawals in batches not yet executed on Ethereum.",
+		}, []string{"token_contract", "symbol"}),
+		pendingWithdrawalsUSD: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "pending_withdrawals_usd",
+			Help:      "USD value (fees included) of the withdrawals in batches not yet executed on Ethereum.",
+		}, []string{"token_contract", "symbol"}),
+		unsignedConfirms: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "unsigned_confirms",
+			Help:      "Number of confirms still missing from validators for pending valsets and batches.",
+		}, []string{"kind"}),
+		valsetNonceLag: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "valset_nonce_lag",
+			Help:      "Latest valset nonce on Cosmos minus the valset nonce checkpointed on Ethereum.",
+		}),
+		eventNonceLag: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "event_nonce_lag",
+			Help:      "Last event nonce on Ethereum minus the last event nonce observed on Cosmos.",
+		}),
+		depositLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "deposit_latency_seconds",
+			Help:      "Time from an Ethereum event (e.g. a deposit) being emitted until its attestation on Cosmos.",
+			Buckets:   prometheus.ExponentialBuckets(30, 2, 10),
+		}),
+		claimLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "claim_latency_seconds",
+			Help:      "Time from an Ethereum event being emitted until its attestation on Cosmos, per claim type.",
+			Buckets:   prometheus.ExponentialBuckets(30, 2, 10),
+		}, []string{"claim_type"}),
+		updateErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "exporter_update_errors_total",
+			Help:      "Number of failed metric updates.",
+		}),
+	}
+
+	for _, option := range options {
+		option(e)
+	}
+
+	collectors := []prometheus.Collector{
+		e.pendingBatches,
+		e.pendingWithdrawals,
+		e.pendingWithdrawalsUSD,
+		e.unsignedConfirms,
+		e.valsetNonceLag,
+		e.eventNonceLag,
+		e.depositLatency,
+		e.claimLatency,
+		e.updateErrors,
+	}
+	if e.depositSLO != nil {
+		collectors = append(collectors, e.depositSLO.violations, e.depositSLO.burnRate)
+	}
+
+	for _, c := range collectors {
+		if err := registerer.Register(c); err != nil {
+			return nil, errors.Wrap(err, "failed to register metric")
+		}
+	}
+
+	return e, nil
+}
+
+// Start updates the metrics every interval until the context is done. Failures
+// are logged and counted, and retried on the next update.
+func (e *Exporter) Start(ctx context.Context) error {
+	return loops.RunLoop(ctx, e.logger, e.interval, func() error {
+		if err := e.update(ctx); err != nil {
+			e.updateErrors.Inc()
+			e.logger.Err(err).Msg("failed to update bridge metrics")
+		}
+
+		return nil
+	})
+}
+
+func (e *Exporter) update(ctx context.Context) error {
+	callOpts := &bind.CallOpts{Context: ctx}
+
+	ethValsetNonce, err := e.gravityCaller.StateLastValsetNonce(callOpts)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the last valset nonce from the Gravity contract")
+	}
+
+	ethEventNonce, err := e.gravityCaller.StateLastEventNonce(callOpts)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the last event nonce from the Gravity contract")
+	}
+
+	if err := e.updateValsets(ctx, ethValsetNonce.Uint64()); err != nil {
+		return err
+	}
+
+	if err := e.updateBatches(ctx); err != nil {
+		return err
+	}
+
+	return e.updateEvents(ctx, ethEventNonce.Uint64())
+}
+
+// updateValsets sets the valset nonce lag and the confirms missing from the
+// valsets not yet checkpointed on Ethereum. Valsets are signed by their own
+// members.
+func (e *Exporter) updateValsets(ctx context.Context, ethValsetNonce uint64) error {
+	res, err := e.gravityQuerier.LastValsetRequests(ctx, &gravitytypes.QueryLastValsetRequestsRequest{})
+	if err != nil {
+		return errors.Wrap(err, "failed to get the latest valsets")
+	}
+
+	var (
+		latestNonce uint64
+		unsigned    int
+	)
+
+	for _, valset := range res.Valsets {
+		if valset.Nonce > latestNonce {
+			latestNonce = valset.Nonce
+		}
+
+		if valset.Nonce <= ethValsetNonce {
+			continue
+		}
+
+		confirms, err := e.gravityQuerier.ValsetConfirmsByNonce(
+			ctx,
+			&gravitytypes.QueryValsetConfirmsByNonceRequest{Nonce: valset.Nonce},
+		)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the confirms of valset %d", valset.Nonce)
+		}
+
+		signers := make([]string, len(confirms.Confirms))
+		for i, c := range confirms.Confirms {
+			signers[i] = c.EthAddress
+		}
+
+		missing, _ := gravity.MissingSigners(valset, signers)
+		unsigned += len(missing)
+	}
+
+	if latestNonce > ethValsetNonce {
+		e.valsetNonceLag.Set(float64(latestNonce - ethValsetNonce))
+	} else {
+		e.valsetNonceLag.Set(0)
+	}
+
+	e.unsignedConfirms.WithLabelValues("valset").Set(float64(unsigned))
+
+	return nil
+}
+
+// updateBatches sets the pending batches per token and the confirms missing
+// from them. Batches are signed by the members of the current valset.
+func (e *Exporter) updateBatches(ctx context.Context) error {
+	currentValset, err := e.gravityQuerier.CurrentValset(ctx, &gravitytypes.QueryCurrentValsetRequest{})
+	if err != nil {
+		return errors.Wrap(err, "failed to get the current valset")
+	}
+
+	res, err := e.gravityQuerier.OutgoingTxBatches(ctx, &gravitytypes.QueryOutgoingTxBatchesRequest{})
+	if err != nil {
+		return errors.Wrap(err, "failed to get the pending batches")
+	}
+
+	// Reset so tokens without pending batches are dropped.
+	e.pendingBatches.Reset()
+
+	unsigned := 0
+	pending := map[string]*big.Int{}
+
+	for _, batch := range res.Batches {
+		e.pendingBatches.WithLabelValues(batch.TokenContract).Inc()
+
+		if _, ok := pending[batch.TokenContract]; !ok {
+			pending[batch.TokenContract] = new(big.Int)
+		}
+
+		for _, tx := range batch.Transactions {
+			pending[batch.TokenContract].Add(pending[batch.TokenContract], tx.Erc20Token.Amount.BigInt())
+			pending[batch.TokenContract].Add(pending[batch.TokenContract], tx.Erc20Fee.Amount.BigInt())
+		}
+
+		confirms, err := e.gravityQuerier.BatchConfirms(
+			ctx,
+			&gravitytypes.QueryBatchConfirmsRequest{Nonce: batch.BatchNonce, ContractAddress: batch.TokenContract},
+		)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the confirms of batch %d", batch.BatchNonce)
+		}
+
+		signers := make([]string, len(confirms.Confirms))
+		for i, c := range confirms.Confirms {
+			signers[i] = c.EthSigner
+		}
+
+		missing, _ := gravity.MissingSigners(currentValset.Valset, signers)
+		unsigned += len(missing)
+	}
+
+	e.unsignedConfirms.WithLabelValues("batch").Set(float64(unsigned))
+	e.updateTokenValues(ctx, pending)
+
+	return nil
+}
+
+// updateEvents sets the event nonce lag and observes the latency of the
+// Ethereum events observed on Cosmos since the last update.
+func (e *Exporter) updateEvents(ctx context.Context, ethEventNonce uint64) error {
+	observedNonce, events, err := e.latestObservedEvents(ctx)
+	if err != nil {
+		return err
+	}
+
+	if ethEventNonce > observedNonce {
+		e.eventNonceLag.Set(float64(ethEventNonce - observedNonce))
+	} else {
+		e.eventNonceLag.Set(0)
+	}
+
+	now := time.Now()
+
+	if e.eventsInitialized {
+		for nonce := e.lastEthEventNonce + 1; nonce <= ethEventNonce; nonce++ {
+			e.pendingEvents[nonce] = now
+		}
+
+		if err := e.observeLatencies(ctx, observedNonce, events); err != nil {
+			return err
+		}
+	} else {
+		e.lastObservedNonce = observedNonce
+	}
+
+	if !e.eventsInitialized || ethEventNonce > e.lastEthEventNonce {
+		e.lastEthEventNonce = ethEventNonce
+		e.eventsInitialized = true
+	}
+
+	for nonce := range e.pendingEvents {
+		if nonce <= observedNonce {
+			delete(e.pendingEvents, nonce)
+		}
+	}
+
+	if e.depositSLO != nil {
+		e.depositSLO.update(now, e.pendingEvents)
+	}
+
+	return nil
+}
+
+// observeLatencies observes the latency of the events observed after the last
+// observed one, up to observedNonce, in order. The latency runs from the time
+// of the Ethereum block the event was emitted in to the time of the Cosmos
+// block its attestation was created in, i.e. of its first claim: attestations
+// don't record the height they were observed at.
+func (e *Exporter) observeLatencies(
+	ctx context.Context,
+	observedNonce uint64,
+	events map[uint64]observedEvent,
+) error {
+	for nonce := e.lastObservedNonce + 1; nonce <= observedNonce; nonce++ {
+		event, ok := events[nonce]
+		if !ok {
+			var err error
+			if event, ok, err = e.observedEventByNonce(ctx, nonce); err != nil {
+				return err
+			}
+		}
+
+		// pruned attestations are skipped
+		if ok {
+			emittedAt, err := e.ethBlockTime(ctx, event.ethHeight)
+			if err != nil {
+				return err
+			}
+
+			attestedAt, err := e.cosmosBlockTime(ctx, event.cosmosHeight)
+			if err != nil {
+				return err
+			}
+
+			latency := attestedAt.Sub(emittedAt)
+			if latency < 0 {
+				latency = 0
+			}
+
+			e.depositLatency.Observe(latency.Seconds())
+			e.claimLatency.WithLabelValues(claimTypeLabel(event.claimType)).Observe(latency.Seconds())
+
+			if e.depositSLO != nil && event.claimType == gravitytypes.CLAIM_TYPE_SEND_TO_COSMOS {
+				e.depositSLO.observe(attestedAt, latency)
+			}
+		}
+
+		e.lastObservedNonce = nonce
+	}
+
+	return nil
+}
+
+// latestObservedEvents returns the highest event nonce among the latest
+// observed attestations, and their events by nonce.
+func (e *Exporter) latestObservedEvents(ctx context.Context) (uint64, map[uint64]observedEvent, error) {
+	res, err := e.gravityQuerier.GetAttestations(ctx, &gravitytypes.QueryAttestationsRequest{
+		Limit:   attestationsLimit,
+		OrderBy: "desc",
+	})
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "failed to get the latest attestations")
+	}
+
+	var nonce uint64
+	events := map[uint64]observedEvent{}
+
+	for _, att := range res.Attestations {
+		if !att.Observed {
+			continue
+		}
+
+		eventNonce, event, err := e.unpackObservedEvent(att)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		events[eventNonce] = event
+
+		if eventNonce > nonce {
+			nonce = eventNonce
+		}
+	}
+
+	return nonce, events, nil
+}
+
+// observedEventByNonce returns the event of the observed attestation of the
+// given nonce, and false if there is none, e.g. it was pruned.
+func (e *Exporter) observedEventByNonce(ctx context.Context, nonce uint64) (observedEvent, bool, error) {
+	res, err := e.gravityQuerier.GetAttestations(ctx, &gravitytypes.QueryAttestationsRequest{Nonce: nonce})
+	if err != nil {
+		return observedEvent{}, false, errors.Wrapf(err, "failed to get the attestations of event %d", nonce)
+	}
+
+	for _, att := range res.Attestations {
+		if !att.Observed {
+			continue
+		}
+
+		_, event, err := e.unpackObservedEvent(att)
+		return event, err == nil, err
+	}
+
+	return observedEvent{}, false, nil
+}
+
+func (e *Exporter) unpackObservedEvent(att gravitytypes.Attestation) (uint64, observedEvent, error) {
+	var claim gravitytypes.EthereumClaim
+	if err := e.unpacker.UnpackAny(att.Claim, &claim); err != nil {
+		return 0, observedEvent{}, errors.Wrap(err, "failed to unpack attestation claim")
+	}
+
+	return claim.GetEventNonce(), observedEvent{
+		claimType:    claim.GetType(),
+		ethHeight:    claim.GetBlockHeight(),
+		cosmosHeight: att.Height,
+	}, nil
+}
+
+// ethBlockTime returns the time of the Ethereum block at the given height.
+func (e *Exporter) ethBlockTime(ctx context.Context, height uint64) (time.Time, error) {
+	header, err := e.ethHeaders.HeaderByNumber(ctx, new(big.Int).SetUint64(height))
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "failed to get Ethereum block %d", height)
+	}
+
+	return time.Unix(int64(header.Time), 0), nil
+}
+
+// cosmosBlockTime returns the time of the Cosmos block at the given height.
+func (e *Exporter) cosmosBlockTime(ctx context.Context, height uint64) (time.Time, error) {
+	res, err := e.cosmosBlocks.GetBlockByHeight(ctx, &tmservice.GetBlockByHeightRequest{Height: int64(height)})
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "failed to get Cosmos block %d", height)
+	}
+
+	if block := res.GetSdkBlock(); block != nil {
+		return block.Header.Time, nil
+	}
+
+	if block := res.GetBlock(); block != nil {
+		return block.Header.Time, nil
+	}
+
+	return time.Time{}, fmt.Errorf("empty Cosmos block %d", height)
+}
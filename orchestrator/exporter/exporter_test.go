@@ -0,0 +1,211 @@
+package exporter
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/rs/zerolog"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/umee-network/peggo/mocks"
+)
+
+type mockGravityCaller struct {
+	eventNonce  int64
+	valsetNonce int64
+}
+
+func (c *mockGravityCaller) StateLastEventNonce(*bind.CallOpts) (*big.Int, error) {
+	return big.NewInt(c.eventNonce), nil
+}
+
+func (c *mockGravityCaller) StateLastValsetNonce(*bind.CallOpts) (*big.Int, error) {
+	return big.NewInt(c.valsetNonce), nil
+}
+
+// mockEthHeaders dates Ethereum block N at N seconds after 1000.
+type mockEthHeaders struct{}
+
+func (mockEthHeaders) HeaderByNumber(_ context.Context, number *big.Int) (*ethtypes.Header, error) {
+	return &ethtypes.Header{Time: 1000 + number.Uint64()}, nil
+}
+
+// mockCosmosBlocks dates Cosmos block N at N seconds after 1000.
+type mockCosmosBlocks struct{}
+
+func (mockCosmosBlocks) GetBlockByHeight(
+	_ context.Context,
+	req *tmservice.GetBlockByHeightRequest,
+	_ ...grpc.CallOption,
+) (*tmservice.GetBlockByHeightResponse, error) {
+	return &tmservice.GetBlockByHeightResponse{
+		SdkBlock: &tmservice.Block{Header: tmservice.Header{Time: time.Unix(1000+req.Height, 0)}},
+	}, nil
+}
+
+func TestUpdate(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	registry := codectypes.NewInterfaceRegistry()
+	gravitytypes.RegisterInterfaces(registry)
+
+	valset := gravitytypes.Valset{
+		Nonce: 4,
+		Members: []gravitytypes.BridgeValidator{
+			{Power: 3000000000, EthereumAddress: "0x0000000000000000000000000000000000000001"},
+			{Power: 1294967295, EthereumAddress: "0x0000000000000000000000000000000000000002"},
+		},
+	}
+
+	// event N is emitted in Ethereum block 10*N and attested in Cosmos block
+	// 100*N, and event 5 was pruned
+	observedNonce := uint64(3)
+	attestation := func(nonce uint64) gravitytypes.Attestation {
+		claim, err := codectypes.NewAnyWithValue(&gravitytypes.MsgSendToCosmosClaim{
+			EventNonce:  nonce,
+			BlockHeight: 10 * nonce,
+		})
+		require.NoError(t, err)
+
+		return gravitytypes.Attestation{Observed: true, Height: 100 * nonce, Claim: claim}
+	}
+	attestations := func(req *gravitytypes.QueryAttestationsRequest) *gravitytypes.QueryAttestationsResponse {
+		switch req.Nonce {
+		case 0:
+			return &gravitytypes.QueryAttestationsResponse{
+				Attestations: []gravitytypes.Attestation{attestation(observedNonce)},
+			}
+
+		case 5:
+			return &gravitytypes.QueryAttestationsResponse{}
+
+		default:
+			return &gravitytypes.QueryAttestationsResponse{
+				Attestations: []gravitytypes.Attestation{attestation(req.Nonce)},
+			}
+		}
+	}
+
+	mockQClient := mocks.NewMockQueryClient(mockCtrl)
+	mockQClient.EXPECT().
+		LastValsetRequests(gomock.Any(), gomock.Any()).
+		Return(&gravitytypes.QueryLastValsetRequestsResponse{Valsets: []gravitytypes.Valset{valset}}, nil).
+		AnyTimes()
+	mockQClient.EXPECT().
+		ValsetConfirmsByNonce(gomock.Any(), &gravitytypes.QueryValsetConfirmsByNonceRequest{Nonce: 4}).
+		Return(&gravitytypes.QueryValsetConfirmsByNonceResponse{
+			Confirms: []gravitytypes.MsgValsetConfirm{{EthAddress: valset.Members[0].EthereumAddress}},
+		}, nil).
+		AnyTimes()
+	mockQClient.EXPECT().
+		CurrentValset(gomock.Any(), gomock.Any()).
+		Return(&gravitytypes.QueryCurrentValsetResponse{Valset: valset}, nil).
+		AnyTimes()
+	mockQClient.EXPECT().
+		OutgoingTxBatches(gomock.Any(), gomock.Any()).
+		Return(&gravitytypes.QueryOutgoingTxBatchesResponse{
+			Batches: []gravitytypes.OutgoingTxBatch{{BatchNonce: 7, TokenContract: "0xtoken"}},
+		}, nil).
+		AnyTimes()
+	mockQClient.EXPECT().
+		BatchConfirms(gomock.Any(), gomock.Any()).
+		Return(&gravitytypes.QueryBatchConfirmsResponse{}, nil).
+		AnyTimes()
+	mockQClient.EXPECT().
+		GetAttestations(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, req *gravitytypes.QueryAttestationsRequest, _ ...grpc.CallOption) (
+			*gravitytypes.QueryAttestationsResponse, error,
+		) {
+			return attestations(req), nil
+		}).
+		AnyTimes()
+
+	caller := &mockGravityCaller{eventNonce: 5, valsetNonce: 2}
+
+	e, err := New(
+		zerolog.Nop(),
+		1,
+		prometheus.NewRegistry(),
+		mockQClient,
+		registry,
+		caller,
+		mockEthHeaders{},
+		mockCosmosBlocks{},
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, e.update(context.Background()))
+	assert.Equal(t, float64(2), testutil.ToFloat64(e.valsetNonceLag))
+	assert.Equal(t, float64(2), testutil.ToFloat64(e.eventNonceLag))
+	assert.Equal(t, float64(1), testutil.ToFloat64(e.unsignedConfirms.WithLabelValues("valset")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(e.unsignedConfirms.WithLabelValues("batch")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(e.pendingBatches.WithLabelValues("0xtoken")))
+
+	// a new event, then everything gets observed
+	caller.eventNonce = 6
+	require.NoError(t, e.update(context.Background()))
+	assert.Len(t, e.pendingEvents, 1)
+	observedNonce = 6
+	require.NoError(t, e.update(context.Background()))
+	assert.Equal(t, float64(0), testutil.ToFloat64(e.eventNonceLag))
+	assert.Empty(t, e.pendingEvents)
+
+	// the events observed before the first update and the pruned ones are
+	// skipped, the older attestations are fetched by nonce
+	m := &dto.Metric{}
+	require.NoError(t, e.depositLatency.Write(m))
+	assert.Equal(t, uint64(2), m.GetHistogram().GetSampleCount())
+	assert.Equal(t, float64(360+540), m.GetHistogram().GetSampleSum())
+	assert.Equal(t, uint64(6), e.lastObservedNonce)
+
+	m = &dto.Metric{}
+	require.NoError(t, e.claimLatency.WithLabelValues("send_to_cosmos").(prometheus.Metric).Write(m))
+	assert.Equal(t, uint64(2), m.GetHistogram().GetSampleCount())
+}
+
+type mockTokenPricer struct{}
+
+func (mockTokenPricer) TokenPrice(context.Context, ethcmn.Address) (string, decimal.Decimal, error) {
+	return "USDC", decimal.NewFromFloat(0.5), nil
+}
+
+func (mockTokenPricer) TokenDecimals(context.Context, ethcmn.Address) (uint8, error) {
+	return 6, nil
+}
+
+func TestUpdateTokenValues(t *testing.T) {
+	e, err := New(
+		zerolog.Nop(),
+		1,
+		prometheus.NewRegistry(),
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		OptionTokenPricer(mockTokenPricer{}),
+	)
+	require.NoError(t, err)
+
+	token := "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	e.updateTokenValues(context.Background(), map[string]*big.Int{token: big.NewInt(3_000_000)})
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(e.pendingWithdrawals.WithLabelValues(token, "USDC")))
+	assert.Equal(t, 1.5, testutil.ToFloat64(e.pendingWithdrawalsUSD.WithLabelValues(token, "USDC")))
+}
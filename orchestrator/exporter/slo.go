@@ -0,0 +1,122 @@
+package exporter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type (
+	// DepositSLO is a service level objective on the time deposits take from
+	// being seen on Ethereum until they're observed on Cosmos.
+	DepositSLO struct {
+		// Target is the latency deposits should be observed within.
+		Target time.Duration
+		// Objective is the share of deposits that should be observed within
+		// Target (e.g. 0.99).
+		Objective float64
+		// Window is the period the burn rate is computed over.
+		Window time.Duration
+	}
+
+	depositSLO struct {
+		DepositSLO
+
+		outcomes   []sloOutcome // observed deposits within the window, oldest first
+		violations prometheus.Counter
+		burnRate   prometheus.Gauge
+	}
+
+	sloOutcome struct {
+		time time.Time
+		good bool
+	}
+)
+
+// OptionDepositSLO enables the deposit SLO metrics.
+func OptionDepositSLO(slo DepositSLO) Option {
+	return func(e *Exporter) {
+		e.depositSLO = &depositSLO{
+			DepositSLO: slo,
+			violations: prometheus.NewCounter(prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "deposit_slo_violations_total",
+				Help:      "Number of deposits observed on Cosmos later than the SLO target.",
+			}),
+			burnRate: prometheus.NewGauge(prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "deposit_slo_burn_rate",
+				Help:      "Rate the deposit SLO error budget is spent at over the SLO window (1 spends it exactly).",
+			}),
+		}
+	}
+}
+
+// Validate returns an error if the SLO can't be computed.
+func (s DepositSLO) Validate() error {
+	switch {
+	case s.Target <= 0:
+		return fmt.Errorf("invalid deposit SLO target: %s", s.Target)
+
+	case s.Objective <= 0 || s.Objective >= 1:
+		return fmt.Errorf("invalid deposit SLO objective: %g; expected a value between 0 and 1", s.Objective)
+
+	case s.Window <= 0:
+		return fmt.Errorf("invalid deposit SLO window: %s", s.Window)
+	}
+
+	return nil
+}
+
+// observe records a deposit observed on Cosmos after the given latency.
+func (s *depositSLO) observe(now time.Time, latency time.Duration) {
+	good := latency <= s.Target
+	if !good {
+		s.violations.Inc()
+	}
+
+	s.outcomes = append(s.outcomes, sloOutcome{time: now, good: good})
+}
+
+// update sets the burn rate over the window. The pending events already older
+// than the target count as violations too, since events are observed in order
+// and a stuck event holds back every deposit after it.
+func (s *depositSLO) update(now time.Time, pendingEvents map[uint64]time.Time) {
+	start := now.Add(-s.Window)
+
+	i := 0
+	for i < len(s.outcomes) && s.outcomes[i].time.Before(start) {
+		i++
+	}
+	s.outcomes = s.outcomes[i:]
+
+	var total, bad int
+	for _, o := range s.outcomes {
+		total++
+		if !o.good {
+			bad++
+		}
+	}
+
+	for _, seen := range pendingEvents {
+		if now.Sub(seen) > s.Target {
+			total++
+			bad++
+		}
+	}
+
+	if total == 0 {
+		s.burnRate.Set(0)
+		return
+	}
+
+	s.burnRate.Set(float64(bad) / float64(total) / (1 - s.Objective))
+}
+
+// claimTypeLabel returns the label value of a claim type, e.g. send_to_cosmos.
+func claimTypeLabel(claimType gravitytypes.ClaimType) string {
+	return strings.ToLower(strings.TrimPrefix(claimType.String(), "CLAIM_TYPE_"))
+}
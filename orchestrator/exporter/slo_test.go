@@ -0,0 +1,42 @@
+package exporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepositSLO(t *testing.T) {
+	slo := DepositSLO{Target: time.Minute, Objective: 0.9, Window: time.Hour}
+	require.NoError(t, slo.Validate())
+	require.Error(t, DepositSLO{Target: time.Minute, Objective: 1, Window: time.Hour}.Validate())
+
+	e, err := New(zerolog.Nop(), 1, prometheus.NewRegistry(), nil, nil, nil, nil, nil, OptionDepositSLO(slo))
+	require.NoError(t, err)
+
+	s := e.depositSLO
+	now := time.Now()
+
+	s.update(now, nil)
+	assert.Equal(t, float64(0), testutil.ToFloat64(s.burnRate))
+
+	// 1 of 4 deposits late spends the 10% budget 2.5 times as fast
+	s.observe(now.Add(-2*time.Hour), 5*time.Minute)
+	s.observe(now, 30*time.Second)
+	s.observe(now, 30*time.Second)
+	s.observe(now, 30*time.Second)
+	s.observe(now, 2*time.Minute)
+	s.update(now, nil)
+	assert.Equal(t, float64(2), testutil.ToFloat64(s.violations))
+	assert.InDelta(t, 2.5, testutil.ToFloat64(s.burnRate), 0.0001)
+	assert.Len(t, s.outcomes, 4)
+
+	// overdue pending events count as late
+	s.update(now, map[uint64]time.Time{7: now.Add(-2 * time.Minute), 8: now})
+	assert.InDelta(t, 4, testutil.ToFloat64(s.burnRate), 0.0001)
+}
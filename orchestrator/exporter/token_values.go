@@ -0,0 +1,138 @@
+package exporter
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/umee-network/peggo/orchestrator/oracle"
+	"github.com/umee-network/peggo/orchestrator/relayer"
+	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
+)
+
+type (
+	// TokenPricer returns the symbol, USD price and decimals of ERC20 tokens.
+	TokenPricer interface {
+		TokenPrice(ctx context.Context, token ethcmn.Address) (symbol string, price decimal.Decimal, err error)
+		TokenDecimals(ctx context.Context, token ethcmn.Address) (uint8, error)
+	}
+
+	// Option configures optional exporter metrics.
+	Option func(*Exporter)
+
+	tokenPricer struct {
+		symbols relayer.SymbolRetriever
+		oracle  relayer.Oracle
+		backend bind.ContractCaller
+
+		mtx      sync.Mutex
+		decimals map[ethcmn.Address]uint8
+	}
+)
+
+// OptionTokenPricer enables the USD value metrics of pending withdrawals.
+func OptionTokenPricer(p TokenPricer) Option {
+	return func(e *Exporter) { e.tokenPricer = p }
+}
+
+// NewTokenPricer returns a TokenPricer that gets the symbol of a token from the
+// symbol retriever, its price from the oracle and its decimals from the token
+// contract.
+func NewTokenPricer(symbols relayer.SymbolRetriever, o relayer.Oracle, backend bind.ContractCaller) TokenPricer {
+	return &tokenPricer{
+		symbols:  symbols,
+		oracle:   o,
+		backend:  backend,
+		decimals: map[ethcmn.Address]uint8{},
+	}
+}
+
+func (p *tokenPricer) TokenPrice(_ context.Context, token ethcmn.Address) (string, decimal.Decimal, error) {
+	symbol, err := p.symbols.GetTokenSymbol(token)
+	if err != nil {
+		return "", decimal.Decimal{}, errors.Wrap(err, "failed to get token symbol")
+	}
+
+	price, err := p.oracle.GetPrice(symbol)
+	if err != nil {
+		// Subscribe, so the price is there on the next update.
+		if err := p.oracle.SubscribeSymbols(symbol); err != nil {
+			return "", decimal.Decimal{}, errors.Wrapf(err, "failed to subscribe to %s", symbol)
+		}
+
+		return "", decimal.Decimal{}, errors.Wrapf(err, "failed to get %s price", symbol)
+	}
+
+	if p.oracle.IsStale(symbol) {
+		return "", decimal.Decimal{}, errors.Wrapf(oracle.ErrStalePrice, "%s", symbol)
+	}
+
+	usdPrice, err := decimal.NewFromString(price.String())
+	if err != nil {
+		return "", decimal.Decimal{}, err
+	}
+
+	return symbol, usdPrice, nil
+}
+
+func (p *tokenPricer) TokenDecimals(ctx context.Context, token ethcmn.Address) (uint8, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if decimals, ok := p.decimals[token]; ok {
+		return decimals, nil
+	}
+
+	erc20, err := wrappers.NewERC20Caller(token, p.backend)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get ERC20 wrapper")
+	}
+
+	decimals, err := erc20.Decimals(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get token decimals")
+	}
+
+	p.decimals[token] = decimals
+	return decimals, nil
+}
+
+// updateTokenValues sets the amount and USD value of the withdrawals pending in
+// batches per token. Tokens whose price or decimals can't be fetched are
+// skipped until the next update.
+func (e *Exporter) updateTokenValues(ctx context.Context, pending map[string]*big.Int) {
+	if e.tokenPricer == nil {
+		return
+	}
+
+	// Reset so tokens without pending withdrawals are dropped.
+	e.pendingWithdrawals.Reset()
+	e.pendingWithdrawalsUSD.Reset()
+
+	for tokenContract, amount := range pending {
+		token := ethcmn.HexToAddress(tokenContract)
+
+		decimals, err := e.tokenPricer.TokenDecimals(ctx, token)
+		if err != nil {
+			e.logger.Debug().Err(err).Str("token_contract", tokenContract).Msg("skipping pending withdrawal value")
+			continue
+		}
+
+		symbol, price, err := e.tokenPricer.TokenPrice(ctx, token)
+		if err != nil {
+			e.logger.Debug().Err(err).Str("token_contract", tokenContract).Msg("skipping pending withdrawal value")
+			continue
+		}
+
+		// Decimals (uint8) can be safely casted into int32.
+		tokens := decimal.NewFromBigInt(amount, -int32(decimals))
+
+		e.pendingWithdrawals.WithLabelValues(tokenContract, symbol).Set(tokens.InexactFloat64())
+		e.pendingWithdrawalsUSD.WithLabelValues(tokenContract, symbol).Set(tokens.Mul(price).InexactFloat64())
+	}
+}
@@ -0,0 +1,255 @@
+package heartbeat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/keystore"
+	"github.com/umee-network/peggo/orchestrator/loops"
+	payloadv1 "github.com/umee-network/peggo/orchestrator/payload/v1"
+)
+
+const (
+	// HeaderSignature holds the hex encoded personal_sign signature of the
+	// heartbeat.
+	HeaderSignature = "X-Peggo-Signature"
+	// HeaderSigner holds the Ethereum address the heartbeat is signed with.
+	HeaderSigner = "X-Peggo-Signer"
+
+	maxRespTime = 15 * time.Second
+)
+
+type (
+	// EthClient defines the Ethereum RPC methods used to build a heartbeat.
+	EthClient interface {
+		HeaderByNumber(ctx context.Context, number *big.Int) (*ethtypes.Header, error)
+		BalanceAt(ctx context.Context, account ethcmn.Address, blockNumber *big.Int) (*big.Int, error)
+		PendingNonceAt(ctx context.Context, account ethcmn.Address) (uint64, error)
+	}
+
+	// GravityCaller defines the Gravity contract methods used to build a heartbeat.
+	GravityCaller interface {
+		StateLastEventNonce(opts *bind.CallOpts) (*big.Int, error)
+	}
+
+	// Config defines where and how often heartbeats are sent, and on behalf of
+	// which orchestrator.
+	Config struct {
+		Endpoint            string
+		Interval            time.Duration
+		Moniker             string
+		OrchestratorAddress sdk.AccAddress
+		EthAddress          ethcmn.Address
+		// Labels are added to the statuses (see payloadv1.Labels).
+		Labels payloadv1.Labels
+	}
+
+	// Status is the status summary sent on every heartbeat. Values that could
+	// not be fetched are left empty and the reason is added to Errors, so a
+	// monitor still hears from a partially broken orchestrator.
+	Status = payloadv1.HeartbeatStatus
+
+	// Publisher periodically sends a signed Status to an external monitor.
+	Publisher struct {
+		logger         zerolog.Logger
+		client         *http.Client
+		config         Config
+		signFn         keystore.PersonalSignFn
+		tmClient       rpcclient.StatusClient
+		gravityQuerier gravitytypes.QueryClient
+		bankQuerier    banktypes.QueryClient
+		ethClient      EthClient
+		gravityCaller  GravityCaller
+	}
+)
+
+// NewPublisher returns a heartbeat publisher. The endpoint must use HTTPS,
+// unless it points to localhost.
+func NewPublisher(
+	logger zerolog.Logger,
+	config Config,
+	signFn keystore.PersonalSignFn,
+	tmClient rpcclient.StatusClient,
+	gravityQuerier gravitytypes.QueryClient,
+	bankQuerier banktypes.QueryClient,
+	ethClient EthClient,
+	gravityCaller GravityCaller,
+) (*Publisher, error) {
+	if err := validateEndpoint(config.Endpoint); err != nil {
+		return nil, err
+	}
+
+	if config.Interval <= 0 {
+		return nil, fmt.Errorf("invalid heartbeat interval: %s", config.Interval)
+	}
+
+	return &Publisher{
+		logger:         logger.With().Str("module", "heartbeat").Logger(),
+		client:         &http.Client{Timeout: maxRespTime},
+		config:         config,
+		signFn:         signFn,
+		tmClient:       tmClient,
+		gravityQuerier: gravityQuerier,
+		bankQuerier:    bankQuerier,
+		ethClient:      ethClient,
+		gravityCaller:  gravityCaller,
+	}, nil
+}
+
+func validateEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return errors.Wrap(err, "invalid heartbeat endpoint")
+	}
+
+	switch {
+	case strings.EqualFold(u.Scheme, "https"):
+		return nil
+
+	case strings.EqualFold(u.Scheme, "http") && (u.Hostname() == "localhost" || u.Hostname() == "127.0.0.1"):
+		return nil
+
+	default:
+		return fmt.Errorf("heartbeat endpoint must use https: %s", endpoint)
+	}
+}
+
+// Start sends a heartbeat every interval until the context is done. Failing to
+// send a heartbeat is logged and never stops the orchestrator.
+func (p *Publisher) Start(ctx context.Context) error {
+	return loops.RunLoop(ctx, p.logger, p.config.Interval, func() error {
+		status := p.collect(ctx)
+
+		if err := p.publish(ctx, status); err != nil {
+			p.logger.Err(err).Msg("failed to send heartbeat")
+			return nil
+		}
+
+		p.logger.Debug().
+			Int64("cosmos_height", status.CosmosHeight).
+			Uint64("eth_height", status.EthHeight).
+			Msg("sent heartbeat")
+
+		return nil
+	})
+}
+
+// collect builds the current status summary.
+func (p *Publisher) collect(ctx context.Context) Status {
+	ctx, cancel := context.WithTimeout(ctx, p.config.Interval)
+	defer cancel()
+
+	status := Status{
+		Moniker:      p.config.Moniker,
+		Orchestrator: p.config.OrchestratorAddress.String(),
+		EthAddress:   p.config.EthAddress.Hex(),
+		Time:         time.Now().UTC(),
+		Labels:       p.config.Labels,
+	}
+
+	addErr := func(err error, msg string) {
+		status.Errors = append(status.Errors, fmt.Sprintf("%s: %s", msg, err))
+	}
+
+	if res, err := p.tmClient.Status(ctx); err != nil {
+		addErr(err, "failed to get Cosmos status")
+	} else {
+		status.CosmosHeight = res.SyncInfo.LatestBlockHeight
+	}
+
+	if header, err := p.ethClient.HeaderByNumber(ctx, nil); err != nil {
+		addErr(err, "failed to get Ethereum header")
+	} else {
+		status.EthHeight = header.Number.Uint64()
+	}
+
+	if res, err := p.gravityQuerier.LastEventNonceByAddr(ctx, &gravitytypes.QueryLastEventNonceByAddrRequest{
+		Address: p.config.OrchestratorAddress.String(),
+	}); err != nil {
+		addErr(err, "failed to get last claimed event nonce")
+	} else {
+		status.LastClaimedEventNonce = res.EventNonce
+	}
+
+	if nonce, err := p.gravityCaller.StateLastEventNonce(&bind.CallOpts{Context: ctx}); err != nil {
+		addErr(err, "failed to get Ethereum last event nonce")
+	} else {
+		status.EthLastEventNonce = nonce.Uint64()
+	}
+
+	if nonce, err := p.ethClient.PendingNonceAt(ctx, p.config.EthAddress); err != nil {
+		addErr(err, "failed to get Ethereum account nonce")
+	} else {
+		status.EthAccountNoncePending = nonce
+	}
+
+	if balance, err := p.ethClient.BalanceAt(ctx, p.config.EthAddress, nil); err != nil {
+		addErr(err, "failed to get Ethereum balance")
+	} else {
+		status.EthBalance = balance.String()
+	}
+
+	if res, err := p.bankQuerier.AllBalances(ctx, &banktypes.QueryAllBalancesRequest{
+		Address: p.config.OrchestratorAddress.String(),
+	}); err != nil {
+		addErr(err, "failed to get Cosmos balances")
+	} else {
+		status.CosmosBalances = res.Balances.String()
+	}
+
+	return status
+}
+
+// publish signs the status with the orchestrator's Ethereum key and sends it to
+// the endpoint. The signature covers the exact request body.
+func (p *Publisher) publish(ctx context.Context, status Status) error {
+	body, err := json.Marshal(status)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal heartbeat")
+	}
+
+	sig, err := p.signFn(p.config.EthAddress, body)
+	if err != nil {
+		return errors.Wrap(err, "failed to sign heartbeat")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(payloadv1.HeaderSchema, payloadv1.SchemaHeartbeatStatus)
+	req.Header.Set(HeaderSignature, hexutil.Encode(sig))
+	req.Header.Set(HeaderSigner, p.config.EthAddress.Hex())
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("heartbeat endpoint returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
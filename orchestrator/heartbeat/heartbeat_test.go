@@ -0,0 +1,71 @@
+package heartbeat
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	payloadv1 "github.com/umee-network/peggo/orchestrator/payload/v1"
+)
+
+func TestPublish(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	ethAddr := crypto.PubkeyToAddress(key.PublicKey)
+
+	signFn := func(_ ethcmn.Address, data []byte) ([]byte, error) {
+		return crypto.Sign(accounts.TextHash(data), key)
+	}
+
+	var received Status
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		sig, err := hexutil.Decode(r.Header.Get(HeaderSignature))
+		assert.NoError(t, err)
+
+		pubKey, err := crypto.SigToPub(accounts.TextHash(body), sig)
+		if assert.NoError(t, err) {
+			assert.Equal(t, ethAddr, crypto.PubkeyToAddress(*pubKey))
+		}
+		assert.Equal(t, ethAddr.Hex(), r.Header.Get(HeaderSigner))
+		assert.Equal(t, payloadv1.SchemaHeartbeatStatus, r.Header.Get(payloadv1.HeaderSchema))
+
+		assert.NoError(t, json.Unmarshal(body, &received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	p, err := NewPublisher(
+		zerolog.Nop(),
+		Config{Endpoint: server.URL, Interval: time.Minute, EthAddress: ethAddr},
+		signFn,
+		nil, nil, nil, nil, nil,
+	)
+	require.NoError(t, err)
+
+	status := Status{EthAddress: ethAddr.Hex(), CosmosHeight: 42, EthHeight: 1000}
+	require.NoError(t, p.publish(context.Background(), status))
+	assert.Equal(t, int64(42), received.CosmosHeight)
+	assert.Equal(t, uint64(1000), received.EthHeight)
+}
+
+func TestValidateEndpoint(t *testing.T) {
+	assert.NoError(t, validateEndpoint("https://monitor.example.com/heartbeat"))
+	assert.NoError(t, validateEndpoint("http://localhost:8080/heartbeat"))
+	assert.Error(t, validateEndpoint("http://monitor.example.com/heartbeat"))
+	assert.Error(t, validateEndpoint("ftp://monitor.example.com"))
+}
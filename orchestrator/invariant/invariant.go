@@ -0,0 +1,198 @@
+package invariant
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/shopspring/decimal"
+
+	"github.com/umee-network/peggo/orchestrator/loops"
+	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
+)
+
+type (
+	// TokenBalancer returns the ERC20 balance of an account.
+	TokenBalancer interface {
+		BalanceOf(ctx context.Context, token, account ethcmn.Address) (*big.Int, error)
+	}
+
+	// Config defines how often the invariant is checked and how much drift is
+	// tolerated before raising an alert.
+	Config struct {
+		Interval       time.Duration
+		Tolerance      float64
+		GravityAddress ethcmn.Address
+	}
+
+	// Violation describes a token whose Gravity contract balance does not match
+	// its bridged supply on Cosmos.
+	Violation struct {
+		Denom          string
+		Token          ethcmn.Address
+		ContractAmount *big.Int
+		CosmosSupply   *big.Int
+		Deviation      decimal.Decimal
+	}
+
+	// Monitor periodically checks that every Ethereum originated token held by
+	// the Gravity contract is backing the same amount of vouchers on Cosmos.
+	Monitor struct {
+		logger      zerolog.Logger
+		config      Config
+		tolerance   decimal.Decimal
+		bankQuerier banktypes.QueryClient
+		balancer    TokenBalancer
+	}
+
+	erc20Balancer struct {
+		backend bind.ContractCaller
+	}
+)
+
+// NewTokenBalancer returns a TokenBalancer that reads balances through the
+// given contract backend.
+func NewTokenBalancer(backend bind.ContractCaller) TokenBalancer {
+	return &erc20Balancer{backend: backend}
+}
+
+func (b *erc20Balancer) BalanceOf(ctx context.Context, token, account ethcmn.Address) (*big.Int, error) {
+	erc20, err := wrappers.NewERC20Caller(token, b.backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get ERC20 wrapper")
+	}
+
+	return erc20.BalanceOf(&bind.CallOpts{Context: ctx}, account)
+}
+
+// NewMonitor returns an invariant monitor.
+func NewMonitor(
+	logger zerolog.Logger,
+	config Config,
+	bankQuerier banktypes.QueryClient,
+	balancer TokenBalancer,
+) (*Monitor, error) {
+	if config.Interval <= 0 {
+		return nil, fmt.Errorf("invalid invariant check interval: %s", config.Interval)
+	}
+
+	if config.Tolerance < 0 || config.Tolerance >= 1 {
+		return nil, fmt.Errorf("invalid invariant tolerance: %v", config.Tolerance)
+	}
+
+	return &Monitor{
+		logger:      logger.With().Str("module", "invariant").Logger(),
+		config:      config,
+		tolerance:   decimal.NewFromFloat(config.Tolerance),
+		bankQuerier: bankQuerier,
+		balancer:    balancer,
+	}, nil
+}
+
+// Start checks the invariant every interval until the context is done. A
+// violation is logged as an error; it never stops the orchestrator.
+func (m *Monitor) Start(ctx context.Context) error {
+	return loops.RunLoop(ctx, m.logger, m.config.Interval, func() error {
+		violations, err := m.Check(ctx)
+		if err != nil {
+			m.logger.Err(err).Msg("failed to check the Gravity balance invariant")
+			return nil
+		}
+
+		for _, v := range violations {
+			m.logger.Error().
+				Str("denom", v.Denom).
+				Str("token", v.Token.Hex()).
+				Str("contract_amount", v.ContractAmount.String()).
+				Str("cosmos_supply", v.CosmosSupply.String()).
+				Str("deviation", v.Deviation.String()).
+				Msg("Gravity contract balance doesn't match the bridged supply")
+		}
+
+		return nil
+	})
+}
+
+// Check compares the Gravity contract balance of every Ethereum originated token
+// against the supply of its voucher denom on Cosmos, and returns the tokens that
+// deviate more than the tolerance. Some drift is expected while deposits are
+// waiting to be attested and batches are waiting to be relayed. Cosmos
+// originated tokens are not checked, as the contract doesn't hold them.
+func (m *Monitor) Check(ctx context.Context) ([]Violation, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.config.Interval)
+	defer cancel()
+
+	var (
+		violations []Violation
+		nextKey    []byte
+	)
+
+	for {
+		res, err := m.bankQuerier.TotalSupply(ctx, &banktypes.QueryTotalSupplyRequest{
+			Pagination: &query.PageRequest{Key: nextKey},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the Cosmos total supply")
+		}
+
+		for _, coin := range res.Supply {
+			if !strings.HasPrefix(coin.Denom, gravitytypes.GravityDenomPrefix+gravitytypes.GravityDenomSeparator) {
+				continue
+			}
+
+			token, err := gravitytypes.GravityDenomToERC20(coin.Denom)
+			if err != nil {
+				m.logger.Debug().Err(err).Str("denom", coin.Denom).Msg("skipping invalid gravity denom")
+				continue
+			}
+
+			contractAmount, err := m.balancer.BalanceOf(ctx, token.GetAddress(), m.config.GravityAddress)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get the Gravity contract balance of %s", token.GetAddress())
+			}
+
+			supply := coin.Amount.BigInt()
+			deviation := relativeDeviation(contractAmount, supply)
+
+			if deviation.GreaterThan(m.tolerance) {
+				violations = append(violations, Violation{
+					Denom:          coin.Denom,
+					Token:          token.GetAddress(),
+					ContractAmount: contractAmount,
+					CosmosSupply:   supply,
+					Deviation:      deviation,
+				})
+			}
+		}
+
+		if res.Pagination == nil || len(res.Pagination.NextKey) == 0 {
+			return violations, nil
+		}
+
+		nextKey = res.Pagination.NextKey
+	}
+}
+
+// relativeDeviation returns |a - b| / max(a, b).
+func relativeDeviation(a, b *big.Int) decimal.Decimal {
+	max := a
+	if b.Cmp(a) > 0 {
+		max = b
+	}
+
+	if max.Sign() == 0 {
+		return decimal.Zero
+	}
+
+	diff := new(big.Int).Sub(a, b)
+	return decimal.NewFromBigInt(diff.Abs(diff), 0).Div(decimal.NewFromBigInt(max, 0))
+}
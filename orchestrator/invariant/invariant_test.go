@@ -0,0 +1,74 @@
+package invariant
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+type mockBankQuerier struct {
+	banktypes.QueryClient
+
+	supply sdk.Coins
+}
+
+func (q *mockBankQuerier) TotalSupply(
+	_ context.Context,
+	_ *banktypes.QueryTotalSupplyRequest,
+	_ ...grpc.CallOption,
+) (*banktypes.QueryTotalSupplyResponse, error) {
+	return &banktypes.QueryTotalSupplyResponse{Supply: q.supply}, nil
+}
+
+type mockBalancer map[ethcmn.Address]*big.Int
+
+func (b mockBalancer) BalanceOf(_ context.Context, token, _ ethcmn.Address) (*big.Int, error) {
+	return b[token], nil
+}
+
+func TestCheck(t *testing.T) {
+	okToken := ethcmn.HexToAddress("0x0000000000000000000000000000000000000001")
+	driftToken := ethcmn.HexToAddress("0x0000000000000000000000000000000000000002")
+	badToken := ethcmn.HexToAddress("0x0000000000000000000000000000000000000003")
+
+	bankQuerier := &mockBankQuerier{
+		supply: sdk.NewCoins(
+			sdk.NewInt64Coin("gravity"+okToken.Hex(), 1000),
+			sdk.NewInt64Coin("gravity"+driftToken.Hex(), 1000),
+			sdk.NewInt64Coin("gravity"+badToken.Hex(), 1000),
+			sdk.NewInt64Coin("uumee", 5000),
+		),
+	}
+
+	balancer := mockBalancer{
+		okToken:    big.NewInt(1000),
+		driftToken: big.NewInt(1005), // a deposit waiting to be attested
+		badToken:   big.NewInt(500),
+	}
+
+	m, err := NewMonitor(zerolog.Nop(), Config{Interval: time.Minute, Tolerance: 0.01}, bankQuerier, balancer)
+	require.NoError(t, err)
+
+	violations, err := m.Check(context.Background())
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+	assert.Equal(t, badToken, violations[0].Token)
+	assert.Equal(t, "0.5", violations[0].Deviation.String())
+}
+
+func TestNewMonitorValidation(t *testing.T) {
+	_, err := NewMonitor(zerolog.Nop(), Config{Interval: 0}, nil, nil)
+	assert.Error(t, err)
+
+	_, err = NewMonitor(zerolog.Nop(), Config{Interval: time.Minute, Tolerance: 1}, nil, nil)
+	assert.Error(t, err)
+}
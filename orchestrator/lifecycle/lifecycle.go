@@ -0,0 +1,245 @@
+package lifecycle
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type (
+	// Tracker keeps count of the goroutines and connections opened by a
+	// component, per owner (e.g. an oracle provider or an Ethereum
+	// subscription), so they can all be closed on Stop and leaks detected.
+	Tracker struct {
+		name string
+
+		mtx     sync.Mutex
+		wg      sync.WaitGroup
+		stopped bool
+		conns   map[*Conn]struct{}
+		counts  map[string]*Counts
+	}
+
+	// Counts holds the number of goroutines and connections still open by an
+	// owner.
+	Counts struct {
+		Goroutines  int
+		Connections int
+	}
+
+	// Conn is a tracked connection, closed either by its owner or when the
+	// tracker stops.
+	Conn struct {
+		tracker *Tracker
+		owner   string
+		close   func()
+		once    sync.Once
+	}
+
+	// Metrics exports the counts of the trackers using them.
+	Metrics struct {
+		mtx      sync.Mutex
+		trackers []*Tracker
+
+		goroutines  *prometheus.Desc
+		connections *prometheus.Desc
+	}
+)
+
+// NewMetrics returns the lifecycle metrics registered with registerer.
+func NewMetrics(registerer prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		goroutines: prometheus.NewDesc(
+			"peggo_lifecycle_goroutines",
+			"Number of running goroutines, per component and owner.",
+			[]string{"component", "owner"},
+			nil,
+		),
+		connections: prometheus.NewDesc(
+			"peggo_lifecycle_connections",
+			"Number of open connections and subscriptions, per component and owner.",
+			[]string{"component", "owner"},
+			nil,
+		),
+	}
+
+	if err := registerer.Register(m); err != nil {
+		return nil, errors.Wrap(err, "failed to register metric")
+	}
+
+	return m, nil
+}
+
+// Describe implements prometheus.Collector.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- m.goroutines
+	ch <- m.connections
+}
+
+// Collect implements prometheus.Collector.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for _, t := range m.trackers {
+		for owner, counts := range t.Counts() {
+			ch <- prometheus.MustNewConstMetric(
+				m.goroutines, prometheus.GaugeValue, float64(counts.Goroutines), t.name, owner,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				m.connections, prometheus.GaugeValue, float64(counts.Connections), t.name, owner,
+			)
+		}
+	}
+}
+
+// NewTracker returns the tracker of a component, whose counts are exported
+// with metrics if not nil.
+func NewTracker(name string, metrics *Metrics) *Tracker {
+	t := &Tracker{
+		name:   name,
+		conns:  map[*Conn]struct{}{},
+		counts: map[string]*Counts{},
+	}
+
+	if metrics != nil {
+		metrics.mtx.Lock()
+		metrics.trackers = append(metrics.trackers, t)
+		metrics.mtx.Unlock()
+	}
+
+	return t
+}
+
+// Go runs fn in a tracked goroutine. It returns false, without running fn, if
+// the tracker is stopped.
+func (t *Tracker) Go(owner string, fn func()) bool {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if t.stopped {
+		return false
+	}
+
+	t.ownerCounts(owner).Goroutines++
+	t.wg.Add(1)
+
+	go func() {
+		defer func() {
+			t.mtx.Lock()
+			t.ownerCounts(owner).Goroutines--
+			t.mtx.Unlock()
+
+			t.wg.Done()
+		}()
+
+		fn()
+	}()
+
+	return true
+}
+
+// Track tracks a connection, closed by closeFn. If the tracker is stopped, the
+// connection is closed right away.
+func (t *Tracker) Track(owner string, closeFn func()) *Conn {
+	c := &Conn{tracker: t, owner: owner, close: closeFn}
+
+	t.mtx.Lock()
+	if t.stopped {
+		t.mtx.Unlock()
+
+		c.once.Do(closeFn)
+		return c
+	}
+
+	t.conns[c] = struct{}{}
+	t.ownerCounts(owner).Connections++
+	t.mtx.Unlock()
+
+	return c
+}
+
+// Close closes the connection, once.
+func (c *Conn) Close() {
+	c.once.Do(func() {
+		t := c.tracker
+
+		t.mtx.Lock()
+		if _, ok := t.conns[c]; ok {
+			delete(t.conns, c)
+			t.ownerCounts(c.owner).Connections--
+		}
+		t.mtx.Unlock()
+
+		c.close()
+	})
+}
+
+// Stop closes all the tracked connections, then waits up to timeout for the
+// tracked goroutines to exit. It returns an error listing the owners of the
+// goroutines still running, if any. No goroutine or connection can be tracked
+// once stopped.
+func (t *Tracker) Stop(timeout time.Duration) error {
+	t.mtx.Lock()
+	t.stopped = true
+
+	conns := make([]*Conn, 0, len(t.conns))
+	for c := range t.conns {
+		conns = append(conns, c)
+	}
+	t.mtx.Unlock()
+
+	for _, c := range conns {
+		c.Close()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+
+	case <-time.After(timeout):
+		var leaked []string
+		for owner, counts := range t.Counts() {
+			if counts.Goroutines > 0 {
+				leaked = append(leaked, fmt.Sprintf("%s=%d", owner, counts.Goroutines))
+			}
+		}
+		sort.Strings(leaked)
+
+		return fmt.Errorf("%s goroutines still running after %s: %s", t.name, timeout, strings.Join(leaked, ", "))
+	}
+}
+
+// Counts returns the goroutines and connections still open, by owner.
+func (t *Tracker) Counts() map[string]Counts {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	counts := make(map[string]Counts, len(t.counts))
+	for owner, c := range t.counts {
+		counts[owner] = *c
+	}
+
+	return counts
+}
+
+func (t *Tracker) ownerCounts(owner string) *Counts {
+	c, ok := t.counts[owner]
+	if !ok {
+		c = &Counts{}
+		t.counts[owner] = c
+	}
+
+	return c
+}
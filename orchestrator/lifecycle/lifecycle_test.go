@@ -0,0 +1,62 @@
+package lifecycle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackerStop(t *testing.T) {
+	tracker := NewTracker("test", nil)
+
+	closed := make(chan struct{})
+	conn := tracker.Track("ws", func() { close(closed) })
+
+	// the goroutine exits once its connection is closed
+	require.True(t, tracker.Go("ws", func() { <-closed }))
+
+	assert.Equal(t, map[string]Counts{"ws": {Goroutines: 1, Connections: 1}}, tracker.Counts())
+
+	require.NoError(t, tracker.Stop(time.Second))
+	assert.Equal(t, map[string]Counts{"ws": {}}, tracker.Counts())
+
+	// closing it again is a no-op
+	conn.Close()
+
+	// nothing can be tracked once stopped
+	assert.False(t, tracker.Go("ws", func() {}))
+
+	var lateClosed bool
+	tracker.Track("ws", func() { lateClosed = true })
+	assert.True(t, lateClosed)
+}
+
+func TestTrackerLeak(t *testing.T) {
+	tracker := NewTracker("test", nil)
+
+	block := make(chan struct{})
+	defer close(block)
+
+	require.True(t, tracker.Go("reader", func() { <-block }))
+
+	err := tracker.Stop(10 * time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reader=1")
+}
+
+func TestMetrics(t *testing.T) {
+	m, err := NewMetrics(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	tracker := NewTracker("oracle", m)
+	conn := tracker.Track("binance", func() {})
+
+	assert.Equal(t, 2, testutil.CollectAndCount(m))
+
+	conn.Close()
+	assert.Equal(t, map[string]Counts{"binance": {}}, tracker.Counts())
+}
@@ -2,11 +2,12 @@ package loops
 
 import (
 	"context"
-	"runtime/debug"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
+
+	"github.com/umee-network/peggo/orchestrator/crash"
 )
 
 // ErrGracefulStop is a special error, if returned from within loop function,
@@ -16,9 +17,10 @@ var ErrGracefulStop = errors.New("stop")
 // Loop runs a function in the loop with a consistent interval. If execution
 // takes longer, the waiting time between iteration decreases. A single iteration
 // has a deadline and cannot run longer than interval itself. There is a
-// protection from panic which could crash adjacent loops.
+// protection from panic which could crash adjacent loops, and panics are
+// reported along with the crash fields of ctx (see crash.WithFields).
 func RunLoop(ctx context.Context, logger zerolog.Logger, interval time.Duration, fn func() error) (err error) {
-	defer panicRecover(logger, &err)
+	defer panicRecover(ctx, logger, &err)
 
 	delayTimer := time.NewTimer(0)
 	for {
@@ -47,18 +49,15 @@ func RunLoop(ctx context.Context, logger zerolog.Logger, interval time.Duration,
 	}
 }
 
-func panicRecover(logger zerolog.Logger, err *error) {
+func panicRecover(ctx context.Context, logger zerolog.Logger, err *error) {
 	if r := recover(); r != nil {
+		crash.Recovered(ctx, logger, r)
+
 		if e, ok := r.(error); ok {
 			*err = e
-
-			logger.Err(e).Msg("loop panicked with an error")
-			logger.Debug().Stack().Msg(string(debug.Stack()))
-
 			return
 		}
 
 		*err = errors.Errorf("loop panic: %v", r)
-		logger.Err(*err).Msg("")
 	}
 }
@@ -0,0 +1,52 @@
+package loops
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/umee-network/peggo/orchestrator/schedule"
+)
+
+// RunSchedule runs a function right away, then at every time of the schedule,
+// like RunLoop. A run taking longer than the time to the next one delays it to
+// the next time of the schedule after the run ended. The loop waits for ctx to
+// be done once the schedule has no next time.
+func RunSchedule(ctx context.Context, logger zerolog.Logger, s schedule.Schedule, fn func() error) (err error) {
+	defer panicRecover(ctx, logger, &err)
+
+	delayTimer := time.NewTimer(0)
+	defer delayTimer.Stop()
+
+	for {
+		select {
+		case <-delayTimer.C:
+			var start = time.Now()
+
+			if fnErr := fn(); fnErr != nil {
+				if fnErr == ErrGracefulStop {
+					return nil
+				}
+
+				return fnErr
+			}
+
+			next := s.Next(start)
+			if now := time.Now(); next.Before(now) {
+				next = s.Next(now)
+			}
+
+			if next.IsZero() {
+				logger.Warn().Msg("schedule has no next run; loop stops")
+				<-ctx.Done()
+				return nil
+			}
+
+			delayTimer.Reset(time.Until(next))
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
@@ -5,13 +5,17 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strconv"
 	"time"
 
 	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
 	"github.com/avast/retry-go"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/shopspring/decimal"
 
+	"github.com/umee-network/peggo/orchestrator/cache"
+	"github.com/umee-network/peggo/orchestrator/crash"
 	"github.com/umee-network/peggo/orchestrator/loops"
 	"github.com/umee-network/peggo/orchestrator/oracle"
 )
@@ -28,6 +32,10 @@ const (
 	// Run every approximately 3 Cosmos blocks; so we sign batches and valset updates ASAP but not run these requests
 	// too often that we make too many requests to Cosmos.
 	ethSignerLoopMultiplier = 3
+
+	// erc20DenomCacheSize bounds the number of ERC20 to denom mappings kept in
+	// memory; there are usually far fewer bridged tokens.
+	erc20DenomCacheSize = 1024
 )
 
 // estimatedGasCosts has a list of gas costs for batches from 1 to 100 txs.
@@ -57,6 +65,21 @@ var estimatedGasCosts = []int64{
 func (p *gravityOrchestrator) Start(ctx context.Context) error {
 	var pg loops.ParanoidGroup
 
+	// The confirms missed while the orchestrator was down are sent right away,
+	// before anything else competes for the Cosmos account.
+	if p.confirmSync {
+		p.syncConfirms(ctx)
+	}
+
+	// The batch requester and the relayer need prices, so both wait for the
+	// oracle's first tick and warm-up, instead of racing it. It returns when
+	// the context is done too.
+	oracleReady := make(chan struct{})
+	go func() {
+		p.waitForOracle(ctx)
+		close(oracleReady)
+	}()
+
 	if !p.ethMergePause {
 		pg.Go(func() error {
 			// scan all the events emitted by ethereum gravity contract
@@ -71,6 +94,7 @@ func (p *gravityOrchestrator) Start(ctx context.Context) error {
 			// looks at the BatchFees on Cosmos and uses the query endpoint BatchFees
 			// to iterate over each token to see if it is profitable, if it is
 			// it will send an request batch for that denom
+			<-oracleReady
 			return p.BatchRequesterLoop(ctx)
 		})
 	}
@@ -93,6 +117,7 @@ func (p *gravityOrchestrator) Start(ctx context.Context) error {
 			// ethereum if that batch of token is profitable, wasn't sent yet
 			// by another node (checking the nonce) and it is not currently
 			// in the eth node node mempool.
+			<-oracleReady
 			return p.RelayerMainLoop(ctx)
 		})
 	}
@@ -104,6 +129,7 @@ func (p *gravityOrchestrator) Start(ctx context.Context) error {
 // and ferried over to Cosmos where they will be used to issue tokens or process batches.
 func (p *gravityOrchestrator) EthOracleMainLoop(ctx context.Context) (err error) {
 	logger := p.logger.With().Str("loop", "EthOracleMainLoop").Logger()
+	ctx = crash.WithFields(ctx, "loop", "EthOracleMainLoop")
 	lastResync := time.Now()
 
 	var (
@@ -166,6 +192,7 @@ func (p *gravityOrchestrator) EthOracleMainLoop(ctx context.Context) (err error)
 	}
 
 	logger.Info().Uint64("last_checked_block", lastCheckedBlock).Msg("start scanning for events")
+	crash.SetField(ctx, "last_checked_block", strconv.FormatUint(lastCheckedBlock, 10))
 
 	return loops.RunLoop(ctx, p.logger, p.ethereumBlockTime*ethOracleLoopMultiplier, func() error {
 		// Relays events from Ethereum -> Cosmos
@@ -181,6 +208,7 @@ func (p *gravityOrchestrator) EthOracleMainLoop(ctx context.Context) (err error)
 		}
 
 		lastCheckedBlock = currentBlock
+		crash.SetField(ctx, "last_checked_block", strconv.FormatUint(lastCheckedBlock, 10))
 
 		// Auto re-sync to catch up the nonce. Reasons why event nonce fall behind.
 		//	1. It takes some time for events to be indexed on Ethereum. So if peggo queried events immediately as
@@ -200,6 +228,7 @@ func (p *gravityOrchestrator) EthOracleMainLoop(ctx context.Context) (err error)
 				return err
 			}
 
+			crash.SetField(ctx, "last_checked_block", strconv.FormatUint(lastCheckedBlock, 10))
 			lastResync = time.Now()
 			logger.Info().
 				Time("last_resync", lastResync).
@@ -216,6 +245,7 @@ func (p *gravityOrchestrator) EthOracleMainLoop(ctx context.Context) (err error)
 // valid and signed off on.
 func (p *gravityOrchestrator) EthSignerMainLoop(ctx context.Context) (err error) {
 	logger := p.logger.With().Str("loop", "EthSignerMainLoop").Logger()
+	ctx = crash.WithFields(ctx, "loop", "EthSignerMainLoop")
 
 	var gravityID string
 	if err := retry.Do(func() (err error) {
@@ -330,6 +360,7 @@ func (p *gravityOrchestrator) EthSignerMainLoop(ctx context.Context) (err error)
 // BatchRequesterLoop sends a batch request to Cosmos (Umee).
 func (p *gravityOrchestrator) BatchRequesterLoop(ctx context.Context) (err error) {
 	logger := p.logger.With().Str("loop", "BatchRequesterLoop").Logger()
+	ctx = crash.WithFields(ctx, "loop", "BatchRequesterLoop")
 
 	return loops.RunLoop(ctx, p.logger, p.batchRequesterLoopDuration, func() error {
 		// Each loop performs the following:
@@ -338,9 +369,8 @@ func (p *gravityOrchestrator) BatchRequesterLoop(ctx context.Context) (err error
 		// - broadcast Request batch
 		var pg loops.ParanoidGroup
 
-		usdEthPriceDec := decimal.NewFromFloat(0.0)
 		gasPrice := big.NewInt(0)
-		tokensPrices := make(map[string]decimal.Decimal)
+		tokensSymbols := make(map[string]string)
 		tokensDecimals := make(map[string]uint8)
 
 		pg.Go(func() error {
@@ -360,25 +390,18 @@ func (p *gravityOrchestrator) BatchRequesterLoop(ctx context.Context) (err error
 						return fmt.Errorf("failed to get Ethereum gas estimate: %w", err)
 					}
 
-					usdEthPrice, err := p.oracle.GetPrice(oracle.SymbolETH)
-					if err != nil {
-						return err
-					}
-
-					usdEthPriceDec, err = decimal.NewFromString(usdEthPrice.String())
-					if err != nil {
+					if _, err := p.oracle.GetPrice(p.relayer.GetGasAssetSymbol()); err != nil {
 						return err
 					}
 
 					for _, token := range unbatchedTokensWithFees {
-						if _, ok := tokensPrices[token.Token]; !ok {
+						if _, ok := tokensSymbols[token.Token]; !ok {
 							baseSymbol, err := p.symbolRetriever.GetTokenSymbol(ethcmn.HexToAddress(token.Token))
 							if err != nil {
 								return err
 							}
 
-							price, err := p.oracle.GetPrice(baseSymbol)
-							if err != nil {
+							if _, err := p.oracle.GetPrice(baseSymbol); err != nil {
 								// Our providers may not yet be subscribed to their websockets.
 								if err := p.oracle.SubscribeSymbols(baseSymbol); err != nil {
 									return err
@@ -386,12 +409,7 @@ func (p *gravityOrchestrator) BatchRequesterLoop(ctx context.Context) (err error
 
 								return err
 							}
-
-							priceDec, err := decimal.NewFromString(price.String())
-							if err != nil {
-								return err
-							}
-							tokensPrices[token.Token] = priceDec
+							tokensSymbols[token.Token] = baseSymbol
 
 							tokensDecimals[token.Token], err = p.gravityContract.GetERC20Decimals(
 								ctx,
@@ -413,6 +431,8 @@ func (p *gravityOrchestrator) BatchRequesterLoop(ctx context.Context) (err error
 				return nil
 			}
 
+			p.pruneBatchWaits(unbatchedTokensWithFees)
+
 			for _, unbatchedToken := range unbatchedTokensWithFees {
 				unbatchedToken := unbatchedToken
 				tokenAddr := ethcmn.HexToAddress(unbatchedToken.Token)
@@ -429,26 +449,62 @@ func (p *gravityOrchestrator) BatchRequesterLoop(ctx context.Context) (err error
 				if p.relayer.GetProfitMultiplier() > 0.0 {
 					// First we get the cost of the transaction in USD
 					totalETHcost := big.NewInt(0).Mul(gasPrice, big.NewInt(estimatedGasCosts[unbatchedToken.TxCount-1]))
-					// Ethereum decimals are 18 and that's a constant.
-					gasCostInUSDDec := decimal.NewFromBigInt(totalETHcost, -18).Mul(usdEthPriceDec)
-					// Decimals (uint8) can be safely casted into int32 because the max uint8 is 255 and the max int32 is 2147483647.
-					totalFeeInUSDDec := decimal.NewFromBigInt(
-						unbatchedToken.TotalFees.BigInt(),
-						-int32(tokensDecimals[unbatchedToken.Token]),
-					).Mul(tokensPrices[unbatchedToken.Token])
+					gasCostInUSDDec, err := p.usdValue(
+						sdk.NewIntFromBigInt(totalETHcost),
+						oracle.DecimalsETH,
+						p.relayer.GetGasAssetSymbol(),
+					)
+					if err != nil {
+						logger.Err(err).Msg("failed to get the gas cost in USD; will not request a batch")
+						return nil
+					}
+
+					totalFeeInUSDDec, err := p.usdValue(
+						unbatchedToken.TotalFees,
+						tokensDecimals[unbatchedToken.Token],
+						tokensSymbols[unbatchedToken.Token],
+					)
+					if err != nil {
+						logger.Err(err).
+							Str("token_contract", tokenAddr.String()).
+							Msg("failed to get the fees in USD; will not request a batch")
+						continue
+					}
 
 					// Simplified: totalFee > (gasCost * profitMultiplier).
 					profitMult := decimal.NewFromFloat(p.relayer.GetProfitMultiplier())
 					shouldRequestBatch = totalFeeInUSDDec.GreaterThanOrEqual(gasCostInUSDDec.Mul(profitMult))
 				}
 
+				preview := p.previewBatch(ctx, denom, unbatchedToken, tokensSymbols, tokensDecimals)
+
+				var (
+					wait   bool
+					waited time.Duration
+				)
 				if shouldRequestBatch {
-					logger.Info().Str("token_contract", tokenAddr.String()).Str("denom", denom).Msg("sending batch request")
+					wait, waited = p.waitForFullerBatch(unbatchedToken)
+				}
+
+				switch {
+				case shouldRequestBatch && p.isDust(preview):
+					logger.Info().EmbedObject(preview).Msg("batch would be a single dust transfer, skipping batch creation")
+				case shouldRequestBatch && wait:
+					logger.Debug().
+						EmbedObject(preview).
+						Uint64("target_size", p.batchTargetSize).
+						Dur("waited", waited).
+						Msg("waiting for more transfers, skipping batch creation")
+				case shouldRequestBatch:
+					logger.Info().EmbedObject(preview).Msg("sending batch request")
 
 					if err := p.gravityBroadcastClient.SendRequestBatch(ctx, denom); err != nil {
 						logger.Err(err).Msg("failed to send batch request")
+						continue
 					}
-				} else {
+
+					p.batchRequested(unbatchedToken.Token)
+				default:
 					logger.Debug().
 						Str("token_contract", tokenAddr.String()).
 						Str("denom", denom).
@@ -478,8 +534,15 @@ func (p *gravityOrchestrator) ERC20ToDenom(ctx context.Context, tokenAddr ethcmn
 	p.mtx.Lock()
 	defer p.mtx.Unlock()
 
+	if p.erc20DenomCache == nil {
+		p.erc20DenomCache = cache.New[string, string](
+			erc20DenomCacheSize,
+			cache.OptionMetrics(p.cacheMetrics, "erc20_denom"),
+		)
+	}
+
 	tokenAddrStr := tokenAddr.String()
-	denom, ok := p.erc20DenomCache[tokenAddrStr]
+	denom, ok := p.erc20DenomCache.Get(tokenAddrStr)
 	if ok {
 		return denom, nil
 	}
@@ -493,11 +556,7 @@ func (p *gravityOrchestrator) ERC20ToDenom(ctx context.Context, tokenAddr ethcmn
 		return "", errors.New("no denom found for token")
 	}
 
-	if p.erc20DenomCache == nil {
-		p.erc20DenomCache = map[string]string{}
-	}
-
-	p.erc20DenomCache[tokenAddrStr] = resp.Denom
+	p.erc20DenomCache.Add(tokenAddrStr, resp.Denom)
 	return resp.Denom, nil
 }
 
@@ -0,0 +1,162 @@
+// Package metatx submits transactions through a relay (meta-transaction)
+// service paying their gas, e.g. an OpenZeppelin Defender relayer or a Gelato
+// relay behind a thin adapter, for operators who outsource execution.
+package metatx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/keystore"
+)
+
+const (
+	// HeaderSignature holds the hex encoded personal_sign signature of the
+	// submitted transaction request.
+	HeaderSignature = "X-Peggo-Signature"
+	// HeaderSigner holds the Ethereum address the relay service checks the
+	// signature against.
+	HeaderSigner = "X-Peggo-Signer"
+
+	maxRespTime = 30 * time.Second
+	// maxRespSize is the maximum size of a response body that is read.
+	maxRespSize = 1 << 20
+)
+
+type (
+	// Request is the transaction submitted to the relay service. The service
+	// sends it to the target from its own account and pays its gas.
+	Request struct {
+		ChainID  uint64    `json:"chain_id"`
+		Target   string    `json:"target"`
+		Data     string    `json:"data"`
+		GasLimit uint64    `json:"gas_limit"`
+		Signer   string    `json:"signer"`
+		Time     time.Time `json:"time"`
+	}
+
+	// Response identifies the submitted transaction: its hash, if the service
+	// already broadcast it, or the ID of the task the service queued.
+	Response struct {
+		TxHash string `json:"tx_hash,omitempty"`
+		TaskID string `json:"task_id,omitempty"`
+	}
+
+	// Config defines the relay service and the chain transactions are sent to.
+	Config struct {
+		Endpoint string
+		// APIKey is sent as a bearer token, if set.
+		APIKey     string
+		ChainID    uint64
+		EthAddress ethcmn.Address
+	}
+
+	// Client posts transactions signed by the orchestrator's Ethereum key to a
+	// relay service.
+	Client struct {
+		client *http.Client
+		config Config
+		signFn keystore.PersonalSignFn
+	}
+)
+
+// NewClient returns a relay service client. The endpoint must use HTTPS, unless
+// it points to localhost.
+func NewClient(config Config, signFn keystore.PersonalSignFn) (*Client, error) {
+	u, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid meta-transaction endpoint")
+	}
+
+	local := u.Hostname() == "localhost" || u.Hostname() == "127.0.0.1"
+	if !strings.EqualFold(u.Scheme, "https") && !(strings.EqualFold(u.Scheme, "http") && local) {
+		return nil, fmt.Errorf("meta-transaction endpoint must use https: %s", config.Endpoint)
+	}
+
+	if config.ChainID == 0 {
+		return nil, errors.New("invalid meta-transaction chain ID: 0")
+	}
+
+	return &Client{
+		client: &http.Client{Timeout: maxRespTime},
+		config: config,
+		signFn: signFn,
+	}, nil
+}
+
+// Submit signs the transaction data with the orchestrator's Ethereum key and
+// posts it to the relay service. It returns the transaction hash, or the task
+// ID if the service didn't broadcast the transaction yet. The signature covers
+// the exact request body, so the service can check who submitted it.
+func (c *Client) Submit(ctx context.Context, target ethcmn.Address, txData []byte, gasLimit uint64) (string, error) {
+	body, err := json.Marshal(Request{
+		ChainID:  c.config.ChainID,
+		Target:   target.Hex(),
+		Data:     hexutil.Encode(txData),
+		GasLimit: gasLimit,
+		Signer:   c.config.EthAddress.Hex(),
+		Time:     time.Now().UTC(),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal meta-transaction")
+	}
+
+	sig, err := c.signFn(c.config.EthAddress, body)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to sign meta-transaction")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderSignature, hexutil.Encode(sig))
+	req.Header.Set(HeaderSigner, c.config.EthAddress.Hex())
+	if c.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxRespSize))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read meta-transaction response")
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("meta-transaction endpoint returned status %d: %s", resp.StatusCode, respBody)
+	}
+
+	var res Response
+	if err := json.Unmarshal(respBody, &res); err != nil {
+		return "", errors.Wrap(err, "invalid meta-transaction response")
+	}
+
+	switch {
+	case res.TxHash != "":
+		return res.TxHash, nil
+
+	case res.TaskID != "":
+		return res.TaskID, nil
+
+	default:
+		return "", errors.New("meta-transaction response holds neither a tx hash nor a task ID")
+	}
+}
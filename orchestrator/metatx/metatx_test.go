@@ -0,0 +1,71 @@
+package metatx
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmit(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	ethAddr := crypto.PubkeyToAddress(key.PublicKey)
+
+	signFn := func(_ ethcmn.Address, data []byte) ([]byte, error) {
+		return crypto.Sign(accounts.TextHash(data), key)
+	}
+
+	target := ethcmn.HexToAddress("0x0000000000000000000000000000000000000001")
+
+	var received Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		sig, err := hexutil.Decode(r.Header.Get(HeaderSignature))
+		assert.NoError(t, err)
+
+		pubKey, err := crypto.SigToPub(accounts.TextHash(body), sig)
+		if assert.NoError(t, err) {
+			assert.Equal(t, ethAddr, crypto.PubkeyToAddress(*pubKey))
+		}
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		assert.NoError(t, json.Unmarshal(body, &received))
+		_, _ = w.Write([]byte(`{"task_id":"task-1"}`))
+	}))
+	defer server.Close()
+
+	c, err := NewClient(Config{Endpoint: server.URL, APIKey: "secret", ChainID: 5, EthAddress: ethAddr}, signFn)
+	require.NoError(t, err)
+
+	ref, err := c.Submit(context.Background(), target, []byte{0xde, 0xad}, 300000)
+	require.NoError(t, err)
+	assert.Equal(t, "task-1", ref)
+	assert.Equal(t, uint64(5), received.ChainID)
+	assert.Equal(t, target.Hex(), received.Target)
+	assert.Equal(t, "0xdead", received.Data)
+	assert.Equal(t, uint64(300000), received.GasLimit)
+	assert.Equal(t, ethAddr.Hex(), received.Signer)
+}
+
+func TestNewClient(t *testing.T) {
+	_, err := NewClient(Config{Endpoint: "http://relay.example.com", ChainID: 1}, nil)
+	assert.Error(t, err)
+
+	_, err = NewClient(Config{Endpoint: "https://relay.example.com", ChainID: 0}, nil)
+	assert.Error(t, err)
+
+	_, err = NewClient(Config{Endpoint: "https://relay.example.com", ChainID: 1}, nil)
+	assert.NoError(t, err)
+}
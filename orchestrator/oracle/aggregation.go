@@ -0,0 +1,77 @@
+package oracle
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+)
+
+// The strategies aggregating the provider prices of a base into a single price.
+const (
+	// AggregationTVWAP uses the time and volume weighted average of the candles,
+	// falling back to AggregationVWAP when no candles are available.
+	AggregationTVWAP = "tvwap"
+	// AggregationVWAP uses the volume weighted average of the ticker prices.
+	AggregationVWAP = "vwap"
+	// AggregationMedian uses the median of the ticker prices across providers,
+	// so a single large print on a thinly traded pair can't skew it.
+	AggregationMedian = "median"
+)
+
+// aggregations are the supported aggregation strategies.
+var aggregations = []string{AggregationTVWAP, AggregationVWAP, AggregationMedian}
+
+// ValidateAggregation returns an error if the aggregation strategy is unknown.
+func ValidateAggregation(aggregation string) error {
+	for _, a := range aggregations {
+		if aggregation == a {
+			return nil
+		}
+	}
+
+	return fmt.Errorf(
+		"invalid price aggregation: %s; expected one of: %s",
+		aggregation, strings.Join(aggregations, ", "),
+	)
+}
+
+// OptionAggregation sets the strategy aggregating the provider prices of a base
+// into a single price (see ValidateAggregation). It defaults to
+// AggregationTVWAP.
+func OptionAggregation(aggregation string) Option {
+	return func(o *Oracle) { o.aggregation = aggregation }
+}
+
+// ComputeMedian computes the median of the ticker prices of each base across
+// providers. With an even number of providers, it's the mean of the two middle
+// prices.
+func ComputeMedian(prices pfprovider.AggregatedProviderPrices) map[string]sdk.Dec {
+	byBase := map[string][]sdk.Dec{}
+	for _, tickers := range prices {
+		for base, ticker := range tickers {
+			if ticker.Price.IsNil() {
+				continue
+			}
+
+			byBase[base] = append(byBase[base], ticker.Price)
+		}
+	}
+
+	medians := make(map[string]sdk.Dec, len(byBase))
+	for base, basePrices := range byBase {
+		sort.Slice(basePrices, func(i, j int) bool { return basePrices[i].LT(basePrices[j]) })
+
+		mid := len(basePrices) / 2
+		if len(basePrices)%2 == 1 {
+			medians[base] = basePrices[mid]
+		} else {
+			medians[base] = basePrices[mid-1].Add(basePrices[mid]).QuoInt64(2)
+		}
+	}
+
+	return medians
+}
@@ -0,0 +1,38 @@
+package oracle
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+func TestComputeMedian(t *testing.T) {
+	ticker := func(price string) pftypes.TickerPrice {
+		return pftypes.TickerPrice{Price: sdk.MustNewDecFromStr(price), Volume: sdk.OneDec()}
+	}
+
+	prices := pfprovider.AggregatedProviderPrices{
+		pfprovider.ProviderBinance: {"ETH": ticker("1200"), "UMEE": ticker("0.01")},
+		pfprovider.ProviderKraken:  {"ETH": ticker("1210"), "UMEE": ticker("0.03")},
+		pfprovider.ProviderHuobi:   {"ETH": ticker("9000")},
+	}
+
+	medians := ComputeMedian(prices)
+	assert.Equal(t, sdk.MustNewDecFromStr("1210"), medians["ETH"])
+	assert.Equal(t, sdk.MustNewDecFromStr("0.02"), medians["UMEE"])
+
+	assert.Empty(t, ComputeMedian(pfprovider.AggregatedProviderPrices{}))
+}
+
+func TestValidateAggregation(t *testing.T) {
+	for _, aggregation := range []string{AggregationTVWAP, AggregationVWAP, AggregationMedian} {
+		assert.NoError(t, ValidateAggregation(aggregation))
+	}
+
+	assert.Error(t, ValidateAggregation("twap"))
+	assert.Error(t, ValidateAggregation(""))
+}
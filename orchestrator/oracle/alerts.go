@@ -0,0 +1,122 @@
+package oracle
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Kinds of price alerts.
+const (
+	// PriceAlertMove is raised when a price moved more than the move threshold
+	// since the previous tick.
+	PriceAlertMove = "move"
+	// PriceAlertMissing is raised when a price disappeared, i.e. none of its
+	// providers reported it and it's no longer held.
+	PriceAlertMissing = "missing"
+)
+
+type (
+	// PriceAlert is an unusual change of a price between two ticks.
+	PriceAlert struct {
+		Kind     string
+		Symbol   string
+		Previous sdk.Dec
+		// Price and Change, the relative move from Previous, are nil for
+		// PriceAlertMissing.
+		Price  sdk.Dec
+		Change sdk.Dec
+		Time   time.Time
+	}
+
+	// PriceAlertHook is called with every price alert. It's called from the
+	// oracle loop, so it must not block.
+	PriceAlertHook func(PriceAlert)
+)
+
+// OptionPriceAlertHook registers a hook called when a price moves more than the
+// move threshold between two ticks (see OptionPriceMoveThreshold) or
+// disappears. It can be set multiple times.
+func OptionPriceAlertHook(hook PriceAlertHook) Option {
+	return func(o *Oracle) { o.priceAlertHooks = append(o.priceAlertHooks, hook) }
+}
+
+// OptionPriceMoveThreshold sets the relative move of a price between two ticks
+// (e.g. 0.1 for 10%) above which the price alert hooks are called. Nil or zero
+// disables the move alerts; the missing price alerts are still raised.
+func OptionPriceMoveThreshold(threshold sdk.Dec) Option {
+	return func(o *Oracle) { o.priceMoveThreshold = threshold }
+}
+
+// alertPrices returns a copy of the current prices to compare the next ones to,
+// nil if no price alert hook is registered.
+func (o *Oracle) alertPrices() map[string]sdk.Dec {
+	if len(o.priceAlertHooks) == 0 {
+		return nil
+	}
+
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+
+	prices := make(map[string]sdk.Dec, len(o.prices))
+	for base, price := range o.prices {
+		prices[base] = price
+	}
+
+	return prices
+}
+
+// priceAlerts returns the alerts raised by the current prices compared to the
+// previous ones. The oracle must be locked.
+func (o *Oracle) priceAlerts(previous map[string]sdk.Dec, now time.Time) []PriceAlert {
+	var alerts []PriceAlert
+
+	for base, previousPrice := range previous {
+		price, ok := o.prices[base]
+		if !ok {
+			alerts = append(alerts, PriceAlert{
+				Kind:     PriceAlertMissing,
+				Symbol:   base,
+				Previous: previousPrice,
+				Time:     now,
+			})
+			continue
+		}
+
+		if o.priceMoveThreshold.IsNil() || !o.priceMoveThreshold.IsPositive() || !previousPrice.IsPositive() {
+			continue
+		}
+
+		change := price.Sub(previousPrice).Quo(previousPrice)
+		if change.Abs().GT(o.priceMoveThreshold) {
+			alerts = append(alerts, PriceAlert{
+				Kind:     PriceAlertMove,
+				Symbol:   base,
+				Previous: previousPrice,
+				Price:    price,
+				Change:   change,
+				Time:     now,
+			})
+		}
+	}
+
+	return alerts
+}
+
+// firePriceAlerts logs the alerts and calls the hooks with each of them.
+func (o *Oracle) firePriceAlerts(alerts []PriceAlert) {
+	for _, alert := range alerts {
+		event := o.logger.Warn().
+			Str("kind", alert.Kind).
+			Str("base", alert.Symbol).
+			Str("previous", alert.Previous.String())
+		if alert.Kind == PriceAlertMove {
+			event = event.Str("price", alert.Price.String()).Str("change", alert.Change.String())
+		}
+		event.Msg("price alert")
+
+		for _, hook := range o.priceAlertHooks {
+			hook(alert)
+		}
+	}
+}
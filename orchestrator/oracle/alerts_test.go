@@ -0,0 +1,77 @@
+package oracle
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceAlerts(t *testing.T) {
+	var alerts []PriceAlert
+
+	now := time.Now()
+	o := &Oracle{
+		logger: zerolog.Nop(),
+		prices: map[string]sdk.Dec{
+			SymbolETH: sdk.NewDec(1200),
+			"ATOM":    sdk.MustNewDecFromStr("10.5"),
+		},
+	}
+	OptionPriceMoveThreshold(sdk.MustNewDecFromStr("0.1"))(o)
+	OptionPriceAlertHook(func(alert PriceAlert) { alerts = append(alerts, alert) })(o)
+
+	// ETH moved by 20%, ATOM by 5% and UMEE isn't reported anymore
+	previous := map[string]sdk.Dec{
+		SymbolETH: sdk.NewDec(1500),
+		"ATOM":    sdk.NewDec(10),
+		"UMEE":    sdk.MustNewDecFromStr("0.005"),
+	}
+
+	o.firePriceAlerts(o.priceAlerts(previous, now))
+
+	require.Len(t, alerts, 2)
+	assert.ElementsMatch(t, []PriceAlert{
+		{
+			Kind:     PriceAlertMove,
+			Symbol:   SymbolETH,
+			Previous: sdk.NewDec(1500),
+			Price:    sdk.NewDec(1200),
+			Change:   sdk.MustNewDecFromStr("-0.2"),
+			Time:     now,
+		},
+		{
+			Kind:     PriceAlertMissing,
+			Symbol:   "UMEE",
+			Previous: sdk.MustNewDecFromStr("0.005"),
+			Time:     now,
+		},
+	}, alerts)
+}
+
+func TestPriceAlertsComputePrices(t *testing.T) {
+	var alerts []PriceAlert
+
+	o := &Oracle{
+		logger:         zerolog.Nop(),
+		computeWorkers: 1,
+		prices:         map[string]sdk.Dec{SymbolETH: sdk.NewDec(1500)},
+		priceTimes:     map[string]time.Time{SymbolETH: time.Now()},
+	}
+	OptionPriceAlertHook(func(alert PriceAlert) { alerts = append(alerts, alert) })(o)
+
+	// no provider reports ETH anymore and it isn't held
+	o.computePrices(nil, nil, nil, nil)
+
+	require.Len(t, alerts, 1)
+	assert.Equal(t, PriceAlertMissing, alerts[0].Kind)
+	assert.Equal(t, SymbolETH, alerts[0].Symbol)
+	assert.True(t, alerts[0].Price.IsNil())
+
+	// no alert once the price is gone
+	o.computePrices(nil, nil, nil, nil)
+	assert.Len(t, alerts, 1)
+}
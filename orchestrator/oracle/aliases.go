@@ -0,0 +1,74 @@
+package oracle
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OptionSymbolAliases prices each alias symbol (e.g. WETH) as its canonical
+// symbol (e.g. ETH), so bridged or wrapped variants don't need provider pairs
+// of their own. Symbols are case-insensitive.
+func OptionSymbolAliases(aliases map[string]string) Option {
+	return func(o *Oracle) { o.aliases = upperAliases(aliases) }
+}
+
+// SetSymbolAliases replaces the symbol aliases of a running oracle, e.g. with
+// the ones of a remote config. Aliases newly subscribed are priced as their
+// canonical symbol from then on.
+func (o *Oracle) SetSymbolAliases(aliases map[string]string) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	o.aliases = upperAliases(aliases)
+}
+
+func upperAliases(aliases map[string]string) map[string]string {
+	upper := make(map[string]string, len(aliases))
+	for alias, canonical := range aliases {
+		upper[strings.ToUpper(alias)] = strings.ToUpper(canonical)
+	}
+
+	return upper
+}
+
+// ParseSymbolAliases parses aliases in the ALIAS=CANONICAL format (e.g.
+// WETH=ETH). An alias can't be the canonical symbol of another one.
+func ParseSymbolAliases(values []string) (map[string]string, error) {
+	aliases := make(map[string]string, len(values))
+
+	for _, v := range values {
+		alias, canonical, ok := strings.Cut(v, "=")
+		alias, canonical = strings.ToUpper(strings.TrimSpace(alias)), strings.ToUpper(strings.TrimSpace(canonical))
+
+		if !ok || alias == "" || canonical == "" {
+			return nil, fmt.Errorf("invalid symbol alias %q; expected ALIAS=CANONICAL (e.g. WETH=ETH)", v)
+		}
+
+		if alias == canonical {
+			return nil, fmt.Errorf("symbol %s can't be an alias of itself", alias)
+		}
+
+		if prev, ok := aliases[alias]; ok && prev != canonical {
+			return nil, fmt.Errorf("symbol %s is an alias of both %s and %s", alias, prev, canonical)
+		}
+
+		aliases[alias] = canonical
+	}
+
+	for alias, canonical := range aliases {
+		if _, ok := aliases[canonical]; ok {
+			return nil, fmt.Errorf("symbol %s is an alias of %s, which is an alias itself", alias, canonical)
+		}
+	}
+
+	return aliases, nil
+}
+
+// canonicalSymbol returns the symbol the given one is priced as.
+func (o *Oracle) canonicalSymbol(symbol string) string {
+	if canonical, ok := o.aliases[strings.ToUpper(symbol)]; ok {
+		return canonical
+	}
+
+	return symbol
+}
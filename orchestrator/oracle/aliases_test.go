@@ -0,0 +1,46 @@
+package oracle
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSymbolAliases(t *testing.T) {
+	aliases, err := ParseSymbolAliases([]string{"weth=ETH", " axlUSDC = USDC "})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"WETH": "ETH", "AXLUSDC": "USDC"}, aliases)
+
+	for _, invalid := range [][]string{
+		{"WETH"},
+		{"WETH="},
+		{"ETH=ETH"},
+		{"WETH=ETH", "WETH=BTC"},
+		{"WETH=ETH", "STETH=WETH"},
+	} {
+		_, err := ParseSymbolAliases(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestSymbolAliasesPrices(t *testing.T) {
+	o := &Oracle{
+		prices: map[string]sdk.Dec{
+			SymbolETH: sdk.NewDec(1500),
+		},
+	}
+	OptionSymbolAliases(map[string]string{"weth": "eth"})(o)
+
+	price, err := o.GetPrice("WETH")
+	require.NoError(t, err)
+	assert.Equal(t, sdk.NewDec(1500), price)
+
+	prices, err := o.GetPrices("WETH", SymbolETH)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]sdk.Dec{"WETH": sdk.NewDec(1500), SymbolETH: sdk.NewDec(1500)}, prices)
+
+	_, err = o.GetPrice("USDC")
+	assert.Error(t, err)
+}
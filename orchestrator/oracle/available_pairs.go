@@ -0,0 +1,194 @@
+package oracle
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+)
+
+const (
+	// availablePairsRetryMin is the wait before retrying to get the available
+	// pairs of a provider that returned none; it doubles on every failure.
+	availablePairsRetryMin = 10 * time.Second
+	// availablePairsRetryMax is the maximum wait between two retries.
+	availablePairsRetryMax = 10 * time.Minute
+)
+
+// ReloadAvailablePairs requests a reload of the available pairs of the
+// providers, followed by a reconciliation of their subscriptions, e.g. after a
+// provider listed new pairs. It doesn't wait for the reload, and requests made
+// while one is pending are coalesced.
+func (o *Oracle) ReloadAvailablePairs() {
+	select {
+	case o.pairsReload <- struct{}{}:
+	default:
+	}
+}
+
+// reloadPairs reloads the available pairs of the providers on the pairs reload
+// schedule and on demand, until the oracle stops. It runs apart from the oracle
+// loop, so neither holds back the other.
+func (o *Oracle) reloadPairs(ctx context.Context) {
+	timer := newScheduleTimer(o.pairsReloadSchedule)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-o.closer.Done():
+			return
+
+		case <-timer.C():
+			o.loadAvailablePairs()
+			o.reconcileSubscriptions()
+			timer.Reset()
+
+		case <-o.pairsReload:
+			o.loadAvailablePairs()
+			o.reconcileSubscriptions()
+		}
+	}
+}
+
+// loadProviderPairs loads the available pairs of a provider not added yet, so
+// not shared with the other goroutines.
+func (o *Oracle) loadProviderPairs(providerName pfprovider.Name, provider *Provider) {
+	availablePairs, err := o.tryAvailablePairs(providerName, provider.Provider)
+	o.setProviderPairs(providerName, provider, availablePairs, err)
+}
+
+// tryAvailablePairs returns the available pairs of a provider without waiting
+// for its rate limit; a provider over it is retried like a failing one.
+func (o *Oracle) tryAvailablePairs(
+	providerName pfprovider.Name,
+	client pfprovider.Provider,
+) (map[string]struct{}, error) {
+	if !o.providerLimiter(providerName).Allow() {
+		return nil, errRateLimited
+	}
+
+	return client.GetAvailablePairs()
+}
+
+// providerAvailablePairs returns the available pairs of a provider, waiting
+// for its rate limit first.
+func (o *Oracle) providerAvailablePairs(
+	providerName pfprovider.Name,
+	client pfprovider.Provider,
+) (map[string]struct{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), providerLimitWait)
+	defer cancel()
+
+	if err := o.providerLimiter(providerName).Wait(ctx); err != nil {
+		return nil, errRateLimited
+	}
+
+	return client.GetAvailablePairs()
+}
+
+// setProviderPairs sets the available pairs a provider returned. Previously
+// loaded pairs are kept if the provider fails to return any; if it never
+// returned any, a retry is scheduled with an exponential backoff.
+func (o *Oracle) setProviderPairs(
+	providerName pfprovider.Name,
+	provider *Provider,
+	availablePairs map[string]struct{},
+	err error,
+) {
+	if err == nil && len(availablePairs) > 0 {
+		if provider.pairsFailures > 0 {
+			o.logger.Info().
+				Str("provider_name", string(providerName)).
+				Int("failures", provider.pairsFailures).
+				Msg("got available pairs for provider")
+		}
+
+		provider.availablePairs = availablePairs
+		provider.pairsFailures = 0
+		o.setPairsUnavailable(providerName, false)
+		return
+	}
+
+	if err == nil {
+		err = errors.New("no available pairs")
+	}
+
+	if len(provider.availablePairs) > 0 {
+		o.logger.Debug().Err(err).Str("provider_name", string(providerName)).
+			Msg("Error reloading available pairs for provider; keeping the previous ones")
+		return
+	}
+
+	provider.pairsFailures++
+	retryIn := availablePairsBackoff(provider.pairsFailures)
+	provider.pairsRetryAt = time.Now().Add(retryIn)
+	o.setPairsUnavailable(providerName, true)
+
+	o.logger.Warn().Err(err).
+		Str("provider_name", string(providerName)).
+		Int("failures", provider.pairsFailures).
+		Dur("retry_in", retryIn).
+		Msg("failed to get available pairs for provider; it contributes no price until it succeeds")
+}
+
+// retryAvailablePairs retries to get the available pairs of the providers that
+// have none once their backoff expired. Like loadAvailablePairs, the providers
+// are queried without holding the lock.
+func (o *Oracle) retryAvailablePairs() {
+	now := time.Now()
+
+	o.mtx.RLock()
+	providers := make(map[pfprovider.Name]*Provider)
+	clients := make(map[pfprovider.Name]pfprovider.Provider)
+	for providerName, provider := range o.providers {
+		if len(provider.availablePairs) > 0 || now.Before(provider.pairsRetryAt) {
+			continue
+		}
+
+		providers[providerName] = provider
+		clients[providerName] = provider.Provider
+	}
+	o.mtx.RUnlock()
+
+	for providerName, client := range clients {
+		availablePairs, err := o.tryAvailablePairs(providerName, client)
+
+		o.mtx.Lock()
+		if provider := providers[providerName]; o.providers[providerName] == provider {
+			o.setProviderPairs(providerName, provider, availablePairs, err)
+		}
+		o.mtx.Unlock()
+	}
+}
+
+func (o *Oracle) setPairsUnavailable(providerName pfprovider.Name, unavailable bool) {
+	if o.providerPairsUnavailable == nil {
+		return
+	}
+
+	value := 0.0
+	if unavailable {
+		value = 1
+	}
+
+	o.providerPairsUnavailable.WithLabelValues(string(providerName)).Set(value)
+}
+
+// availablePairsBackoff returns the wait before the next retry after the given
+// number of consecutive failures.
+func availablePairsBackoff(failures int) time.Duration {
+	backoff := availablePairsRetryMin
+	for i := 1; i < failures && backoff < availablePairsRetryMax; i++ {
+		backoff *= 2
+	}
+
+	if backoff > availablePairsRetryMax {
+		return availablePairsRetryMax
+	}
+
+	return backoff
+}
@@ -0,0 +1,140 @@
+package oracle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+	pfsync "github.com/umee-network/umee/price-feeder/v2/pkg/sync"
+
+	"github.com/umee-network/peggo/orchestrator/schedule"
+)
+
+func TestRetryAvailablePairs(t *testing.T) {
+	provider := &fakeProvider{}
+	o := &Oracle{
+		logger: zerolog.Nop(),
+		providers: map[pfprovider.Name]*Provider{
+			pfprovider.ProviderBinance: {Provider: provider},
+		},
+		registerer: prometheus.NewRegistry(),
+	}
+	require.NoError(t, o.registerMetrics())
+
+	unavailable := func() float64 {
+		return testutil.ToFloat64(o.providerPairsUnavailable.WithLabelValues(string(pfprovider.ProviderBinance)))
+	}
+
+	o.loadAvailablePairs()
+	binance := o.providers[pfprovider.ProviderBinance]
+	assert.Equal(t, 1, binance.pairsFailures)
+	assert.Equal(t, float64(1), unavailable())
+
+	// not retried before the backoff expires
+	o.retryAvailablePairs()
+	assert.Equal(t, 1, binance.pairsFailures)
+
+	binance.pairsRetryAt = time.Now()
+	o.retryAvailablePairs()
+	assert.Equal(t, 2, binance.pairsFailures)
+	assert.WithinDuration(t, time.Now().Add(2*availablePairsRetryMin), binance.pairsRetryAt, time.Second)
+
+	// the provider recovers
+	provider.available = map[string]struct{}{"ETHUSDT": {}}
+	binance.pairsRetryAt = time.Now()
+	o.retryAvailablePairs()
+	assert.Zero(t, binance.pairsFailures)
+	assert.Len(t, binance.availablePairs, 1)
+	assert.Equal(t, float64(0), unavailable())
+}
+
+// lockCheckingProvider records whether the oracle lock was free while its
+// available pairs were queried.
+type lockCheckingProvider struct {
+	fakeProvider
+	o        *Oracle
+	unlocked bool
+}
+
+func (p *lockCheckingProvider) GetAvailablePairs() (map[string]struct{}, error) {
+	if p.o.mtx.TryLock() {
+		p.unlocked = true
+		p.o.mtx.Unlock()
+	}
+
+	return map[string]struct{}{"ETHUSDT": {}}, nil
+}
+
+func TestRetryAvailablePairsUnlocked(t *testing.T) {
+	provider := &lockCheckingProvider{}
+	o := &Oracle{
+		logger: zerolog.Nop(),
+		providers: map[pfprovider.Name]*Provider{
+			pfprovider.ProviderBinance: {Provider: provider},
+		},
+	}
+	provider.o = o
+
+	o.retryAvailablePairs()
+	assert.True(t, provider.unlocked)
+	assert.Len(t, o.providers[pfprovider.ProviderBinance].availablePairs, 1)
+}
+
+func TestReloadAvailablePairs(t *testing.T) {
+	provider := &fakeProvider{available: map[string]struct{}{"USDTUSD": {}}}
+	binance := &Provider{
+		Provider:        provider,
+		availablePairs:  map[string]struct{}{"USDCUSD": {}},
+		subscribedPairs: map[string]pftypes.CurrencyPair{},
+	}
+
+	o := &Oracle{
+		logger:                  zerolog.Nop(),
+		closer:                  pfsync.NewCloser(),
+		providers:               map[pfprovider.Name]*Provider{pfprovider.ProviderBinance: binance},
+		subscribedBaseSymbols:   map[string]struct{}{},
+		providerSubscribedPairs: map[pfprovider.Name][]pftypes.CurrencyPair{},
+		pairsReloadSchedule:     schedule.Every(time.Hour),
+		pairsReload:             make(chan struct{}, 1),
+	}
+
+	// requests are coalesced while one is pending
+	o.ReloadAvailablePairs()
+	o.ReloadAvailablePairs()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		o.reloadPairs(ctx)
+		close(done)
+	}()
+
+	usdtUSD := pftypes.CurrencyPair{Base: "USDT", Quote: "USD"}
+	require.Eventually(t, func() bool {
+		o.mtx.RLock()
+		defer o.mtx.RUnlock()
+
+		return len(o.providerSubscribedPairs[pfprovider.ProviderBinance]) > 0
+	}, time.Second, 10*time.Millisecond)
+
+	o.mtx.RLock()
+	assert.Equal(t, provider.available, binance.availablePairs)
+	assert.Equal(t, []pftypes.CurrencyPair{usdtUSD}, provider.pairs)
+	o.mtx.RUnlock()
+
+	cancel()
+	<-done
+}
+
+func TestAvailablePairsBackoff(t *testing.T) {
+	assert.Equal(t, availablePairsRetryMin, availablePairsBackoff(1))
+	assert.Equal(t, 4*availablePairsRetryMin, availablePairsBackoff(3))
+	assert.Equal(t, availablePairsRetryMax, availablePairsBackoff(100))
+}
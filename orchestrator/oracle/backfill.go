@@ -0,0 +1,384 @@
+package oracle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"golang.org/x/sync/errgroup"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+const (
+	// candleBackfillTimeout bounds the REST requests backfilling the candles of
+	// the pairs subscribed since the previous tick.
+	candleBackfillTimeout = 10 * time.Second
+	// candleBackfillLimit is the number of 1 minute candles requested per pair,
+	// enough to cover the candles window.
+	candleBackfillLimit = int(candlesWindow/time.Minute) + 1
+	// candleBackfillMaxBody limits the size of the REST responses.
+	candleBackfillMaxBody = 1 << 20
+)
+
+type (
+	// candleFetchFn returns the recent 1 minute candles of a pair from the REST
+	// API at host, with the timestamps the provider websocket uses.
+	candleFetchFn func(ctx context.Context, host string, pair pftypes.CurrencyPair) ([]pftypes.CandlePrice, error)
+
+	// candleBackfiller fetches the candles of a provider over REST.
+	candleBackfiller struct {
+		host  string
+		fetch candleFetchFn
+	}
+)
+
+// defaultCandleBackfillers returns the backfillers of the providers whose REST
+// API serves 1 minute candles.
+func defaultCandleBackfillers() map[pfprovider.Name]candleBackfiller {
+	return map[pfprovider.Name]candleBackfiller{
+		pfprovider.ProviderBinance:   {host: "https://api1.binance.com", fetch: fetchBinanceCandles},
+		pfprovider.ProviderBinanceUS: {host: "https://api.binance.us", fetch: fetchBinanceCandles},
+		pfprovider.ProviderKraken:    {host: pfprovider.KrakenRestHost, fetch: fetchKrakenCandles},
+		pfprovider.ProviderOkx:       {host: "https://www.okx.com", fetch: fetchOkxCandles},
+	}
+}
+
+// OptionCandleBackfill enables or disables the candle backfill, enabled by
+// default. When enabled, the recent candles of the pairs subscribed on Binance,
+// Kraken or OKX are fetched over REST on the next tick, so their prices are
+// computed with TVWAP right away instead of falling back to VWAP while the
+// websockets fill the candles window.
+func OptionCandleBackfill(enabled bool) Option {
+	return func(o *Oracle) {
+		if enabled {
+			o.candleBackfillers = defaultCandleBackfillers()
+		} else {
+			o.candleBackfillers = nil
+		}
+	}
+}
+
+// queueCandleBackfill records newly subscribed pairs of a provider, whose
+// candles are backfilled on the next tick. Nothing is queued when the candles
+// aren't used for the prices.
+func (o *Oracle) queueCandleBackfill(providerName pfprovider.Name, pairs []pftypes.CurrencyPair) {
+	if _, ok := o.candleBackfillers[providerName]; !ok || o.aggregation != AggregationTVWAP {
+		return
+	}
+
+	if o.backfillPending == nil {
+		o.backfillPending = map[pfprovider.Name][]pftypes.CurrencyPair{}
+	}
+	o.backfillPending[providerName] = append(o.backfillPending[providerName], pairs...)
+}
+
+// backfillPairs takes the queued pairs still subscribed, one per base since the
+// candles are kept by base. A pair quoted in ETH or BTC is left out when the
+// provider has a stablecoin pair for its base, which is preferred.
+func (o *Oracle) backfillPairs() map[pfprovider.Name][]pftypes.CurrencyPair {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	pending := o.backfillPending
+	o.backfillPending = nil
+
+	pairs := map[pfprovider.Name][]pftypes.CurrencyPair{}
+
+	for providerName, queued := range pending {
+		subscribed := map[string]struct{}{}
+		stablecoinQuoted := map[string]struct{}{}
+		for _, pair := range o.providerSubscribedPairs[providerName] {
+			subscribed[pair.String()] = struct{}{}
+			if !isCrossQuote(pair.Quote) {
+				stablecoinQuoted[pair.Base] = struct{}{}
+			}
+		}
+
+		seen := map[string]struct{}{}
+		for _, pair := range queued {
+			if _, ok := subscribed[pair.String()]; !ok {
+				continue
+			}
+			if _, ok := seen[pair.Base]; ok {
+				continue
+			}
+			if _, ok := stablecoinQuoted[pair.Base]; ok && isCrossQuote(pair.Quote) {
+				continue
+			}
+
+			seen[pair.Base] = struct{}{}
+			pairs[providerName] = append(pairs[providerName], pair)
+		}
+	}
+
+	return pairs
+}
+
+// backfillCandles fetches over REST the candles of the pairs subscribed since
+// the previous tick, limited to the candles window. Pairs failing to backfill
+// are left to the provider websockets.
+func (o *Oracle) backfillCandles() pfprovider.AggregatedProviderCandles {
+	backfilled := pfprovider.AggregatedProviderCandles{}
+
+	pairs := o.backfillPairs()
+	if len(pairs) == 0 {
+		return backfilled
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), candleBackfillTimeout)
+	defer cancel()
+
+	since := pfprovider.PastUnixTime(candlesWindow)
+	g := new(errgroup.Group)
+	mtx := new(sync.Mutex)
+	count := 0
+
+	for providerName, providerPairs := range pairs {
+		providerName := providerName
+		backfiller := o.candleBackfillers[providerName]
+
+		for _, pair := range providerPairs {
+			pair := pair
+
+			g.Go(func() error {
+				if err := o.providerLimiter(providerName).Wait(ctx); err != nil {
+					o.logger.Debug().Err(err).
+						Str("provider_name", string(providerName)).
+						Str("pair_symbol", pair.String()).
+						Msg("rate limited; candles not backfilled")
+					return nil
+				}
+
+				candles, err := backfiller.fetch(ctx, backfiller.host, pair)
+				if err != nil {
+					o.logger.Debug().Err(err).
+						Str("provider_name", string(providerName)).
+						Str("pair_symbol", pair.String()).
+						Msg("failed to backfill candles")
+					return nil
+				}
+
+				recent := make([]pftypes.CandlePrice, 0, len(candles))
+				for _, candle := range candles {
+					if candle.TimeStamp > since {
+						recent = append(recent, candle)
+					}
+				}
+
+				if len(recent) == 0 {
+					return nil
+				}
+
+				mtx.Lock()
+				defer mtx.Unlock()
+
+				if _, ok := backfilled[providerName]; !ok {
+					backfilled[providerName] = map[string][]pftypes.CandlePrice{}
+				}
+				backfilled[providerName][pair.Base] = recent
+				count++
+
+				return nil
+			})
+		}
+	}
+
+	_ = g.Wait()
+
+	o.logger.Debug().Int("pairs", count).Msg("backfilled candles")
+
+	return backfilled
+}
+
+// fetchBinanceCandles fetches the klines of a pair, timestamped at their close
+// like the kline stream.
+// REF: https://binance-docs.github.io/apidocs/spot/en/#kline-candlestick-data
+func fetchBinanceCandles(ctx context.Context, host string, pair pftypes.CurrencyPair) ([]pftypes.CandlePrice, error) {
+	query := url.Values{
+		"symbol":   {pair.String()},
+		"interval": {"1m"},
+		"limit":    {strconv.Itoa(candleBackfillLimit)},
+	}
+
+	var klines [][]interface{}
+	if err := getBackfillJSON(ctx, host+"/api/v3/klines?"+query.Encode(), &klines); err != nil {
+		return nil, err
+	}
+
+	candles := make([]pftypes.CandlePrice, 0, len(klines))
+	for _, kline := range klines {
+		if len(kline) < 7 {
+			return nil, fmt.Errorf("invalid binance kline: %v", kline)
+		}
+
+		candle, err := newBackfillCandle(kline[4], kline[5], kline[6], 1)
+		if err != nil {
+			return nil, err
+		}
+		candles = append(candles, candle)
+	}
+
+	return candles, nil
+}
+
+// fetchKrakenCandles fetches the OHLC of a pair, timestamped at their end like
+// the ohlc-1 channel.
+// REF: https://docs.kraken.com/rest/#tag/Market-Data/operation/getOHLCData
+func fetchKrakenCandles(ctx context.Context, host string, pair pftypes.CurrencyPair) ([]pftypes.CandlePrice, error) {
+	query := url.Values{
+		"pair":     {strings.Replace(pair.String(), symbolBTC, "XBT", 1)},
+		"interval": {"1"},
+		"since":    {strconv.FormatInt(time.Now().Add(-candlesWindow-time.Minute).Unix(), 10)},
+	}
+
+	var resp struct {
+		Error  []string                   `json:"error"`
+		Result map[string]json.RawMessage `json:"result"`
+	}
+	if err := getBackfillJSON(ctx, host+"/0/public/OHLC?"+query.Encode(), &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Error) > 0 {
+		return nil, fmt.Errorf("kraken error: %s", strings.Join(resp.Error, ", "))
+	}
+
+	var candles []pftypes.CandlePrice
+	for key, raw := range resp.Result {
+		// the result holds the OHLC under the kraken pair name, and the id of the
+		// most recent one under "last"
+		if key == "last" {
+			continue
+		}
+
+		var ohlc [][]interface{}
+		if err := decodeBackfillJSON(bytes.NewReader(raw), &ohlc); err != nil {
+			return nil, err
+		}
+
+		for _, entry := range ohlc {
+			if len(entry) < 7 {
+				return nil, fmt.Errorf("invalid kraken ohlc: %v", entry)
+			}
+
+			candle, err := newBackfillCandle(entry[4], entry[6], entry[0], 1000)
+			if err != nil {
+				return nil, err
+			}
+			candle.TimeStamp += time.Minute.Milliseconds()
+			candles = append(candles, candle)
+		}
+	}
+
+	return candles, nil
+}
+
+// fetchOkxCandles fetches the candlesticks of a pair, timestamped at their
+// start like the candle1m channel.
+// REF: https://www.okx.com/docs-v5/en/#rest-api-market-data-get-candlesticks
+func fetchOkxCandles(ctx context.Context, host string, pair pftypes.CurrencyPair) ([]pftypes.CandlePrice, error) {
+	query := url.Values{
+		"instId": {pair.Base + "-" + pair.Quote},
+		"bar":    {"1m"},
+		"limit":  {strconv.Itoa(candleBackfillLimit)},
+	}
+
+	var resp struct {
+		Code string          `json:"code"`
+		Msg  string          `json:"msg"`
+		Data [][]interface{} `json:"data"`
+	}
+	if err := getBackfillJSON(ctx, host+"/api/v5/market/candles?"+query.Encode(), &resp); err != nil {
+		return nil, err
+	}
+	if resp.Code != "0" {
+		return nil, fmt.Errorf("okx error %s: %s", resp.Code, resp.Msg)
+	}
+
+	candles := make([]pftypes.CandlePrice, 0, len(resp.Data))
+	for _, entry := range resp.Data {
+		if len(entry) < 6 {
+			return nil, fmt.Errorf("invalid okx candle: %v", entry)
+		}
+
+		candle, err := newBackfillCandle(entry[4], entry[5], entry[0], 1)
+		if err != nil {
+			return nil, err
+		}
+		candles = append(candles, candle)
+	}
+
+	return candles, nil
+}
+
+// newBackfillCandle returns a candle from the JSON values of its close price,
+// volume and timestamp, the timestamp being scaled into unix milliseconds.
+func newBackfillCandle(closePrice, volume, timestamp interface{}, timestampScale int64) (pftypes.CandlePrice, error) {
+	ts, err := strconv.ParseInt(jsonValue(timestamp), 10, 64)
+	if err != nil {
+		return pftypes.CandlePrice{}, fmt.Errorf("invalid candle timestamp %v: %w", timestamp, err)
+	}
+
+	price, err := sdk.NewDecFromStr(jsonValue(closePrice))
+	if err != nil {
+		return pftypes.CandlePrice{}, fmt.Errorf("invalid candle price %v: %w", closePrice, err)
+	}
+
+	vol, err := sdk.NewDecFromStr(jsonValue(volume))
+	if err != nil {
+		return pftypes.CandlePrice{}, fmt.Errorf("invalid candle volume %v: %w", volume, err)
+	}
+
+	return pftypes.CandlePrice{Price: price, Volume: vol, TimeStamp: ts * timestampScale}, nil
+}
+
+// jsonValue returns the text of a JSON string or number.
+func jsonValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// getBackfillJSON decodes the JSON response of a GET request into v.
+func getBackfillJSON(ctx context.Context, reqURL string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return decodeBackfillJSON(io.LimitReader(resp.Body, candleBackfillMaxBody), v)
+}
+
+// decodeBackfillJSON decodes JSON into v, keeping the numbers as json.Number so
+// prices and timestamps aren't rounded.
+func decodeBackfillJSON(r io.Reader, v interface{}) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	return dec.Decode(v)
+}
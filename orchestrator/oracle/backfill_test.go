@@ -0,0 +1,178 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+func candleWithVolume(price, volume string, timestamp int64) pftypes.CandlePrice {
+	return pftypes.CandlePrice{
+		Price:     sdk.MustNewDecFromStr(price),
+		Volume:    sdk.MustNewDecFromStr(volume),
+		TimeStamp: timestamp,
+	}
+}
+
+func TestFetchCandles(t *testing.T) {
+	ethUSDT := pftypes.CurrencyPair{Base: "ETH", Quote: "USDT"}
+	btcUSDT := pftypes.CurrencyPair{Base: "BTC", Quote: "USDT"}
+
+	testCases := []struct {
+		name     string
+		fetch    candleFetchFn
+		pair     pftypes.CurrencyPair
+		path     string
+		query    string
+		response string
+		expected []pftypes.CandlePrice
+	}{
+		{
+			name:  "binance",
+			fetch: fetchBinanceCandles,
+			pair:  ethUSDT,
+			path:  "/api/v3/klines",
+			query: "symbol=ETHUSDT",
+			response: `[
+				[1664974020000,"1350.10","1351.00","1349.90","1350.50","12.5",1664974079999,"0",10,"0","0","0"],
+				[1664974080000,"1350.50","1352.00","1350.00","1351.25","7",1664974139999,"0",5,"0","0","0"]
+			]`,
+			expected: []pftypes.CandlePrice{
+				candleWithVolume("1350.50", "12.5", 1664974079999),
+				candleWithVolume("1351.25", "7", 1664974139999),
+			},
+		},
+		{
+			name:  "kraken",
+			fetch: fetchKrakenCandles,
+			pair:  btcUSDT,
+			path:  "/0/public/OHLC",
+			query: "pair=XBTUSDT",
+			response: `{"error":[],"result":{
+				"XBTUSDT":[[1664974020,"20100.0","20110.0","20090.0","20105.5","20101.2","0.25",8]],
+				"last":1664974020
+			}}`,
+			expected: []pftypes.CandlePrice{candleWithVolume("20105.5", "0.25", 1664974080000)},
+		},
+		{
+			name:     "okx",
+			fetch:    fetchOkxCandles,
+			pair:     ethUSDT,
+			path:     "/api/v5/market/candles",
+			query:    "instId=ETH-USDT",
+			response: `{"code":"0","msg":"","data":[["1664974080000","1350.5","1352","1350","1351.25","7","9459","9459","0"]]}`,
+			expected: []pftypes.CandlePrice{candleWithVolume("1351.25", "7", 1664974080000)},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tc.path, r.URL.Path)
+				assert.Contains(t, r.URL.RawQuery, tc.query)
+				fmt.Fprint(w, tc.response)
+			}))
+			defer server.Close()
+
+			candles, err := tc.fetch(context.Background(), server.URL, tc.pair)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, candles)
+		})
+	}
+}
+
+func TestFetchCandlesErrors(t *testing.T) {
+	pair := pftypes.CurrencyPair{Base: "ETH", Quote: "USDT"}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/0/public/OHLC":
+			fmt.Fprint(w, `{"error":["EQuery:Unknown asset pair"]}`)
+		case "/api/v5/market/candles":
+			fmt.Fprint(w, `{"code":"51001","msg":"Instrument ID does not exist","data":[]}`)
+		default:
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	for _, fetch := range []candleFetchFn{fetchBinanceCandles, fetchKrakenCandles, fetchOkxCandles} {
+		_, err := fetch(context.Background(), server.URL, pair)
+		assert.Error(t, err)
+	}
+}
+
+func TestBackfillCandles(t *testing.T) {
+	recent := pfprovider.PastUnixTime(time.Minute)
+	expired := pfprovider.PastUnixTime(2 * candlesWindow)
+
+	var (
+		mtx     sync.Mutex
+		fetched []string
+	)
+	backfiller := candleBackfiller{
+		fetch: func(_ context.Context, _ string, pair pftypes.CurrencyPair) ([]pftypes.CandlePrice, error) {
+			mtx.Lock()
+			fetched = append(fetched, pair.String())
+			mtx.Unlock()
+
+			if pair.Base == "ATOM" {
+				return nil, fmt.Errorf("rate limited")
+			}
+
+			return []pftypes.CandlePrice{candle("1000", expired), candle("1200", recent)}, nil
+		},
+	}
+
+	ethUSDT := pftypes.CurrencyPair{Base: "ETH", Quote: "USDT"}
+	ethBTC := pftypes.CurrencyPair{Base: "ETH", Quote: "BTC"}
+	atomUSDT := pftypes.CurrencyPair{Base: "ATOM", Quote: "USDT"}
+	umeeUSDT := pftypes.CurrencyPair{Base: "UMEE", Quote: "USDT"}
+
+	o := &Oracle{
+		logger:      zerolog.Nop(),
+		aggregation: AggregationTVWAP,
+		candleBackfillers: map[pfprovider.Name]candleBackfiller{
+			pfprovider.ProviderBinance: backfiller,
+		},
+		providerSubscribedPairs: map[pfprovider.Name][]pftypes.CurrencyPair{
+			pfprovider.ProviderBinance: {ethUSDT, ethBTC, atomUSDT},
+		},
+	}
+
+	// providers without REST candles aren't queued
+	o.queueCandleBackfill(pfprovider.ProviderMexc, []pftypes.CurrencyPair{ethUSDT})
+	// UMEE was unsubscribed before the tick
+	o.queueCandleBackfill(pfprovider.ProviderBinance, []pftypes.CurrencyPair{ethUSDT, ethBTC, atomUSDT, umeeUSDT})
+
+	backfilled := o.backfillCandles()
+
+	// the cross pair is left out as ETH is quoted in a stablecoin
+	assert.ElementsMatch(t, []string{"ETHUSDT", "ATOMUSDT"}, fetched)
+	assert.Equal(t, pfprovider.AggregatedProviderCandles{
+		pfprovider.ProviderBinance: {"ETH": {candle("1200", recent)}},
+	}, backfilled)
+
+	// the queue is emptied
+	fetched = nil
+	assert.Empty(t, o.backfillCandles())
+	assert.Empty(t, fetched)
+
+	// candles aren't backfilled when they aren't used
+	o.aggregation = AggregationMedian
+	o.queueCandleBackfill(pfprovider.ProviderBinance, []pftypes.CurrencyPair{ethUSDT})
+	assert.Empty(t, o.backfillPending)
+}
@@ -0,0 +1,73 @@
+package oracle
+
+import (
+	"context"
+	"time"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+
+	"github.com/umee-network/peggo/orchestrator/breaker"
+)
+
+// OptionProviderBreaker sidelines a provider from the aggregation after
+// maxFailures consecutive ticks failing to get both its ticker prices and its
+// candles. The provider is then reconnected, and fetched again once the backoff
+// elapses, starting at minBackoff and doubling up to maxBackoff while it keeps
+// failing; it's added back to the aggregation once a fetch succeeds. A
+// maxFailures of zero disables it.
+func OptionProviderBreaker(maxFailures int, minBackoff, maxBackoff time.Duration) Option {
+	return func(o *Oracle) {
+		o.breakerMaxFailures = maxFailures
+		o.breakerMinBackoff = minBackoff
+		o.breakerMaxBackoff = maxBackoff
+	}
+}
+
+// newProviderBreaker returns the circuit breaker of a provider, or nil when the
+// breakers are disabled. The provider is marked for reconnection whenever its
+// breaker opens.
+func (o *Oracle) newProviderBreaker(providerName pfprovider.Name, provider *Provider) *breaker.Breaker {
+	if o.breakerMaxFailures <= 0 {
+		return nil
+	}
+
+	b := breaker.New(
+		o.logger,
+		"oracle-"+string(providerName),
+		o.breakerMaxFailures,
+		o.breakerMinBackoff,
+		o.breakerMaxBackoff,
+	)
+
+	b.OnStateChange(func(state breaker.State) {
+		if state == breaker.StateOpen {
+			provider.reconnectPending.Store(true)
+		}
+
+		o.observeBreakerState(providerName, state)
+	})
+
+	return b
+}
+
+// reconnectSidelinedProviders reconnects the providers whose breaker opened
+// since their last reconnection. The breaker lets a fetch through the new
+// connection once its backoff elapses; if that fetch fails, the breaker opens
+// again and the provider is reconnected again.
+func (o *Oracle) reconnectSidelinedProviders(ctx context.Context) {
+	o.mtx.RLock()
+	var sidelined []providerReconnection
+	for providerName, provider := range o.providers {
+		if provider.reconnectPending.CompareAndSwap(true, false) {
+			sidelined = append(sidelined, newProviderReconnection(providerName, provider))
+		}
+	}
+	o.mtx.RUnlock()
+
+	o.reconnectProviders(ctx, sidelined, "provider sidelined by its circuit breaker")
+}
+
+// isProviderFailure counts every failed provider fetch as a failure.
+func isProviderFailure(error) bool {
+	return true
+}
@@ -0,0 +1,79 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+
+	"github.com/umee-network/peggo/orchestrator/breaker"
+)
+
+// failingProvider fails every fetch.
+type failingProvider struct {
+	fakeProvider
+	fetches int
+}
+
+func (p *failingProvider) GetTickerPrices(...pftypes.CurrencyPair) (map[string]pftypes.TickerPrice, error) {
+	p.fetches++
+	return nil, errors.New("websocket closed")
+}
+
+func TestProviderBreaker(t *testing.T) {
+	failing := &failingProvider{}
+	provider := &Provider{Provider: failing}
+
+	var reconnected []pfprovider.Provider
+	o := &Oracle{
+		logger:         zerolog.Nop(),
+		computeWorkers: 1,
+		providers:      map[pfprovider.Name]*Provider{pfprovider.ProviderBinance: provider},
+		newProvider: func(
+			context.Context,
+			zerolog.Logger,
+			pfprovider.Name,
+			...pftypes.CurrencyPair,
+		) (pfprovider.Provider, error) {
+			p := &failingProvider{}
+			reconnected = append(reconnected, p)
+			return p, nil
+		},
+	}
+	OptionProviderBreaker(2, time.Hour, time.Hour)(o)
+	provider.breaker = o.newProviderBreaker(pfprovider.ProviderBinance, provider)
+
+	o.setPrices()
+	assert.Equal(t, breaker.StateClosed, provider.breaker.State())
+	assert.False(t, provider.reconnectPending.Load())
+
+	o.setPrices()
+	assert.Equal(t, breaker.StateOpen, provider.breaker.State())
+	assert.True(t, provider.reconnectPending.Load())
+
+	// the sidelined provider isn't fetched until the backoff elapses
+	o.setPrices()
+	assert.Equal(t, 2, failing.fetches)
+
+	o.reconnectSidelinedProviders(context.Background())
+	require.Len(t, reconnected, 1)
+	assert.Same(t, reconnected[0], provider.Provider)
+	assert.False(t, provider.reconnectPending.Load())
+
+	// it's reconnected once per opening
+	o.reconnectSidelinedProviders(context.Background())
+	assert.Len(t, reconnected, 1)
+
+	require.NoError(t, o.tracker().Stop(time.Second))
+}
+
+func TestProviderBreakerDisabled(t *testing.T) {
+	o := &Oracle{logger: zerolog.Nop()}
+	assert.Nil(t, o.newProviderBreaker(pfprovider.ProviderBinance, &Provider{}))
+}
@@ -0,0 +1,150 @@
+package oracle
+
+import (
+	"sort"
+	"time"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+
+	"github.com/umee-network/peggo/orchestrator/store"
+)
+
+const (
+	// candlesStoreKey is the store key holding the recent provider candles.
+	candlesStoreKey = "oracle_candles"
+	// candlesWindow is how long candles are kept; it matches the TVWAP period of
+	// the price-feeder, so older candles would be ignored anyway.
+	candlesWindow = 5 * time.Minute
+	// candlesPersistInterval is the minimum time between each candles write.
+	candlesPersistInterval = 30 * time.Second
+)
+
+// Option configures optional oracle features.
+type Option func(*Oracle)
+
+// OptionStore persists the recent provider candles to the given store and
+// reloads them at startup, so prices are computed with TVWAP right after a
+// restart instead of waiting for the providers to fill a new candle window.
+// The last known prices are also persisted on shutdown and restored at startup
+// (see loadPrices).
+func OptionStore(s *store.Store) Option {
+	return func(o *Oracle) { o.store = s }
+}
+
+// loadCandles loads the candles persisted by a previous run, dropping the ones
+// outside of the candles window.
+func (o *Oracle) loadCandles() {
+	if o.store == nil {
+		return
+	}
+
+	stored := pfprovider.AggregatedProviderCandles{}
+	if _, err := o.store.Get(candlesStoreKey, &stored); err != nil {
+		o.logger.Warn().Err(err).Msg("failed to load persisted candles; starting without them")
+		return
+	}
+
+	o.candles = mergeCandles(stored, nil, pfprovider.PastUnixTime(candlesWindow))
+	o.logger.Debug().Int("providers", len(o.candles)).Msg("loaded persisted candles")
+}
+
+// persistCandles writes the recent candles to the store, at most once every
+// candlesPersistInterval. The candles are copied under the oracle lock and
+// written outside of it, so the write doesn't hold up the price computations.
+func (o *Oracle) persistCandles() {
+	if o.store == nil {
+		return
+	}
+
+	o.candlesPersistMtx.Lock()
+	defer o.candlesPersistMtx.Unlock()
+
+	if time.Since(o.candlesPersistedAt) < candlesPersistInterval {
+		return
+	}
+
+	o.mtx.RLock()
+	candles := mergeCandles(o.candles, nil, 0)
+	o.mtx.RUnlock()
+
+	if err := o.store.Set(candlesStoreKey, candles); err != nil {
+		o.logger.Debug().Err(err).Msg("failed to persist candles")
+		return
+	}
+
+	o.candlesPersistedAt = time.Now()
+}
+
+// withStoredCandles merges the fresh provider candles with the ones kept from
+// previous ticks and runs, limited to the pairs each provider is subscribed to.
+// The merged candles are kept for the next tick; a copy is returned since the
+// price-feeder converts candles to USD in place.
+func (o *Oracle) withStoredCandles(fresh pfprovider.AggregatedProviderCandles) pfprovider.AggregatedProviderCandles {
+	stored := pfprovider.AggregatedProviderCandles{}
+
+	for providerName, pairs := range o.providerSubscribedPairs {
+		for _, pair := range pairs {
+			if candles, ok := o.candles[providerName][pair.Base]; ok {
+				if _, ok := stored[providerName]; !ok {
+					stored[providerName] = map[string][]pftypes.CandlePrice{}
+				}
+				stored[providerName][pair.Base] = candles
+			}
+		}
+	}
+
+	since := pfprovider.PastUnixTime(candlesWindow)
+	o.candles = mergeCandles(stored, fresh, since)
+
+	return mergeCandles(o.candles, nil, since)
+}
+
+// mergeCandles returns the candles of both sets more recent than since (in unix
+// milliseconds), sorted by timestamp. Fresh candles replace stored ones with
+// the same timestamp. The given sets are not modified.
+func mergeCandles(
+	stored, fresh pfprovider.AggregatedProviderCandles,
+	since int64,
+) pfprovider.AggregatedProviderCandles {
+	byTimestamp := map[pfprovider.Name]map[string]map[int64]pftypes.CandlePrice{}
+
+	for _, set := range []pfprovider.AggregatedProviderCandles{stored, fresh} {
+		for providerName, bases := range set {
+			for base, candles := range bases {
+				for _, candle := range candles {
+					if candle.TimeStamp <= since {
+						continue
+					}
+
+					if _, ok := byTimestamp[providerName]; !ok {
+						byTimestamp[providerName] = map[string]map[int64]pftypes.CandlePrice{}
+					}
+					if _, ok := byTimestamp[providerName][base]; !ok {
+						byTimestamp[providerName][base] = map[int64]pftypes.CandlePrice{}
+					}
+
+					byTimestamp[providerName][base][candle.TimeStamp] = candle
+				}
+			}
+		}
+	}
+
+	merged := pfprovider.AggregatedProviderCandles{}
+
+	for providerName, bases := range byTimestamp {
+		merged[providerName] = map[string][]pftypes.CandlePrice{}
+
+		for base, candles := range bases {
+			sorted := make([]pftypes.CandlePrice, 0, len(candles))
+			for _, candle := range candles {
+				sorted = append(sorted, candle)
+			}
+			sort.Slice(sorted, func(i, j int) bool { return sorted[i].TimeStamp < sorted[j].TimeStamp })
+
+			merged[providerName][base] = sorted
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,121 @@
+package oracle
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+
+	"github.com/umee-network/peggo/orchestrator/store"
+)
+
+func candle(price string, timestamp int64) pftypes.CandlePrice {
+	return pftypes.CandlePrice{
+		Price:     sdk.MustNewDecFromStr(price),
+		Volume:    sdk.OneDec(),
+		TimeStamp: timestamp,
+	}
+}
+
+func TestMergeCandles(t *testing.T) {
+	stored := pfprovider.AggregatedProviderCandles{
+		pfprovider.ProviderBinance: {
+			"ETH": {candle("1000", 1), candle("1100", 20), candle("1200", 30)},
+		},
+	}
+	fresh := pfprovider.AggregatedProviderCandles{
+		pfprovider.ProviderBinance: {
+			"ETH": {candle("1250", 30), candle("1300", 40)},
+		},
+		pfprovider.ProviderKraken: {
+			"ETH": {candle("1310", 40)},
+		},
+	}
+
+	merged := mergeCandles(stored, fresh, 10)
+
+	assert.Equal(t, []pftypes.CandlePrice{
+		candle("1100", 20), candle("1250", 30), candle("1300", 40),
+	}, merged[pfprovider.ProviderBinance]["ETH"])
+	assert.Equal(t, []pftypes.CandlePrice{candle("1310", 40)}, merged[pfprovider.ProviderKraken]["ETH"])
+
+	// the given sets are left untouched
+	assert.Len(t, stored[pfprovider.ProviderBinance]["ETH"], 3)
+}
+
+func TestPersistCandles(t *testing.T) {
+	s, err := store.New(t.TempDir())
+	require.NoError(t, err)
+
+	recent := pfprovider.PastUnixTime(time.Minute)
+	expired := pfprovider.PastUnixTime(2 * candlesWindow)
+
+	o := &Oracle{
+		logger: zerolog.Nop(),
+		store:  s,
+		providerSubscribedPairs: map[pfprovider.Name][]pftypes.CurrencyPair{
+			pfprovider.ProviderBinance: {{Base: "ETH", Quote: "USDT"}},
+		},
+		candles: pfprovider.AggregatedProviderCandles{},
+	}
+
+	o.withStoredCandles(pfprovider.AggregatedProviderCandles{
+		pfprovider.ProviderBinance: {
+			"ETH": {candle("1000", expired), candle("1200", recent)},
+		},
+	})
+	o.persistCandles()
+
+	// a new oracle reloads the recent candles
+	restarted := &Oracle{logger: zerolog.Nop(), store: s}
+	restarted.loadCandles()
+
+	require.Len(t, restarted.candles[pfprovider.ProviderBinance]["ETH"], 1)
+	assert.Equal(t, recent, restarted.candles[pfprovider.ProviderBinance]["ETH"][0].TimeStamp)
+	assert.Equal(t, "1200.000000000000000000", restarted.candles[pfprovider.ProviderBinance]["ETH"][0].Price.String())
+}
+
+func TestPersistCandlesConcurrent(t *testing.T) {
+	s, err := store.New(t.TempDir())
+	require.NoError(t, err)
+
+	o := &Oracle{
+		logger: zerolog.Nop(),
+		store:  s,
+		providerSubscribedPairs: map[pfprovider.Name][]pftypes.CurrencyPair{
+			pfprovider.ProviderBinance: {{Base: "ETH", Quote: "USDT"}},
+		},
+		candles: pfprovider.AggregatedProviderCandles{},
+	}
+
+	// persisting concurrently with the oracle loop merging candles; run with -race
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			o.persistCandles()
+		}()
+		go func() {
+			defer wg.Done()
+
+			o.mtx.Lock()
+			defer o.mtx.Unlock()
+			o.withStoredCandles(pfprovider.AggregatedProviderCandles{
+				pfprovider.ProviderBinance: {"ETH": {candle("1200", pfprovider.PastUnixTime(time.Minute))}},
+			})
+		}()
+	}
+	wg.Wait()
+
+	// the writes are throttled
+	persistedAt := o.candlesPersistedAt
+	o.persistCandles()
+	assert.Equal(t, persistedAt, o.candlesPersistedAt)
+}
@@ -0,0 +1,240 @@
+package oracle
+
+import (
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+const (
+	// DefaultComputeWorkers is the default maximum number of bases whose price
+	// is computed at the same time.
+	DefaultComputeWorkers = 4
+	// DefaultFetchTimeout is the default time a tick waits for the prices of
+	// each provider.
+	DefaultFetchTimeout = 5 * time.Second
+	// computeOwner owns the price computation goroutines.
+	computeOwner = "compute"
+	// fetchOwner owns the provider fetch goroutines.
+	fetchOwner = "fetch"
+)
+
+// OptionComputeWorkers sets the maximum number of bases whose price is computed
+// at the same time. Values below 1 are raised to 1.
+func OptionComputeWorkers(workers int) Option {
+	return func(o *Oracle) {
+		if workers < 1 {
+			workers = 1
+		}
+
+		o.computeWorkers = workers
+	}
+}
+
+// OptionFetchTimeout sets how long a tick waits for the prices of each
+// provider. The prices are computed without the providers answering later,
+// which are skipped until their fetch returns. Zero waits for every provider.
+func OptionFetchTimeout(timeout time.Duration) Option {
+	return func(o *Oracle) {
+		o.fetchTimeout = timeout
+	}
+}
+
+// computePrices computes the price of each base on its own, with the stablecoin
+// prices needed to convert it into USD, in a pool of computeWorkers workers.
+// Each price is set as soon as it's computed, so a base that is slow or fails
+// to compute doesn't hold back the others. The prices of the bases no longer
+// reported by any provider are removed, except the restored ones during their
+// grace period and the ones held within the interpolation gap; the ones failing
+// to compute are kept. The price alert hooks are then called with the prices
+// that moved or disappeared since the previous tick.
+func (o *Oracle) computePrices(
+	candles pfprovider.AggregatedProviderCandles,
+	prices pfprovider.AggregatedProviderPrices,
+	providerPairs map[pfprovider.Name][]pftypes.CurrencyPair,
+	deviations map[string]sdk.Dec,
+) {
+	previous := o.alertPrices()
+
+	stablecoins := map[string]struct{}{}
+	for _, pair := range stablecoinPairs {
+		stablecoins[pair.Base] = struct{}{}
+	}
+
+	// the stablecoins are computed together, and with every other base
+	groups := [][]string{nil}
+	seen := map[string]struct{}{}
+	for _, pairs := range providerPairs {
+		for _, pair := range pairs {
+			if _, ok := seen[pair.Base]; ok {
+				continue
+			}
+			seen[pair.Base] = struct{}{}
+
+			if _, ok := stablecoins[pair.Base]; ok {
+				groups[0] = append(groups[0], pair.Base)
+			} else {
+				groups = append(groups, []string{pair.Base})
+			}
+		}
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mtx     sync.Mutex
+		handled = map[string]struct{}{} // bases computed or failing to compute
+		workers = make(chan struct{}, o.computeWorkers)
+	)
+
+	for _, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+
+		group := group
+		workers <- struct{}{}
+		wg.Add(1)
+
+		go func() {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+
+			keep := map[string]struct{}{}
+			for base := range stablecoins {
+				keep[base] = struct{}{}
+			}
+			for _, base := range group {
+				keep[base] = struct{}{}
+			}
+
+			groupCandles, groupPrices, groupPairs := filterBases(candles, prices, providerPairs, keep)
+			computed, filtered, err := computeFilteredPrices(
+				o.logger,
+				groupCandles,
+				groupPrices,
+				groupPairs,
+				deviations,
+				o.aggregation,
+			)
+
+			mtx.Lock()
+			for _, base := range group {
+				if _, ok := computed[base]; ok || err != nil {
+					handled[base] = struct{}{}
+				}
+			}
+			mtx.Unlock()
+
+			if err != nil {
+				o.logger.Err(err).Strs("bases", group).Msg("failed to compute prices")
+				o.incTickErrors(tickErrorCompute)
+				return
+			}
+
+			// the stablecoins are computed with every group, only count them once
+			o.observeFiltered(group, filtered)
+			o.setComputedPrices(group, computed)
+		}()
+	}
+
+	wg.Wait()
+
+	o.mtx.Lock()
+
+	now := time.Now()
+	for base := range o.prices {
+		if _, ok := handled[base]; !ok && !o.keepRestoredPrice(base, now) && !o.keepInterpolatedPrice(base, now) {
+			delete(o.prices, base)
+			delete(o.priceTimes, base)
+			delete(o.symbolUpdates, base)
+			delete(o.restoredPrices, base)
+			delete(o.interpolatedPrices, base)
+		}
+	}
+
+	alerts := o.priceAlerts(previous, now)
+	o.mtx.Unlock()
+
+	o.firePriceAlerts(alerts)
+}
+
+// setComputedPrices sets the computed prices of the given bases.
+func (o *Oracle) setComputedPrices(bases []string, computed map[string]sdk.Dec) {
+	now := time.Now()
+
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	if o.prices == nil {
+		o.prices = map[string]sdk.Dec{}
+		o.priceTimes = map[string]time.Time{}
+	}
+
+	for _, base := range bases {
+		if price, ok := computed[base]; ok {
+			o.prices[base] = price
+			o.priceTimes[base] = o.symbolUpdatedAt(base, now)
+			o.recordPrice(base, price, now)
+			delete(o.restoredPrices, base)
+			delete(o.interpolatedPrices, base)
+		}
+	}
+}
+
+// filterBases returns copies of the candles, prices and pairs limited to the
+// given bases, so they can be converted into USD in place concurrently.
+func filterBases(
+	candles pfprovider.AggregatedProviderCandles,
+	prices pfprovider.AggregatedProviderPrices,
+	providerPairs map[pfprovider.Name][]pftypes.CurrencyPair,
+	bases map[string]struct{},
+) (
+	pfprovider.AggregatedProviderCandles,
+	pfprovider.AggregatedProviderPrices,
+	map[pfprovider.Name][]pftypes.CurrencyPair,
+) {
+	filteredCandles := pfprovider.AggregatedProviderCandles{}
+	for providerName, baseCandles := range candles {
+		for base, c := range baseCandles {
+			if _, ok := bases[base]; !ok {
+				continue
+			}
+
+			if _, ok := filteredCandles[providerName]; !ok {
+				filteredCandles[providerName] = map[string][]pftypes.CandlePrice{}
+			}
+			filteredCandles[providerName][base] = append([]pftypes.CandlePrice{}, c...)
+		}
+	}
+
+	filteredPrices := pfprovider.AggregatedProviderPrices{}
+	for providerName, tickers := range prices {
+		for base, ticker := range tickers {
+			if _, ok := bases[base]; !ok {
+				continue
+			}
+
+			if _, ok := filteredPrices[providerName]; !ok {
+				filteredPrices[providerName] = map[string]pftypes.TickerPrice{}
+			}
+			filteredPrices[providerName][base] = ticker
+		}
+	}
+
+	filteredPairs := make(map[pfprovider.Name][]pftypes.CurrencyPair, len(providerPairs))
+	for providerName, pairs := range providerPairs {
+		for _, pair := range pairs {
+			if _, ok := bases[pair.Base]; ok {
+				filteredPairs[providerName] = append(filteredPairs[providerName], pair)
+			}
+		}
+	}
+
+	return filteredCandles, filteredPrices, filteredPairs
+}
@@ -0,0 +1,135 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+func TestComputePrices(t *testing.T) {
+	o := &Oracle{
+		logger:         zerolog.Nop(),
+		aggregation:    AggregationVWAP,
+		computeWorkers: 2,
+		prices:         map[string]sdk.Dec{"OLD": sdk.NewDec(1)},
+		priceTimes:     map[string]time.Time{"OLD": time.Now()},
+	}
+
+	pairs := map[pfprovider.Name][]pftypes.CurrencyPair{
+		pfprovider.ProviderBinance: {
+			{Base: "USDT", Quote: "USD"},
+			{Base: "ATOM", Quote: "USDT"},
+			{Base: "UMEE", Quote: "USD"},
+		},
+	}
+	prices := pfprovider.AggregatedProviderPrices{
+		pfprovider.ProviderBinance: {
+			"USDT": {Price: sdk.NewDec(1), Volume: sdk.NewDec(100)},
+			"ATOM": {Price: sdk.NewDec(10), Volume: sdk.NewDec(100)},
+			"UMEE": {Price: sdk.MustNewDecFromStr("0.005"), Volume: sdk.NewDec(100)},
+		},
+	}
+
+	o.computePrices(
+		pfprovider.AggregatedProviderCandles{},
+		prices,
+		pairs,
+		o.deviationThresholdsByBase([]string{"USDT", "ATOM", "UMEE"}),
+	)
+
+	assert.Equal(t, map[string]sdk.Dec{
+		"USDT": sdk.NewDec(1),
+		"ATOM": sdk.NewDec(10),
+		"UMEE": sdk.MustNewDecFromStr("0.005"),
+	}, o.prices)
+	assert.Len(t, o.priceTimes, 3)
+
+	// the shared prices aren't converted in place
+	assert.Equal(t, sdk.NewDec(10), prices[pfprovider.ProviderBinance]["ATOM"].Price)
+}
+
+func TestTickSkipsRunningComputation(t *testing.T) {
+	o := &Oracle{
+		logger:         zerolog.Nop(),
+		computeWorkers: 1,
+		computing:      make(chan struct{}, 1),
+	}
+
+	// a computation is still running
+	o.computing <- struct{}{}
+
+	done := make(chan struct{})
+	go func() {
+		o.tick(context.Background(), false)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("tick blocked on the running computation")
+	}
+
+	assert.Len(t, o.computing, 1)
+}
+
+// blockingProvider doesn't answer until released.
+type blockingProvider struct {
+	fakeProvider
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (p *blockingProvider) GetTickerPrices(...pftypes.CurrencyPair) (map[string]pftypes.TickerPrice, error) {
+	p.calls.Add(1)
+	<-p.release
+	return nil, errors.New("too late")
+}
+
+func TestSetPricesFetchTimeout(t *testing.T) {
+	counting := &countingProvider{}
+	blocking := &blockingProvider{release: make(chan struct{})}
+	slow := &Provider{Provider: blocking}
+
+	pairs := []pftypes.CurrencyPair{{Base: "ETH", Quote: "USDT"}}
+	o := &Oracle{
+		logger:         zerolog.Nop(),
+		computeWorkers: 1,
+		fetchTimeout:   50 * time.Millisecond,
+		providers: map[pfprovider.Name]*Provider{
+			pfprovider.ProviderBinance: {Provider: counting},
+			pfprovider.ProviderKraken:  slow,
+		},
+		providerSubscribedPairs: map[pfprovider.Name][]pftypes.CurrencyPair{
+			pfprovider.ProviderBinance: pairs,
+			pfprovider.ProviderKraken:  pairs,
+		},
+	}
+
+	// the prices are computed without waiting for the slow provider
+	start := time.Now()
+	o.setPrices()
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, 1, counting.fetches)
+
+	// the slow provider is skipped until its fetch returns
+	o.setPrices()
+	assert.Equal(t, 2, counting.fetches)
+	assert.Equal(t, int32(1), blocking.calls.Load())
+
+	close(blocking.release)
+	require.Eventually(t, func() bool { return !slow.fetching.Load() }, time.Second, 10*time.Millisecond)
+
+	o.setPrices()
+	assert.Equal(t, int32(2), blocking.calls.Load())
+}
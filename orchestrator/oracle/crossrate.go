@@ -0,0 +1,120 @@
+package oracle
+
+import (
+	"github.com/rs/zerolog"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+// symbolPairs returns the currency pairs subscribed for a symbol: its
+// stablecoin pairs and, when no provider has any of them, its ETH and BTC
+// pairs along with the stablecoin pairs of ETH and BTC, so its price can be
+// derived from the cross rate.
+func (o *Oracle) symbolPairs(baseSymbol string) []pftypes.CurrencyPair {
+	currencyPairs := GetStablecoinsCurrencyPair(baseSymbol)
+	if isCrossQuote(baseSymbol) || o.anyPairAvailable(currencyPairs) {
+		return currencyPairs
+	}
+
+	currencyPairs = append(currencyPairs, getCrossCurrencyPairs(baseSymbol)...)
+	for _, quote := range crossQuotes {
+		currencyPairs = append(currencyPairs, GetStablecoinsCurrencyPair(quote)...)
+	}
+
+	return currencyPairs
+}
+
+// anyPairAvailable returns true if any provider has any of the currency pairs.
+func (o *Oracle) anyPairAvailable(currencyPairs []pftypes.CurrencyPair) bool {
+	for _, provider := range o.providers {
+		for _, pair := range currencyPairs {
+			if _, ok := provider.availablePairs[pair.String()]; ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// normalizeCrossQuotes converts in place the provider prices and candles quoted
+// in ETH or BTC into USD, at the cross quote's own USD price, and filters out
+// the ones whose cross quote is missing a price. It runs once the stablecoin
+// quotes are normalized, so the cross quotes are priced in USD by then. The
+// returned pairs are all quoted in USD.
+func normalizeCrossQuotes(
+	logger zerolog.Logger,
+	candles pfprovider.AggregatedProviderCandles,
+	prices pfprovider.AggregatedProviderPrices,
+	providerPairs map[pfprovider.Name][]pftypes.CurrencyPair,
+	quotes map[pfprovider.Name]map[string]string,
+) map[pfprovider.Name][]pftypes.CurrencyPair {
+	quoteOf := quoteLookup(providerPairs, quotes)
+	rates := usdRates(candles, prices, quoteOf, crossQuotes)
+
+	dropped := map[pfprovider.Name]map[string]struct{}{}
+	for providerName, pairs := range providerPairs {
+		for _, pair := range pairs {
+			base := pair.Base
+			if _, ok := dropped[providerName][base]; ok {
+				continue
+			}
+
+			quote := quoteOf(providerName, base)
+			if !isCrossQuote(quote) {
+				continue
+			}
+
+			rate, ok := rates[quote]
+			if !ok {
+				logger.Debug().
+					Str("provider_name", string(providerName)).
+					Str("base", base).
+					Str("quote", quote).
+					Msg("no USD price of the cross quote; filtering out the provider price")
+
+				delete(prices[providerName], base)
+				delete(candles[providerName], base)
+				if _, ok := dropped[providerName]; !ok {
+					dropped[providerName] = map[string]struct{}{}
+				}
+				dropped[providerName][base] = struct{}{}
+				continue
+			}
+
+			if ticker, ok := prices[providerName][base]; ok {
+				ticker.Price = ticker.Price.Mul(rate)
+				prices[providerName][base] = ticker
+			}
+
+			for i := range candles[providerName][base] {
+				candles[providerName][base][i].Price = candles[providerName][base][i].Price.Mul(rate)
+			}
+
+			// the base is now quoted in USD, whichever cross quote it was priced in
+			if quotes[providerName] == nil {
+				quotes[providerName] = map[string]string{}
+			}
+			quotes[providerName][base] = symbolUSD
+		}
+	}
+
+	usdPairs := make(map[pfprovider.Name][]pftypes.CurrencyPair, len(providerPairs))
+	for providerName, pairs := range providerPairs {
+		seen := map[string]struct{}{}
+		for _, pair := range pairs {
+			if _, ok := dropped[providerName][pair.Base]; ok {
+				continue
+			}
+			if _, ok := seen[pair.Base]; ok {
+				continue
+			}
+			seen[pair.Base] = struct{}{}
+
+			usdPairs[providerName] = append(usdPairs[providerName], pftypes.CurrencyPair{Base: pair.Base, Quote: symbolUSD})
+		}
+	}
+
+	return usdPairs
+}
@@ -0,0 +1,117 @@
+package oracle
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+func TestNormalizeCrossQuotes(t *testing.T) {
+	ticker := func(price string) pftypes.TickerPrice {
+		return pftypes.TickerPrice{Price: sdk.MustNewDecFromStr(price), Volume: sdk.NewDec(100)}
+	}
+
+	prices := pfprovider.AggregatedProviderPrices{
+		pfprovider.ProviderBinance: {
+			"USDT": ticker("1"),
+			"ETH":  ticker("2000"),
+			"FOO":  ticker("0.001"),
+		},
+		pfprovider.ProviderKraken: {
+			"BAR": ticker("0.5"),
+		},
+	}
+	candles := pfprovider.AggregatedProviderCandles{
+		pfprovider.ProviderBinance: {
+			"FOO": {{Price: sdk.MustNewDecFromStr("0.002"), Volume: sdk.NewDec(1), TimeStamp: 1}},
+		},
+	}
+	providerPairs := map[pfprovider.Name][]pftypes.CurrencyPair{
+		pfprovider.ProviderBinance: {
+			{Base: "USDT", Quote: "USD"},
+			{Base: "ETH", Quote: "USDT"},
+			{Base: "FOO", Quote: "ETH"},
+		},
+		pfprovider.ProviderKraken: {
+			{Base: "BAR", Quote: "BTC"},
+		},
+	}
+	quotes := map[pfprovider.Name]map[string]string{
+		pfprovider.ProviderBinance: {"USDT": "USD", "ETH": "USDT", "FOO": "ETH"},
+		pfprovider.ProviderKraken:  {"BAR": "BTC"},
+	}
+
+	pairs := normalizeStablecoinQuotes(
+		zerolog.Nop(),
+		candles,
+		prices,
+		providerPairs,
+		quotes,
+		DefaultStablecoinDepegThreshold,
+	)
+
+	// the cross quoted prices are left to normalizeCrossQuotes
+	assert.Equal(t, sdk.MustNewDecFromStr("0.001"), prices[pfprovider.ProviderBinance]["FOO"].Price)
+	assert.Contains(t, pairs[pfprovider.ProviderBinance], pftypes.CurrencyPair{Base: "FOO", Quote: "ETH"})
+
+	pairs = normalizeCrossQuotes(zerolog.Nop(), candles, prices, pairs, quotes)
+
+	// FOO/ETH converted at the ETH price in USD
+	assert.Equal(t, sdk.MustNewDecFromStr("2"), prices[pfprovider.ProviderBinance]["FOO"].Price)
+	assert.Equal(t, sdk.MustNewDecFromStr("4"), candles[pfprovider.ProviderBinance]["FOO"][0].Price)
+
+	// no BTC price: filtered out
+	assert.NotContains(t, prices[pfprovider.ProviderKraken], "BAR")
+
+	assert.Equal(t, map[pfprovider.Name][]pftypes.CurrencyPair{
+		pfprovider.ProviderBinance: {
+			{Base: "USDT", Quote: "USD"},
+			{Base: "ETH", Quote: "USD"},
+			{Base: "FOO", Quote: "USD"},
+		},
+	}, pairs)
+}
+
+func TestSubscribeSymbolsCrossPairs(t *testing.T) {
+	provider := &fakeProvider{}
+	binance := &Provider{
+		Provider: provider,
+		availablePairs: map[string]struct{}{
+			"USDTUSD": {}, "ETHUSDT": {}, "FOOETH": {}, "ATOMUSDT": {}, "ATOMETH": {},
+		},
+		subscribedPairs: map[string]pftypes.CurrencyPair{},
+	}
+
+	o := &Oracle{
+		logger:                  zerolog.Nop(),
+		providers:               map[pfprovider.Name]*Provider{pfprovider.ProviderBinance: binance},
+		subscribedBaseSymbols:   map[string]struct{}{},
+		providerSubscribedPairs: map[pfprovider.Name][]pftypes.CurrencyPair{},
+	}
+
+	// ATOM has a stablecoin pair, FOO only an ETH one
+	require.NoError(t, o.SubscribeSymbols("ATOM", "FOO"))
+
+	atomUSDT := pftypes.CurrencyPair{Base: "ATOM", Quote: "USDT"}
+	fooETH := pftypes.CurrencyPair{Base: "FOO", Quote: "ETH"}
+	ethUSDT := pftypes.CurrencyPair{Base: "ETH", Quote: "USDT"}
+	assert.ElementsMatch(t, []pftypes.CurrencyPair{atomUSDT, fooETH, ethUSDT}, provider.pairs)
+
+	// the ETH pairs converting the cross rate are kept while FOO is subscribed
+	require.NoError(t, o.SubscribeSymbols("ETH"))
+	o.UnsubscribeSymbols("ETH")
+	assert.ElementsMatch(
+		t,
+		[]pftypes.CurrencyPair{atomUSDT, fooETH, ethUSDT},
+		o.providerSubscribedPairs[pfprovider.ProviderBinance],
+	)
+
+	o.UnsubscribeSymbols("FOO")
+	assert.ElementsMatch(t, []pftypes.CurrencyPair{atomUSDT}, o.providerSubscribedPairs[pfprovider.ProviderBinance])
+}
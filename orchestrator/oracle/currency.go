@@ -9,11 +9,25 @@ import (
 const (
 	symbolUSD  = "USD"
 	symbolUSDT = "USDT"
+	symbolUSDC = "USDC"
 	symbolDAI  = "DAI"
+	symbolBTC  = "BTC"
 )
 
 var (
-	quoteStablecoins = []string{symbolUSD, symbolUSDT, symbolDAI}
+	quoteStablecoins = []string{symbolUSD, symbolUSDT, symbolUSDC, symbolDAI}
+
+	// stablecoinPairs are always subscribed, to convert the prices quoted in
+	// stablecoins into USD.
+	stablecoinPairs = []umeepftypes.CurrencyPair{
+		{Base: symbolUSDT, Quote: symbolUSD},
+		{Base: symbolUSDC, Quote: symbolUSD},
+		{Base: symbolDAI, Quote: symbolUSD},
+	}
+
+	// crossQuotes price the symbols without any stablecoin pair, at their own
+	// USD price.
+	crossQuotes = []string{SymbolETH, symbolBTC}
 )
 
 // GetStablecoinsCurrencyPair return the currency pair of that symbol quoted by some
@@ -30,3 +44,29 @@ func GetStablecoinsCurrencyPair(baseSymbol string) []umeepftypes.CurrencyPair {
 
 	return currencyPairs
 }
+
+// getCrossCurrencyPairs returns the currency pairs of that symbol quoted by the
+// cross quotes, ETH and BTC.
+func getCrossCurrencyPairs(baseSymbol string) []umeepftypes.CurrencyPair {
+	currencyPairs := make([]umeepftypes.CurrencyPair, len(crossQuotes))
+
+	for i, quote := range crossQuotes {
+		currencyPairs[i] = umeepftypes.CurrencyPair{
+			Base:  strings.ToUpper(baseSymbol),
+			Quote: quote,
+		}
+	}
+
+	return currencyPairs
+}
+
+// isCrossQuote returns true if the symbol is one of the cross quotes.
+func isCrossQuote(symbol string) bool {
+	for _, quote := range crossQuotes {
+		if strings.EqualFold(quote, symbol) {
+			return true
+		}
+	}
+
+	return false
+}
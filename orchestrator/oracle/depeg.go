@@ -0,0 +1,216 @@
+package oracle
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+// DefaultStablecoinDepegThreshold is the default maximum deviation from 1 USD
+// of the stablecoins prices are quoted in.
+var DefaultStablecoinDepegThreshold = sdk.NewDecWithPrec(2, 2)
+
+// OptionStablecoinDepegThreshold sets the maximum deviation from 1 USD (e.g.
+// 0.02 for 2%) of a quote stablecoin, above which the provider prices quoted
+// in it are filtered out. Zero disables the filter; the prices are converted
+// into USD at the stablecoin's own price regardless.
+func OptionStablecoinDepegThreshold(threshold sdk.Dec) Option {
+	return func(o *Oracle) { o.depegThreshold = threshold }
+}
+
+// normalizeStablecoinQuotes converts in place the provider prices and candles
+// quoted in a stablecoin into USD, at the stablecoin's own USD price, and
+// filters out the ones whose stablecoin is missing a price or depegged beyond
+// the threshold. quotes holds the quote of each provider price, by provider
+// and base; the pairs are used for the bases missing from it, e.g. with stored
+// candles only. The returned pairs are all quoted in USD, except the ones
+// quoted in a cross quote, left to normalizeCrossQuotes.
+func normalizeStablecoinQuotes(
+	logger zerolog.Logger,
+	candles pfprovider.AggregatedProviderCandles,
+	prices pfprovider.AggregatedProviderPrices,
+	providerPairs map[pfprovider.Name][]pftypes.CurrencyPair,
+	quotes map[pfprovider.Name]map[string]string,
+	depegThreshold sdk.Dec,
+) map[pfprovider.Name][]pftypes.CurrencyPair {
+	quoteOf := quoteLookup(providerPairs, quotes)
+	rates := usdRates(candles, prices, quoteOf, quoteStablecoins)
+
+	depegged := map[string]bool{}
+	for stablecoin, rate := range rates {
+		if depegThreshold.IsNil() || !depegThreshold.IsPositive() {
+			continue
+		}
+
+		if rate.Sub(sdk.OneDec()).Abs().GT(depegThreshold) {
+			depegged[stablecoin] = true
+
+			logger.Warn().
+				Str("stablecoin", stablecoin).
+				Str("price", rate.String()).
+				Str("threshold", depegThreshold.String()).
+				Msg("quote stablecoin depegged; filtering out the prices quoted in it")
+		}
+	}
+
+	dropped := map[pfprovider.Name]map[string]struct{}{}
+	drop := func(providerName pfprovider.Name, base string) {
+		delete(prices[providerName], base)
+		delete(candles[providerName], base)
+
+		if _, ok := dropped[providerName]; !ok {
+			dropped[providerName] = map[string]struct{}{}
+		}
+		dropped[providerName][base] = struct{}{}
+	}
+
+	for providerName, pairs := range providerPairs {
+		for _, pair := range pairs {
+			base := pair.Base
+			if _, ok := dropped[providerName][base]; ok {
+				continue
+			}
+
+			// the cross quotes are converted once the stablecoins are
+			quote := quoteOf(providerName, base)
+			if quote == symbolUSD || isCrossQuote(quote) {
+				continue
+			}
+
+			rate, ok := rates[quote]
+			if !ok {
+				logger.Debug().
+					Str("provider_name", string(providerName)).
+					Str("base", base).
+					Str("quote", quote).
+					Msg("no USD price of the quote stablecoin; filtering out the provider price")
+				drop(providerName, base)
+				continue
+			}
+
+			if depegged[quote] {
+				drop(providerName, base)
+				continue
+			}
+
+			if ticker, ok := prices[providerName][base]; ok {
+				ticker.Price = ticker.Price.Mul(rate)
+				prices[providerName][base] = ticker
+			}
+
+			for i := range candles[providerName][base] {
+				candles[providerName][base][i].Price = candles[providerName][base][i].Price.Mul(rate)
+			}
+
+			// the base is now quoted in USD, whichever stablecoin it was priced in
+			if quotes[providerName] == nil {
+				quotes[providerName] = map[string]string{}
+			}
+			quotes[providerName][base] = symbolUSD
+		}
+	}
+
+	usdPairs := make(map[pfprovider.Name][]pftypes.CurrencyPair, len(providerPairs))
+	for providerName, pairs := range providerPairs {
+		seen := map[string]struct{}{}
+		for _, pair := range pairs {
+			if _, ok := dropped[providerName][pair.Base]; ok {
+				continue
+			}
+			if _, ok := seen[pair.Base]; ok {
+				continue
+			}
+			seen[pair.Base] = struct{}{}
+
+			quote := symbolUSD
+			if crossQuote := quoteOf(providerName, pair.Base); isCrossQuote(crossQuote) {
+				quote = crossQuote
+			}
+
+			usdPairs[providerName] = append(usdPairs[providerName], pftypes.CurrencyPair{Base: pair.Base, Quote: quote})
+		}
+	}
+
+	return usdPairs
+}
+
+// quoteLookup returns the quote of each provider price, by provider and base,
+// from quotes, falling back to the last pair of the base.
+func quoteLookup(
+	providerPairs map[pfprovider.Name][]pftypes.CurrencyPair,
+	quotes map[pfprovider.Name]map[string]string,
+) func(pfprovider.Name, string) string {
+	return func(providerName pfprovider.Name, base string) string {
+		if quote, ok := quotes[providerName][base]; ok {
+			return quote
+		}
+
+		quote := symbolUSD
+		for _, pair := range providerPairs[providerName] {
+			if pair.Base == base {
+				quote = pair.Quote
+			}
+		}
+
+		return quote
+	}
+}
+
+// usdRates returns the USD price of each of the given quotes, as the median of
+// the provider prices quoted in USD, falling back to their latest candle.
+func usdRates(
+	candles pfprovider.AggregatedProviderCandles,
+	prices pfprovider.AggregatedProviderPrices,
+	quoteOf func(pfprovider.Name, string) string,
+	quotes []string,
+) map[string]sdk.Dec {
+	usdPrices := pfprovider.AggregatedProviderPrices{}
+
+	add := func(providerName pfprovider.Name, base string, price sdk.Dec) {
+		if _, ok := usdPrices[providerName]; !ok {
+			usdPrices[providerName] = map[string]pftypes.TickerPrice{}
+		}
+		usdPrices[providerName][base] = pftypes.TickerPrice{Price: price}
+	}
+
+	for _, quote := range quotes {
+		if quote == symbolUSD {
+			continue
+		}
+
+		for providerName, tickers := range prices {
+			if quoteOf(providerName, quote) != symbolUSD {
+				continue
+			}
+
+			if ticker, ok := tickers[quote]; ok && !ticker.Price.IsNil() && ticker.Price.IsPositive() {
+				add(providerName, quote, ticker.Price)
+			}
+		}
+
+		for providerName, bases := range candles {
+			if _, ok := usdPrices[providerName][quote]; ok || quoteOf(providerName, quote) != symbolUSD {
+				continue
+			}
+
+			if latest := latestCandle(bases[quote]); latest != nil && !latest.Price.IsNil() && latest.Price.IsPositive() {
+				add(providerName, quote, latest.Price)
+			}
+		}
+	}
+
+	return ComputeMedian(usdPrices)
+}
+
+func latestCandle(candles []pftypes.CandlePrice) *pftypes.CandlePrice {
+	var latest *pftypes.CandlePrice
+	for i := range candles {
+		if latest == nil || candles[i].TimeStamp > latest.TimeStamp {
+			latest = &candles[i]
+		}
+	}
+
+	return latest
+}
@@ -0,0 +1,108 @@
+package oracle
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+func TestNormalizeStablecoinQuotes(t *testing.T) {
+	ticker := func(price string) pftypes.TickerPrice {
+		return pftypes.TickerPrice{Price: sdk.MustNewDecFromStr(price), Volume: sdk.NewDec(100)}
+	}
+
+	prices := pfprovider.AggregatedProviderPrices{
+		pfprovider.ProviderBinance: {
+			"USDT": ticker("0.99"),
+			"ETH":  ticker("2000"),
+		},
+		pfprovider.ProviderKraken: {
+			"USDT": ticker("0.97"),
+			"USDC": ticker("0.90"),
+			"ETH":  ticker("1900"),
+		},
+		pfprovider.ProviderOkx: {
+			"USDT": ticker("0.98"),
+			"ATOM": ticker("10"),
+		},
+	}
+	candles := pfprovider.AggregatedProviderCandles{
+		pfprovider.ProviderBinance: {
+			"ETH": {{Price: sdk.NewDec(2000), Volume: sdk.NewDec(1), TimeStamp: 1}},
+		},
+	}
+	providerPairs := map[pfprovider.Name][]pftypes.CurrencyPair{
+		pfprovider.ProviderBinance: {
+			{Base: "USDT", Quote: "USD"},
+			{Base: "ETH", Quote: "USD"},
+			{Base: "ETH", Quote: "USDT"},
+		},
+		pfprovider.ProviderKraken: {
+			{Base: "USDT", Quote: "USD"},
+			{Base: "USDC", Quote: "USD"},
+			{Base: "ETH", Quote: "USDC"},
+		},
+		pfprovider.ProviderOkx: {
+			{Base: "USDT", Quote: "USD"},
+			{Base: "ATOM", Quote: "DAI"},
+		},
+	}
+	quotes := map[pfprovider.Name]map[string]string{
+		pfprovider.ProviderBinance: {"USDT": "USD", "ETH": "USDT"},
+		pfprovider.ProviderKraken:  {"USDT": "USD", "USDC": "USD", "ETH": "USDC"},
+		pfprovider.ProviderOkx:     {"USDT": "USD", "ATOM": "DAI"},
+	}
+
+	pairs := normalizeStablecoinQuotes(
+		zerolog.Nop(),
+		candles,
+		prices,
+		providerPairs,
+		quotes,
+		DefaultStablecoinDepegThreshold,
+	)
+
+	// ETH/USDT converted at the median USDT price
+	assert.Equal(t, sdk.MustNewDecFromStr("1960"), prices[pfprovider.ProviderBinance]["ETH"].Price)
+	assert.Equal(t, sdk.MustNewDecFromStr("1960"), candles[pfprovider.ProviderBinance]["ETH"][0].Price)
+
+	// USDC depegged and no DAI price: filtered out
+	assert.NotContains(t, prices[pfprovider.ProviderKraken], "ETH")
+	assert.NotContains(t, prices[pfprovider.ProviderOkx], "ATOM")
+	assert.Equal(t, sdk.MustNewDecFromStr("0.90"), prices[pfprovider.ProviderKraken]["USDC"].Price)
+
+	assert.Equal(t, map[pfprovider.Name][]pftypes.CurrencyPair{
+		pfprovider.ProviderBinance: {{Base: "USDT", Quote: "USD"}, {Base: "ETH", Quote: "USD"}},
+		pfprovider.ProviderKraken:  {{Base: "USDT", Quote: "USD"}, {Base: "USDC", Quote: "USD"}},
+		pfprovider.ProviderOkx:     {{Base: "USDT", Quote: "USD"}},
+	}, pairs)
+}
+
+func TestNormalizeStablecoinQuotesWithoutFilter(t *testing.T) {
+	prices := pfprovider.AggregatedProviderPrices{
+		pfprovider.ProviderKraken: {
+			"USDC": {Price: sdk.MustNewDecFromStr("0.90"), Volume: sdk.NewDec(1)},
+			"ETH":  {Price: sdk.NewDec(2000), Volume: sdk.NewDec(1)},
+		},
+	}
+	providerPairs := map[pfprovider.Name][]pftypes.CurrencyPair{
+		pfprovider.ProviderKraken: {{Base: "USDC", Quote: "USD"}, {Base: "ETH", Quote: "USDC"}},
+	}
+
+	// the quotes default to the last pair of each base
+	normalizeStablecoinQuotes(
+		zerolog.Nop(),
+		pfprovider.AggregatedProviderCandles{},
+		prices,
+		providerPairs,
+		map[pfprovider.Name]map[string]string{},
+		sdk.ZeroDec(),
+	)
+
+	assert.Equal(t, sdk.NewDec(1800), prices[pfprovider.ProviderKraken]["ETH"].Price)
+}
@@ -0,0 +1,91 @@
+package oracle
+
+import (
+	"fmt"
+	"strings"
+
+	sdkmath "cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	umeeparams "github.com/umee-network/umee/v3/app/params"
+)
+
+// defaultDeviationThresholds are the thresholds, in standard deviations from
+// the mean, used for the bases without a configured one. The price feeder uses
+// 1𝜎 for the others.
+var defaultDeviationThresholds = map[string]sdk.Dec{
+	SymbolETH:            sdk.NewDecFromIntWithPrec(sdkmath.NewInt(15), 1),
+	umeeparams.BondDenom: sdk.NewDecFromIntWithPrec(sdkmath.NewInt(15), 1),
+}
+
+// OptionDeviationThreshold sets the threshold, in standard deviations from the
+// mean, above which a provider price is filtered out for the bases without a
+// threshold of their own. It replaces the default thresholds.
+func OptionDeviationThreshold(threshold sdk.Dec) Option {
+	return func(o *Oracle) { o.deviationThreshold = threshold }
+}
+
+// OptionDeviationThresholds sets the thresholds, in standard deviations from
+// the mean, above which a provider price is filtered out per base symbol (e.g.
+// tighter for stablecoins and looser for illiquid tokens). Symbols are
+// case-insensitive.
+func OptionDeviationThresholds(thresholds map[string]sdk.Dec) Option {
+	return func(o *Oracle) {
+		o.deviationThresholds = make(map[string]sdk.Dec, len(thresholds))
+		for symbol, threshold := range thresholds {
+			o.deviationThresholds[strings.ToUpper(symbol)] = threshold
+		}
+	}
+}
+
+// ParseDeviationThresholds parses thresholds in the SYMBOL=THRESHOLD format
+// (e.g. USDC=0.5).
+func ParseDeviationThresholds(values []string) (map[string]sdk.Dec, error) {
+	thresholds := make(map[string]sdk.Dec, len(values))
+
+	for _, v := range values {
+		symbol, threshold, ok := strings.Cut(v, "=")
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+
+		if !ok || symbol == "" {
+			return nil, fmt.Errorf("invalid deviation threshold %q; expected SYMBOL=THRESHOLD (e.g. USDC=0.5)", v)
+		}
+
+		dec, err := sdk.NewDecFromStr(strings.TrimSpace(threshold))
+		if err != nil || !dec.IsPositive() {
+			return nil, fmt.Errorf("invalid deviation threshold %q for %s; expected a positive number", threshold, symbol)
+		}
+
+		if _, ok := thresholds[symbol]; ok {
+			return nil, fmt.Errorf("duplicate deviation threshold for %s", symbol)
+		}
+
+		thresholds[symbol] = dec
+	}
+
+	return thresholds, nil
+}
+
+// deviationThresholdsByBase returns the deviation thresholds of the given base
+// symbols, as expected by the price feeder filters.
+func (o *Oracle) deviationThresholdsByBase(bases []string) map[string]sdk.Dec {
+	thresholds := make(map[string]sdk.Dec, len(bases))
+
+	if o.deviationThreshold.IsNil() {
+		for base, threshold := range defaultDeviationThresholds {
+			thresholds[base] = threshold
+		}
+	} else {
+		for _, base := range bases {
+			thresholds[base] = o.deviationThreshold
+		}
+	}
+
+	for _, base := range bases {
+		if threshold, ok := o.deviationThresholds[strings.ToUpper(base)]; ok {
+			thresholds[base] = threshold
+		}
+	}
+
+	return thresholds
+}
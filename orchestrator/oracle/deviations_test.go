@@ -0,0 +1,56 @@
+package oracle
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	umeeparams "github.com/umee-network/umee/v3/app/params"
+)
+
+func TestParseDeviationThresholds(t *testing.T) {
+	thresholds, err := ParseDeviationThresholds([]string{"usdc=0.5", " ATOM = 3 "})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]sdk.Dec{
+		"USDC": sdk.MustNewDecFromStr("0.5"),
+		"ATOM": sdk.NewDec(3),
+	}, thresholds)
+
+	for _, invalid := range [][]string{
+		{"USDC"},
+		{"=0.5"},
+		{"USDC=abc"},
+		{"USDC=0"},
+		{"USDC=-1"},
+		{"USDC=0.5", "usdc=1"},
+	} {
+		_, err := ParseDeviationThresholds(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestDeviationThresholdsByBase(t *testing.T) {
+	bases := []string{SymbolETH, "USDC", "ATOM"}
+
+	// defaults
+	o := &Oracle{}
+	assert.Equal(t, defaultDeviationThresholds, o.deviationThresholdsByBase(bases))
+
+	// per asset thresholds on top of the defaults
+	OptionDeviationThresholds(map[string]sdk.Dec{"usdc": sdk.MustNewDecFromStr("0.5")})(o)
+	assert.Equal(t, map[string]sdk.Dec{
+		SymbolETH:            sdk.MustNewDecFromStr("1.5"),
+		umeeparams.BondDenom: sdk.MustNewDecFromStr("1.5"),
+		"USDC":               sdk.MustNewDecFromStr("0.5"),
+	}, o.deviationThresholdsByBase(bases))
+
+	// per asset thresholds on top of the global one
+	OptionDeviationThreshold(sdk.NewDec(2))(o)
+	assert.Equal(t, map[string]sdk.Dec{
+		SymbolETH: sdk.NewDec(2),
+		"USDC":    sdk.MustNewDecFromStr("0.5"),
+		"ATOM":    sdk.NewDec(2),
+	}, o.deviationThresholdsByBase(bases))
+}
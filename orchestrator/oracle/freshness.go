@@ -0,0 +1,73 @@
+package oracle
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+)
+
+// candleFreshnessGrace is the age of the last candle of a provider still
+// counted as fresh, as the providers stream candles of up to a minute.
+const candleFreshnessGrace = time.Minute
+
+// minFreshnessWeight bounds the weight of the stalest candles, so they still
+// contribute, if little, to the prices no other provider reports.
+var minFreshnessWeight = sdk.NewDecWithPrec(1, 2)
+
+// OptionCandleFreshness weights the candles of each provider in the TVWAP by
+// the age of its last candle of each base: past a minute, their volume is
+// halved every halfLife, so a provider lagging minutes behind contributes less
+// than one streaming in real time. Zero disables it.
+func OptionCandleFreshness(halfLife time.Duration) Option {
+	return func(o *Oracle) { o.candleFreshness = halfLife }
+}
+
+// freshnessWeight returns the weight of the candles whose last one is age old,
+// in [minFreshnessWeight, 1].
+func freshnessWeight(age, halfLife time.Duration) sdk.Dec {
+	if age <= candleFreshnessGrace {
+		return sdk.OneDec()
+	}
+
+	w := math.Pow(0.5, float64(age-candleFreshnessGrace)/float64(halfLife))
+	weight, err := sdk.NewDecFromStr(strconv.FormatFloat(w, 'f', sdk.Precision, 64))
+	if err != nil || weight.LT(minFreshnessWeight) {
+		return minFreshnessWeight
+	}
+
+	return weight
+}
+
+// applyCandleFreshness scales the candle volumes of each provider and base in
+// place by the freshness of their last candle at now.
+func applyCandleFreshness(halfLife time.Duration, candles pfprovider.AggregatedProviderCandles, now time.Time) {
+	if halfLife <= 0 {
+		return
+	}
+
+	for _, bases := range candles {
+		for _, baseCandles := range bases {
+			var last int64
+			for _, candle := range baseCandles {
+				if candle.TimeStamp > last {
+					last = candle.TimeStamp
+				}
+			}
+
+			weight := freshnessWeight(now.Sub(time.UnixMilli(last)), halfLife)
+			if weight.Equal(sdk.OneDec()) {
+				continue
+			}
+
+			for i := range baseCandles {
+				if !baseCandles[i].Volume.IsNil() {
+					baseCandles[i].Volume = baseCandles[i].Volume.Mul(weight)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package oracle
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+func TestFreshnessWeight(t *testing.T) {
+	assert.Equal(t, sdk.OneDec(), freshnessWeight(0, time.Minute))
+	assert.Equal(t, sdk.OneDec(), freshnessWeight(candleFreshnessGrace, time.Minute))
+	assert.Equal(t, sdk.MustNewDecFromStr("0.5"), freshnessWeight(2*time.Minute, time.Minute))
+	assert.Equal(t, sdk.MustNewDecFromStr("0.0625"), freshnessWeight(5*time.Minute, time.Minute))
+
+	// the stalest candles still contribute a little
+	assert.Equal(t, minFreshnessWeight, freshnessWeight(time.Hour, time.Minute))
+}
+
+func TestApplyCandleFreshness(t *testing.T) {
+	now := time.UnixMilli(time.Now().UnixMilli())
+	candles := pfprovider.AggregatedProviderCandles{
+		pfprovider.ProviderBinance: {"ATOM": {
+			{Price: sdk.NewDec(10), Volume: sdk.NewDec(100), TimeStamp: now.Add(-2 * time.Minute).UnixMilli()},
+			{Price: sdk.NewDec(10), Volume: sdk.NewDec(100), TimeStamp: now.Add(-10 * time.Second).UnixMilli()},
+		}},
+		pfprovider.ProviderMexc: {"ATOM": {
+			{Price: sdk.NewDec(20), Volume: sdk.NewDec(100), TimeStamp: now.Add(-6 * time.Minute).UnixMilli()},
+			{Price: sdk.NewDec(20), Volume: sdk.NewDec(100), TimeStamp: now.Add(-3 * time.Minute).UnixMilli()},
+		}},
+	}
+
+	applyCandleFreshness(time.Minute, candles, now)
+
+	// weighted by the age of the last candle of each provider
+	for _, candle := range candles[pfprovider.ProviderBinance]["ATOM"] {
+		assert.Equal(t, sdk.NewDec(100), candle.Volume)
+	}
+	for _, candle := range candles[pfprovider.ProviderMexc]["ATOM"] {
+		assert.Equal(t, sdk.NewDec(25), candle.Volume)
+	}
+
+	disabled := pfprovider.AggregatedProviderCandles{
+		pfprovider.ProviderMexc: {"ATOM": {{Price: sdk.NewDec(20), Volume: sdk.NewDec(100), TimeStamp: 1}}},
+	}
+	applyCandleFreshness(0, disabled, now)
+	assert.Equal(t, []pftypes.CandlePrice{
+		{Price: sdk.NewDec(20), Volume: sdk.NewDec(100), TimeStamp: 1},
+	}, disabled[pfprovider.ProviderMexc]["ATOM"])
+}
@@ -0,0 +1,50 @@
+package oracle
+
+import (
+	"time"
+)
+
+// OptionInterpolationGap holds the last price of a symbol for up to gap after
+// it was last computed, when none of its providers report it anymore, instead
+// of dropping it right away. Held prices are flagged as interpolated (see
+// IsInterpolated) until they're computed again, so short exchange hiccups don't
+// make the price flip in and out of existence. Zero disables it.
+func OptionInterpolationGap(gap time.Duration) Option {
+	return func(o *Oracle) { o.interpolationGap = gap }
+}
+
+// IsInterpolated returns whether the price of a symbol is held from its last
+// computation because its providers went silent.
+func (o *Oracle) IsInterpolated(baseSymbol string) bool {
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+
+	_, ok := o.interpolatedPrices[o.canonicalSymbol(baseSymbol)]
+	return ok
+}
+
+// keepInterpolatedPrice returns whether a price no longer reported by the
+// providers is held, within the interpolation gap of its last computation. The
+// price is flagged as interpolated while held.
+func (o *Oracle) keepInterpolatedPrice(base string, now time.Time) bool {
+	if o.interpolationGap <= 0 || now.Sub(o.priceTimes[base]) > o.interpolationGap {
+		if _, ok := o.interpolatedPrices[base]; ok {
+			o.logger.Warn().Str("base", base).Dur("gap", o.interpolationGap).
+				Msg("price not reported again within the interpolation gap; dropping it")
+		}
+
+		return false
+	}
+
+	if _, ok := o.interpolatedPrices[base]; !ok {
+		if o.interpolatedPrices == nil {
+			o.interpolatedPrices = map[string]struct{}{}
+		}
+		o.interpolatedPrices[base] = struct{}{}
+
+		o.logger.Info().Str("base", base).Time("computed_at", o.priceTimes[base]).
+			Msg("price no longer reported; holding the last one")
+	}
+
+	return true
+}
@@ -0,0 +1,60 @@
+package oracle
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolatedPrices(t *testing.T) {
+	now := time.Now()
+
+	o := &Oracle{
+		logger:           zerolog.Nop(),
+		computeWorkers:   1,
+		interpolationGap: time.Minute,
+		prices:           map[string]sdk.Dec{SymbolETH: sdk.NewDec(1500), "UMEE": sdk.MustNewDecFromStr("0.005")},
+		priceTimes:       map[string]time.Time{SymbolETH: now.Add(-30 * time.Second), "UMEE": now.Add(-2 * time.Minute)},
+	}
+
+	// no provider reports the prices anymore: ETH is held, UMEE went silent for
+	// longer than the gap
+	o.computePrices(nil, nil, nil, nil)
+
+	price, err := o.GetPrice(SymbolETH)
+	require.NoError(t, err)
+	assert.Equal(t, sdk.NewDec(1500), price)
+	assert.True(t, o.IsInterpolated(SymbolETH))
+	assert.NotContains(t, o.prices, "UMEE")
+	assert.False(t, o.IsInterpolated("UMEE"))
+
+	// recomputed prices are no longer interpolated
+	o.setComputedPrices([]string{SymbolETH}, map[string]sdk.Dec{SymbolETH: sdk.NewDec(1510)})
+	assert.False(t, o.IsInterpolated(SymbolETH))
+
+	// held prices are dropped once the gap elapses
+	o.computePrices(nil, nil, nil, nil)
+	assert.True(t, o.IsInterpolated(SymbolETH))
+
+	o.priceTimes[SymbolETH] = now.Add(-2 * time.Minute)
+	o.computePrices(nil, nil, nil, nil)
+	assert.NotContains(t, o.prices, SymbolETH)
+	assert.False(t, o.IsInterpolated(SymbolETH))
+}
+
+func TestInterpolatedPricesDisabled(t *testing.T) {
+	o := &Oracle{
+		logger:         zerolog.Nop(),
+		computeWorkers: 1,
+		prices:         map[string]sdk.Dec{SymbolETH: sdk.NewDec(1500)},
+		priceTimes:     map[string]time.Time{SymbolETH: time.Now()},
+	}
+
+	o.computePrices(nil, nil, nil, nil)
+	assert.Empty(t, o.prices)
+	assert.False(t, o.IsInterpolated(SymbolETH))
+}
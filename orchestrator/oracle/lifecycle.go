@@ -0,0 +1,45 @@
+package oracle
+
+import (
+	"time"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+
+	"github.com/umee-network/peggo/orchestrator/lifecycle"
+)
+
+const (
+	// stopTimeout is how long Stop waits for the oracle goroutines to exit.
+	stopTimeout = 10 * time.Second
+	// loopOwner owns the oracle loop goroutine.
+	loopOwner = "loop"
+	// pairsReloadOwner owns the goroutine reloading the available pairs.
+	pairsReloadOwner = "pairs_reload"
+)
+
+// OptionLifecycleMetrics exports the number of goroutines and provider
+// connections opened by the oracle with the given metrics.
+func OptionLifecycleMetrics(m *lifecycle.Metrics) Option {
+	return func(o *Oracle) { o.lifecycleMetrics = m }
+}
+
+// LifecycleCounts returns the number of goroutines and provider connections
+// still open, by owner.
+func (o *Oracle) LifecycleCounts() map[string]lifecycle.Counts {
+	return o.tracker().Counts()
+}
+
+// tracker returns the lifecycle tracker of the oracle, created on first use
+// when the oracle wasn't built by New.
+func (o *Oracle) tracker() *lifecycle.Tracker {
+	if o.lifecycle == nil {
+		o.lifecycle = lifecycle.NewTracker("oracle", o.lifecycleMetrics)
+	}
+
+	return o.lifecycle
+}
+
+// providerOwner returns the owner of the connections of a provider.
+func providerOwner(providerName pfprovider.Name) string {
+	return "provider_" + string(providerName)
+}
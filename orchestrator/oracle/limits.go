@@ -0,0 +1,283 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+)
+
+// providerLimitWait bounds how long a REST request of a provider, e.g. for its
+// available pairs, waits for its rate limit.
+const providerLimitWait = 10 * time.Second
+
+// errRateLimited is returned for the requests of a provider over its rate
+// limit.
+var errRateLimited = errors.New("provider rate limit exceeded")
+
+// ProviderCoinGecko names the CoinGecko API in the provider limits and keys.
+// It isn't an oracle provider, but shares their rate limits configuration.
+const ProviderCoinGecko pfprovider.Name = "coingecko"
+
+// ProviderLimit is the rate limit of a provider: Rate requests per second on
+// average, in bursts of up to Burst requests.
+type ProviderLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// Limiter is a token bucket enforcing a ProviderLimit. It's safe for
+// concurrent use, so everything querying a provider shares one. A nil *Limiter
+// doesn't limit anything.
+type Limiter struct {
+	mtx       sync.Mutex
+	rate      float64
+	burst     float64
+	tokens    float64
+	last      time.Time
+	now       func() time.Time
+	unlimited bool // set once the limit is removed, see Oracle.SetProviderLimits
+}
+
+// NewLimiter returns a limiter allowing a full burst right away.
+func NewLimiter(limit ProviderLimit) *Limiter {
+	burst := limitBurst(limit)
+
+	return &Limiter{
+		rate:   limit.Rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+		now:    time.Now,
+	}
+}
+
+// limitBurst returns the burst of a limit, at least 1.
+func limitBurst(limit ProviderLimit) float64 {
+	if limit.Burst < 1 {
+		return 1
+	}
+
+	return float64(limit.Burst)
+}
+
+// NewProviderLimiters returns a limiter per provider, to be shared by the
+// oracle (see OptionProviderLimiters) and the other clients of the providers,
+// e.g. the CoinGecko client.
+func NewProviderLimiters(limits map[pfprovider.Name]ProviderLimit) map[pfprovider.Name]*Limiter {
+	limiters := make(map[pfprovider.Name]*Limiter, len(limits))
+	for providerName, limit := range limits {
+		limiters[providerName] = NewLimiter(limit)
+	}
+
+	return limiters
+}
+
+// Allow takes a request from the limit if one is available right away.
+func (l *Limiter) Allow() bool {
+	if l == nil {
+		return true
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	return l.take() == 0
+}
+
+// Wait takes a request from the limit, waiting until one is available or ctx
+// is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	for {
+		l.mtx.Lock()
+		delay := l.take()
+		l.mtx.Unlock()
+
+		if delay == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// setLimit changes the limit in place, keeping the tokens available up to the
+// new burst. A nil limit removes it until the next one.
+func (l *Limiter) setLimit(limit *ProviderLimit) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if limit == nil {
+		l.unlimited = true
+		return
+	}
+
+	l.rate = limit.Rate
+	l.burst = limitBurst(*limit)
+	if l.unlimited {
+		// limited again, with a full burst like a new limiter
+		l.unlimited = false
+		l.tokens = l.burst
+		l.last = l.now()
+	}
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+// take refills the bucket and takes a token from it, or returns how long until
+// one is available. It must be called with the lock held.
+func (l *Limiter) take() time.Duration {
+	if l.unlimited {
+		return 0
+	}
+
+	now := l.now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+
+	delay := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	if delay < time.Millisecond {
+		delay = time.Millisecond
+	}
+
+	return delay
+}
+
+// OptionProviderLimiters rate limits the requests of the oracle to the
+// providers: the prices of a provider over its limit are the ones of its last
+// fetch until it's allowed again, and its REST requests wait for it.
+func OptionProviderLimiters(limiters map[pfprovider.Name]*Limiter) Option {
+	return func(o *Oracle) { o.providerLimiters = limiters }
+}
+
+// OptionProviderKeys sets the API keys of the providers taking one, e.g. the
+// Osmosis gRPC endpoint of ProviderOsmosisPool.
+func OptionProviderKeys(keys map[pfprovider.Name]string) Option {
+	return func(o *Oracle) { o.providerKeys = keys }
+}
+
+// SetProviderLimits changes the rate limits of a running oracle, e.g. on a
+// config reload. The limiters are updated in place, so the other clients
+// sharing them (see NewProviderLimiters) follow, and the providers without a
+// limit anymore aren't limited.
+func (o *Oracle) SetProviderLimits(limits map[pfprovider.Name]ProviderLimit) {
+	o.limitersMtx.Lock()
+	defer o.limitersMtx.Unlock()
+
+	limiters := make(map[pfprovider.Name]*Limiter, len(limits))
+	for providerName, limiter := range o.providerLimiters {
+		if limit, ok := limits[providerName]; ok {
+			limiter.setLimit(&limit)
+		} else {
+			limiter.setLimit(nil)
+		}
+
+		// kept even once unlimited, so its clients follow a later limit
+		limiters[providerName] = limiter
+	}
+
+	for providerName, limit := range limits {
+		if _, ok := limiters[providerName]; !ok {
+			limiters[providerName] = NewLimiter(limit)
+		}
+	}
+
+	o.providerLimiters = limiters
+}
+
+// providerLimiter returns the rate limiter of a provider, nil if it isn't
+// limited.
+func (o *Oracle) providerLimiter(providerName pfprovider.Name) *Limiter {
+	o.limitersMtx.RLock()
+	defer o.limitersMtx.RUnlock()
+
+	return o.providerLimiters[providerName]
+}
+
+// ParseProviderLimits parses rate limits in the PROVIDER=RATE[:BURST] format,
+// the rate being in requests per second (e.g. coingecko=0.5:5,binance=10). The
+// burst defaults to 1.
+func ParseProviderLimits(values []string) (map[pfprovider.Name]ProviderLimit, error) {
+	limits := make(map[pfprovider.Name]ProviderLimit, len(values))
+
+	for _, v := range values {
+		name, limit, ok := strings.Cut(v, "=")
+		name = strings.TrimSpace(name)
+		rate, burst, hasBurst := strings.Cut(strings.TrimSpace(limit), ":")
+
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid provider limit %q; expected PROVIDER=RATE[:BURST] (e.g. coingecko=0.5:5)", v)
+		}
+
+		l := ProviderLimit{Burst: 1}
+
+		var err error
+		l.Rate, err = strconv.ParseFloat(rate, 64)
+		if err != nil || l.Rate <= 0 {
+			return nil, fmt.Errorf("invalid provider rate %q for %s; expected a positive number", rate, name)
+		}
+
+		if hasBurst {
+			l.Burst, err = strconv.Atoi(burst)
+			if err != nil || l.Burst < 1 {
+				return nil, fmt.Errorf("invalid provider burst %q for %s; expected a positive integer", burst, name)
+			}
+		}
+
+		if _, ok := limits[pfprovider.Name(name)]; ok {
+			return nil, fmt.Errorf("duplicate provider limit for %s", name)
+		}
+
+		limits[pfprovider.Name(name)] = l
+	}
+
+	return limits, nil
+}
+
+// ParseProviderKeys parses API keys in the PROVIDER=KEY format (e.g.
+// coingecko=CG-xxx).
+func ParseProviderKeys(values []string) (map[pfprovider.Name]string, error) {
+	keys := make(map[pfprovider.Name]string, len(values))
+
+	for _, v := range values {
+		name, key, ok := strings.Cut(v, "=")
+		name, key = strings.TrimSpace(name), strings.TrimSpace(key)
+
+		// the value holds a secret, so it's left out of the errors
+		if !ok || name == "" || key == "" {
+			return nil, fmt.Errorf("invalid provider API key for %q; expected PROVIDER=KEY", name)
+		}
+
+		if _, ok := keys[pfprovider.Name(name)]; ok {
+			return nil, fmt.Errorf("duplicate provider API key for %s", name)
+		}
+
+		keys[pfprovider.Name(name)] = key
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,167 @@
+package oracle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+// countingProvider returns a fixed ETH price, counting the fetches.
+type countingProvider struct {
+	fakeProvider
+	fetches int
+}
+
+func (p *countingProvider) GetTickerPrices(...pftypes.CurrencyPair) (map[string]pftypes.TickerPrice, error) {
+	p.fetches++
+	return map[string]pftypes.TickerPrice{
+		"ETHUSDT": {Price: sdk.NewDec(1500), Volume: sdk.OneDec()},
+	}, nil
+}
+
+func TestLimiter(t *testing.T) {
+	now := time.Now()
+	limiter := NewLimiter(ProviderLimit{Rate: 1, Burst: 2})
+	limiter.now = func() time.Time { return now }
+	limiter.last = now
+
+	// a full burst is allowed right away
+	assert.True(t, limiter.Allow())
+	assert.True(t, limiter.Allow())
+	assert.False(t, limiter.Allow())
+
+	now = now.Add(500 * time.Millisecond)
+	assert.False(t, limiter.Allow())
+
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, limiter.Allow())
+
+	// the wait for the next request is bounded by ctx
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, limiter.Wait(ctx), context.Canceled)
+
+	var unlimited *Limiter
+	assert.True(t, unlimited.Allow())
+	assert.NoError(t, unlimited.Wait(ctx))
+}
+
+func TestLimiterWait(t *testing.T) {
+	limiter := NewLimiter(ProviderLimit{Rate: 20, Burst: 1})
+	require.True(t, limiter.Allow())
+
+	start := time.Now()
+	require.NoError(t, limiter.Wait(context.Background()))
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
+
+func TestProviderLimiterFetch(t *testing.T) {
+	counting := &countingProvider{}
+	provider := &Provider{Provider: counting}
+
+	o := &Oracle{
+		logger:         zerolog.Nop(),
+		computeWorkers: 1,
+		providers:      map[pfprovider.Name]*Provider{pfprovider.ProviderBinance: provider},
+		providerSubscribedPairs: map[pfprovider.Name][]pftypes.CurrencyPair{
+			pfprovider.ProviderBinance: {{Base: "ETH", Quote: "USDT"}},
+		},
+	}
+	OptionProviderLimiters(NewProviderLimiters(map[pfprovider.Name]ProviderLimit{
+		pfprovider.ProviderBinance: {Rate: 0.001, Burst: 1},
+	}))(o)
+
+	o.setPrices()
+	assert.Equal(t, 1, counting.fetches)
+	require.Contains(t, provider.lastPrices, "ETHUSDT")
+
+	// over its limit, the provider isn't fetched and its last prices are used
+	o.setPrices()
+	assert.Equal(t, 1, counting.fetches)
+	assert.Equal(t, sdk.NewDec(1500), provider.lastPrices["ETHUSDT"].Price)
+}
+
+func TestSetProviderLimits(t *testing.T) {
+	o := &Oracle{}
+	OptionProviderLimiters(NewProviderLimiters(map[pfprovider.Name]ProviderLimit{
+		ProviderCoinGecko:          {Rate: 0.001, Burst: 1},
+		pfprovider.ProviderBinance: {Rate: 0.001, Burst: 1},
+	}))(o)
+
+	// shared with another client, e.g. the CoinGecko one
+	coingecko := o.providerLimiter(ProviderCoinGecko)
+	require.True(t, coingecko.Allow())
+	require.False(t, coingecko.Allow())
+	require.True(t, o.providerLimiter(pfprovider.ProviderBinance).Allow())
+
+	o.SetProviderLimits(map[pfprovider.Name]ProviderLimit{
+		ProviderCoinGecko:         {Rate: 1000, Burst: 2},
+		pfprovider.ProviderKraken: {Rate: 0.001, Burst: 1},
+	})
+
+	// the limiter is updated in place
+	assert.Same(t, coingecko, o.providerLimiter(ProviderCoinGecko))
+	time.Sleep(5 * time.Millisecond)
+	assert.True(t, coingecko.Allow())
+	assert.True(t, coingecko.Allow())
+
+	// a removed limit doesn't limit anymore
+	binance := o.providerLimiter(pfprovider.ProviderBinance)
+	for i := 0; i < 10; i++ {
+		assert.True(t, binance.Allow())
+	}
+
+	// an added one does
+	kraken := o.providerLimiter(pfprovider.ProviderKraken)
+	require.NotNil(t, kraken)
+	assert.True(t, kraken.Allow())
+	assert.False(t, kraken.Allow())
+
+	// and a limit set again applies to the clients sharing the limiter
+	o.SetProviderLimits(map[pfprovider.Name]ProviderLimit{pfprovider.ProviderBinance: {Rate: 0.001, Burst: 1}})
+	assert.Same(t, binance, o.providerLimiter(pfprovider.ProviderBinance))
+	assert.True(t, binance.Allow())
+	assert.False(t, binance.Allow())
+}
+
+func TestParseProviderLimits(t *testing.T) {
+	limits, err := ParseProviderLimits([]string{"coingecko=0.5:5", " binance = 10 "})
+	require.NoError(t, err)
+	assert.Equal(t, map[pfprovider.Name]ProviderLimit{
+		ProviderCoinGecko:          {Rate: 0.5, Burst: 5},
+		pfprovider.ProviderBinance: {Rate: 10, Burst: 1},
+	}, limits)
+
+	for _, invalid := range [][]string{
+		{"coingecko"},
+		{"=1"},
+		{"coingecko=0"},
+		{"coingecko=fast"},
+		{"coingecko=1:0"},
+		{"coingecko=1", "coingecko=2"},
+	} {
+		_, err := ParseProviderLimits(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestParseProviderKeys(t *testing.T) {
+	keys, err := ParseProviderKeys([]string{"coingecko=CG-secret"})
+	require.NoError(t, err)
+	assert.Equal(t, map[pfprovider.Name]string{ProviderCoinGecko: "CG-secret"}, keys)
+
+	_, err = ParseProviderKeys([]string{"coingecko="})
+	assert.Error(t, err)
+
+	// the key isn't part of the error
+	_, err = ParseProviderKeys([]string{"coingecko=CG-secret", "coingecko=CG-secret"})
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "CG-secret")
+}
@@ -0,0 +1,208 @@
+package oracle
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+
+	"github.com/umee-network/peggo/orchestrator/breaker"
+)
+
+// Tick error types.
+const (
+	tickErrorProvider = "provider"
+	tickErrorCompute  = "compute"
+)
+
+// priceAgeCollector exports the age of each price when scraped.
+type priceAgeCollector struct {
+	oracle *Oracle
+	age    *prometheus.Desc
+}
+
+// OptionRegisterer exports the oracle metrics (e.g. the provider fetch latency,
+// the prices filtered out or the age of each price) with the given registerer.
+func OptionRegisterer(r prometheus.Registerer) Option {
+	return func(o *Oracle) { o.registerer = r }
+}
+
+func (o *Oracle) registerMetrics() error {
+	if o.registerer == nil {
+		return nil
+	}
+
+	o.providerPairsUnavailable = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "peggo",
+		Subsystem: "oracle",
+		Name:      "provider_pairs_unavailable",
+		Help:      "Whether an oracle provider has no available pair to subscribe to (1), so it contributes no price.",
+	}, []string{"provider"})
+
+	o.providerFetchDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "peggo",
+		Subsystem: "oracle",
+		Name:      "provider_fetch_duration_seconds",
+		Help:      "Time taken to get the ticker prices and candles of an oracle provider.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 4, 8),
+	}, []string{"provider"})
+
+	o.providerTickers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "peggo",
+		Subsystem: "oracle",
+		Name:      "provider_tickers",
+		Help:      "Number of ticker prices returned by an oracle provider on the last tick.",
+	}, []string{"provider"})
+
+	o.providerCandles = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "peggo",
+		Subsystem: "oracle",
+		Name:      "provider_candles",
+		Help:      "Number of candles returned by an oracle provider on the last tick.",
+	}, []string{"provider"})
+
+	o.providerBreakerState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "peggo",
+		Subsystem: "oracle",
+		Name:      "provider_breaker_state",
+		Help:      "Circuit breaker state of an oracle provider: closed (0), open (1), sidelining it, or half-open (2).",
+	}, []string{"provider"})
+
+	o.providerBreakerChanges = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "peggo",
+		Subsystem: "oracle",
+		Name:      "provider_breaker_transitions_total",
+		Help:      "Number of circuit breaker transitions of an oracle provider, by new state.",
+	}, []string{"provider", "state"})
+
+	o.pricesFiltered = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "peggo",
+		Subsystem: "oracle",
+		Name:      "prices_filtered_total",
+		Help:      "Number of provider prices filtered out for deviating from the others.",
+	}, []string{"provider", "symbol"})
+
+	o.tickErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "peggo",
+		Subsystem: "oracle",
+		Name:      "tick_errors_total",
+		Help:      "Number of oracle tick errors, by type (provider or compute).",
+	}, []string{"type"})
+
+	priceAge := &priceAgeCollector{
+		oracle: o,
+		age: prometheus.NewDesc(
+			"peggo_oracle_price_age_seconds",
+			"Time since the price of a symbol was last computed.",
+			[]string{"symbol"},
+			nil,
+		),
+	}
+
+	for _, c := range []prometheus.Collector{
+		o.providerPairsUnavailable,
+		o.providerFetchDuration,
+		o.providerTickers,
+		o.providerCandles,
+		o.providerBreakerState,
+		o.providerBreakerChanges,
+		o.pricesFiltered,
+		o.tickErrors,
+		priceAge,
+	} {
+		if err := o.registerer.Register(c); err != nil {
+			return errors.Wrap(err, "failed to register metric")
+		}
+	}
+
+	return nil
+}
+
+// Describe implements prometheus.Collector.
+func (c *priceAgeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.age
+}
+
+// Collect implements prometheus.Collector.
+func (c *priceAgeCollector) Collect(ch chan<- prometheus.Metric) {
+	c.oracle.mtx.RLock()
+	defer c.oracle.mtx.RUnlock()
+
+	now := time.Now()
+	for base, computedAt := range c.oracle.priceTimes {
+		ch <- prometheus.MustNewConstMetric(c.age, prometheus.GaugeValue, now.Sub(computedAt).Seconds(), base)
+	}
+}
+
+// observeFetch records the latency of a provider fetch and the number of ticker
+// prices and candles it returned.
+func (o *Oracle) observeFetch(
+	providerName pfprovider.Name,
+	took time.Duration,
+	tickers map[string]pftypes.TickerPrice,
+	candles map[string][]pftypes.CandlePrice,
+) {
+	if o.providerFetchDuration == nil {
+		return
+	}
+
+	candlesCount := 0
+	for _, c := range candles {
+		candlesCount += len(c)
+	}
+
+	o.providerFetchDuration.WithLabelValues(string(providerName)).Observe(took.Seconds())
+	o.providerTickers.WithLabelValues(string(providerName)).Set(float64(len(tickers)))
+	o.providerCandles.WithLabelValues(string(providerName)).Set(float64(candlesCount))
+}
+
+// observeFiltered counts the provider prices of the given bases filtered out
+// for deviating from the others.
+func (o *Oracle) observeFiltered(bases []string, filtered map[string][]pfprovider.Name) {
+	if o.pricesFiltered == nil {
+		return
+	}
+
+	for _, base := range bases {
+		for _, providerName := range filtered[base] {
+			o.pricesFiltered.WithLabelValues(string(providerName), base).Inc()
+		}
+	}
+}
+
+// observeBreakerState records a transition of the circuit breaker of a provider.
+func (o *Oracle) observeBreakerState(providerName pfprovider.Name, state breaker.State) {
+	if o.providerBreakerState == nil {
+		return
+	}
+
+	o.providerBreakerState.WithLabelValues(string(providerName)).Set(float64(state))
+	o.providerBreakerChanges.WithLabelValues(string(providerName), state.String()).Inc()
+}
+
+// deleteProviderMetrics drops the series of a removed provider, so it isn't
+// reported with its last values.
+func (o *Oracle) deleteProviderMetrics(providerName pfprovider.Name) {
+	if o.providerPairsUnavailable == nil {
+		return
+	}
+
+	labels := prometheus.Labels{"provider": string(providerName)}
+	o.providerPairsUnavailable.DeletePartialMatch(labels)
+	o.providerFetchDuration.DeletePartialMatch(labels)
+	o.providerTickers.DeletePartialMatch(labels)
+	o.providerCandles.DeletePartialMatch(labels)
+	o.providerBreakerState.DeletePartialMatch(labels)
+	o.providerBreakerChanges.DeletePartialMatch(labels)
+	o.pricesFiltered.DeletePartialMatch(labels)
+}
+
+func (o *Oracle) incTickErrors(errorType string) {
+	if o.tickErrors == nil {
+		return
+	}
+
+	o.tickErrors.WithLabelValues(errorType).Inc()
+}
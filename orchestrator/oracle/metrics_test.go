@@ -0,0 +1,70 @@
+package oracle
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+func TestOracleMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	o := &Oracle{logger: zerolog.Nop(), registerer: registry}
+	require.NoError(t, o.registerMetrics())
+
+	binance := string(pfprovider.ProviderBinance)
+
+	o.observeFetch(
+		pfprovider.ProviderBinance,
+		10*time.Millisecond,
+		map[string]pftypes.TickerPrice{"ETH": {}, "UMEE": {}},
+		map[string][]pftypes.CandlePrice{"ETH": {{}, {}, {}}},
+	)
+	assert.Equal(t, float64(2), testutil.ToFloat64(o.providerTickers.WithLabelValues(binance)))
+	assert.Equal(t, float64(3), testutil.ToFloat64(o.providerCandles.WithLabelValues(binance)))
+	assert.Equal(t, 1, testutil.CollectAndCount(o.providerFetchDuration))
+
+	o.observeFiltered([]string{"ETH"}, map[string][]pfprovider.Name{
+		"ETH":  {pfprovider.ProviderBinance},
+		"USDT": {pfprovider.ProviderBinance},
+	})
+	assert.Equal(t, float64(1), testutil.ToFloat64(o.pricesFiltered.WithLabelValues(binance, "ETH")))
+	assert.Equal(t, 1, testutil.CollectAndCount(o.pricesFiltered))
+
+	o.incTickErrors(tickErrorCompute)
+	assert.Equal(t, float64(1), testutil.ToFloat64(o.tickErrors.WithLabelValues(tickErrorCompute)))
+
+	o.priceTimes = map[string]time.Time{SymbolETH: time.Now().Add(-time.Minute), "UMEE": time.Now()}
+	assert.Equal(t, 2, testutil.CollectAndCount(registry, "peggo_oracle_price_age_seconds"))
+
+	// without a registerer, nothing is recorded
+	o = &Oracle{logger: zerolog.Nop()}
+	require.NoError(t, o.registerMetrics())
+	o.observeFetch(pfprovider.ProviderBinance, time.Second, nil, nil)
+	o.observeFiltered([]string{"ETH"}, nil)
+	o.incTickErrors(tickErrorProvider)
+}
+
+func TestFilteredOut(t *testing.T) {
+	ticker := pftypes.TickerPrice{Price: sdk.OneDec(), Volume: sdk.OneDec()}
+
+	before := pfprovider.AggregatedProviderPrices{
+		pfprovider.ProviderBinance: {"ETH": ticker, "ATOM": ticker},
+		pfprovider.ProviderKraken:  {"ETH": ticker},
+	}
+	after := pfprovider.AggregatedProviderPrices{
+		pfprovider.ProviderBinance: {"ETH": ticker},
+	}
+
+	assert.Equal(t, map[string][]pfprovider.Name{
+		"ATOM": {pfprovider.ProviderBinance},
+		"ETH":  {pfprovider.ProviderKraken},
+	}, filteredOut(before, after))
+}
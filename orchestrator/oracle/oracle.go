@@ -2,29 +2,41 @@ package oracle
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	sdkmath "cosmossdk.io/math"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
-	"golang.org/x/sync/errgroup"
 
 	pforacle "github.com/umee-network/umee/price-feeder/v2/oracle"
 	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
 	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
 	pfsync "github.com/umee-network/umee/price-feeder/v2/pkg/sync"
-	umeeparams "github.com/umee-network/umee/v3/app/params"
+
+	"github.com/umee-network/peggo/orchestrator/breaker"
+	"github.com/umee-network/peggo/orchestrator/lifecycle"
+	"github.com/umee-network/peggo/orchestrator/schedule"
+	"github.com/umee-network/peggo/orchestrator/store"
 )
 
 const (
-	// tickerTimeout is the minimum timeout between each oracle loop.
-	tickerTimeout = 1000 * time.Millisecond
+	// DefaultTickInterval is the default timeout between each oracle loop.
+	DefaultTickInterval = 1000 * time.Millisecond
+	// MinTickInterval is the minimum timeout between each oracle loop, so the
+	// providers aren't polled in a busy loop.
+	MinTickInterval = 100 * time.Millisecond
 	// availablePairsReload is the amount of time to reload the providers available pairs.
 	availablePairsReload = 24 * time.Hour
 	// SymbolETH refers to the ethereum symbol.
 	SymbolETH = "ETH"
+	// DecimalsETH is the number of decimals of ETH, as well as of the native gas
+	// asset of other EVM chains (e.g. BNB, MATIC or AVAX).
+	DecimalsETH = 18
 )
 
 // Oracle implements the core component responsible for fetching exchange rates
@@ -36,10 +48,84 @@ type Oracle struct {
 	mtx                   sync.RWMutex
 	providers             map[pfprovider.Name]*Provider // providerName => Provider
 	prices                map[string]sdk.Dec            // baseSymbol => price ex.: UMEE, ETH => sdk.Dec
+	priceTimes            map[string]time.Time          // baseSymbol => when the data of its price was updated
+	symbolUpdates         map[string]symbolUpdate       // baseSymbol => fingerprint of its provider data
+	priceMaxAge           time.Duration                 // age after which a price is stale, zero to disable it
+	priceHistory          map[string][]priceSample      // baseSymbol => prices computed within the retention, oldest first
+	historyRetention      time.Duration                 // how long computed prices are kept, zero to disable it
+	smoothingAlpha        sdk.Dec                       // weight of each new price in smoothed prices, nil for the default
+	restoredPrices        map[string]bool               // baseSymbol => whether stale, until recomputed
+	restoredUntil         time.Time                     // when the restored prices not reported again are dropped
+	interpolationGap      time.Duration                 // how long a price no longer reported is held, zero to disable it
+	interpolatedPrices    map[string]struct{}           // baseSymbol => nothing, while its last price is held
 	subscribedBaseSymbols map[string]struct{}           // baseSymbol => nothing
+	aliases               map[string]string             // alias => canonical baseSymbol ex.: WETH => ETH
+	deviationThreshold    sdk.Dec                       // fallback deviation threshold, nil for the defaults
+	deviationThresholds   map[string]sdk.Dec            // baseSymbol => deviation threshold ex.: USDC => 0.5
+	aggregation           string                        // strategy aggregating the provider prices ex.: tvwap
+	providerWeights       map[pfprovider.Name]sdk.Dec   // providerName => trust weight scaling its volume
+	candleFreshness       time.Duration                 // half-life of the weights of stale candles, zero to disable
+	depegThreshold        sdk.Dec                       // maximum depeg of the quote stablecoins, zero to disable
+	priceMoveThreshold    sdk.Dec                       // relative move between two ticks raising an alert, nil to disable
+	priceAlertHooks       []PriceAlertHook              // called with every price alert
 	// this field could be calculated each time by looping providers.subscribedPairs
 	// but the time to process is not worth the amount of memory
 	providerSubscribedPairs map[pfprovider.Name][]pftypes.CurrencyPair // providerName => []CurrencyPair
+
+	store              *store.Store
+	candles            pfprovider.AggregatedProviderCandles // recent candles, merged by the oracle loop
+	candlesPersistedAt time.Time                            // guarded by candlesPersistMtx
+	candlesPersistMtx  sync.Mutex                           // serializes the candles writes, outside of mtx
+
+	candleBackfillers map[pfprovider.Name]candleBackfiller       // providerName => REST candles, nil if disabled
+	backfillPending   map[pfprovider.Name][]pftypes.CurrencyPair // pairs subscribed since the last backfill
+
+	newProvider    newProviderFn
+	ethCaller      ethereum.ContractCaller // queried by the on-chain providers
+	tickInterval   time.Duration
+	computeWorkers int           // maximum number of bases whose price is computed at the same time
+	fetchTimeout   time.Duration // how long a tick waits for each provider fetch, zero to wait for all
+	computing      chan struct{} // holds a value while the prices are computed
+
+	ready chan struct{} // closed after the first tick
+
+	pairsReloadSchedule schedule.Schedule // when the available pairs are reloaded
+	pairsReload         chan struct{}     // holds a value while an on-demand pairs reload is pending
+	reconcileSchedule   schedule.Schedule // when the subscriptions are reconciled
+
+	limitersMtx      sync.RWMutex
+	providerLimiters map[pfprovider.Name]*Limiter // providerName => rate limit of its requests
+	providerKeys     map[pfprovider.Name]string   // providerName => API key, for the providers taking one
+
+	breakerMaxFailures int // consecutive failed fetches sidelining a provider, zero to disable it
+	breakerMinBackoff  time.Duration
+	breakerMaxBackoff  time.Duration
+
+	lifecycle        *lifecycle.Tracker
+	lifecycleMetrics *lifecycle.Metrics
+
+	registerer               prometheus.Registerer
+	providerPairsUnavailable *prometheus.GaugeVec
+	providerFetchDuration    *prometheus.HistogramVec
+	providerTickers          *prometheus.GaugeVec
+	providerCandles          *prometheus.GaugeVec
+	providerBreakerState     *prometheus.GaugeVec
+	providerBreakerChanges   *prometheus.CounterVec
+	pricesFiltered           *prometheus.CounterVec
+	tickErrors               *prometheus.CounterVec
+}
+
+// OptionTickInterval sets the timeout between each oracle loop, e.g. longer
+// on low-resource hosts or shorter on testnets. Intervals below
+// MinTickInterval are raised to it.
+func OptionTickInterval(interval time.Duration) Option {
+	return func(o *Oracle) {
+		if interval < MinTickInterval {
+			interval = MinTickInterval
+		}
+
+		o.tickInterval = interval
+	}
 }
 
 // Provider wraps the umee provider interface.
@@ -47,65 +133,103 @@ type Provider struct {
 	pfprovider.Provider
 	availablePairs  map[string]struct{}             // Symbol => nothing
 	subscribedPairs map[string]pftypes.CurrencyPair // Symbol => currencyPair
+
+	conn          *lifecycle.Conn // closes the provider websocket
+	lastCandle    int64           // timestamp of the most recent candle, in unix milliseconds
+	lastUpdate    time.Time       // when lastCandle last advanced
+	reconnectedAt time.Time       // when the provider was reconnected; zero once its ticks resumed
+	pairsFailures int             // consecutive failures to get any available pair
+	pairsRetryAt  time.Time       // when to retry getting the available pairs
+	reconnects    uint64          // number of times the provider was reconnected
+	fetchErrors   atomic.Uint64   // number of ticks failing to get both its ticker prices and its candles
+	fetching      atomic.Bool     // set while a fetch is running, possibly past the fetch timeout
+
+	lastPrices  map[string]pftypes.TickerPrice   // prices of the last fetch, used while over its rate limit
+	lastCandles map[string][]pftypes.CandlePrice // candles of the last fetch, used while over its rate limit
+
+	breaker          *breaker.Breaker // sidelines the provider while it keeps failing, nil if disabled
+	reconnectPending atomic.Bool      // set when the breaker opens, until the provider is reconnected
 }
 
-func New(ctx context.Context, logger zerolog.Logger, providersName []pfprovider.Name) (*Oracle, error) {
-	providers := map[pfprovider.Name]*Provider{}
+func New(
+	ctx context.Context,
+	logger zerolog.Logger,
+	providersName []pfprovider.Name,
+	options ...Option,
+) (*Oracle, error) {
+	o := &Oracle{
+		logger:                  logger.With().Str("module", "oracle").Logger(),
+		closer:                  pfsync.NewCloser(),
+		providers:               map[pfprovider.Name]*Provider{},
+		subscribedBaseSymbols:   map[string]struct{}{},
+		providerSubscribedPairs: map[pfprovider.Name][]pftypes.CurrencyPair{},
+		candles:                 pfprovider.AggregatedProviderCandles{},
+		candleBackfillers:       defaultCandleBackfillers(),
+		newProvider:             newPriceFeederProvider,
+		tickInterval:            DefaultTickInterval,
+		computeWorkers:          DefaultComputeWorkers,
+		fetchTimeout:            DefaultFetchTimeout,
+		computing:               make(chan struct{}, 1),
+		aggregation:             AggregationTVWAP,
+		depegThreshold:          DefaultStablecoinDepegThreshold,
+		ready:                   make(chan struct{}),
+		pairsReloadSchedule:     schedule.Every(availablePairsReload),
+		pairsReload:             make(chan struct{}, 1),
+		reconcileSchedule:       schedule.Every(subscriptionsReconcileInterval),
+	}
+	for _, option := range options {
+		option(o)
+	}
+	o.lifecycle = lifecycle.NewTracker("oracle", o.lifecycleMetrics)
 
 	for _, providerName := range providersName {
-		provider, err := pforacle.NewProvider(
-			ctx,
-			providerName,
-			logger,
-			pfprovider.Endpoint{},
-			pftypes.CurrencyPair{},
-		)
+		providerCtx, cancel := context.WithCancel(ctx)
+
+		provider, err := o.newProvider(providerCtx, logger, providerName, pftypes.CurrencyPair{})
 		if err != nil {
+			cancel()
+			o.Stop()
 			return nil, err
 		}
 
-		providers[providerName] = &Provider{
+		o.providers[providerName] = &Provider{
 			Provider:        provider,
 			availablePairs:  map[string]struct{}{},
 			subscribedPairs: map[string]pftypes.CurrencyPair{},
+			conn:            o.lifecycle.Track(providerOwner(providerName), cancel),
+			lastUpdate:      time.Now(),
 		}
 	}
 
-	o := &Oracle{
-		logger:                  logger.With().Str("module", "oracle").Logger(),
-		closer:                  pfsync.NewCloser(),
-		providers:               providers,
-		subscribedBaseSymbols:   map[string]struct{}{},
-		providerSubscribedPairs: map[pfprovider.Name][]pftypes.CurrencyPair{},
+	if err := o.registerMetrics(); err != nil {
+		o.Stop()
+		return nil, err
 	}
+	for providerName, provider := range o.providers {
+		provider.breaker = o.newProviderBreaker(providerName, provider)
+	}
+	o.loadCandles()
+	o.loadPrices()
 	o.loadAvailablePairs()
 	o.mtx.Lock()
 	defer o.mtx.Unlock()
-	if err := o.subscribeProviders([]pftypes.CurrencyPair{
-		{Base: symbolUSDT, Quote: symbolUSD},
-		{Base: symbolDAI, Quote: symbolUSD},
-	}); err != nil {
+	if err := o.subscribeProviders(stablecoinPairs); err != nil {
+		o.Stop()
 		return nil, err
 	}
-	go o.start(ctx)
+	o.lifecycle.Go(loopOwner, func() { o.start(ctx) })
+	o.lifecycle.Go(pairsReloadOwner, func() { o.reloadPairs(ctx) })
 
 	return o, nil
 }
 
-// GetPrices returns the price for the provided base symbols.
+// GetPrices returns the price for the provided base symbols. It fails if any
+// of them is missing, with the PriceErrors of all the missing ones; see
+// GetPricesPartial to use the prices of the others anyway.
 func (o *Oracle) GetPrices(baseSymbols ...string) (map[string]sdk.Dec, error) {
-	o.mtx.RLock()
-	defer o.mtx.RUnlock()
-
-	// Creates a new array for the prices in the oracle.
-	prices := make(map[string]sdk.Dec, len(baseSymbols))
-
-	for _, baseSymbol := range baseSymbols {
-		price, ok := o.prices[baseSymbol]
-		if !ok {
-			return nil, fmt.Errorf("error getting price for %s", baseSymbol)
-		}
-		prices[baseSymbol] = price
+	prices, priceErrs := o.GetPricesPartial(baseSymbols...)
+	if priceErrs != nil {
+		return nil, priceErrs
 	}
 
 	return prices, nil
@@ -116,9 +240,9 @@ func (o *Oracle) GetPrice(baseSymbol string) (sdk.Dec, error) {
 	o.mtx.RLock()
 	defer o.mtx.RUnlock()
 
-	price, ok := o.prices[baseSymbol]
+	price, ok := o.prices[o.canonicalSymbol(baseSymbol)]
 	if !ok {
-		return sdk.Dec{}, fmt.Errorf("error getting price for %s", baseSymbol)
+		return sdk.Dec{}, fmt.Errorf("error getting price for %s: %w", baseSymbol, ErrMissingPrice)
 	}
 
 	return price, nil
@@ -131,13 +255,16 @@ func (o *Oracle) SubscribeSymbols(baseSymbols ...string) error {
 	defer o.mtx.Unlock()
 
 	for _, baseSymbol := range baseSymbols {
+		// aliases subscribe the pairs of their canonical symbol
+		baseSymbol = o.canonicalSymbol(baseSymbol)
+
 		_, ok := o.subscribedBaseSymbols[baseSymbol]
 		if ok {
 			// pair already subscribed
 			continue
 		}
 
-		currencyPairs := GetStablecoinsCurrencyPair(baseSymbol)
+		currencyPairs := o.symbolPairs(baseSymbol)
 		if err := o.subscribeProviders(currencyPairs); err != nil {
 			return err
 		}
@@ -154,110 +281,195 @@ func (o *Oracle) SubscribeSymbols(baseSymbols ...string) error {
 
 func (o *Oracle) subscribeProviders(currencyPairs []pftypes.CurrencyPair) error {
 	for providerName, provider := range o.providers {
-		var pairsToSubscribe []pftypes.CurrencyPair
+		subscribed, err := o.subscribeProvider(providerName, provider, currencyPairs)
+		if err != nil {
+			return err
+		}
 
-		for _, currencyPair := range currencyPairs {
-			symbol := currencyPair.String()
+		o.addSubscribedPairs(providerName, subscribed)
+	}
 
-			_, ok := provider.subscribedPairs[symbol]
-			if ok {
-				// currency pair already subscribed
-				continue
-			}
+	return nil
+}
 
-			_, availablePair := provider.availablePairs[symbol]
-			if !availablePair {
-				o.logger.Debug().Str("provider_name", string(providerName)).Str("symbol", symbol).Msg("symbol is not available")
-				continue
-			}
+// subscribeProvider subscribes a provider to the available currency pairs it
+// isn't subscribed to yet, and returns them. It only updates the provider, so a
+// provider not added to the oracle yet can be subscribed without the lock.
+func (o *Oracle) subscribeProvider(
+	providerName pfprovider.Name,
+	provider *Provider,
+	currencyPairs []pftypes.CurrencyPair,
+) ([]pftypes.CurrencyPair, error) {
+	var pairsToSubscribe []pftypes.CurrencyPair
 
-			pairsToSubscribe = append(pairsToSubscribe, currencyPair)
-		}
+	for _, currencyPair := range currencyPairs {
+		symbol := currencyPair.String()
 
-		if len(pairsToSubscribe) == 0 {
-			o.logger.Debug().Str("provider_name", string(providerName)).
-				Msgf("No pairs to subscribe, received pairs to try: %+v", currencyPairs)
+		_, ok := provider.subscribedPairs[symbol]
+		if ok {
+			// currency pair already subscribed
 			continue
 		}
 
-		if err := provider.SubscribeCurrencyPairs(pairsToSubscribe...); err != nil {
-			o.logger.Err(err).Str("provider_name", string(providerName)).Msg("subscribing to new currency pairs")
-			return err
+		_, availablePair := provider.availablePairs[symbol]
+		if !availablePair {
+			o.logger.Debug().Str("provider_name", string(providerName)).Str("symbol", symbol).Msg("symbol is not available")
+			continue
 		}
 
-		for _, pair := range pairsToSubscribe {
-			provider.subscribedPairs[pair.String()] = pair
-			o.providerSubscribedPairs[providerName] = append(o.providerSubscribedPairs[providerName], pair)
+		pairsToSubscribe = append(pairsToSubscribe, currencyPair)
+	}
 
-			o.logger.Debug().Str("provider_name", string(providerName)).
-				Str("pair_symbol", pair.String()).
-				Msg("Subscribed new pair")
-		}
+	if len(pairsToSubscribe) == 0 {
+		o.logger.Debug().Str("provider_name", string(providerName)).
+			Msgf("No pairs to subscribe, received pairs to try: %+v", currencyPairs)
+		return nil, nil
+	}
 
-		o.logger.Info().Str("provider_name", string(providerName)).
-			Int("currency_pairs_length", len(pairsToSubscribe)).
-			Msgf("Subscribed pairs %+v", pairsToSubscribe)
+	if err := provider.SubscribeCurrencyPairs(pairsToSubscribe...); err != nil {
+		o.logger.Err(err).Str("provider_name", string(providerName)).Msg("subscribing to new currency pairs")
+		return nil, err
 	}
 
-	return nil
+	for _, pair := range pairsToSubscribe {
+		provider.subscribedPairs[pair.String()] = pair
+
+		o.logger.Debug().Str("provider_name", string(providerName)).
+			Str("pair_symbol", pair.String()).
+			Msg("Subscribed new pair")
+	}
+
+	return pairsToSubscribe, nil
+}
+
+// addSubscribedPairs records the pairs a provider was subscribed to and queues
+// their candle backfill. The caller must hold the lock.
+func (o *Oracle) addSubscribedPairs(providerName pfprovider.Name, pairs []pftypes.CurrencyPair) {
+	if len(pairs) == 0 {
+		return
+	}
+
+	o.providerSubscribedPairs[providerName] = append(o.providerSubscribedPairs[providerName], pairs...)
+	o.queueCandleBackfill(providerName, pairs)
+
+	o.logger.Info().Str("provider_name", string(providerName)).
+		Int("currency_pairs_length", len(pairs)).
+		Msgf("Subscribed pairs %+v", pairs)
 }
 
-// Stop stops the oracle process and waits for it to gracefully exit.
+// Ready returns a channel closed once the oracle completed its first tick, so
+// its prices can be relied on; prices missing by then are actually missing from
+// the providers, not still being computed.
+func (o *Oracle) Ready() <-chan struct{} {
+	return o.ready
+}
+
+// Stop stops the oracle process, closes the provider websockets and waits for
+// it to gracefully exit. Goroutines still running after stopTimeout are logged
+// as leaked. The prices are then persisted, when a store is set.
 func (o *Oracle) Stop() {
 	o.closer.Close()
-	<-o.closer.Done()
+
+	if err := o.tracker().Stop(stopTimeout); err != nil {
+		o.logger.Error().Err(err).Msg("oracle didn't stop cleanly")
+	}
+
+	o.persistPrices()
 }
 
 // start starts the oracle process in a blocking fashion.
 func (o *Oracle) start(ctx context.Context) {
+	// A timer, as a time.After in the loop would be reset on every oracle tick.
+	reconcileTimer := newScheduleTimer(o.reconcileSchedule)
+	defer reconcileTimer.Stop()
+
+	// The first tick runs right away, so the loops waiting for the oracle to be
+	// ready start as soon as possible.
+	o.tick(ctx, true)
+	o.mtx.RLock()
+	o.logger.Info().Int("prices", len(o.prices)).Msg("oracle ready")
+	o.mtx.RUnlock()
+	close(o.ready)
+
 	for {
 		select {
 		case <-ctx.Done():
 			o.closer.Close()
+			return
 
-		case <-time.After(tickerTimeout):
-			if err := o.tick(); err != nil {
-				o.logger.Err(err).Msg("oracle tick failed")
-			}
+		case <-o.closer.Done():
+			return
 
-		case <-time.After(availablePairsReload):
-			o.loadAvailablePairs()
+		case <-time.After(o.tickInterval):
+			o.tick(ctx, false)
+
+		case <-reconcileTimer.C():
+			o.reconcileSubscriptions()
+			reconcileTimer.Reset()
 		}
 	}
 }
 
-// loadAvailablePairs loads all the available pairs from providers.
+// loadAvailablePairs loads all the available pairs from providers. The oracle
+// isn't locked while the providers are queried, so a slow provider API doesn't
+// hold back the prices.
 func (o *Oracle) loadAvailablePairs() {
-	o.mtx.Lock()
-	defer o.mtx.Unlock()
-
+	o.mtx.RLock()
+	providers := make(map[pfprovider.Name]*Provider, len(o.providers))
+	clients := make(map[pfprovider.Name]pfprovider.Provider, len(o.providers))
 	for providerName, provider := range o.providers {
-		availablePairs, err := provider.GetAvailablePairs()
-		if err != nil {
-			o.logger.Debug().Err(err).Str(
-				"provider_name",
-				string(providerName),
-			).Msg("Error getting available pairs for provider")
-			continue
-		}
-		if len(availablePairs) == 0 {
-			continue
+		providers[providerName] = provider
+		clients[providerName] = provider.Provider
+	}
+	o.mtx.RUnlock()
+
+	for providerName, client := range clients {
+		availablePairs, err := o.providerAvailablePairs(providerName, client)
+
+		o.mtx.Lock()
+		// the provider may have been removed, or removed and added again
+		if provider := providers[providerName]; o.providers[providerName] == provider {
+			o.setProviderPairs(providerName, provider, availablePairs, err)
 		}
-		provider.availablePairs = availablePairs
+		o.mtx.Unlock()
 	}
 }
 
-// GetComputedPrices gets the candle and ticker prices and computes it.
-// It returns candles' TVWAP if possible, if not possible (not available
-// or due to some staleness) it will use the most recent ticker prices
-// and the VWAP formula instead.
+// GetComputedPrices gets the candle and ticker prices and computes it with the
+// given aggregation strategy. With AggregationTVWAP, it returns candles' TVWAP
+// if possible, if not possible (not available or due to some staleness) it will
+// use the most recent ticker prices and the VWAP formula instead.
 func GetComputedPrices(
 	logger zerolog.Logger,
 	providerCandles pfprovider.AggregatedProviderCandles,
 	providerPrices pfprovider.AggregatedProviderPrices,
 	providerPairs map[pfprovider.Name][]pftypes.CurrencyPair,
 	deviations map[string]sdk.Dec,
+	aggregation string,
 ) (prices map[string]sdk.Dec, err error) {
+	prices, _, err = computeFilteredPrices(logger, providerCandles, providerPrices, providerPairs, deviations, aggregation)
+	return prices, err
+}
+
+// computeFilteredPrices computes the prices like GetComputedPrices, along with
+// the providers whose price of each base was filtered out for deviating from
+// the others.
+func computeFilteredPrices(
+	logger zerolog.Logger,
+	providerCandles pfprovider.AggregatedProviderCandles,
+	providerPrices pfprovider.AggregatedProviderPrices,
+	providerPairs map[pfprovider.Name][]pftypes.CurrencyPair,
+	deviations map[string]sdk.Dec,
+	aggregation string,
+) (map[string]sdk.Dec, map[string][]pfprovider.Name, error) {
+	switch aggregation {
+	case AggregationVWAP:
+		return computeTickerPrices(logger, providerPrices, providerPairs, deviations, pforacle.ComputeVWAP)
+
+	case AggregationMedian:
+		return computeTickerPrices(logger, providerPrices, providerPairs, deviations, ComputeMedian)
+	}
+
 	// convert any non-USD denominated candles into USD
 	convertedCandles, err := pforacle.ConvertCandlesToUSD(
 		logger,
@@ -266,7 +478,7 @@ func GetComputedPrices(
 		deviations,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// filter out any erroneous candles
@@ -276,123 +488,346 @@ func GetComputedPrices(
 		deviations,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// attempt to use candles for TVWAP calculations
 	tvwapPrices, err := pforacle.ComputeTVWAP(filteredCandles)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// If TVWAP candles are not available or were filtered out due to staleness,
 	// use most recent prices & VWAP instead.
 	if len(tvwapPrices) == 0 {
-		convertedTickers, err := pforacle.ConvertTickersToUSD(
-			logger,
-			providerPrices,
-			providerPairs,
-			deviations,
-		)
-		if err != nil {
-			return nil, err
-		}
+		return computeTickerPrices(logger, providerPrices, providerPairs, deviations, pforacle.ComputeVWAP)
+	}
 
-		filteredProviderPrices, err := pforacle.FilterTickerDeviations(
-			logger,
-			convertedTickers,
-			deviations,
-		)
-		if err != nil {
-			return nil, err
-		}
+	return tvwapPrices, filteredOut(convertedCandles, filteredCandles), nil
+}
+
+// computeTickerPrices converts the ticker prices into USD, filters out the
+// erroneous ones and aggregates the others with the given function. It returns
+// the providers filtered out of each base as well.
+func computeTickerPrices(
+	logger zerolog.Logger,
+	providerPrices pfprovider.AggregatedProviderPrices,
+	providerPairs map[pfprovider.Name][]pftypes.CurrencyPair,
+	deviations map[string]sdk.Dec,
+	aggregate func(pfprovider.AggregatedProviderPrices) map[string]sdk.Dec,
+) (map[string]sdk.Dec, map[string][]pfprovider.Name, error) {
+	convertedTickers, err := pforacle.ConvertTickersToUSD(
+		logger,
+		providerPrices,
+		providerPairs,
+		deviations,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filteredProviderPrices, err := pforacle.FilterTickerDeviations(
+		logger,
+		convertedTickers,
+		deviations,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return aggregate(filteredProviderPrices), filteredOut(convertedTickers, filteredProviderPrices), nil
+}
 
-		return pforacle.ComputeVWAP(filteredProviderPrices), nil
+// filteredOut returns the providers of each base present before filtering but
+// missing after it.
+func filteredOut[V any](before, after map[pfprovider.Name]map[string]V) map[string][]pfprovider.Name {
+	filtered := map[string][]pfprovider.Name{}
+	for providerName, bases := range before {
+		for base := range bases {
+			if _, ok := after[providerName][base]; !ok {
+				filtered[base] = append(filtered[base], providerName)
+			}
+		}
 	}
 
-	return tvwapPrices, nil
+	return filtered
 }
 
-// setPrices retrieves all the prices and candles from our set of providers as
-// determined in the config. If candles are available, uses TVWAP in order
-// to determine prices. If candles are not available, uses the most recent prices
-// with VWAP. Warns the the user of any missing prices, and filters out any faulty
-// providers which do not report prices or candles within 2𝜎 of the others.
+// setPrices retrieves all the prices and candles from the providers set in the
+// config, backfilling the candles of newly subscribed pairs over REST first (see
+// OptionCandleBackfill). It filters out the providers whose prices or candles
+// deviate from the others by more than the threshold (see
+// OptionDeviationThresholds) and warns the user of any missing prices. The
+// providers answering past the fetch timeout are left out (see
+// OptionFetchTimeout). The price of each base is computed by a pool of workers
+// (see OptionComputeWorkers) and set as soon as it's computed: with TVWAP if
+// candles are available, else with VWAP of the most recent prices (see
+// OptionAggregation for the other strategies), the volumes being scaled by the provider weights (see
+// OptionProviderWeights) and, for candles, their freshness (see
+// OptionCandleFreshness). Prices quoted in stablecoins are converted into USD at
+// the stablecoins' own prices unless they are depegged (see
+// OptionStablecoinDepegThreshold), and the symbols without any stablecoin pair
+// are then converted from their ETH or BTC quotes.
 // code originally from https://github.com/umee-network/umee/blob/2a69b56ae1c6098cb2d23ef8384f5acf28f76d35/price-feeder/oracle/oracle.go#L166-L167
-func (o *Oracle) setPrices() error {
-	g := new(errgroup.Group)
-	mtx := new(sync.Mutex)
+func (o *Oracle) setPrices() {
+	o.mtx.RLock()
+	providers := make(map[pfprovider.Name]*Provider, len(o.providers))
+	clients := make(map[pfprovider.Name]pfprovider.Provider, len(o.providers))
+	providerPairs := make(map[pfprovider.Name][]pftypes.CurrencyPair, len(o.providerSubscribedPairs))
+	for providerName, provider := range o.providers {
+		providers[providerName] = provider
+		clients[providerName] = provider.Provider
+	}
+	for providerName, pairs := range o.providerSubscribedPairs {
+		providerPairs[providerName] = append([]pftypes.CurrencyPair{}, pairs...)
+	}
+	o.mtx.RUnlock()
+
 	providerPrices := make(pfprovider.AggregatedProviderPrices)
 	providerCandles := make(pfprovider.AggregatedProviderCandles)
-
-	for providerName, provider := range o.providers {
+	providerQuotes := make(map[pfprovider.Name]map[string]string)
+	sidelined := make(map[pfprovider.Name]struct{})
+
+	// the providers answer on a buffered channel, so the ones answering past
+	// the fetch timeout don't block
+	results := make(chan providerFetch, len(clients))
+	pending := make(map[pfprovider.Name]struct{}, len(clients))
+	for providerName, client := range clients {
 		providerName := providerName
-		provider := provider
-		subscribedPrices := o.providerSubscribedPairs[providerName]
+		client := client
+		provider := providers[providerName]
+		subscribedPrices := providerPairs[providerName]
+
+		if !provider.fetching.CompareAndSwap(false, true) {
+			o.logger.Debug().
+				Str("provider", providerName.String()).
+				Msg("previous fetch still running; skipping the provider this tick")
+			continue
+		}
 
-		g.Go(func() error {
-			var (
-				tickerErr error
-				candleErr error
-			)
+		if !o.tracker().Go(fetchOwner, func() {
+			defer provider.fetching.Store(false)
+			results <- o.fetchProvider(providerName, provider, client, subscribedPrices)
+		}) {
+			provider.fetching.Store(false)
+			continue
+		}
 
-			prices, tickerErr := provider.GetTickerPrices(subscribedPrices...)
-			candles, candleErr := provider.GetCandlePrices(subscribedPrices...)
+		pending[providerName] = struct{}{}
+	}
 
-			if tickerErr != nil && candleErr != nil {
-				// only generates error if ticker and candle generate errors
-				o.logger.Debug().Msgf("provider: %s ticker error: %+v\ncandle error: %+v", providerName, tickerErr, candleErr)
-				return nil
+	var timeout <-chan time.Time
+	if o.fetchTimeout > 0 {
+		timer := time.NewTimer(o.fetchTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	for len(pending) > 0 {
+		select {
+		case fetch := <-results:
+			delete(pending, fetch.name)
+
+			if fetch.sidelined {
+				// the provider is left out of the aggregation until it recovers
+				sidelined[fetch.name] = struct{}{}
+				continue
+			}
+
+			if !fetch.ok {
+				continue
 			}
 
 			// flatten and collect prices based on the base currency per provider
 			//
 			// e.g.: {ProviderKraken: {"ATOM": <price, volume>, ...}}
-			mtx.Lock()
-			for _, pair := range subscribedPrices {
-				pforacle.SetProviderTickerPricesAndCandles(
-					providerName,
+			providerQuotes[fetch.name] = make(map[string]string)
+			for _, pair := range providerPairs[fetch.name] {
+				// a price quoted in a stablecoin is preferred to a cross rate
+				if _, ok := providerQuotes[fetch.name][pair.Base]; ok && isCrossQuote(pair.Quote) {
+					continue
+				}
+
+				if pforacle.SetProviderTickerPricesAndCandles(
+					fetch.name,
 					providerPrices,
 					providerCandles,
-					prices,
-					candles,
+					fetch.prices,
+					fetch.candles,
 					pair,
-				)
+				) {
+					providerQuotes[fetch.name][pair.Base] = pair.Quote
+				}
 			}
 
-			mtx.Unlock()
-			return nil
-		})
+		case <-timeout:
+			// the prices are computed without the slow providers, which are
+			// skipped until their fetch returns
+			for providerName := range pending {
+				o.logger.Warn().
+					Str("provider", providerName.String()).
+					Dur("timeout", o.fetchTimeout).
+					Msg("provider fetch timed out; computing the prices without it")
+			}
+			pending = nil
+		}
 	}
 
-	if err := g.Wait(); err != nil {
-		o.logger.Debug().Err(err).Msg("failed to get ticker prices from provider")
+	o.trackSymbolUpdates(providerPrices, providerCandles, time.Now())
+
+	var bases []string
+	for _, pairs := range providerPairs {
+		for _, pair := range pairs {
+			bases = append(bases, pair.Base)
+		}
 	}
 
-	deviationTreshold := sdk.NewDecFromIntWithPrec(sdkmath.NewInt(15), 1)
+	// backfilled candles are replaced by the fresh ones with the same timestamp
+	backfilled := o.backfillCandles()
+
+	o.mtx.Lock()
+	candles := o.withStoredCandles(mergeCandles(backfilled, providerCandles, 0))
+	deviations := o.deviationThresholdsByBase(bases)
+	weights := o.providerWeights
+	o.mtx.Unlock()
+
+	// the candles stored before a provider was sidelined aren't used either
+	for providerName := range sidelined {
+		delete(candles, providerName)
+	}
 
-	computedPrices, err := GetComputedPrices(
+	o.persistCandles()
+
+	applyProviderWeights(weights, providerPrices, candles)
+	applyCandleFreshness(o.candleFreshness, candles, time.Now())
+	providerPairs = normalizeStablecoinQuotes(
 		o.logger,
-		providerCandles,
+		candles,
 		providerPrices,
-		o.providerSubscribedPairs,
-		map[string]sdk.Dec{
-			SymbolETH:            deviationTreshold,
-			umeeparams.BondDenom: deviationTreshold,
-		}, // uses default deviation for other bases
+		providerPairs,
+		providerQuotes,
+		o.depegThreshold,
 	)
-	if err != nil {
-		return err
+	providerPairs = normalizeCrossQuotes(o.logger, candles, providerPrices, providerPairs, providerQuotes)
+
+	o.computePrices(candles, providerPrices, providerPairs, deviations)
+}
+
+// providerFetch is the outcome of fetching the prices and candles of a
+// provider.
+type providerFetch struct {
+	name      pfprovider.Name
+	prices    map[string]pftypes.TickerPrice
+	candles   map[string][]pftypes.CandlePrice
+	ok        bool // the prices or the candles are usable
+	sidelined bool // the breaker of the provider is open
+}
+
+// fetchProvider gets the ticker prices and candles of the subscribed pairs of a
+// provider through its breaker. Over its rate limit, the provider contributes
+// its last fetch instead.
+func (o *Oracle) fetchProvider(
+	providerName pfprovider.Name,
+	provider *Provider,
+	client pfprovider.Provider,
+	subscribedPrices []pftypes.CurrencyPair,
+) providerFetch {
+	var (
+		prices    map[string]pftypes.TickerPrice
+		candles   map[string][]pftypes.CandlePrice
+		tickerErr error
+		candleErr error
+	)
+
+	fetch := providerFetch{name: providerName}
+	limiter := o.providerLimiter(providerName)
+
+	var err error
+	if limiter.Allow() {
+		err = provider.breaker.Do(func() error {
+			start := time.Now()
+			prices, tickerErr = client.GetTickerPrices(subscribedPrices...)
+			candles, candleErr = client.GetCandlePrices(subscribedPrices...)
+			o.observeFetch(providerName, time.Since(start), prices, candles)
+
+			if limiter != nil {
+				if tickerErr == nil {
+					provider.lastPrices = prices
+				}
+				if candleErr == nil {
+					provider.lastCandles = candles
+				}
+			}
+
+			if tickerErr != nil && candleErr != nil {
+				return tickerErr
+			}
+
+			return nil
+		}, isProviderFailure)
+	} else {
+		// over its rate limit, the provider contributes its last fetch,
+		// unless it's sidelined
+		if provider.lastPrices == nil && provider.lastCandles == nil {
+			return fetch
+		}
+
+		prices, candles = provider.lastPrices, provider.lastCandles
+		if provider.breaker != nil && provider.breaker.State() == breaker.StateOpen {
+			err = breaker.ErrOpen
+		}
 	}
 
-	o.prices = computedPrices
-	return nil
+	if errors.Is(err, breaker.ErrOpen) {
+		fetch.sidelined = true
+		return fetch
+	}
+
+	if tickerErr != nil && candleErr != nil {
+		provider.fetchErrors.Add(1)
+
+		// only generates error if ticker and candle generate errors
+		o.logger.Debug().Msgf("provider: %s ticker error: %+v\ncandle error: %+v", providerName, tickerErr, candleErr)
+		o.incTickErrors(tickErrorProvider)
+		return fetch
+	}
+
+	if candleErr == nil {
+		o.mtx.Lock()
+		o.observeCandles(providerName, provider, candles)
+		o.mtx.Unlock()
+	}
+
+	fetch.prices, fetch.candles, fetch.ok = prices, candles, true
+	return fetch
 }
 
-func (o *Oracle) tick() error {
-	if err := o.setPrices(); err != nil {
-		return err
+// tick computes the prices, then retries getting the unavailable pairs and
+// reconnects the stale and sidelined providers. The prices are computed in the background,
+// and not at all while the previous computation is still running, so slow
+// providers or computations can't hold back the oracle loop; with wait, e.g.
+// on the first tick, they're computed before returning.
+func (o *Oracle) tick(ctx context.Context, wait bool) {
+	select {
+	case o.computing <- struct{}{}:
+		if wait {
+			o.runComputation()
+		} else if !o.tracker().Go(computeOwner, o.runComputation) {
+			<-o.computing
+		}
+
+	default:
+		o.logger.Debug().Msg("previous price computation still running; skipping it this tick")
 	}
 
-	return nil
+	o.retryAvailablePairs()
+	o.recoverProviders(ctx)
+	o.reconnectSidelinedProviders(ctx)
+}
+
+// runComputation sets the prices and releases the computation slot.
+func (o *Oracle) runComputation() {
+	defer func() { <-o.computing }()
+
+	o.setPrices()
 }
@@ -0,0 +1,372 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+
+	"github.com/umee-network/peggo/orchestrator/rawgrpc"
+)
+
+// ProviderOsmosisPool prices tokens from Osmosis pools, queried over gRPC.
+const ProviderOsmosisPool pfprovider.Name = "osmosispool"
+
+const (
+	osmosisSpotPriceMethod = "/osmosis.poolmanager.v1beta1.Query/SpotPrice"
+	osmosisTWAPMethod      = "/osmosis.twap.v1beta1.Query/ArithmeticTwapToNow"
+
+	// osmosisQueryTimeout bounds each Osmosis gRPC query.
+	osmosisQueryTimeout = 10 * time.Second
+	// maxOsmosisExponent bounds the decimals difference between pool assets.
+	maxOsmosisExponent = 18
+	// osmosisAPIKeyHeader is the gRPC metadata holding the API key of the Osmosis
+	// endpoint, if any.
+	osmosisAPIKeyHeader = "x-api-key"
+)
+
+// poolPriceVolume is the nominal volume of the prices of the DEX pool
+// providers, as pools don't expose their volume over their queries; use a
+// provider weight to scale it against the other providers.
+var poolPriceVolume = sdk.OneDec()
+
+var _ pfprovider.Provider = (*osmosisPoolProvider)(nil)
+
+type (
+	// OsmosisPool is an Osmosis pool pricing a pair.
+	OsmosisPool struct {
+		Pair       pftypes.CurrencyPair
+		PoolID     uint64
+		BaseDenom  string
+		QuoteDenom string
+		// Exponent is the base decimals minus the quote decimals, scaling the pool
+		// price of the base units into a price of whole tokens.
+		Exponent int
+	}
+
+	// osmosisPoolProvider implements the price-feeder provider interface with
+	// the spot price (tickers) and 5 minute arithmetic TWAP (candles) of Osmosis
+	// pools.
+	osmosisPoolProvider struct {
+		pools  map[string]OsmosisPool // pair symbol => pool
+		invoke rawgrpc.InvokeFn
+	}
+)
+
+// OptionOsmosisPools enables the ProviderOsmosisPool provider, pricing the
+// pairs of the given pools through the Osmosis gRPC endpoint (e.g.
+// localhost:9090).
+func OptionOsmosisPools(grpcAddr string, pools []OsmosisPool) Option {
+	return func(o *Oracle) {
+		next := o.newProvider
+
+		o.newProvider = func(
+			ctx context.Context,
+			logger zerolog.Logger,
+			providerName pfprovider.Name,
+			pairs ...pftypes.CurrencyPair,
+		) (pfprovider.Provider, error) {
+			if providerName != ProviderOsmosisPool {
+				return next(ctx, logger, providerName, pairs...)
+			}
+
+			return newOsmosisPoolProvider(ctx, grpcAddr, o.providerKeys[ProviderOsmosisPool], pools)
+		}
+	}
+}
+
+// ParseOsmosisPools parses pools in the BASE/QUOTE=POOL_ID:BASE_DENOM:QUOTE_DENOM
+// format, optionally followed by :EXPONENT (see OsmosisPool), e.g.
+// UMEE/USD=1110:ibc/67795E5:ibc/D189335:0. The quote must be a stablecoin the
+// oracle converts into USD.
+func ParseOsmosisPools(values []string) ([]OsmosisPool, error) {
+	pools := make([]OsmosisPool, 0, len(values))
+	seen := map[string]struct{}{}
+
+	for _, v := range values {
+		pair, pool, ok := strings.Cut(v, "=")
+		base, quote, okPair := strings.Cut(strings.TrimSpace(pair), "/")
+		parts := strings.Split(strings.TrimSpace(pool), ":")
+
+		if !ok || !okPair || base == "" || (len(parts) != 3 && len(parts) != 4) {
+			return nil, fmt.Errorf(
+				"invalid Osmosis pool %q; expected BASE/QUOTE=POOL_ID:BASE_DENOM:QUOTE_DENOM[:EXPONENT]", v,
+			)
+		}
+
+		p := OsmosisPool{
+			Pair: pftypes.CurrencyPair{
+				Base:  strings.ToUpper(base),
+				Quote: strings.ToUpper(quote),
+			},
+			BaseDenom:  parts[1],
+			QuoteDenom: parts[2],
+		}
+
+		if !isQuoteStablecoin(p.Pair.Quote) {
+			return nil, fmt.Errorf(
+				"invalid Osmosis pool %q; the quote must be one of: %s", v, strings.Join(quoteStablecoins, ", "),
+			)
+		}
+
+		poolID, err := strconv.ParseUint(parts[0], 10, 64)
+		if err != nil || poolID == 0 {
+			return nil, fmt.Errorf("invalid Osmosis pool %q; invalid pool ID %q", v, parts[0])
+		}
+		p.PoolID = poolID
+
+		if p.BaseDenom == "" || p.QuoteDenom == "" || p.BaseDenom == p.QuoteDenom {
+			return nil, fmt.Errorf("invalid Osmosis pool %q; expected two different denoms", v)
+		}
+
+		if len(parts) == 4 {
+			p.Exponent, err = strconv.Atoi(parts[3])
+			if err != nil || p.Exponent < -maxOsmosisExponent || p.Exponent > maxOsmosisExponent {
+				return nil, fmt.Errorf("invalid Osmosis pool %q; invalid exponent %q", v, parts[3])
+			}
+		}
+
+		if _, ok := seen[p.Pair.String()]; ok {
+			return nil, fmt.Errorf("duplicate Osmosis pool for %s", pair)
+		}
+		seen[p.Pair.String()] = struct{}{}
+
+		pools = append(pools, p)
+	}
+
+	return pools, nil
+}
+
+// isQuoteStablecoin returns true if prices quoted in the symbol can be
+// converted into USD.
+func isQuoteStablecoin(symbol string) bool {
+	for _, quote := range quoteStablecoins {
+		if symbol == quote {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newOsmosisPoolProvider connects to the Osmosis gRPC endpoint; the connection
+// is closed once the context is done.
+func newOsmosisPoolProvider(
+	ctx context.Context,
+	grpcAddr string,
+	apiKey string,
+	pools []OsmosisPool,
+) (*osmosisPoolProvider, error) {
+	if grpcAddr == "" {
+		return nil, fmt.Errorf("the %s provider requires an Osmosis gRPC endpoint", ProviderOsmosisPool)
+	}
+
+	if len(pools) == 0 {
+		return nil, fmt.Errorf("the %s provider requires at least one Osmosis pool", ProviderOsmosisPool)
+	}
+
+	invoke, err := rawgrpc.Dial(ctx, grpcAddr, insecure.NewCredentials())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the Osmosis gRPC %s: %w", grpcAddr, err)
+	}
+
+	return newOsmosisPoolProviderWithInvoke(withAPIKey(invoke, apiKey), pools), nil
+}
+
+// withAPIKey sends the API key, if any, along with every query.
+func withAPIKey(invoke rawgrpc.InvokeFn, apiKey string) rawgrpc.InvokeFn {
+	if apiKey == "" {
+		return invoke
+	}
+
+	return func(ctx context.Context, method string, req, reply interface{}) error {
+		return invoke(metadata.AppendToOutgoingContext(ctx, osmosisAPIKeyHeader, apiKey), method, req, reply)
+	}
+}
+
+func newOsmosisPoolProviderWithInvoke(invoke rawgrpc.InvokeFn, pools []OsmosisPool) *osmosisPoolProvider {
+	p := &osmosisPoolProvider{
+		pools:  make(map[string]OsmosisPool, len(pools)),
+		invoke: invoke,
+	}
+	for _, pool := range pools {
+		p.pools[pool.Pair.String()] = pool
+	}
+
+	return p
+}
+
+func (p *osmosisPoolProvider) GetTickerPrices(pairs ...pftypes.CurrencyPair) (map[string]pftypes.TickerPrice, error) {
+	tickers := make(map[string]pftypes.TickerPrice, len(pairs))
+
+	for _, pair := range pairs {
+		pool, ok := p.pools[pair.String()]
+		if !ok {
+			return nil, fmt.Errorf(pftypes.ErrMissingExchangeRate.Error(), pair.String())
+		}
+
+		price, err := p.spotPrice(pool)
+		if err != nil {
+			return nil, err
+		}
+
+		tickers[pair.String()] = pftypes.TickerPrice{Price: price, Volume: poolPriceVolume}
+	}
+
+	return tickers, nil
+}
+
+func (p *osmosisPoolProvider) GetCandlePrices(pairs ...pftypes.CurrencyPair) (map[string][]pftypes.CandlePrice, error) {
+	candles := make(map[string][]pftypes.CandlePrice, len(pairs))
+
+	for _, pair := range pairs {
+		pool, ok := p.pools[pair.String()]
+		if !ok {
+			return nil, fmt.Errorf(pftypes.ErrMissingExchangeRate.Error(), pair.String())
+		}
+
+		price, err := p.twap(pool, time.Now().Add(-candlesWindow))
+		if err != nil {
+			return nil, err
+		}
+
+		candles[pair.String()] = []pftypes.CandlePrice{{
+			Price:     price,
+			Volume:    poolPriceVolume,
+			TimeStamp: time.Now().UnixMilli(),
+		}}
+	}
+
+	return candles, nil
+}
+
+// GetAvailablePairs returns the pairs of the configured Osmosis pools.
+func (p *osmosisPoolProvider) GetAvailablePairs() (map[string]struct{}, error) {
+	pairs := make(map[string]struct{}, len(p.pools))
+	for symbol := range p.pools {
+		pairs[symbol] = struct{}{}
+	}
+
+	return pairs, nil
+}
+
+// SubscribeCurrencyPairs performs a no-op since the pools are queried over gRPC
+// on each GetTickerPrices call.
+func (p *osmosisPoolProvider) SubscribeCurrencyPairs(...pftypes.CurrencyPair) error {
+	return nil
+}
+
+func (p *osmosisPoolProvider) spotPrice(pool OsmosisPool) (sdk.Dec, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), osmosisQueryTimeout)
+	defer cancel()
+
+	req := &osmosisSpotPriceRequest{PoolID: pool.PoolID, BaseAssetDenom: pool.BaseDenom, QuoteAssetDenom: pool.QuoteDenom}
+	res := &osmosisSpotPriceResponse{}
+	if err := p.invoke(ctx, osmosisSpotPriceMethod, req, res); err != nil {
+		return sdk.Dec{}, fmt.Errorf("failed to query the spot price of Osmosis pool %d: %w", pool.PoolID, err)
+	}
+
+	price, err := sdk.NewDecFromStr(res.SpotPrice)
+	if err != nil {
+		return sdk.Dec{}, fmt.Errorf("invalid spot price of Osmosis pool %d: %w", pool.PoolID, err)
+	}
+
+	return scaleOsmosisPrice(price, pool.Exponent), nil
+}
+
+func (p *osmosisPoolProvider) twap(pool OsmosisPool, start time.Time) (sdk.Dec, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), osmosisQueryTimeout)
+	defer cancel()
+
+	req := &osmosisTWAPRequest{
+		PoolID:     pool.PoolID,
+		BaseAsset:  pool.BaseDenom,
+		QuoteAsset: pool.QuoteDenom,
+		StartTime:  &osmosisTimestamp{Seconds: start.Unix(), Nanos: int32(start.Nanosecond())},
+	}
+	res := &osmosisTWAPResponse{}
+	if err := p.invoke(ctx, osmosisTWAPMethod, req, res); err != nil {
+		return sdk.Dec{}, fmt.Errorf("failed to query the TWAP of Osmosis pool %d: %w", pool.PoolID, err)
+	}
+
+	// the TWAP is an sdk.Dec encoded as its integer representation
+	i, ok := new(big.Int).SetString(res.ArithmeticTwap, 10)
+	if !ok {
+		return sdk.Dec{}, fmt.Errorf("invalid TWAP of Osmosis pool %d: %q", pool.PoolID, res.ArithmeticTwap)
+	}
+
+	return scaleOsmosisPrice(sdk.NewDecFromBigIntWithPrec(i, sdk.Precision), pool.Exponent), nil
+}
+
+// scaleOsmosisPrice converts a price of the base units in quote units into a
+// price of whole tokens.
+func scaleOsmosisPrice(price sdk.Dec, exponent int) sdk.Dec {
+	switch {
+	case exponent > 0:
+		return price.Mul(sdk.NewDec(10).Power(uint64(exponent)))
+	case exponent < 0:
+		return price.Quo(sdk.NewDec(10).Power(uint64(-exponent)))
+	default:
+		return price
+	}
+}
+
+// The Osmosis query messages (see rawgrpc).
+type (
+	osmosisSpotPriceRequest struct {
+		PoolID          uint64 `protobuf:"varint,1,opt,name=pool_id,json=poolId,proto3"`
+		BaseAssetDenom  string `protobuf:"bytes,2,opt,name=base_asset_denom,json=baseAssetDenom,proto3"`
+		QuoteAssetDenom string `protobuf:"bytes,3,opt,name=quote_asset_denom,json=quoteAssetDenom,proto3"`
+	}
+
+	osmosisSpotPriceResponse struct {
+		SpotPrice string `protobuf:"bytes,1,opt,name=spot_price,json=spotPrice,proto3"`
+	}
+
+	osmosisTWAPRequest struct {
+		PoolID     uint64            `protobuf:"varint,1,opt,name=pool_id,json=poolId,proto3"`
+		BaseAsset  string            `protobuf:"bytes,2,opt,name=base_asset,json=baseAsset,proto3"`
+		QuoteAsset string            `protobuf:"bytes,3,opt,name=quote_asset,json=quoteAsset,proto3"`
+		StartTime  *osmosisTimestamp `protobuf:"bytes,4,opt,name=start_time,json=startTime,proto3"`
+	}
+
+	osmosisTWAPResponse struct {
+		ArithmeticTwap string `protobuf:"bytes,1,opt,name=arithmetic_twap,json=arithmeticTwap,proto3"`
+	}
+
+	// osmosisTimestamp has the wire format of google.protobuf.Timestamp.
+	osmosisTimestamp struct {
+		Seconds int64 `protobuf:"varint,1,opt,name=seconds,proto3"`
+		Nanos   int32 `protobuf:"varint,2,opt,name=nanos,proto3"`
+	}
+)
+
+func (m *osmosisSpotPriceRequest) Reset()         { *m = osmosisSpotPriceRequest{} }
+func (m *osmosisSpotPriceRequest) String() string { return fmt.Sprintf("%+v", *m) }
+func (*osmosisSpotPriceRequest) ProtoMessage()    {}
+
+func (m *osmosisSpotPriceResponse) Reset()         { *m = osmosisSpotPriceResponse{} }
+func (m *osmosisSpotPriceResponse) String() string { return fmt.Sprintf("%+v", *m) }
+func (*osmosisSpotPriceResponse) ProtoMessage()    {}
+
+func (m *osmosisTWAPRequest) Reset()         { *m = osmosisTWAPRequest{} }
+func (m *osmosisTWAPRequest) String() string { return fmt.Sprintf("%+v", *m) }
+func (*osmosisTWAPRequest) ProtoMessage()    {}
+
+func (m *osmosisTWAPResponse) Reset()         { *m = osmosisTWAPResponse{} }
+func (m *osmosisTWAPResponse) String() string { return fmt.Sprintf("%+v", *m) }
+func (*osmosisTWAPResponse) ProtoMessage()    {}
+
+func (m *osmosisTimestamp) Reset()         { *m = osmosisTimestamp{} }
+func (m *osmosisTimestamp) String() string { return fmt.Sprintf("%+v", *m) }
+func (*osmosisTimestamp) ProtoMessage()    {}
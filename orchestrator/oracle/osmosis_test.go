@@ -0,0 +1,124 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+func TestParseOsmosisPools(t *testing.T) {
+	pools, err := ParseOsmosisPools([]string{
+		"umee/usd=1110:ibc/67795E5:ibc/D189335",
+		"WETH/USDT=704:weth-wei:uusdt:12",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []OsmosisPool{
+		{
+			Pair:       pftypes.CurrencyPair{Base: "UMEE", Quote: "USD"},
+			PoolID:     1110,
+			BaseDenom:  "ibc/67795E5",
+			QuoteDenom: "ibc/D189335",
+		},
+		{
+			Pair:       pftypes.CurrencyPair{Base: "WETH", Quote: "USDT"},
+			PoolID:     704,
+			BaseDenom:  "weth-wei",
+			QuoteDenom: "uusdt",
+			Exponent:   12,
+		},
+	}, pools)
+
+	for _, invalid := range [][]string{
+		{"UMEE/USD"},
+		{"UMEE=1110:uumee:uusdc"},
+		{"UMEE/OSMO=1110:uumee:uosmo"},
+		{"UMEE/USD=0:uumee:uusdc"},
+		{"UMEE/USD=1110:uumee"},
+		{"UMEE/USD=1110:uumee:uumee"},
+		{"UMEE/USD=1110:uumee:uusdc:19"},
+		{"UMEE/USD=1110:uumee:uusdc", "UMEE/USD=1:uumee:uusdc"},
+	} {
+		_, err := ParseOsmosisPools(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestOsmosisPoolProvider(t *testing.T) {
+	pools, err := ParseOsmosisPools([]string{
+		"UMEE/USD=1110:uumee:uusdc",
+		"WETH/USD=704:weth-wei:uusdc:12",
+	})
+	require.NoError(t, err)
+
+	invoke := func(_ context.Context, method string, req, reply interface{}) error {
+		switch method {
+		case osmosisSpotPriceMethod:
+			r := req.(*osmosisSpotPriceRequest)
+			switch r.PoolID {
+			case 1110:
+				assert.Equal(t, "uumee", r.BaseAssetDenom)
+				assert.Equal(t, "uusdc", r.QuoteAssetDenom)
+				reply.(*osmosisSpotPriceResponse).SpotPrice = "0.005000000000000000"
+			case 704:
+				reply.(*osmosisSpotPriceResponse).SpotPrice = "0.000000001800000000"
+			}
+
+		case osmosisTWAPMethod:
+			r := req.(*osmosisTWAPRequest)
+			assert.Equal(t, uint64(1110), r.PoolID)
+			assert.NotNil(t, r.StartTime)
+			reply.(*osmosisTWAPResponse).ArithmeticTwap = "6000000000000000" // 0.006
+
+		default:
+			return fmt.Errorf("unexpected method %s", method)
+		}
+
+		return nil
+	}
+
+	p := newOsmosisPoolProviderWithInvoke(invoke, pools)
+
+	umee := pftypes.CurrencyPair{Base: "UMEE", Quote: "USD"}
+	weth := pftypes.CurrencyPair{Base: "WETH", Quote: "USD"}
+
+	available, err := p.GetAvailablePairs()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]struct{}{"UMEEUSD": {}, "WETHUSD": {}}, available)
+
+	tickers, err := p.GetTickerPrices(umee, weth)
+	require.NoError(t, err)
+	assert.Equal(t, sdk.MustNewDecFromStr("0.005"), tickers["UMEEUSD"].Price)
+	assert.Equal(t, sdk.NewDec(1800), tickers["WETHUSD"].Price)
+	assert.Equal(t, poolPriceVolume, tickers["UMEEUSD"].Volume)
+
+	candles, err := p.GetCandlePrices(umee)
+	require.NoError(t, err)
+	require.Len(t, candles["UMEEUSD"], 1)
+	assert.Equal(t, sdk.MustNewDecFromStr("0.006"), candles["UMEEUSD"][0].Price)
+
+	_, err = p.GetTickerPrices(pftypes.CurrencyPair{Base: "ATOM", Quote: "USD"})
+	assert.Error(t, err)
+}
+
+func TestWithAPIKey(t *testing.T) {
+	var keys []string
+	invoke := func(ctx context.Context, _ string, _, _ interface{}) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		keys = md.Get(osmosisAPIKeyHeader)
+		return nil
+	}
+
+	require.NoError(t, withAPIKey(invoke, "secret")(context.Background(), osmosisSpotPriceMethod, nil, nil))
+	assert.Equal(t, []string{"secret"}, keys)
+
+	// without a key, nothing is sent
+	require.NoError(t, withAPIKey(invoke, "")(context.Background(), osmosisSpotPriceMethod, nil, nil))
+	assert.Empty(t, keys)
+}
@@ -0,0 +1,65 @@
+package oracle
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+)
+
+// ErrMissingPrice is returned for symbols the oracle has no price of.
+var ErrMissingPrice = errors.New("missing price")
+
+// PriceErrors are the errors of the symbols that couldn't be priced, by
+// symbol.
+type PriceErrors map[string]error
+
+// Symbols returns the symbols that couldn't be priced, sorted.
+func (e PriceErrors) Symbols() []string {
+	symbols := make([]string, 0, len(e))
+	for symbol := range e {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	return symbols
+}
+
+// Error implements error, listing the errors of every symbol.
+func (e PriceErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, symbol := range e.Symbols() {
+		msgs = append(msgs, e[symbol].Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// GetPricesPartial returns the prices of the provided base symbols the oracle
+// has, along with the errors of the missing ones, which are nil when every
+// symbol is priced. Unlike GetPrices, a missing symbol doesn't prevent using
+// the prices of the others.
+func (o *Oracle) GetPricesPartial(baseSymbols ...string) (map[string]sdk.Dec, PriceErrors) {
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+
+	prices := make(map[string]sdk.Dec, len(baseSymbols))
+	var priceErrs PriceErrors
+
+	for _, baseSymbol := range baseSymbols {
+		price, ok := o.prices[o.canonicalSymbol(baseSymbol)]
+		if !ok {
+			if priceErrs == nil {
+				priceErrs = PriceErrors{}
+			}
+			priceErrs[baseSymbol] = fmt.Errorf("error getting price for %s: %w", baseSymbol, ErrMissingPrice)
+			continue
+		}
+
+		prices[baseSymbol] = price
+	}
+
+	return prices, priceErrs
+}
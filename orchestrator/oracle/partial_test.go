@@ -0,0 +1,40 @@
+package oracle
+
+import (
+	"errors"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPricesPartial(t *testing.T) {
+	o := &Oracle{
+		prices: map[string]sdk.Dec{
+			SymbolETH: sdk.NewDec(1500),
+			"UMEE":    sdk.MustNewDecFromStr("0.005"),
+		},
+	}
+
+	prices, priceErrs := o.GetPricesPartial(SymbolETH, "ATOM", "UMEE", "USDC")
+	assert.Equal(t, map[string]sdk.Dec{SymbolETH: sdk.NewDec(1500), "UMEE": sdk.MustNewDecFromStr("0.005")}, prices)
+	require.Len(t, priceErrs, 2)
+	assert.Equal(t, []string{"ATOM", "USDC"}, priceErrs.Symbols())
+	assert.True(t, errors.Is(priceErrs["ATOM"], ErrMissingPrice))
+	assert.EqualError(
+		t,
+		priceErrs,
+		"error getting price for ATOM: missing price; error getting price for USDC: missing price",
+	)
+
+	prices, priceErrs = o.GetPricesPartial(SymbolETH)
+	assert.Nil(t, priceErrs)
+	assert.Equal(t, map[string]sdk.Dec{SymbolETH: sdk.NewDec(1500)}, prices)
+
+	// GetPrices still fails on any missing symbol, with the errors of all of them
+	_, err := o.GetPrices(SymbolETH, "ATOM", "USDC")
+	var getErrs PriceErrors
+	require.True(t, errors.As(err, &getErrs))
+	assert.Equal(t, []string{"ATOM", "USDC"}, getErrs.Symbols())
+}
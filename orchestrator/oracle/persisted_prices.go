@@ -0,0 +1,134 @@
+package oracle
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	// pricesStoreKey is the store key holding the last known prices.
+	pricesStoreKey = "oracle_prices"
+	// restoredPricesGrace is how long the restored prices not yet recomputed are
+	// kept after startup, while the provider subscriptions warm up.
+	restoredPricesGrace = 10 * time.Minute
+	// defaultRestoredPriceMaxAge is the age after which a restored price is
+	// stale when OptionPriceMaxAge is disabled.
+	defaultRestoredPriceMaxAge = 30 * time.Minute
+)
+
+// persistedPrice is a price persisted on shutdown.
+type persistedPrice struct {
+	Price      sdk.Dec   `json:"price"`
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+// persistPrices writes the current prices, with the time they were computed,
+// to the store, so they can be restored by the next run.
+func (o *Oracle) persistPrices() {
+	if o.store == nil {
+		return
+	}
+
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+
+	// an oracle stopped before computing any price keeps the previous ones
+	if len(o.prices) == 0 {
+		return
+	}
+
+	prices := make(map[string]persistedPrice, len(o.prices))
+	for base, price := range o.prices {
+		prices[base] = persistedPrice{Price: price, ComputedAt: o.priceTimes[base]}
+	}
+
+	if err := o.store.Set(pricesStoreKey, prices); err != nil {
+		o.logger.Warn().Err(err).Msg("failed to persist prices")
+		return
+	}
+
+	o.logger.Debug().Int("prices", len(prices)).Msg("persisted prices")
+}
+
+// loadPrices restores the prices persisted by a previous run, so they're
+// available before the providers report new ones. They keep the time they were
+// computed, and the ones older than the max age (or defaultRestoredPriceMaxAge
+// without one) are marked stale until recomputed. Restored prices the providers
+// don't report again are dropped after restoredPricesGrace.
+func (o *Oracle) loadPrices() {
+	if o.store == nil {
+		return
+	}
+
+	var stored map[string]persistedPrice
+	if _, err := o.store.Get(pricesStoreKey, &stored); err != nil {
+		o.logger.Warn().Err(err).Msg("failed to load persisted prices; starting without them")
+		return
+	}
+
+	if len(stored) == 0 {
+		return
+	}
+
+	maxAge := o.priceMaxAge
+	if maxAge <= 0 {
+		maxAge = defaultRestoredPriceMaxAge
+	}
+
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	if o.prices == nil {
+		o.prices = map[string]sdk.Dec{}
+		o.priceTimes = map[string]time.Time{}
+	}
+	o.restoredPrices = make(map[string]bool, len(stored))
+	o.restoredUntil = time.Now().Add(restoredPricesGrace)
+
+	stale := 0
+	for base, p := range stored {
+		if p.Price.IsNil() || !p.Price.IsPositive() {
+			continue
+		}
+
+		o.prices[base] = p.Price
+		o.priceTimes[base] = p.ComputedAt
+		o.restoredPrices[base] = time.Since(p.ComputedAt) > maxAge
+
+		if o.restoredPrices[base] {
+			stale++
+		}
+	}
+
+	o.logger.Info().
+		Int("prices", len(o.restoredPrices)).
+		Int("stale", stale).
+		Msg("restored persisted prices")
+}
+
+// IsRestored returns whether the price of a symbol was restored from the
+// previous run and not yet recomputed.
+func (o *Oracle) IsRestored(baseSymbol string) bool {
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+
+	_, ok := o.restoredPrices[o.canonicalSymbol(baseSymbol)]
+	return ok
+}
+
+// isRestoredStale returns whether the price of a symbol was restored stale and
+// not yet recomputed.
+func (o *Oracle) isRestoredStale(baseSymbol string) bool {
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+
+	return o.restoredPrices[o.canonicalSymbol(baseSymbol)]
+}
+
+// keepRestoredPrice returns whether a restored price no longer reported by the
+// providers is kept, during restoredPricesGrace after startup.
+func (o *Oracle) keepRestoredPrice(base string, now time.Time) bool {
+	_, ok := o.restoredPrices[base]
+	return ok && now.Before(o.restoredUntil)
+}
@@ -0,0 +1,66 @@
+package oracle
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umee-network/peggo/orchestrator/store"
+)
+
+func TestPersistPrices(t *testing.T) {
+	s, err := store.New(t.TempDir())
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+
+	o := &Oracle{logger: zerolog.Nop(), store: s}
+
+	// nothing computed yet, nothing persisted
+	o.persistPrices()
+	ok, err := s.Get(pricesStoreKey, &map[string]persistedPrice{})
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	o.prices = map[string]sdk.Dec{SymbolETH: sdk.NewDec(1500), "UMEE": sdk.MustNewDecFromStr("0.005")}
+	o.priceTimes = map[string]time.Time{SymbolETH: now.Add(-time.Minute), "UMEE": now.Add(-time.Hour)}
+	o.persistPrices()
+
+	restored := &Oracle{
+		logger:         zerolog.Nop(),
+		store:          s,
+		priceMaxAge:    5 * time.Minute,
+		computeWorkers: 1,
+		computing:      make(chan struct{}, 1),
+	}
+	restored.loadPrices()
+
+	price, computedAt, err := restored.GetPriceWithTimestamp(SymbolETH)
+	require.NoError(t, err)
+	assert.Equal(t, sdk.NewDec(1500), price)
+	assert.True(t, computedAt.Equal(now.Add(-time.Minute)))
+	assert.True(t, restored.IsRestored(SymbolETH))
+	assert.False(t, restored.IsStale(SymbolETH))
+
+	// older than the max age
+	assert.True(t, restored.IsRestored("UMEE"))
+	assert.True(t, restored.IsStale("UMEE"))
+
+	// the restored prices not reported yet are kept during the grace period
+	restored.computePrices(nil, nil, nil, nil)
+	assert.Len(t, restored.prices, 2)
+
+	// recomputed prices are no longer restored
+	restored.setComputedPrices([]string{"UMEE"}, map[string]sdk.Dec{"UMEE": sdk.MustNewDecFromStr("0.006")})
+	assert.False(t, restored.IsRestored("UMEE"))
+	assert.False(t, restored.IsStale("UMEE"))
+
+	restored.restoredUntil = time.Now().Add(-time.Second)
+	restored.computePrices(nil, nil, nil, nil)
+	assert.NotContains(t, restored.prices, SymbolETH)
+	assert.False(t, restored.IsRestored(SymbolETH))
+}
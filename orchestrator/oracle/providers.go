@@ -0,0 +1,115 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+// SetProviders reconfigures the providers of a running oracle. The providers
+// added are connected, subscribed to the pairs of every subscribed symbol and
+// part of the aggregation from the next tick; the ones removed are closed and
+// their candles dropped. Nothing changes if any new provider fails to connect.
+func (o *Oracle) SetProviders(ctx context.Context, providerNames []pfprovider.Name) error {
+	if len(providerNames) == 0 {
+		return errors.New("at least one oracle provider is required")
+	}
+
+	wanted := make(map[pfprovider.Name]struct{}, len(providerNames))
+	for _, providerName := range providerNames {
+		wanted[providerName] = struct{}{}
+	}
+
+	o.mtx.RLock()
+	var added []pfprovider.Name
+	for providerName := range wanted {
+		if _, ok := o.providers[providerName]; !ok {
+			added = append(added, providerName)
+		}
+	}
+
+	pairs := append([]pftypes.CurrencyPair{}, stablecoinPairs...)
+	for baseSymbol := range o.subscribedBaseSymbols {
+		pairs = append(pairs, o.symbolPairs(baseSymbol)...)
+	}
+	o.mtx.RUnlock()
+	sort.Slice(added, func(i, j int) bool { return added[i] < added[j] })
+
+	// the new providers are connected, and get their available pairs and
+	// subscriptions, before locking, so the prices can still be read meanwhile;
+	// they aren't part of the oracle yet, so nothing else touches them
+	newProviders := make(map[pfprovider.Name]*Provider, len(added))
+	subscribed := make(map[pfprovider.Name][]pftypes.CurrencyPair, len(added))
+	for _, providerName := range added {
+		providerCtx, cancel := context.WithCancel(ctx)
+
+		client, err := o.newProvider(providerCtx, o.logger, providerName, pftypes.CurrencyPair{})
+		if err != nil {
+			cancel()
+			for _, p := range newProviders {
+				p.conn.Close()
+			}
+
+			return fmt.Errorf("failed to connect oracle provider %s: %w", providerName, err)
+		}
+
+		provider := &Provider{
+			Provider:        client,
+			availablePairs:  map[string]struct{}{},
+			subscribedPairs: map[string]pftypes.CurrencyPair{},
+			conn:            o.tracker().Track(providerOwner(providerName), cancel),
+			lastUpdate:      time.Now(),
+		}
+		newProviders[providerName] = provider
+
+		o.loadProviderPairs(providerName, provider)
+
+		// the pairs missing are retried on the next subscription reconciliation
+		subscribed[providerName], err = o.subscribeProvider(providerName, provider, pairs)
+		if err != nil {
+			o.logger.Warn().Err(err).Str("provider_name", string(providerName)).
+				Msg("failed to subscribe the added oracle provider")
+		}
+	}
+
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	for providerName, provider := range o.providers {
+		if _, ok := wanted[providerName]; ok {
+			continue
+		}
+
+		if provider.conn != nil {
+			provider.conn.Close()
+		}
+
+		delete(o.providers, providerName)
+		delete(o.providerSubscribedPairs, providerName)
+		delete(o.candles, providerName)
+		o.deleteProviderMetrics(providerName)
+
+		o.logger.Info().Str("provider_name", string(providerName)).Msg("oracle provider removed")
+	}
+
+	for providerName, provider := range newProviders {
+		// a concurrent reconfiguration added it meanwhile
+		if _, ok := o.providers[providerName]; ok {
+			provider.conn.Close()
+			continue
+		}
+
+		provider.breaker = o.newProviderBreaker(providerName, provider)
+		o.providers[providerName] = provider
+		o.addSubscribedPairs(providerName, subscribed[providerName])
+
+		o.logger.Info().Str("provider_name", string(providerName)).Msg("oracle provider added")
+	}
+
+	return nil
+}
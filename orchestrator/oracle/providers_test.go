@@ -0,0 +1,98 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+func TestSetProviders(t *testing.T) {
+	pair := pftypes.CurrencyPair{Base: "ETH", Quote: "USDT"}
+
+	o := &Oracle{
+		logger:                  zerolog.Nop(),
+		providers:               map[pfprovider.Name]*Provider{},
+		providerSubscribedPairs: map[pfprovider.Name][]pftypes.CurrencyPair{},
+		subscribedBaseSymbols:   map[string]struct{}{"ETH": {}},
+		candles:                 pfprovider.AggregatedProviderCandles{},
+	}
+
+	connected := map[pfprovider.Name]*fakeProvider{}
+	o.newProvider = func(
+		_ context.Context,
+		_ zerolog.Logger,
+		providerName pfprovider.Name,
+		_ ...pftypes.CurrencyPair,
+	) (pfprovider.Provider, error) {
+		if providerName == pfprovider.ProviderOkx {
+			return nil, errors.New("connection refused")
+		}
+
+		p := &fakeProvider{available: map[string]struct{}{pair.String(): {}}}
+		connected[providerName] = p
+		return p, nil
+	}
+
+	ctx := context.Background()
+
+	require.NoError(t, o.SetProviders(ctx, []pfprovider.Name{pfprovider.ProviderBinance}))
+	require.Contains(t, o.providers, pfprovider.ProviderBinance)
+
+	// the new provider is subscribed to the symbols already subscribed
+	assert.Equal(t, []pftypes.CurrencyPair{pair}, connected[pfprovider.ProviderBinance].pairs)
+	assert.Equal(t, []pftypes.CurrencyPair{pair}, o.providerSubscribedPairs[pfprovider.ProviderBinance])
+
+	o.candles[pfprovider.ProviderBinance] = map[string][]pftypes.CandlePrice{
+		"ETH": {candle("1500", pfprovider.PastUnixTime(0))},
+	}
+
+	// nothing changes when a new provider fails to connect
+	require.Error(t, o.SetProviders(ctx, []pfprovider.Name{pfprovider.ProviderKraken, pfprovider.ProviderOkx}))
+	assert.Len(t, o.providers, 1)
+	assert.Equal(t, 0, o.LifecycleCounts()[providerOwner(pfprovider.ProviderKraken)].Connections)
+
+	// the removed provider is closed and its candles dropped
+	require.NoError(t, o.SetProviders(ctx, []pfprovider.Name{pfprovider.ProviderKraken}))
+	assert.Contains(t, o.providers, pfprovider.ProviderKraken)
+	assert.NotContains(t, o.providers, pfprovider.ProviderBinance)
+	assert.NotContains(t, o.providerSubscribedPairs, pfprovider.ProviderBinance)
+	assert.NotContains(t, o.candles, pfprovider.ProviderBinance)
+	assert.Equal(t, 0, o.LifecycleCounts()[providerOwner(pfprovider.ProviderBinance)].Connections)
+	assert.Equal(t, 1, o.LifecycleCounts()[providerOwner(pfprovider.ProviderKraken)].Connections)
+
+	// a provider added by a concurrent reconfiguration while connecting is kept
+	var concurrent *Provider
+	newProvider := o.newProvider
+	o.newProvider = func(
+		ctx context.Context,
+		logger zerolog.Logger,
+		providerName pfprovider.Name,
+		pairs ...pftypes.CurrencyPair,
+	) (pfprovider.Provider, error) {
+		client, err := newProvider(ctx, logger, providerName, pairs...)
+
+		o.mtx.Lock()
+		concurrent = &Provider{Provider: client}
+		o.providers[providerName] = concurrent
+		o.mtx.Unlock()
+
+		return client, err
+	}
+
+	require.NoError(t, o.SetProviders(ctx, []pfprovider.Name{pfprovider.ProviderKraken, pfprovider.ProviderBinance}))
+	assert.Same(t, concurrent, o.providers[pfprovider.ProviderBinance])
+	assert.Equal(t, 1, o.LifecycleCounts()[providerOwner(pfprovider.ProviderKraken)].Connections)
+	assert.Equal(t, 0, o.LifecycleCounts()[providerOwner(pfprovider.ProviderBinance)].Connections)
+	o.newProvider = newProvider
+
+	require.Error(t, o.SetProviders(ctx, nil))
+
+	require.NoError(t, o.tracker().Stop(time.Second))
+}
@@ -0,0 +1,173 @@
+package oracle
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+	pforacle "github.com/umee-network/umee/price-feeder/v2/oracle"
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+// providerStaleTimeout is how long a provider with subscribed pairs can go
+// without a new candle before it is reconnected. Candles are 1 minute long for
+// most providers.
+const providerStaleTimeout = 3 * time.Minute
+
+// newProviderFn creates a provider subscribed to the given pairs. Its websocket,
+// if any, is closed when ctx is done.
+type newProviderFn func(
+	ctx context.Context,
+	logger zerolog.Logger,
+	providerName pfprovider.Name,
+	pairs ...pftypes.CurrencyPair,
+) (pfprovider.Provider, error)
+
+func newPriceFeederProvider(
+	ctx context.Context,
+	logger zerolog.Logger,
+	providerName pfprovider.Name,
+	pairs ...pftypes.CurrencyPair,
+) (pfprovider.Provider, error) {
+	return pforacle.NewProvider(ctx, providerName, logger, pfprovider.Endpoint{}, pairs...)
+}
+
+// observeCandles records the most recent candle received from a provider, so
+// providers that stop ticking can be detected.
+func (o *Oracle) observeCandles(
+	providerName pfprovider.Name,
+	provider *Provider,
+	candles map[string][]pftypes.CandlePrice,
+) {
+	latest := provider.lastCandle
+	for _, pairCandles := range candles {
+		for _, candle := range pairCandles {
+			if candle.TimeStamp > latest {
+				latest = candle.TimeStamp
+			}
+		}
+	}
+
+	if latest <= provider.lastCandle {
+		return
+	}
+
+	provider.lastCandle = latest
+	provider.lastUpdate = time.Now()
+
+	if !provider.reconnectedAt.IsZero() {
+		o.logger.Info().
+			Str("provider_name", string(providerName)).
+			Dur("downtime", time.Since(provider.reconnectedAt)).
+			Msg("provider ticks resumed after reconnecting")
+
+		provider.reconnectedAt = time.Time{}
+	}
+}
+
+// recoverProviders reconnects the providers that haven't sent a new candle for
+// their subscribed pairs within providerStaleTimeout, e.g. because their
+// websocket reconnected without restoring the subscriptions. Providers that
+// still don't tick are retried every providerStaleTimeout.
+func (o *Oracle) recoverProviders(ctx context.Context) {
+	o.mtx.RLock()
+	now := time.Now()
+
+	var stale []providerReconnection
+	for providerName, provider := range o.providers {
+		if len(provider.subscribedPairs) == 0 ||
+			now.Sub(provider.lastUpdate) < providerStaleTimeout ||
+			now.Sub(provider.reconnectedAt) < providerStaleTimeout {
+			continue
+		}
+
+		if !provider.reconnectedAt.IsZero() {
+			o.logger.Warn().
+				Str("provider_name", string(providerName)).
+				Msg("provider ticks didn't resume after reconnecting; retrying")
+		}
+
+		stale = append(stale, newProviderReconnection(providerName, provider))
+	}
+	o.mtx.RUnlock()
+
+	o.reconnectProviders(ctx, stale, "provider stopped ticking")
+}
+
+// providerReconnection is a provider being replaced by a new connection.
+type providerReconnection struct {
+	name     pfprovider.Name
+	provider *Provider
+	pairs    []pftypes.CurrencyPair
+
+	client pfprovider.Provider
+	cancel context.CancelFunc
+}
+
+// newProviderReconnection returns the reconnection of a provider to all its
+// subscribed pairs. The caller must hold the lock.
+func newProviderReconnection(providerName pfprovider.Name, provider *Provider) providerReconnection {
+	pairs := make([]pftypes.CurrencyPair, 0, len(provider.subscribedPairs))
+	for _, pair := range provider.subscribedPairs {
+		pairs = append(pairs, pair)
+	}
+
+	return providerReconnection{name: providerName, provider: provider, pairs: pairs}
+}
+
+// reconnectProviders replaces the providers with new connections, closing the
+// previous ones. The reason is logged. The new connections are made without
+// holding the lock, as dialing can take a while and would block every price
+// read; they are then swapped in under it, unless the provider was removed or
+// replaced meanwhile.
+func (o *Oracle) reconnectProviders(ctx context.Context, reconnections []providerReconnection, reason string) {
+	if len(reconnections) == 0 {
+		return
+	}
+
+	for i, r := range reconnections {
+		providerCtx, cancel := context.WithCancel(ctx)
+
+		client, err := o.newProvider(providerCtx, o.logger, r.name, r.pairs...)
+		if err != nil {
+			cancel()
+			o.logger.Err(err).Str("provider_name", string(r.name)).Msg("failed to reconnect provider")
+			continue
+		}
+
+		reconnections[i].client = client
+		reconnections[i].cancel = cancel
+	}
+
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	now := time.Now()
+	for _, r := range reconnections {
+		if r.client == nil {
+			continue
+		}
+
+		provider := r.provider
+		if o.providers[r.name] != provider {
+			r.cancel()
+			continue
+		}
+
+		if provider.conn != nil {
+			provider.conn.Close()
+		}
+
+		provider.Provider = r.client
+		provider.conn = o.tracker().Track(providerOwner(r.name), r.cancel)
+		provider.reconnects++
+		provider.reconnectedAt = now
+
+		o.logger.Warn().
+			Str("provider_name", string(r.name)).
+			Time("last_update", provider.lastUpdate).
+			Int("currency_pairs_length", len(r.pairs)).
+			Msgf("%s; reconnected and re-subscribed its pairs", reason)
+	}
+}
@@ -0,0 +1,105 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+type fakeProvider struct {
+	pairs     []pftypes.CurrencyPair
+	available map[string]struct{}
+}
+
+func (p *fakeProvider) GetTickerPrices(...pftypes.CurrencyPair) (map[string]pftypes.TickerPrice, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *fakeProvider) GetCandlePrices(...pftypes.CurrencyPair) (map[string][]pftypes.CandlePrice, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *fakeProvider) GetAvailablePairs() (map[string]struct{}, error) {
+	return p.available, nil
+}
+
+func (p *fakeProvider) SubscribeCurrencyPairs(pairs ...pftypes.CurrencyPair) error {
+	p.pairs = append(p.pairs, pairs...)
+	return nil
+}
+
+func TestRecoverProviders(t *testing.T) {
+	pair := pftypes.CurrencyPair{Base: "ETH", Quote: "USDT"}
+	stale := time.Now().Add(-2 * providerStaleTimeout)
+
+	var (
+		reconnected []*fakeProvider
+		o           *Oracle
+	)
+	o = &Oracle{
+		logger: zerolog.Nop(),
+		providers: map[pfprovider.Name]*Provider{
+			pfprovider.ProviderBinance: {
+				Provider:        &fakeProvider{},
+				subscribedPairs: map[string]pftypes.CurrencyPair{pair.String(): pair},
+				lastUpdate:      stale,
+			},
+			pfprovider.ProviderKraken: {
+				Provider:        &fakeProvider{},
+				subscribedPairs: map[string]pftypes.CurrencyPair{pair.String(): pair},
+				lastUpdate:      time.Now(),
+			},
+		},
+		newProvider: func(
+			_ context.Context,
+			_ zerolog.Logger,
+			_ pfprovider.Name,
+			pairs ...pftypes.CurrencyPair,
+		) (pfprovider.Provider, error) {
+			// prices can still be read while the provider connects
+			require.True(t, o.mtx.TryLock())
+			o.mtx.Unlock()
+
+			p := &fakeProvider{pairs: pairs}
+			reconnected = append(reconnected, p)
+			return p, nil
+		},
+	}
+
+	o.recoverProviders(context.Background())
+
+	// only the stale provider is reconnected, with its subscribed pairs
+	require.Len(t, reconnected, 1)
+	assert.Equal(t, []pftypes.CurrencyPair{pair}, reconnected[0].pairs)
+
+	binance := o.providers[pfprovider.ProviderBinance]
+	assert.Same(t, reconnected[0], binance.Provider)
+	assert.False(t, binance.reconnectedAt.IsZero())
+
+	// it isn't reconnected again before its ticks had a chance to resume
+	o.recoverProviders(context.Background())
+	assert.Len(t, reconnected, 1)
+
+	// new candles mark it as recovered
+	o.observeCandles(pfprovider.ProviderBinance, binance, map[string][]pftypes.CandlePrice{
+		pair.String(): {candle("1500", pfprovider.PastUnixTime(0))},
+	})
+	assert.True(t, binance.reconnectedAt.IsZero())
+	assert.WithinDuration(t, time.Now(), binance.lastUpdate, time.Second)
+
+	// reconnecting it again closes its previous connection
+	binance.lastUpdate = stale
+	o.recoverProviders(context.Background())
+	require.Len(t, reconnected, 2)
+	assert.Equal(t, 1, o.LifecycleCounts()[providerOwner(pfprovider.ProviderBinance)].Connections)
+
+	require.NoError(t, o.tracker().Stop(time.Second))
+	assert.Equal(t, 0, o.LifecycleCounts()[providerOwner(pfprovider.ProviderBinance)].Connections)
+}
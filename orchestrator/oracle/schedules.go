@@ -0,0 +1,68 @@
+package oracle
+
+import (
+	"time"
+
+	"github.com/umee-network/peggo/orchestrator/schedule"
+)
+
+// OptionPairsReloadSchedule sets when the available pairs of the providers are
+// reloaded, every 24 hours by default.
+func OptionPairsReloadSchedule(s schedule.Schedule) Option {
+	return func(o *Oracle) { o.pairsReloadSchedule = s }
+}
+
+// OptionReconcileSchedule sets when the subscriptions of the providers are
+// reconciled, every 5 minutes by default.
+func OptionReconcileSchedule(s schedule.Schedule) Option {
+	return func(o *Oracle) { o.reconcileSchedule = s }
+}
+
+// scheduleTimer fires at the next time of a schedule, and never once the
+// schedule has none.
+type scheduleTimer struct {
+	schedule schedule.Schedule
+	timer    *time.Timer
+}
+
+func newScheduleTimer(s schedule.Schedule) *scheduleTimer {
+	t := &scheduleTimer{schedule: s}
+	t.Reset()
+
+	return t
+}
+
+// C returns the channel the timer fires on, nil if it never fires.
+func (t *scheduleTimer) C() <-chan time.Time {
+	if t.timer == nil {
+		return nil
+	}
+
+	return t.timer.C
+}
+
+// Reset sets the timer to the next time of the schedule. It must only be called
+// once the timer fired, or before it was set.
+func (t *scheduleTimer) Reset() {
+	now := time.Now()
+
+	next := t.schedule.Next(now)
+	if next.IsZero() {
+		t.timer = nil
+		return
+	}
+
+	if t.timer == nil {
+		t.timer = time.NewTimer(next.Sub(now))
+		return
+	}
+
+	t.timer.Reset(next.Sub(now))
+}
+
+// Stop stops the timer.
+func (t *scheduleTimer) Stop() {
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+}
@@ -0,0 +1,114 @@
+package oracle
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// maxPriceHistory bounds the number of prices kept per symbol, whatever the
+// tick interval and the history retention.
+const maxPriceHistory = 10000
+
+// DefaultSmoothingAlpha is the default weight of each new price in the
+// exponential moving average of GetSmoothedPrice.
+var DefaultSmoothingAlpha = sdk.NewDecWithPrec(2, 1)
+
+// priceSample is a price computed on a tick.
+type priceSample struct {
+	price sdk.Dec
+	time  time.Time
+}
+
+// OptionPriceHistory keeps the prices computed within the given retention, so
+// GetSmoothedPrice can average them over windows up to it. Zero disables it.
+func OptionPriceHistory(retention time.Duration) Option {
+	return func(o *Oracle) { o.historyRetention = retention }
+}
+
+// OptionSmoothingAlpha sets the weight, in (0, 1], of each new price in the
+// exponential moving average of GetSmoothedPrice. Higher values follow the
+// spot price more closely.
+func OptionSmoothingAlpha(alpha sdk.Dec) Option {
+	return func(o *Oracle) { o.smoothingAlpha = alpha }
+}
+
+// ValidateSmoothingAlpha returns an error if alpha isn't in (0, 1].
+func ValidateSmoothingAlpha(alpha sdk.Dec) error {
+	if !alpha.IsPositive() || alpha.GT(sdk.OneDec()) {
+		return fmt.Errorf("invalid smoothing alpha %s; expected a value in (0, 1]", alpha)
+	}
+
+	return nil
+}
+
+// GetSmoothedPrice returns the exponential moving average of the prices of a
+// symbol computed within the window, so a single-tick spike doesn't flap the
+// decisions based on it. Windows longer than the history retention are limited
+// to it. Without a history, or any price computed within the window, the last
+// price is returned as is.
+func (o *Oracle) GetSmoothedPrice(baseSymbol string, window time.Duration) (sdk.Dec, error) {
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+
+	symbol := o.canonicalSymbol(baseSymbol)
+
+	price, ok := o.prices[symbol]
+	if !ok {
+		return sdk.Dec{}, fmt.Errorf("error getting price for %s", baseSymbol)
+	}
+
+	if window <= 0 || o.historyRetention <= 0 {
+		return price, nil
+	}
+
+	alpha := o.smoothingAlpha
+	if alpha.IsNil() {
+		alpha = DefaultSmoothingAlpha
+	}
+
+	cutoff := time.Now().Add(-window)
+	samples := o.priceHistory[symbol]
+	start := sort.Search(len(samples), func(i int) bool { return !samples[i].time.Before(cutoff) })
+
+	var ema sdk.Dec
+	for _, s := range samples[start:] {
+		if ema.IsNil() {
+			ema = s.price
+			continue
+		}
+
+		// ema += alpha * (price - ema)
+		ema = ema.Add(alpha.Mul(s.price.Sub(ema)))
+	}
+
+	if ema.IsNil() {
+		return price, nil
+	}
+
+	return ema, nil
+}
+
+// recordPrice adds a computed price to the history of its symbol, and drops the
+// prices past the retention. The caller must hold the lock.
+func (o *Oracle) recordPrice(base string, price sdk.Dec, now time.Time) {
+	if o.historyRetention <= 0 {
+		return
+	}
+
+	if o.priceHistory == nil {
+		o.priceHistory = map[string][]priceSample{}
+	}
+
+	samples := append(o.priceHistory[base], priceSample{price: price, time: now})
+
+	cutoff := now.Add(-o.historyRetention)
+	start := sort.Search(len(samples), func(i int) bool { return !samples[i].time.Before(cutoff) })
+	if len(samples)-start > maxPriceHistory {
+		start = len(samples) - maxPriceHistory
+	}
+
+	o.priceHistory[base] = samples[start:]
+}
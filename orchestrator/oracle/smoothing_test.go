@@ -0,0 +1,64 @@
+package oracle
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSmoothedPrice(t *testing.T) {
+	o := &Oracle{
+		prices:     map[string]sdk.Dec{},
+		priceTimes: map[string]time.Time{},
+	}
+	OptionSymbolAliases(map[string]string{"WETH": SymbolETH})(o)
+
+	now := time.Now()
+	record := func(price int64, age time.Duration) {
+		o.prices[SymbolETH] = sdk.NewDec(price)
+		o.recordPrice(SymbolETH, sdk.NewDec(price), now.Add(-age))
+	}
+
+	// without a history, the spot price is returned
+	record(1000, 0)
+	price, err := o.GetSmoothedPrice(SymbolETH, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, sdk.NewDec(1000), price)
+
+	OptionPriceHistory(time.Hour)(o)
+	OptionSmoothingAlpha(sdk.NewDecWithPrec(5, 1))(o)
+
+	record(2000, 2*time.Hour) // past the retention
+	record(1000, 30*time.Minute)
+	record(1000, 3*time.Second)
+	record(1000, 2*time.Second)
+	record(2000, time.Second) // single-tick spike
+	assert.Len(t, o.priceHistory[SymbolETH], 4)
+
+	price, err = o.GetSmoothedPrice("WETH", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, sdk.NewDec(1500), price)
+
+	// 1000, 1000, 1000, 2000 over the retention
+	price, err = o.GetSmoothedPrice(SymbolETH, 2*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, sdk.NewDec(1500), price)
+
+	// no price within the window
+	price, err = o.GetSmoothedPrice(SymbolETH, time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, sdk.NewDec(2000), price)
+
+	_, err = o.GetSmoothedPrice("USDC", time.Minute)
+	assert.Error(t, err)
+}
+
+func TestValidateSmoothingAlpha(t *testing.T) {
+	assert.NoError(t, ValidateSmoothingAlpha(sdk.OneDec()))
+	assert.NoError(t, ValidateSmoothingAlpha(DefaultSmoothingAlpha))
+	assert.Error(t, ValidateSmoothingAlpha(sdk.ZeroDec()))
+	assert.Error(t, ValidateSmoothingAlpha(sdk.NewDec(2)))
+}
@@ -0,0 +1,156 @@
+package oracle
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+// ErrStalePrice is returned for prices older than the configured max age.
+var ErrStalePrice = errors.New("stale price")
+
+// OptionPriceMaxAge sets the age after which a price is stale, see IsStale.
+// The age of a price is the time since the provider data it's computed from was
+// last updated, so it gets old when the providers stop updating a symbol, even
+// if its price is still recomputed on every tick from the data they last
+// reported, or when the computation keeps failing. Zero disables it.
+func OptionPriceMaxAge(maxAge time.Duration) Option {
+	return func(o *Oracle) { o.priceMaxAge = maxAge }
+}
+
+// GetPriceWithTimestamp returns the price of a symbol ex.: UMEE, ETH, along
+// with the time the provider data it was computed from was last updated.
+func (o *Oracle) GetPriceWithTimestamp(baseSymbol string) (sdk.Dec, time.Time, error) {
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+
+	symbol := o.canonicalSymbol(baseSymbol)
+
+	price, ok := o.prices[symbol]
+	if !ok {
+		return sdk.Dec{}, time.Time{}, fmt.Errorf("error getting price for %s", baseSymbol)
+	}
+
+	return price, o.priceTimes[symbol], nil
+}
+
+// Symbols returns the symbols the oracle has a price of, sorted.
+func (o *Oracle) Symbols() []string {
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+
+	symbols := make([]string, 0, len(o.prices))
+	for symbol := range o.prices {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	return symbols
+}
+
+// IsStale returns whether the price of a symbol is missing or older than the
+// configured max age, or was restored stale from the previous run, so consumers
+// can refuse it rather than silently use old data.
+func (o *Oracle) IsStale(baseSymbol string) bool {
+	_, computedAt, err := o.GetPriceWithTimestamp(baseSymbol)
+	if err != nil || o.isRestoredStale(baseSymbol) {
+		return true
+	}
+
+	return o.priceMaxAge > 0 && time.Since(computedAt) > o.priceMaxAge
+}
+
+// symbolUpdate is the fingerprint of the provider data of a symbol and when it
+// last changed.
+type symbolUpdate struct {
+	fingerprint uint64
+	updatedAt   time.Time
+}
+
+// trackSymbolUpdates records, for each symbol reported by the providers, when
+// its data last changed: the ticker prices and volumes, and the latest candles.
+// The providers keep returning the last data they received for a symbol, e.g.
+// after its websocket subscription silently stopped, so a symbol is only
+// updated when that data changes.
+func (o *Oracle) trackSymbolUpdates(
+	prices pfprovider.AggregatedProviderPrices,
+	candles pfprovider.AggregatedProviderCandles,
+	now time.Time,
+) {
+	fingerprints := symbolFingerprints(prices, candles)
+
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	if o.symbolUpdates == nil {
+		o.symbolUpdates = map[string]symbolUpdate{}
+	}
+
+	for base, fingerprint := range fingerprints {
+		if update, ok := o.symbolUpdates[base]; ok && update.fingerprint == fingerprint {
+			continue
+		}
+
+		o.symbolUpdates[base] = symbolUpdate{fingerprint: fingerprint, updatedAt: now}
+	}
+}
+
+// symbolUpdatedAt returns when the data of a symbol was last updated, or now
+// if it isn't tracked. It must be called with the lock held.
+func (o *Oracle) symbolUpdatedAt(base string, now time.Time) time.Time {
+	if update, ok := o.symbolUpdates[base]; ok {
+		return update.updatedAt
+	}
+
+	return now
+}
+
+// symbolFingerprints hashes the data of each symbol, over all the providers in
+// a stable order.
+func symbolFingerprints(
+	prices pfprovider.AggregatedProviderPrices,
+	candles pfprovider.AggregatedProviderCandles,
+) map[string]uint64 {
+	data := map[string][]string{}
+	for providerName, tickers := range prices {
+		for base, ticker := range tickers {
+			data[base] = append(data[base], fmt.Sprintf("%s/t/%s/%s", providerName, ticker.Price, ticker.Volume))
+		}
+	}
+	for providerName, baseCandles := range candles {
+		for base, c := range baseCandles {
+			var latest pftypes.CandlePrice
+			for _, candle := range c {
+				if candle.TimeStamp >= latest.TimeStamp {
+					latest = candle
+				}
+			}
+
+			data[base] = append(data[base], fmt.Sprintf(
+				"%s/c/%d/%d/%s/%s",
+				providerName, len(c), latest.TimeStamp, latest.Price, latest.Volume,
+			))
+		}
+	}
+
+	fingerprints := make(map[string]uint64, len(data))
+	for base, parts := range data {
+		sort.Strings(parts)
+
+		h := fnv.New64a()
+		for _, part := range parts {
+			h.Write([]byte(part))
+			h.Write([]byte{0})
+		}
+		fingerprints[base] = h.Sum64()
+	}
+
+	return fingerprints
+}
@@ -0,0 +1,76 @@
+package oracle
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+)
+
+func TestPriceStaleness(t *testing.T) {
+	computedAt := time.Now().Add(-time.Minute)
+
+	o := &Oracle{
+		prices:     map[string]sdk.Dec{SymbolETH: sdk.NewDec(1500)},
+		priceTimes: map[string]time.Time{SymbolETH: computedAt},
+	}
+	OptionSymbolAliases(map[string]string{"WETH": SymbolETH})(o)
+
+	price, ts, err := o.GetPriceWithTimestamp("WETH")
+	require.NoError(t, err)
+	assert.Equal(t, sdk.NewDec(1500), price)
+	assert.Equal(t, computedAt, ts)
+	assert.Equal(t, []string{SymbolETH}, o.Symbols())
+
+	// no max age
+	assert.False(t, o.IsStale(SymbolETH))
+	assert.True(t, o.IsStale("USDC"))
+
+	OptionPriceMaxAge(2 * time.Minute)(o)
+	assert.False(t, o.IsStale(SymbolETH))
+
+	OptionPriceMaxAge(30 * time.Second)(o)
+	assert.True(t, o.IsStale(SymbolETH))
+	assert.True(t, o.IsStale("WETH"))
+}
+
+func TestSymbolStopsUpdating(t *testing.T) {
+	o := &Oracle{}
+	OptionPriceMaxAge(time.Minute)(o)
+
+	tickers := func(umee string) pfprovider.AggregatedProviderPrices {
+		return pfprovider.AggregatedProviderPrices{
+			pfprovider.ProviderBinance: {
+				SymbolETH: {Price: sdk.NewDec(1500), Volume: sdk.NewDec(10)},
+				"UMEE":    {Price: sdk.MustNewDecFromStr(umee), Volume: sdk.NewDec(1000)},
+			},
+		}
+	}
+
+	start := time.Now().Add(-2 * time.Minute)
+	o.trackSymbolUpdates(tickers("0.01"), nil, start)
+	o.setComputedPrices([]string{SymbolETH, "UMEE"}, map[string]sdk.Dec{
+		SymbolETH: sdk.NewDec(1500),
+		"UMEE":    sdk.MustNewDecFromStr("0.01"),
+	})
+
+	// on the next ticks, only UMEE is updated while ETH keeps the same data
+	for i := 1; i <= 2; i++ {
+		o.trackSymbolUpdates(tickers(fmt.Sprintf("0.0%d", i+1)), nil, start.Add(time.Duration(i)*time.Minute))
+		o.setComputedPrices([]string{SymbolETH, "UMEE"}, map[string]sdk.Dec{
+			SymbolETH: sdk.NewDec(1500),
+			"UMEE":    sdk.MustNewDecFromStr("0.02"),
+		})
+	}
+
+	_, ts, err := o.GetPriceWithTimestamp(SymbolETH)
+	require.NoError(t, err)
+	assert.Equal(t, start, ts)
+	assert.True(t, o.IsStale(SymbolETH))
+	assert.False(t, o.IsStale("UMEE"))
+}
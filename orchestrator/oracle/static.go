@@ -0,0 +1,242 @@
+package oracle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/knadh/koanf/parsers/toml"
+	"github.com/rs/zerolog"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+// ProviderStaticFile prices tokens from a local file, for devnets and testnets
+// without exchange connectivity.
+const ProviderStaticFile pfprovider.Name = "staticfile"
+
+var _ pfprovider.Provider = (*staticFileProvider)(nil)
+
+// staticFileProvider implements the price-feeder provider interface with the
+// prices of a JSON or TOML file. When watched, the file is reloaded whenever
+// its modification time changes.
+type staticFileProvider struct {
+	logger zerolog.Logger
+	path   string
+	watch  bool
+
+	mtx     sync.Mutex
+	modTime time.Time
+	prices  map[string]sdk.Dec // pair symbol => price
+}
+
+// OptionStaticPricesFile enables the ProviderStaticFile provider, pricing pairs
+// from the given file (see LoadStaticPrices). When watch is set, changes to the
+// file are picked up without restarting.
+func OptionStaticPricesFile(path string, watch bool) Option {
+	return func(o *Oracle) {
+		next := o.newProvider
+
+		o.newProvider = func(
+			ctx context.Context,
+			logger zerolog.Logger,
+			providerName pfprovider.Name,
+			pairs ...pftypes.CurrencyPair,
+		) (pfprovider.Provider, error) {
+			if providerName != ProviderStaticFile {
+				return next(ctx, logger, providerName, pairs...)
+			}
+
+			return newStaticFileProvider(logger, path, watch)
+		}
+	}
+}
+
+// LoadStaticPrices loads the prices of a .json or .toml file mapping pairs to
+// prices, e.g. {"UMEE/USD": "0.005", "WETH/USD": 1800}. The quotes must be
+// stablecoins the oracle converts into USD. The prices are returned by pair
+// symbol (e.g. UMEEUSD).
+func LoadStaticPrices(path string) (map[string]sdk.Dec, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read static prices file: %w", err)
+	}
+
+	var values map[string]interface{}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(bz))
+		dec.UseNumber()
+		err = dec.Decode(&values)
+	case ".toml":
+		values, err = toml.Parser().Unmarshal(bz)
+	default:
+		return nil, fmt.Errorf("unsupported static prices file extension %q; expected .json or .toml", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse static prices file %s: %w", path, err)
+	}
+
+	prices := make(map[string]sdk.Dec, len(values))
+
+	for pair, value := range values {
+		base, quote, ok := strings.Cut(pair, "/")
+		if !ok || base == "" {
+			return nil, fmt.Errorf("invalid static price pair %q; expected BASE/QUOTE", pair)
+		}
+
+		p := pftypes.CurrencyPair{Base: strings.ToUpper(base), Quote: strings.ToUpper(quote)}
+		if !isQuoteStablecoin(p.Quote) {
+			return nil, fmt.Errorf(
+				"invalid static price pair %q; the quote must be one of: %s", pair, strings.Join(quoteStablecoins, ", "),
+			)
+		}
+
+		price, err := parseStaticPrice(value)
+		if err != nil || !price.IsPositive() {
+			return nil, fmt.Errorf("invalid static price of %s: %v", pair, value)
+		}
+
+		if _, ok := prices[p.String()]; ok {
+			return nil, fmt.Errorf("duplicate static price for %s", p.String())
+		}
+		prices[p.String()] = price
+	}
+
+	return prices, nil
+}
+
+func parseStaticPrice(value interface{}) (sdk.Dec, error) {
+	switch v := value.(type) {
+	case string:
+		return sdk.NewDecFromStr(v)
+	case json.Number:
+		return sdk.NewDecFromStr(v.String())
+	case float64:
+		return sdk.NewDecFromStr(strconv.FormatFloat(v, 'f', -1, 64))
+	case int64:
+		return sdk.NewDec(v), nil
+	default:
+		return sdk.Dec{}, fmt.Errorf("unexpected price type %T", value)
+	}
+}
+
+func newStaticFileProvider(logger zerolog.Logger, path string, watch bool) (*staticFileProvider, error) {
+	p := &staticFileProvider{
+		logger: logger.With().Str("provider", ProviderStaticFile.String()).Logger(),
+		path:   path,
+		watch:  watch,
+	}
+
+	if err := p.load(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// load (re)loads the file when it changed. A file that fails to load while
+// watched keeps the last prices, so a half written file doesn't stop pricing.
+func (p *staticFileProvider) load() error {
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return fmt.Errorf("failed to read static prices file: %w", err)
+	}
+
+	if p.prices != nil && info.ModTime().Equal(p.modTime) {
+		return nil
+	}
+
+	prices, err := LoadStaticPrices(p.path)
+	if err != nil {
+		return err
+	}
+
+	if p.prices != nil {
+		p.logger.Info().Int("pairs", len(prices)).Msg("reloaded static prices")
+	}
+
+	p.prices = prices
+	p.modTime = info.ModTime()
+
+	return nil
+}
+
+func (p *staticFileProvider) currentPrices() map[string]sdk.Dec {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.watch {
+		if err := p.load(); err != nil {
+			p.logger.Err(err).Msg("failed to reload static prices; keeping the previous ones")
+		}
+	}
+
+	return p.prices
+}
+
+// GetTickerPrices returns the file's price of the pairs.
+func (p *staticFileProvider) GetTickerPrices(pairs ...pftypes.CurrencyPair) (map[string]pftypes.TickerPrice, error) {
+	prices := p.currentPrices()
+	tickers := make(map[string]pftypes.TickerPrice, len(pairs))
+
+	for _, pair := range pairs {
+		price, ok := prices[pair.String()]
+		if !ok {
+			return nil, fmt.Errorf(pftypes.ErrMissingExchangeRate.Error(), pair.String())
+		}
+
+		tickers[pair.String()] = pftypes.TickerPrice{Price: price, Volume: poolPriceVolume}
+	}
+
+	return tickers, nil
+}
+
+// GetCandlePrices returns a single candle of the file's price of the pairs.
+func (p *staticFileProvider) GetCandlePrices(pairs ...pftypes.CurrencyPair) (map[string][]pftypes.CandlePrice, error) {
+	prices := p.currentPrices()
+	candles := make(map[string][]pftypes.CandlePrice, len(pairs))
+	now := time.Now().UnixMilli()
+
+	for _, pair := range pairs {
+		price, ok := prices[pair.String()]
+		if !ok {
+			return nil, fmt.Errorf(pftypes.ErrMissingExchangeRate.Error(), pair.String())
+		}
+
+		candles[pair.String()] = []pftypes.CandlePrice{{
+			Price:     price,
+			Volume:    poolPriceVolume,
+			TimeStamp: now,
+		}}
+	}
+
+	return candles, nil
+}
+
+// GetAvailablePairs returns the pairs of the file.
+func (p *staticFileProvider) GetAvailablePairs() (map[string]struct{}, error) {
+	prices := p.currentPrices()
+	pairs := make(map[string]struct{}, len(prices))
+	for symbol := range prices {
+		pairs[symbol] = struct{}{}
+	}
+
+	return pairs, nil
+}
+
+// SubscribeCurrencyPairs performs a no-op since the prices are read from the
+// file.
+func (p *staticFileProvider) SubscribeCurrencyPairs(...pftypes.CurrencyPair) error {
+	return nil
+}
@@ -0,0 +1,87 @@
+package oracle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+func TestLoadStaticPrices(t *testing.T) {
+	dir := t.TempDir()
+
+	jsonPath := filepath.Join(dir, "prices.json")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`{"umee/usd": "0.005", "WETH/USDT": 1800.5}`), 0o600))
+
+	prices, err := LoadStaticPrices(jsonPath)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]sdk.Dec{
+		"UMEEUSD":  sdk.MustNewDecFromStr("0.005"),
+		"WETHUSDT": sdk.MustNewDecFromStr("1800.5"),
+	}, prices)
+
+	tomlPath := filepath.Join(dir, "prices.toml")
+	require.NoError(t, os.WriteFile(tomlPath, []byte("\"UMEE/USD\" = \"0.005\"\n\"WETH/USD\" = 1800\n"), 0o600))
+
+	prices, err = LoadStaticPrices(tomlPath)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]sdk.Dec{
+		"UMEEUSD": sdk.MustNewDecFromStr("0.005"),
+		"WETHUSD": sdk.NewDec(1800),
+	}, prices)
+
+	for _, invalid := range []string{
+		`{"UMEE": "0.005"}`,
+		`{"UMEE/OSMO": "0.005"}`,
+		`{"UMEE/USD": "-1"}`,
+		`{"UMEE/USD": "abc"}`,
+		`{"UMEE/USD": "1", "umee/usd": "2"}`,
+	} {
+		require.NoError(t, os.WriteFile(jsonPath, []byte(invalid), 0o600))
+		_, err := LoadStaticPrices(jsonPath)
+		assert.Error(t, err, invalid)
+	}
+
+	_, err = LoadStaticPrices(filepath.Join(dir, "prices.yaml"))
+	assert.Error(t, err)
+}
+
+func TestStaticFileProviderWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"UMEE/USD": "0.005"}`), 0o600))
+
+	p, err := newStaticFileProvider(zerolog.Nop(), path, true)
+	require.NoError(t, err)
+
+	umee := pftypes.CurrencyPair{Base: "UMEE", Quote: "USD"}
+
+	tickers, err := p.GetTickerPrices(umee)
+	require.NoError(t, err)
+	assert.Equal(t, sdk.MustNewDecFromStr("0.005"), tickers["UMEEUSD"].Price)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"UMEE/USD": "0.006"}`), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+
+	candles, err := p.GetCandlePrices(umee)
+	require.NoError(t, err)
+	require.Len(t, candles["UMEEUSD"], 1)
+	assert.Equal(t, sdk.MustNewDecFromStr("0.006"), candles["UMEEUSD"][0].Price)
+
+	// an invalid file keeps the last prices
+	require.NoError(t, os.WriteFile(path, []byte(`{"UMEE/USD": `), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Minute)))
+
+	tickers, err = p.GetTickerPrices(umee)
+	require.NoError(t, err)
+	assert.Equal(t, sdk.MustNewDecFromStr("0.006"), tickers["UMEEUSD"].Price)
+
+	_, err = p.GetTickerPrices(pftypes.CurrencyPair{Base: "ATOM", Quote: "USD"})
+	assert.Error(t, err)
+}
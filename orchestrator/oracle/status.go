@@ -0,0 +1,44 @@
+package oracle
+
+import (
+	"sort"
+	"time"
+
+	"github.com/umee-network/peggo/orchestrator/breaker"
+	payloadv1 "github.com/umee-network/peggo/orchestrator/payload/v1"
+)
+
+// ProviderStatus is the health of a provider, as seen by the oracle.
+type ProviderStatus = payloadv1.ProviderStatus
+
+// ProviderStatus returns the health of every provider, sorted by name.
+func (o *Oracle) ProviderStatus() []ProviderStatus {
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+
+	now := time.Now()
+
+	statuses := make([]ProviderStatus, 0, len(o.providers))
+	for providerName, provider := range o.providers {
+		sidelined := provider.breaker != nil && provider.breaker.State() == breaker.StateOpen
+		stale := len(provider.subscribedPairs) > 0 && now.Sub(provider.lastUpdate) >= providerStaleTimeout
+
+		statuses = append(statuses, ProviderStatus{
+			Name:            string(providerName),
+			Connected:       !sidelined && !stale && provider.reconnectedAt.IsZero(),
+			Sidelined:       sidelined,
+			LastMessage:     provider.lastUpdate.UTC(),
+			SubscribedPairs: len(provider.subscribedPairs),
+			AvailablePairs:  len(provider.availablePairs),
+			FetchErrors:     provider.fetchErrors.Load(),
+			Reconnects:      provider.reconnects,
+			PairsFailures:   provider.pairsFailures,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses
+}
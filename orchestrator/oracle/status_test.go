@@ -0,0 +1,75 @@
+package oracle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+func TestProviderStatus(t *testing.T) {
+	pair := pftypes.CurrencyPair{Base: "ETH", Quote: "USDT"}
+	lastUpdate := time.Now().Add(-time.Minute).UTC()
+
+	failing := &Provider{
+		Provider:        &failingProvider{},
+		subscribedPairs: map[string]pftypes.CurrencyPair{pair.String(): pair},
+		lastUpdate:      lastUpdate,
+	}
+	// fakeProvider fails its fetches too, but isn't sidelined without a breaker
+	ticking := &Provider{
+		Provider:        &fakeProvider{},
+		availablePairs:  map[string]struct{}{pair.String(): {}},
+		subscribedPairs: map[string]pftypes.CurrencyPair{pair.String(): pair},
+		lastUpdate:      lastUpdate,
+	}
+
+	o := &Oracle{
+		logger:         zerolog.Nop(),
+		computeWorkers: 1,
+		providers: map[pfprovider.Name]*Provider{
+			pfprovider.ProviderKraken:  failing,
+			pfprovider.ProviderBinance: ticking,
+		},
+		newProvider: func(
+			context.Context,
+			zerolog.Logger,
+			pfprovider.Name,
+			...pftypes.CurrencyPair,
+		) (pfprovider.Provider, error) {
+			return &failingProvider{}, nil
+		},
+	}
+	OptionProviderBreaker(2, time.Hour, time.Hour)(o)
+	failing.breaker = o.newProviderBreaker(pfprovider.ProviderKraken, failing)
+
+	o.setPrices()
+	o.setPrices()
+	o.reconnectSidelinedProviders(context.Background())
+
+	assert.Equal(t, []ProviderStatus{
+		{
+			Name:            string(pfprovider.ProviderBinance),
+			Connected:       true,
+			LastMessage:     lastUpdate,
+			SubscribedPairs: 1,
+			AvailablePairs:  1,
+			FetchErrors:     2,
+		},
+		{
+			Name:            string(pfprovider.ProviderKraken),
+			Sidelined:       true,
+			LastMessage:     lastUpdate,
+			SubscribedPairs: 1,
+			FetchErrors:     2,
+			Reconnects:      1,
+		},
+	}, o.ProviderStatus())
+
+	require.NoError(t, o.tracker().Stop(time.Second))
+}
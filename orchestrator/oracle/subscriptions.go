@@ -0,0 +1,166 @@
+package oracle
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+// subscriptionsReconcileInterval is the time between two subscription
+// reconciliations.
+const subscriptionsReconcileInterval = 5 * time.Minute
+
+// reconcileSubscriptions subscribes the providers to the pairs of every
+// subscribed symbol they are missing, e.g. pairs that weren't available when
+// the symbol was subscribed, and repairs the pairs tracked for price
+// computations that drifted from the ones the providers are subscribed to.
+func (o *Oracle) reconcileSubscriptions() {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	repaired := 0
+
+	for providerName, provider := range o.providers {
+		tracked := map[string]struct{}{}
+		for _, pair := range o.providerSubscribedPairs[providerName] {
+			tracked[pair.String()] = struct{}{}
+		}
+
+		inSync := len(tracked) == len(provider.subscribedPairs)
+		for symbol := range provider.subscribedPairs {
+			if _, ok := tracked[symbol]; !ok {
+				inSync = false
+				break
+			}
+		}
+
+		if inSync {
+			continue
+		}
+
+		pairs := make([]pftypes.CurrencyPair, 0, len(provider.subscribedPairs))
+		for _, pair := range provider.subscribedPairs {
+			pairs = append(pairs, pair)
+		}
+		sort.Slice(pairs, func(i, j int) bool { return pairs[i].String() < pairs[j].String() })
+
+		o.providerSubscribedPairs[providerName] = pairs
+		repaired++
+	}
+
+	wanted := append([]pftypes.CurrencyPair{}, stablecoinPairs...)
+	for baseSymbol := range o.subscribedBaseSymbols {
+		wanted = append(wanted, o.symbolPairs(baseSymbol)...)
+	}
+
+	before := o.subscribedPairsCount()
+	if err := o.subscribeProviders(wanted); err != nil {
+		o.logger.Warn().Err(err).Msg("failed to subscribe missing pairs; retrying on the next reconciliation")
+	}
+	added := o.subscribedPairsCount() - before
+
+	if repaired > 0 || added > 0 {
+		o.logger.Info().
+			Int("repaired_providers", repaired).
+			Int("subscribed_pairs", added).
+			Msg("reconciled oracle subscriptions")
+	}
+}
+
+func (o *Oracle) subscribedPairsCount() int {
+	count := 0
+	for _, provider := range o.providers {
+		count += len(provider.subscribedPairs)
+	}
+
+	return count
+}
+
+// UnsubscribeSymbols stops tracking the symbols in all the providers and removes
+// their cached prices and candles, so symbols no longer needed (e.g. tokens no
+// longer batched) don't pile up in long-running processes. The price-feeder
+// providers can't unsubscribe from their websockets, so the pairs are only no
+// longer requested nor priced; subscribing a symbol again resumes them. The
+// stablecoin pairs converting prices into USD are always kept, and the ETH and
+// BTC pairs as long as a subscribed symbol is priced from their cross rate.
+func (o *Oracle) UnsubscribeSymbols(baseSymbols ...string) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	for _, baseSymbol := range baseSymbols {
+		// unsubscribing an alias drops its canonical symbol
+		baseSymbol = o.canonicalSymbol(baseSymbol)
+
+		if _, ok := o.subscribedBaseSymbols[baseSymbol]; !ok {
+			continue
+		}
+
+		delete(o.subscribedBaseSymbols, baseSymbol)
+		o.unsubscribeProviders(append(o.symbolPairs(baseSymbol), getCrossCurrencyPairs(baseSymbol)...))
+
+		if !isStablecoinBase(baseSymbol) {
+			base := strings.ToUpper(baseSymbol)
+
+			delete(o.prices, base)
+			delete(o.priceTimes, base)
+			delete(o.symbolUpdates, base)
+			delete(o.restoredPrices, base)
+			delete(o.interpolatedPrices, base)
+			delete(o.priceHistory, base)
+			for _, bases := range o.candles {
+				delete(bases, base)
+			}
+		}
+
+		o.logger.Debug().
+			Str("token_symbol", baseSymbol).
+			Msg("Symbol unsubscribed")
+	}
+}
+
+// unsubscribeProviders removes the currency pairs, except the stablecoin ones
+// and the ones still needed by the subscribed symbols (e.g. the ETH pairs
+// converting cross rates), from the pairs of every provider.
+func (o *Oracle) unsubscribeProviders(currencyPairs []pftypes.CurrencyPair) {
+	removed := map[string]struct{}{}
+	for _, pair := range currencyPairs {
+		removed[pair.String()] = struct{}{}
+	}
+	for _, pair := range stablecoinPairs {
+		delete(removed, pair.String())
+	}
+	for baseSymbol := range o.subscribedBaseSymbols {
+		for _, pair := range o.symbolPairs(baseSymbol) {
+			delete(removed, pair.String())
+		}
+	}
+
+	for providerName, provider := range o.providers {
+		for symbol := range removed {
+			delete(provider.subscribedPairs, symbol)
+		}
+
+		pairs := make([]pftypes.CurrencyPair, 0, len(o.providerSubscribedPairs[providerName]))
+		for _, pair := range o.providerSubscribedPairs[providerName] {
+			if _, ok := removed[pair.String()]; !ok {
+				pairs = append(pairs, pair)
+			}
+		}
+
+		o.providerSubscribedPairs[providerName] = pairs
+	}
+}
+
+// isStablecoinBase returns true if the symbol is the base of a stablecoin pair,
+// which is always subscribed.
+func isStablecoinBase(baseSymbol string) bool {
+	for _, pair := range stablecoinPairs {
+		if strings.EqualFold(pair.Base, baseSymbol) {
+			return true
+		}
+	}
+
+	return false
+}
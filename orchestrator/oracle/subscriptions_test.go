@@ -0,0 +1,130 @@
+package oracle
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+func TestReconcileSubscriptions(t *testing.T) {
+	provider := &fakeProvider{}
+	binance := &Provider{
+		Provider:        provider,
+		availablePairs:  map[string]struct{}{"USDTUSD": {}},
+		subscribedPairs: map[string]pftypes.CurrencyPair{},
+	}
+
+	o := &Oracle{
+		logger:                  zerolog.Nop(),
+		providers:               map[pfprovider.Name]*Provider{pfprovider.ProviderBinance: binance},
+		subscribedBaseSymbols:   map[string]struct{}{},
+		providerSubscribedPairs: map[pfprovider.Name][]pftypes.CurrencyPair{},
+	}
+
+	// ETH isn't available yet, so only the symbol is recorded
+	require.NoError(t, o.SubscribeSymbols("ETH"))
+	assert.Empty(t, binance.subscribedPairs)
+
+	binance.availablePairs["ETHUSDT"] = struct{}{}
+	o.reconcileSubscriptions()
+
+	ethUSDT := pftypes.CurrencyPair{Base: "ETH", Quote: "USDT"}
+	usdtUSD := pftypes.CurrencyPair{Base: "USDT", Quote: "USD"}
+	wanted := []pftypes.CurrencyPair{usdtUSD, ethUSDT}
+	assert.ElementsMatch(t, wanted, provider.pairs)
+	assert.ElementsMatch(t, wanted, o.providerSubscribedPairs[pfprovider.ProviderBinance])
+
+	// pairs tracked for price computations drifted from the subscribed ones
+	o.providerSubscribedPairs[pfprovider.ProviderBinance] = []pftypes.CurrencyPair{ethUSDT}
+	o.reconcileSubscriptions()
+
+	assert.Equal(t, []pftypes.CurrencyPair{ethUSDT, usdtUSD}, o.providerSubscribedPairs[pfprovider.ProviderBinance])
+	assert.Len(t, provider.pairs, 2)
+}
+
+func TestUnsubscribeSymbols(t *testing.T) {
+	binance := &Provider{
+		Provider:        &fakeProvider{},
+		availablePairs:  map[string]struct{}{"USDTUSD": {}, "ETHUSDT": {}, "ATOMUSDT": {}},
+		subscribedPairs: map[string]pftypes.CurrencyPair{},
+	}
+
+	o := &Oracle{
+		logger:                  zerolog.Nop(),
+		providers:               map[pfprovider.Name]*Provider{pfprovider.ProviderBinance: binance},
+		subscribedBaseSymbols:   map[string]struct{}{},
+		providerSubscribedPairs: map[pfprovider.Name][]pftypes.CurrencyPair{},
+		prices:                  map[string]sdk.Dec{"ETH": sdk.NewDec(1000), "ATOM": sdk.NewDec(10)},
+		priceTimes:              map[string]time.Time{"ETH": time.Now(), "ATOM": time.Now()},
+		candles: pfprovider.AggregatedProviderCandles{
+			pfprovider.ProviderBinance: {"ETH": {{Price: sdk.NewDec(1000)}}, "ATOM": {{Price: sdk.NewDec(10)}}},
+		},
+	}
+
+	require.NoError(t, o.subscribeProviders(stablecoinPairs))
+	require.NoError(t, o.SubscribeSymbols("ETH", "ATOM", "USDT"))
+
+	o.UnsubscribeSymbols("ETH", "USDT", "UNKNOWN")
+
+	usdtUSD := pftypes.CurrencyPair{Base: "USDT", Quote: "USD"}
+	atomUSDT := pftypes.CurrencyPair{Base: "ATOM", Quote: "USDT"}
+	assert.ElementsMatch(
+		t,
+		[]pftypes.CurrencyPair{usdtUSD, atomUSDT},
+		o.providerSubscribedPairs[pfprovider.ProviderBinance],
+	)
+	assert.Equal(t, map[string]pftypes.CurrencyPair{"USDTUSD": usdtUSD, "ATOMUSDT": atomUSDT}, binance.subscribedPairs)
+	assert.Equal(t, map[string]struct{}{"ATOM": {}}, o.subscribedBaseSymbols)
+
+	_, err := o.GetPrice("ETH")
+	assert.Error(t, err)
+	_, err = o.GetPrice("ATOM")
+	assert.NoError(t, err)
+	assert.NotContains(t, o.priceTimes, "ETH")
+	assert.NotContains(t, o.candles[pfprovider.ProviderBinance], "ETH")
+
+	// subscribing again resumes the pairs
+	require.NoError(t, o.SubscribeSymbols("ETH"))
+	assert.Len(t, o.providerSubscribedPairs[pfprovider.ProviderBinance], 3)
+}
+
+func TestUnsubscribeSymbolsWhileSettingPrices(t *testing.T) {
+	binance := &Provider{
+		Provider:        &fakeProvider{},
+		availablePairs:  map[string]struct{}{"USDTUSD": {}, "ETHUSDT": {}},
+		subscribedPairs: map[string]pftypes.CurrencyPair{},
+	}
+
+	o := &Oracle{
+		logger:                  zerolog.Nop(),
+		providers:               map[pfprovider.Name]*Provider{pfprovider.ProviderBinance: binance},
+		subscribedBaseSymbols:   map[string]struct{}{},
+		providerSubscribedPairs: map[pfprovider.Name][]pftypes.CurrencyPair{},
+		computeWorkers:          DefaultComputeWorkers,
+		candles: pfprovider.AggregatedProviderCandles{
+			pfprovider.ProviderBinance: {"ETH": {{Price: sdk.NewDec(1000), TimeStamp: time.Now().UnixMilli()}}},
+		},
+	}
+	require.NoError(t, o.SubscribeSymbols("ETH"))
+
+	// run with -race: the oracle loop must not access the maps being pruned
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			o.setPrices()
+		}
+	}()
+
+	for i := 0; i < 10; i++ {
+		o.UnsubscribeSymbols("ETH")
+		require.NoError(t, o.SubscribeSymbols("ETH"))
+	}
+	<-done
+}
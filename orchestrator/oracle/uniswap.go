@@ -0,0 +1,375 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+// ProviderUniswapV3 prices tokens from Uniswap v3 pools, queried through the
+// Ethereum node.
+const ProviderUniswapV3 pfprovider.Name = "uniswapv3"
+
+const (
+	// uniswapV3QueryTimeout bounds each Uniswap v3 pool query.
+	uniswapV3QueryTimeout = 10 * time.Second
+
+	// uniswapV3ABI is the subset of the Uniswap v3 pool and ERC20 ABIs used to
+	// price the pools.
+	uniswapV3ABI = `[
+		{"name":"slot0","type":"function","stateMutability":"view","inputs":[],"outputs":[
+			{"name":"sqrtPriceX96","type":"uint160"},{"name":"tick","type":"int24"},
+			{"name":"observationIndex","type":"uint16"},{"name":"observationCardinality","type":"uint16"},
+			{"name":"observationCardinalityNext","type":"uint16"},{"name":"feeProtocol","type":"uint8"},
+			{"name":"unlocked","type":"bool"}]},
+		{"name":"observe","type":"function","stateMutability":"view",
+			"inputs":[{"name":"secondsAgos","type":"uint32[]"}],
+			"outputs":[{"name":"tickCumulatives","type":"int56[]"},
+				{"name":"secondsPerLiquidityCumulativeX128s","type":"uint160[]"}]},
+		{"name":"token0","type":"function","stateMutability":"view","inputs":[],
+			"outputs":[{"name":"","type":"address"}]},
+		{"name":"token1","type":"function","stateMutability":"view","inputs":[],
+			"outputs":[{"name":"","type":"address"}]},
+		{"name":"decimals","type":"function","stateMutability":"view","inputs":[],
+			"outputs":[{"name":"","type":"uint8"}]}
+	]`
+)
+
+var (
+	uniswapV3PoolABI = mustParseABI(uniswapV3ABI)
+
+	// q96 is 2^96, the fixed point scale of the Uniswap v3 square root prices.
+	q96 = new(big.Float).SetInt(new(big.Int).Lsh(big.NewInt(1), 96))
+)
+
+var _ pfprovider.Provider = (*uniswapV3Provider)(nil)
+
+type (
+	// UniswapV3Pool is a Uniswap v3 pool pricing a pair.
+	UniswapV3Pool struct {
+		Pair pftypes.CurrencyPair
+		Pool ethcmn.Address
+		// BaseToken is the pool token priced, the other one being the quote.
+		BaseToken ethcmn.Address
+	}
+
+	// uniswapV3PoolInfo holds the tokens of a pool, fetched once.
+	uniswapV3PoolInfo struct {
+		tokens [2]ethcmn.Address
+		// exponent is the decimals of token0 minus the ones of token1, scaling
+		// the pool price of the token units into a price of whole tokens.
+		exponent int
+	}
+
+	// uniswapV3Provider implements the price-feeder provider interface with the
+	// spot price (tickers) and 5 minute TWAP (candles) of Uniswap v3 pools.
+	uniswapV3Provider struct {
+		caller ethereum.ContractCaller
+		pools  map[string]UniswapV3Pool // pair symbol => pool
+
+		mtx   sync.Mutex
+		infos map[ethcmn.Address]uniswapV3PoolInfo // pool => tokens
+	}
+)
+
+// OptionUniswapV3Pools enables the ProviderUniswapV3 provider, pricing the
+// pairs of the given pools through the Ethereum node set with OptionEthCaller.
+func OptionUniswapV3Pools(pools []UniswapV3Pool) Option {
+	return func(o *Oracle) {
+		next := o.newProvider
+
+		o.newProvider = func(
+			ctx context.Context,
+			logger zerolog.Logger,
+			providerName pfprovider.Name,
+			pairs ...pftypes.CurrencyPair,
+		) (pfprovider.Provider, error) {
+			if providerName != ProviderUniswapV3 {
+				return next(ctx, logger, providerName, pairs...)
+			}
+
+			if o.ethCaller == nil {
+				return nil, fmt.Errorf("the %s provider requires an Ethereum node", ProviderUniswapV3)
+			}
+
+			if len(pools) == 0 {
+				return nil, fmt.Errorf("the %s provider requires at least one Uniswap v3 pool", ProviderUniswapV3)
+			}
+
+			return newUniswapV3Provider(o.ethCaller, pools), nil
+		}
+	}
+}
+
+// OptionEthCaller sets the Ethereum node the on-chain providers (e.g.
+// ProviderUniswapV3) are queried through.
+func OptionEthCaller(caller ethereum.ContractCaller) Option {
+	return func(o *Oracle) { o.ethCaller = caller }
+}
+
+// ParseUniswapV3Pools parses pools in the BASE/QUOTE=POOL_ADDRESS:BASE_TOKEN
+// format, e.g. UMEE/USD=0x1b2c...:0xc0a4... for a UMEE/USDC pool. The quote must
+// be a stablecoin the oracle converts into USD.
+func ParseUniswapV3Pools(values []string) ([]UniswapV3Pool, error) {
+	pools := make([]UniswapV3Pool, 0, len(values))
+	seen := map[string]struct{}{}
+
+	for _, v := range values {
+		pair, addresses, ok := strings.Cut(v, "=")
+		base, quote, okPair := strings.Cut(strings.TrimSpace(pair), "/")
+		pool, baseToken, okAddresses := strings.Cut(strings.TrimSpace(addresses), ":")
+
+		if !ok || !okPair || !okAddresses || base == "" ||
+			!ethcmn.IsHexAddress(pool) || !ethcmn.IsHexAddress(baseToken) {
+			return nil, fmt.Errorf("invalid Uniswap v3 pool %q; expected BASE/QUOTE=POOL_ADDRESS:BASE_TOKEN", v)
+		}
+
+		p := UniswapV3Pool{
+			Pair: pftypes.CurrencyPair{
+				Base:  strings.ToUpper(base),
+				Quote: strings.ToUpper(quote),
+			},
+			Pool:      ethcmn.HexToAddress(pool),
+			BaseToken: ethcmn.HexToAddress(baseToken),
+		}
+
+		if !isQuoteStablecoin(p.Pair.Quote) {
+			return nil, fmt.Errorf(
+				"invalid Uniswap v3 pool %q; the quote must be one of: %s", v, strings.Join(quoteStablecoins, ", "),
+			)
+		}
+
+		if _, ok := seen[p.Pair.String()]; ok {
+			return nil, fmt.Errorf("duplicate Uniswap v3 pool for %s", pair)
+		}
+		seen[p.Pair.String()] = struct{}{}
+
+		pools = append(pools, p)
+	}
+
+	return pools, nil
+}
+
+func newUniswapV3Provider(caller ethereum.ContractCaller, pools []UniswapV3Pool) *uniswapV3Provider {
+	p := &uniswapV3Provider{
+		caller: caller,
+		pools:  make(map[string]UniswapV3Pool, len(pools)),
+		infos:  map[ethcmn.Address]uniswapV3PoolInfo{},
+	}
+	for _, pool := range pools {
+		p.pools[pool.Pair.String()] = pool
+	}
+
+	return p
+}
+
+func (p *uniswapV3Provider) GetTickerPrices(pairs ...pftypes.CurrencyPair) (map[string]pftypes.TickerPrice, error) {
+	tickers := make(map[string]pftypes.TickerPrice, len(pairs))
+
+	for _, pair := range pairs {
+		pool, ok := p.pools[pair.String()]
+		if !ok {
+			return nil, fmt.Errorf(pftypes.ErrMissingExchangeRate.Error(), pair.String())
+		}
+
+		price, err := p.spotPrice(pool)
+		if err != nil {
+			return nil, err
+		}
+
+		tickers[pair.String()] = pftypes.TickerPrice{Price: price, Volume: poolPriceVolume}
+	}
+
+	return tickers, nil
+}
+
+func (p *uniswapV3Provider) GetCandlePrices(pairs ...pftypes.CurrencyPair) (map[string][]pftypes.CandlePrice, error) {
+	candles := make(map[string][]pftypes.CandlePrice, len(pairs))
+
+	for _, pair := range pairs {
+		pool, ok := p.pools[pair.String()]
+		if !ok {
+			return nil, fmt.Errorf(pftypes.ErrMissingExchangeRate.Error(), pair.String())
+		}
+
+		price, err := p.twap(pool, candlesWindow)
+		if err != nil {
+			return nil, err
+		}
+
+		candles[pair.String()] = []pftypes.CandlePrice{{
+			Price:     price,
+			Volume:    poolPriceVolume,
+			TimeStamp: time.Now().UnixMilli(),
+		}}
+	}
+
+	return candles, nil
+}
+
+// GetAvailablePairs returns the pairs of the configured Uniswap V3 pools.
+func (p *uniswapV3Provider) GetAvailablePairs() (map[string]struct{}, error) {
+	pairs := make(map[string]struct{}, len(p.pools))
+	for symbol := range p.pools {
+		pairs[symbol] = struct{}{}
+	}
+
+	return pairs, nil
+}
+
+// SubscribeCurrencyPairs performs a no-op since the pools' slot0 is read over
+// JSON-RPC on each GetTickerPrices call.
+func (p *uniswapV3Provider) SubscribeCurrencyPairs(...pftypes.CurrencyPair) error {
+	return nil
+}
+
+// spotPrice returns the current price of the pool, from its square root price.
+func (p *uniswapV3Provider) spotPrice(pool UniswapV3Pool) (sdk.Dec, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), uniswapV3QueryTimeout)
+	defer cancel()
+
+	info, err := p.poolInfo(ctx, pool)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+
+	out, err := p.call(ctx, pool.Pool, "slot0")
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+
+	sqrtPrice := new(big.Float).Quo(new(big.Float).SetInt(out[0].(*big.Int)), q96)
+	price, _ := new(big.Float).Mul(sqrtPrice, sqrtPrice).Float64()
+
+	return uniswapV3BasePrice(pool, info, price)
+}
+
+// twap returns the time weighted average price of the pool over the window,
+// from the geometric mean of its ticks. It fails if the pool doesn't keep
+// observations that old.
+func (p *uniswapV3Provider) twap(pool UniswapV3Pool, window time.Duration) (sdk.Dec, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), uniswapV3QueryTimeout)
+	defer cancel()
+
+	info, err := p.poolInfo(ctx, pool)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+
+	seconds := uint32(window.Seconds())
+	out, err := p.call(ctx, pool.Pool, "observe", []uint32{seconds, 0})
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+
+	tickCumulatives := out[0].([]*big.Int)
+	if len(tickCumulatives) != 2 {
+		return sdk.Dec{}, fmt.Errorf("unexpected observations of Uniswap v3 pool %s", pool.Pool)
+	}
+
+	// rounded down, as the Uniswap oracle library does
+	delta := new(big.Int).Sub(tickCumulatives[1], tickCumulatives[0])
+	tick := new(big.Int).Div(delta, big.NewInt(int64(seconds)))
+
+	return uniswapV3BasePrice(pool, info, math.Pow(1.0001, float64(tick.Int64())))
+}
+
+// poolInfo returns the tokens of the pool, querying them on first use.
+func (p *uniswapV3Provider) poolInfo(ctx context.Context, pool UniswapV3Pool) (uniswapV3PoolInfo, error) {
+	p.mtx.Lock()
+	info, ok := p.infos[pool.Pool]
+	p.mtx.Unlock()
+
+	if !ok {
+		var decimals [2]uint8
+		for i, method := range []string{"token0", "token1"} {
+			out, err := p.call(ctx, pool.Pool, method)
+			if err != nil {
+				return uniswapV3PoolInfo{}, err
+			}
+			info.tokens[i] = out[0].(ethcmn.Address)
+
+			out, err = p.call(ctx, info.tokens[i], "decimals")
+			if err != nil {
+				return uniswapV3PoolInfo{}, err
+			}
+			decimals[i] = out[0].(uint8)
+		}
+		info.exponent = int(decimals[0]) - int(decimals[1])
+
+		p.mtx.Lock()
+		p.infos[pool.Pool] = info
+		p.mtx.Unlock()
+	}
+
+	// checked on every use since pairs may price either token of the same pool
+	if pool.BaseToken != info.tokens[0] && pool.BaseToken != info.tokens[1] {
+		return uniswapV3PoolInfo{}, fmt.Errorf(
+			"%s isn't a token of Uniswap v3 pool %s (%s, %s)", pool.BaseToken, pool.Pool, info.tokens[0], info.tokens[1],
+		)
+	}
+
+	return info, nil
+}
+
+func (p *uniswapV3Provider) call(
+	ctx context.Context,
+	contract ethcmn.Address,
+	method string,
+	args ...interface{},
+) ([]interface{}, error) {
+	data, err := uniswapV3PoolABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := p.caller.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s on %s: %w", method, contract, err)
+	}
+
+	out, err := uniswapV3PoolABI.Unpack(method, res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack %s of %s: %w", method, contract, err)
+	}
+
+	return out, nil
+}
+
+// uniswapV3BasePrice converts a pool price of token0 units in token1 units into
+// a price of whole base tokens in quote tokens.
+func uniswapV3BasePrice(pool UniswapV3Pool, info uniswapV3PoolInfo, price float64) (sdk.Dec, error) {
+	price *= math.Pow10(info.exponent)
+	if pool.BaseToken != info.tokens[0] && price != 0 {
+		price = 1 / price
+	}
+
+	if price <= 0 || math.IsInf(price, 0) || math.IsNaN(price) {
+		return sdk.Dec{}, fmt.Errorf("invalid price of Uniswap v3 pool %s: %g", pool.Pool, price)
+	}
+
+	return sdk.NewDecFromStr(fmt.Sprintf("%.18f", price))
+}
+
+// mustParseABI parses a contract ABI, panicking on error.
+func mustParseABI(s string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+
+	return parsed
+}
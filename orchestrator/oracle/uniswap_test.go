@@ -0,0 +1,124 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+// fakeUniswapV3Pool answers the calls of a pool whose token0 has 18 decimals
+// and token1 6 decimals.
+type fakeUniswapV3Pool struct {
+	pool, token0, token1 ethcmn.Address
+	sqrtPriceX96         *big.Int
+	tickCumulatives      []*big.Int
+}
+
+func (f fakeUniswapV3Pool) CodeAt(context.Context, ethcmn.Address, *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (f fakeUniswapV3Pool) CallContract(_ context.Context, call ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	method, err := uniswapV3PoolABI.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case *call.To == f.pool && method.Name == "token0":
+		return method.Outputs.Pack(f.token0)
+	case *call.To == f.pool && method.Name == "token1":
+		return method.Outputs.Pack(f.token1)
+	case *call.To == f.token0 && method.Name == "decimals":
+		return method.Outputs.Pack(uint8(18))
+	case *call.To == f.token1 && method.Name == "decimals":
+		return method.Outputs.Pack(uint8(6))
+	case *call.To == f.pool && method.Name == "slot0":
+		return method.Outputs.Pack(f.sqrtPriceX96, big.NewInt(0), uint16(0), uint16(0), uint16(0), uint8(0), true)
+	case *call.To == f.pool && method.Name == "observe":
+		return method.Outputs.Pack(f.tickCumulatives, []*big.Int{big.NewInt(0), big.NewInt(0)})
+	}
+
+	return nil, fmt.Errorf("unexpected call of %s on %s", method.Name, call.To)
+}
+
+func TestParseUniswapV3Pools(t *testing.T) {
+	const (
+		pool  = "0x0000000000000000000000000000000000000001"
+		token = "0x0000000000000000000000000000000000000002"
+	)
+
+	pools, err := ParseUniswapV3Pools([]string{"weth/usd=" + pool + ":" + token})
+	require.NoError(t, err)
+	assert.Equal(t, []UniswapV3Pool{{
+		Pair:      pftypes.CurrencyPair{Base: "WETH", Quote: "USD"},
+		Pool:      ethcmn.HexToAddress(pool),
+		BaseToken: ethcmn.HexToAddress(token),
+	}}, pools)
+
+	for _, invalid := range [][]string{
+		{"WETH/USD"},
+		{"WETH/USD=" + pool},
+		{"WETH/USD=" + pool + ":0x123"},
+		{"WETH/ETH=" + pool + ":" + token},
+		{"WETH=" + pool + ":" + token},
+		{"WETH/USD=" + pool + ":" + token, "WETH/USD=" + pool + ":" + token},
+	} {
+		_, err := ParseUniswapV3Pools(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestUniswapV3Provider(t *testing.T) {
+	fake := fakeUniswapV3Pool{
+		pool:   ethcmn.HexToAddress("0x0000000000000000000000000000000000000001"),
+		token0: ethcmn.HexToAddress("0x0000000000000000000000000000000000000002"), // WETH
+		token1: ethcmn.HexToAddress("0x0000000000000000000000000000000000000003"), // USDC
+		// sqrt(2000e6 / 1e18) * 2^96: 2000 USDC per WETH
+		sqrtPriceX96: new(big.Int).Div(
+			new(big.Int).Mul(big.NewInt(44721359549995), new(big.Int).Lsh(big.NewInt(1), 96)),
+			big.NewInt(1e18),
+		),
+		// an average tick of -200000 over 300 seconds
+		tickCumulatives: []*big.Int{big.NewInt(0), big.NewInt(-200000 * 300)},
+	}
+
+	weth := pftypes.CurrencyPair{Base: "WETH", Quote: "USD"}
+	// the pool priced the other way around, in WETH
+	usdc := pftypes.CurrencyPair{Base: "USDC", Quote: "USD"}
+
+	p := newUniswapV3Provider(fake, []UniswapV3Pool{
+		{Pair: weth, Pool: fake.pool, BaseToken: fake.token0},
+		{Pair: usdc, Pool: fake.pool, BaseToken: fake.token1},
+	})
+
+	tickers, err := p.GetTickerPrices(weth, usdc)
+	require.NoError(t, err)
+	assert.InDelta(t, 2000, tickers["WETHUSD"].Price.MustFloat64(), 0.001)
+	assert.InDelta(t, 0.0005, tickers["USDCUSD"].Price.MustFloat64(), 0.0000001)
+	assert.Equal(t, poolPriceVolume, tickers["WETHUSD"].Volume)
+
+	// 1.0001^-200000 * 10^12
+	candles, err := p.GetCandlePrices(weth)
+	require.NoError(t, err)
+	require.Len(t, candles["WETHUSD"], 1)
+	assert.InDelta(t, 2063.2, candles["WETHUSD"][0].Price.MustFloat64(), 0.1)
+
+	// the base token must be in the pool
+	p = newUniswapV3Provider(fake, []UniswapV3Pool{
+		{Pair: weth, Pool: fake.pool, BaseToken: ethcmn.HexToAddress("0x0000000000000000000000000000000000000004")},
+	})
+	_, err = p.GetTickerPrices(weth)
+	assert.Error(t, err)
+
+	_, err = p.GetTickerPrices(pftypes.CurrencyPair{Base: "ATOM", Quote: "USD"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,30 @@
+package oracle
+
+import (
+	sdkmath "cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// ConvertValue returns the USD value of an amount of the token with the given
+// symbol, expressed in its smallest unit (e.g. wei for ETH with 18 decimals).
+func (o *Oracle) ConvertValue(amount sdk.Int, decimals uint8, symbol string) (sdk.Dec, error) {
+	price, err := o.GetPrice(symbol)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+
+	return USDValue(amount, decimals, price), nil
+}
+
+// USDValue returns the value of an amount of a token, expressed in its smallest
+// unit, at the given USD price. Digits beyond the 18 decimals of sdk.Dec are
+// truncated.
+func USDValue(amount sdk.Int, decimals uint8, price sdk.Dec) sdk.Dec {
+	if decimals > sdk.Precision {
+		// sdk.Dec can't hold more than 18 decimals, so we drop the extra digits first.
+		amount = amount.Quo(sdkmath.NewIntWithDecimal(1, int(decimals)-sdk.Precision))
+		decimals = sdk.Precision
+	}
+
+	return sdk.NewDecFromIntWithPrec(amount, int64(decimals)).Mul(price)
+}
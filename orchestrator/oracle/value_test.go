@@ -0,0 +1,32 @@
+package oracle
+
+import (
+	"testing"
+
+	sdkmath "cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUSDValue(t *testing.T) {
+	price := sdk.MustNewDecFromStr("1500.5")
+
+	testCases := []struct {
+		name     string
+		amount   sdk.Int
+		decimals uint8
+		expected string
+	}{
+		{"wei", sdkmath.NewIntWithDecimal(2, 18), 18, "3001.000000000000000000"},
+		{"six decimals", sdk.NewInt(250_000), 6, "375.125000000000000000"},
+		{"no decimals", sdk.NewInt(3), 0, "4501.500000000000000000"},
+		{"more than 18 decimals", sdkmath.NewIntWithDecimal(1, 24), 24, "1500.500000000000000000"},
+		{"dust beyond 18 decimals", sdk.NewInt(1), 24, "0.000000000000000000"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, USDValue(tc.amount, tc.decimals, price).String())
+		})
+	}
+}
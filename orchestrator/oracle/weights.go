@@ -0,0 +1,81 @@
+package oracle
+
+import (
+	"fmt"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+)
+
+// OptionProviderWeights sets the trust weight of each provider (e.g. 1 for a
+// liquid exchange and 0.3 for a small one), scaling its volume and thus its
+// contribution to the VWAP and TVWAP. Providers without a weight keep a weight
+// of 1. The median isn't weighted.
+func OptionProviderWeights(weights map[pfprovider.Name]sdk.Dec) Option {
+	return func(o *Oracle) { o.providerWeights = weights }
+}
+
+// SetProviderWeights changes the provider weights of a running oracle (see
+// OptionProviderWeights), e.g. on a config reload. They apply from the next
+// tick.
+func (o *Oracle) SetProviderWeights(weights map[pfprovider.Name]sdk.Dec) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	o.providerWeights = weights
+}
+
+// ParseProviderWeights parses weights in the PROVIDER=WEIGHT format (e.g.
+// binance=1,mexc=0.3).
+func ParseProviderWeights(values []string) (map[pfprovider.Name]sdk.Dec, error) {
+	weights := make(map[pfprovider.Name]sdk.Dec, len(values))
+
+	for _, v := range values {
+		name, weight, ok := strings.Cut(v, "=")
+		name = strings.TrimSpace(name)
+
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid provider weight %q; expected PROVIDER=WEIGHT (e.g. mexc=0.3)", v)
+		}
+
+		dec, err := sdk.NewDecFromStr(strings.TrimSpace(weight))
+		if err != nil || !dec.IsPositive() {
+			return nil, fmt.Errorf("invalid provider weight %q for %s; expected a positive number", weight, name)
+		}
+
+		if _, ok := weights[pfprovider.Name(name)]; ok {
+			return nil, fmt.Errorf("duplicate provider weight for %s", name)
+		}
+
+		weights[pfprovider.Name(name)] = dec
+	}
+
+	return weights, nil
+}
+
+// applyProviderWeights scales the ticker and candle volumes of the weighted
+// providers in place.
+func applyProviderWeights(
+	weights map[pfprovider.Name]sdk.Dec,
+	prices pfprovider.AggregatedProviderPrices,
+	candles pfprovider.AggregatedProviderCandles,
+) {
+	for providerName, weight := range weights {
+		for base, ticker := range prices[providerName] {
+			if !ticker.Volume.IsNil() {
+				ticker.Volume = ticker.Volume.Mul(weight)
+				prices[providerName][base] = ticker
+			}
+		}
+
+		for _, baseCandles := range candles[providerName] {
+			for i := range baseCandles {
+				if !baseCandles[i].Volume.IsNil() {
+					baseCandles[i].Volume = baseCandles[i].Volume.Mul(weight)
+				}
+			}
+		}
+	}
+}
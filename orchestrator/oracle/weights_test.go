@@ -0,0 +1,56 @@
+package oracle
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pforacle "github.com/umee-network/umee/price-feeder/v2/oracle"
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+func TestParseProviderWeights(t *testing.T) {
+	weights, err := ParseProviderWeights([]string{"binance=1", " mexc = 0.3 "})
+	require.NoError(t, err)
+	assert.Equal(t, map[pfprovider.Name]sdk.Dec{
+		pfprovider.ProviderBinance: sdk.NewDec(1),
+		pfprovider.ProviderMexc:    sdk.MustNewDecFromStr("0.3"),
+	}, weights)
+
+	for _, invalid := range [][]string{
+		{"mexc"},
+		{"=0.3"},
+		{"mexc=abc"},
+		{"mexc=0"},
+		{"mexc=0.3", "mexc=1"},
+	} {
+		_, err := ParseProviderWeights(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestApplyProviderWeights(t *testing.T) {
+	prices := pfprovider.AggregatedProviderPrices{
+		pfprovider.ProviderBinance: {"ATOM": {Price: sdk.NewDec(10), Volume: sdk.NewDec(100)}},
+		pfprovider.ProviderMexc:    {"ATOM": {Price: sdk.NewDec(20), Volume: sdk.NewDec(100)}},
+	}
+	candles := pfprovider.AggregatedProviderCandles{
+		pfprovider.ProviderMexc: {"ATOM": {{Price: sdk.NewDec(20), Volume: sdk.NewDec(50), TimeStamp: 1}}},
+	}
+
+	applyProviderWeights(map[pfprovider.Name]sdk.Dec{
+		pfprovider.ProviderMexc: sdk.MustNewDecFromStr("0.25"),
+	}, prices, candles)
+
+	assert.Equal(t, sdk.NewDec(25), prices[pfprovider.ProviderMexc]["ATOM"].Volume)
+	assert.Equal(t, sdk.NewDec(100), prices[pfprovider.ProviderBinance]["ATOM"].Volume)
+	assert.Equal(t, []pftypes.CandlePrice{
+		{Price: sdk.NewDec(20), Volume: sdk.MustNewDecFromStr("12.5"), TimeStamp: 1},
+	}, candles[pfprovider.ProviderMexc]["ATOM"])
+
+	// (10*100 + 20*25) / 125
+	assert.Equal(t, sdk.NewDec(12), pforacle.ComputeVWAP(prices)["ATOM"])
+}
@@ -2,10 +2,14 @@ package orchestrator
 
 import (
 	"context"
+	"sync/atomic"
 
 	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+
 	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
 )
 
@@ -23,7 +27,13 @@ func (p *gravityOrchestrator) GetLastCheckedBlock(
 		return uint64(0), err
 	}
 
-	lastEventNonce := lastEventResp.EventNonce
+	lastEventNonce := p.lastEventNonce(lastEventResp.EventNonce)
+	if lastEventNonce != lastEventResp.EventNonce {
+		p.logger.Warn().
+			Uint64("last_claimed_nonce", lastEventResp.EventNonce).
+			Uint64("skip_events_before_nonce", p.skipEventsBeforeNonce).
+			Msg("skipping unclaimed events; they will never be claimed by this orchestrator")
+	}
 
 	// zero indicates this oracle has never submitted an event before since there is no
 	// zero event nonce (it's pre-incremented in the solidity contract) we have to go
@@ -32,6 +42,13 @@ func (p *gravityOrchestrator) GetLastCheckedBlock(
 		lastEventNonce = 1
 	}
 
+	// after a contract migration, the event may have been emitted by either
+	// contract
+	gravityAddr, lastEventNonce, err := p.lastEventSource(ctx, lastEventNonce)
+	if err != nil {
+		return 0, err
+	}
+
 	// add delay to ensure minimum confirmations are received and block is finalized
 	currentBlock, err := p.getCurrentBlock(ctx, ethBlockConfirmationDelay)
 	if err != nil {
@@ -39,153 +56,206 @@ func (p *gravityOrchestrator) GetLastCheckedBlock(
 		return 0, err
 	}
 
+	workers := p.startupScanWorkers
+	if workers < 1 {
+		workers = 1
+	}
+
 	for currentBlock > 0 {
-		endSearch := uint64(0)
-		if currentBlock < p.ethBlocksPerLoop {
-			endSearch = 0
-		} else {
-			endSearch = currentBlock - p.ethBlocksPerLoop
+		// The next chunks to scan, newest first, scanned at once.
+		chunks := p.startupScanChunks(currentBlock, workers)
+		results := make([]startupScanResult, len(chunks))
+
+		g, gctx := errgroup.WithContext(ctx)
+		var rateLimited atomic.Bool
+
+		for i, chunk := range chunks {
+			i, chunk := i, chunk
+
+			g.Go(func() (err error) {
+				results[i], err = p.scanChunkWithBackoff(gctx, gravityAddr, chunk, lastEventNonce, &rateLimited)
+				return err
+			})
 		}
 
-		gravityFilterer, err := wrappers.NewGravityFilterer(p.gravityContract.Address(), p.ethProvider)
-		if err != nil {
-			err = errors.Wrap(err, "failed to init Gravity events filterer")
+		if err := g.Wait(); err != nil {
 			return 0, err
 		}
 
-		iterSendToCosmos, err := gravityFilterer.FilterSendToCosmosEvent(&bind.FilterOpts{
-			Start: endSearch,
-			End:   &currentBlock,
-		}, nil, nil)
-		if err != nil {
-			p.logger.Err(err).
-				Uint64("start", endSearch).
-				Uint64("end", currentBlock).
-				Msg("failed to scan past SendToCosmos events from Ethereum")
+		// The results are checked from the newest chunk, as a serial scan would.
+		for _, res := range results {
+			if res.found {
+				return res.block, nil
+			}
 
-			if !isUnknownBlockErr(err) {
-				err = errors.Wrap(err, "failed to scan past SendToCosmos events from Ethereum")
-				return 0, err
-			} else if iterSendToCosmos == nil {
-				return 0, errors.New("no iterator returned")
+			if res.reachedFirstValset {
+				// If another iterator is added below the valset iterator, this panic will be triggered. Add new
+				// iterators above.
+				p.logger.Panic().Msg("could not find the last event relayed")
 			}
 		}
 
-		for iterSendToCosmos.Next() {
-			if iterSendToCosmos.Event.EventNonce.Uint64() == lastEventNonce {
-				return iterSendToCosmos.Event.Raw.BlockNumber, nil
-			}
+		if rateLimited.Load() && workers > 1 {
+			workers /= 2
+			p.logger.Warn().
+				Int("workers", workers).
+				Msg("Ethereum node rate limited the startup scan; scanning fewer chunks at once")
 		}
 
-		iterSendToCosmos.Close()
+		currentBlock = chunks[len(chunks)-1].start
+	}
 
-		iterTXBatchExec, err := gravityFilterer.FilterTransactionBatchExecutedEvent(&bind.FilterOpts{
-			Start: endSearch,
-			End:   &currentBlock,
-		}, nil, nil)
-		if err != nil {
-			p.logger.Err(err).
-				Uint64("start", endSearch).
-				Uint64("end", currentBlock).
-				Msg("failed to scan past TransactionBatchExecuted events from Ethereum")
+	return 0, errors.New("reached the end of block history without finding the Gravity contract deploy event")
+}
 
-			if !isUnknownBlockErr(err) {
-				err = errors.Wrap(err, "failed to scan past TransactionBatchExecuted events from Ethereum")
-				return 0, err
-			} else if iterTXBatchExec == nil {
-				return 0, errors.New("no iterator returned")
-			}
+// scanChunk looks for the event of the given nonce of a Gravity contract within
+// the chunk.
+func (p *gravityOrchestrator) scanChunk(
+	ctx context.Context,
+	gravityAddr ethcmn.Address,
+	chunk startupScanChunk,
+	lastEventNonce uint64,
+) (startupScanResult, error) {
+	endSearch, currentBlock := chunk.start, chunk.end
+
+	gravityFilterer, err := wrappers.NewGravityFilterer(gravityAddr, p.ethProvider)
+	if err != nil {
+		err = errors.Wrap(err, "failed to init Gravity events filterer")
+		return startupScanResult{}, err
+	}
+
+	iterSendToCosmos, err := gravityFilterer.FilterSendToCosmosEvent(&bind.FilterOpts{
+		Start:   endSearch,
+		End:     &currentBlock,
+		Context: ctx,
+	}, nil, nil)
+	if err != nil {
+		p.logger.Err(err).
+			Uint64("start", endSearch).
+			Uint64("end", currentBlock).
+			Msg("failed to scan past SendToCosmos events from Ethereum")
+
+		if !isUnknownBlockErr(err) {
+			err = errors.Wrap(err, "failed to scan past SendToCosmos events from Ethereum")
+			return startupScanResult{}, err
+		} else if iterSendToCosmos == nil {
+			return startupScanResult{}, errors.New("no iterator returned")
 		}
+	}
 
-		for iterTXBatchExec.Next() {
-			if iterTXBatchExec.Event.EventNonce.Uint64() == lastEventNonce {
-				return iterTXBatchExec.Event.Raw.BlockNumber, nil
-			}
+	for iterSendToCosmos.Next() {
+		if iterSendToCosmos.Event.EventNonce.Uint64() == lastEventNonce {
+			return startupScanResult{found: true, block: iterSendToCosmos.Event.Raw.BlockNumber}, nil
+		}
+	}
+
+	iterSendToCosmos.Close()
+
+	iterTXBatchExec, err := gravityFilterer.FilterTransactionBatchExecutedEvent(&bind.FilterOpts{
+		Start:   endSearch,
+		End:     &currentBlock,
+		Context: ctx,
+	}, nil, nil)
+	if err != nil {
+		p.logger.Err(err).
+			Uint64("start", endSearch).
+			Uint64("end", currentBlock).
+			Msg("failed to scan past TransactionBatchExecuted events from Ethereum")
+
+		if !isUnknownBlockErr(err) {
+			err = errors.Wrap(err, "failed to scan past TransactionBatchExecuted events from Ethereum")
+			return startupScanResult{}, err
+		} else if iterTXBatchExec == nil {
+			return startupScanResult{}, errors.New("no iterator returned")
 		}
+	}
 
-		iterTXBatchExec.Close()
+	for iterTXBatchExec.Next() {
+		if iterTXBatchExec.Event.EventNonce.Uint64() == lastEventNonce {
+			return startupScanResult{found: true, block: iterTXBatchExec.Event.Raw.BlockNumber}, nil
+		}
+	}
 
-		iterErc20Deploy, err := gravityFilterer.FilterERC20DeployedEvent(&bind.FilterOpts{
-			Start: endSearch,
-			End:   &currentBlock,
+	iterTXBatchExec.Close()
+
+	iterErc20Deploy, err := gravityFilterer.FilterERC20DeployedEvent(&bind.FilterOpts{
+		Start:   endSearch,
+		End:     &currentBlock,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		p.logger.Err(err).
+			Uint64("start", endSearch).
+			Uint64("end", currentBlock).
+			Msg("failed to scan past ERC20Deployed events from Ethereum")
+
+		if !isUnknownBlockErr(err) {
+			err = errors.Wrap(err, "failed to scan past ERC20Deployed events from Ethereum")
+			return startupScanResult{}, err
+		} else if iterErc20Deploy == nil {
+			return startupScanResult{}, errors.New("no iterator returned")
+		}
+	}
+
+	for iterErc20Deploy.Next() {
+		if iterErc20Deploy.Event.EventNonce.Uint64() == lastEventNonce {
+			return startupScanResult{found: true, block: iterErc20Deploy.Event.Raw.BlockNumber}, nil
+		}
+	}
+
+	iterErc20Deploy.Close()
+
+	// This reverse solves a very specific bug, we use the properties of the first valsets for edgecase
+	// handling here, but events come in chronological order, so if we don't reverse the iterator
+	// we will encounter the first validator sets first and exit early and incorrectly.
+	// Note that reversing everything won't actually get you that much of a performance gain
+	// because this only involves events within the searching block range.
+	var valsetUpdatedEvents []*wrappers.GravityValsetUpdatedEvent
+	{
+		iter, err := gravityFilterer.FilterValsetUpdatedEvent(&bind.FilterOpts{
+			Start:   endSearch,
+			End:     &currentBlock,
+			Context: ctx,
 		}, nil)
 		if err != nil {
 			p.logger.Err(err).
 				Uint64("start", endSearch).
 				Uint64("end", currentBlock).
-				Msg("failed to scan past ERC20Deployed events from Ethereum")
+				Msg("failed to scan past ValsetUpdatedEvent events from Ethereum")
 
 			if !isUnknownBlockErr(err) {
-				err = errors.Wrap(err, "failed to scan past ERC20Deployed events from Ethereum")
-				return 0, err
-			} else if iterErc20Deploy == nil {
-				return 0, errors.New("no iterator returned")
+				err = errors.Wrap(err, "failed to scan past ValsetUpdatedEvent events from Ethereum")
+				return startupScanResult{}, err
+			} else if iter == nil {
+				return startupScanResult{}, errors.New("no iterator returned")
 			}
 		}
 
-		for iterErc20Deploy.Next() {
-			if iterErc20Deploy.Event.EventNonce.Uint64() == lastEventNonce {
-				return iterErc20Deploy.Event.Raw.BlockNumber, nil
-			}
+		for iter.Next() {
+			valsetUpdatedEvents = append(valsetUpdatedEvents, iter.Event)
 		}
 
-		iterErc20Deploy.Close()
-
-		// This reverse solves a very specific bug, we use the properties of the first valsets for edgecase
-		// handling here, but events come in chronological order, so if we don't reverse the iterator
-		// we will encounter the first validator sets first and exit early and incorrectly.
-		// Note that reversing everything won't actually get you that much of a performance gain
-		// because this only involves events within the searching block range.
-		var valsetUpdatedEvents []*wrappers.GravityValsetUpdatedEvent
-		{
-			iter, err := gravityFilterer.FilterValsetUpdatedEvent(&bind.FilterOpts{
-				Start: endSearch,
-				End:   &currentBlock,
-			}, nil)
-			if err != nil {
-				p.logger.Err(err).
-					Uint64("start", endSearch).
-					Uint64("end", currentBlock).
-					Msg("failed to scan past ValsetUpdatedEvent events from Ethereum")
-
-				if !isUnknownBlockErr(err) {
-					err = errors.Wrap(err, "failed to scan past ValsetUpdatedEvent events from Ethereum")
-					return 0, err
-				} else if iter == nil {
-					return 0, errors.New("no iterator returned")
-				}
-			}
-
-			for iter.Next() {
-				valsetUpdatedEvents = append(valsetUpdatedEvents, iter.Event)
-			}
-
-			iter.Close()
-		}
+		iter.Close()
+	}
 
-		// There's no easy way to reverse the list, so we have to do it manually.
-		for i := 0; i < len(valsetUpdatedEvents)/2; i++ {
-			j := len(valsetUpdatedEvents) - i - 1
-			valsetUpdatedEvents[i], valsetUpdatedEvents[j] = valsetUpdatedEvents[j], valsetUpdatedEvents[i]
-		}
+	// There's no easy way to reverse the list, so we have to do it manually.
+	for i := 0; i < len(valsetUpdatedEvents)/2; i++ {
+		j := len(valsetUpdatedEvents) - i - 1
+		valsetUpdatedEvents[i], valsetUpdatedEvents[j] = valsetUpdatedEvents[j], valsetUpdatedEvents[i]
+	}
 
-		for _, valset := range valsetUpdatedEvents {
-			bootstrapping := valset.NewValsetNonce.Uint64() == 0 && lastEventNonce == 1
-			commonCase := valset.EventNonce.Uint64() == lastEventNonce
+	for _, valset := range valsetUpdatedEvents {
+		bootstrapping := valset.NewValsetNonce.Uint64() == 0 && lastEventNonce == 1
+		commonCase := valset.EventNonce.Uint64() == lastEventNonce
 
-			if commonCase || bootstrapping {
-				return valset.Raw.BlockNumber, nil
-			} else if valset.NewValsetNonce.Uint64() == 0 && lastEventNonce > 1 {
-				// If another iterator is added below the valset iterator, this panic will be triggered. Add new
-				// iterators above.
-				p.logger.Panic().Msg("could not find the last event relayed")
-			}
+		if commonCase || bootstrapping {
+			return startupScanResult{found: true, block: valset.Raw.BlockNumber}, nil
+		} else if valset.NewValsetNonce.Uint64() == 0 && lastEventNonce > 1 {
+			return startupScanResult{reachedFirstValset: true}, nil
 		}
-
-		currentBlock = endSearch
 	}
 
-	return 0, errors.New("reached the end of block history without finding the Gravity contract deploy event")
+	return startupScanResult{}, nil
 }
 
 // getCurrentBlock returns the latest block in the eth
@@ -0,0 +1,165 @@
+package orchestrator
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+
+	"github.com/umee-network/peggo/orchestrator/oracle"
+	"github.com/umee-network/peggo/orchestrator/relayer"
+)
+
+// Oracle defines the Oracle interface that the orchestrator depends on: the one
+// of the relayer, along with the partial prices checked while warming up.
+type Oracle interface {
+	relayer.Oracle
+
+	// GetPricesPartial returns the prices of the provided base symbols the
+	// oracle has, along with the errors of the missing ones.
+	GetPricesPartial(baseSymbols ...string) (map[string]sdk.Dec, oracle.PriceErrors)
+}
+
+// oracleWarmupPoll is how often the oracle is checked for prices while warming up.
+const oracleWarmupPoll = time.Second
+
+// SetOracleWarmup returns the orchestrator option waiting up to timeout at
+// startup for the oracle to price the needed symbols.
+func SetOracleWarmup(timeout time.Duration) func(GravityOrchestrator) {
+	return func(o GravityOrchestrator) { o.SetOracleWarmup(timeout) }
+}
+
+// SetOracleWarmup sets how long the relayer and batch requester loops wait at
+// startup for the oracle to price the symbols they need. Zero disables it.
+func (p *gravityOrchestrator) SetOracleWarmup(timeout time.Duration) {
+	p.oracleWarmup = timeout
+}
+
+// waitForOracle waits for the oracle to complete its first tick, then until it
+// has a price for the gas asset and every token paying fees in unbatched
+// transfers or pending batches, or the warm-up timeout expires. It never fails:
+// once it returns, the loops start either way and retry the prices still
+// missing on their own.
+func (p *gravityOrchestrator) waitForOracle(ctx context.Context) {
+	if p.oracle == nil {
+		return
+	}
+
+	select {
+	case <-ctx.Done():
+		return
+	case <-p.oracle.Ready():
+	}
+
+	if p.oracleWarmup <= 0 {
+		return
+	}
+
+	logger := p.logger.With().Str("loop", "OracleWarmup").Logger()
+
+	symbols := p.priceSymbols(ctx)
+	if err := p.oracle.SubscribeSymbols(symbols...); err != nil {
+		logger.Err(err).Strs("symbols", symbols).Msg("failed to subscribe to oracle symbols")
+	}
+
+	timeout := time.NewTimer(p.oracleWarmup)
+	defer timeout.Stop()
+
+	ticker := time.NewTicker(oracleWarmupPoll)
+	defer ticker.Stop()
+
+	for {
+		missing := p.missingPrices(symbols)
+		if len(missing) == 0 {
+			logger.Info().Strs("symbols", symbols).Msg("oracle is ready")
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-timeout.C:
+			logger.Warn().
+				Strs("missing_prices", missing).
+				Dur("timeout", p.oracleWarmup).
+				Msg("oracle is still missing prices; starting anyway")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// priceSymbols returns the symbols of the gas asset and of the tokens paying
+// fees in unbatched transfers or pending batches. Failed lookups are logged and
+// skipped; those prices are then only checked by the loops themselves.
+func (p *gravityOrchestrator) priceSymbols(ctx context.Context) []string {
+	tokens := map[string]struct{}{}
+
+	if res, err := p.cosmosQueryClient.BatchFees(ctx, &types.QueryBatchFeeRequest{}); err != nil {
+		p.logger.Debug().Err(err).Msg("failed to get batch fees for the oracle warm-up")
+	} else {
+		for _, fees := range res.BatchFees {
+			tokens[fees.Token] = struct{}{}
+		}
+	}
+
+	if res, err := p.cosmosQueryClient.OutgoingTxBatches(ctx, &types.QueryOutgoingTxBatchesRequest{}); err != nil {
+		p.logger.Debug().Err(err).Msg("failed to get pending batches for the oracle warm-up")
+	} else {
+		for _, batch := range res.Batches {
+			tokens[batch.TokenContract] = struct{}{}
+		}
+	}
+
+	symbols := map[string]struct{}{p.gasAssetSymbol(): {}}
+
+	for token := range tokens {
+		if p.symbolRetriever == nil {
+			break
+		}
+
+		symbol, err := p.symbolRetriever.GetTokenSymbol(ethcmn.HexToAddress(token))
+		if err != nil {
+			p.logger.Debug().Err(err).Str("token_contract", token).Msg("failed to get token symbol")
+			continue
+		}
+
+		symbols[symbol] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(symbols))
+	for symbol := range symbols {
+		sorted = append(sorted, symbol)
+	}
+	sort.Strings(sorted)
+
+	return sorted
+}
+
+// gasAssetSymbol returns the symbol of the asset paying for gas on the target
+// EVM chain, as configured in the relayer.
+func (p *gravityOrchestrator) gasAssetSymbol() string {
+	if p.relayer == nil {
+		return oracle.SymbolETH
+	}
+
+	return p.relayer.GetGasAssetSymbol()
+}
+
+// missingPrices returns the symbols the oracle has no valid price for.
+func (p *gravityOrchestrator) missingPrices(symbols []string) []string {
+	prices, priceErrs := p.oracle.GetPricesPartial(symbols...)
+
+	var missing []string
+	for _, symbol := range symbols {
+		price := prices[symbol]
+		if priceErrs[symbol] != nil || price.IsNil() || !price.IsPositive() {
+			missing = append(missing, symbol)
+		}
+	}
+
+	return missing
+}
@@ -0,0 +1,200 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/umee-network/peggo/mocks"
+	"github.com/umee-network/peggo/orchestrator/oracle"
+)
+
+type warmingOracle struct {
+	mtx        sync.Mutex
+	prices     map[string]sdk.Dec
+	subscribed []string
+	ready      chan struct{}
+}
+
+func newWarmingOracle(prices map[string]sdk.Dec) *warmingOracle {
+	ready := make(chan struct{})
+	close(ready)
+
+	return &warmingOracle{prices: prices, ready: ready}
+}
+
+func (o *warmingOracle) GetPrices(baseSymbols ...string) (map[string]sdk.Dec, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (o *warmingOracle) GetPricesPartial(baseSymbols ...string) (map[string]sdk.Dec, oracle.PriceErrors) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	prices := map[string]sdk.Dec{}
+	var priceErrs oracle.PriceErrors
+
+	for _, symbol := range baseSymbols {
+		price, ok := o.prices[symbol]
+		if !ok {
+			if priceErrs == nil {
+				priceErrs = oracle.PriceErrors{}
+			}
+			priceErrs[symbol] = oracle.ErrMissingPrice
+			continue
+		}
+
+		prices[symbol] = price
+	}
+
+	return prices, priceErrs
+}
+
+func (o *warmingOracle) GetPrice(baseSymbol string) (sdk.Dec, error) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	price, ok := o.prices[baseSymbol]
+	if !ok {
+		return sdk.Dec{}, errors.New("error getting price")
+	}
+
+	return price, nil
+}
+
+func (o *warmingOracle) GetSmoothedPrice(baseSymbol string, _ time.Duration) (sdk.Dec, error) {
+	return o.GetPrice(baseSymbol)
+}
+
+func (o *warmingOracle) IsStale(baseSymbol string) bool {
+	return false
+}
+
+func (o *warmingOracle) ConvertValue(amount sdk.Int, decimals uint8, symbol string) (sdk.Dec, error) {
+	price, err := o.GetPrice(symbol)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+
+	return oracle.USDValue(amount, decimals, price), nil
+}
+
+func (o *warmingOracle) SubscribeSymbols(baseSymbols ...string) error {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	o.subscribed = append(o.subscribed, baseSymbols...)
+	return nil
+}
+
+func (o *warmingOracle) Ready() <-chan struct{} {
+	return o.ready
+}
+
+func (o *warmingOracle) setPrice(symbol, price string) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	o.prices[symbol] = sdk.MustNewDecFromStr(price)
+}
+
+type staticSymbolRetriever map[ethcmn.Address]string
+
+func (r staticSymbolRetriever) GetTokenSymbol(erc20Contract ethcmn.Address) (string, error) {
+	return r[erc20Contract], nil
+}
+
+func TestWaitForOracle(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	token := "0x0000000000000000000000000000000000000001"
+
+	mockQClient := mocks.NewMockQueryClient(mockCtrl)
+	mockQClient.EXPECT().
+		BatchFees(gomock.Any(), gomock.Any()).
+		Return(&types.QueryBatchFeeResponse{BatchFees: []types.BatchFees{{Token: token}}}, nil).
+		AnyTimes()
+	mockQClient.EXPECT().
+		OutgoingTxBatches(gomock.Any(), gomock.Any()).
+		Return(&types.QueryOutgoingTxBatchesResponse{}, nil).
+		AnyTimes()
+
+	newOrch := func(o *warmingOracle, timeout time.Duration) *gravityOrchestrator {
+		orch := &gravityOrchestrator{
+			logger:            zerolog.Nop(),
+			cosmosQueryClient: mockQClient,
+			symbolRetriever:   staticSymbolRetriever{ethcmn.HexToAddress(token): "USDC"},
+			oracle:            o,
+		}
+		orch.SetOracleWarmup(timeout)
+
+		return orch
+	}
+
+	t.Run("ready", func(t *testing.T) {
+		o := newWarmingOracle(map[string]sdk.Dec{"ETH": sdk.MustNewDecFromStr("1500")})
+		orch := newOrch(o, time.Minute)
+
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			o.setPrice("USDC", "1")
+		}()
+
+		start := time.Now()
+		orch.waitForOracle(context.Background())
+
+		assert.Less(t, time.Since(start), time.Minute)
+		assert.ElementsMatch(t, []string{"ETH", "USDC"}, o.subscribed)
+		assert.Empty(t, orch.missingPrices([]string{"ETH", "USDC"}))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		o := newWarmingOracle(map[string]sdk.Dec{"ETH": sdk.MustNewDecFromStr("1500")})
+		orch := newOrch(o, 50*time.Millisecond)
+
+		orch.waitForOracle(context.Background())
+
+		assert.Equal(t, []string{"USDC"}, orch.missingPrices([]string{"ETH", "USDC"}))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		o := newWarmingOracle(map[string]sdk.Dec{})
+		orch := newOrch(o, 0)
+
+		orch.waitForOracle(context.Background())
+
+		assert.Empty(t, o.subscribed)
+	})
+
+	t.Run("not ready", func(t *testing.T) {
+		o := newWarmingOracle(map[string]sdk.Dec{"ETH": sdk.MustNewDecFromStr("1500")})
+		o.ready = make(chan struct{})
+		orch := newOrch(o, 0)
+
+		// the warm-up being disabled, only the first tick is waited for
+		done := make(chan struct{})
+		go func() {
+			orch.waitForOracle(context.Background())
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			t.Fatal("returned before the oracle was ready")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(o.ready)
+		<-done
+	})
+}
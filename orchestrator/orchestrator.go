@@ -8,7 +8,9 @@ import (
 	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
 	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/rs/zerolog"
+	"github.com/shopspring/decimal"
 
+	"github.com/umee-network/peggo/orchestrator/cache"
 	sidechain "github.com/umee-network/peggo/orchestrator/cosmos"
 	gravity "github.com/umee-network/peggo/orchestrator/ethereum/gravity"
 	"github.com/umee-network/peggo/orchestrator/ethereum/keystore"
@@ -24,6 +26,41 @@ type GravityOrchestrator interface {
 	EthSignerMainLoop(ctx context.Context) error
 	BatchRequesterLoop(ctx context.Context) error
 	RelayerMainLoop(ctx context.Context) error
+
+	// SetBatchDustThreshold sets the USD fee value under which a batch of a
+	// single transfer is considered dust and not requested.
+	SetBatchDustThreshold(usd float64)
+
+	// SetBatchTiming delays batch requests until maxSize transfers are
+	// unbatched or maxWait has elapsed.
+	SetBatchTiming(maxSize uint64, maxWait time.Duration)
+
+	// SetOracleWarmup sets how long the relayer and batch requester loops wait
+	// at startup for the oracle to price the symbols they need.
+	SetOracleWarmup(timeout time.Duration)
+
+	// SetSkipEventsBeforeNonce makes the Ethereum oracle ignore the events with
+	// a nonce lower than the given one.
+	SetSkipEventsBeforeNonce(nonce uint64)
+
+	// SetCosmosHeightChecker sets the checker run before sending Ethereum
+	// claims; no claim is sent while it fails.
+	SetCosmosHeightChecker(checker CosmosHeightChecker)
+
+	// SetCacheMetrics exports the hits and misses of the orchestrator caches.
+	SetCacheMetrics(m *cache.Metrics)
+
+	// SetStartupScan sets how many chunks of blocks, and how many blocks per
+	// chunk, are scanned at once for the last claimed event.
+	SetStartupScan(workers int, chunkSize uint64)
+
+	// SetContractMigration makes the Ethereum oracle switch to a new Gravity
+	// contract at the given block.
+	SetContractMigration(m ContractMigration)
+
+	// SetConfirmSync makes the orchestrator sign the confirms of all the
+	// valsets and batches it missed at startup, before starting its loops.
+	SetConfirmSync(enabled bool)
 }
 
 type gravityOrchestrator struct {
@@ -42,11 +79,24 @@ type gravityOrchestrator struct {
 	ethBlocksPerLoop           uint64
 	bridgeStartHeight          uint64
 	symbolRetriever            relayer.SymbolRetriever
-	oracle                     relayer.Oracle
+	oracle                     Oracle
+	batchDustThresholdUSD      decimal.Decimal
+	batchTargetSize            uint64
+	batchMaxWait               time.Duration
+	batchWaitingSince          map[string]time.Time
+	oracleWarmup               time.Duration
+	skipEventsBeforeNonce      uint64
+	cosmosHeightChecker        CosmosHeightChecker
+	cacheMetrics               *cache.Metrics
+	startupScanWorkers         int
+	startupScanChunkSize       uint64
+	contractMigration          *ContractMigration
+	confirmSync                bool
 
 	mtx             sync.Mutex
-	erc20DenomCache map[string]string
+	erc20DenomCache *cache.LRU[string, string]
 	ethMergePause   bool
+	now             func() time.Time
 }
 
 func NewGravityOrchestrator(
@@ -64,7 +114,7 @@ func NewGravityOrchestrator(
 	ethBlocksPerLoop int64,
 	bridgeStartHeight int64,
 	symbolRetriever relayer.SymbolRetriever,
-	oracle relayer.Oracle,
+	oracle Oracle,
 	ethMergePause bool, // TODO: remove this after merge is completed
 	options ...func(GravityOrchestrator),
 ) GravityOrchestrator {
@@ -87,6 +137,7 @@ func NewGravityOrchestrator(
 		symbolRetriever:            symbolRetriever,
 		oracle:                     oracle,
 		ethMergePause:              ethMergePause,
+		now:                        time.Now,
 	}
 
 	for _, option := range options {
@@ -0,0 +1,176 @@
+// Package v1 defines version 1 of the JSON payloads peggo sends to webhooks
+// and serves over its HTTP APIs.
+//
+// Within a version, payloads only change in backward compatible ways: fields
+// may be added, but existing fields are never removed, renamed, retyped or made
+// optional. Any other change requires a new version. The JSON schema of each
+// payload is checked in under schemas/ and printed by `peggo schema`.
+package v1
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Version is the version of the payloads defined in this package.
+const Version = "v1"
+
+// HeaderSchema is the HTTP header set to the schema name of the payload sent
+// in a webhook request or API response.
+const HeaderSchema = "X-Peggo-Schema"
+
+// Schema names, as set in HeaderSchema.
+const (
+	SchemaPolicyAlert       = "peggo.v1.policy_alert"
+	SchemaTopupAlert        = "peggo.v1.topup_alert"
+	SchemaPriceAlert        = "peggo.v1.price_alert"
+	SchemaDailySummary      = "peggo.v1.daily_summary"
+	SchemaHeartbeatStatus   = "peggo.v1.heartbeat_status"
+	SchemaPrice             = "peggo.v1.price"
+	SchemaPricesResponse    = "peggo.v1.prices_response"
+	SchemaProvidersResponse = "peggo.v1.providers_response"
+	SchemaErrorResponse     = "peggo.v1.error_response"
+)
+
+type (
+	// Labels are the key=value pairs set with --instance-label. They're added
+	// to the webhook payloads, summaries and heartbeats to distinguish this
+	// instance from others, e.g. in aggregated monitoring.
+	Labels map[string]string
+
+	// PolicyAlert is sent to the key usage policy webhook for every tx the
+	// policy refused to sign.
+	PolicyAlert struct {
+		From      string    `json:"from"`
+		To        string    `json:"to"`
+		Nonce     uint64    `json:"nonce"`
+		Violation string    `json:"violation"`
+		Time      time.Time `json:"time"`
+		Labels    Labels    `json:"labels,omitempty"`
+	}
+
+	// TopupAlert is sent to the top-up webhook when the relayer's ETH balance
+	// drops below the threshold. Amounts are in wei.
+	TopupAlert struct {
+		EthAddress string    `json:"eth_address"`
+		Balance    string    `json:"balance"`
+		Threshold  string    `json:"threshold"`
+		Time       time.Time `json:"time"`
+		Labels     Labels    `json:"labels,omitempty"`
+	}
+
+	// PriceAlert is sent to the price alert webhook when an oracle price moves
+	// more than the threshold between two ticks (kind "move") or disappears
+	// (kind "missing"). Prices are in USD; the change is relative to the
+	// previous price. Price and Change are omitted for missing prices.
+	PriceAlert struct {
+		Kind     string    `json:"kind"`
+		Symbol   string    `json:"symbol"`
+		Previous string    `json:"previous"`
+		Price    string    `json:"price,omitempty"`
+		Change   string    `json:"change,omitempty"`
+		Time     time.Time `json:"time"`
+		Labels   Labels    `json:"labels,omitempty"`
+	}
+
+	// DailySummary summarizes the bridge usage of a UTC day. Volumes are in
+	// the token's base units. A partial summary doesn't cover the whole day,
+	// e.g. the day the aggregator was first started.
+	DailySummary struct {
+		Date            string         `json:"date"`
+		Partial         bool           `json:"partial,omitempty"`
+		Tokens          []TokenSummary `json:"tokens"`
+		UniqueAddresses int            `json:"unique_addresses"`
+		Labels          Labels         `json:"labels,omitempty"`
+	}
+
+	// TokenSummary summarizes the bridge usage of a token in a day.
+	TokenSummary struct {
+		TokenContract    string `json:"token_contract"`
+		Deposits         int    `json:"deposits"`
+		DepositVolume    string `json:"deposit_volume"`
+		Withdrawals      int    `json:"withdrawals"`
+		WithdrawalVolume string `json:"withdrawal_volume"`
+		UniqueAddresses  int    `json:"unique_addresses"`
+	}
+
+	// HeartbeatStatus is the status summary sent on every heartbeat. Values
+	// that could not be fetched are left empty and the reason is added to
+	// Errors, so a monitor still hears from a partially broken orchestrator.
+	HeartbeatStatus struct {
+		Moniker                string    `json:"moniker,omitempty"`
+		Orchestrator           string    `json:"orchestrator"`
+		EthAddress             string    `json:"eth_address"`
+		Time                   time.Time `json:"time"`
+		CosmosHeight           int64     `json:"cosmos_height"`
+		EthHeight              uint64    `json:"eth_height"`
+		LastClaimedEventNonce  uint64    `json:"last_claimed_event_nonce"`
+		EthLastEventNonce      uint64    `json:"eth_last_event_nonce"`
+		EthAccountNoncePending uint64    `json:"eth_account_nonce_pending"`
+		EthBalance             string    `json:"eth_balance"`
+		CosmosBalances         string    `json:"cosmos_balances"`
+		Errors                 []string  `json:"errors,omitempty"`
+		Labels                 Labels    `json:"labels,omitempty"`
+	}
+
+	// Price is the current price of a symbol, in USD. Stale prices are served
+	// too, flagged so clients can refuse them, as well as the prices held while
+	// their providers are silent.
+	Price struct {
+		Symbol       string    `json:"symbol"`
+		Price        sdk.Dec   `json:"price"`
+		ComputedAt   time.Time `json:"computed_at"`
+		Stale        bool      `json:"stale"`
+		Interpolated bool      `json:"interpolated"`
+	}
+
+	// PricesResponse is the response of /prices.
+	PricesResponse struct {
+		Prices []Price `json:"prices"`
+	}
+
+	// ProviderStatus is the health of an oracle provider.
+	ProviderStatus struct {
+		Name string `json:"name"`
+		// Connected is false while the provider is sidelined by its circuit
+		// breaker, waits for its ticks to resume after reconnecting or hasn't
+		// sent a new candle for its subscribed pairs in the last 3 minutes.
+		Connected bool `json:"connected"`
+		Sidelined bool `json:"sidelined"`
+		// LastMessage is when the provider last sent a new candle.
+		LastMessage     time.Time `json:"last_message"`
+		SubscribedPairs int       `json:"subscribed_pairs"`
+		AvailablePairs  int       `json:"available_pairs"`
+		// FetchErrors is the number of ticks the provider failed to get both
+		// its ticker prices and its candles.
+		FetchErrors uint64 `json:"fetch_errors"`
+		Reconnects  uint64 `json:"reconnects"`
+		// PairsFailures is the number of consecutive failures to get any of
+		// the available pairs of the provider.
+		PairsFailures int `json:"pairs_failures"`
+	}
+
+	// ProvidersResponse is the response of /providers.
+	ProvidersResponse struct {
+		Providers []ProviderStatus `json:"providers"`
+	}
+
+	// ErrorResponse is the response of a failed API request.
+	ErrorResponse struct {
+		Error string `json:"error"`
+	}
+)
+
+// payloads are a value of each payload, by schema name.
+var payloads = map[string]interface{}{
+	SchemaPolicyAlert:       PolicyAlert{},
+	SchemaTopupAlert:        TopupAlert{},
+	SchemaPriceAlert:        PriceAlert{},
+	SchemaDailySummary:      DailySummary{},
+	SchemaHeartbeatStatus:   HeartbeatStatus{},
+	SchemaPrice:             Price{},
+	SchemaPricesResponse:    PricesResponse{},
+	SchemaProvidersResponse: ProvidersResponse{},
+	SchemaErrorResponse:     ErrorResponse{},
+}
@@ -0,0 +1,143 @@
+package v1
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// schemaDialect is the JSON schema dialect of the generated schemas.
+	schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+	// schemaIDBase is the URL of the checked in schemas.
+	schemaIDBase = "https://github.com/umee-network/peggo/blob/main/orchestrator/payload/v1/schemas/"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Names returns the schema names of the payloads, sorted.
+func Names() []string {
+	names := make([]string, 0, len(payloads))
+	for name := range payloads {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Schema returns the indented JSON schema of the named payload. The output is
+// deterministic, so it can be checked in and diffed.
+func Schema(name string) ([]byte, error) {
+	v, ok := payloads[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown payload schema %q; expected one of %s", name, strings.Join(Names(), ", "))
+	}
+
+	s := typeSchema(reflect.TypeOf(v))
+	s["$schema"] = schemaDialect
+	s["$id"] = schemaIDBase + name + ".json"
+
+	// maps are marshaled with sorted keys
+	bz, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(bz, '\n'), nil
+}
+
+// typeSchema returns the JSON schema of the JSON encoding of a Go type. Types
+// with their own JSON encoding, e.g. sdk.Dec, are expected to encode as
+// strings. Nil slices and maps encode as null.
+func typeSchema(t reflect.Type) map[string]interface{} {
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	for _, marshaler := range []reflect.Type{jsonMarshalerType, textMarshalerType} {
+		if t.Implements(marshaler) || reflect.PtrTo(t).Implements(marshaler) {
+			return map[string]interface{}{"type": "string"}
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeSchema(t.Elem())
+
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+
+	case reflect.Slice:
+		return map[string]interface{}{"type": []string{"array", "null"}, "items": typeSchema(t.Elem())}
+
+	case reflect.Map:
+		return map[string]interface{}{"type": []string{"object", "null"}, "additionalProperties": typeSchema(t.Elem())}
+
+	case reflect.Struct:
+		return structSchema(t)
+
+	default:
+		panic(fmt.Sprintf("no JSON schema for %s", t))
+	}
+}
+
+// structSchema returns the JSON schema of a struct. Fields without omitempty
+// are always encoded, so they're required. Additional properties are allowed,
+// as fields may be added within a version.
+func structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = typeSchema(field.Type)
+
+		omitEmpty := false
+		for _, opt := range strings.Split(opts, ",") {
+			omitEmpty = omitEmpty || opt == "omitempty"
+		}
+		if !omitEmpty {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]interface{}{
+		"title":      t.Name(),
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
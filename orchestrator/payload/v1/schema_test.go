@@ -0,0 +1,182 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkCompatible returns an error if a payload valid against the previous
+// schema isn't valid against the current one from a consumer's point of view:
+// a property was removed, retyped or made optional.
+func checkCompatible(path string, previous, current map[string]interface{}) error {
+	for _, key := range []string{"type", "format"} {
+		if !reflect.DeepEqual(previous[key], current[key]) {
+			return fmt.Errorf("%s: %s changed from %v to %v", path, key, previous[key], current[key])
+		}
+	}
+
+	previousProperties, _ := previous["properties"].(map[string]interface{})
+	currentProperties, _ := current["properties"].(map[string]interface{})
+	for name, p := range previousProperties {
+		c, ok := currentProperties[name]
+		if !ok {
+			return fmt.Errorf("%s.%s was removed", path, name)
+		}
+
+		if err := checkCompatible(path+"."+name, p.(map[string]interface{}), c.(map[string]interface{})); err != nil {
+			return err
+		}
+	}
+
+	currentRequired := map[interface{}]struct{}{}
+	if required, ok := current["required"].([]interface{}); ok {
+		for _, name := range required {
+			currentRequired[name] = struct{}{}
+		}
+	}
+	if required, ok := previous["required"].([]interface{}); ok {
+		for _, name := range required {
+			if _, ok := currentRequired[name]; !ok {
+				return fmt.Errorf("%s.%s is no longer required", path, name)
+			}
+		}
+	}
+
+	for _, key := range []string{"items", "additionalProperties"} {
+		p, ok := previous[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		c, ok := current[key].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: %s was removed", path, key)
+		}
+
+		if err := checkCompatible(path+"[]", p, c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestSchemas(t *testing.T) {
+	for _, name := range Names() {
+		generated, err := Schema(name)
+		require.NoError(t, err)
+
+		checkedIn, err := os.ReadFile(filepath.Join("schemas", name+".json"))
+		require.NoError(t, err, "missing schema of %s; write it with `peggo schema --out schemas`", name)
+
+		var previous, current map[string]interface{}
+		require.NoError(t, json.Unmarshal(checkedIn, &previous))
+		require.NoError(t, json.Unmarshal(generated, &current))
+
+		require.NoError(
+			t,
+			checkCompatible(name, previous, current),
+			"breaking change to a %s payload; add a new version instead", Version,
+		)
+		assert.Equal(
+			t,
+			string(checkedIn),
+			string(generated),
+			"outdated schema of %s; rewrite it with `peggo schema --out schemas`", name,
+		)
+	}
+}
+
+func TestCheckCompatible(t *testing.T) {
+	schema := func(v interface{}) map[string]interface{} {
+		bz, err := json.Marshal(typeSchema(reflect.TypeOf(v)))
+		require.NoError(t, err)
+
+		var s map[string]interface{}
+		require.NoError(t, json.Unmarshal(bz, &s))
+
+		return s
+	}
+
+	type previous struct {
+		Name   string   `json:"name"`
+		Count  int      `json:"count"`
+		Errors []string `json:"errors"`
+	}
+
+	testCases := []struct {
+		name    string
+		current interface{}
+		err     string
+	}{
+		{
+			name: "field added",
+			current: struct {
+				Name   string   `json:"name"`
+				Count  int      `json:"count"`
+				Errors []string `json:"errors"`
+				Extra  bool     `json:"extra,omitempty"`
+			}{},
+		},
+		{
+			name: "field removed",
+			current: struct {
+				Name   string   `json:"name"`
+				Errors []string `json:"errors"`
+			}{},
+			err: "previous.count was removed",
+		},
+		{
+			name: "field retyped",
+			current: struct {
+				Name   string   `json:"name"`
+				Count  string   `json:"count"`
+				Errors []string `json:"errors"`
+			}{},
+			err: "previous.count: type changed from integer to string",
+		},
+		{
+			name: "field made optional",
+			current: struct {
+				Name   string   `json:"name,omitempty"`
+				Count  int      `json:"count"`
+				Errors []string `json:"errors"`
+			}{},
+			err: "previous.name is no longer required",
+		},
+		{
+			name: "items retyped",
+			current: struct {
+				Name   string `json:"name"`
+				Count  int    `json:"count"`
+				Errors []int  `json:"errors"`
+			}{},
+			err: "previous.errors[]: type changed from string to integer",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkCompatible("previous", schema(previous{}), schema(tc.current))
+			if tc.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestSchemaUnknown(t *testing.T) {
+	_, err := Schema("peggo.v1.unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,225 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	payloadv1 "github.com/umee-network/peggo/orchestrator/payload/v1"
+)
+
+const (
+	window      = time.Hour
+	maxRespTime = 15 * time.Second
+)
+
+// ErrViolation is returned instead of a signature when signing a tx would
+// violate the key usage policy.
+var ErrViolation = errors.New("key usage policy violation")
+
+type (
+	// Config bounds the txs the orchestrator's Ethereum key may sign. Zero
+	// values disable the corresponding limit.
+	Config struct {
+		// MaxSpendPerHour is the maximum cost (gas limit times gas price, plus
+		// value) in wei of the txs signed within an hour.
+		MaxSpendPerHour *big.Int
+		// MaxTxsPerHour is the maximum number of txs signed within an hour.
+		MaxTxsPerHour int
+		// AllowedContracts are the only contracts txs may be sent to.
+		AllowedContracts []ethcmn.Address
+		// WebhookURL receives a JSON POST for every violation.
+		WebhookURL string
+		// Labels tag every violation alert (see payloadv1.Labels).
+		Labels payloadv1.Labels
+	}
+
+	// Alert is the violation posted to WebhookURL.
+	Alert = payloadv1.PolicyAlert
+
+	signed struct {
+		time time.Time
+		cost *big.Int
+	}
+
+	// Policy enforces a Config on every tx signature. Every signature counts
+	// towards the hourly limits, including retries of the same tx, so the
+	// limits err on the safe side.
+	Policy struct {
+		logger  zerolog.Logger
+		client  *http.Client
+		config  Config
+		allowed map[ethcmn.Address]struct{}
+		now     func() time.Time
+
+		mtx    sync.Mutex
+		signed []signed
+	}
+)
+
+// New returns a key usage policy enforcing config.
+func New(logger zerolog.Logger, config Config) (*Policy, error) {
+	if config.MaxSpendPerHour != nil && config.MaxSpendPerHour.Sign() < 0 {
+		return nil, errors.New("maximum spend per hour can't be negative")
+	}
+
+	if config.MaxTxsPerHour < 0 {
+		return nil, errors.New("maximum txs per hour can't be negative")
+	}
+
+	p := &Policy{
+		logger: logger.With().Str("module", "key_policy").Logger(),
+		client: &http.Client{Timeout: maxRespTime},
+		config: config,
+		now:    time.Now,
+	}
+
+	if len(config.AllowedContracts) > 0 {
+		p.allowed = make(map[ethcmn.Address]struct{}, len(config.AllowedContracts))
+		for _, addr := range config.AllowedContracts {
+			p.allowed[addr] = struct{}{}
+		}
+	}
+
+	return p, nil
+}
+
+// SignerFn wraps signerFn so that txs violating the policy are not signed.
+func (p *Policy) SignerFn(signerFn bind.SignerFn) bind.SignerFn {
+	return func(from ethcmn.Address, tx *ethtypes.Transaction) (*ethtypes.Transaction, error) {
+		if err := p.Check(from, tx); err != nil {
+			return nil, err
+		}
+
+		return signerFn(from, tx)
+	}
+}
+
+// Check records tx as signed if it complies with the policy. Otherwise it
+// alerts and returns an error wrapping ErrViolation.
+func (p *Policy) Check(from ethcmn.Address, tx *ethtypes.Transaction) error {
+	violation := p.check(tx)
+	if violation == "" {
+		return nil
+	}
+
+	to := ""
+	if tx.To() != nil {
+		to = tx.To().Hex()
+	}
+
+	p.logger.Error().
+		Str("from", from.Hex()).
+		Str("to", to).
+		Uint64("nonce", tx.Nonce()).
+		Str("violation", violation).
+		Msg("refusing to sign tx; key usage policy violated")
+
+	if p.config.WebhookURL != "" {
+		alert := Alert{
+			From:      from.Hex(),
+			To:        to,
+			Nonce:     tx.Nonce(),
+			Violation: violation,
+			Time:      p.now().UTC(),
+			Labels:    p.config.Labels,
+		}
+
+		// don't hold the relayer back while alerting
+		go func() {
+			if err := p.callWebhook(alert); err != nil {
+				p.logger.Err(err).Msg("key usage policy webhook failed")
+			}
+		}()
+	}
+
+	return errors.Wrap(ErrViolation, violation)
+}
+
+// check returns the violation, if any, or records tx as signed.
+func (p *Policy) check(tx *ethtypes.Transaction) string {
+	if p.allowed != nil {
+		if tx.To() == nil {
+			return "contract creation is not allowed"
+		}
+
+		if _, ok := p.allowed[*tx.To()]; !ok {
+			return fmt.Sprintf("destination %s is not allowed", tx.To().Hex())
+		}
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	now := p.now()
+
+	// drop the signatures older than the window
+	recent := p.signed[:0]
+	for _, s := range p.signed {
+		if now.Sub(s.time) < window {
+			recent = append(recent, s)
+		}
+	}
+	p.signed = recent
+
+	if limit := p.config.MaxTxsPerHour; limit > 0 && len(p.signed) >= limit {
+		return fmt.Sprintf("%d txs already signed within the last hour", len(p.signed))
+	}
+
+	cost := tx.Cost()
+
+	if limit := p.config.MaxSpendPerHour; limit != nil && limit.Sign() > 0 {
+		spent := new(big.Int).Set(cost)
+		for _, s := range p.signed {
+			spent.Add(spent, s.cost)
+		}
+
+		if spent.Cmp(limit) > 0 {
+			return fmt.Sprintf("spending %s wei within the last hour exceeds %s wei", spent, limit)
+		}
+	}
+
+	p.signed = append(p.signed, signed{time: now, cost: cost})
+
+	return ""
+}
+
+func (p *Policy) callWebhook(alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), maxRespTime)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(payloadv1.HeaderSchema, payloadv1.SchemaPolicyAlert)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to call key usage policy webhook")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("key usage policy webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
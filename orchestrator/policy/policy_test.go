@@ -0,0 +1,89 @@
+package policy
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTx(to ethcmn.Address, nonce uint64) *ethtypes.Transaction {
+	// costs 100 * 10 = 1000 wei
+	return ethtypes.NewTransaction(nonce, to, nil, 100, big.NewInt(10), nil)
+}
+
+func TestPolicy(t *testing.T) {
+	from := ethcmn.HexToAddress("0x0000000000000000000000000000000000000001")
+	gravity := ethcmn.HexToAddress("0x0000000000000000000000000000000000000002")
+	other := ethcmn.HexToAddress("0x0000000000000000000000000000000000000003")
+
+	alerts := make(chan Alert, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert Alert
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&alert))
+		alerts <- alert
+	}))
+	defer server.Close()
+
+	p, err := New(zerolog.Nop(), Config{
+		MaxSpendPerHour:  big.NewInt(2500),
+		MaxTxsPerHour:    3,
+		AllowedContracts: []ethcmn.Address{gravity},
+		WebhookURL:       server.URL,
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	p.now = func() time.Time { return now }
+
+	signerFn := p.SignerFn(func(_ ethcmn.Address, tx *ethtypes.Transaction) (*ethtypes.Transaction, error) {
+		return tx, nil
+	})
+
+	_, err = signerFn(from, newTx(gravity, 0))
+	require.NoError(t, err)
+	_, err = signerFn(from, newTx(gravity, 1))
+	require.NoError(t, err)
+
+	// not an allowed contract
+	_, err = signerFn(from, newTx(other, 2))
+	require.ErrorIs(t, err, ErrViolation)
+
+	select {
+	case alert := <-alerts:
+		assert.Equal(t, other.Hex(), alert.To)
+		assert.Equal(t, uint64(2), alert.Nonce)
+		assert.Contains(t, alert.Violation, "is not allowed")
+	case <-time.After(5 * time.Second):
+		t.Fatal("no alert was sent")
+	}
+
+	// 3000 wei within the hour
+	_, err = signerFn(from, newTx(gravity, 2))
+	require.ErrorIs(t, err, ErrViolation)
+	<-alerts
+
+	// the first txs are out of the window
+	now = now.Add(time.Hour)
+	_, err = signerFn(from, newTx(gravity, 2))
+	require.NoError(t, err)
+}
+
+func TestPolicyMaxTxs(t *testing.T) {
+	p, err := New(zerolog.Nop(), Config{MaxTxsPerHour: 1})
+	require.NoError(t, err)
+
+	from := ethcmn.HexToAddress("0x0000000000000000000000000000000000000001")
+	to := ethcmn.HexToAddress("0x0000000000000000000000000000000000000002")
+
+	require.NoError(t, p.Check(from, newTx(to, 0)))
+	require.ErrorIs(t, p.Check(from, newTx(to, 1)), ErrViolation)
+}
@@ -0,0 +1,105 @@
+package pricealert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/umee-network/peggo/orchestrator/oracle"
+	payloadv1 "github.com/umee-network/peggo/orchestrator/payload/v1"
+)
+
+const maxRespTime = 15 * time.Second
+
+type (
+	// Config defines where the price alerts are sent.
+	Config struct {
+		// WebhookURL receives a JSON POST for every price alert.
+		WebhookURL string
+		// Labels tag every price alert (see payloadv1.Labels).
+		Labels payloadv1.Labels
+	}
+
+	// Alert is the price event posted to WebhookURL.
+	Alert = payloadv1.PriceAlert
+
+	// Webhook sends the oracle price alerts to a webhook.
+	Webhook struct {
+		logger zerolog.Logger
+		client *http.Client
+		config Config
+	}
+)
+
+// NewWebhook returns a webhook sending the oracle price alerts, to be
+// registered with oracle.OptionPriceAlertHook(webhook.Hook).
+func NewWebhook(logger zerolog.Logger, config Config) (*Webhook, error) {
+	if config.WebhookURL == "" {
+		return nil, errors.New("price alert webhook URL is required")
+	}
+
+	return &Webhook{
+		logger: logger.With().Str("module", "pricealert").Logger(),
+		client: &http.Client{Timeout: maxRespTime},
+		config: config,
+	}, nil
+}
+
+// Hook sends a price alert to the webhook in the background, so the oracle
+// isn't held back while alerting. Failures are logged.
+func (w *Webhook) Hook(alert oracle.PriceAlert) {
+	body := Alert{
+		Kind:     alert.Kind,
+		Symbol:   alert.Symbol,
+		Previous: alert.Previous.String(),
+		Time:     alert.Time.UTC(),
+		Labels:   w.config.Labels,
+	}
+	if !alert.Price.IsNil() {
+		body.Price = alert.Price.String()
+	}
+	if !alert.Change.IsNil() {
+		body.Change = alert.Change.String()
+	}
+
+	go func() {
+		if err := w.callWebhook(body); err != nil {
+			w.logger.Err(err).Str("symbol", body.Symbol).Msg("price alert webhook failed")
+		}
+	}()
+}
+
+func (w *Webhook) callWebhook(alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), maxRespTime)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.config.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(payloadv1.HeaderSchema, payloadv1.SchemaPriceAlert)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to call price alert webhook")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("price alert webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
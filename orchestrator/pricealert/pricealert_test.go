@@ -0,0 +1,81 @@
+package pricealert
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umee-network/peggo/orchestrator/oracle"
+	payloadv1 "github.com/umee-network/peggo/orchestrator/payload/v1"
+)
+
+func TestWebhook(t *testing.T) {
+	alerts := make(chan Alert, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, payloadv1.SchemaPriceAlert, r.Header.Get(payloadv1.HeaderSchema))
+
+		var alert Alert
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&alert))
+		alerts <- alert
+	}))
+	defer server.Close()
+
+	labels := map[string]string{"region": "eu-west-1"}
+	webhook, err := NewWebhook(zerolog.Nop(), Config{WebhookURL: server.URL, Labels: labels})
+	require.NoError(t, err)
+
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	receive := func() Alert {
+		select {
+		case alert := <-alerts:
+			return alert
+		case <-time.After(5 * time.Second):
+			t.Fatal("no alert was sent")
+			return Alert{}
+		}
+	}
+
+	webhook.Hook(oracle.PriceAlert{
+		Kind:     oracle.PriceAlertMove,
+		Symbol:   "ETH",
+		Previous: sdk.NewDec(1500),
+		Price:    sdk.NewDec(1200),
+		Change:   sdk.MustNewDecFromStr("-0.2"),
+		Time:     now,
+	})
+	assert.Equal(t, Alert{
+		Kind:     oracle.PriceAlertMove,
+		Symbol:   "ETH",
+		Previous: "1500.000000000000000000",
+		Price:    "1200.000000000000000000",
+		Change:   "-0.200000000000000000",
+		Time:     now,
+		Labels:   labels,
+	}, receive())
+
+	webhook.Hook(oracle.PriceAlert{
+		Kind:     oracle.PriceAlertMissing,
+		Symbol:   "UMEE",
+		Previous: sdk.MustNewDecFromStr("0.005"),
+		Time:     now,
+	})
+	assert.Equal(t, Alert{
+		Kind:     oracle.PriceAlertMissing,
+		Symbol:   "UMEE",
+		Previous: "0.005000000000000000",
+		Time:     now,
+		Labels:   labels,
+	}, receive())
+}
+
+func TestNewWebhookRequiresURL(t *testing.T) {
+	_, err := NewWebhook(zerolog.Nop(), Config{})
+	assert.Error(t, err)
+}
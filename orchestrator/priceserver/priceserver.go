@@ -0,0 +1,147 @@
+package priceserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+
+	"github.com/umee-network/peggo/orchestrator/oracle"
+	payloadv1 "github.com/umee-network/peggo/orchestrator/payload/v1"
+)
+
+const pricePath = "/price/"
+
+type (
+	// Oracle defines the oracle methods used to serve its prices.
+	Oracle interface {
+		Symbols() []string
+		GetPriceWithTimestamp(baseSymbol string) (sdk.Dec, time.Time, error)
+		IsStale(baseSymbol string) bool
+		IsInterpolated(baseSymbol string) bool
+		ProviderStatus() []oracle.ProviderStatus
+	}
+
+	// Price is the current price of a symbol, in USD.
+	Price = payloadv1.Price
+
+	// PricesResponse is the payloadv1 body served on /prices.
+	PricesResponse = payloadv1.PricesResponse
+
+	// ProvidersResponse is the payloadv1 body served on /providers.
+	ProvidersResponse = payloadv1.ProvidersResponse
+
+	// ErrorResponse is the response of a failed request.
+	ErrorResponse = payloadv1.ErrorResponse
+
+	handler struct {
+		logger zerolog.Logger
+		oracle Oracle
+	}
+)
+
+// NewHandler returns an HTTP handler serving the oracle prices, read-only:
+//
+//   - /prices returns all the prices
+//   - /price/{symbol} returns the price of a symbol (or an alias of it)
+//   - /providers returns the health of the oracle providers
+func NewHandler(logger zerolog.Logger, oracle Oracle) http.Handler {
+	h := &handler{
+		logger: logger.With().Str("module", "price_server").Logger(),
+		oracle: oracle,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/prices", h.handlePrices)
+	mux.HandleFunc(pricePath, h.handlePrice)
+	mux.HandleFunc("/providers", h.handleProviders)
+
+	return mux
+}
+
+func (h *handler) handlePrices(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	res := PricesResponse{Prices: []Price{}}
+	for _, symbol := range h.oracle.Symbols() {
+		price, ok := h.price(symbol)
+		if !ok {
+			// dropped since listed
+			continue
+		}
+
+		res.Prices = append(res.Prices, price)
+	}
+
+	h.writeJSON(w, http.StatusOK, payloadv1.SchemaPricesResponse, res)
+}
+
+func (h *handler) handlePrice(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	symbol := strings.ToUpper(strings.TrimPrefix(r.URL.Path, pricePath))
+	if symbol == "" || strings.Contains(symbol, "/") {
+		h.writeError(w, http.StatusNotFound, "invalid symbol")
+		return
+	}
+
+	price, ok := h.price(symbol)
+	if !ok {
+		h.writeError(w, http.StatusNotFound, "no price for "+symbol)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, payloadv1.SchemaPrice, price)
+}
+
+func (h *handler) handleProviders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	h.writeJSON(
+		w,
+		http.StatusOK,
+		payloadv1.SchemaProvidersResponse,
+		ProvidersResponse{Providers: h.oracle.ProviderStatus()},
+	)
+}
+
+func (h *handler) price(symbol string) (Price, bool) {
+	price, computedAt, err := h.oracle.GetPriceWithTimestamp(symbol)
+	if err != nil {
+		return Price{}, false
+	}
+
+	return Price{
+		Symbol:       symbol,
+		Price:        price,
+		ComputedAt:   computedAt.UTC(),
+		Stale:        h.oracle.IsStale(symbol),
+		Interpolated: h.oracle.IsInterpolated(symbol),
+	}, true
+}
+
+func (h *handler) writeError(w http.ResponseWriter, status int, msg string) {
+	h.writeJSON(w, status, payloadv1.SchemaErrorResponse, ErrorResponse{Error: msg})
+}
+
+func (h *handler) writeJSON(w http.ResponseWriter, status int, schema string, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(payloadv1.HeaderSchema, schema)
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.Err(err).Msg("failed to write response")
+	}
+}
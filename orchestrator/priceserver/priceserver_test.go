@@ -0,0 +1,99 @@
+package priceserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umee-network/peggo/orchestrator/oracle"
+)
+
+type mockOracle struct {
+	prices       map[string]sdk.Dec
+	stale        map[string]bool
+	interpolated map[string]bool
+	time         time.Time
+}
+
+func (m *mockOracle) Symbols() []string {
+	return []string{"ETH", "UMEE"}
+}
+
+func (m *mockOracle) GetPriceWithTimestamp(baseSymbol string) (sdk.Dec, time.Time, error) {
+	price, ok := m.prices[baseSymbol]
+	if !ok {
+		return sdk.Dec{}, time.Time{}, fmt.Errorf("error getting price for %s", baseSymbol)
+	}
+
+	return price, m.time, nil
+}
+
+func (m *mockOracle) IsStale(baseSymbol string) bool {
+	return m.stale[baseSymbol]
+}
+
+func (m *mockOracle) IsInterpolated(baseSymbol string) bool {
+	return m.interpolated[baseSymbol]
+}
+
+func (m *mockOracle) ProviderStatus() []oracle.ProviderStatus {
+	return []oracle.ProviderStatus{{Name: "binance", Connected: true, LastMessage: m.time, SubscribedPairs: 2}}
+}
+
+func TestHandler(t *testing.T) {
+	computedAt := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	o := &mockOracle{
+		prices:       map[string]sdk.Dec{"ETH": sdk.NewDec(1500), "UMEE": sdk.MustNewDecFromStr("0.01")},
+		stale:        map[string]bool{"UMEE": true},
+		interpolated: map[string]bool{"ETH": true},
+		time:         computedAt,
+	}
+
+	server := httptest.NewServer(NewHandler(zerolog.Nop(), o))
+	defer server.Close()
+
+	get := func(path string, v interface{}) int {
+		resp, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+
+		return resp.StatusCode
+	}
+
+	var prices PricesResponse
+	assert.Equal(t, http.StatusOK, get("/prices", &prices))
+	assert.Equal(t, []Price{
+		{Symbol: "ETH", Price: sdk.NewDec(1500), ComputedAt: computedAt, Interpolated: true},
+		{Symbol: "UMEE", Price: sdk.MustNewDecFromStr("0.01"), ComputedAt: computedAt, Stale: true},
+	}, prices.Prices)
+
+	var price Price
+	assert.Equal(t, http.StatusOK, get("/price/eth", &price))
+	assert.Equal(t, Price{Symbol: "ETH", Price: sdk.NewDec(1500), ComputedAt: computedAt, Interpolated: true}, price)
+
+	var providers ProvidersResponse
+	assert.Equal(t, http.StatusOK, get("/providers", &providers))
+	assert.Equal(t, []oracle.ProviderStatus{
+		{Name: "binance", Connected: true, LastMessage: computedAt, SubscribedPairs: 2},
+	}, providers.Providers)
+
+	var errRes ErrorResponse
+	assert.Equal(t, http.StatusNotFound, get("/price/USDC", &errRes))
+	assert.Equal(t, "no price for USDC", errRes.Error)
+
+	resp, err := http.Post(server.URL+"/prices", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
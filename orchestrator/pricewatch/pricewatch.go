@@ -0,0 +1,185 @@
+package pricewatch
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	oracletypes "github.com/umee-network/umee/v3/x/oracle/types"
+
+	"github.com/umee-network/peggo/orchestrator/loops"
+)
+
+type (
+	// PriceGetter returns the USD price peggo aggregated for a symbol.
+	PriceGetter interface {
+		GetPrice(baseSymbol string) (sdk.Dec, error)
+	}
+
+	// Config defines how often the prices are compared, how much they may
+	// differ and for how long a divergence must last before raising an alert.
+	Config struct {
+		Interval  time.Duration
+		Tolerance float64
+		Sustain   time.Duration
+	}
+
+	// Divergence describes a symbol whose local price differs from the chain
+	// oracle exchange rate by more than the tolerance.
+	Divergence struct {
+		Symbol     string
+		LocalPrice sdk.Dec
+		ChainPrice sdk.Dec
+		Deviation  sdk.Dec
+		Since      time.Time
+	}
+
+	// Watchdog periodically compares the prices peggo computes with the
+	// exchange rates of the chain's x/oracle module.
+	Watchdog struct {
+		logger        zerolog.Logger
+		config        Config
+		tolerance     sdk.Dec
+		oracleQuerier oracletypes.QueryClient
+		prices        PriceGetter
+
+		mtx sync.Mutex
+		// since tracks when each currently diverging symbol started diverging.
+		since map[string]time.Time
+	}
+)
+
+// NewWatchdog returns a price feed watchdog.
+func NewWatchdog(
+	logger zerolog.Logger,
+	config Config,
+	oracleQuerier oracletypes.QueryClient,
+	prices PriceGetter,
+) (*Watchdog, error) {
+	if config.Interval <= 0 {
+		return nil, fmt.Errorf("invalid price watchdog interval: %s", config.Interval)
+	}
+
+	if config.Tolerance <= 0 || config.Tolerance >= 1 {
+		return nil, fmt.Errorf("invalid price watchdog tolerance: %v", config.Tolerance)
+	}
+
+	if config.Sustain < 0 {
+		return nil, fmt.Errorf("invalid price watchdog sustain duration: %s", config.Sustain)
+	}
+
+	tolerance, err := sdk.NewDecFromStr(strconv.FormatFloat(config.Tolerance, 'f', -1, 64))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid price watchdog tolerance")
+	}
+
+	return &Watchdog{
+		logger:        logger.With().Str("module", "pricewatch").Logger(),
+		config:        config,
+		tolerance:     tolerance,
+		oracleQuerier: oracleQuerier,
+		prices:        prices,
+		since:         map[string]time.Time{},
+	}, nil
+}
+
+// Start compares the prices every interval until the context is done. Only
+// divergences that lasted for the sustain duration are logged as errors, so a
+// short lived spike of a single provider doesn't raise an alert.
+func (w *Watchdog) Start(ctx context.Context) error {
+	return loops.RunLoop(ctx, w.logger, w.config.Interval, func() error {
+		now := time.Now()
+
+		divergences, err := w.Check(ctx, now)
+		if err != nil {
+			w.logger.Err(err).Msg("failed to compare prices against the chain oracle")
+			return nil
+		}
+
+		for _, d := range divergences {
+			if now.Sub(d.Since) < w.config.Sustain {
+				continue
+			}
+
+			w.logger.Error().
+				Str("symbol", d.Symbol).
+				Str("local_price", d.LocalPrice.String()).
+				Str("chain_price", d.ChainPrice.String()).
+				Str("deviation", d.Deviation.String()).
+				Time("since", d.Since).
+				Msg("price diverges from the chain oracle exchange rate")
+		}
+
+		return nil
+	})
+}
+
+// Check compares the local price of every symbol priced by the chain oracle and
+// returns the ones deviating more than the tolerance, along with when they
+// started diverging. Symbols peggo doesn't price are skipped.
+func (w *Watchdog) Check(ctx context.Context, now time.Time) ([]Divergence, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.config.Interval)
+	defer cancel()
+
+	res, err := w.oracleQuerier.ExchangeRates(ctx, &oracletypes.QueryExchangeRates{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the chain oracle exchange rates")
+	}
+
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	var divergences []Divergence
+	diverging := make(map[string]struct{}, len(w.since))
+
+	for _, rate := range res.ExchangeRates {
+		if !rate.Amount.IsPositive() {
+			continue
+		}
+
+		symbol := strings.ToUpper(rate.Denom)
+
+		price, err := w.prices.GetPrice(symbol)
+		if err != nil {
+			continue
+		}
+
+		deviation := price.Sub(rate.Amount).Abs().Quo(rate.Amount)
+		if deviation.LTE(w.tolerance) {
+			continue
+		}
+
+		since, ok := w.since[symbol]
+		if !ok {
+			since = now
+		}
+		diverging[symbol] = struct{}{}
+
+		divergences = append(divergences, Divergence{
+			Symbol:     symbol,
+			LocalPrice: price,
+			ChainPrice: rate.Amount,
+			Deviation:  deviation,
+			Since:      since,
+		})
+	}
+
+	for symbol, since := range w.since {
+		if _, ok := diverging[symbol]; !ok {
+			w.logger.Debug().Str("symbol", symbol).Time("since", since).Msg("price converged with the chain oracle")
+			delete(w.since, symbol)
+		}
+	}
+
+	for _, d := range divergences {
+		w.since[d.Symbol] = d.Since
+	}
+
+	return divergences, nil
+}
@@ -0,0 +1,100 @@
+package pricewatch
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	oracletypes "github.com/umee-network/umee/v3/x/oracle/types"
+	"google.golang.org/grpc"
+)
+
+type mockOracleQuerier struct {
+	oracletypes.QueryClient
+
+	rates sdk.DecCoins
+}
+
+func (q *mockOracleQuerier) ExchangeRates(
+	_ context.Context,
+	_ *oracletypes.QueryExchangeRates,
+	_ ...grpc.CallOption,
+) (*oracletypes.QueryExchangeRatesResponse, error) {
+	return &oracletypes.QueryExchangeRatesResponse{ExchangeRates: q.rates}, nil
+}
+
+type mockPrices map[string]sdk.Dec
+
+func (p mockPrices) GetPrice(baseSymbol string) (sdk.Dec, error) {
+	price, ok := p[baseSymbol]
+	if !ok {
+		return sdk.Dec{}, fmt.Errorf("error getting price for %s", baseSymbol)
+	}
+
+	return price, nil
+}
+
+func TestCheck(t *testing.T) {
+	querier := &mockOracleQuerier{
+		rates: sdk.NewDecCoins(
+			sdk.NewDecCoinFromDec("ATOM", sdk.NewDec(10)),
+			sdk.NewDecCoinFromDec("UMEE", sdk.MustNewDecFromStr("0.005")),
+			sdk.NewDecCoinFromDec("OSMO", sdk.NewDec(1)),
+		),
+	}
+
+	prices := mockPrices{
+		"ATOM": sdk.MustNewDecFromStr("10.2"),
+		"UMEE": sdk.MustNewDecFromStr("0.006"),
+		// OSMO isn't priced by peggo
+	}
+
+	w, err := NewWatchdog(zerolog.Nop(), Config{Interval: time.Minute, Tolerance: 0.05}, querier, prices)
+	require.NoError(t, err)
+
+	start := time.Now()
+
+	divergences, err := w.Check(context.Background(), start)
+	require.NoError(t, err)
+	require.Len(t, divergences, 1)
+	assert.Equal(t, "UMEE", divergences[0].Symbol)
+	assert.Equal(t, sdk.MustNewDecFromStr("0.2"), divergences[0].Deviation)
+	assert.Equal(t, start, divergences[0].Since)
+
+	// a sustained divergence keeps its start time
+	divergences, err = w.Check(context.Background(), start.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, divergences, 1)
+	assert.Equal(t, start, divergences[0].Since)
+
+	// a converged price starts over
+	prices["UMEE"] = sdk.MustNewDecFromStr("0.005")
+	divergences, err = w.Check(context.Background(), start.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, divergences)
+
+	prices["UMEE"] = sdk.MustNewDecFromStr("0.004")
+	divergences, err = w.Check(context.Background(), start.Add(3*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, divergences, 1)
+	assert.Equal(t, start.Add(3*time.Minute), divergences[0].Since)
+}
+
+func TestNewWatchdogValidation(t *testing.T) {
+	_, err := NewWatchdog(zerolog.Nop(), Config{Interval: 0, Tolerance: 0.05}, nil, nil)
+	assert.Error(t, err)
+
+	_, err = NewWatchdog(zerolog.Nop(), Config{Interval: time.Minute, Tolerance: 0}, nil, nil)
+	assert.Error(t, err)
+
+	_, err = NewWatchdog(zerolog.Nop(), Config{Interval: time.Minute, Tolerance: 1}, nil, nil)
+	assert.Error(t, err)
+
+	_, err = NewWatchdog(zerolog.Nop(), Config{Interval: time.Minute, Tolerance: 0.05, Sustain: -time.Minute}, nil, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,35 @@
+// Package rawgrpc invokes the unary methods of gRPC services peggo doesn't
+// depend on the generated code of, e.g. the Osmosis queries or a threshold
+// signing service. Their messages are hand-written structs, encoded through
+// their protobuf struct tags, which implement proto.Message with Reset, String
+// and ProtoMessage methods.
+package rawgrpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// InvokeFn invokes the given unary gRPC method, like grpc.ClientConn.Invoke.
+// Tests replace it with a fake service.
+type InvokeFn func(ctx context.Context, method string, req, reply interface{}) error
+
+// Dial connects to the gRPC service at addr and returns a function invoking its
+// methods. The connection is closed once the context is done.
+func Dial(ctx context.Context, addr string, creds credentials.TransportCredentials) (InvokeFn, error) {
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	return func(ctx context.Context, method string, req, reply interface{}) error {
+		return conn.Invoke(ctx, method, req, reply)
+	}, nil
+}
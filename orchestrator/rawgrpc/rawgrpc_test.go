@@ -0,0 +1,71 @@
+package rawgrpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type (
+	echoRequest struct {
+		Text  string `protobuf:"bytes,1,opt,name=text,proto3"`
+		Times uint64 `protobuf:"varint,2,opt,name=times,proto3"`
+	}
+
+	echoResponse struct {
+		Texts []string `protobuf:"bytes,1,rep,name=texts,proto3"`
+	}
+)
+
+func (m *echoRequest) Reset()         { *m = echoRequest{} }
+func (m *echoRequest) String() string { return fmt.Sprintf("%+v", *m) }
+func (*echoRequest) ProtoMessage()    {}
+
+func (m *echoResponse) Reset()         { *m = echoResponse{} }
+func (m *echoResponse) String() string { return fmt.Sprintf("%+v", *m) }
+func (*echoResponse) ProtoMessage()    {}
+
+func TestDial(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	// serves any method, as there's no generated service to register
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+		method, _ := grpc.MethodFromServerStream(stream)
+		if method != "/test.Echo/Echo" {
+			return fmt.Errorf("unexpected method %s", method)
+		}
+
+		req := &echoRequest{}
+		if err := stream.RecvMsg(req); err != nil {
+			return err
+		}
+
+		res := &echoResponse{}
+		for i := uint64(0); i < req.Times; i++ {
+			res.Texts = append(res.Texts, req.Text)
+		}
+
+		return stream.SendMsg(res)
+	}))
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	invoke, err := Dial(ctx, lis.Addr().String(), insecure.NewCredentials())
+	require.NoError(t, err)
+
+	res := &echoResponse{}
+	require.NoError(t, invoke(ctx, "/test.Echo/Echo", &echoRequest{Text: "peggo", Times: 2}, res))
+	assert.Equal(t, []string{"peggo", "peggo"}, res.Texts)
+
+	require.Error(t, invoke(ctx, "/test.Echo/Other", &echoRequest{}, res))
+}
@@ -6,9 +6,14 @@ import (
 	"sort"
 
 	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/committer"
 	"github.com/umee-network/peggo/orchestrator/oracle"
+	"github.com/umee-network/peggo/orchestrator/totals"
 )
 
 type SubmittableBatch struct {
@@ -119,6 +124,15 @@ func (s *gravityRelayer) RelayBatches(
 	}
 
 	ethBlockHeight := lastEthereumHeader.Number.Uint64()
+	s.forgetBatchesSeen(possibleBatches)
+
+	pendingBatches := map[string]struct{}{}
+	for _, batches := range possibleBatches {
+		for _, batch := range batches {
+			pendingBatches[batchRelayKey(batch.Batch)] = struct{}{}
+		}
+	}
+	s.forgetNotPivotal("batch", pendingBatches)
 
 	for tokenContract, batches := range possibleBatches {
 
@@ -136,6 +150,8 @@ func (s *gravityRelayer) RelayBatches(
 			return err
 		}
 
+		s.forgetBatchApprovals(tokenContract, latestEthereumBatch.Uint64(), batches, ethBlockHeight)
+
 		// Now we iterate through batches per token type.
 		for _, batch := range batches {
 			if batch.Batch.BatchTimeout < ethBlockHeight {
@@ -153,6 +169,31 @@ func (s *gravityRelayer) RelayBatches(
 				continue
 			}
 
+			if addr, denied := s.deniedDestination(batch.Batch); denied {
+				s.logger.Warn().
+					Uint64("batch_nonce", batch.Batch.BatchNonce).
+					Str("token_contract", batch.Batch.TokenContract).
+					Str("dest_address", addr).
+					Msg("batch has a transfer to a denylisted address; skipping")
+				continue
+			}
+
+			if s.heldForApproval(ctx, batch.Batch) {
+				continue
+			}
+
+			if !s.shouldRelay(batchRelayKey(batch.Batch), currentValset, batchSigners(batch.Signatures)) {
+				s.logger.Debug().
+					Uint64("batch_nonce", batch.Batch.BatchNonce).
+					Str("token_contract", batch.Batch.TokenContract).
+					Msg("our confirm isn't required to pass the power threshold; skipping batch")
+				continue
+			}
+
+			if s.waitingForPreferred(currentValset, batch.Batch) {
+				continue
+			}
+
 			txData, err := s.gravityContract.EncodeTransactionBatch(ctx, currentValset, batch.Batch, batch.Signatures)
 			if err != nil {
 				s.logger.Err(err).Msg("failed to encode transaction batch")
@@ -163,6 +204,10 @@ func (s *gravityRelayer) RelayBatches(
 				continue
 			}
 
+			if !s.paysOurRewards(batch.Batch, txData) {
+				continue
+			}
+
 			estimatedGasCost, gasPrice, err := s.gravityContract.EstimateGas(ctx, s.gravityContract.Address(), txData)
 			if err != nil {
 				s.logger.Err(err).Msg("failed to estimate gas cost")
@@ -172,10 +217,37 @@ func (s *gravityRelayer) RelayBatches(
 			}
 
 			// If the batch is not profitable, move on to the next one.
-			if !s.IsBatchProfitable(ctx, batch.Batch, estimatedGasCost, gasPrice, s.profitMultiplier) {
+			profitGasPrice := s.profitabilityGasPrice(gasPrice)
+			isProfitable, feesUSD := s.batchProfitability(ctx, batch.Batch, estimatedGasCost, profitGasPrice, s.profitMultiplier)
+			if !isProfitable {
 				continue
 			}
 
+			if s.relayJitter > 0 {
+				if err := s.waitRelayJitter(ctx); err != nil {
+					return err
+				}
+
+				// Another relayer may have submitted the batch while we waited.
+				latestEthereumBatch, err = s.gravityContract.GetTxBatchNonce(
+					ctx,
+					tokenContract,
+					s.gravityContract.FromAddress(),
+				)
+				if err != nil {
+					s.logger.Err(err).Msg("failed to get latest Ethereum batch")
+					return err
+				}
+
+				if batch.Batch.BatchNonce <= latestEthereumBatch.Uint64() {
+					s.logger.Debug().
+						Uint64("batch_nonce", batch.Batch.BatchNonce).
+						Str("token_contract", batch.Batch.TokenContract).
+						Msg("batch was relayed by another relayer during the jitter delay")
+					continue
+				}
+			}
+
 			// Checking in pending txs(mempool) if tx with same input is already submitted
 			// We have to check this at the last moment because any other relayer could have submitted.
 			if s.gravityContract.IsPendingTxInput(txData, s.pendingTxWait) {
@@ -184,18 +256,42 @@ func (s *gravityRelayer) RelayBatches(
 				continue
 			}
 
+			// relaying may have been paused while the previous batches were sent
+			if s.isPaused() {
+				return nil
+			}
+
 			s.logger.Info().
 				Uint64("latest_batch", batch.Batch.BatchNonce).
 				Uint64("latest_ethereum_batch", latestEthereumBatch.Uint64()).
 				Msg("we have detected a newer profitable batch; sending an update")
 
+			if s.metaTx != nil {
+				ref, err := s.metaTx.Submit(ctx, s.gravityContract.Address(), txData, estimatedGasCost)
+				if err != nil {
+					s.logger.Err(err).Msg("failed to submit (Gravity submitBatch) to the meta-transaction service")
+					continue
+				}
+
+				// The service pays the gas, so nothing is added to the relayed totals.
+				s.logger.Info().Str("tx_ref", ref).Msg("submitted Tx (Gravity submitBatch) to the meta-transaction service")
+				s.lastSentBatchNonce = batch.Batch.BatchNonce
+				continue
+			}
+
 			txHash, err := s.gravityContract.SendTx(ctx, s.gravityContract.Address(), txData, estimatedGasCost, gasPrice)
+			if errors.Is(err, committer.ErrMaxInFlightTxs) {
+				s.logger.Warn().Err(err).Msg("too many in-flight txs; waiting before relaying more batches")
+				return nil
+			}
 			if err != nil {
 				s.logger.Err(err).Str("tx_hash", txHash.Hex()).Msg("failed to sign and submit (Gravity submitBatch) to EVM")
 				continue
 			}
 
 			s.logger.Info().Str("tx_hash", txHash.Hex()).Msg("sent Tx (Gravity submitBatch)")
+			s.totals.AddRelayed(totals.RelayedBatch, txHash.Hex())
+			s.recordRelay(ctx, batch.Batch, txHash, estimatedGasCost, gasPrice, feesUSD)
 
 			// Update our local tracker of the latest batch.
 			s.lastSentBatchNonce = batch.Batch.BatchNonce
@@ -206,9 +302,19 @@ func (s *gravityRelayer) RelayBatches(
 	return nil
 }
 
-// IsBatchProfitable gets the current prices in USD of ETH and the ERC20 token and compares the value of the estimated
-// gas cost of the transaction to the fees paid by the batch. If the estimated gas cost is greater than the batch's
-// fees, the batch is not profitable and should not be submitted.
+func batchSigners(confirms []types.MsgConfirmBatch) []string {
+	signers := make([]string, len(confirms))
+	for i, c := range confirms {
+		signers[i] = c.EthSigner
+	}
+
+	return signers
+}
+
+// IsBatchProfitable gets the current prices in USD of ETH and the ERC20 fee tokens and compares the value of the
+// estimated gas cost of the transaction to the fees paid by the batch. If the estimated gas cost is greater than the
+// batch's fees, the batch is not profitable and should not be submitted. The transferred token doesn't need a price
+// when the fees are paid in another token.
 func (s *gravityRelayer) IsBatchProfitable(
 	ctx context.Context,
 	batch types.OutgoingTxBatch,
@@ -216,83 +322,216 @@ func (s *gravityRelayer) IsBatchProfitable(
 	gasPrice *big.Int,
 	profitMultiplier float64,
 ) bool {
+	isProfitable, _ := s.batchProfitability(ctx, batch, ethGasCost, gasPrice, profitMultiplier)
+	return isProfitable
+}
+
+// batchProfitability implements IsBatchProfitable, also returning the fees of
+// the batch in USD. They are zero when the batch couldn't be priced.
+func (s *gravityRelayer) batchProfitability(
+	ctx context.Context,
+	batch types.OutgoingTxBatch,
+	ethGasCost uint64,
+	gasPrice *big.Int,
+	profitMultiplier float64,
+) (bool, decimal.Decimal) {
 	if s.symbolRetriever == nil || s.oracle == nil || profitMultiplier == 0 {
-		return true
+		return true, decimal.Zero
 	}
 
 	// First we get the cost of the transaction in USD
-	usdEthPrice, err := s.oracle.GetPrice(oracle.SymbolETH)
+	gasAsset := s.GetGasAssetSymbol()
+	usdEthPriceDec, err := s.getPrice(ctx, gasAsset)
 	if err != nil {
-		s.logger.Err(err).Msg("failed to get ETH price")
-		return false
+		return s.missingPriceFallback(err, gasAsset, batch), decimal.Zero
 	}
-	usdEthPriceDec, err := decimal.NewFromString(usdEthPrice.String())
+
+	gasCostInUSDDec, err := s.convertValue(totalGasCost(ethGasCost, gasPrice), oracle.DecimalsETH, gasAsset)
 	if err != nil {
-		s.logger.Err(err).Msg("failed to parse ETH price")
-		return false
+		return s.missingPriceFallback(err, gasAsset, batch), decimal.Zero
 	}
-	totalETHcost := big.NewInt(0).Mul(gasPrice, big.NewInt(int64(ethGasCost)))
 
-	// Ethereum decimals are 18 and that's a constant.
-	gasCostInUSDDec := decimal.NewFromBigInt(totalETHcost, -18).Mul(usdEthPriceDec)
+	// Then we get the fees of the batch in USD. They may be paid in a token other
+	// than the transferred one, so each fee token is priced on its own.
+	totalFeeInUSDDec := decimal.Zero
+	feeTokenPrices := make(map[string]decimal.Decimal)
 
-	// Then we get the fees of the batch in USD
-	decimals, err := s.gravityContract.GetERC20Decimals(
-		ctx,
-		ethcmn.HexToAddress(batch.TokenContract),
-		s.gravityContract.FromAddress(),
-	)
-	if err != nil {
-		s.logger.Err(err).Str("token_contract", batch.TokenContract).Msg("failed to get token decimals")
-		return false
+	for _, fee := range batchFeesByToken(batch) {
+		decimals, err := s.gravityContract.GetERC20Decimals(
+			ctx,
+			fee.contract,
+			s.gravityContract.FromAddress(),
+		)
+		if err != nil {
+			s.logger.Err(err).Str("token_contract", fee.contract.Hex()).Msg("failed to get token decimals")
+			return false, decimal.Zero
+		}
+
+		s.logger.Debug().
+			Uint8("decimals", decimals).
+			Str("token_contract", fee.contract.Hex()).
+			Msg("got token decimals")
+
+		tokenSymbol, err := s.symbolRetriever.GetTokenSymbol(fee.contract)
+		if err != nil {
+			return false, decimal.Zero
+		}
+
+		usdTokenPriceDec, err := s.getPrice(ctx, tokenSymbol)
+		if err != nil {
+			return s.missingPriceFallback(err, tokenSymbol, batch), decimal.Zero
+		}
+
+		feeInUSDDec, err := s.convertValue(fee.amount, decimals, tokenSymbol)
+		if err != nil {
+			return s.missingPriceFallback(err, tokenSymbol, batch), decimal.Zero
+		}
+
+		s.logger.Debug().
+			Str("fee_token_contract", fee.contract.Hex()).
+			Str("token_price_in_usd", usdTokenPriceDec.String()).
+			Str("fees", fee.amount.String()).
+			Float64("fees_in_usd", feeInUSDDec.InexactFloat64()).
+			Msg("priced batch fees")
+
+		totalFeeInUSDDec = totalFeeInUSDDec.Add(feeInUSDDec)
+		feeTokenPrices[tokenSymbol] = usdTokenPriceDec
+	}
+
+	requiredMultiplier := decimal.NewFromFloat(profitMultiplier)
+	if s.priceBreaker != nil {
+		// All prices must be observed, so we don't short-circuit here.
+		ethUnstable := s.priceBreaker.observe(gasAsset, usdEthPriceDec)
+		tokenUnstable := false
+		for tokenSymbol, usdTokenPriceDec := range feeTokenPrices {
+			if s.priceBreaker.observe(tokenSymbol, usdTokenPriceDec) {
+				tokenUnstable = true
+			}
+		}
+
+		if ethUnstable || tokenUnstable {
+			requiredMultiplier = requiredMultiplier.Mul(decimal.NewFromInt(1).Add(s.priceBreaker.extraMargin))
+
+			s.logger.Warn().
+				Str("token_contract", batch.TokenContract).
+				Bool("eth_price_unstable", ethUnstable).
+				Bool("token_price_unstable", tokenUnstable).
+				Str("required_profit_multiplier", requiredMultiplier.String()).
+				Msg("price deviation circuit breaker tripped; only relaying batches with a wider profit margin")
+		}
 	}
 
+	// Simplified: totalFee > (gasCost * profitMultiplier).
+	isProfitable := totalFeeInUSDDec.GreaterThanOrEqual(gasCostInUSDDec.Mul(requiredMultiplier))
+
 	s.logger.Debug().
-		Uint8("decimals", decimals).
 		Str("token_contract", batch.TokenContract).
-		Msg("got token decimals")
+		Int("fee_tokens", len(feeTokenPrices)).
+		Float64("total_fee_in_usd", totalFeeInUSDDec.InexactFloat64()).
+		Float64("gas_cost_in_usd", gasCostInUSDDec.InexactFloat64()).
+		Float64("profit_multiplier", profitMultiplier).
+		Bool("is_profitable", isProfitable).
+		Msg("checking if batch is profitable")
 
-	tokenSymbol, err := s.symbolRetriever.GetTokenSymbol(ethcmn.HexToAddress(batch.TokenContract))
-	if err != nil {
-		return false
-	}
+	return isProfitable, totalFeeInUSDDec
+}
 
-	if err := s.oracle.SubscribeSymbols(tokenSymbol); err != nil {
-		return false
-	}
+// convertValue returns the USD value of an amount of a token, expressed in its
+// smallest unit, at the oracle price, smoothed if price smoothing is set.
+func (s *gravityRelayer) convertValue(amount *big.Int, decimals uint8, symbol string) (decimal.Decimal, error) {
+	if s.priceSmoothing > 0 {
+		price, err := s.oracle.GetSmoothedPrice(symbol, s.priceSmoothing)
+		if err != nil {
+			return decimal.Decimal{}, err
+		}
 
-	usdTokenPrice, err := s.oracle.GetPrice(tokenSymbol)
-	if err != nil {
-		return false
+		return decimal.NewFromString(oracle.USDValue(sdk.NewIntFromBigInt(amount), decimals, price).String())
 	}
 
-	usdTokenPriceDec, err := decimal.NewFromString(usdTokenPrice.String())
+	value, err := s.oracle.ConvertValue(sdk.NewIntFromBigInt(amount), decimals, symbol)
 	if err != nil {
-		return false
+		return decimal.Decimal{}, err
 	}
 
-	// We calculate the total fee in ERC20 tokens
+	return decimal.NewFromString(value.String())
+}
+
+// totalGasCost returns the cost in wei of the gas used by a transaction.
+func totalGasCost(ethGasCost uint64, gasPrice *big.Int) *big.Int {
+	return big.NewInt(0).Mul(gasPrice, big.NewInt(int64(ethGasCost)))
+}
+
+// batchTotalFees returns the total fees of a batch, in ERC20 tokens.
+func batchTotalFees(batch types.OutgoingTxBatch) *big.Int {
 	totalBatchFees := big.NewInt(0)
 	for _, tx := range batch.Transactions {
 		totalBatchFees = totalBatchFees.Add(tx.Erc20Fee.Amount.BigInt(), totalBatchFees)
 	}
 
-	// Decimals (uint8) can be safely casted into int32 because the max uint8 is 255 and the max int32 is 2147483647.
-	totalFeeInUSDDec := decimal.NewFromBigInt(totalBatchFees, -int32(decimals)).Mul(usdTokenPriceDec)
+	return totalBatchFees
+}
 
-	// Simplified: totalFee > (gasCost * profitMultiplier).
-	isProfitable := totalFeeInUSDDec.GreaterThanOrEqual(gasCostInUSDDec.Mul(decimal.NewFromFloat(profitMultiplier)))
+// batchFee is the total fees paid in a token by the transactions of a batch.
+type batchFee struct {
+	contract ethcmn.Address
+	amount   *big.Int
+}
 
-	s.logger.Debug().
-		Str("token_contract", batch.TokenContract).
-		Str("token_price_in_usd", usdTokenPrice.String()).
-		Int64("total_fees", totalBatchFees.Int64()).
-		Float64("total_fee_in_usd", totalFeeInUSDDec.InexactFloat64()).
-		Float64("gas_cost_in_usd", gasCostInUSDDec.InexactFloat64()).
-		Float64("profit_multiplier", profitMultiplier).
-		Bool("is_profitable", isProfitable).
-		Msg("checking if batch is profitable")
+// batchFeesByToken returns the total fees of a batch per fee token, sorted by
+// token contract. Fees without a token contract are paid in the transferred
+// token.
+func batchFeesByToken(batch types.OutgoingTxBatch) []batchFee {
+	totals := make(map[ethcmn.Address]*big.Int)
 
-	return isProfitable
+	for _, tx := range batch.Transactions {
+		contract := ethcmn.HexToAddress(batch.TokenContract)
+		if tx.Erc20Fee.Contract != "" {
+			contract = ethcmn.HexToAddress(tx.Erc20Fee.Contract)
+		}
+
+		if _, ok := totals[contract]; !ok {
+			totals[contract] = big.NewInt(0)
+		}
+		totals[contract].Add(totals[contract], tx.Erc20Fee.Amount.BigInt())
+	}
+
+	fees := make([]batchFee, 0, len(totals))
+	for contract, amount := range totals {
+		fees = append(fees, batchFee{contract: contract, amount: amount})
+	}
+	sort.Slice(fees, func(i, j int) bool { return fees[i].contract.Hex() < fees[j].contract.Hex() })
+
+	return fees
+}
+
+// historicalUSDValue returns the USD value of an amount of a token, expressed in
+// its smallest unit, at a price that isn't the current oracle one.
+func historicalUSDValue(amount *big.Int, decimals uint8, price decimal.Decimal) decimal.Decimal {
+	usdPrice, err := sdk.NewDecFromStr(price.StringFixed(sdk.Precision))
+	if err != nil {
+		return decimal.Zero
+	}
+
+	value, err := decimal.NewFromString(oracle.USDValue(sdk.NewIntFromBigInt(amount), decimals, usdPrice).String())
+	if err != nil {
+		return decimal.Zero
+	}
+
+	return value
+}
+
+// missingPriceFallback logs a price that couldn't be obtained and returns the
+// configured fallback decision for the batch.
+func (s *gravityRelayer) missingPriceFallback(err error, symbol string, batch types.OutgoingTxBatch) bool {
+	// Stale prices are always refused, regardless of the fallback.
+	allow := s.allowMissingPrice() && !errors.Is(err, oracle.ErrStalePrice)
+
+	s.logger.Err(err).
+		Str("symbol", symbol).
+		Str("token_contract", batch.TokenContract).
+		Uint64("batch_nonce", batch.BatchNonce).
+		Bool("relay_anyway", allow).
+		Msg("failed to get price; using the missing price fallback")
 
+	return allow
 }
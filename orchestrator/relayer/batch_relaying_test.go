@@ -10,6 +10,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/ethereum/go-ethereum"
@@ -26,6 +27,7 @@ import (
 	"github.com/umee-network/peggo/orchestrator/coingecko"
 	"github.com/umee-network/peggo/orchestrator/ethereum/committer"
 	"github.com/umee-network/peggo/orchestrator/ethereum/gravity"
+	"github.com/umee-network/peggo/orchestrator/oracle"
 )
 
 type mockOracle struct {
@@ -40,10 +42,29 @@ func (m mockOracle) GetPrice(baseSymbol string) (sdk.Dec, error) {
 	return m.prices[baseSymbol], nil
 }
 
+func (m mockOracle) GetSmoothedPrice(baseSymbol string, _ time.Duration) (sdk.Dec, error) {
+	return m.prices[baseSymbol], nil
+}
+
+func (m mockOracle) IsStale(baseSymbol string) bool {
+	return false
+}
+
+func (m mockOracle) ConvertValue(amount sdk.Int, decimals uint8, symbol string) (sdk.Dec, error) {
+	return oracle.USDValue(amount, decimals, m.prices[symbol]), nil
+}
+
 func (m mockOracle) SubscribeSymbols(baseSymbols ...string) error {
 	return nil
 }
 
+func (m mockOracle) Ready() <-chan struct{} {
+	ready := make(chan struct{})
+	close(ready)
+
+	return ready
+}
+
 func NewMockOracle() Oracle {
 	return mockOracle{
 		prices: map[string]sdk.Dec{
@@ -504,4 +525,132 @@ func TestRelayBatches(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, uint64(0), relayer.lastSentBatchNonce)
 	})
+
+	t.Run("relayed by another relayer during the jitter delay, no error", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+		mockQClient := mocks.NewMockQueryClient(mockCtrl)
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		mockGravityContract := gravityMocks.NewMockContract(mockCtrl)
+
+		gravityAddress := ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
+		fromAddress := ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e03e53415d37aa96045")
+
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&ethtypes.Header{
+			Number: big.NewInt(112),
+		}, nil)
+
+		mockGravityContract.EXPECT().FromAddress().Return(fromAddress).AnyTimes()
+		gomock.InOrder(
+			mockGravityContract.EXPECT().GetTxBatchNonce(gomock.Any(), gomock.Any(), gomock.Any()).Return(big.NewInt(1), nil),
+			mockGravityContract.EXPECT().GetTxBatchNonce(gomock.Any(), gomock.Any(), gomock.Any()).Return(big.NewInt(2), nil),
+		)
+		mockGravityContract.EXPECT().EncodeTransactionBatch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil)
+		mockGravityContract.EXPECT().Address().Return(gravityAddress).AnyTimes()
+		mockGravityContract.EXPECT().EstimateGas(gomock.Any(), gomock.Any(), gomock.Any()).Return(uint64(99999), big.NewInt(1), nil)
+
+		relayer := gravityRelayer{
+			logger:            logger,
+			cosmosQueryClient: mockQClient,
+			gravityContract:   mockGravityContract,
+			ethProvider:       ethProvider,
+		}
+		SetRelayJitter(time.Millisecond)(&relayer)
+
+		possibleBatches := map[ethcmn.Address][]SubmittableBatch{
+			ethcmn.HexToAddress("0x0"): {
+				{
+					Batch: types.OutgoingTxBatch{
+						BatchTimeout: 113,
+						BatchNonce:   2,
+					},
+					Signatures: []types.MsgConfirmBatch{},
+				},
+			},
+		}
+
+		err := relayer.RelayBatches(context.Background(), types.Valset{}, possibleBatches)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(0), relayer.lastSentBatchNonce)
+	})
+
+	t.Run("submitted to the meta-transaction service, no error", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+		mockQClient := mocks.NewMockQueryClient(mockCtrl)
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		mockGravityContract := gravityMocks.NewMockContract(mockCtrl)
+
+		gravityAddress := ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
+		fromAddress := ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e03e53415d37aa96045")
+
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&ethtypes.Header{
+			Number: big.NewInt(112),
+		}, nil)
+
+		mockGravityContract.EXPECT().FromAddress().Return(fromAddress).AnyTimes()
+		mockGravityContract.EXPECT().GetTxBatchNonce(gomock.Any(), gomock.Any(), gomock.Any()).Return(big.NewInt(1), nil)
+		mockGravityContract.EXPECT().EncodeTransactionBatch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{1}, nil)
+		mockGravityContract.EXPECT().Address().Return(gravityAddress).AnyTimes()
+		mockGravityContract.EXPECT().EstimateGas(gomock.Any(), gomock.Any(), gomock.Any()).Return(uint64(99999), big.NewInt(1), nil)
+		mockGravityContract.EXPECT().IsPendingTxInput(gomock.Any(), gomock.Any()).Return(false)
+
+		submitter := &mockMetaTxSubmitter{}
+		relayer := gravityRelayer{
+			logger:            logger,
+			cosmosQueryClient: mockQClient,
+			gravityContract:   mockGravityContract,
+			ethProvider:       ethProvider,
+		}
+		SetMetaTxSubmitter(submitter)(&relayer)
+
+		possibleBatches := map[ethcmn.Address][]SubmittableBatch{
+			ethcmn.HexToAddress("0x0"): {
+				{
+					Batch: types.OutgoingTxBatch{
+						BatchTimeout: 113,
+						BatchNonce:   2,
+					},
+					Signatures: []types.MsgConfirmBatch{},
+				},
+			},
+		}
+
+		err := relayer.RelayBatches(context.Background(), types.Valset{}, possibleBatches)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), relayer.lastSentBatchNonce)
+		assert.Equal(t, [][]byte{{1}}, submitter.txData)
+	})
+}
+
+type mockMetaTxSubmitter struct {
+	txData [][]byte
+}
+
+func (m *mockMetaTxSubmitter) Submit(_ context.Context, _ ethcmn.Address, txData []byte, _ uint64) (string, error) {
+	m.txData = append(m.txData, txData)
+	return "task-1", nil
+}
+
+func TestBatchFeesByToken(t *testing.T) {
+	transferToken := "0xdac17f958d2ee523a2206206994597c13d831ec7"
+	feeToken := "0x6b175474e89094c44da98b954eedeac495271d0f"
+
+	batch := types.OutgoingTxBatch{
+		TokenContract: transferToken,
+		Transactions: []types.OutgoingTransferTx{
+			{Erc20Fee: types.ERC20Token{Contract: feeToken, Amount: sdk.NewInt(10)}},
+			{Erc20Fee: types.ERC20Token{Contract: feeToken, Amount: sdk.NewInt(5)}},
+			{Erc20Fee: types.ERC20Token{Contract: transferToken, Amount: sdk.NewInt(7)}},
+			// no fee contract means the fee is paid in the transferred token
+			{Erc20Fee: types.ERC20Token{Amount: sdk.NewInt(1)}},
+		},
+	}
+
+	fees := batchFeesByToken(batch)
+	assert.Len(t, fees, 2)
+	assert.Equal(t, ethcmn.HexToAddress(feeToken), fees[0].contract)
+	assert.Equal(t, "15", fees[0].amount.String())
+	assert.Equal(t, ethcmn.HexToAddress(transferToken), fees[1].contract)
+	assert.Equal(t, "8", fees[1].amount.String())
 }
@@ -0,0 +1,261 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+
+	"github.com/umee-network/peggo/orchestrator/store"
+)
+
+// batchApprovalsStoreKey is the store key holding the batches approved by the
+// operator despite the value concentration guardrail.
+const batchApprovalsStoreKey = "relayer_batch_approvals"
+
+// batchApprovalRetention bounds how long an approval is kept, for the batches
+// that are neither relayed nor seen timing out, e.g. canceled on Cosmos.
+const batchApprovalRetention = 30 * 24 * time.Hour
+
+// approvalsMtx serializes the updates of the approvals, made by the admin API
+// and pruned by the relayer loop.
+var approvalsMtx sync.Mutex
+
+// BatchApproval is a batch the operator approved for relaying.
+type BatchApproval struct {
+	TokenContract string    `json:"token_contract"`
+	BatchNonce    uint64    `json:"batch_nonce"`
+	ApprovedAt    time.Time `json:"approved_at"`
+}
+
+// GetBatchApprovals returns the batches approved by the operator.
+func GetBatchApprovals(s *store.Store) ([]BatchApproval, error) {
+	var approvals []BatchApproval
+	if _, err := s.Get(batchApprovalsStoreKey, &approvals); err != nil {
+		return nil, err
+	}
+
+	return approvals, nil
+}
+
+// ApproveBatch approves a batch held by the value concentration guardrail, e.g.
+// through the admin API. The approval is persisted, so it's picked up on the
+// next relayer loop, until the batch is relayed or times out.
+func ApproveBatch(s *store.Store, tokenContract ethcmn.Address, batchNonce uint64) error {
+	approvalsMtx.Lock()
+	defer approvalsMtx.Unlock()
+
+	approvals, err := GetBatchApprovals(s)
+	if err != nil {
+		return err
+	}
+
+	if isApproved(approvals, tokenContract, batchNonce) {
+		return nil
+	}
+
+	return s.Set(batchApprovalsStoreKey, append(approvals, BatchApproval{
+		TokenContract: tokenContract.Hex(),
+		BatchNonce:    batchNonce,
+		ApprovedAt:    time.Now().UTC(),
+	}))
+}
+
+// pruneBatchApprovals drops the approvals of the batches of tokenContract that
+// can no longer be relayed: the ones up to latestNonce, which were relayed or
+// superseded on Ethereum, and the timed out ones. Approvals older than the
+// retention are dropped too.
+func pruneBatchApprovals(
+	s *store.Store,
+	tokenContract ethcmn.Address,
+	latestNonce uint64,
+	timedOut map[uint64]struct{},
+	now time.Time,
+) error {
+	approvalsMtx.Lock()
+	defer approvalsMtx.Unlock()
+
+	approvals, err := GetBatchApprovals(s)
+	if err != nil || len(approvals) == 0 {
+		return err
+	}
+
+	kept := make([]BatchApproval, 0, len(approvals))
+	for _, a := range approvals {
+		if now.Sub(a.ApprovedAt) > batchApprovalRetention {
+			continue
+		}
+
+		if strings.EqualFold(a.TokenContract, tokenContract.Hex()) {
+			if _, ok := timedOut[a.BatchNonce]; ok || a.BatchNonce <= latestNonce {
+				continue
+			}
+		}
+
+		kept = append(kept, a)
+	}
+
+	if len(kept) == len(approvals) {
+		return nil
+	}
+
+	return s.Set(batchApprovalsStoreKey, kept)
+}
+
+func isApproved(approvals []BatchApproval, tokenContract ethcmn.Address, batchNonce uint64) bool {
+	for _, a := range approvals {
+		if a.BatchNonce == batchNonce && strings.EqualFold(a.TokenContract, tokenContract.Hex()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetMaxValueConcentration returns the relayer option holding batches for
+// manual approval when more than maxPercent of their value flows to a single
+// fresh address.
+func SetMaxValueConcentration(maxPercent float64) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetMaxValueConcentration(maxPercent) }
+}
+
+// SetMaxValueConcentration sets the maximum percentage of the value of a batch
+// that can flow to a single fresh address before the batch is held for manual
+// approval.
+func (s *gravityRelayer) SetMaxValueConcentration(maxPercent float64) {
+	s.maxConcentration = maxPercent
+}
+
+// concentratedDestination returns the destination receiving the largest share
+// of the batch value, and its share in percent, if the share is above the
+// configured maximum.
+func (s *gravityRelayer) concentratedDestination(batch types.OutgoingTxBatch) (string, float64, bool) {
+	if s.maxConcentration <= 0 || len(batch.Transactions) == 0 {
+		return "", 0, false
+	}
+
+	total := sdkmath.ZeroInt()
+	amounts := map[ethcmn.Address]sdkmath.Int{}
+
+	for _, tx := range batch.Transactions {
+		if tx.Erc20Token.Amount.IsNil() {
+			continue
+		}
+
+		dest := ethcmn.HexToAddress(tx.DestAddress)
+		if _, ok := amounts[dest]; !ok {
+			amounts[dest] = sdkmath.ZeroInt()
+		}
+
+		amounts[dest] = amounts[dest].Add(tx.Erc20Token.Amount)
+		total = total.Add(tx.Erc20Token.Amount)
+	}
+
+	if !total.IsPositive() {
+		return "", 0, false
+	}
+
+	var (
+		top       ethcmn.Address
+		topAmount = sdkmath.ZeroInt()
+	)
+	for dest, amount := range amounts {
+		if amount.GT(topAmount) {
+			top, topAmount = dest, amount
+		}
+	}
+
+	share, err := sdk.NewDecFromInt(topAmount.MulRaw(100)).QuoInt(total).Float64()
+	if err != nil || share <= s.maxConcentration {
+		return "", 0, false
+	}
+
+	return top.Hex(), share, true
+}
+
+// isFreshAddress returns true if the address never sent a tx and isn't a
+// contract. If that cannot be checked, the address is considered fresh, as
+// holding a batch is always the safe option.
+func (s *gravityRelayer) isFreshAddress(ctx context.Context, addr ethcmn.Address) bool {
+	nonce, err := s.ethProvider.PendingNonceAt(ctx, addr)
+	if err != nil {
+		s.logger.Err(err).Str("address", addr.Hex()).Msg("failed to get address nonce; considering it fresh")
+		return true
+	}
+
+	if nonce > 0 {
+		return false
+	}
+
+	code, err := s.ethProvider.PendingCodeAt(ctx, addr)
+	if err != nil {
+		s.logger.Err(err).Str("address", addr.Hex()).Msg("failed to get address code; considering it fresh")
+		return true
+	}
+
+	return len(code) == 0
+}
+
+// forgetBatchApprovals prunes the approvals of the batches of tokenContract
+// that can no longer be relayed (see pruneBatchApprovals).
+func (s *gravityRelayer) forgetBatchApprovals(
+	tokenContract ethcmn.Address,
+	latestNonce uint64,
+	batches []SubmittableBatch,
+	ethBlockHeight uint64,
+) {
+	if s.store == nil {
+		return
+	}
+
+	timedOut := map[uint64]struct{}{}
+	for _, batch := range batches {
+		if batch.Batch.BatchTimeout < ethBlockHeight {
+			timedOut[batch.Batch.BatchNonce] = struct{}{}
+		}
+	}
+
+	if err := pruneBatchApprovals(s.store, tokenContract, latestNonce, timedOut, time.Now()); err != nil {
+		s.logger.Err(err).Msg("failed to prune batch approvals")
+	}
+}
+
+// heldForApproval returns true if more than the configured share of the batch
+// value flows to a single fresh address and the operator didn't approve the
+// batch. This is a tripwire for exploits draining the bridge to a new address.
+func (s *gravityRelayer) heldForApproval(ctx context.Context, batch types.OutgoingTxBatch) bool {
+	dest, share, ok := s.concentratedDestination(batch)
+	if !ok || !s.isFreshAddress(ctx, ethcmn.HexToAddress(dest)) {
+		return false
+	}
+
+	tokenContract := ethcmn.HexToAddress(batch.TokenContract)
+
+	if s.store != nil {
+		approvals, err := GetBatchApprovals(s.store)
+		if err != nil {
+			s.logger.Err(err).Msg("failed to read batch approvals; holding batch")
+			return true
+		}
+
+		if isApproved(approvals, tokenContract, batch.BatchNonce) {
+			return false
+		}
+	}
+
+	s.logger.Warn().
+		Uint64("batch_nonce", batch.BatchNonce).
+		Str("token_contract", batch.TokenContract).
+		Str("dest_address", dest).
+		Float64("value_share", share).
+		Str("approve", fmt.Sprintf("peggo relayer approve-batch %s %d", tokenContract.Hex(), batch.BatchNonce)).
+		Msg("batch value is concentrated on a fresh address; holding it for manual approval")
+
+	return true
+}
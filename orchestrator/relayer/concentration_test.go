@@ -0,0 +1,117 @@
+package relayer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umee-network/peggo/mocks"
+	"github.com/umee-network/peggo/orchestrator/store"
+)
+
+func TestHeldForApproval(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	const (
+		fresh    = "0x0000000000000000000000000000000000000001"
+		used     = "0x0000000000000000000000000000000000000002"
+		other    = "0x0000000000000000000000000000000000000003"
+		contract = "0x0000000000000000000000000000000000000004"
+	)
+
+	ctx := context.Background()
+	tokenContract := ethcmn.HexToAddress("0x0000000000000000000000000000000000000005")
+
+	ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+	ethProvider.EXPECT().PendingNonceAt(gomock.Any(), ethcmn.HexToAddress(fresh)).Return(uint64(0), nil).AnyTimes()
+	ethProvider.EXPECT().PendingCodeAt(gomock.Any(), ethcmn.HexToAddress(fresh)).Return(nil, nil).AnyTimes()
+	ethProvider.EXPECT().PendingNonceAt(gomock.Any(), ethcmn.HexToAddress(used)).Return(uint64(3), nil).AnyTimes()
+	ethProvider.EXPECT().PendingNonceAt(gomock.Any(), ethcmn.HexToAddress(contract)).Return(uint64(0), nil).AnyTimes()
+	ethProvider.EXPECT().PendingCodeAt(gomock.Any(), ethcmn.HexToAddress(contract)).Return([]byte{1}, nil).AnyTimes()
+
+	st, err := store.New(t.TempDir())
+	require.NoError(t, err)
+
+	relayer := &gravityRelayer{
+		logger:      zerolog.Nop(),
+		ethProvider: ethProvider,
+		store:       st,
+	}
+
+	batch := func(dest string, destAmount, otherAmount int64) types.OutgoingTxBatch {
+		return types.OutgoingTxBatch{
+			BatchNonce:    7,
+			TokenContract: tokenContract.Hex(),
+			Transactions: []types.OutgoingTransferTx{
+				{DestAddress: dest, Erc20Token: types.ERC20Token{Amount: sdk.NewInt(destAmount)}},
+				{DestAddress: other, Erc20Token: types.ERC20Token{Amount: sdk.NewInt(otherAmount)}},
+			},
+		}
+	}
+
+	// disabled by default
+	assert.False(t, relayer.heldForApproval(ctx, batch(fresh, 90, 10)))
+
+	relayer.SetMaxValueConcentration(80)
+
+	dest, share, ok := relayer.concentratedDestination(batch(fresh, 90, 10))
+	assert.True(t, ok)
+	assert.Equal(t, ethcmn.HexToAddress(fresh).Hex(), dest)
+	assert.InDelta(t, 90, share, 0.0001)
+
+	assert.True(t, relayer.heldForApproval(ctx, batch(fresh, 90, 10)))
+	assert.False(t, relayer.heldForApproval(ctx, batch(fresh, 80, 20)))
+	assert.False(t, relayer.heldForApproval(ctx, batch(used, 90, 10)))
+	assert.False(t, relayer.heldForApproval(ctx, batch(contract, 90, 10)))
+
+	// approved batches are relayed
+	require.NoError(t, ApproveBatch(st, tokenContract, 7))
+	require.NoError(t, ApproveBatch(st, tokenContract, 7))
+	assert.False(t, relayer.heldForApproval(ctx, batch(fresh, 90, 10)))
+
+	approvals, err := GetBatchApprovals(st)
+	require.NoError(t, err)
+	assert.Len(t, approvals, 1)
+}
+
+func TestPruneBatchApprovals(t *testing.T) {
+	st, err := store.New(t.TempDir())
+	require.NoError(t, err)
+
+	tokenContract := ethcmn.HexToAddress("0x0000000000000000000000000000000000000005")
+	otherContract := ethcmn.HexToAddress("0x0000000000000000000000000000000000000006")
+
+	for _, nonce := range []uint64{3, 5, 7, 8} {
+		require.NoError(t, ApproveBatch(st, tokenContract, nonce))
+	}
+	require.NoError(t, ApproveBatch(st, otherContract, 3))
+
+	now := time.Now()
+
+	// batch 3 was relayed and batch 7 timed out
+	timedOut := map[uint64]struct{}{7: {}}
+	require.NoError(t, pruneBatchApprovals(st, tokenContract, 4, timedOut, now))
+
+	approvals, err := GetBatchApprovals(st)
+	require.NoError(t, err)
+	require.Len(t, approvals, 3)
+	assert.True(t, isApproved(approvals, tokenContract, 5))
+	assert.True(t, isApproved(approvals, tokenContract, 8))
+	assert.True(t, isApproved(approvals, otherContract, 3))
+
+	// approvals past the retention are dropped whatever the token
+	require.NoError(t, pruneBatchApprovals(st, tokenContract, 4, nil, now.Add(batchApprovalRetention+time.Hour)))
+
+	approvals, err = GetBatchApprovals(st)
+	require.NoError(t, err)
+	assert.Empty(t, approvals)
+}
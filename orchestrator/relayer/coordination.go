@@ -0,0 +1,104 @@
+package relayer
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"time"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+)
+
+// SetRelayGracePeriod returns the relayer option leaving each batch to its
+// preferred relayer (see preferredRelayer) for the given grace period, so the
+// relayers don't all pay gas for the same batch. Zero disables it.
+func SetRelayGracePeriod(grace time.Duration) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetRelayGracePeriod(grace) }
+}
+
+// SetRelayGracePeriod makes the relayer leave each batch to its preferred
+// relayer for the given grace period before relaying it.
+func (s *gravityRelayer) SetRelayGracePeriod(grace time.Duration) {
+	s.relayGrace = grace
+	s.batchesSeen = map[uint64]time.Time{}
+}
+
+// preferredRelayer returns the relayer every peggo instance expects to relay
+// the batch with the given nonce: the member of the valset stored on Ethereum
+// at the index of the hash of the nonce, modulo the size of the valset. Both
+// are read from the chains, so all the relayers agree on it without talking to
+// each other.
+func preferredRelayer(currentValset types.Valset, batchNonce uint64) (ethcmn.Address, bool) {
+	var relayers []ethcmn.Address
+	for _, member := range currentValset.Members {
+		if ethcmn.IsHexAddress(member.EthereumAddress) {
+			relayers = append(relayers, ethcmn.HexToAddress(member.EthereumAddress))
+		}
+	}
+
+	if len(relayers) == 0 {
+		return ethcmn.Address{}, false
+	}
+
+	var nonce [8]byte
+	binary.BigEndian.PutUint64(nonce[:], batchNonce)
+	hash := sha256.Sum256(nonce[:])
+
+	return relayers[binary.BigEndian.Uint64(hash[:8])%uint64(len(relayers))], true
+}
+
+// waitingForPreferred reports whether the relayer leaves the batch to its
+// preferred relayer: that's the case while the batch has been submittable for
+// less than the grace period, unless we're the preferred relayer. Once it
+// elapses, the batch is relayed as usual, so a preferred relayer that's down or
+// unwilling to relay it doesn't hold it back.
+func (s *gravityRelayer) waitingForPreferred(currentValset types.Valset, batch types.OutgoingTxBatch) bool {
+	if s.relayGrace <= 0 {
+		return false
+	}
+
+	preferred, ok := preferredRelayer(currentValset, batch.BatchNonce)
+	if !ok || preferred == s.gravityContract.FromAddress() {
+		return false
+	}
+
+	seen, ok := s.batchesSeen[batch.BatchNonce]
+	if !ok {
+		seen = time.Now()
+		s.batchesSeen[batch.BatchNonce] = seen
+	}
+
+	if time.Since(seen) >= s.relayGrace {
+		return false
+	}
+
+	s.logger.Debug().
+		Uint64("batch_nonce", batch.BatchNonce).
+		Str("token_contract", batch.TokenContract).
+		Str("preferred_relayer", preferred.Hex()).
+		Dur("grace_left", s.relayGrace-time.Since(seen)).
+		Msg("leaving the batch to its preferred relayer")
+
+	return true
+}
+
+// forgetBatchesSeen drops the batches that are no longer submittable from the
+// ones waiting for their preferred relayer.
+func (s *gravityRelayer) forgetBatchesSeen(possibleBatches map[ethcmn.Address][]SubmittableBatch) {
+	if len(s.batchesSeen) == 0 {
+		return
+	}
+
+	submittable := map[uint64]struct{}{}
+	for _, batches := range possibleBatches {
+		for _, batch := range batches {
+			submittable[batch.Batch.BatchNonce] = struct{}{}
+		}
+	}
+
+	for nonce := range s.batchesSeen {
+		if _, ok := submittable[nonce]; !ok {
+			delete(s.batchesSeen, nonce)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package relayer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	gravityMocks "github.com/umee-network/peggo/mocks/gravity"
+)
+
+func TestPreferredRelayer(t *testing.T) {
+	valset := types.Valset{
+		Members: []types.BridgeValidator{
+			{Power: 1000000000, EthereumAddress: "0x0000000000000000000000000000000000000001"},
+			{Power: 1100000000, EthereumAddress: "0x0000000000000000000000000000000000000002"},
+			{Power: 1100000000, EthereumAddress: "0x0000000000000000000000000000000000000003"},
+			{Power: 1094967296, EthereumAddress: "0x0000000000000000000000000000000000000004"},
+		},
+	}
+
+	preferred := map[ethcmn.Address]int{}
+	for nonce := uint64(1); nonce <= 100; nonce++ {
+		relayer, ok := preferredRelayer(valset, nonce)
+		require.True(t, ok)
+		preferred[relayer]++
+
+		// every relayer derives the same one
+		again, _ := preferredRelayer(valset, nonce)
+		assert.Equal(t, relayer, again)
+	}
+
+	// the batches are spread over the whole valset
+	assert.Len(t, preferred, len(valset.Members))
+
+	_, ok := preferredRelayer(types.Valset{}, 1)
+	assert.False(t, ok)
+}
+
+func TestWaitingForPreferred(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	const (
+		ours  = "0x0000000000000000000000000000000000000001"
+		other = "0x0000000000000000000000000000000000000002"
+	)
+
+	valset := types.Valset{
+		Members: []types.BridgeValidator{
+			{Power: 2147483648, EthereumAddress: ours},
+			{Power: 2147483647, EthereumAddress: other},
+		},
+	}
+
+	// find a batch preferring each relayer
+	var oursNonce, otherNonce uint64
+	for nonce := uint64(1); oursNonce == 0 || otherNonce == 0; nonce++ {
+		if preferred, _ := preferredRelayer(valset, nonce); preferred == ethcmn.HexToAddress(ours) {
+			oursNonce = nonce
+		} else {
+			otherNonce = nonce
+		}
+	}
+
+	mockGravityContract := gravityMocks.NewMockContract(mockCtrl)
+	mockGravityContract.EXPECT().FromAddress().Return(ethcmn.HexToAddress(ours)).AnyTimes()
+
+	relayer := &gravityRelayer{logger: zerolog.Nop(), gravityContract: mockGravityContract}
+
+	// without a grace period, every batch is relayed right away
+	assert.False(t, relayer.waitingForPreferred(valset, types.OutgoingTxBatch{BatchNonce: otherNonce}))
+
+	SetRelayGracePeriod(time.Minute)(relayer)
+
+	assert.False(t, relayer.waitingForPreferred(valset, types.OutgoingTxBatch{BatchNonce: oursNonce}))
+	assert.True(t, relayer.waitingForPreferred(valset, types.OutgoingTxBatch{BatchNonce: otherNonce}))
+
+	// once the grace period elapses, the batch is relayed by anyone
+	relayer.batchesSeen[otherNonce] = time.Now().Add(-time.Minute)
+	assert.False(t, relayer.waitingForPreferred(valset, types.OutgoingTxBatch{BatchNonce: otherNonce}))
+
+	// the batches no longer submittable are forgotten
+	relayer.forgetBatchesSeen(map[ethcmn.Address][]SubmittableBatch{})
+	assert.Empty(t, relayer.batchesSeen)
+}
@@ -0,0 +1,206 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/umee-network/peggo/orchestrator/loops"
+)
+
+const (
+	denylistMaxRespTime = 30 * time.Second
+	// denylistMaxSize bounds the size of the list fetched from the URL, far
+	// above the published sanctions lists.
+	denylistMaxSize = 16 << 20
+)
+
+// ethAddressRe matches any Ethereum address, so lists can be plain text (one
+// address per line), CSV or JSON.
+var ethAddressRe = regexp.MustCompile(`0x[0-9a-fA-F]{40}`)
+
+// Denylist holds Ethereum addresses this relayer refuses to relay transfers to,
+// e.g. a published sanctions list. Addresses are read from a local file and/or
+// a remote URL, and can be added by a remote config.
+type Denylist struct {
+	logger zerolog.Logger
+	client *http.Client
+	file   string
+	url    string
+
+	mtx    sync.RWMutex
+	addrs  map[ethcmn.Address]struct{}
+	remote map[ethcmn.Address]struct{} // set by the remote config, kept on reloads
+}
+
+// NewDenylist returns a denylist loaded from the given file and URL (either can
+// be empty). Failing to load the list on startup is an error, as relaying
+// without it could breach the operator's obligations.
+func NewDenylist(logger zerolog.Logger, file, url string) (*Denylist, error) {
+	if file == "" && url == "" {
+		return nil, errors.New("denylist requires a file or a URL")
+	}
+
+	d := &Denylist{
+		logger: logger.With().Str("module", "denylist").Logger(),
+		client: &http.Client{Timeout: denylistMaxRespTime},
+		file:   file,
+		url:    url,
+	}
+
+	if err := d.Reload(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// NewRemoteDenylist returns a denylist without a file nor a URL, whose
+// addresses are only set with SetRemote.
+func NewRemoteDenylist(logger zerolog.Logger) *Denylist {
+	return &Denylist{
+		logger: logger.With().Str("module", "denylist").Logger(),
+		client: &http.Client{Timeout: denylistMaxRespTime},
+	}
+}
+
+// SetRemote replaces the addresses set by the remote config, on top of the
+// ones of the file and URL.
+func (d *Denylist) SetRemote(addrs []ethcmn.Address) {
+	remote := make(map[ethcmn.Address]struct{}, len(addrs))
+	for _, addr := range addrs {
+		remote[addr] = struct{}{}
+	}
+
+	d.mtx.Lock()
+	d.remote = remote
+	d.mtx.Unlock()
+
+	d.logger.Info().Int("addresses", len(remote)).Msg("remote denylist set")
+}
+
+// Reload reads the list again from its sources. The current list is kept if
+// any source fails.
+func (d *Denylist) Reload(ctx context.Context) error {
+	addrs := map[ethcmn.Address]struct{}{}
+
+	if d.file != "" {
+		bz, err := os.ReadFile(d.file)
+		if err != nil {
+			return errors.Wrap(err, "failed to read denylist file")
+		}
+		parseDenylist(bz, addrs)
+	}
+
+	if d.url != "" {
+		bz, err := d.fetch(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to fetch denylist")
+		}
+		parseDenylist(bz, addrs)
+	}
+
+	d.mtx.Lock()
+	d.addrs = addrs
+	d.mtx.Unlock()
+
+	d.logger.Info().Int("addresses", len(addrs)).Msg("denylist loaded")
+	return nil
+}
+
+func (d *Denylist) fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("denylist URL returned status %d", resp.StatusCode)
+	}
+
+	bz, err := io.ReadAll(io.LimitReader(resp.Body, denylistMaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bz) > denylistMaxSize {
+		return nil, fmt.Errorf("denylist URL returned more than %d bytes", denylistMaxSize)
+	}
+
+	return bz, nil
+}
+
+func parseDenylist(bz []byte, addrs map[ethcmn.Address]struct{}) {
+	for _, match := range ethAddressRe.FindAll(bz, -1) {
+		addrs[ethcmn.HexToAddress(string(match))] = struct{}{}
+	}
+}
+
+// Start reloads the list every interval until the context is done. A failed
+// reload is logged and the previous list is kept.
+func (d *Denylist) Start(ctx context.Context, interval time.Duration) error {
+	return loops.RunLoop(ctx, d.logger, interval, func() error {
+		if err := d.Reload(ctx); err != nil {
+			d.logger.Err(err).Msg("failed to reload denylist; keeping the previous one")
+		}
+
+		return nil
+	})
+}
+
+// Contains returns true if the address is in the list.
+func (d *Denylist) Contains(addr ethcmn.Address) bool {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	if _, ok := d.addrs[addr]; ok {
+		return true
+	}
+
+	_, ok := d.remote[addr]
+	return ok
+}
+
+// SetDenylist returns the relayer option skipping the batches sending to an
+// address of the denylist.
+func SetDenylist(d *Denylist) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetDenylist(d) }
+}
+
+// SetDenylist sets the denylist the destinations of each batch are checked
+// against. A nil denylist allows every destination.
+func (s *gravityRelayer) SetDenylist(d *Denylist) {
+	s.denylist = d
+}
+
+// deniedDestination returns the first destination of the batch that is in the
+// denylist, if any.
+func (s *gravityRelayer) deniedDestination(batch types.OutgoingTxBatch) (string, bool) {
+	if s.denylist == nil {
+		return "", false
+	}
+
+	for _, tx := range batch.Transactions {
+		if s.denylist.Contains(ethcmn.HexToAddress(tx.DestAddress)) {
+			return tx.DestAddress, true
+		}
+	}
+
+	return "", false
+}
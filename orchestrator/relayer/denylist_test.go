@@ -0,0 +1,78 @@
+package relayer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDenylist(t *testing.T) {
+	fileAddr := "0x8589427373D6D84E98730D7795D8f6f8731FDA16"
+	urlAddr := "0x722122dF12D4e14e13Ac3b6895a86e84145b6967"
+	okAddr := "0x0000000000000000000000000000000000000001"
+
+	file := filepath.Join(t.TempDir(), "denylist.txt")
+	require.NoError(t, os.WriteFile(file, []byte("# sanctioned\n"+fileAddr+"\n"), 0o600))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`["` + urlAddr + `"]`))
+	}))
+	defer server.Close()
+
+	d, err := NewDenylist(zerolog.Nop(), file, server.URL)
+	require.NoError(t, err)
+
+	assert.True(t, d.Contains(ethcmn.HexToAddress(fileAddr)))
+	assert.True(t, d.Contains(ethcmn.HexToAddress(urlAddr)))
+	assert.False(t, d.Contains(ethcmn.HexToAddress(okAddr)))
+
+	r := &gravityRelayer{denylist: d}
+
+	addr, denied := r.deniedDestination(types.OutgoingTxBatch{
+		Transactions: []types.OutgoingTransferTx{{DestAddress: okAddr}, {DestAddress: urlAddr}},
+	})
+	assert.True(t, denied)
+	assert.Equal(t, urlAddr, addr)
+
+	_, denied = r.deniedDestination(types.OutgoingTxBatch{
+		Transactions: []types.OutgoingTransferTx{{DestAddress: okAddr}},
+	})
+	assert.False(t, denied)
+
+	_, err = NewDenylist(zerolog.Nop(), filepath.Join(t.TempDir(), "missing.txt"), "")
+	assert.Error(t, err)
+
+	large := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(make([]byte, denylistMaxSize+1))
+	}))
+	defer large.Close()
+
+	_, err = NewDenylist(zerolog.Nop(), "", large.URL)
+	assert.Error(t, err)
+}
+
+func TestDenylistRemote(t *testing.T) {
+	addr := ethcmn.HexToAddress("0x8589427373D6D84E98730D7795D8f6f8731FDA16")
+
+	d := NewRemoteDenylist(zerolog.Nop())
+	assert.False(t, d.Contains(addr))
+
+	d.SetRemote([]ethcmn.Address{addr})
+	assert.True(t, d.Contains(addr))
+
+	// the remote addresses are kept on reloads
+	require.NoError(t, d.Reload(context.Background()))
+	assert.True(t, d.Contains(addr))
+
+	d.SetRemote(nil)
+	assert.False(t, d.Contains(addr))
+}
@@ -0,0 +1,29 @@
+package relayer
+
+import (
+	"strings"
+
+	"github.com/umee-network/peggo/orchestrator/oracle"
+)
+
+// SetGasAssetSymbol returns the relayer option pricing the relaying costs in
+// USD with the given gas asset (e.g. BNB, MATIC or AVAX) instead of ETH.
+func SetGasAssetSymbol(symbol string) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetGasAssetSymbol(symbol) }
+}
+
+// SetGasAssetSymbol sets the symbol of the asset paying for gas on the target
+// EVM chain (e.g. BNB, MATIC or AVAX), used to price relaying costs in USD.
+func (s *gravityRelayer) SetGasAssetSymbol(symbol string) {
+	s.gasAsset = strings.ToUpper(symbol)
+}
+
+// GetGasAssetSymbol returns the symbol of the asset paying for gas on the target
+// EVM chain; ETH unless set otherwise.
+func (s *gravityRelayer) GetGasAssetSymbol() string {
+	if s.gasAsset == "" {
+		return oracle.SymbolETH
+	}
+
+	return s.gasAsset
+}
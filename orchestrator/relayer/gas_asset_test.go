@@ -0,0 +1,15 @@
+package relayer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasAssetSymbol(t *testing.T) {
+	relayer := &gravityRelayer{}
+	assert.Equal(t, "ETH", relayer.GetGasAssetSymbol())
+
+	SetGasAssetSymbol("bnb")(relayer)
+	assert.Equal(t, "BNB", relayer.GetGasAssetSymbol())
+}
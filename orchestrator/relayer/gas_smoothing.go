@@ -0,0 +1,79 @@
+package relayer
+
+import (
+	"math"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// gasPriceSmoother keeps an exponentially weighted moving average of the gas
+// price, so a short spike doesn't flap the profitability of batches back and
+// forth. The weight of a price halves roughly every window*ln(2), regardless of
+// how often the gas price is observed.
+type gasPriceSmoother struct {
+	mtx      sync.Mutex
+	window   time.Duration
+	average  *big.Float
+	observed time.Time
+	now      func() time.Time
+}
+
+func newGasPriceSmoother(window time.Duration) *gasPriceSmoother {
+	return &gasPriceSmoother{
+		window: window,
+		now:    time.Now,
+	}
+}
+
+// observe records the spot gas price and returns the smoothed one.
+func (g *gasPriceSmoother) observe(gasPrice *big.Int) *big.Int {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	now := g.now()
+	price := new(big.Float).SetInt(gasPrice)
+
+	if g.average == nil {
+		g.average = price
+	} else {
+		alpha := 1 - math.Exp(-float64(now.Sub(g.observed))/float64(g.window))
+
+		// average += alpha * (price - average)
+		delta := new(big.Float).Sub(price, g.average)
+		g.average = new(big.Float).Add(g.average, delta.Mul(delta, big.NewFloat(alpha)))
+	}
+	g.observed = now
+
+	smoothed, _ := g.average.Int(nil)
+	return smoothed
+}
+
+// SetGasPriceSmoothing returns the relayer option averaging the gas price over
+// the given window in profitability checks.
+func SetGasPriceSmoothing(window time.Duration) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetGasPriceSmoothing(window) }
+}
+
+// SetGasPriceSmoothing sets the window of the moving average of the gas price
+// used when performing profitable batch calculations. A zero window disables
+// it, and the spot gas price is used instead. Relayed txs are always sent with
+// the spot gas price.
+func (s *gravityRelayer) SetGasPriceSmoothing(window time.Duration) {
+	if window <= 0 {
+		s.gasPriceSmoother = nil
+		return
+	}
+
+	s.gasPriceSmoother = newGasPriceSmoother(window)
+}
+
+// profitabilityGasPrice returns the gas price batch profitability is
+// calculated with.
+func (s *gravityRelayer) profitabilityGasPrice(spot *big.Int) *big.Int {
+	if s.gasPriceSmoother == nil {
+		return spot
+	}
+
+	return s.gasPriceSmoother.observe(spot)
+}
@@ -0,0 +1,43 @@
+package relayer
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasPriceSmootherObserve(t *testing.T) {
+	now := time.Now()
+	smoother := newGasPriceSmoother(10 * time.Minute)
+	smoother.now = func() time.Time { return now }
+
+	assert.Equal(t, big.NewInt(100), smoother.observe(big.NewInt(100)))
+
+	// A 30 seconds spike barely moves the average.
+	now = now.Add(30 * time.Second)
+	spike := smoother.observe(big.NewInt(500))
+	assert.True(t, spike.Cmp(big.NewInt(130)) < 0, spike)
+	assert.True(t, spike.Cmp(big.NewInt(100)) > 0, spike)
+
+	// A lasting change is followed.
+	var average *big.Int
+	for i := 0; i < 12; i++ {
+		now = now.Add(5 * time.Minute)
+		average = smoother.observe(big.NewInt(500))
+	}
+	assert.True(t, average.Cmp(big.NewInt(495)) >= 0, average)
+	assert.True(t, average.Cmp(big.NewInt(500)) <= 0, average)
+}
+
+func TestSetGasPriceSmoothing(t *testing.T) {
+	relayer := &gravityRelayer{}
+
+	relayer.SetGasPriceSmoothing(time.Minute)
+	assert.NotNil(t, relayer.gasPriceSmoother)
+
+	relayer.SetGasPriceSmoothing(0)
+	assert.Nil(t, relayer.gasPriceSmoother)
+	assert.Equal(t, big.NewInt(42), relayer.profitabilityGasPrice(big.NewInt(42)))
+}
@@ -0,0 +1,319 @@
+package relayer
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/util"
+	"github.com/umee-network/peggo/orchestrator/oracle"
+	"github.com/umee-network/peggo/orchestrator/store"
+)
+
+// relayHistoryStoreKey is the store key holding the batches relayed by this
+// orchestrator.
+const relayHistoryStoreKey = "relayer_batch_history"
+
+const (
+	// relayHistoryRetention is how long relayed batches are kept in the history.
+	relayHistoryRetention = 90 * 24 * time.Hour
+	// maxRelayHistory bounds the number of relayed batches kept in the history.
+	maxRelayHistory = 10000
+)
+
+// Statuses of a relayed batch tx.
+const (
+	RelayStatusPending = "pending"
+	RelayStatusSuccess = "success"
+	RelayStatusFailed  = "failed"
+)
+
+// RelayRecord is a batch relayed by this orchestrator. The fees and gas cost
+// are valued in USD at the oracle prices when the batch was relayed, and are
+// zero without an oracle. Until the tx is mined, the gas used and gas cost
+// are the estimated ones.
+type RelayRecord struct {
+	RelayedAt     time.Time       `json:"relayed_at"`
+	Denom         string          `json:"denom"`
+	TokenContract string          `json:"token_contract"`
+	BatchNonce    uint64          `json:"batch_nonce"`
+	Txs           int             `json:"txs"`
+	TxHash        string          `json:"tx_hash"`
+	Status        string          `json:"status"`
+	GasUsed       uint64          `json:"gas_used"`
+	GasPrice      *big.Int        `json:"gas_price"`
+	FeesUSD       decimal.Decimal `json:"fees_usd"`
+	GasCostUSD    decimal.Decimal `json:"gas_cost_usd"`
+}
+
+// ProfitUSD returns the profit of relaying the batch in USD. The fees of a
+// failed tx aren't earned, but its gas is still paid.
+func (r RelayRecord) ProfitUSD() decimal.Decimal {
+	if r.Status == RelayStatusFailed {
+		return r.GasCostUSD.Neg()
+	}
+
+	return r.FeesUSD.Sub(r.GasCostUSD)
+}
+
+// GetRelayHistory returns the batches relayed by this orchestrator, oldest
+// first.
+func GetRelayHistory(s *store.Store) ([]RelayRecord, error) {
+	var records []RelayRecord
+	if _, err := s.Get(relayHistoryStoreKey, &records); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// RelayStats summarizes the batches relayed in a time window. The success rate
+// only accounts for the mined or dropped txs.
+type RelayStats struct {
+	Batches      int             `json:"batches"`
+	Succeeded    int             `json:"succeeded"`
+	Failed       int             `json:"failed"`
+	Pending      int             `json:"pending"`
+	AvgTxs       float64         `json:"avg_txs"`
+	AvgFeesUSD   decimal.Decimal `json:"avg_fees_usd"`
+	AvgGasUsed   uint64          `json:"avg_gas_used"`
+	AvgProfitUSD decimal.Decimal `json:"avg_profit_usd"`
+	SuccessRate  float64         `json:"success_rate"`
+}
+
+// ComputeRelayStats summarizes the records relayed since the given time. The
+// denom filter matches the Cosmos denom or the token contract of the batches;
+// an empty one matches all the batches.
+func ComputeRelayStats(records []RelayRecord, denom string, since time.Time) RelayStats {
+	var (
+		stats   RelayStats
+		txs     int
+		gasUsed uint64
+		fees    = decimal.Zero
+		profit  = decimal.Zero
+	)
+
+	for _, r := range records {
+		if r.RelayedAt.Before(since) {
+			continue
+		}
+
+		if denom != "" && !strings.EqualFold(r.Denom, denom) && !strings.EqualFold(r.TokenContract, denom) {
+			continue
+		}
+
+		stats.Batches++
+		switch r.Status {
+		case RelayStatusSuccess:
+			stats.Succeeded++
+		case RelayStatusFailed:
+			stats.Failed++
+		default:
+			stats.Pending++
+		}
+
+		txs += r.Txs
+		gasUsed += r.GasUsed
+		fees = fees.Add(r.FeesUSD)
+		profit = profit.Add(r.ProfitUSD())
+	}
+
+	if stats.Batches == 0 {
+		stats.AvgFeesUSD = decimal.Zero
+		stats.AvgProfitUSD = decimal.Zero
+		return stats
+	}
+
+	n := decimal.NewFromInt(int64(stats.Batches))
+	stats.AvgTxs = float64(txs) / float64(stats.Batches)
+	stats.AvgFeesUSD = fees.Div(n)
+	stats.AvgGasUsed = gasUsed / uint64(stats.Batches)
+	stats.AvgProfitUSD = profit.Div(n)
+
+	if done := stats.Succeeded + stats.Failed; done > 0 {
+		stats.SuccessRate = float64(stats.Succeeded) / float64(done)
+	}
+
+	return stats
+}
+
+// recordRelay appends a relayed batch to the history, for relay statistics.
+func (s *gravityRelayer) recordRelay(
+	ctx context.Context,
+	batch types.OutgoingTxBatch,
+	txHash ethcmn.Hash,
+	estimatedGas uint64,
+	gasPrice *big.Int,
+	feesUSD decimal.Decimal,
+) {
+	if s.store == nil {
+		return
+	}
+
+	gasCostUSD := decimal.Zero
+	if s.oracle != nil {
+		cost, err := s.convertValue(totalGasCost(estimatedGas, gasPrice), oracle.DecimalsETH, s.GetGasAssetSymbol())
+		if err == nil {
+			gasCostUSD = cost
+		}
+	}
+
+	record := RelayRecord{
+		RelayedAt:     time.Now().UTC(),
+		Denom:         s.batchDenom(ctx, batch.TokenContract),
+		TokenContract: batch.TokenContract,
+		BatchNonce:    batch.BatchNonce,
+		Txs:           len(batch.Transactions),
+		TxHash:        txHash.Hex(),
+		Status:        RelayStatusPending,
+		GasUsed:       estimatedGas,
+		GasPrice:      gasPrice,
+		FeesUSD:       feesUSD,
+		GasCostUSD:    gasCostUSD,
+	}
+
+	records, err := GetRelayHistory(s.store)
+	if err != nil {
+		s.logger.Err(err).Msg("failed to read relay history")
+		return
+	}
+
+	if err := s.store.Set(relayHistoryStoreKey, pruneRelayHistory(append(records, record), time.Now())); err != nil {
+		s.logger.Err(err).Msg("failed to save relay history")
+	}
+}
+
+// batchDenom returns the Cosmos denom of a batch token, falling back to its
+// Gravity denom when the chain can't be queried.
+func (s *gravityRelayer) batchDenom(ctx context.Context, tokenContract string) string {
+	resp, err := s.cosmosQueryClient.ERC20ToDenom(ctx, &types.QueryERC20ToDenomRequest{Erc20: tokenContract})
+	if err != nil || resp == nil {
+		return types.GravityDenomPrefix + types.GravityDenomSeparator + tokenContract
+	}
+
+	return resp.Denom
+}
+
+// updateRelayHistory settles the pending txs of the history with their
+// receipts. The gas cost is rescaled to the gas actually paid. A tx no longer
+// known to the Ethereum node after --relayer-pending-tx-wait, e.g. dropped or
+// replaced, is counted as failed.
+func (s *gravityRelayer) updateRelayHistory(ctx context.Context) {
+	if s.store == nil {
+		return
+	}
+
+	records, err := GetRelayHistory(s.store)
+	if err != nil {
+		s.logger.Err(err).Msg("failed to read relay history")
+		return
+	}
+
+	updated := false
+	for i, r := range records {
+		if r.Status != RelayStatusPending {
+			continue
+		}
+
+		txHash := ethcmn.HexToHash(r.TxHash)
+
+		receipt, err := s.ethProvider.TransactionReceipt(ctx, txHash)
+		if err == nil {
+			gasPrice, err := s.effectiveGasPrice(ctx, txHash, receipt)
+			if err != nil {
+				s.logger.Debug().Err(err).Str("tx_hash", r.TxHash).Msg("failed to get relayed tx gas price; retrying later")
+				continue
+			}
+
+			records[i] = settleRelayRecord(r, receipt, gasPrice)
+			updated = true
+			continue
+		}
+
+		if time.Since(r.RelayedAt) < s.pendingTxWait {
+			continue
+		}
+
+		if _, _, err := s.ethProvider.TransactionByHash(ctx, txHash); errors.Is(err, ethereum.NotFound) {
+			records[i].Status = RelayStatusFailed
+			updated = true
+		}
+	}
+
+	if !updated {
+		return
+	}
+
+	if err := s.store.Set(relayHistoryStoreKey, records); err != nil {
+		s.logger.Err(err).Msg("failed to save relay history")
+	}
+}
+
+// effectiveGasPrice returns the price per gas paid by a mined tx, from the base
+// fee of its block.
+func (s *gravityRelayer) effectiveGasPrice(
+	ctx context.Context,
+	txHash ethcmn.Hash,
+	receipt *ethtypes.Receipt,
+) (*big.Int, error) {
+	tx, _, err := s.ethProvider.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := s.ethProvider.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return util.EffectiveGasPrice(tx, header)
+}
+
+// settleRelayRecord updates a relayed batch with the receipt of its tx and the
+// gas price it paid.
+func settleRelayRecord(r RelayRecord, receipt *ethtypes.Receipt, gasPrice *big.Int) RelayRecord {
+	if receipt.Status == ethtypes.ReceiptStatusSuccessful {
+		r.Status = RelayStatusSuccess
+	} else {
+		r.Status = RelayStatusFailed
+	}
+
+	if r.GasPrice != nil && gasPrice != nil && r.GasUsed > 0 && r.GasPrice.Sign() > 0 {
+		estimatedCost := totalGasCost(r.GasUsed, r.GasPrice)
+		actualCost := totalGasCost(receipt.GasUsed, gasPrice)
+		r.GasCostUSD = r.GasCostUSD.
+			Mul(decimal.NewFromBigInt(actualCost, 0)).
+			Div(decimal.NewFromBigInt(estimatedCost, 0))
+	}
+
+	r.GasUsed = receipt.GasUsed
+	r.GasPrice = gasPrice
+
+	return r
+}
+
+// pruneRelayHistory drops the records older than the retention, then the
+// oldest ones above the maximum size.
+func pruneRelayHistory(records []RelayRecord, now time.Time) []RelayRecord {
+	cutoff := now.Add(-relayHistoryRetention)
+
+	i := 0
+	for i < len(records) && records[i].RelayedAt.Before(cutoff) {
+		i++
+	}
+	records = records[i:]
+
+	if len(records) > maxRelayHistory {
+		records = records[len(records)-maxRelayHistory:]
+	}
+
+	return records
+}
@@ -0,0 +1,131 @@
+package relayer
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umee-network/peggo/orchestrator/store"
+)
+
+func TestComputeRelayStats(t *testing.T) {
+	now := time.Now()
+
+	records := []RelayRecord{
+		{
+			RelayedAt:  now.Add(-48 * time.Hour),
+			Denom:      "uumee",
+			Txs:        10,
+			Status:     RelayStatusSuccess,
+			GasUsed:    500000,
+			FeesUSD:    decimal.NewFromInt(100),
+			GasCostUSD: decimal.NewFromInt(20),
+		},
+		{
+			RelayedAt:     now.Add(-time.Hour),
+			Denom:         "uumee",
+			TokenContract: "0xe54fbaecc50731afe54924c40dfd1274f718fe02",
+			Txs:           4,
+			Status:        RelayStatusFailed,
+			GasUsed:       100000,
+			FeesUSD:       decimal.NewFromInt(50),
+			GasCostUSD:    decimal.NewFromInt(10),
+		},
+		{
+			RelayedAt:  now.Add(-time.Minute),
+			Denom:      "uumee",
+			Txs:        6,
+			Status:     RelayStatusPending,
+			GasUsed:    300000,
+			FeesUSD:    decimal.NewFromInt(60),
+			GasCostUSD: decimal.NewFromInt(30),
+		},
+		{
+			RelayedAt:  now.Add(-time.Minute),
+			Denom:      "gravity0xd787Ec2b6C962f611300175603741Db8438674a0",
+			Txs:        1,
+			Status:     RelayStatusSuccess,
+			GasUsed:    200000,
+			FeesUSD:    decimal.NewFromInt(5),
+			GasCostUSD: decimal.NewFromInt(1),
+		},
+	}
+
+	stats := ComputeRelayStats(records, "uumee", now.Add(-72*time.Hour))
+	assert.Equal(t, 3, stats.Batches)
+	assert.Equal(t, 1, stats.Succeeded)
+	assert.Equal(t, 1, stats.Failed)
+	assert.Equal(t, 1, stats.Pending)
+	assert.Equal(t, float64(20)/3, stats.AvgTxs)
+	assert.Equal(t, "70", stats.AvgFeesUSD.String())
+	assert.Equal(t, uint64(300000), stats.AvgGasUsed)
+	// (80 - 10 + 30) / 3
+	assert.Equal(t, "33.3333333333333333", stats.AvgProfitUSD.String())
+	assert.Equal(t, 0.5, stats.SuccessRate)
+
+	// the last day, matched by token contract
+	stats = ComputeRelayStats(records, "0xE54FBAECC50731AFE54924C40DFD1274F718FE02", now.Add(-24*time.Hour))
+	assert.Equal(t, 1, stats.Batches)
+	assert.Equal(t, "-10", stats.AvgProfitUSD.String())
+	assert.Equal(t, float64(0), stats.SuccessRate)
+
+	stats = ComputeRelayStats(records, "", now.Add(-24*time.Hour))
+	assert.Equal(t, 3, stats.Batches)
+
+	stats = ComputeRelayStats(records, "uatom", time.Time{})
+	assert.Equal(t, 0, stats.Batches)
+	assert.True(t, stats.AvgProfitUSD.IsZero())
+}
+
+func TestSettleRelayRecord(t *testing.T) {
+	r := RelayRecord{
+		Status:     RelayStatusPending,
+		GasUsed:    200000,
+		GasPrice:   big.NewInt(50),
+		FeesUSD:    decimal.NewFromInt(100),
+		GasCostUSD: decimal.NewFromInt(40),
+	}
+
+	settled := settleRelayRecord(r, &ethtypes.Receipt{
+		Status:  ethtypes.ReceiptStatusSuccessful,
+		GasUsed: 150000,
+	}, big.NewInt(40))
+	assert.Equal(t, RelayStatusSuccess, settled.Status)
+	assert.Equal(t, uint64(150000), settled.GasUsed)
+	assert.Equal(t, "24", settled.GasCostUSD.String())
+	assert.Equal(t, "76", settled.ProfitUSD().String())
+
+	settled = settleRelayRecord(r, &ethtypes.Receipt{Status: ethtypes.ReceiptStatusFailed, GasUsed: 200000}, big.NewInt(50))
+	assert.Equal(t, RelayStatusFailed, settled.Status)
+	assert.Equal(t, "40", settled.GasCostUSD.String())
+	assert.Equal(t, "-40", settled.ProfitUSD().String())
+}
+
+func TestRelayHistoryPersistence(t *testing.T) {
+	s, err := store.New(t.TempDir())
+	require.NoError(t, err)
+
+	records, err := GetRelayHistory(s)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	now := time.Now().UTC()
+	records = pruneRelayHistory([]RelayRecord{
+		{RelayedAt: now.Add(-relayHistoryRetention - time.Hour), BatchNonce: 1},
+		{RelayedAt: now, BatchNonce: 2, GasPrice: big.NewInt(7), FeesUSD: decimal.RequireFromString("1.5")},
+	}, now)
+	require.Len(t, records, 1)
+	require.NoError(t, s.Set(relayHistoryStoreKey, records))
+
+	records, err = GetRelayHistory(s)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, uint64(2), records[0].BatchNonce)
+	assert.Equal(t, big.NewInt(7), records[0].GasPrice)
+	assert.Equal(t, "1.5", records[0].FeesUSD.String())
+}
@@ -0,0 +1,44 @@
+package relayer
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// SetRelayJitter returns the relayer option delaying each batch submission by a
+// random duration of up to max.
+func SetRelayJitter(max time.Duration) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetRelayJitter(max) }
+}
+
+// SetRelayJitter sets the maximum random delay the relayer waits before
+// submitting a batch, so relayers seeing the same batch don't all submit it at
+// once.
+func (s *gravityRelayer) SetRelayJitter(max time.Duration) {
+	s.relayJitter = max
+	// The global source is seeded identically by every process, which would
+	// give every relayer the same delays.
+	s.jitterRand = rand.New(rand.NewSource(time.Now().UnixNano())) //nolint: gosec
+}
+
+// waitRelayJitter waits for a random delay up to the relay jitter, or until the
+// context is done.
+func (s *gravityRelayer) waitRelayJitter(ctx context.Context) error {
+	if s.relayJitter <= 0 {
+		return nil
+	}
+
+	delay := time.Duration(s.jitterRand.Int63n(int64(s.relayJitter)))
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+
+	case <-timer.C:
+		return nil
+	}
+}
@@ -2,17 +2,20 @@ package relayer
 
 import (
 	"context"
+	"strconv"
 
 	retry "github.com/avast/retry-go"
 	"github.com/pkg/errors"
 
 	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
 
+	"github.com/umee-network/peggo/orchestrator/crash"
 	"github.com/umee-network/peggo/orchestrator/loops"
 )
 
 func (s *gravityRelayer) Start(ctx context.Context) error {
 	logger := s.logger.With().Str("loop", "RelayerMainLoop").Logger()
+	ctx = crash.WithFields(ctx, "loop", "RelayerMainLoop")
 
 	if s.valsetRelayMode != ValsetRelayModeNone {
 		logger.Info().Msg("valset relay enabled; starting to relay valsets to Ethereum")
@@ -23,6 +26,12 @@ func (s *gravityRelayer) Start(ctx context.Context) error {
 	}
 
 	return loops.RunLoop(ctx, s.logger, s.loopDuration, func() error {
+		s.samplePrices()
+
+		if s.isPaused() {
+			return nil
+		}
+
 		var (
 			currentValset *types.Valset
 			err           error
@@ -43,6 +52,10 @@ func (s *gravityRelayer) Start(ctx context.Context) error {
 			s.logger.Panic().Err(err).Msg("exhausted retries to get latest valset")
 		}
 
+		crash.SetField(ctx, "valset_nonce", strconv.FormatUint(currentValset.Nonce, 10))
+
+		s.settleRelayedTotals(ctx)
+
 		var pg loops.ParanoidGroup
 		if s.valsetRelayMode != ValsetRelayModeNone {
 			pg.Go(func() error {
@@ -56,6 +69,8 @@ func (s *gravityRelayer) Start(ctx context.Context) error {
 
 		if s.batchRelayEnabled {
 			pg.Go(func() error {
+				s.updateRelayHistory(ctx)
+
 				return retry.Do(func() error {
 
 					possibleBatches, err := s.getBatchesAndSignatures(ctx, *currentValset)
@@ -0,0 +1,27 @@
+package relayer
+
+import (
+	"context"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+)
+
+// MetaTxSubmitter submits transactions through a relay service paying their
+// gas, instead of the orchestrator's Ethereum account.
+type MetaTxSubmitter interface {
+	// Submit posts the signed transaction data and returns the transaction hash,
+	// or the ID of the task the service queued.
+	Submit(ctx context.Context, target ethcmn.Address, txData []byte, gasLimit uint64) (string, error)
+}
+
+// SetMetaTxSubmitter returns the relayer option posting the relayed batches to
+// the given relay service.
+func SetMetaTxSubmitter(submitter MetaTxSubmitter) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetMetaTxSubmitter(submitter) }
+}
+
+// SetMetaTxSubmitter makes the relayer post the batches it relays to a relay
+// service paying their gas, instead of broadcasting them itself.
+func (s *gravityRelayer) SetMetaTxSubmitter(submitter MetaTxSubmitter) {
+	s.metaTx = submitter
+}
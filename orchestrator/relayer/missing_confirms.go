@@ -0,0 +1,121 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/gravity"
+)
+
+// MissingConfirm is a validator set member that hasn't signed a valset or
+// batch.
+type MissingConfirm struct {
+	EthAddress       string `json:"eth_address" yaml:"eth_address"`
+	ValidatorAddress string `json:"validator_address,omitempty" yaml:"validator_address,omitempty"`
+	Moniker          string `json:"moniker,omitempty" yaml:"moniker,omitempty"`
+	Power            uint64 `json:"power" yaml:"power"`
+}
+
+// String returns the moniker (if known) and addresses of the validator.
+func (m MissingConfirm) String() string {
+	if m.Moniker == "" {
+		return fmt.Sprintf("%s (power %d)", m.EthAddress, m.Power)
+	}
+
+	return fmt.Sprintf("%s %s %s (power %d)", m.Moniker, m.ValidatorAddress, m.EthAddress, m.Power)
+}
+
+// SetStakingQueryClient returns the relayer option naming the validators with
+// missing confirms by moniker.
+func SetStakingQueryClient(q stakingtypes.QueryClient) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetStakingQueryClient(q) }
+}
+
+// SetStakingQueryClient sets the staking query client used to report missing
+// confirms by validator moniker.
+func (s *gravityRelayer) SetStakingQueryClient(q stakingtypes.QueryClient) {
+	s.stakingQueryClient = q
+}
+
+// ResolveMissingConfirms looks up the validator behind each member's Ethereum
+// address and its moniker. Failed lookups leave those fields empty, so a report
+// can still be made when the staking queries are unavailable.
+func ResolveMissingConfirms(
+	ctx context.Context,
+	gravityQueryClient gravitytypes.QueryClient,
+	stakingQueryClient stakingtypes.QueryClient,
+	members []gravitytypes.BridgeValidator,
+) []MissingConfirm {
+	missing := make([]MissingConfirm, len(members))
+
+	for i, m := range members {
+		missing[i] = MissingConfirm{
+			EthAddress: m.EthereumAddress,
+			Power:      m.Power,
+		}
+
+		keys, err := gravityQueryClient.GetDelegateKeyByEth(ctx, &gravitytypes.QueryDelegateKeysByEthAddress{
+			EthAddress: m.EthereumAddress,
+		})
+		if err != nil || keys == nil {
+			continue
+		}
+
+		missing[i].ValidatorAddress = keys.ValidatorAddress
+
+		if stakingQueryClient == nil {
+			continue
+		}
+
+		val, err := stakingQueryClient.Validator(ctx, &stakingtypes.QueryValidatorRequest{
+			ValidatorAddr: keys.ValidatorAddress,
+		})
+		if err != nil || val == nil {
+			continue
+		}
+
+		missing[i].Moniker = val.Validator.Description.Moniker
+	}
+
+	return missing
+}
+
+// reportMissingValsetConfirms logs the validators missing confirms if the power
+// that signed the new valset doesn't pass the power threshold of the current
+// valset on Ethereum, in which case the update would revert.
+func (s *gravityRelayer) reportMissingValsetConfirms(
+	ctx context.Context,
+	currentValset gravitytypes.Valset,
+	newValset gravitytypes.Valset,
+	confirms []gravitytypes.MsgValsetConfirm,
+) {
+	members, ok := gravity.MissingSigners(currentValset, valsetSigners(confirms))
+	if ok {
+		return
+	}
+
+	missing := ResolveMissingConfirms(ctx, s.cosmosQueryClient, s.stakingQueryClient, members)
+
+	validators := make([]string, len(missing))
+	for i, m := range missing {
+		validators[i] = m.String()
+	}
+
+	s.logger.Error().
+		Uint64("valset_nonce", newValset.Nonce).
+		Uint64("current_valset_nonce", currentValset.Nonce).
+		Strs("missing_confirms", validators).
+		Msg("not enough signing power to relay the valset update; validators are missing confirms")
+}
+
+func valsetSigners(confirms []gravitytypes.MsgValsetConfirm) []string {
+	signers := make([]string, len(confirms))
+	for i, c := range confirms {
+		signers[i] = c.EthAddress
+	}
+
+	return signers
+}
@@ -0,0 +1,76 @@
+package relayer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/umee-network/peggo/mocks"
+)
+
+type mockStakingQueryClient struct {
+	stakingtypes.QueryClient
+
+	monikers map[string]string
+}
+
+func (c *mockStakingQueryClient) Validator(
+	_ context.Context,
+	req *stakingtypes.QueryValidatorRequest,
+	_ ...grpc.CallOption,
+) (*stakingtypes.QueryValidatorResponse, error) {
+	moniker, ok := c.monikers[req.ValidatorAddr]
+	if !ok {
+		return nil, errors.New("validator not found")
+	}
+
+	return &stakingtypes.QueryValidatorResponse{
+		Validator: stakingtypes.Validator{
+			OperatorAddress: req.ValidatorAddr,
+			Description:     stakingtypes.Description{Moniker: moniker},
+		},
+	}, nil
+}
+
+func TestResolveMissingConfirms(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	members := []types.BridgeValidator{
+		{Power: 100, EthereumAddress: "0x0000000000000000000000000000000000000001"},
+		{Power: 200, EthereumAddress: "0x0000000000000000000000000000000000000002"},
+	}
+
+	mockQClient := mocks.NewMockQueryClient(mockCtrl)
+	mockQClient.EXPECT().
+		GetDelegateKeyByEth(gomock.Any(), &types.QueryDelegateKeysByEthAddress{EthAddress: members[0].EthereumAddress}).
+		Return(&types.QueryDelegateKeysByEthAddressResponse{ValidatorAddress: "umeevaloper1"}, nil)
+	mockQClient.EXPECT().
+		GetDelegateKeyByEth(gomock.Any(), &types.QueryDelegateKeysByEthAddress{EthAddress: members[1].EthereumAddress}).
+		Return(nil, errors.New("not found"))
+
+	stakingQClient := &mockStakingQueryClient{monikers: map[string]string{"umeevaloper1": "val1"}}
+
+	missing := ResolveMissingConfirms(context.Background(), mockQClient, stakingQClient, members)
+	assert.Equal(t, []MissingConfirm{
+		{
+			EthAddress:       members[0].EthereumAddress,
+			ValidatorAddress: "umeevaloper1",
+			Moniker:          "val1",
+			Power:            100,
+		},
+		{
+			EthAddress: members[1].EthereumAddress,
+			Power:      200,
+		},
+	}, missing)
+
+	assert.Equal(t, "val1 umeevaloper1 0x0000000000000000000000000000000000000001 (power 100)", missing[0].String())
+	assert.Equal(t, "0x0000000000000000000000000000000000000002 (power 200)", missing[1].String())
+}
@@ -0,0 +1,162 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/umee-network/peggo/orchestrator/oracle"
+)
+
+// Allowed decisions when a price is still missing after subscribing to it.
+const (
+	MissingPriceFallbackDeny  = "deny"
+	MissingPriceFallbackAllow = "allow"
+)
+
+// missingPricePollInterval is how often the oracle is checked for a newly
+// subscribed symbol. The oracle itself ticks every second.
+const missingPricePollInterval = 500 * time.Millisecond
+
+// missingPricePolicy defines how the relayer recovers from a price the oracle
+// doesn't have yet.
+type missingPricePolicy struct {
+	wait  time.Duration // how long to wait for a price after subscribing
+	allow bool          // whether batches are relayed if the price is still missing
+
+	mtx    sync.Mutex
+	waited map[string]struct{} // symbols we already waited for without success
+}
+
+// ValidateMissingPriceFallback returns an error if the fallback decision is not
+// one of the allowed values.
+func ValidateMissingPriceFallback(fallback string) error {
+	switch fallback {
+	case MissingPriceFallbackDeny, MissingPriceFallbackAllow:
+		return nil
+
+	default:
+		return fmt.Errorf(
+			"invalid missing price fallback: %s; expected %s or %s",
+			fallback, MissingPriceFallbackDeny, MissingPriceFallbackAllow,
+		)
+	}
+}
+
+// SetMissingPricePolicy returns the relayer option waiting up to wait for a
+// missing price, then relaying or not as the fallback says (see
+// MissingPriceFallbackAllow).
+func SetMissingPricePolicy(wait time.Duration, fallback string) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetMissingPricePolicy(wait, fallback) }
+}
+
+// SetMissingPricePolicy sets how long to wait for a price missing in the oracle
+// and whether batches are relayed when it's still missing after that.
+func (s *gravityRelayer) SetMissingPricePolicy(wait time.Duration, fallback string) {
+	s.missingPrice = &missingPricePolicy{
+		wait:   wait,
+		allow:  fallback == MissingPriceFallbackAllow,
+		waited: map[string]struct{}{},
+	}
+}
+
+// getPrice returns the USD price of a symbol. If the oracle doesn't have it, the
+// symbol gets subscribed and we wait (once per symbol) a bounded time for the
+// price to show up, so new tokens don't need to be added by hand.
+func (s *gravityRelayer) getPrice(ctx context.Context, symbol string) (decimal.Decimal, error) {
+	price, err := s.oracle.GetPrice(symbol)
+	if err == nil {
+		if s.oracle.IsStale(symbol) {
+			return decimal.Decimal{}, errors.Wrapf(oracle.ErrStalePrice, "%s", symbol)
+		}
+
+		s.missingPriceRecovered(symbol)
+		return s.profitabilityPrice(symbol, price)
+	}
+
+	if err := s.oracle.SubscribeSymbols(symbol); err != nil {
+		return decimal.Decimal{}, errors.Wrapf(err, "failed to subscribe to %s", symbol)
+	}
+
+	wait := s.missingPriceWait(symbol)
+	if wait <= 0 {
+		return decimal.Decimal{}, err
+	}
+
+	s.logger.Info().
+		Str("symbol", symbol).
+		Dur("wait", wait).
+		Msg("price missing in the oracle; subscribed and waiting for it")
+
+	timeout := time.NewTimer(wait)
+	defer timeout.Stop()
+
+	ticker := time.NewTicker(missingPricePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return decimal.Decimal{}, ctx.Err()
+
+		case <-timeout.C:
+			s.missingPrice.markWaited(symbol)
+			return decimal.Decimal{}, errors.Wrapf(err, "price still missing after %s", wait)
+
+		case <-ticker.C:
+			// a stale price isn't a recovered one; keep waiting for a fresh one
+			price, err := s.oracle.GetPrice(symbol)
+			if err != nil || s.oracle.IsStale(symbol) {
+				continue
+			}
+
+			s.missingPriceRecovered(symbol)
+			return s.profitabilityPrice(symbol, price)
+		}
+	}
+}
+
+// allowMissingPrice returns the fallback decision used when a price can't be
+// obtained.
+func (s *gravityRelayer) allowMissingPrice() bool {
+	return s.missingPrice != nil && s.missingPrice.allow
+}
+
+// missingPriceWait returns how long to wait for the price of a symbol. We only
+// wait once per symbol, so a symbol no provider lists doesn't block every loop.
+func (s *gravityRelayer) missingPriceWait(symbol string) time.Duration {
+	if s.missingPrice == nil {
+		return 0
+	}
+
+	s.missingPrice.mtx.Lock()
+	defer s.missingPrice.mtx.Unlock()
+
+	if _, ok := s.missingPrice.waited[symbol]; ok {
+		return 0
+	}
+
+	return s.missingPrice.wait
+}
+
+func (s *gravityRelayer) missingPriceRecovered(symbol string) {
+	if s.missingPrice == nil {
+		return
+	}
+
+	s.missingPrice.mtx.Lock()
+	defer s.missingPrice.mtx.Unlock()
+
+	delete(s.missingPrice.waited, symbol)
+}
+
+func (p *missingPricePolicy) markWaited(symbol string) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.waited[symbol] = struct{}{}
+}
@@ -0,0 +1,189 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+
+	"github.com/umee-network/peggo/orchestrator/oracle"
+)
+
+// lazyOracle only returns prices for symbols after they have been subscribed
+// and a few price requests have gone by, like the real oracle does.
+type lazyOracle struct {
+	mtx        sync.Mutex
+	prices     map[string]sdk.Dec
+	subscribed map[string]int // symbol => GetPrice calls since subscription
+	delay      int
+	stale      map[string]bool
+	smoothed   map[string]sdk.Dec
+}
+
+func (m *lazyOracle) GetPrices(baseSymbols ...string) (map[string]sdk.Dec, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *lazyOracle) GetPrice(baseSymbol string) (sdk.Dec, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	calls, ok := m.subscribed[baseSymbol]
+	if !ok || calls < m.delay {
+		if ok {
+			m.subscribed[baseSymbol]++
+		}
+		return sdk.Dec{}, fmt.Errorf("error getting price for %s", baseSymbol)
+	}
+
+	price, ok := m.prices[baseSymbol]
+	if !ok {
+		return sdk.Dec{}, fmt.Errorf("error getting price for %s", baseSymbol)
+	}
+
+	return price, nil
+}
+
+func (m *lazyOracle) GetSmoothedPrice(baseSymbol string, _ time.Duration) (sdk.Dec, error) {
+	price, err := m.GetPrice(baseSymbol)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if smoothed, ok := m.smoothed[baseSymbol]; ok {
+		return smoothed, nil
+	}
+
+	return price, nil
+}
+
+func (m *lazyOracle) IsStale(baseSymbol string) bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	return m.stale[baseSymbol]
+}
+
+func (m *lazyOracle) ConvertValue(amount sdk.Int, decimals uint8, symbol string) (sdk.Dec, error) {
+	price, err := m.GetPrice(symbol)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+
+	return oracle.USDValue(amount, decimals, price), nil
+}
+
+func (m *lazyOracle) SubscribeSymbols(baseSymbols ...string) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for _, s := range baseSymbols {
+		if _, ok := m.subscribed[s]; !ok {
+			m.subscribed[s] = 0
+		}
+	}
+
+	return nil
+}
+
+func (m *lazyOracle) Ready() <-chan struct{} {
+	ready := make(chan struct{})
+	close(ready)
+
+	return ready
+}
+
+func TestGetPriceMissing(t *testing.T) {
+	o := &lazyOracle{
+		prices:     map[string]sdk.Dec{"USDT": sdk.MustNewDecFromStr("0.998")},
+		subscribed: map[string]int{},
+		delay:      2,
+	}
+
+	relayer := gravityRelayer{logger: zerolog.Nop(), oracle: o}
+	relayer.SetMissingPricePolicy(5*time.Second, MissingPriceFallbackDeny)
+
+	// The price shows up after subscribing and waiting a bit.
+	price, err := relayer.getPrice(context.Background(), "USDT")
+	require.NoError(t, err)
+	assert.Equal(t, "0.998", price.String())
+
+	// The price never shows up; we only wait for it once.
+	relayer.SetMissingPricePolicy(time.Second, MissingPriceFallbackAllow)
+
+	start := time.Now()
+	_, err = relayer.getPrice(context.Background(), "FOO")
+	assert.Error(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+
+	start = time.Now()
+	_, err = relayer.getPrice(context.Background(), "FOO")
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+
+	assert.True(t, relayer.allowMissingPrice())
+
+	// No policy means no waiting and the batch is denied.
+	relayer.missingPrice = nil
+	_, err = relayer.getPrice(context.Background(), "BAR")
+	assert.Error(t, err)
+	assert.False(t, relayer.allowMissingPrice())
+
+	assert.Error(t, ValidateMissingPriceFallback("maybe"))
+	assert.NoError(t, ValidateMissingPriceFallback(MissingPriceFallbackAllow))
+}
+
+func TestGetPriceStale(t *testing.T) {
+	o := &lazyOracle{
+		prices:     map[string]sdk.Dec{"USDT": sdk.MustNewDecFromStr("0.998")},
+		subscribed: map[string]int{"USDT": 0},
+		stale:      map[string]bool{"USDT": true},
+	}
+
+	relayer := gravityRelayer{logger: zerolog.Nop(), oracle: o}
+	relayer.SetMissingPricePolicy(time.Second, MissingPriceFallbackAllow)
+
+	// Stale prices are refused, even when missing prices are allowed.
+	_, err := relayer.getPrice(context.Background(), "USDT")
+	assert.ErrorIs(t, err, oracle.ErrStalePrice)
+	assert.False(t, relayer.missingPriceFallback(err, "USDT", types.OutgoingTxBatch{}))
+	assert.True(t, relayer.missingPriceFallback(fmt.Errorf("missing"), "USDT", types.OutgoingTxBatch{}))
+}
+
+func TestGetPriceRecoveredSmoothed(t *testing.T) {
+	o := &lazyOracle{
+		prices: map[string]sdk.Dec{
+			"USDT": sdk.MustNewDecFromStr("1.2"),
+			"FOO":  sdk.MustNewDecFromStr("3"),
+		},
+		smoothed:   map[string]sdk.Dec{"USDT": sdk.MustNewDecFromStr("0.998")},
+		subscribed: map[string]int{},
+		delay:      2,
+		stale:      map[string]bool{"FOO": true},
+	}
+
+	relayer := gravityRelayer{logger: zerolog.Nop(), oracle: o}
+	relayer.SetMissingPricePolicy(5*time.Second, MissingPriceFallbackDeny)
+	relayer.SetPriceSmoothing(time.Hour)
+
+	// The recovered price is smoothed like any other, not priced at spot.
+	price, err := relayer.getPrice(context.Background(), "USDT")
+	require.NoError(t, err)
+	assert.Equal(t, "0.998", price.String())
+
+	// A price showing up stale is still missing once the wait is over.
+	relayer.SetMissingPricePolicy(time.Second, MissingPriceFallbackDeny)
+	_, err = relayer.getPrice(context.Background(), "FOO")
+	assert.Error(t, err)
+}
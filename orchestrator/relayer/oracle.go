@@ -1,6 +1,8 @@
 package relayer
 
 import (
+	"time"
+
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
@@ -12,7 +14,23 @@ type Oracle interface {
 	// GetPrice returns the price based on the base symbol ex.: UMEE, ETH.
 	GetPrice(baseSymbol string) (sdk.Dec, error)
 
+	// GetSmoothedPrice returns the exponential moving average of the prices of
+	// a base symbol computed within the window.
+	GetSmoothedPrice(baseSymbol string, window time.Duration) (sdk.Dec, error)
+
+	// IsStale returns whether the price of a symbol is missing or older than
+	// the max age of the oracle.
+	IsStale(baseSymbol string) bool
+
+	// ConvertValue returns the USD value of an amount of the token with the given
+	// symbol, expressed in its smallest unit.
+	ConvertValue(amount sdk.Int, decimals uint8, symbol string) (sdk.Dec, error)
+
 	// SubscribeSymbols attempts to subscribe the symbols in all the providers.
 	// baseSymbols is the base to be subscribed ex.: ["UMEE", "ATOM"].
 	SubscribeSymbols(baseSymbols ...string) error
+
+	// Ready returns a channel closed once the oracle completed its first price
+	// computation, successful or not.
+	Ready() <-chan struct{}
 }
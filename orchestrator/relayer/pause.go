@@ -0,0 +1,74 @@
+package relayer
+
+import (
+	"time"
+
+	"github.com/umee-network/peggo/orchestrator/store"
+)
+
+// pauseStoreKey is the store key holding the relayer pause state.
+const pauseStoreKey = "relayer_pause"
+
+// PauseState is the persisted state of the relaying pause switch.
+type PauseState struct {
+	Paused    bool      `json:"paused"`
+	Reason    string    `json:"reason,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// GetPauseState returns the current relaying pause state. A missing state means
+// relaying is not paused.
+func GetPauseState(s *store.Store) (PauseState, error) {
+	var state PauseState
+	if _, err := s.Get(pauseStoreKey, &state); err != nil {
+		return PauseState{}, err
+	}
+
+	return state, nil
+}
+
+// SetPauseState pauses or resumes relaying. The state is persisted, so it
+// survives restarts, and a running orchestrator checks it before sending each
+// valset update or batch.
+func SetPauseState(s *store.Store, paused bool, reason string) error {
+	return s.Set(pauseStoreKey, PauseState{
+		Paused:    paused,
+		Reason:    reason,
+		UpdatedAt: time.Now().UTC(),
+	})
+}
+
+// SetStore returns the relayer option reading the pause switch from the given
+// store.
+func SetStore(s *store.Store) func(GravityRelayer) {
+	return func(r GravityRelayer) { r.SetStore(s) }
+}
+
+// SetStore sets the store isPaused reads the pause switch from. A nil store
+// never pauses relaying.
+func (s *gravityRelayer) SetStore(st *store.Store) {
+	s.store = st
+}
+
+// isPaused reports whether relaying was paused by the operator. If the state
+// cannot be read we fail closed, as not spending is always the safe option.
+func (s *gravityRelayer) isPaused() bool {
+	if s.store == nil {
+		return false
+	}
+
+	state, err := GetPauseState(s.store)
+	if err != nil {
+		s.logger.Err(err).Msg("failed to read relayer pause state; not relaying")
+		return true
+	}
+
+	if state.Paused {
+		s.logger.Warn().
+			Str("reason", state.Reason).
+			Time("paused_at", state.UpdatedAt).
+			Msg("relaying is paused; skipping")
+	}
+
+	return state.Paused
+}
@@ -0,0 +1,116 @@
+package relayer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/gravity"
+)
+
+// SetRelayOnlyWhenPivotal returns the relayer option leaving to other relayers
+// the batches and valset updates this validator's confirm isn't needed for, for
+// up to timeout. Zero skips them until they're relayed by another relayer.
+func SetRelayOnlyWhenPivotal(enabled bool, timeout time.Duration) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetRelayOnlyWhenPivotal(enabled, timeout) }
+}
+
+// SetRelayOnlyWhenPivotal makes the relayer skip the batches and valset updates
+// whose confirms pass the power threshold without this validator's one, for up
+// to timeout.
+func (s *gravityRelayer) SetRelayOnlyWhenPivotal(enabled bool, timeout time.Duration) {
+	s.notPivotalMtx.Lock()
+	defer s.notPivotalMtx.Unlock()
+
+	s.onlyWhenPivotal = enabled
+	s.pivotalTimeout = timeout
+	s.notPivotalSince = map[string]time.Time{}
+}
+
+// shouldRelay reports whether the relayer should submit the batch or valset
+// update identified by key, signed by the given Ethereum signers. When relaying
+// only when pivotal, that is only the case if our own confirm is required to
+// pass the power threshold of the valset stored on Ethereum, so large relayer
+// sets don't all race to submit the same update.
+//
+// Every relayer skipping an update it isn't pivotal for could leave it pending
+// forever, e.g. if the validators whose confirms are enough all run with the
+// option, or their relayers are down. The update is thus relayed anyway once it
+// has been skipped for pivotalTimeout.
+func (s *gravityRelayer) shouldRelay(key string, currentValset gravitytypes.Valset, signers []string) bool {
+	if !s.onlyWhenPivotal {
+		return true
+	}
+
+	ourAddress := s.gravityContract.FromAddress()
+
+	var (
+		others []string
+		signed bool
+	)
+	for _, signer := range signers {
+		if ethcmn.HexToAddress(signer) == ourAddress {
+			signed = true
+			continue
+		}
+
+		others = append(others, signer)
+	}
+
+	s.notPivotalMtx.Lock()
+	defer s.notPivotalMtx.Unlock()
+
+	if signed {
+		if _, enoughPower := gravity.MissingSigners(currentValset, others); !enoughPower {
+			delete(s.notPivotalSince, key)
+			return true
+		}
+	}
+
+	if s.pivotalTimeout <= 0 {
+		return false
+	}
+
+	since, ok := s.notPivotalSince[key]
+	if !ok {
+		s.notPivotalSince[key] = time.Now()
+		return false
+	}
+
+	if time.Since(since) < s.pivotalTimeout {
+		return false
+	}
+
+	s.logger.Info().
+		Str("update", key).
+		Dur("skipped_for", time.Since(since)).
+		Msg("our confirm isn't pivotal, but nobody relayed the update in time; relaying it")
+
+	return true
+}
+
+// forgetNotPivotal drops the updates of the given kind ("batch" or "valset")
+// skipped as not pivotal that are no longer among the pending ones, e.g. once
+// they were relayed.
+func (s *gravityRelayer) forgetNotPivotal(kind string, pending map[string]struct{}) {
+	s.notPivotalMtx.Lock()
+	defer s.notPivotalMtx.Unlock()
+
+	for key := range s.notPivotalSince {
+		if _, ok := pending[key]; !ok && strings.HasPrefix(key, kind+"/") {
+			delete(s.notPivotalSince, key)
+		}
+	}
+}
+
+func batchRelayKey(batch gravitytypes.OutgoingTxBatch) string {
+	return fmt.Sprintf("batch/%d", batch.BatchNonce)
+}
+
+func valsetRelayKey(valset gravitytypes.Valset) string {
+	return fmt.Sprintf("valset/%d", valset.Nonce)
+}
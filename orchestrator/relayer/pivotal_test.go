@@ -0,0 +1,132 @@
+package relayer
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	gravityMocks "github.com/umee-network/peggo/mocks/gravity"
+)
+
+func TestShouldRelay(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	const (
+		ours   = "0x0000000000000000000000000000000000000001"
+		other1 = "0x0000000000000000000000000000000000000002"
+		other2 = "0x0000000000000000000000000000000000000003"
+		other3 = "0x0000000000000000000000000000000000000004"
+	)
+
+	valset := types.Valset{
+		Members: []types.BridgeValidator{
+			{Power: 1000000000, EthereumAddress: ours},
+			{Power: 1100000000, EthereumAddress: other1},
+			{Power: 1100000000, EthereumAddress: other2},
+			{Power: 1094967296, EthereumAddress: other3},
+		},
+	}
+
+	mockGravityContract := gravityMocks.NewMockContract(mockCtrl)
+	mockGravityContract.EXPECT().FromAddress().Return(ethcmn.HexToAddress(ours)).AnyTimes()
+
+	relayer := &gravityRelayer{logger: zerolog.Nop(), gravityContract: mockGravityContract}
+
+	// without the option, anything that passes the threshold is relayed
+	assert.True(t, relayer.shouldRelay("batch/1", valset, []string{ours, other1, other2, other3}))
+
+	SetRelayOnlyWhenPivotal(true, 0)(relayer)
+
+	// the other confirms pass the threshold on their own
+	assert.False(t, relayer.shouldRelay("batch/1", valset, []string{ours, other1, other2, other3}))
+	// without our confirm, the other two don't pass it
+	assert.True(t, relayer.shouldRelay("batch/1", valset, []string{ours, other1, other2}))
+	// we didn't sign, so our confirm can't be pivotal
+	assert.False(t, relayer.shouldRelay("batch/1", valset, []string{other1, other2, other3}))
+}
+
+func TestShouldRelayAfterPivotalTimeout(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	const (
+		ours  = "0x0000000000000000000000000000000000000001"
+		other = "0x0000000000000000000000000000000000000002"
+	)
+
+	valset := types.Valset{
+		Members: []types.BridgeValidator{
+			{Power: 1000000000, EthereumAddress: ours},
+			{Power: 3294967296, EthereumAddress: other},
+		},
+	}
+
+	mockGravityContract := gravityMocks.NewMockContract(mockCtrl)
+	mockGravityContract.EXPECT().FromAddress().Return(ethcmn.HexToAddress(ours)).AnyTimes()
+
+	relayer := &gravityRelayer{logger: zerolog.Nop(), gravityContract: mockGravityContract}
+	SetRelayOnlyWhenPivotal(true, time.Minute)(relayer)
+
+	// skipped until the update has been pending for the timeout
+	assert.False(t, relayer.shouldRelay("valset/2", valset, []string{ours, other}))
+	assert.False(t, relayer.shouldRelay("valset/2", valset, []string{ours, other}))
+
+	relayer.notPivotalSince["valset/2"] = time.Now().Add(-time.Minute)
+	assert.True(t, relayer.shouldRelay("valset/2", valset, []string{ours, other}))
+
+	// once the update is gone, its timeout starts over if it shows up again
+	relayer.forgetNotPivotal("batch", nil)
+	assert.Contains(t, relayer.notPivotalSince, "valset/2")
+	relayer.forgetNotPivotal("valset", nil)
+	assert.Empty(t, relayer.notPivotalSince)
+}
+
+func TestShouldRelayConcurrentLoops(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	const (
+		ours  = "0x0000000000000000000000000000000000000001"
+		other = "0x0000000000000000000000000000000000000002"
+	)
+
+	valset := types.Valset{
+		Members: []types.BridgeValidator{
+			{Power: 1000000000, EthereumAddress: ours},
+			{Power: 3294967296, EthereumAddress: other},
+		},
+	}
+
+	mockGravityContract := gravityMocks.NewMockContract(mockCtrl)
+	mockGravityContract.EXPECT().FromAddress().Return(ethcmn.HexToAddress(ours)).AnyTimes()
+
+	relayer := &gravityRelayer{logger: zerolog.Nop(), gravityContract: mockGravityContract}
+	SetRelayOnlyWhenPivotal(true, time.Minute)(relayer)
+
+	// RelayValsets and RelayBatches run in parallel in the relayer loop, each
+	// skipping and forgetting its own updates; run with -race
+	var wg sync.WaitGroup
+	for _, kind := range []string{"valset", "batch"} {
+		wg.Add(1)
+		go func(kind string) {
+			defer wg.Done()
+
+			for nonce := 0; nonce < 100; nonce++ {
+				key := fmt.Sprintf("%s/%d", kind, nonce)
+				relayer.forgetNotPivotal(kind, map[string]struct{}{key: {}})
+				assert.False(t, relayer.shouldRelay(key, valset, []string{ours, other}))
+			}
+		}(kind)
+	}
+	wg.Wait()
+
+	assert.Len(t, relayer.notPivotalSince, 2)
+}
@@ -0,0 +1,144 @@
+package relayer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type pricePoint struct {
+	price decimal.Decimal
+	time  time.Time
+}
+
+// priceBreaker keeps a short history of the prices used for profitability
+// calculations and trips when a price moves more than maxDeviation within
+// window. While tripped, only batches that are profitable by a wider margin
+// are relayed, so we don't spend gas based on a price that is mid-crash.
+type priceBreaker struct {
+	mtx          sync.Mutex
+	maxDeviation decimal.Decimal // fraction, e.g.: 0.1 means 10%
+	window       time.Duration
+	extraMargin  decimal.Decimal // fraction added to the profit multiplier while tripped
+	history      map[string][]pricePoint
+	now          func() time.Time
+}
+
+func newPriceBreaker(maxDeviation float64, window time.Duration, extraMargin float64) *priceBreaker {
+	return &priceBreaker{
+		maxDeviation: decimal.NewFromFloat(maxDeviation),
+		window:       window,
+		extraMargin:  decimal.NewFromFloat(extraMargin),
+		history:      map[string][]pricePoint{},
+		now:          time.Now,
+	}
+}
+
+// observe records the price of a symbol and returns true if it moved more than
+// the allowed deviation within the configured window.
+func (b *priceBreaker) observe(symbol string, price decimal.Decimal) bool {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	points := b.record(symbol, price)
+
+	low, high := points[0].price, points[0].price
+	for _, p := range points[1:] {
+		low = decimal.Min(low, p.price)
+		high = decimal.Max(high, p.price)
+	}
+
+	if !low.IsPositive() {
+		return false
+	}
+
+	return high.Sub(low).Div(low).GreaterThan(b.maxDeviation)
+}
+
+// sample records the price of a symbol without checking it, so moves happening
+// between batches are caught by the next observe call.
+func (b *priceBreaker) sample(symbol string, price decimal.Decimal) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.record(symbol, price)
+}
+
+// record appends a price point to the history of a symbol, dropping the ones
+// out of the window, and returns the remaining points. The caller must hold
+// the lock.
+func (b *priceBreaker) record(symbol string, price decimal.Decimal) []pricePoint {
+	now := b.now()
+	cutoff := now.Add(-b.window)
+
+	points := b.history[symbol][:0]
+	for _, p := range b.history[symbol] {
+		if p.time.After(cutoff) {
+			points = append(points, p)
+		}
+	}
+	points = append(points, pricePoint{price: price, time: now})
+	b.history[symbol] = points
+
+	return points
+}
+
+// symbols returns the symbols with a price history.
+func (b *priceBreaker) symbols() []string {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	symbols := make([]string, 0, len(b.history))
+	for symbol := range b.history {
+		symbols = append(symbols, symbol)
+	}
+
+	return symbols
+}
+
+// samplePrices records the current price of the gas asset and of the fee tokens
+// priced so far in the price breaker. It's done on every relayer loop, so a
+// price moving while no batch is pending still trips the breaker.
+func (s *gravityRelayer) samplePrices() {
+	if s.priceBreaker == nil || s.oracle == nil {
+		return
+	}
+
+	symbols := map[string]struct{}{s.GetGasAssetSymbol(): {}}
+	for _, symbol := range s.priceBreaker.symbols() {
+		symbols[symbol] = struct{}{}
+	}
+
+	for symbol := range symbols {
+		spot, err := s.oracle.GetPrice(symbol)
+		if err != nil || s.oracle.IsStale(symbol) {
+			continue
+		}
+
+		price, err := s.profitabilityPrice(symbol, spot)
+		if err != nil {
+			continue
+		}
+
+		s.priceBreaker.sample(symbol, price)
+	}
+}
+
+// SetPriceBreaker returns the relayer option requiring extraMargin more profit,
+// or holding the batch, when a price moved more than maxDeviation within
+// window.
+func SetPriceBreaker(maxDeviation float64, window time.Duration, extraMargin float64) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetPriceBreaker(maxDeviation, window, extraMargin) }
+}
+
+// SetPriceBreaker replaces the price breaker, dropping the sampled prices. A
+// zero maxDeviation or window disables it.
+func (s *gravityRelayer) SetPriceBreaker(maxDeviation float64, window time.Duration, extraMargin float64) {
+	if maxDeviation <= 0 || window <= 0 {
+		s.priceBreaker = nil
+		return
+	}
+
+	s.priceBreaker = newPriceBreaker(maxDeviation, window, extraMargin)
+}
@@ -0,0 +1,73 @@
+package relayer
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceBreakerObserve(t *testing.T) {
+	now := time.Now()
+	breaker := newPriceBreaker(0.1, 10*time.Minute, 0.5)
+	breaker.now = func() time.Time { return now }
+
+	assert.False(t, breaker.observe("ETH", decimal.NewFromInt(1000)))
+
+	now = now.Add(time.Minute)
+	assert.False(t, breaker.observe("ETH", decimal.NewFromInt(1050)))
+	assert.False(t, breaker.observe("USDT", decimal.NewFromInt(1)))
+
+	// 1000 -> 850 is a 23% move within the window.
+	now = now.Add(time.Minute)
+	assert.True(t, breaker.observe("ETH", decimal.NewFromInt(850)))
+
+	// Old points fall out of the window and the breaker closes again.
+	now = now.Add(11 * time.Minute)
+	assert.False(t, breaker.observe("ETH", decimal.NewFromInt(860)))
+	assert.Len(t, breaker.history["ETH"], 1)
+}
+
+func TestSamplePrices(t *testing.T) {
+	now := time.Now()
+	breaker := newPriceBreaker(0.1, 10*time.Minute, 0.5)
+	breaker.now = func() time.Time { return now }
+
+	o := &lazyOracle{
+		prices: map[string]sdk.Dec{
+			"ETH":  sdk.MustNewDecFromStr("1000"),
+			"USDT": sdk.MustNewDecFromStr("1"),
+		},
+		subscribed: map[string]int{"ETH": 0, "USDT": 0},
+	}
+	relayer := &gravityRelayer{logger: zerolog.Nop(), oracle: o, priceBreaker: breaker}
+
+	// USDT was priced for a batch, ETH is always sampled.
+	assert.False(t, breaker.observe("USDT", decimal.NewFromInt(1)))
+	relayer.samplePrices()
+
+	// Both prices move while no batch is pending.
+	now = now.Add(time.Minute)
+	o.prices["ETH"] = sdk.MustNewDecFromStr("800")
+	o.prices["USDT"] = sdk.MustNewDecFromStr("0.8")
+	relayer.samplePrices()
+
+	// The next batch sees the moves.
+	now = now.Add(time.Minute)
+	assert.True(t, breaker.observe("ETH", decimal.NewFromInt(800)))
+	assert.True(t, breaker.observe("USDT", decimal.RequireFromString("0.8")))
+	assert.ElementsMatch(t, []string{"ETH", "USDT"}, breaker.symbols())
+}
+
+func TestSetPriceBreaker(t *testing.T) {
+	relayer := &gravityRelayer{}
+
+	relayer.SetPriceBreaker(0.1, time.Minute, 0.5)
+	assert.NotNil(t, relayer.priceBreaker)
+
+	relayer.SetPriceBreaker(0, time.Minute, 0.5)
+	assert.Nil(t, relayer.priceBreaker)
+}
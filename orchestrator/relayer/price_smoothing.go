@@ -0,0 +1,37 @@
+package relayer
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/shopspring/decimal"
+)
+
+// SetPriceSmoothing returns the relayer option averaging the oracle prices over
+// the given window in profitability checks.
+func SetPriceSmoothing(window time.Duration) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetPriceSmoothing(window) }
+}
+
+// SetPriceSmoothing sets the window of the moving average of the oracle prices
+// used when performing profitable batch calculations, so a single-tick spike
+// doesn't flap the profitability of batches. A zero window disables it, and
+// the last oracle prices are used instead.
+func (s *gravityRelayer) SetPriceSmoothing(window time.Duration) {
+	s.priceSmoothing = window
+}
+
+// profitabilityPrice returns the price batch profitability is calculated with,
+// given the last oracle price of a symbol.
+func (s *gravityRelayer) profitabilityPrice(symbol string, spot sdk.Dec) (decimal.Decimal, error) {
+	if s.priceSmoothing <= 0 {
+		return decimal.NewFromString(spot.String())
+	}
+
+	smoothed, err := s.oracle.GetSmoothedPrice(symbol, s.priceSmoothing)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	return decimal.NewFromString(smoothed.String())
+}
@@ -0,0 +1,51 @@
+package relayer
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smoothingOracle smooths every price to a fixed one.
+type smoothingOracle struct {
+	mockOracle
+	smoothed sdk.Dec
+}
+
+func (m smoothingOracle) GetSmoothedPrice(string, time.Duration) (sdk.Dec, error) {
+	return m.smoothed, nil
+}
+
+func TestPriceSmoothing(t *testing.T) {
+	relayer := &gravityRelayer{
+		oracle: smoothingOracle{
+			mockOracle: mockOracle{prices: map[string]sdk.Dec{"ETH": sdk.NewDec(2000)}},
+			smoothed:   sdk.NewDec(1500),
+		},
+	}
+	oneEth := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+
+	// disabled by default
+	price, err := relayer.profitabilityPrice("ETH", sdk.NewDec(2000))
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(2000).Equal(price), price)
+
+	value, err := relayer.convertValue(oneEth, 18, "ETH")
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(2000).Equal(value), value)
+
+	relayer.SetPriceSmoothing(time.Minute)
+
+	price, err = relayer.profitabilityPrice("ETH", sdk.NewDec(2000))
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(1500).Equal(price), price)
+
+	value, err = relayer.convertValue(oneEth, 18, "ETH")
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(1500).Equal(value), value)
+}
@@ -2,14 +2,20 @@ package relayer
 
 import (
 	"context"
+	"math/rand"
+	"sync"
 	"time"
 
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
 	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 
 	gravity "github.com/umee-network/peggo/orchestrator/ethereum/gravity"
 	"github.com/umee-network/peggo/orchestrator/ethereum/provider"
+	"github.com/umee-network/peggo/orchestrator/store"
+	"github.com/umee-network/peggo/orchestrator/totals"
+	"github.com/umee-network/peggo/solwrappers/versions"
 
 	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
 )
@@ -51,21 +57,110 @@ type GravityRelayer interface {
 	// batch calculations.
 	SetOracle(Oracle)
 
+	// SetStore sets the local store used to read the relaying pause switch.
+	SetStore(*store.Store)
+
+	// SetPriceBreaker sets the price deviation circuit breaker used when
+	// performing profitable batch calculations.
+	SetPriceBreaker(maxDeviation float64, window time.Duration, extraMargin float64)
+
+	// SetMissingPricePolicy sets how long to wait for a price missing in the
+	// oracle and whether batches are relayed when it's still missing.
+	SetMissingPricePolicy(wait time.Duration, fallback string)
+
+	// SetDenylist sets the list of destination addresses the relayer won't send
+	// batches to.
+	SetDenylist(*Denylist)
+
+	// SetStakingQueryClient sets the staking query client used to report
+	// missing confirms by validator moniker.
+	SetStakingQueryClient(stakingtypes.QueryClient)
+
+	// SetGasAssetSymbol sets the symbol of the asset paying for gas on the
+	// target EVM chain, used to price relaying costs in USD.
+	SetGasAssetSymbol(symbol string)
+
+	// SetRelayOnlyWhenPivotal makes the relayer skip the batches and valset
+	// updates whose confirms pass the power threshold without our own, for up
+	// to timeout.
+	SetRelayOnlyWhenPivotal(enabled bool, timeout time.Duration)
+
+	// SetRelayJitter sets the maximum random delay the relayer waits before
+	// submitting a batch.
+	SetRelayJitter(max time.Duration)
+
+	// SetRewardAddresses sets the addresses the fees of the relayed batches
+	// must be paid to.
+	SetRewardAddresses(addresses []ethcmn.Address)
+
+	// SetGravityVersion sets the Gravity.sol version of the deployment, which
+	// tells who the fees of the relayed batches are paid to.
+	SetGravityVersion(version versions.Version)
+
+	// SetRelayGracePeriod sets how long the relayer leaves each batch to its
+	// preferred relayer before relaying it.
+	SetRelayGracePeriod(grace time.Duration)
+
+	// SetTotals sets the persisted totals the relayed txs are counted in.
+	SetTotals(*totals.Totals)
+
+	// SetGasPriceSmoothing sets the window of the moving average of the gas
+	// price used when performing profitable batch calculations.
+	SetGasPriceSmoothing(window time.Duration)
+
+	// SetPriceSmoothing sets the window of the moving average of the oracle
+	// prices used when performing profitable batch calculations.
+	SetPriceSmoothing(window time.Duration)
+
+	// SetMaxValueConcentration sets the maximum percentage of the value of a
+	// batch that can flow to a single fresh address before the batch is held
+	// for manual approval.
+	SetMaxValueConcentration(maxPercent float64)
+
+	// SetMetaTxSubmitter makes the relayer post the batches it relays to a
+	// relay service paying their gas.
+	SetMetaTxSubmitter(MetaTxSubmitter)
+
 	GetProfitMultiplier() float64
+
+	// GetGasAssetSymbol returns the symbol of the asset paying for gas on the
+	// target EVM chain.
+	GetGasAssetSymbol() string
 }
 
 type gravityRelayer struct {
-	logger            zerolog.Logger
-	cosmosQueryClient gravitytypes.QueryClient
-	gravityContract   gravity.Contract
-	ethProvider       provider.EVMProvider
-	valsetRelayMode   ValsetRelayMode
-	batchRelayEnabled bool
-	loopDuration      time.Duration
-	pendingTxWait     time.Duration
-	profitMultiplier  float64
-	symbolRetriever   SymbolRetriever
-	oracle            Oracle
+	logger             zerolog.Logger
+	cosmosQueryClient  gravitytypes.QueryClient
+	stakingQueryClient stakingtypes.QueryClient
+	gravityContract    gravity.Contract
+	ethProvider        provider.EVMProvider
+	valsetRelayMode    ValsetRelayMode
+	batchRelayEnabled  bool
+	loopDuration       time.Duration
+	pendingTxWait      time.Duration
+	profitMultiplier   float64
+	symbolRetriever    SymbolRetriever
+	oracle             Oracle
+	store              *store.Store
+	priceBreaker       *priceBreaker
+	gasPriceSmoother   *gasPriceSmoother
+	priceSmoothing     time.Duration
+	missingPrice       *missingPricePolicy
+	denylist           *Denylist
+	gasAsset           string
+	onlyWhenPivotal    bool
+	pivotalTimeout     time.Duration
+	notPivotalSince    map[string]time.Time // update key => when first skipped as not pivotal
+	notPivotalMtx      sync.Mutex           // guards notPivotalSince, shared by the valset and batch loops
+	relayJitter        time.Duration
+	jitterRand         *rand.Rand
+	relayGrace         time.Duration
+	batchesSeen        map[uint64]time.Time // batchNonce => when first seen submittable, while in its grace period
+	totals             *totals.Totals
+	maxConcentration   float64
+	metaTx             MetaTxSubmitter
+	rewardAddresses    map[ethcmn.Address]struct{}
+	gravityVersion     versions.Version
 
 	// Store locally the last tx this validator made to avoid sending duplicates
 	// or invalid txs.
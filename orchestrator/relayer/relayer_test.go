@@ -11,6 +11,7 @@ import (
 	"github.com/umee-network/peggo/mocks"
 	gravityMocks "github.com/umee-network/peggo/mocks/gravity"
 	"github.com/umee-network/peggo/orchestrator/ethereum/provider"
+	"github.com/umee-network/peggo/orchestrator/store"
 )
 
 func TestNewGravityRelayer(t *testing.T) {
@@ -35,3 +36,25 @@ func TestNewGravityRelayer(t *testing.T) {
 
 	assert.NotNil(t, relayer)
 }
+
+func TestRelayerPause(t *testing.T) {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+	s, err := store.New(t.TempDir())
+	assert.NoError(t, err)
+
+	relayer := gravityRelayer{logger: logger}
+	assert.False(t, relayer.isPaused())
+
+	relayer.SetStore(s)
+	assert.False(t, relayer.isPaused())
+
+	assert.NoError(t, SetPauseState(s, true, "market chaos"))
+	assert.True(t, relayer.isPaused())
+
+	state, err := GetPauseState(s)
+	assert.NoError(t, err)
+	assert.Equal(t, "market chaos", state.Reason)
+
+	assert.NoError(t, SetPauseState(s, false, ""))
+	assert.False(t, relayer.isPaused())
+}
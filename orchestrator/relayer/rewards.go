@@ -0,0 +1,83 @@
+package relayer
+
+import (
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+
+	"github.com/umee-network/peggo/solwrappers/versions"
+)
+
+// SetRewardAddresses returns the relayer option only relaying the batches whose
+// fees are paid to one of the given addresses, so it never relays batches for
+// the benefit of another relayer. No address disables the check.
+func SetRewardAddresses(addresses []ethcmn.Address) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetRewardAddresses(addresses) }
+}
+
+// SetRewardAddresses makes the relayer only relay the batches whose fees are
+// paid to one of the given addresses.
+func (s *gravityRelayer) SetRewardAddresses(addresses []ethcmn.Address) {
+	s.rewardAddresses = make(map[ethcmn.Address]struct{}, len(addresses))
+	for _, addr := range addresses {
+		s.rewardAddresses[addr] = struct{}{}
+	}
+}
+
+// SetGravityVersion makes the relayer tell who the batch fees are paid to as
+// the given Gravity.sol version does.
+func SetGravityVersion(version versions.Version) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetGravityVersion(version) }
+}
+
+// SetGravityVersion sets the Gravity.sol version of the deployment.
+func (s *gravityRelayer) SetGravityVersion(version versions.Version) {
+	s.gravityVersion = version
+}
+
+// rewardRecipient returns the address the fees of a batch submitted with the
+// given tx data would be paid to. A version with directed rewards pays them to
+// the recipient in the submitBatch arguments. Gravity.sol v1 pays them to the
+// sender of the tx: our own account, unless the batch is posted to a
+// meta-transaction service, whose account sends it then and gets the fees.
+func (s *gravityRelayer) rewardRecipient(txData []byte) (ethcmn.Address, bool) {
+	if s.gravityVersion.DirectedRewards() {
+		recipient, err := s.gravityVersion.RewardRecipient(txData)
+		if err != nil {
+			s.logger.Err(err).Msg("failed to get the reward recipient of the batch")
+			return ethcmn.Address{}, false
+		}
+
+		return recipient, true
+	}
+
+	if s.metaTx != nil {
+		return ethcmn.Address{}, false
+	}
+
+	return s.gravityContract.FromAddress(), true
+}
+
+// paysOurRewards reports whether the fees of the batch submitted with the given
+// tx data would be paid to one of the reward addresses, if any are set.
+func (s *gravityRelayer) paysOurRewards(batch types.OutgoingTxBatch, txData []byte) bool {
+	if len(s.rewardAddresses) == 0 {
+		return true
+	}
+
+	recipient, ok := s.rewardRecipient(txData)
+	if ok {
+		if _, listed := s.rewardAddresses[recipient]; listed {
+			return true
+		}
+	}
+
+	event := s.logger.Warn().
+		Uint64("batch_nonce", batch.BatchNonce).
+		Str("token_contract", batch.TokenContract)
+	if ok {
+		event = event.Str("reward_recipient", recipient.Hex())
+	}
+	event.Msg("the batch fees wouldn't be paid to one of our reward addresses; skipping batch")
+
+	return false
+}
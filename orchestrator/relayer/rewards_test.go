@@ -0,0 +1,77 @@
+package relayer
+
+import (
+	"testing"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	gravityMocks "github.com/umee-network/peggo/mocks/gravity"
+	"github.com/umee-network/peggo/solwrappers/versions"
+)
+
+func TestPaysOurRewards(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	var (
+		ours  = ethcmn.HexToAddress("0x0000000000000000000000000000000000000001")
+		cold  = ethcmn.HexToAddress("0x0000000000000000000000000000000000000002")
+		batch = types.OutgoingTxBatch{BatchNonce: 1}
+	)
+
+	mockGravityContract := gravityMocks.NewMockContract(mockCtrl)
+	mockGravityContract.EXPECT().FromAddress().Return(ours).AnyTimes()
+
+	relayer := &gravityRelayer{logger: zerolog.Nop(), gravityContract: mockGravityContract}
+
+	// without reward addresses, every batch is relayed
+	assert.True(t, relayer.paysOurRewards(batch, nil))
+
+	// the fees are paid to the sender of the tx, which isn't listed
+	SetRewardAddresses([]ethcmn.Address{cold})(relayer)
+	assert.False(t, relayer.paysOurRewards(batch, nil))
+
+	SetRewardAddresses([]ethcmn.Address{cold, ours})(relayer)
+	assert.True(t, relayer.paysOurRewards(batch, nil))
+
+	// through a meta-transaction service, its account gets the fees
+	SetMetaTxSubmitter(&mockMetaTxSubmitter{})(relayer)
+	assert.False(t, relayer.paysOurRewards(batch, nil))
+}
+
+func TestPaysOurRewardsDirected(t *testing.T) {
+	var (
+		ours  = ethcmn.HexToAddress("0x0000000000000000000000000000000000000001")
+		other = ethcmn.HexToAddress("0x0000000000000000000000000000000000000002")
+		batch = types.OutgoingTxBatch{BatchNonce: 1}
+	)
+
+	version, err := versions.New("v2", &bind.MetaData{ABI: `[
+		{"type":"function","name":"submitBatch","stateMutability":"nonpayable",
+		 "inputs":[{"name":"_rewardRecipient","type":"address"}],"outputs":[]}
+	]`}, "_rewardRecipient")
+	require.NoError(t, err)
+
+	relayer := &gravityRelayer{logger: zerolog.Nop()}
+	SetGravityVersion(version)(relayer)
+	SetRewardAddresses([]ethcmn.Address{ours})(relayer)
+
+	pack := func(recipient ethcmn.Address) []byte {
+		txData, err := version.ABI.Pack("submitBatch", recipient)
+		require.NoError(t, err)
+		return txData
+	}
+
+	// the fees go to the recipient in the submitBatch arguments, even through a
+	// meta-transaction service
+	SetMetaTxSubmitter(&mockMetaTxSubmitter{})(relayer)
+	assert.True(t, relayer.paysOurRewards(batch, pack(ours)))
+	assert.False(t, relayer.paysOurRewards(batch, pack(other)))
+	assert.False(t, relayer.paysOurRewards(batch, []byte{1, 2, 3, 4}))
+}
@@ -0,0 +1,90 @@
+package relayer
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+
+	"github.com/umee-network/peggo/orchestrator/oracle"
+)
+
+type (
+	// HistoricalBatch is a batch relayed to Ethereum in the past, along with the
+	// gas and prices at the time it was relayed.
+	HistoricalBatch struct {
+		Batch         types.OutgoingTxBatch
+		TxHash        ethcmn.Hash
+		Time          time.Time
+		Decimals      uint8
+		GasUsed       uint64
+		GasPrice      *big.Int
+		GasAssetPrice decimal.Decimal
+		TokenPrice    decimal.Decimal
+	}
+
+	// SimulatedBatch is the outcome of replaying a historical batch through the
+	// profitability check.
+	SimulatedBatch struct {
+		HistoricalBatch
+
+		FeesUSD decimal.Decimal
+		CostUSD decimal.Decimal
+		Relay   bool
+	}
+
+	// SimulationReport summarizes what would have been relayed.
+	SimulationReport struct {
+		ProfitMultiplier float64
+		Batches          []SimulatedBatch
+		Relayed          int
+		Skipped          int
+		FeesUSD          decimal.Decimal
+		CostUSD          decimal.Decimal
+		PnLUSD           decimal.Decimal
+	}
+)
+
+// SimulateProfitability replays historical batches through the profitability
+// check using the given profit multiplier, and reports which batches would have
+// been relayed and the resulting P&L. The price deviation circuit breaker and
+// missing price fallbacks are not simulated.
+func SimulateProfitability(batches []HistoricalBatch, profitMultiplier float64) SimulationReport {
+	report := SimulationReport{
+		ProfitMultiplier: profitMultiplier,
+		Batches:          make([]SimulatedBatch, 0, len(batches)),
+		FeesUSD:          decimal.Zero,
+		CostUSD:          decimal.Zero,
+	}
+
+	multiplier := decimal.NewFromFloat(profitMultiplier)
+
+	for _, b := range batches {
+		feesUSD := historicalUSDValue(batchTotalFees(b.Batch), b.Decimals, b.TokenPrice)
+		costUSD := historicalUSDValue(totalGasCost(b.GasUsed, b.GasPrice), oracle.DecimalsETH, b.GasAssetPrice)
+
+		// A zero multiplier disables the profitability check, see IsBatchProfitable.
+		relay := profitMultiplier == 0 || feesUSD.GreaterThanOrEqual(costUSD.Mul(multiplier))
+
+		report.Batches = append(report.Batches, SimulatedBatch{
+			HistoricalBatch: b,
+			FeesUSD:         feesUSD,
+			CostUSD:         costUSD,
+			Relay:           relay,
+		})
+
+		if !relay {
+			report.Skipped++
+			continue
+		}
+
+		report.Relayed++
+		report.FeesUSD = report.FeesUSD.Add(feesUSD)
+		report.CostUSD = report.CostUSD.Add(costUSD)
+	}
+
+	report.PnLUSD = report.FeesUSD.Sub(report.CostUSD)
+	return report
+}
@@ -0,0 +1,54 @@
+package relayer
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulateProfitability(t *testing.T) {
+	batchWithFee := func(fee int64) types.OutgoingTxBatch {
+		return types.OutgoingTxBatch{
+			Transactions: []types.OutgoingTransferTx{
+				{Erc20Fee: types.ERC20Token{Amount: sdk.NewInt(fee)}},
+			},
+		}
+	}
+
+	// 100k gas at 100 gwei is 0.01 ETH, i.e. 10 USD at 1000 USD/ETH.
+	historical := func(fee int64) HistoricalBatch {
+		return HistoricalBatch{
+			Batch:         batchWithFee(fee),
+			Decimals:      6,
+			GasUsed:       100000,
+			GasPrice:      big.NewInt(100_000_000_000),
+			GasAssetPrice: decimal.NewFromInt(1000),
+			TokenPrice:    decimal.NewFromInt(1),
+		}
+	}
+
+	batches := []HistoricalBatch{
+		historical(15_000_000), // 15 USD
+		historical(11_000_000), // 11 USD
+		historical(5_000_000),  // 5 USD
+	}
+
+	report := SimulateProfitability(batches, 1.2)
+	assert.Equal(t, 1, report.Relayed)
+	assert.Equal(t, 2, report.Skipped)
+	assert.Equal(t, "15", report.FeesUSD.String())
+	assert.Equal(t, "10", report.CostUSD.String())
+	assert.Equal(t, "5", report.PnLUSD.String())
+
+	report = SimulateProfitability(batches, 1)
+	assert.Equal(t, 2, report.Relayed)
+	assert.Equal(t, "6", report.PnLUSD.String())
+
+	report = SimulateProfitability(batches, 0)
+	assert.Equal(t, 3, report.Relayed)
+	assert.Equal(t, "1", report.PnLUSD.String())
+}
@@ -1,6 +1,9 @@
 package relayer
 
 import (
+	"strings"
+	"sync"
+
 	ethcmn "github.com/ethereum/go-ethereum/common"
 )
 
@@ -11,3 +14,39 @@ type SymbolRetriever interface {
 	// correct Symbol from the contract address
 	GetTokenSymbol(erc20Contract ethcmn.Address) (string, error)
 }
+
+// TokenSymbols is a SymbolRetriever returning the symbols set for some ERC20
+// contracts (e.g. by the remote config), and retrieving the others from next.
+type TokenSymbols struct {
+	next SymbolRetriever
+
+	mtx     sync.RWMutex
+	symbols map[ethcmn.Address]string
+}
+
+// NewTokenSymbols returns a SymbolRetriever retrieving the symbols from next
+// until some are set.
+func NewTokenSymbols(next SymbolRetriever) *TokenSymbols {
+	return &TokenSymbols{next: next}
+}
+
+// SetSymbols replaces the symbols set for ERC20 contracts.
+func (t *TokenSymbols) SetSymbols(symbols map[ethcmn.Address]string) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.symbols = symbols
+}
+
+// GetTokenSymbol returns the symbol set for the contract, if any.
+func (t *TokenSymbols) GetTokenSymbol(erc20Contract ethcmn.Address) (string, error) {
+	t.mtx.RLock()
+	symbol, ok := t.symbols[erc20Contract]
+	t.mtx.RUnlock()
+
+	if ok {
+		return strings.ToUpper(symbol), nil
+	}
+
+	return t.next.GetTokenSymbol(erc20Contract)
+}
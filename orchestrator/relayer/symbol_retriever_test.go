@@ -0,0 +1,33 @@
+package relayer
+
+import (
+	"errors"
+	"testing"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type unknownSymbols struct{}
+
+func (unknownSymbols) GetTokenSymbol(ethcmn.Address) (string, error) {
+	return "", errors.New("unknown token")
+}
+
+func TestTokenSymbols(t *testing.T) {
+	token := ethcmn.HexToAddress("0x0000000000000000000000000000000000000001")
+	symbols := NewTokenSymbols(unknownSymbols{})
+
+	_, err := symbols.GetTokenSymbol(token)
+	assert.Error(t, err)
+
+	symbols.SetSymbols(map[ethcmn.Address]string{token: "weth"})
+	symbol, err := symbols.GetTokenSymbol(token)
+	require.NoError(t, err)
+	assert.Equal(t, "WETH", symbol)
+
+	symbols.SetSymbols(nil)
+	_, err = symbols.GetTokenSymbol(token)
+	assert.Error(t, err)
+}
@@ -0,0 +1,55 @@
+package relayer
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+
+	"github.com/umee-network/peggo/orchestrator/totals"
+)
+
+// SetTotals returns the relayer option counting the relayed txs in the given
+// totals.
+func SetTotals(t *totals.Totals) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetTotals(t) }
+}
+
+// SetTotals sets the totals the relayed valset updates and batches are added to
+// once they're sent. Nil disables counting.
+func (s *gravityRelayer) SetTotals(t *totals.Totals) {
+	s.totals = t
+}
+
+// settleRelayedTotals counts the relayed txs mined since the last time in the
+// totals, with the gas they actually paid. A tx no longer known to the Ethereum
+// node after --relayer-pending-tx-wait, e.g. dropped or replaced, is dropped.
+func (s *gravityRelayer) settleRelayedTotals(ctx context.Context) {
+	for hash, p := range s.totals.PendingRelayed() {
+		txHash := ethcmn.HexToHash(hash)
+
+		receipt, err := s.ethProvider.TransactionReceipt(ctx, txHash)
+		if err == nil {
+			gasPrice, err := s.effectiveGasPrice(ctx, txHash, receipt)
+			if err != nil {
+				s.logger.Debug().Err(err).Str("tx_hash", hash).Msg("failed to get relayed tx gas price; retrying later")
+				continue
+			}
+
+			succeeded := receipt.Status == ethtypes.ReceiptStatusSuccessful
+			s.totals.SettleRelayed(hash, succeeded, totalGasCost(receipt.GasUsed, gasPrice))
+			continue
+		}
+
+		if time.Since(p.SentAt) < s.pendingTxWait {
+			continue
+		}
+
+		if _, _, err := s.ethProvider.TransactionByHash(ctx, txHash); errors.Is(err, ethereum.NotFound) {
+			s.totals.DropRelayed(hash)
+		}
+	}
+}
@@ -5,6 +5,9 @@ import (
 
 	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
 	"github.com/pkg/errors"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/committer"
+	"github.com/umee-network/peggo/orchestrator/totals"
 )
 
 // RelayValsets checks the last validator set on Ethereum, if it's lower than our latest validator
@@ -38,10 +41,13 @@ func (s *gravityRelayer) RelayValsets(ctx context.Context, currentValset types.V
 	}
 
 	if latestValidValset == nil && err == nil {
+		s.forgetNotPivotal("valset", nil)
 		s.logger.Info().Msg("no valset updates to relay")
 		return nil
 	}
 
+	s.forgetNotPivotal("valset", map[string]struct{}{valsetRelayKey(*latestValidValset): {}})
+
 	if s.lastSentValsetNonce >= latestValidValset.Nonce {
 		s.logger.Debug().Msg("already relayed this valset; skipping")
 		return nil
@@ -72,6 +78,13 @@ func (s *gravityRelayer) RelayValsets(ctx context.Context, currentValset types.V
 		}
 	}
 
+	if !s.shouldRelay(valsetRelayKey(*latestValidValset), currentValset, valsetSigners(latestValidValsetSigs)) {
+		s.logger.Debug().
+			Uint64("valset_nonce", latestValidValset.Nonce).
+			Msg("our confirm isn't required to pass the power threshold; skipping valset update")
+		return nil
+	}
+
 	s.logger.Info().
 		Uint64("latest_cosmos_confirmed_nonce", latestValidValset.Nonce).
 		Uint64("latest_ethereum_valset_nonce", latestEthereumValsetNonce.Uint64()).
@@ -89,6 +102,9 @@ func (s *gravityRelayer) RelayValsets(ctx context.Context, currentValset types.V
 	}
 
 	if txData == nil {
+		// The confirms were checked locally and don't pass the power threshold,
+		// so report who is missing instead of sending a tx that would revert.
+		s.reportMissingValsetConfirms(ctx, currentValset, *latestValidValset, latestValidValsetSigs)
 		return nil
 	}
 
@@ -110,8 +126,17 @@ func (s *gravityRelayer) RelayValsets(ctx context.Context, currentValset types.V
 		return nil
 	}
 
+	// relaying may have been paused while the update was built and estimated
+	if s.isPaused() {
+		return nil
+	}
+
 	// Send Valset Update to Ethereum
 	txHash, err := s.gravityContract.SendTx(ctx, s.gravityContract.Address(), txData, estimatedGasCost, gasPrice)
+	if errors.Is(err, committer.ErrMaxInFlightTxs) {
+		s.logger.Warn().Err(err).Msg("too many in-flight txs; waiting before relaying the valset update")
+		return nil
+	}
 	if err != nil {
 		s.logger.Err(err).
 			Str("tx_hash", txHash.Hex()).
@@ -121,6 +146,7 @@ func (s *gravityRelayer) RelayValsets(ctx context.Context, currentValset types.V
 	}
 
 	s.logger.Info().Str("tx_hash", txHash.Hex()).Msg("sent Tx (Gravity updateValset)")
+	s.totals.AddRelayed(totals.RelayedValset, txHash.Hex())
 
 	// update our local tracker of the latest valset
 	s.lastSentValsetNonce = latestValidValset.Nonce
@@ -5,14 +5,17 @@ import (
 	"errors"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
 	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/umee-network/peggo/mocks"
 	gravityMocks "github.com/umee-network/peggo/mocks/gravity"
+	"github.com/umee-network/peggo/orchestrator/store"
 )
 
 func TestRelayValsets(t *testing.T) {
@@ -109,6 +112,106 @@ func TestRelayValsets(t *testing.T) {
 		assert.Nil(t, relayer.RelayValsets(context.Background(), types.Valset{}))
 	})
 
+	t.Run("paused while relaying", func(t *testing.T) {
+
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		mockQClient := mocks.NewMockQueryClient(mockCtrl)
+		mockQClient.EXPECT().
+			LastValsetRequests(gomock.Any(), &types.QueryLastValsetRequestsRequest{}).
+			Return(&types.QueryLastValsetRequestsResponse{
+				Valsets: []types.Valset{
+					{
+						Nonce: 3,
+						Members: []types.BridgeValidator{
+							{
+								Power:           1000,
+								EthereumAddress: "0x0000000000000000000000000000000000000000",
+							},
+							{
+								Power:           1000,
+								EthereumAddress: "0x1000000000000000000000000000000000000000",
+							},
+						},
+						Height: 0,
+					},
+				},
+			}, nil)
+
+		mockQClient.EXPECT().
+			ValsetRequest(gomock.Any(), &types.QueryValsetRequestRequest{Nonce: 3}).
+			Return(&types.QueryValsetRequestResponse{
+				Valset: &types.Valset{
+					Nonce: 3,
+					Members: []types.BridgeValidator{
+						{
+							Power:           1000,
+							EthereumAddress: "0x0000000000000000000000000000000000000000",
+						},
+						{
+							Power:           1000,
+							EthereumAddress: "0x1000000000000000000000000000000000000000",
+						},
+					},
+					Height: 0,
+				},
+			}, nil)
+
+		mockQClient.EXPECT().ValsetConfirmsByNonce(
+			gomock.Any(),
+			&types.QueryValsetConfirmsByNonceRequest{
+				Nonce: 3,
+			}).Return(&types.QueryValsetConfirmsByNonceResponse{
+			Confirms: []types.MsgValsetConfirm{
+				{
+					Nonce:        0,
+					Orchestrator: "aaa",
+					EthAddress:   "0x0000000000000000000000000000000000000000",
+					Signature:    "0x000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+				},
+			},
+		}, nil)
+
+		gravityAddress := ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
+		fromAddress := ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e03e53415d37aa96045")
+
+		mockGravityContract := gravityMocks.NewMockContract(mockCtrl)
+		mockGravityContract.EXPECT().GetValsetNonce(gomock.Any(), fromAddress).Return(big.NewInt(2), nil)
+		mockGravityContract.EXPECT().FromAddress().Return(fromAddress).AnyTimes()
+		mockGravityContract.EXPECT().
+			EncodeValsetUpdate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]byte{1, 2, 3}, nil).Times(2)
+		mockGravityContract.EXPECT().Address().Return(gravityAddress).AnyTimes()
+		mockGravityContract.EXPECT().
+			EstimateGas(gomock.Any(), gravityAddress, []byte{1, 2, 3}).
+			Return(uint64(1000), big.NewInt(100), nil)
+
+		st, err := store.New(t.TempDir())
+		assert.NoError(t, err)
+
+		// the operator pauses relaying once the loop started
+		mockGravityContract.EXPECT().IsPendingTxInput([]byte{1, 2, 3}, gomock.Any()).DoAndReturn(
+			func([]byte, time.Duration) bool {
+				assert.NoError(t, SetPauseState(st, true, "gas spike"))
+				return false
+			},
+		)
+
+		mockGravityContract.EXPECT().SendTx(
+			gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+		).Times(0)
+
+		relayer := gravityRelayer{
+			logger:            zerolog.Nop(),
+			gravityContract:   mockGravityContract,
+			cosmosQueryClient: mockQClient,
+			store:             st,
+		}
+
+		assert.Nil(t, relayer.RelayValsets(context.Background(), types.Valset{}))
+	})
+
 	t.Run("error. no valsets found", func(t *testing.T) {
 
 		mockCtrl := gomock.NewController(t)
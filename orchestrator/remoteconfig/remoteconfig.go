@@ -0,0 +1,269 @@
+package remoteconfig
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/umee-network/peggo/orchestrator/loops"
+	"github.com/umee-network/peggo/orchestrator/store"
+)
+
+// versionStoreKey is the store key holding the version of the applied config,
+// so a replayed older document is refused across restarts too.
+const versionStoreKey = "remote_config_version"
+
+const (
+	// SignatureSuffix is appended to the config URL to get the URL of its
+	// signature, unless set.
+	SignatureSuffix = ".sig"
+
+	maxRespTime = 30 * time.Second
+	// maxBodySize bounds the config and signature downloads.
+	maxBodySize = 10 << 20
+)
+
+type (
+	// Config defines where the remote config is fetched from and the key it must
+	// be signed with.
+	Config struct {
+		URL string
+		// SignatureURL serves the base64 encoded ed25519 signature of the config,
+		// URL with SignatureSuffix if empty.
+		SignatureURL string
+		PublicKey    ed25519.PublicKey
+	}
+
+	// Document is the non-secret config fetched, as JSON. Its version must
+	// increase with every update, so an older document served again (e.g. by a
+	// stale cache or an attacker replaying it) is never applied.
+	Document struct {
+		Version uint64 `json:"version"`
+		// Denylist lists Ethereum addresses not to relay transfers to, on top of
+		// the local denylist.
+		Denylist []string `json:"denylist,omitempty"`
+		// SymbolAliases lists symbols priced as another one, in the ALIAS=CANONICAL
+		// format (e.g. WETH=ETH), on top of the local aliases.
+		SymbolAliases []string `json:"symbol_aliases,omitempty"`
+		// TokenSymbols maps ERC20 contracts to the symbols they're priced as,
+		// instead of the symbols CoinGecko lists them with.
+		TokenSymbols map[string]string `json:"token_symbols,omitempty"`
+	}
+
+	// ApplyFn applies a verified document. A document failing to apply is
+	// retried on the next fetch.
+	ApplyFn func(Document) error
+
+	// Fetcher fetches the remote config, verifies its signature and applies it
+	// when its version increased.
+	Fetcher struct {
+		logger zerolog.Logger
+		client *http.Client
+		config Config
+		store  *store.Store
+		apply  ApplyFn
+
+		mtx     sync.Mutex
+		version uint64
+		applied bool
+	}
+)
+
+// ParsePublicKey parses a hex encoded ed25519 public key.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	bz, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(s), "0x"))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid ed25519 public key")
+	}
+
+	if len(bz) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid ed25519 public key; expected %d bytes, got %d", ed25519.PublicKeySize, len(bz))
+	}
+
+	return ed25519.PublicKey(bz), nil
+}
+
+// Verify checks the base64 encoded signature of the config body and decodes
+// the document.
+func Verify(publicKey ed25519.PublicKey, body, signature []byte) (Document, error) {
+	sig, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature)))
+	if err != nil {
+		return Document{}, errors.Wrap(err, "invalid remote config signature encoding")
+	}
+
+	if !ed25519.Verify(publicKey, body, sig) {
+		return Document{}, errors.New("invalid remote config signature")
+	}
+
+	var doc Document
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return Document{}, errors.Wrap(err, "invalid remote config")
+	}
+
+	return doc, nil
+}
+
+// NewFetcher returns a fetcher of the remote config and applies it once.
+// Failing to apply it on startup is an error, so the orchestrator doesn't run
+// with a config the operator expects to be overridden. The version applied is
+// persisted in st, and a document older than the one applied before the
+// restart is refused.
+func NewFetcher(
+	ctx context.Context,
+	logger zerolog.Logger,
+	config Config,
+	st *store.Store,
+	apply ApplyFn,
+) (*Fetcher, error) {
+	if config.URL == "" {
+		return nil, errors.New("remote config requires a URL")
+	}
+
+	if len(config.PublicKey) != ed25519.PublicKeySize {
+		return nil, errors.New("remote config requires an ed25519 public key")
+	}
+
+	if config.SignatureURL == "" {
+		config.SignatureURL = config.URL + SignatureSuffix
+	}
+
+	f := &Fetcher{
+		logger: logger.With().Str("module", "remote_config").Logger(),
+		client: &http.Client{Timeout: maxRespTime},
+		config: config,
+		store:  st,
+		apply:  apply,
+	}
+
+	if _, err := st.Get(versionStoreKey, &f.version); err != nil {
+		return nil, errors.Wrap(err, "failed to load the applied remote config version")
+	}
+
+	if err := f.Reload(ctx); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Reload fetches the config and its signature and applies the config if its
+// version is greater than the applied one. Invalid configs are not applied.
+func (f *Fetcher) Reload(ctx context.Context) error {
+	body, err := f.fetch(ctx, f.config.URL)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch remote config")
+	}
+
+	signature, err := f.fetch(ctx, f.config.SignatureURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch remote config signature")
+	}
+
+	doc, err := Verify(f.config.PublicKey, body, signature)
+	if err != nil {
+		return err
+	}
+
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	// the version applied before a restart is applied again, as the process
+	// starts with the local config only
+	if !f.applied && doc.Version < f.version {
+		return fmt.Errorf(
+			"remote config version %d is older than the applied version %d",
+			doc.Version, f.version,
+		)
+	}
+
+	if f.applied && doc.Version <= f.version {
+		if doc.Version < f.version {
+			f.logger.Warn().
+				Uint64("version", doc.Version).
+				Uint64("applied_version", f.version).
+				Msg("remote config older than the applied one; ignoring it")
+		}
+
+		return nil
+	}
+
+	if err := f.apply(doc); err != nil {
+		return errors.Wrapf(err, "failed to apply remote config version %d", doc.Version)
+	}
+
+	f.version = doc.Version
+	f.applied = true
+
+	if err := f.store.Set(versionStoreKey, f.version); err != nil {
+		f.logger.Err(err).Msg("failed to persist the applied remote config version")
+	}
+
+	f.logger.Info().
+		Uint64("version", doc.Version).
+		Int("denylist", len(doc.Denylist)).
+		Int("symbol_aliases", len(doc.SymbolAliases)).
+		Int("token_symbols", len(doc.TokenSymbols)).
+		Msg("remote config applied")
+
+	return nil
+}
+
+// Version returns the version of the applied config.
+func (f *Fetcher) Version() uint64 {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	return f.version
+}
+
+func (f *Fetcher) fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+
+	bz, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bz) > maxBodySize {
+		return nil, fmt.Errorf("%s returned more than %d bytes", url, maxBodySize)
+	}
+
+	return bz, nil
+}
+
+// Start fetches the config every interval until the context is done. A failed
+// fetch is logged and the applied config is kept.
+func (f *Fetcher) Start(ctx context.Context, interval time.Duration) error {
+	return loops.RunLoop(ctx, f.logger, interval, func() error {
+		if err := f.Reload(ctx); err != nil {
+			f.logger.Err(err).Msg("failed to reload remote config; keeping the applied one")
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,132 @@
+package remoteconfig
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umee-network/peggo/orchestrator/store"
+)
+
+func TestFetcher(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	var (
+		mtx       sync.Mutex
+		body      []byte
+		signature []byte
+	)
+	serve := func(b []byte, key ed25519.PrivateKey) {
+		mtx.Lock()
+		defer mtx.Unlock()
+
+		body = b
+		signature = []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(key, b)))
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mtx.Lock()
+		defer mtx.Unlock()
+
+		switch r.URL.Path {
+		case "/config.json":
+			_, _ = w.Write(body)
+		case "/config.json" + SignatureSuffix:
+			_, _ = w.Write(signature)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var applied []Document
+	apply := func(doc Document) error {
+		if len(doc.SymbolAliases) > 0 && doc.SymbolAliases[0] == "BAD" {
+			return errors.New("invalid alias")
+		}
+
+		applied = append(applied, doc)
+		return nil
+	}
+
+	config := Config{URL: server.URL + "/config.json", PublicKey: publicKey}
+	ctx := context.Background()
+
+	serve([]byte(`{"version":2,"denylist":["0x8589427373D6D84E98730D7795D8f6f8731FDA16"]}`), privateKey)
+	s, err := store.New(t.TempDir())
+	require.NoError(t, err)
+
+	f, err := NewFetcher(ctx, zerolog.Nop(), config, s, apply)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, []string{"0x8589427373D6D84E98730D7795D8f6f8731FDA16"}, applied[0].Denylist)
+	assert.Equal(t, uint64(2), f.Version())
+
+	// the same or an older version isn't applied again
+	require.NoError(t, f.Reload(ctx))
+	serve([]byte(`{"version":1}`), privateKey)
+	require.NoError(t, f.Reload(ctx))
+	assert.Len(t, applied, 1)
+
+	// a config signed with another key is rejected
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	serve([]byte(`{"version":3}`), otherKey)
+	assert.Error(t, f.Reload(ctx))
+	assert.Len(t, applied, 1)
+
+	// a config failing to apply is retried
+	serve([]byte(`{"version":3,"symbol_aliases":["BAD"]}`), privateKey)
+	assert.Error(t, f.Reload(ctx))
+	assert.Equal(t, uint64(2), f.Version())
+
+	serve([]byte(`{"version":3,"symbol_aliases":["WETH=ETH"]}`), privateKey)
+	require.NoError(t, f.Reload(ctx))
+	require.Len(t, applied, 2)
+	assert.Equal(t, []string{"WETH=ETH"}, applied[1].SymbolAliases)
+	assert.Equal(t, uint64(3), f.Version())
+
+	// the applied version is applied again after a restart, but an older one
+	// is refused
+	applied = nil
+	f, err = NewFetcher(ctx, zerolog.Nop(), config, s, apply)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, uint64(3), f.Version())
+
+	serve([]byte(`{"version":2}`), privateKey)
+	_, err = NewFetcher(ctx, zerolog.Nop(), config, s, apply)
+	assert.Error(t, err)
+	assert.Len(t, applied, 1)
+
+	// failing to get a valid config on startup is an error
+	serve([]byte(`{"version":4}`), otherKey)
+	_, err = NewFetcher(ctx, zerolog.Nop(), config, s, apply)
+	assert.Error(t, err)
+}
+
+func TestParsePublicKey(t *testing.T) {
+	publicKey, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	parsed, err := ParsePublicKey("0x" + hex.EncodeToString(publicKey))
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, parsed)
+
+	_, err = ParsePublicKey("abcd")
+	assert.Error(t, err)
+
+	_, err = ParsePublicKey("not hex")
+	assert.Error(t, err)
+}
@@ -0,0 +1,163 @@
+package safemode
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/umee-network/peggo/orchestrator/store"
+)
+
+const (
+	// journalStoreKey is the store key holding the journal.
+	journalStoreKey = "safemode_journal"
+	// journalRetention is how long a tx is kept in the journal when it's never
+	// seen mined, e.g. sent asynchronously and not waited for.
+	journalRetention = 24 * time.Hour
+	// journalMaxTxs bounds the number of txs kept in the journal.
+	journalMaxTxs = 1000
+)
+
+// Chains a tx can be sent to.
+const (
+	ChainEthereum = "ethereum"
+	ChainCosmos   = "cosmos"
+)
+
+type (
+	// Tx is a signed tx sent to a chain and not yet seen mined.
+	Tx struct {
+		Chain  string    `json:"chain"`
+		Hash   string    `json:"hash"`
+		Nonce  uint64    `json:"nonce"`
+		SentAt time.Time `json:"sent_at"`
+	}
+
+	// Journal records the txs sent by the orchestrator until they're mined,
+	// along with whether the process is running, in the local store. A journal
+	// left running on startup means the previous process exited uncleanly, and
+	// its txs may still be in flight.
+	Journal struct {
+		logger zerolog.Logger
+		store  *store.Store
+
+		mtx   sync.Mutex
+		state journalState
+	}
+
+	journalState struct {
+		Running bool `json:"running"`
+		Txs     []Tx `json:"txs"`
+	}
+)
+
+// NewJournal returns the journal persisted in s.
+func NewJournal(logger zerolog.Logger, s *store.Store) (*Journal, error) {
+	j := &Journal{
+		logger: logger.With().Str("module", "safemode").Logger(),
+		store:  s,
+	}
+
+	if _, err := s.Get(journalStoreKey, &j.state); err != nil {
+		return nil, errors.Wrap(err, "failed to load the tx journal")
+	}
+
+	return j, nil
+}
+
+// Unclean returns whether the previous process exited without stopping the
+// journal.
+func (j *Journal) Unclean() bool {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+
+	return j.state.Running
+}
+
+// Pending returns the txs recorded and not yet seen mined.
+func (j *Journal) Pending() []Tx {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+
+	return append([]Tx(nil), j.state.Txs...)
+}
+
+// Start marks the process as running, after the txs left in flight by the
+// previous one were resolved.
+func (j *Journal) Start() error {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+
+	j.state.Running = true
+	j.state.Txs = nil
+
+	return j.persist()
+}
+
+// Stop marks the process as stopped cleanly.
+func (j *Journal) Stop() {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+
+	j.state.Running = false
+
+	if err := j.persist(); err != nil {
+		j.logger.Err(err).Msg("failed to persist the tx journal")
+	}
+}
+
+// Add records a sent tx. The txs older than journalRetention are dropped.
+func (j *Journal) Add(tx Tx) {
+	if tx.SentAt.IsZero() {
+		tx.SentAt = time.Now().UTC()
+	}
+
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+
+	txs := j.state.Txs[:0]
+	for _, t := range j.state.Txs {
+		if tx.SentAt.Sub(t.SentAt) <= journalRetention {
+			txs = append(txs, t)
+		}
+	}
+
+	txs = append(txs, tx)
+	if len(txs) > journalMaxTxs {
+		txs = txs[len(txs)-journalMaxTxs:]
+	}
+	j.state.Txs = txs
+
+	if err := j.persist(); err != nil {
+		j.logger.Err(err).Str("chain", tx.Chain).Str("tx_hash", tx.Hash).Msg("failed to record tx in the journal")
+	}
+}
+
+// Remove drops a tx from the journal, once it's mined or known to be dropped.
+func (j *Journal) Remove(chain, hash string) {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+
+	txs := j.state.Txs[:0]
+	for _, t := range j.state.Txs {
+		if t.Chain != chain || !strings.EqualFold(t.Hash, hash) {
+			txs = append(txs, t)
+		}
+	}
+
+	if len(txs) == len(j.state.Txs) {
+		return
+	}
+	j.state.Txs = txs
+
+	if err := j.persist(); err != nil {
+		j.logger.Err(err).Str("chain", chain).Str("tx_hash", hash).Msg("failed to remove tx from the journal")
+	}
+}
+
+func (j *Journal) persist() error {
+	return j.store.Set(journalStoreKey, j.state)
+}
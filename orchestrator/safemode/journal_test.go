@@ -0,0 +1,56 @@
+package safemode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umee-network/peggo/orchestrator/store"
+)
+
+func TestJournal(t *testing.T) {
+	s, err := store.New(t.TempDir())
+	require.NoError(t, err)
+
+	j, err := NewJournal(zerolog.Nop(), s)
+	require.NoError(t, err)
+	assert.False(t, j.Unclean())
+	assert.Empty(t, j.Pending())
+
+	require.NoError(t, j.Start())
+
+	now := time.Now().UTC()
+	j.Add(Tx{Chain: ChainEthereum, Hash: "0xAB", Nonce: 1, SentAt: now.Add(-journalRetention - time.Hour)})
+	j.Add(Tx{Chain: ChainEthereum, Hash: "0xCD", Nonce: 2, SentAt: now})
+	j.Add(Tx{Chain: ChainCosmos, Hash: "EF", Nonce: 7})
+
+	// the tx older than the retention was dropped
+	pending := j.Pending()
+	require.Len(t, pending, 2)
+	assert.Equal(t, "0xCD", pending[0].Hash)
+	assert.False(t, pending[1].SentAt.IsZero())
+
+	j.Remove(ChainEthereum, "0xcd")
+	j.Remove(ChainEthereum, "EF")
+	assert.Len(t, j.Pending(), 1)
+
+	// a process exiting without stopping the journal leaves it unclean
+	restarted, err := NewJournal(zerolog.Nop(), s)
+	require.NoError(t, err)
+	assert.True(t, restarted.Unclean())
+	pending = restarted.Pending()
+	require.Len(t, pending, 1)
+	assert.Equal(t, ChainCosmos, pending[0].Chain)
+	assert.Equal(t, uint64(7), pending[0].Nonce)
+
+	require.NoError(t, restarted.Start())
+	assert.Empty(t, restarted.Pending())
+	restarted.Stop()
+
+	restarted, err = NewJournal(zerolog.Nop(), s)
+	require.NoError(t, err)
+	assert.False(t, restarted.Unclean())
+}
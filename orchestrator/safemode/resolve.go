@@ -0,0 +1,264 @@
+package safemode
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+const (
+	defaultPollInterval = 5 * time.Second
+	defaultDropWait     = 2 * time.Minute
+)
+
+// Resolutions of an in-flight tx.
+const (
+	// ResolutionConfirmed means the tx was mined successfully.
+	ResolutionConfirmed = "confirmed"
+	// ResolutionFailed means the tx was mined but its execution failed; its
+	// nonce was used nonetheless.
+	ResolutionFailed = "failed"
+	// ResolutionReplaced means the tx is unknown, but another tx with the same
+	// nonce was mined or is pending.
+	ResolutionReplaced = "replaced"
+	// ResolutionDropped means the tx is unknown and its nonce is still unused.
+	ResolutionDropped = "dropped"
+)
+
+// ErrUnresolved is returned when some in-flight txs could not be resolved
+// before the timeout.
+var ErrUnresolved = errors.New("in-flight txs left unresolved")
+
+type (
+	// EthereumClient looks up the Ethereum txs and the nonce of the sender.
+	EthereumClient interface {
+		TransactionReceipt(ctx context.Context, txHash ethcmn.Hash) (*ethtypes.Receipt, error)
+		TransactionByHash(ctx context.Context, hash ethcmn.Hash) (tx *ethtypes.Transaction, isPending bool, err error)
+		PendingNonceAt(ctx context.Context, account ethcmn.Address) (uint64, error)
+	}
+
+	// CosmosClient looks up the Cosmos txs, e.g. the Tendermint RPC client.
+	CosmosClient interface {
+		Tx(ctx context.Context, hash []byte, prove bool) (*ctypes.ResultTx, error)
+	}
+
+	// SequenceFn returns the committed account sequence of the sender of the
+	// Cosmos txs.
+	SequenceFn func(ctx context.Context) (uint64, error)
+
+	// Config defines how long the in-flight txs are waited for. A tx unknown by
+	// the nodes whose nonce is still unused is only considered dropped once it
+	// was sent DropWait ago, as it may still be propagating.
+	Config struct {
+		Timeout         time.Duration
+		PollInterval    time.Duration
+		DropWait        time.Duration
+		EthereumAddress ethcmn.Address
+	}
+
+	// Resolution is the outcome of a tx left in flight by the previous process.
+	Resolution struct {
+		Tx     Tx
+		Status string
+	}
+
+	// Resolver resolves the txs left in flight by a process that exited
+	// uncleanly, so that new txs are only signed once the nonces they used are
+	// known to be either consumed or free.
+	Resolver struct {
+		logger         zerolog.Logger
+		config         Config
+		journal        *Journal
+		eth            EthereumClient
+		cosmos         CosmosClient
+		cosmosSequence SequenceFn
+	}
+)
+
+// NewResolver returns a resolver of the in-flight txs recorded in journal.
+func NewResolver(
+	logger zerolog.Logger,
+	config Config,
+	journal *Journal,
+	eth EthereumClient,
+	cosmos CosmosClient,
+	cosmosSequence SequenceFn,
+) (*Resolver, error) {
+	if config.Timeout <= 0 {
+		return nil, fmt.Errorf("invalid safe mode timeout: %s", config.Timeout)
+	}
+
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaultPollInterval
+	}
+
+	if config.DropWait <= 0 {
+		config.DropWait = defaultDropWait
+	}
+
+	return &Resolver{
+		logger:         logger.With().Str("module", "safemode").Logger(),
+		config:         config,
+		journal:        journal,
+		eth:            eth,
+		cosmos:         cosmos,
+		cosmosSequence: cosmosSequence,
+	}, nil
+}
+
+// Resolve polls the chains until every tx pending in the journal is
+// confirmed, failed, replaced or dropped. It returns ErrUnresolved if some are
+// still in flight after the timeout.
+func (r *Resolver) Resolve(ctx context.Context) ([]Resolution, error) {
+	pending := r.journal.Pending()
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	r.logger.Warn().
+		Int("txs", len(pending)).
+		Dur("timeout", r.config.Timeout).
+		Msg("previous process exited uncleanly; resolving its in-flight txs before signing new ones")
+
+	ctx, cancel := context.WithTimeout(ctx, r.config.Timeout)
+	defer cancel()
+
+	var resolutions []Resolution
+	for {
+		remaining := pending[:0]
+		for _, tx := range pending {
+			status, err := r.resolve(ctx, tx)
+			if err != nil {
+				r.logger.Debug().Err(err).Str("chain", tx.Chain).Str("tx_hash", tx.Hash).Msg("failed to resolve tx")
+			}
+
+			if status == "" {
+				remaining = append(remaining, tx)
+				continue
+			}
+
+			r.logger.Info().
+				Str("chain", tx.Chain).
+				Str("tx_hash", tx.Hash).
+				Uint64("nonce", tx.Nonce).
+				Str("status", status).
+				Msg("resolved in-flight tx")
+
+			resolutions = append(resolutions, Resolution{Tx: tx, Status: status})
+		}
+		pending = remaining
+
+		if len(pending) == 0 {
+			return resolutions, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			hashes := make([]string, 0, len(pending))
+			for _, tx := range pending {
+				hashes = append(hashes, fmt.Sprintf("%s:%s", tx.Chain, tx.Hash))
+			}
+
+			return resolutions, errors.Wrapf(ErrUnresolved, "%s", strings.Join(hashes, ", "))
+
+		case <-time.After(r.config.PollInterval):
+		}
+	}
+}
+
+// resolve returns the resolution of tx, or an empty string while it's still
+// in flight.
+func (r *Resolver) resolve(ctx context.Context, tx Tx) (string, error) {
+	switch tx.Chain {
+	case ChainEthereum:
+		return r.resolveEthereum(ctx, tx)
+	case ChainCosmos:
+		return r.resolveCosmos(ctx, tx)
+	default:
+		return ResolutionDropped, fmt.Errorf("unknown chain %q", tx.Chain)
+	}
+}
+
+func (r *Resolver) resolveEthereum(ctx context.Context, tx Tx) (string, error) {
+	hash := ethcmn.HexToHash(tx.Hash)
+
+	receipt, err := r.eth.TransactionReceipt(ctx, hash)
+	switch {
+	case err == nil && receipt != nil:
+		if receipt.Status == ethtypes.ReceiptStatusFailed {
+			return ResolutionFailed, nil
+		}
+
+		return ResolutionConfirmed, nil
+
+	case err != nil && !errors.Is(err, ethereum.NotFound):
+		return "", errors.Wrap(err, "failed to get tx receipt")
+	}
+
+	// known but not mined yet: still in the mempool
+	if _, _, err := r.eth.TransactionByHash(ctx, hash); err == nil {
+		return "", nil
+	} else if !errors.Is(err, ethereum.NotFound) {
+		return "", errors.Wrap(err, "failed to get tx")
+	}
+
+	nonce, err := r.eth.PendingNonceAt(ctx, r.config.EthereumAddress)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get pending nonce")
+	}
+
+	return r.unknownTxResolution(tx, nonce), nil
+}
+
+func (r *Resolver) resolveCosmos(ctx context.Context, tx Tx) (string, error) {
+	hash, err := hex.DecodeString(tx.Hash)
+	if err != nil {
+		return ResolutionDropped, errors.Wrapf(err, "invalid tx hash %s", tx.Hash)
+	}
+
+	// The tx is only found once included in a block.
+	res, err := r.cosmos.Tx(ctx, hash, false)
+	switch {
+	case err == nil && res.Height > 0:
+		if res.TxResult.Code != 0 {
+			return ResolutionFailed, nil
+		}
+
+		return ResolutionConfirmed, nil
+
+	case err != nil && !strings.Contains(err.Error(), "not found"):
+		return "", errors.Wrap(err, "failed to get tx")
+	}
+
+	// Without tx indexing on the node, an included tx is never found and is
+	// reported as replaced, which is just as safe for the sequence.
+	sequence, err := r.cosmosSequence(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get account sequence")
+	}
+
+	return r.unknownTxResolution(tx, sequence), nil
+}
+
+// unknownTxResolution returns the resolution of a tx unknown by the node,
+// given the next nonce of the sender.
+func (r *Resolver) unknownTxResolution(tx Tx, nextNonce uint64) string {
+	if nextNonce > tx.Nonce {
+		return ResolutionReplaced
+	}
+
+	if time.Since(tx.SentAt) < r.config.DropWait {
+		return ""
+	}
+
+	return ResolutionDropped
+}
@@ -0,0 +1,151 @@
+package safemode
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+
+	"github.com/umee-network/peggo/orchestrator/store"
+)
+
+type mockEthereumClient struct {
+	receipts     map[ethcmn.Hash]*ethtypes.Receipt
+	pending      map[ethcmn.Hash]bool
+	pendingNonce uint64
+}
+
+func (m *mockEthereumClient) TransactionReceipt(_ context.Context, txHash ethcmn.Hash) (*ethtypes.Receipt, error) {
+	if receipt, ok := m.receipts[txHash]; ok {
+		return receipt, nil
+	}
+
+	return nil, ethereum.NotFound
+}
+
+func (m *mockEthereumClient) TransactionByHash(
+	_ context.Context,
+	hash ethcmn.Hash,
+) (*ethtypes.Transaction, bool, error) {
+	if m.pending[hash] {
+		return &ethtypes.Transaction{}, true, nil
+	}
+
+	return nil, false, ethereum.NotFound
+}
+
+func (m *mockEthereumClient) PendingNonceAt(context.Context, ethcmn.Address) (uint64, error) {
+	return m.pendingNonce, nil
+}
+
+type mockCosmosClient struct {
+	txs map[string]*ctypes.ResultTx
+}
+
+func (m *mockCosmosClient) Tx(_ context.Context, hash []byte, _ bool) (*ctypes.ResultTx, error) {
+	if res, ok := m.txs[hex.EncodeToString(hash)]; ok {
+		return res, nil
+	}
+
+	return nil, fmt.Errorf("tx (%X) not found", hash)
+}
+
+func TestResolve(t *testing.T) {
+	s, err := store.New(t.TempDir())
+	require.NoError(t, err)
+
+	j, err := NewJournal(zerolog.Nop(), s)
+	require.NoError(t, err)
+
+	hash := func(b byte) ethcmn.Hash { return ethcmn.BytesToHash([]byte{b}) }
+	old := time.Now().UTC().Add(-time.Hour)
+
+	j.Add(Tx{Chain: ChainEthereum, Hash: hash(1).Hex(), Nonce: 10, SentAt: old})
+	j.Add(Tx{Chain: ChainEthereum, Hash: hash(2).Hex(), Nonce: 11, SentAt: old})
+	j.Add(Tx{Chain: ChainEthereum, Hash: hash(3).Hex(), Nonce: 12, SentAt: old})
+	j.Add(Tx{Chain: ChainEthereum, Hash: hash(4).Hex(), Nonce: 13, SentAt: old})
+	j.Add(Tx{Chain: ChainCosmos, Hash: "0a", Nonce: 5, SentAt: old})
+	j.Add(Tx{Chain: ChainCosmos, Hash: "0b", Nonce: 6, SentAt: old})
+	j.Add(Tx{Chain: ChainCosmos, Hash: "0c", Nonce: 7, SentAt: old})
+
+	eth := &mockEthereumClient{
+		receipts: map[ethcmn.Hash]*ethtypes.Receipt{
+			hash(1): {Status: ethtypes.ReceiptStatusSuccessful},
+			hash(2): {Status: ethtypes.ReceiptStatusFailed},
+		},
+		pending:      map[ethcmn.Hash]bool{},
+		pendingNonce: 13,
+	}
+	cosmos := &mockCosmosClient{
+		txs: map[string]*ctypes.ResultTx{
+			"0a": {Height: 100},
+			"0b": {Height: 101, TxResult: abci.ResponseDeliverTx{Code: 5}},
+		},
+	}
+	sequence := func(context.Context) (uint64, error) { return 7, nil }
+
+	r, err := NewResolver(zerolog.Nop(), Config{Timeout: time.Second}, j, eth, cosmos, sequence)
+	require.NoError(t, err)
+
+	resolutions, err := r.Resolve(context.Background())
+	require.NoError(t, err)
+
+	statuses := map[string]string{}
+	for _, res := range resolutions {
+		statuses[res.Tx.Hash] = res.Status
+	}
+
+	assert.Equal(t, map[string]string{
+		hash(1).Hex(): ResolutionConfirmed,
+		hash(2).Hex(): ResolutionFailed,
+		hash(3).Hex(): ResolutionReplaced,
+		hash(4).Hex(): ResolutionDropped,
+		"0a":          ResolutionConfirmed,
+		"0b":          ResolutionFailed,
+		"0c":          ResolutionDropped,
+	}, statuses)
+}
+
+func TestResolveTimeout(t *testing.T) {
+	s, err := store.New(t.TempDir())
+	require.NoError(t, err)
+
+	j, err := NewJournal(zerolog.Nop(), s)
+	require.NoError(t, err)
+
+	pendingHash := ethcmn.BytesToHash([]byte{1})
+	j.Add(Tx{Chain: ChainEthereum, Hash: pendingHash.Hex(), Nonce: 3})
+	// unknown but sent too recently to be considered dropped
+	j.Add(Tx{Chain: ChainCosmos, Hash: "0a", Nonce: 4})
+
+	eth := &mockEthereumClient{pending: map[ethcmn.Hash]bool{pendingHash: true}, pendingNonce: 4}
+	sequence := func(context.Context) (uint64, error) { return 4, nil }
+
+	r, err := NewResolver(
+		zerolog.Nop(),
+		Config{Timeout: 50 * time.Millisecond, PollInterval: 10 * time.Millisecond},
+		j,
+		eth,
+		&mockCosmosClient{},
+		sequence,
+	)
+	require.NoError(t, err)
+
+	resolutions, err := r.Resolve(context.Background())
+	assert.True(t, errors.Is(err, ErrUnresolved))
+	assert.Empty(t, resolutions)
+
+	_, err = NewResolver(zerolog.Nop(), Config{}, j, eth, &mockCosmosClient{}, sequence)
+	assert.Error(t, err)
+}
@@ -0,0 +1,221 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxLookahead bounds the search of the next time of a cron expression, e.g.
+// "0 0 30 2 *" never matches.
+const maxLookahead = 5 * 366 * 24 * time.Hour
+
+// Schedule defines when a periodic task runs.
+type Schedule interface {
+	// Next returns the first time of the schedule after t, or the zero time if
+	// there's none.
+	Next(t time.Time) time.Time
+}
+
+// every runs a task at a fixed interval.
+type every time.Duration
+
+// Every returns a schedule running a task every interval.
+func Every(interval time.Duration) Schedule {
+	return every(interval)
+}
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+// cron is a parsed cron expression, evaluated in UTC. Each field is the set of
+// its matching values, as a bitmask.
+type cron struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar are set when the day of month or of week is "*". As
+	// in cron, a day matches either of them when both are restricted.
+	domStar, dowStar bool
+}
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a schedule, either:
+//
+//   - "@every <duration>", e.g. "@every 5m"
+//   - a descriptor: @yearly (or @annually), @monthly, @weekly, @daily (or
+//     @midnight) or @hourly
+//   - a cron expression of 5 fields, evaluated in UTC: minute (0-59), hour
+//     (0-23), day of month (1-31), month (1-12) and day of week (0-6, 0 or 7
+//     being Sunday). Each field is "*" or a list of values and ranges (e.g.
+//     "1,15" or "9-17"), optionally with a step (e.g. "*/15" or "0-30/10").
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid schedule %q", spec)
+		}
+
+		if d <= 0 {
+			return nil, fmt.Errorf("invalid schedule %q: the interval must be positive", spec)
+		}
+
+		return Every(d), nil
+	}
+
+	if expr, ok := descriptors[spec]; ok {
+		spec = expr
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 fields, @every or a descriptor", spec)
+	}
+
+	var (
+		c   cron
+		err error
+	)
+
+	for i, f := range []struct {
+		bits     *uint64
+		min, max int
+	}{
+		{&c.minute, 0, 59},
+		{&c.hour, 0, 23},
+		{&c.dom, 1, 31},
+		{&c.month, 1, 12},
+		{&c.dow, 0, 7},
+	} {
+		if *f.bits, err = parseField(fields[i], f.min, f.max); err != nil {
+			return nil, errors.Wrapf(err, "invalid schedule %q", spec)
+		}
+	}
+
+	// Sunday is either 0 or 7
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+
+	c.domStar = fields[2] == "*"
+	c.dowStar = fields[4] == "*"
+
+	return c, nil
+}
+
+// parseField returns the bitmask of the values matching a cron field.
+func parseField(field string, min, max int) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+		}
+
+		var start, end int
+		switch lo, hi, isRange := strings.Cut(rng, "-"); {
+		case rng == "*":
+			start, end = min, max
+
+		case isRange:
+			var err error
+			if start, err = parseValue(lo, min, max); err != nil {
+				return 0, err
+			}
+
+			if end, err = parseValue(hi, min, max); err != nil {
+				return 0, err
+			}
+
+			if start > end {
+				return 0, fmt.Errorf("invalid range %q", rng)
+			}
+
+		default:
+			var err error
+			if start, err = parseValue(rng, min, max); err != nil {
+				return 0, err
+			}
+
+			// a single value with a step runs from it to the maximum
+			end = start
+			if hasStep {
+				end = max
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << v
+		}
+	}
+
+	return bits, nil
+}
+
+func parseValue(s string, min, max int) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < min || v > max {
+		return 0, fmt.Errorf("invalid value %q; expected %d-%d", s, min, max)
+	}
+
+	return v, nil
+}
+
+// Next returns the first minute after t matching the expression, in UTC.
+func (c cron) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxLookahead)
+
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+
+		case !c.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func (c cron) matchDay(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+
+	switch {
+	case c.domStar || c.dowStar:
+		return dom && dow
+
+	default:
+		return dom || dow
+	}
+}
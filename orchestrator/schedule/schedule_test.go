@@ -0,0 +1,65 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	// a Wednesday
+	now := time.Date(2022, 10, 5, 13, 7, 42, 0, time.UTC)
+
+	testCases := []struct {
+		spec string
+		next time.Time
+	}{
+		{"@every 5m", now.Add(5 * time.Minute)},
+		{"@hourly", time.Date(2022, 10, 5, 14, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2022, 10, 6, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2022, 10, 9, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2022, 10, 5, 13, 15, 0, 0, time.UTC)},
+		{"5 0 * * *", time.Date(2022, 10, 6, 0, 5, 0, 0, time.UTC)},
+		{"30 9-17/4 * * 1-5", time.Date(2022, 10, 5, 13, 30, 0, 0, time.UTC)},
+		{"0 12 * * 6,7", time.Date(2022, 10, 8, 12, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// either the day of month or of week when both are set
+		{"0 0 1 * 5", time.Date(2022, 10, 7, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.spec, func(t *testing.T) {
+			s, err := Parse(tc.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tc.next, s.Next(now))
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"@every",
+		"@every -5m",
+		"@fortnightly",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+	} {
+		_, err := Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
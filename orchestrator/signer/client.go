@@ -0,0 +1,128 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+// Client is a ConfirmSigner backed by a signing Server.
+type Client struct {
+	httpClient *http.Client
+	token      string
+}
+
+var _ ConfirmSigner = (*Client)(nil)
+
+// NewClient returns a client of the signing server listening on the given Unix
+// socket.
+func NewClient(socketPath, token string, timeout time.Duration) *Client {
+	var dialer net.Dialer
+
+	return &Client{
+		httpClient: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return dialer.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+		token: token,
+	}
+}
+
+// Address returns the Ethereum address the server signs with.
+func (c *Client) Address(ctx context.Context) (ethcmn.Address, error) {
+	var resp addressResponse
+	if err := c.do(ctx, http.MethodGet, pathAddress, nil, &resp); err != nil {
+		return ethcmn.Address{}, err
+	}
+
+	if !ethcmn.IsHexAddress(resp.Address) {
+		return ethcmn.Address{}, fmt.Errorf("signer returned an invalid address: %s", resp.Address)
+	}
+
+	return ethcmn.HexToAddress(resp.Address), nil
+}
+
+func (c *Client) SignValsetConfirm(
+	ctx context.Context,
+	ethFrom ethcmn.Address,
+	gravityID string,
+	valset gravitytypes.Valset,
+) ([]byte, error) {
+	return c.sign(ctx, pathValsetConfirm, valsetConfirmRequest{
+		EthAddress: ethFrom.Hex(),
+		GravityID:  gravityID,
+		Valset:     valset,
+	})
+}
+
+func (c *Client) SignBatchConfirm(
+	ctx context.Context,
+	ethFrom ethcmn.Address,
+	gravityID string,
+	batch gravitytypes.OutgoingTxBatch,
+) ([]byte, error) {
+	return c.sign(ctx, pathBatchConfirm, batchConfirmRequest{
+		EthAddress: ethFrom.Hex(),
+		GravityID:  gravityID,
+		Batch:      batch,
+	})
+}
+
+func (c *Client) sign(ctx context.Context, path string, req interface{}) ([]byte, error) {
+	var resp signatureResponse
+	if err := c.do(ctx, http.MethodPost, path, req, &resp); err != nil {
+		return nil, err
+	}
+
+	sig, err := hexutil.Decode(resp.Signature)
+	if err != nil {
+		return nil, errors.Wrap(err, "signer returned an invalid signature")
+	}
+
+	return sig, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, req, resp interface{}) error {
+	var body bytes.Buffer
+	if req != nil {
+		if err := json.NewEncoder(&body).Encode(req); err != nil {
+			return errors.Wrap(err, "failed to encode signing request")
+		}
+	}
+
+	// The host is ignored, every request goes to the socket.
+	httpReq, err := http.NewRequestWithContext(ctx, method, "http://signer"+path, &body)
+	if err != nil {
+		return err
+	}
+
+	httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return errors.Wrap(err, "failed to reach signer")
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		var errResp errorResponse
+		_ = json.NewDecoder(httpResp.Body).Decode(&errResp)
+		return fmt.Errorf("signer refused request (%s): %s", httpResp.Status, errResp.Error)
+	}
+
+	return errors.Wrap(json.NewDecoder(httpResp.Body).Decode(resp), "failed to decode signer response")
+}
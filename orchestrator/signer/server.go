@@ -0,0 +1,300 @@
+package signer
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/rs/zerolog"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/gravity"
+)
+
+const (
+	pathAddress       = "/v1/address"
+	pathValsetConfirm = "/v1/valset-confirm"
+	pathBatchConfirm  = "/v1/batch-confirm"
+
+	// MinTokenLength is the minimum length of the token shared by the signer and
+	// the orchestrator.
+	MinTokenLength = 16
+
+	maxRequestSize = 1 << 20
+)
+
+type (
+	// Server exposes the confirm signing of a ConfirmSigner, and nothing else,
+	// on a Unix socket. Every request must carry the shared token, and only the
+	// valsets and batches of its Source are signed.
+	Server struct {
+		logger     zerolog.Logger
+		socketPath string
+		token      string
+		signer     ConfirmSigner
+		source     Source
+		ethAddress ethcmn.Address
+		gravityID  string
+	}
+
+	addressResponse struct {
+		Address string `json:"address"`
+	}
+
+	valsetConfirmRequest struct {
+		EthAddress string              `json:"eth_address"`
+		GravityID  string              `json:"gravity_id"`
+		Valset     gravitytypes.Valset `json:"valset"`
+	}
+
+	batchConfirmRequest struct {
+		EthAddress string                       `json:"eth_address"`
+		GravityID  string                       `json:"gravity_id"`
+		Batch      gravitytypes.OutgoingTxBatch `json:"batch"`
+	}
+
+	signatureResponse struct {
+		Signature string `json:"signature"`
+	}
+
+	errorResponse struct {
+		Error string `json:"error"`
+	}
+)
+
+// NewServer returns a signing server for the given Ethereum address, refusing
+// confirms for any other Gravity ID than gravityID.
+func NewServer(
+	logger zerolog.Logger,
+	socketPath string,
+	token string,
+	signer ConfirmSigner,
+	source Source,
+	ethAddress ethcmn.Address,
+	gravityID string,
+) (*Server, error) {
+	if socketPath == "" {
+		return nil, errors.New("signer socket path is empty")
+	}
+
+	if len(token) < MinTokenLength {
+		return nil, fmt.Errorf("signer token must be at least %d characters long", MinTokenLength)
+	}
+
+	if gravityID == "" {
+		return nil, errors.New("signer gravity ID is empty")
+	}
+
+	return &Server{
+		logger:     logger.With().Str("module", "signer").Logger(),
+		socketPath: socketPath,
+		token:      token,
+		signer:     signer,
+		source:     source,
+		ethAddress: ethAddress,
+		gravityID:  gravityID,
+	}, nil
+}
+
+// Start serves signing requests until the context is done. The socket is only
+// accessible by the user running the signer.
+func (s *Server) Start(ctx context.Context) error {
+	// Remove a socket left behind by a previous run.
+	if err := os.Remove(s.socketPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove stale signer socket: %w", err)
+	}
+
+	listener, err := net.Listen("unix", s.socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on signer socket: %w", err)
+	}
+
+	if err := os.Chmod(s.socketPath, 0o600); err != nil {
+		_ = listener.Close()
+		return fmt.Errorf("failed to restrict signer socket permissions: %w", err)
+	}
+
+	srv := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			s.logger.Err(err).Msg("failed to shut down signer")
+		}
+	}()
+
+	s.logger.Info().
+		Str("socket", s.socketPath).
+		Str("eth_address", s.ethAddress.Hex()).
+		Msg("serving confirm signing requests")
+
+	if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve signing requests: %w", err)
+	}
+
+	return nil
+}
+
+// Handler returns the HTTP handler of the signing API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(pathAddress, s.handleAddress)
+	mux.HandleFunc(pathValsetConfirm, s.handleValsetConfirm)
+	mux.HandleFunc(pathBatchConfirm, s.handleBatchConfirm)
+
+	return s.authenticate(mux)
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			s.logger.Warn().Str("path", r.URL.Path).Msg("refused unauthenticated signing request")
+			writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "unauthorized"})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleAddress(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, addressResponse{Address: s.ethAddress.Hex()})
+}
+
+func (s *Server) handleValsetConfirm(w http.ResponseWriter, r *http.Request) {
+	var req valsetConfirmRequest
+	if !s.decode(w, r, &req) || !s.checkSigner(w, req.EthAddress, req.GravityID) {
+		return
+	}
+
+	valset, err := s.source.Valset(r.Context(), req.Valset.Nonce)
+	if err != nil {
+		s.logger.Err(err).Uint64("nonce", req.Valset.Nonce).Msg("failed to get valset from the Gravity module")
+		writeSourceError(w, err)
+		return
+	}
+
+	// the valset is signed as stored on the Gravity module, so a request that
+	// doesn't match it was tampered with
+	if gravity.EncodeValsetConfirm(s.gravityID, valset) != gravity.EncodeValsetConfirm(s.gravityID, req.Valset) {
+		s.logger.Warn().Uint64("nonce", valset.Nonce).Msg("refused valset confirm not matching the Gravity module")
+		writeJSON(w, http.StatusConflict, errorResponse{Error: "valset doesn't match the Gravity module"})
+		return
+	}
+
+	sig, err := s.signer.SignValsetConfirm(r.Context(), s.ethAddress, s.gravityID, valset)
+	if err != nil {
+		s.logger.Err(err).Uint64("nonce", valset.Nonce).Msg("failed to sign valset confirm")
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to sign valset confirm"})
+		return
+	}
+
+	s.logger.Info().Uint64("nonce", valset.Nonce).Msg("signed valset confirm")
+	writeJSON(w, http.StatusOK, signatureResponse{Signature: hexutil.Encode(sig)})
+}
+
+func (s *Server) handleBatchConfirm(w http.ResponseWriter, r *http.Request) {
+	var req batchConfirmRequest
+	if !s.decode(w, r, &req) || !s.checkSigner(w, req.EthAddress, req.GravityID) {
+		return
+	}
+
+	batch, err := s.source.Batch(r.Context(), req.Batch.TokenContract, req.Batch.BatchNonce)
+	if err != nil {
+		s.logger.Err(err).
+			Uint64("nonce", req.Batch.BatchNonce).
+			Str("token_contract", req.Batch.TokenContract).
+			Msg("failed to get batch from the Gravity module")
+		writeSourceError(w, err)
+		return
+	}
+
+	if gravity.EncodeTxBatchConfirm(s.gravityID, batch) != gravity.EncodeTxBatchConfirm(s.gravityID, req.Batch) {
+		s.logger.Warn().
+			Uint64("nonce", batch.BatchNonce).
+			Str("token_contract", batch.TokenContract).
+			Msg("refused batch confirm not matching the Gravity module")
+		writeJSON(w, http.StatusConflict, errorResponse{Error: "batch doesn't match the Gravity module"})
+		return
+	}
+
+	sig, err := s.signer.SignBatchConfirm(r.Context(), s.ethAddress, s.gravityID, batch)
+	if err != nil {
+		s.logger.Err(err).Uint64("nonce", batch.BatchNonce).Msg("failed to sign batch confirm")
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to sign batch confirm"})
+		return
+	}
+
+	s.logger.Info().
+		Uint64("nonce", batch.BatchNonce).
+		Str("token_contract", batch.TokenContract).
+		Msg("signed batch confirm")
+	writeJSON(w, http.StatusOK, signatureResponse{Signature: hexutil.Encode(sig)})
+}
+
+func (s *Server) decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return false
+	}
+
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(v); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid request: %s", err)})
+		return false
+	}
+
+	return true
+}
+
+func (s *Server) checkSigner(w http.ResponseWriter, ethAddress, gravityID string) bool {
+	if !ethcmn.IsHexAddress(ethAddress) || ethcmn.HexToAddress(ethAddress) != s.ethAddress {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("unknown signer: %s", ethAddress)})
+		return false
+	}
+
+	if gravityID != s.gravityID {
+		s.logger.Warn().Str("gravity_id", gravityID).Msg("refused confirm for another Gravity ID")
+		writeJSON(w, http.StatusForbidden, errorResponse{Error: fmt.Sprintf("unexpected gravity ID: %s", gravityID)})
+		return false
+	}
+
+	return true
+}
+
+// writeSourceError reports a valset or batch the Source failed to return.
+func writeSourceError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusBadGateway, errorResponse{Error: "failed to query the Gravity module"})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
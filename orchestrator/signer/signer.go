@@ -0,0 +1,94 @@
+package signer
+
+import (
+	"context"
+	"errors"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/gravity"
+	"github.com/umee-network/peggo/orchestrator/ethereum/keystore"
+)
+
+// ErrRemoteKey is returned when something other than a confirm needs to be
+// signed while the Ethereum key is held by a remote signer.
+var ErrRemoteKey = errors.New("the Ethereum key is held by a remote signer")
+
+type (
+	// ConfirmSigner signs the valset and batch confirms of an orchestrator.
+	// Implementations hash the valset or batch themselves, so they never sign
+	// arbitrary data.
+	ConfirmSigner interface {
+		SignValsetConfirm(
+			ctx context.Context,
+			ethFrom ethcmn.Address,
+			gravityID string,
+			valset gravitytypes.Valset,
+		) ([]byte, error)
+		SignBatchConfirm(
+			ctx context.Context,
+			ethFrom ethcmn.Address,
+			gravityID string,
+			batch gravitytypes.OutgoingTxBatch,
+		) ([]byte, error)
+	}
+
+	localSigner struct {
+		signFn keystore.PersonalSignFn
+	}
+
+	typedDataSigner struct {
+		signFn keystore.TypedDataSignFn
+		scheme gravity.TypedConfirmScheme
+	}
+)
+
+// NewLocal returns a ConfirmSigner that signs with a key held in process.
+func NewLocal(signFn keystore.PersonalSignFn) ConfirmSigner {
+	return &localSigner{signFn: signFn}
+}
+
+func (s *localSigner) SignValsetConfirm(
+	_ context.Context,
+	ethFrom ethcmn.Address,
+	gravityID string,
+	valset gravitytypes.Valset,
+) ([]byte, error) {
+	confirmHash := gravity.EncodeValsetConfirm(gravityID, valset)
+	return s.signFn(ethFrom, confirmHash.Bytes())
+}
+
+func (s *localSigner) SignBatchConfirm(
+	_ context.Context,
+	ethFrom ethcmn.Address,
+	gravityID string,
+	batch gravitytypes.OutgoingTxBatch,
+) ([]byte, error) {
+	confirmHash := gravity.EncodeTxBatchConfirm(gravityID, batch)
+	return s.signFn(ethFrom, confirmHash.Bytes())
+}
+
+// NewTypedData returns a ConfirmSigner that signs EIP-712 typed confirms of the
+// given scheme with a key held in process, for Gravity forks expecting them.
+func NewTypedData(signFn keystore.TypedDataSignFn, scheme gravity.TypedConfirmScheme) ConfirmSigner {
+	return &typedDataSigner{signFn: signFn, scheme: scheme}
+}
+
+func (s *typedDataSigner) SignValsetConfirm(
+	_ context.Context,
+	ethFrom ethcmn.Address,
+	gravityID string,
+	valset gravitytypes.Valset,
+) ([]byte, error) {
+	return s.signFn(ethFrom, gravity.EncodeTypedValsetConfirm(s.scheme, gravityID, valset))
+}
+
+func (s *typedDataSigner) SignBatchConfirm(
+	_ context.Context,
+	ethFrom ethcmn.Address,
+	gravityID string,
+	batch gravitytypes.OutgoingTxBatch,
+) ([]byte, error) {
+	return s.signFn(ethFrom, gravity.EncodeTypedBatchConfirm(s.scheme, gravityID, batch))
+}
@@ -0,0 +1,198 @@
+package signer
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/gravity"
+	"github.com/umee-network/peggo/orchestrator/ethereum/keystore"
+)
+
+// fakeSource holds the valsets and batches of a Gravity module.
+type fakeSource struct {
+	valsets map[uint64]gravitytypes.Valset
+	batches map[uint64]gravitytypes.OutgoingTxBatch
+}
+
+func (s *fakeSource) Valset(_ context.Context, nonce uint64) (gravitytypes.Valset, error) {
+	valset, ok := s.valsets[nonce]
+	if !ok {
+		return gravitytypes.Valset{}, ErrNotFound
+	}
+
+	return valset, nil
+}
+
+func (s *fakeSource) Batch(
+	_ context.Context,
+	tokenContract string,
+	nonce uint64,
+) (gravitytypes.OutgoingTxBatch, error) {
+	batch, ok := s.batches[nonce]
+	if !ok || batch.TokenContract != tokenContract {
+		return gravitytypes.OutgoingTxBatch{}, ErrNotFound
+	}
+
+	return batch, nil
+}
+
+func TestServer(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	ethAddr := crypto.PubkeyToAddress(key.PublicKey)
+
+	signFn, err := keystore.PrivateKeyPersonalSignFn(key)
+	require.NoError(t, err)
+
+	socketPath := filepath.Join(t.TempDir(), "signer.sock")
+	token := "0123456789abcdef"
+
+	valset := gravitytypes.Valset{
+		Nonce:        3,
+		Members:      []gravitytypes.BridgeValidator{{Power: 100, EthereumAddress: ethAddr.Hex()}},
+		RewardAmount: sdk.NewInt(0),
+	}
+
+	tokenContract := "0x0000000000000000000000000000000000000001"
+	batch := gravitytypes.OutgoingTxBatch{
+		BatchNonce:    7,
+		TokenContract: tokenContract,
+		Transactions: []gravitytypes.OutgoingTransferTx{{
+			Id:          1,
+			DestAddress: "0x0000000000000000000000000000000000000002",
+			Erc20Token:  gravitytypes.ERC20Token{Contract: tokenContract, Amount: sdk.NewInt(5)},
+			Erc20Fee:    gravitytypes.ERC20Token{Contract: tokenContract, Amount: sdk.NewInt(1)},
+		}},
+	}
+
+	source := &fakeSource{
+		valsets: map[uint64]gravitytypes.Valset{valset.Nonce: valset},
+		batches: map[uint64]gravitytypes.OutgoingTxBatch{batch.BatchNonce: batch},
+	}
+
+	_, err = NewServer(zerolog.Nop(), socketPath, "short", NewLocal(signFn), source, ethAddr, "gravity-test")
+	require.Error(t, err)
+
+	_, err = NewServer(zerolog.Nop(), socketPath, token, NewLocal(signFn), source, ethAddr, "")
+	require.Error(t, err)
+
+	server, err := NewServer(zerolog.Nop(), socketPath, token, NewLocal(signFn), source, ethAddr, "gravity-test")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- server.Start(ctx) }()
+	defer func() {
+		cancel()
+		require.NoError(t, <-done)
+	}()
+
+	client := NewClient(socketPath, token, time.Second)
+
+	var addr ethcmn.Address
+	require.Eventually(t, func() bool {
+		addr, err = client.Address(ctx)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, ethAddr, addr)
+
+	sig, err := client.SignValsetConfirm(ctx, ethAddr, "gravity-test", valset)
+	require.NoError(t, err)
+
+	confirmHash := gravity.EncodeValsetConfirm("gravity-test", valset)
+	pubKey, err := crypto.SigToPub(accounts.TextHash(confirmHash.Bytes()), sig)
+	require.NoError(t, err)
+	assert.Equal(t, ethAddr, crypto.PubkeyToAddress(*pubKey))
+
+	sig, err = client.SignBatchConfirm(ctx, ethAddr, "gravity-test", batch)
+	require.NoError(t, err)
+
+	confirmHash = gravity.EncodeTxBatchConfirm("gravity-test", batch)
+	pubKey, err = crypto.SigToPub(accounts.TextHash(confirmHash.Bytes()), sig)
+	require.NoError(t, err)
+	assert.Equal(t, ethAddr, crypto.PubkeyToAddress(*pubKey))
+
+	// another Gravity ID
+	_, err = client.SignValsetConfirm(ctx, ethAddr, "gravity-other", valset)
+	assert.Error(t, err)
+
+	// a valset or batch that isn't on the Gravity module
+	unknownValset := valset
+	unknownValset.Nonce = 4
+	_, err = client.SignValsetConfirm(ctx, ethAddr, "gravity-test", unknownValset)
+	assert.Error(t, err)
+
+	unknownBatch := batch
+	unknownBatch.TokenContract = "0x0000000000000000000000000000000000000004"
+	_, err = client.SignBatchConfirm(ctx, ethAddr, "gravity-test", unknownBatch)
+	assert.Error(t, err)
+
+	// or that doesn't match it
+	tamperedValset := valset
+	tamperedValset.Members = []gravitytypes.BridgeValidator{{Power: 100, EthereumAddress: tokenContract}}
+	_, err = client.SignValsetConfirm(ctx, ethAddr, "gravity-test", tamperedValset)
+	assert.Error(t, err)
+
+	tamperedBatch := batch
+	tamperedBatch.BatchTimeout = 1
+	_, err = client.SignBatchConfirm(ctx, ethAddr, "gravity-test", tamperedBatch)
+	assert.Error(t, err)
+
+	// another signer
+	otherAddr := ethcmn.HexToAddress("0x0000000000000000000000000000000000000003")
+	_, err = client.SignValsetConfirm(ctx, otherAddr, "gravity-test", valset)
+	assert.Error(t, err)
+
+	// wrong token
+	client = NewClient(socketPath, "fedcba9876543210", time.Second)
+	_, err = client.SignValsetConfirm(ctx, ethAddr, "gravity-test", valset)
+	assert.Error(t, err)
+}
+
+func TestTypedDataSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	ethAddr := crypto.PubkeyToAddress(key.PublicKey)
+
+	signFn, err := keystore.PrivateKeyTypedDataSignFn(key)
+	require.NoError(t, err)
+
+	valsetType, err := gravity.ParseValsetConfirmType(gravity.DefaultValsetConfirmType)
+	require.NoError(t, err)
+
+	scheme := gravity.TypedConfirmScheme{
+		Domain: gravity.EIP712Domain{
+			Name:              "Gravity",
+			Version:           "1",
+			ChainID:           big.NewInt(5),
+			VerifyingContract: ethcmn.HexToAddress("0x0000000000000000000000000000000000000001"),
+		},
+		ValsetType: valsetType,
+	}
+
+	valset := gravitytypes.Valset{
+		Nonce:        3,
+		Members:      []gravitytypes.BridgeValidator{{Power: 100, EthereumAddress: ethAddr.Hex()}},
+		RewardAmount: sdk.NewInt(0),
+	}
+
+	sig, err := NewTypedData(signFn, scheme).SignValsetConfirm(context.Background(), ethAddr, "gravity-test", valset)
+	require.NoError(t, err)
+
+	typedData := gravity.EncodeTypedValsetConfirm(scheme, "gravity-test", valset)
+	pubKey, err := crypto.SigToPub(crypto.Keccak256(typedData), sig)
+	require.NoError(t, err)
+	assert.Equal(t, ethAddr, crypto.PubkeyToAddress(*pubKey))
+}
@@ -0,0 +1,64 @@
+package signer
+
+import (
+	"context"
+	"errors"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+)
+
+// ErrNotFound is returned by a Source for a valset or batch the Gravity module
+// doesn't hold.
+var ErrNotFound = errors.New("not found on the Gravity module")
+
+type (
+	// Source returns the valsets and batches of the Gravity module. The Server
+	// only signs those, whatever the requests hold.
+	Source interface {
+		Valset(ctx context.Context, nonce uint64) (gravitytypes.Valset, error)
+		Batch(ctx context.Context, tokenContract string, nonce uint64) (gravitytypes.OutgoingTxBatch, error)
+	}
+
+	gravitySource struct {
+		queryClient gravitytypes.QueryClient
+	}
+)
+
+// NewGravitySource returns a Source querying the Gravity module, which should
+// be reached through the signer's own node.
+func NewGravitySource(queryClient gravitytypes.QueryClient) Source {
+	return &gravitySource{queryClient: queryClient}
+}
+
+func (s *gravitySource) Valset(ctx context.Context, nonce uint64) (gravitytypes.Valset, error) {
+	resp, err := s.queryClient.ValsetRequest(ctx, &gravitytypes.QueryValsetRequestRequest{Nonce: nonce})
+	if err != nil {
+		return gravitytypes.Valset{}, err
+	}
+
+	if resp.Valset == nil {
+		return gravitytypes.Valset{}, ErrNotFound
+	}
+
+	return *resp.Valset, nil
+}
+
+func (s *gravitySource) Batch(
+	ctx context.Context,
+	tokenContract string,
+	nonce uint64,
+) (gravitytypes.OutgoingTxBatch, error) {
+	resp, err := s.queryClient.BatchRequestByNonce(ctx, &gravitytypes.QueryBatchRequestByNonceRequest{
+		Nonce:           nonce,
+		ContractAddress: tokenContract,
+	})
+	if err != nil {
+		return gravitytypes.OutgoingTxBatch{}, err
+	}
+
+	if resp.Batch.BatchNonce != nonce {
+		return gravitytypes.OutgoingTxBatch{}, ErrNotFound
+	}
+
+	return resp.Batch, nil
+}
@@ -0,0 +1,246 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/keystore"
+	"github.com/umee-network/peggo/orchestrator/rawgrpc"
+)
+
+// The methods of the threshold signing service. A compatible service only has
+// to implement these two calls on top of its TSS protocol.
+const (
+	thresholdPublicKeyMethod = "/peggo.signer.v1.ThresholdSigner/PublicKey"
+	thresholdSignMethod      = "/peggo.signer.v1.ThresholdSigner/Sign"
+)
+
+// The kinds of payloads sent to the threshold signing service, so the parties
+// can apply their own policy to the message before signing its digest.
+const (
+	ThresholdKindTransaction = "transaction"
+	ThresholdKindPersonal    = "personal"
+	ThresholdKindTypedData   = "typed_data"
+)
+
+// ThresholdClient signs with an Ethereum key split across the parties of a
+// threshold signing (TSS) service, so no single host holds the complete key.
+// Unlike a peggo signer, it signs transactions as well as confirms.
+type ThresholdClient struct {
+	invoke  rawgrpc.InvokeFn
+	keyID   string
+	timeout time.Duration
+	address ethcmn.Address
+}
+
+// DialThreshold connects to the threshold signing service at the given gRPC
+// address and resolves the address of the key. The connection uses TLS when a
+// CA certificate file is given. It is closed once the context is done.
+func DialThreshold(
+	ctx context.Context,
+	grpcAddr string,
+	caFile string,
+	keyID string,
+	timeout time.Duration,
+) (*ThresholdClient, error) {
+	creds := insecure.NewCredentials()
+	if caFile != "" {
+		var err error
+		if creds, err = credentials.NewClientTLSFromFile(caFile, ""); err != nil {
+			return nil, errors.Wrap(err, "failed to load the threshold signer CA certificate")
+		}
+	}
+
+	invoke, err := rawgrpc.Dial(ctx, grpcAddr, creds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the threshold signer %s: %w", grpcAddr, err)
+	}
+
+	return newThresholdClient(ctx, invoke, keyID, timeout)
+}
+
+func newThresholdClient(
+	ctx context.Context,
+	invoke rawgrpc.InvokeFn,
+	keyID string,
+	timeout time.Duration,
+) (*ThresholdClient, error) {
+	if keyID == "" {
+		return nil, errors.New("the threshold signer requires a key ID")
+	}
+
+	c := &ThresholdClient{
+		invoke:  invoke,
+		keyID:   keyID,
+		timeout: timeout,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res := &thresholdPublicKeyResponse{}
+	if err := invoke(ctx, thresholdPublicKeyMethod, &thresholdPublicKeyRequest{KeyID: keyID}, res); err != nil {
+		return nil, errors.Wrapf(err, "failed to get the public key of %s from the threshold signer", keyID)
+	}
+
+	pubKey, err := unmarshalPubkey(res.PublicKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "threshold signer returned an invalid public key for %s", keyID)
+	}
+
+	c.address = crypto.PubkeyToAddress(*pubKey)
+
+	return c, nil
+}
+
+// Address returns the Ethereum address of the threshold key.
+func (c *ThresholdClient) Address() ethcmn.Address {
+	return c.address
+}
+
+// SignerFn returns a transaction signer for the given chain ID.
+func (c *ThresholdClient) SignerFn(chainID uint64) bind.SignerFn {
+	txSigner := ethtypes.LatestSignerForChainID(new(big.Int).SetUint64(chainID))
+
+	return func(from ethcmn.Address, tx *ethtypes.Transaction) (*ethtypes.Transaction, error) {
+		if from != c.address {
+			return nil, bind.ErrNotAuthorized
+		}
+
+		rawTx, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encode transaction")
+		}
+
+		sig, err := c.sign(ThresholdKindTransaction, txSigner.Hash(tx).Bytes(), rawTx)
+		if err != nil {
+			return nil, err
+		}
+
+		return tx.WithSignature(txSigner, sig)
+	}
+}
+
+// PersonalSignFn returns a personal_sign function.
+func (c *ThresholdClient) PersonalSignFn() keystore.PersonalSignFn {
+	return func(from ethcmn.Address, data []byte) ([]byte, error) {
+		if from != c.address {
+			return nil, errors.New("from address mismatch")
+		}
+
+		return c.sign(ThresholdKindPersonal, accounts.TextHash(data), data)
+	}
+}
+
+// TypedDataSignFn returns an EIP-712 typed data signing function.
+func (c *ThresholdClient) TypedDataSignFn() keystore.TypedDataSignFn {
+	return func(from ethcmn.Address, typedData []byte) ([]byte, error) {
+		if from != c.address {
+			return nil, errors.New("from address mismatch")
+		}
+
+		return c.sign(ThresholdKindTypedData, crypto.Keccak256(typedData), typedData)
+	}
+}
+
+// sign has the parties sign the digest of the message. The signature is
+// verified against the key's address, so a faulty quorum can't make peggo
+// submit a signature of another key.
+func (c *ThresholdClient) sign(kind string, digest, message []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	req := &thresholdSignRequest{
+		KeyID:   c.keyID,
+		Digest:  digest,
+		Kind:    kind,
+		Message: message,
+	}
+	res := &thresholdSignResponse{}
+	if err := c.invoke(ctx, thresholdSignMethod, req, res); err != nil {
+		return nil, errors.Wrapf(err, "threshold signer failed to sign %s", kind)
+	}
+
+	sig := res.Signature
+	if len(sig) != crypto.SignatureLength {
+		return nil, fmt.Errorf("threshold signer returned a signature of %d bytes", len(sig))
+	}
+
+	// [R || S || V] with V as 0/1, like crypto.Sign; some services return 27/28.
+	sig = append([]byte(nil), sig...)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	pubKey, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		return nil, errors.Wrap(err, "threshold signer returned an invalid signature")
+	}
+
+	if signer := crypto.PubkeyToAddress(*pubKey); signer != c.address {
+		return nil, fmt.Errorf("threshold signature recovers to %s instead of %s", signer, c.address)
+	}
+
+	return sig, nil
+}
+
+func unmarshalPubkey(pubKey []byte) (*ecdsa.PublicKey, error) {
+	if len(pubKey) == 33 {
+		return crypto.DecompressPubkey(pubKey)
+	}
+
+	return crypto.UnmarshalPubkey(pubKey)
+}
+
+// The threshold signing service messages (see rawgrpc).
+type (
+	thresholdPublicKeyRequest struct {
+		KeyID string `protobuf:"bytes,1,opt,name=key_id,json=keyId,proto3"`
+	}
+
+	// thresholdPublicKeyResponse holds a compressed or uncompressed secp256k1
+	// public key.
+	thresholdPublicKeyResponse struct {
+		PublicKey []byte `protobuf:"bytes,1,opt,name=public_key,json=publicKey,proto3"`
+	}
+
+	thresholdSignRequest struct {
+		KeyID   string `protobuf:"bytes,1,opt,name=key_id,json=keyId,proto3"`
+		Digest  []byte `protobuf:"bytes,2,opt,name=digest,proto3"`
+		Kind    string `protobuf:"bytes,3,opt,name=kind,proto3"`
+		Message []byte `protobuf:"bytes,4,opt,name=message,proto3"`
+	}
+
+	// thresholdSignResponse holds a [R || S || V] signature.
+	thresholdSignResponse struct {
+		Signature []byte `protobuf:"bytes,1,opt,name=signature,proto3"`
+	}
+)
+
+func (m *thresholdPublicKeyRequest) Reset()         { *m = thresholdPublicKeyRequest{} }
+func (m *thresholdPublicKeyRequest) String() string { return fmt.Sprintf("%+v", *m) }
+func (*thresholdPublicKeyRequest) ProtoMessage()    {}
+
+func (m *thresholdPublicKeyResponse) Reset()         { *m = thresholdPublicKeyResponse{} }
+func (m *thresholdPublicKeyResponse) String() string { return fmt.Sprintf("%+v", *m) }
+func (*thresholdPublicKeyResponse) ProtoMessage()    {}
+
+func (m *thresholdSignRequest) Reset()         { *m = thresholdSignRequest{} }
+func (m *thresholdSignRequest) String() string { return fmt.Sprintf("%+v", *m) }
+func (*thresholdSignRequest) ProtoMessage()    {}
+
+func (m *thresholdSignResponse) Reset()         { *m = thresholdSignResponse{} }
+func (m *thresholdSignResponse) String() string { return fmt.Sprintf("%+v", *m) }
+func (*thresholdSignResponse) ProtoMessage()    {}
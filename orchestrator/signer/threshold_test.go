@@ -0,0 +1,106 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umee-network/peggo/orchestrator/rawgrpc"
+)
+
+// fakeThresholdSigner stands in for the parties of a threshold signing service,
+// signing with the complete key.
+func fakeThresholdSigner(t *testing.T, key *ecdsa.PrivateKey, signKey *ecdsa.PrivateKey) rawgrpc.InvokeFn {
+	return func(_ context.Context, method string, req, reply interface{}) error {
+		switch method {
+		case thresholdPublicKeyMethod:
+			assert.Equal(t, "relayer", req.(*thresholdPublicKeyRequest).KeyID)
+			reply.(*thresholdPublicKeyResponse).PublicKey = crypto.CompressPubkey(&key.PublicKey)
+
+		case thresholdSignMethod:
+			r := req.(*thresholdSignRequest)
+			sig, err := crypto.Sign(r.Digest, signKey)
+			if err != nil {
+				return err
+			}
+
+			// some services return V as 27/28
+			sig[crypto.RecoveryIDOffset] += 27
+			reply.(*thresholdSignResponse).Signature = sig
+
+		default:
+			return fmt.Errorf("unexpected method %s", method)
+		}
+
+		return nil
+	}
+}
+
+func TestThresholdClient(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	ethAddr := crypto.PubkeyToAddress(key.PublicKey)
+
+	_, err = newThresholdClient(context.Background(), fakeThresholdSigner(t, key, key), "", time.Second)
+	require.Error(t, err)
+
+	c, err := newThresholdClient(context.Background(), fakeThresholdSigner(t, key, key), "relayer", time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, ethAddr, c.Address())
+
+	data := []byte("confirm")
+
+	sig, err := c.PersonalSignFn()(ethAddr, data)
+	require.NoError(t, err)
+	pubKey, err := crypto.SigToPub(accounts.TextHash(data), sig)
+	require.NoError(t, err)
+	assert.Equal(t, ethAddr, crypto.PubkeyToAddress(*pubKey))
+
+	sig, err = c.TypedDataSignFn()(ethAddr, data)
+	require.NoError(t, err)
+	pubKey, err = crypto.SigToPub(crypto.Keccak256(data), sig)
+	require.NoError(t, err)
+	assert.Equal(t, ethAddr, crypto.PubkeyToAddress(*pubKey))
+
+	_, err = c.PersonalSignFn()(ethcmn.Address{}, data)
+	assert.Error(t, err)
+
+	tx := ethtypes.NewTx(&ethtypes.DynamicFeeTx{
+		ChainID:   big.NewInt(5),
+		Nonce:     1,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(100),
+		Gas:       21000,
+		To:        &ethcmn.Address{},
+		Value:     big.NewInt(1),
+	})
+
+	signedTx, err := c.SignerFn(5)(ethAddr, tx)
+	require.NoError(t, err)
+	sender, err := ethtypes.Sender(ethtypes.LatestSignerForChainID(big.NewInt(5)), signedTx)
+	require.NoError(t, err)
+	assert.Equal(t, ethAddr, sender)
+}
+
+func TestThresholdClientRejectsOtherKey(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	c, err := newThresholdClient(context.Background(), fakeThresholdSigner(t, key, otherKey), "relayer", time.Second)
+	require.NoError(t, err)
+
+	_, err = c.PersonalSignFn()(c.Address(), []byte("confirm"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,126 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/avast/retry-go"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// startupScanAttempts bounds the attempts of a chunk scan rate limited by
+	// the Ethereum node.
+	startupScanAttempts = 6
+	// startupScanBackoff is the first delay before retrying a rate limited chunk
+	// scan; it doubles on each attempt.
+	startupScanBackoff = time.Second
+)
+
+type (
+	// startupScanChunk is a block range scanned for the last claimed event.
+	startupScanChunk struct {
+		start, end uint64
+	}
+
+	startupScanResult struct {
+		found bool
+		block uint64
+		// reachedFirstValset is set when the chunk holds the first valset of the
+		// contract, but not the event looked for.
+		reachedFirstValset bool
+	}
+)
+
+// SetStartupScan returns the orchestrator option scanning workers chunks of
+// chunkSize blocks at once when looking for the last claimed event.
+func SetStartupScan(workers int, chunkSize uint64) func(GravityOrchestrator) {
+	return func(o GravityOrchestrator) { o.SetStartupScan(workers, chunkSize) }
+}
+
+// SetStartupScan sets the parallelism and chunk size of the historical scan for
+// the last claimed event, run at startup and on resyncs. It is distinct from
+// the steady-state scan, so archive nodes allowing it can be scanned faster. A
+// zero chunk size scans --eth-blocks-per-loop blocks per chunk.
+func (p *gravityOrchestrator) SetStartupScan(workers int, chunkSize uint64) {
+	p.startupScanWorkers = workers
+	p.startupScanChunkSize = chunkSize
+}
+
+// startupScanChunks returns up to n chunks, newest first, ending at the given
+// block. Consecutive chunks share their boundary block.
+func (p *gravityOrchestrator) startupScanChunks(end uint64, n int) []startupScanChunk {
+	chunkSize := p.startupScanChunkSize
+	if chunkSize == 0 {
+		chunkSize = p.ethBlocksPerLoop
+	}
+
+	var chunks []startupScanChunk
+	for end > 0 && len(chunks) < n {
+		start := uint64(0)
+		if end >= chunkSize {
+			start = end - chunkSize
+		}
+
+		chunks = append(chunks, startupScanChunk{start: start, end: end})
+		end = start
+	}
+
+	return chunks
+}
+
+// scanChunkWithBackoff scans the chunk, backing off while the Ethereum node
+// rate limits the scan; rateLimited is then set so fewer chunks are scanned at
+// once afterwards.
+func (p *gravityOrchestrator) scanChunkWithBackoff(
+	ctx context.Context,
+	gravityAddr ethcmn.Address,
+	chunk startupScanChunk,
+	lastEventNonce uint64,
+	rateLimited *atomic.Bool,
+) (res startupScanResult, err error) {
+	err = retry.Do(func() (err error) {
+		res, err = p.scanChunk(ctx, gravityAddr, chunk, lastEventNonce)
+		return err
+	},
+		retry.Context(ctx),
+		retry.Attempts(startupScanAttempts),
+		retry.Delay(startupScanBackoff),
+		retry.DelayType(retry.BackOffDelay),
+		retry.LastErrorOnly(true),
+		retry.RetryIf(isRateLimitErr),
+		retry.OnRetry(func(n uint, err error) {
+			rateLimited.Store(true)
+			p.logger.Warn().
+				Uint64("start", chunk.start).
+				Uint64("end", chunk.end).
+				Uint("retry", n).
+				Msg("Ethereum node rate limited the startup scan; backing off")
+		}),
+	)
+
+	return res, err
+}
+
+// isRateLimitErr returns true if the Ethereum node refused a request because
+// of its rate limit.
+func isRateLimitErr(err error) bool {
+	msg := strings.ToLower(err.Error())
+
+	for _, s := range []string{
+		"429",
+		"too many requests",
+		"rate limit",
+		"rate exceeded",
+		"limit exceeded",
+		"exceeded the quota",
+	} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+
+	return false
+}
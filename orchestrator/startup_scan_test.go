@@ -0,0 +1,34 @@
+package orchestrator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartupScanChunks(t *testing.T) {
+	p := &gravityOrchestrator{ethBlocksPerLoop: 100}
+
+	// defaults to the steady-state chunk size
+	assert.Equal(t, []startupScanChunk{
+		{start: 150, end: 250},
+		{start: 50, end: 150},
+	}, p.startupScanChunks(250, 2))
+
+	p.SetStartupScan(4, 1000)
+	assert.Equal(t, []startupScanChunk{
+		{start: 1500, end: 2500},
+		{start: 500, end: 1500},
+		{start: 0, end: 500},
+	}, p.startupScanChunks(2500, 4))
+
+	assert.Empty(t, p.startupScanChunks(0, 4))
+}
+
+func TestIsRateLimitErr(t *testing.T) {
+	assert.True(t, isRateLimitErr(errors.New("429 Too Many Requests: {\"code\":-32005}")))
+	assert.True(t, isRateLimitErr(errors.New("daily request count exceeded, request rate limited")))
+	assert.True(t, isRateLimitErr(errors.New("project ID request rate exceeded")))
+	assert.False(t, isRateLimitErr(errors.New("unknown block")))
+}
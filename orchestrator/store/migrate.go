@@ -0,0 +1,209 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// schemaVersionKey is the store key holding the schema version of the
+// persisted state.
+const schemaVersionKey = "schema_version"
+
+// ErrNewerSchema is returned when the state was persisted by a newer peggo
+// release, which this one can't safely read.
+var ErrNewerSchema = errors.New("local state was written by a newer peggo release")
+
+type (
+	// Migration transforms the persisted state from the previous schema version
+	// to Version.
+	Migration struct {
+		Version     int
+		Description string
+		Migrate     func(s *Store) error
+	}
+
+	// MigrationResult is a migration that was applied (or would be, on a dry
+	// run), along with the keys it wrote or deleted.
+	MigrationResult struct {
+		Migration
+		ChangedKeys []string
+	}
+
+	schemaVersion struct {
+		Version int `json:"version"`
+	}
+)
+
+// SchemaVersion returns the schema version of the persisted state; 0 if it was
+// never migrated.
+func (s *Store) SchemaVersion() (int, error) {
+	var v schemaVersion
+	if _, err := s.Get(schemaVersionKey, &v); err != nil {
+		return 0, err
+	}
+
+	return v.Version, nil
+}
+
+// Keys returns the keys persisted in the store, sorted.
+func (s *Store) Keys() ([]string, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list store directory: %w", err)
+	}
+
+	var keys []string
+	for _, e := range entries {
+		key := strings.TrimSuffix(e.Name(), fileExt)
+		if e.IsDir() || key == e.Name() || !validKey.MatchString(key) {
+			continue
+		}
+
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+// Migrate applies the migrations newer than the schema version of s, in
+// version order, and records the new version after each of them. With dryRun,
+// they are applied to a temporary copy of the store instead, to preview the
+// keys they would change.
+func Migrate(s *Store, migrations []Migration, dryRun bool) ([]MigrationResult, error) {
+	current, err := s.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	if n := len(sorted); n > 0 && current > sorted[n-1].Version {
+		return nil, fmt.Errorf("%w: schema version %d, latest known %d", ErrNewerSchema, current, sorted[n-1].Version)
+	}
+
+	var pending []Migration
+	for _, m := range sorted {
+		if m.Version > current {
+			pending = append(pending, m)
+		}
+	}
+
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	target := s
+	if dryRun {
+		tmpDir, err := os.MkdirTemp("", "peggo-migrate-*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create dry run directory: %w", err)
+		}
+		defer os.RemoveAll(tmpDir)
+
+		if target, err = s.copyTo(tmpDir); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]MigrationResult, 0, len(pending))
+	for _, m := range pending {
+		before, err := target.snapshot()
+		if err != nil {
+			return results, err
+		}
+
+		if err := m.Migrate(target); err != nil {
+			return results, fmt.Errorf("migration to schema version %d failed: %w", m.Version, err)
+		}
+
+		after, err := target.snapshot()
+		if err != nil {
+			return results, err
+		}
+
+		if err := target.Set(schemaVersionKey, schemaVersion{Version: m.Version}); err != nil {
+			return results, err
+		}
+
+		results = append(results, MigrationResult{Migration: m, ChangedKeys: changedKeys(before, after)})
+	}
+
+	return results, nil
+}
+
+// copyTo copies every key of s to a new store in dir.
+func (s *Store) copyTo(dir string) (*Store, error) {
+	dst, err := New(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := s.snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	for key, bz := range values {
+		if err := os.WriteFile(filepath.Join(dir, key+fileExt), bz, filePerm); err != nil {
+			return nil, fmt.Errorf("failed to copy %s: %w", key, err)
+		}
+	}
+
+	return dst, nil
+}
+
+// snapshot returns the raw value of every key, the schema version excluded.
+func (s *Store) snapshot() (map[string][]byte, error) {
+	keys, err := s.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		if key == schemaVersionKey {
+			continue
+		}
+
+		bz, err := os.ReadFile(filepath.Join(s.dir, key+fileExt))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", key, err)
+		}
+
+		values[key] = bz
+	}
+
+	return values, nil
+}
+
+func changedKeys(before, after map[string][]byte) []string {
+	var keys []string
+
+	for key, bz := range after {
+		if prev, ok := before[key]; !ok || !bytes.Equal(prev, bz) {
+			keys = append(keys, key)
+		}
+	}
+
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
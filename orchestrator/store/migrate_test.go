@@ -0,0 +1,100 @@
+package store
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate(t *testing.T) {
+	s, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, s.Set("old_key", "value"))
+	require.NoError(t, s.Set("untouched", 1))
+
+	migrations := []Migration{
+		{
+			Version:     2,
+			Description: "rename old_key",
+			Migrate: func(s *Store) error {
+				var v string
+				if _, err := s.Get("old_key", &v); err != nil {
+					return err
+				}
+
+				if err := s.Set("new_key", v); err != nil {
+					return err
+				}
+
+				return s.Delete("old_key")
+			},
+		},
+		{
+			Version:     1,
+			Description: "baseline",
+			Migrate:     func(*Store) error { return nil },
+		},
+	}
+
+	// a dry run doesn't change the store
+	results, err := Migrate(s, migrations, true)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, 1, results[0].Version)
+	assert.Empty(t, results[0].ChangedKeys)
+	assert.Equal(t, 2, results[1].Version)
+	assert.Equal(t, []string{"new_key", "old_key"}, results[1].ChangedKeys)
+
+	version, err := s.SchemaVersion()
+	require.NoError(t, err)
+	assert.Equal(t, 0, version)
+
+	keys, err := s.Keys()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old_key", "untouched"}, keys)
+
+	results, err = Migrate(s, migrations, false)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	version, err = s.SchemaVersion()
+	require.NoError(t, err)
+	assert.Equal(t, 2, version)
+
+	var v string
+	found, err := s.Get("new_key", &v)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "value", v)
+
+	// nothing left to migrate
+	results, err = Migrate(s, migrations, false)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	// the state was written by a newer release
+	_, err = Migrate(s, migrations[1:], false)
+	assert.ErrorIs(t, err, ErrNewerSchema)
+}
+
+func TestMigrateFailure(t *testing.T) {
+	s, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	migrations := []Migration{
+		{Version: 1, Migrate: func(*Store) error { return nil }},
+		{Version: 2, Migrate: func(*Store) error { return errors.New("boom") }},
+	}
+
+	results, err := Migrate(s, migrations, false)
+	assert.Error(t, err)
+	assert.Len(t, results, 1)
+
+	// the successful migrations are kept, so the next run resumes after them
+	version, err := s.SchemaVersion()
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+}
@@ -0,0 +1,12 @@
+package store
+
+// Migrations are the schema migrations of the local state, applied at startup.
+// A release changing the format of a persisted key appends a migration with
+// the next version, transforming the values written by previous releases.
+var Migrations = []Migration{
+	{
+		Version:     1,
+		Description: "record the schema version of the local state",
+		Migrate:     func(*Store) error { return nil },
+	},
+}
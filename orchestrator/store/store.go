@@ -0,0 +1,135 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+)
+
+const (
+	fileExt  = ".json"
+	dirPerm  = 0o700
+	filePerm = 0o600
+)
+
+var validKey = regexp.MustCompile(`^[a-z0-9_\-]+$`)
+
+// Store is a minimal persistent key/value store used to keep local peggo state
+// across restarts. Every key is stored in its own JSON file inside the store
+// directory, so separate processes (e.g. the orchestrator and a CLI command)
+// can safely update different keys at the same time.
+type Store struct {
+	mtx sync.Mutex
+	dir string
+}
+
+// New returns a Store rooted at dir, creating the directory if needed.
+func New(dir string) (*Store, error) {
+	if len(dir) == 0 {
+		return nil, errors.New("store directory cannot be empty")
+	}
+
+	if err := os.MkdirAll(dir, dirPerm); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+
+	return &Store{dir: dir}, nil
+}
+
+// Dir returns the directory the store persists its files to.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Get loads the value of key into v. It returns false if the key does not exist.
+func (s *Store) Get(key string, v interface{}) (bool, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return false, err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	bz, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+
+	if err := json.Unmarshal(bz, v); err != nil {
+		return false, fmt.Errorf("failed to decode %s: %w", key, err)
+	}
+
+	return true, nil
+}
+
+// Set persists v under key. The file is written atomically, so readers never
+// observe a partially written value.
+func (s *Store) Set(key string, v interface{}) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	bz, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	tmp, err := os.CreateTemp(s.dir, key+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file for %s: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(bz); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+
+	if err := tmp.Chmod(filePerm); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Delete removes key from the store. Deleting a missing key is not an error.
+func (s *Store) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Store) path(key string) (string, error) {
+	if !validKey.MatchString(key) {
+		return "", fmt.Errorf("invalid store key: %q", key)
+	}
+
+	return filepath.Join(s.dir, key+fileExt), nil
+}
@@ -0,0 +1,41 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	s, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	type value struct {
+		Foo string
+		Bar uint64
+	}
+
+	var v value
+	found, err := s.Get("missing", &v)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, s.Set("some_key", value{Foo: "foo", Bar: 42}))
+
+	found, err = s.Get("some_key", &v)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, value{Foo: "foo", Bar: 42}, v)
+
+	require.NoError(t, s.Delete("some_key"))
+	require.NoError(t, s.Delete("some_key"))
+
+	found, err = s.Get("some_key", &v)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	assert.Error(t, s.Set("../escape", v))
+	_, err = New("")
+	assert.Error(t, err)
+}
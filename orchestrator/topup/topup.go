@@ -0,0 +1,229 @@
+package topup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/umee-network/peggo/orchestrator/loops"
+	payloadv1 "github.com/umee-network/peggo/orchestrator/payload/v1"
+	"github.com/umee-network/peggo/orchestrator/schedule"
+)
+
+const maxRespTime = 15 * time.Second
+
+type (
+	// BalanceClient defines the Ethereum RPC method used to read the relayer's
+	// balance.
+	BalanceClient interface {
+		BalanceAt(ctx context.Context, account ethcmn.Address, blockNumber *big.Int) (*big.Int, error)
+	}
+
+	// MsgBroadcaster defines the Cosmos client method used to send a top-up
+	// SendToEth.
+	MsgBroadcaster interface {
+		SyncBroadcastMsg(msgs ...sdk.Msg) (*sdk.TxResponse, error)
+	}
+
+	// Config defines when a top-up is triggered and which actions are taken.
+	// At least one of WebhookURL or SendToEthAmount must be set.
+	Config struct {
+		Interval            time.Duration
+		Threshold           *big.Int // in wei
+		Cooldown            time.Duration
+		EthAddress          ethcmn.Address
+		OrchestratorAddress sdk.AccAddress
+
+		// WebhookURL receives a JSON POST with the balance details.
+		WebhookURL string
+		// Labels tag every top-up alert (see payloadv1.Labels).
+		Labels payloadv1.Labels
+
+		// SendToEthAmount is sent from the orchestrator's Cosmos account to its
+		// own Ethereum address, e.g. bridged WETH to be unwrapped.
+		SendToEthAmount    sdk.Coin
+		SendToEthBridgeFee sdk.Coin
+
+		// Schedule runs the checks instead of every Interval when set.
+		Schedule schedule.Schedule
+	}
+
+	// Alert is the low balance notice posted to WebhookURL.
+	Alert = payloadv1.TopupAlert
+
+	// Monitor checks the relayer's ETH balance and runs the configured top-up
+	// actions when it drops below the threshold.
+	Monitor struct {
+		logger        zerolog.Logger
+		client        *http.Client
+		config        Config
+		ethClient     BalanceClient
+		broadcaster   MsgBroadcaster
+		lastTriggered time.Time
+	}
+)
+
+// NewMonitor returns a top-up monitor.
+func NewMonitor(
+	logger zerolog.Logger,
+	config Config,
+	ethClient BalanceClient,
+	broadcaster MsgBroadcaster,
+) (*Monitor, error) {
+	if config.Schedule == nil {
+		if config.Interval <= 0 {
+			return nil, fmt.Errorf("invalid top-up check interval: %s", config.Interval)
+		}
+
+		config.Schedule = schedule.Every(config.Interval)
+	}
+
+	if config.Threshold == nil || config.Threshold.Sign() <= 0 {
+		return nil, errors.New("top-up threshold must be positive")
+	}
+
+	if config.WebhookURL == "" && config.SendToEthAmount.IsNil() {
+		return nil, errors.New("top-up requires a webhook or a SendToEth amount")
+	}
+
+	return &Monitor{
+		logger:      logger.With().Str("module", "topup").Logger(),
+		client:      &http.Client{Timeout: maxRespTime},
+		config:      config,
+		ethClient:   ethClient,
+		broadcaster: broadcaster,
+	}, nil
+}
+
+// Start checks the balance on its schedule until the context is done. Failures
+// are logged and retried on the next check.
+func (m *Monitor) Start(ctx context.Context) error {
+	return loops.RunSchedule(ctx, m.logger, m.config.Schedule, func() error {
+		if err := m.check(ctx); err != nil {
+			m.logger.Err(err).Msg("failed to top up ETH balance")
+		}
+
+		return nil
+	})
+}
+
+// check triggers the top-up actions if the balance is below the threshold and
+// no top-up was triggered within the cooldown, so a pending top-up isn't
+// repeated while it's being bridged.
+func (m *Monitor) check(ctx context.Context) error {
+	balance, err := m.ethClient.BalanceAt(ctx, m.config.EthAddress, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to get ETH balance")
+	}
+
+	if balance.Cmp(m.config.Threshold) >= 0 {
+		return nil
+	}
+
+	if !m.lastTriggered.IsZero() && time.Since(m.lastTriggered) < m.config.Cooldown {
+		m.logger.Debug().
+			Str("balance", balance.String()).
+			Time("last_triggered", m.lastTriggered).
+			Msg("ETH balance is low; top-up already triggered")
+		return nil
+	}
+
+	m.logger.Warn().
+		Str("balance", balance.String()).
+		Str("threshold", m.config.Threshold.String()).
+		Msg("ETH balance is low; triggering top-up")
+
+	// Set it before running the actions, so a failing action doesn't retrigger
+	// the others on every check.
+	m.lastTriggered = time.Now()
+
+	failed := 0
+
+	if m.config.WebhookURL != "" {
+		if err := m.callWebhook(ctx, balance); err != nil {
+			m.logger.Err(err).Msg("top-up webhook failed")
+			failed++
+		}
+	}
+
+	if !m.config.SendToEthAmount.IsNil() {
+		if err := m.sendToEth(); err != nil {
+			m.logger.Err(err).Msg("top-up SendToEth failed")
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d top-up action(s) failed", failed)
+	}
+
+	return nil
+}
+
+func (m *Monitor) callWebhook(ctx context.Context, balance *big.Int) error {
+	body, err := json.Marshal(Alert{
+		EthAddress: m.config.EthAddress.Hex(),
+		Balance:    balance.String(),
+		Threshold:  m.config.Threshold.String(),
+		Time:       time.Now().UTC(),
+		Labels:     m.config.Labels,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.config.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(payloadv1.HeaderSchema, payloadv1.SchemaTopupAlert)
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to call top-up webhook")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("top-up webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (m *Monitor) sendToEth() error {
+	bridgeFee := m.config.SendToEthBridgeFee
+	if bridgeFee.IsNil() {
+		bridgeFee = sdk.NewCoin(m.config.SendToEthAmount.Denom, sdk.ZeroInt())
+	}
+
+	msg := &gravitytypes.MsgSendToEth{
+		Sender:    m.config.OrchestratorAddress.String(),
+		EthDest:   m.config.EthAddress.Hex(),
+		Amount:    m.config.SendToEthAmount,
+		BridgeFee: bridgeFee,
+	}
+
+	res, err := m.broadcaster.SyncBroadcastMsg(msg)
+	if err != nil {
+		return errors.Wrap(err, "failed to broadcast top-up SendToEth")
+	}
+
+	m.logger.Info().
+		Str("tx_hash", res.TxHash).
+		Str("amount", m.config.SendToEthAmount.String()).
+		Msg("sent top-up SendToEth")
+
+	return nil
+}
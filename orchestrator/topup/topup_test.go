@@ -0,0 +1,96 @@
+package topup
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockBalanceClient struct {
+	balance *big.Int
+}
+
+func (c *mockBalanceClient) BalanceAt(context.Context, ethcmn.Address, *big.Int) (*big.Int, error) {
+	return c.balance, nil
+}
+
+type mockBroadcaster struct {
+	msgs []sdk.Msg
+}
+
+func (b *mockBroadcaster) SyncBroadcastMsg(msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+	b.msgs = append(b.msgs, msgs...)
+	return &sdk.TxResponse{}, nil
+}
+
+func TestCheck(t *testing.T) {
+	ethAddr := ethcmn.HexToAddress("0x0000000000000000000000000000000000000001")
+
+	var alerts []Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert Alert
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&alert))
+		alerts = append(alerts, alert)
+	}))
+	defer server.Close()
+
+	ethClient := &mockBalanceClient{balance: big.NewInt(1000)}
+	broadcaster := &mockBroadcaster{}
+
+	m, err := NewMonitor(
+		zerolog.Nop(),
+		Config{
+			Interval:        time.Minute,
+			Threshold:       big.NewInt(500),
+			Cooldown:        time.Hour,
+			EthAddress:      ethAddr,
+			WebhookURL:      server.URL,
+			SendToEthAmount: sdk.NewInt64Coin("gravity0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", 100),
+			Labels:          map[string]string{"region": "eu-west-1"},
+		},
+		ethClient,
+		broadcaster,
+	)
+	require.NoError(t, err)
+
+	// balance above the threshold
+	require.NoError(t, m.check(context.Background()))
+	assert.Empty(t, alerts)
+	assert.Empty(t, broadcaster.msgs)
+
+	// balance below the threshold
+	ethClient.balance = big.NewInt(100)
+	require.NoError(t, m.check(context.Background()))
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "100", alerts[0].Balance)
+	assert.EqualValues(t, map[string]string{"region": "eu-west-1"}, alerts[0].Labels)
+	require.Len(t, broadcaster.msgs, 1)
+
+	msg := broadcaster.msgs[0].(*gravitytypes.MsgSendToEth)
+	assert.Equal(t, ethAddr.Hex(), msg.EthDest)
+	assert.True(t, msg.BridgeFee.IsZero())
+
+	// still below the threshold, but within the cooldown
+	require.NoError(t, m.check(context.Background()))
+	assert.Len(t, alerts, 1)
+	assert.Len(t, broadcaster.msgs, 1)
+}
+
+func TestNewMonitorValidation(t *testing.T) {
+	_, err := NewMonitor(zerolog.Nop(), Config{Interval: time.Minute, Threshold: big.NewInt(1)}, nil, nil)
+	assert.Error(t, err)
+
+	_, err = NewMonitor(zerolog.Nop(), Config{Interval: time.Minute, WebhookURL: "http://localhost"}, nil, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,414 @@
+package totals
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+
+	"github.com/umee-network/peggo/orchestrator/store"
+)
+
+const storeKey = "metrics_totals"
+
+// Relayed tx types.
+const (
+	RelayedBatch  = "batch"
+	RelayedValset = "valset"
+)
+
+// Chains the cost of operation is paid on.
+const (
+	ChainEthereum = "ethereum"
+	ChainCosmos   = "cosmos"
+)
+
+// gasAssetDecimals are the decimals of the gas fees, counted in wei.
+const gasAssetDecimals = 18
+
+// persistInterval is how often the updated totals are persisted. Claims and
+// Cosmos fees are counted for every tx, so they're not written on each update.
+const persistInterval = 30 * time.Second
+
+var weiPerEth = new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
+
+// Values are the cumulative counters persisted in the local store. CostUSD is
+// the cost of operation per chain, valued at the prices when the fees were
+// paid.
+type Values struct {
+	Relayed    map[string]uint64  `json:"relayed"`
+	GasFees    *big.Int           `json:"gas_fees_wei"`
+	Claims     uint64             `json:"claims"`
+	CosmosFees sdk.Coins          `json:"cosmos_fees"`
+	CostUSD    map[string]sdk.Dec `json:"cost_usd"`
+}
+
+// PendingRelayed is a relayed tx that isn't mined yet, whose gas fees are
+// counted once it is.
+type PendingRelayed struct {
+	Type   string    `json:"type"`
+	SentAt time.Time `json:"sent_at"`
+}
+
+// state is what's persisted: the counters, plus the relayed txs waiting to be
+// mined.
+type state struct {
+	Values
+	Pending map[string]PendingRelayed `json:"pending_relayed,omitempty"`
+}
+
+// Valuer returns the USD value of an amount of a token, expressed in its
+// smallest unit, e.g. the oracle.
+type Valuer interface {
+	ConvertValue(amount sdk.Int, decimals uint8, symbol string) (sdk.Dec, error)
+}
+
+// Token is the symbol and decimals a fee denom is valued with.
+type Token struct {
+	Symbol   string
+	Decimals uint8
+}
+
+// Totals exports cumulative counters (relayed txs, gas fees, claims, Cosmos
+// fees and the cost of operation in USD) that are persisted in the local store
+// and restored at startup, so rate() panels and lifetime figures survive
+// restarts. The relayed txs and their gas fees are only counted once mined,
+// with the gas they actually paid. Updates are persisted by Run, every
+// persistInterval. A nil *Totals ignores every update.
+type Totals struct {
+	logger zerolog.Logger
+	store  *store.Store
+
+	mtx          sync.Mutex
+	values       Values
+	pending      map[string]PendingRelayed // tx hash => relayed tx
+	dirty        bool
+	valuer       Valuer
+	gasAsset     string
+	cosmosTokens map[string]Token
+
+	relayedDesc    *prometheus.Desc
+	gasFeesDesc    *prometheus.Desc
+	claimsDesc     *prometheus.Desc
+	cosmosFeesDesc *prometheus.Desc
+	costDesc       *prometheus.Desc
+}
+
+// New returns the totals restored from st and, unless registerer is nil,
+// registered with it.
+func New(logger zerolog.Logger, st *store.Store, registerer prometheus.Registerer) (*Totals, error) {
+	t := &Totals{
+		logger: logger.With().Str("module", "totals").Logger(),
+		store:  st,
+		relayedDesc: prometheus.NewDesc(
+			"peggo_relayer_relayed_total",
+			"Number of batches and valset updates relayed to Ethereum and successfully mined.",
+			[]string{"type"},
+			nil,
+		),
+		gasFeesDesc: prometheus.NewDesc(
+			"peggo_relayer_gas_fees_eth_total",
+			"Gas fees paid by the mined relayed txs, in the gas asset.",
+			nil,
+			nil,
+		),
+		claimsDesc: prometheus.NewDesc(
+			"peggo_orchestrator_claims_total",
+			"Number of Ethereum event claims sent to the Cosmos chain.",
+			nil,
+			nil,
+		),
+		cosmosFeesDesc: prometheus.NewDesc(
+			"peggo_orchestrator_cosmos_fees_total",
+			"Fees of the Cosmos txs sent, in the smallest unit of the denom.",
+			[]string{"denom"},
+			nil,
+		),
+		costDesc: prometheus.NewDesc(
+			"peggo_operation_cost_usd_total",
+			"Cost of operation in USD: relayed txs gas and Cosmos txs fees.",
+			[]string{"chain"},
+			nil,
+		),
+	}
+
+	var restored state
+	if _, err := st.Get(storeKey, &restored); err != nil {
+		return nil, errors.Wrap(err, "failed to restore the metrics totals")
+	}
+
+	t.values = restored.Values
+	t.pending = restored.Pending
+	if t.pending == nil {
+		t.pending = map[string]PendingRelayed{}
+	}
+
+	if t.values.Relayed == nil {
+		t.values.Relayed = map[string]uint64{}
+	}
+	if t.values.GasFees == nil {
+		t.values.GasFees = new(big.Int)
+	}
+	if t.values.CostUSD == nil {
+		t.values.CostUSD = map[string]sdk.Dec{}
+	}
+	for _, chain := range []string{ChainEthereum, ChainCosmos} {
+		if t.values.CostUSD[chain].IsNil() {
+			t.values.CostUSD[chain] = sdk.ZeroDec()
+		}
+	}
+
+	if registerer != nil {
+		if err := registerer.Register(t); err != nil {
+			return nil, errors.Wrap(err, "failed to register metric")
+		}
+	}
+
+	return t, nil
+}
+
+// Run persists the updated totals every persistInterval, and a last time once
+// ctx is done.
+func (t *Totals) Run(ctx context.Context) {
+	if t == nil {
+		return
+	}
+
+	ticker := time.NewTicker(persistInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			t.Flush()
+			return
+		case <-ticker.C:
+			t.Flush()
+		}
+	}
+}
+
+// Flush persists the totals if they were updated since the last time.
+func (t *Totals) Flush() {
+	if t == nil {
+		return
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if !t.dirty {
+		return
+	}
+
+	if err := t.store.Set(storeKey, state{Values: t.values, Pending: t.pending}); err != nil {
+		// failing to persist only loses the latest updates on restart, and it's
+		// retried on the next flush
+		t.logger.Err(err).Msg("failed to persist the metrics totals")
+		return
+	}
+
+	t.dirty = false
+}
+
+// SetValuer values the fees in USD from now on, the gas fees with the price of
+// gasAsset and the Cosmos fees with the tokens of their denoms. The fees paid
+// before, or in a denom without a token, aren't part of the cost of operation.
+func (t *Totals) SetValuer(valuer Valuer, gasAsset string, cosmosTokens map[string]Token) {
+	if t == nil {
+		return
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.valuer = valuer
+	t.gasAsset = gasAsset
+	t.cosmosTokens = cosmosTokens
+}
+
+// AddRelayed tracks a relayed tx of the given type until it's settled by
+// SettleRelayed, or dropped by DropRelayed if it never gets mined.
+func (t *Totals) AddRelayed(txType string, txHash string) {
+	if t == nil {
+		return
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.pending[txHash] = PendingRelayed{Type: txType, SentAt: time.Now().UTC()}
+	t.dirty = true
+}
+
+// PendingRelayed returns the relayed txs that aren't settled yet, by tx hash.
+func (t *Totals) PendingRelayed() map[string]PendingRelayed {
+	if t == nil {
+		return nil
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	pending := make(map[string]PendingRelayed, len(t.pending))
+	for txHash, p := range t.pending {
+		pending[txHash] = p
+	}
+
+	return pending
+}
+
+// SettleRelayed counts a mined relayed tx: its gas fees (in wei) in any case,
+// and the tx itself if it succeeded.
+func (t *Totals) SettleRelayed(txHash string, succeeded bool, gasFees *big.Int) {
+	if t == nil {
+		return
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	p, ok := t.pending[txHash]
+	if !ok {
+		return
+	}
+	delete(t.pending, txHash)
+
+	if succeeded {
+		t.values.Relayed[p.Type]++
+	}
+	if gasFees != nil {
+		t.values.GasFees.Add(t.values.GasFees, gasFees)
+		t.addCost(ChainEthereum, sdk.NewIntFromBigInt(gasFees), Token{Symbol: t.gasAsset, Decimals: gasAssetDecimals})
+	}
+
+	t.dirty = true
+}
+
+// DropRelayed stops tracking a relayed tx that won't be mined, e.g. replaced
+// or dropped from the mempool. It paid no gas.
+func (t *Totals) DropRelayed(txHash string) {
+	if t == nil {
+		return
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if _, ok := t.pending[txHash]; ok {
+		delete(t.pending, txHash)
+		t.dirty = true
+	}
+}
+
+// AddClaims counts claims sent to the Cosmos chain.
+func (t *Totals) AddClaims(n int) {
+	if t == nil || n <= 0 {
+		return
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.values.Claims += uint64(n)
+	t.dirty = true
+}
+
+// AddCosmosFees counts the fees of a Cosmos tx.
+func (t *Totals) AddCosmosFees(fees sdk.Coins) {
+	if t == nil || fees.IsZero() {
+		return
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.values.CosmosFees = t.values.CosmosFees.Add(fees...)
+	for _, fee := range fees {
+		token, ok := t.cosmosTokens[fee.Denom]
+		if !ok {
+			continue
+		}
+
+		t.addCost(ChainCosmos, fee.Amount, token)
+	}
+
+	t.dirty = true
+}
+
+// Values returns a copy of the current totals.
+func (t *Totals) Values() Values {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	// the Cosmos fees and USD costs are replaced, never mutated in place, on
+	// updates, so they're safe to share
+	values := Values{
+		Relayed:    make(map[string]uint64, len(t.values.Relayed)),
+		GasFees:    new(big.Int).Set(t.values.GasFees),
+		Claims:     t.values.Claims,
+		CosmosFees: t.values.CosmosFees,
+		CostUSD:    make(map[string]sdk.Dec, len(t.values.CostUSD)),
+	}
+	for txType, n := range t.values.Relayed {
+		values.Relayed[txType] = n
+	}
+	for chain, cost := range t.values.CostUSD {
+		values.CostUSD[chain] = cost
+	}
+
+	return values
+}
+
+// Describe implements prometheus.Collector.
+func (t *Totals) Describe(ch chan<- *prometheus.Desc) {
+	ch <- t.relayedDesc
+	ch <- t.gasFeesDesc
+	ch <- t.claimsDesc
+	ch <- t.cosmosFeesDesc
+	ch <- t.costDesc
+}
+
+// Collect implements prometheus.Collector.
+func (t *Totals) Collect(ch chan<- prometheus.Metric) {
+	values := t.Values()
+
+	for txType, n := range values.Relayed {
+		ch <- prometheus.MustNewConstMetric(t.relayedDesc, prometheus.CounterValue, float64(n), txType)
+	}
+
+	gasFees, _ := new(big.Float).Quo(new(big.Float).SetInt(values.GasFees), weiPerEth).Float64()
+	ch <- prometheus.MustNewConstMetric(t.gasFeesDesc, prometheus.CounterValue, gasFees)
+	ch <- prometheus.MustNewConstMetric(t.claimsDesc, prometheus.CounterValue, float64(values.Claims))
+
+	for _, fee := range values.CosmosFees {
+		amount, _ := new(big.Float).SetInt(fee.Amount.BigInt()).Float64()
+		ch <- prometheus.MustNewConstMetric(t.cosmosFeesDesc, prometheus.CounterValue, amount, fee.Denom)
+	}
+
+	for chain, cost := range values.CostUSD {
+		ch <- prometheus.MustNewConstMetric(t.costDesc, prometheus.CounterValue, cost.MustFloat64(), chain)
+	}
+}
+
+// addCost adds the USD value of a fee to the cost of operation on chain. It
+// must be called with the lock held. A fee that can't be valued, e.g. while
+// the oracle has no price, is only logged, so the cost is a lower bound.
+func (t *Totals) addCost(chain string, amount sdk.Int, token Token) {
+	if t.valuer == nil || token.Symbol == "" {
+		return
+	}
+
+	value, err := t.valuer.ConvertValue(amount, token.Decimals, token.Symbol)
+	if err != nil {
+		t.logger.Warn().Err(err).Str("chain", chain).Str("symbol", token.Symbol).Msg("failed to value fees in USD")
+		return
+	}
+
+	t.values.CostUSD[chain] = t.values.CostUSD[chain].Add(value)
+}
@@ -0,0 +1,163 @@
+package totals
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umee-network/peggo/orchestrator/store"
+)
+
+func TestTotals(t *testing.T) {
+	st, err := store.New(t.TempDir())
+	require.NoError(t, err)
+
+	totals, err := New(zerolog.Nop(), st, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	totals.AddRelayed(RelayedBatch, "0x01")
+	totals.AddRelayed(RelayedBatch, "0x02")
+	totals.AddRelayed(RelayedBatch, "0x03")
+	totals.AddRelayed(RelayedValset, "0x04")
+	totals.AddRelayed(RelayedValset, "0x05")
+	totals.AddClaims(3)
+
+	// only the mined txs are counted, and the failed ones only for their gas
+	totals.SettleRelayed("0x01", true, big.NewInt(1e18))
+	totals.SettleRelayed("0x02", false, big.NewInt(5e17))
+	totals.SettleRelayed("0x04", true, big.NewInt(0))
+	totals.DropRelayed("0x05")
+	assert.Equal(t, big.NewInt(15e17), totals.Values().GasFees)
+
+	// nothing is persisted until flushed
+	reloaded, err := New(zerolog.Nop(), st, nil)
+	require.NoError(t, err)
+	assert.Zero(t, reloaded.Values().Claims)
+
+	totals.Flush()
+
+	// the totals are restored by a new instance, e.g. after a restart
+	registry := prometheus.NewRegistry()
+	restored, err := New(zerolog.Nop(), st, registry)
+	require.NoError(t, err)
+
+	values := restored.Values()
+	assert.Equal(t, map[string]uint64{RelayedBatch: 1, RelayedValset: 1}, values.Relayed)
+	assert.Equal(t, big.NewInt(15e17), values.GasFees)
+	assert.Equal(t, uint64(3), values.Claims)
+	assert.Equal(t, []string{"0x03"}, keys(restored.PendingRelayed()))
+
+	restored.AddClaims(2)
+
+	expected := `
+# HELP peggo_operation_cost_usd_total Cost of operation in USD: relayed txs gas and Cosmos txs fees.
+# TYPE peggo_operation_cost_usd_total counter
+peggo_operation_cost_usd_total{chain="cosmos"} 0
+peggo_operation_cost_usd_total{chain="ethereum"} 0
+# HELP peggo_orchestrator_claims_total Number of Ethereum event claims sent to the Cosmos chain.
+# TYPE peggo_orchestrator_claims_total counter
+peggo_orchestrator_claims_total 5
+# HELP peggo_relayer_gas_fees_eth_total Gas fees paid by the mined relayed txs, in the gas asset.
+# TYPE peggo_relayer_gas_fees_eth_total counter
+peggo_relayer_gas_fees_eth_total 1.5
+# HELP peggo_relayer_relayed_total Number of batches and valset updates relayed to Ethereum and successfully mined.
+# TYPE peggo_relayer_relayed_total counter
+peggo_relayer_relayed_total{type="batch"} 1
+peggo_relayer_relayed_total{type="valset"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected)))
+}
+
+func keys(m map[string]PendingRelayed) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+// fixedPrices values the tokens at fixed USD prices.
+type fixedPrices map[string]sdk.Dec
+
+func (p fixedPrices) ConvertValue(amount sdk.Int, decimals uint8, symbol string) (sdk.Dec, error) {
+	price, ok := p[symbol]
+	if !ok {
+		return sdk.Dec{}, errors.New("no price")
+	}
+
+	return sdk.NewDecFromIntWithPrec(amount, int64(decimals)).Mul(price), nil
+}
+
+func TestTotalsCost(t *testing.T) {
+	st, err := store.New(t.TempDir())
+	require.NoError(t, err)
+
+	totals, err := New(zerolog.Nop(), st, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	// paid before the valuer is set, so not part of the cost
+	totals.AddCosmosFees(sdk.NewCoins(sdk.NewInt64Coin("uumee", 1000000)))
+
+	totals.SetValuer(
+		fixedPrices{"ETH": sdk.NewDec(1500), "UMEE": sdk.MustNewDecFromStr("0.005")},
+		"ETH",
+		map[string]Token{"uumee": {Symbol: "UMEE", Decimals: 6}, "uatom": {Symbol: "ATOM", Decimals: 6}},
+	)
+
+	totals.AddRelayed(RelayedBatch, "0x01")
+	totals.SettleRelayed("0x01", true, big.NewInt(1e16))
+	totals.AddCosmosFees(sdk.NewCoins(sdk.NewInt64Coin("uumee", 2000000), sdk.NewInt64Coin("ibc/27394FB", 10)))
+	// ATOM has no price
+	totals.AddCosmosFees(sdk.NewCoins(sdk.NewInt64Coin("uatom", 5000)))
+	totals.Flush()
+
+	registry := prometheus.NewRegistry()
+	restored, err := New(zerolog.Nop(), st, registry)
+	require.NoError(t, err)
+
+	values := restored.Values()
+	assert.Equal(t, "10ibc/27394FB,5000uatom,3000000uumee", values.CosmosFees.String())
+	assert.Equal(t, sdk.NewDec(15).String(), values.CostUSD[ChainEthereum].String())
+	assert.Equal(t, sdk.MustNewDecFromStr("0.01").String(), values.CostUSD[ChainCosmos].String())
+
+	expected := `
+# HELP peggo_operation_cost_usd_total Cost of operation in USD: relayed txs gas and Cosmos txs fees.
+# TYPE peggo_operation_cost_usd_total counter
+peggo_operation_cost_usd_total{chain="cosmos"} 0.01
+peggo_operation_cost_usd_total{chain="ethereum"} 15
+# HELP peggo_orchestrator_cosmos_fees_total Fees of the Cosmos txs sent, in the smallest unit of the denom.
+# TYPE peggo_orchestrator_cosmos_fees_total counter
+peggo_orchestrator_cosmos_fees_total{denom="ibc/27394FB"} 10
+peggo_orchestrator_cosmos_fees_total{denom="uatom"} 5000
+peggo_orchestrator_cosmos_fees_total{denom="uumee"} 3e+06
+`
+	assert.NoError(t, testutil.GatherAndCompare(
+		registry,
+		strings.NewReader(expected),
+		"peggo_operation_cost_usd_total",
+		"peggo_orchestrator_cosmos_fees_total",
+	))
+}
+
+func TestTotalsNil(t *testing.T) {
+	var totals *Totals
+
+	assert.NotPanics(t, func() {
+		totals.AddRelayed(RelayedBatch, "0x01")
+		totals.SettleRelayed("0x01", true, big.NewInt(1))
+		totals.DropRelayed("0x01")
+		totals.AddClaims(1)
+		totals.AddCosmosFees(sdk.NewCoins(sdk.NewInt64Coin("uumee", 1)))
+		totals.SetValuer(nil, "ETH", nil)
+		totals.Flush()
+	})
+}
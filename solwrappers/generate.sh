@@ -0,0 +1,33 @@
+#!/usr/bin/env bash
+#
+# Generates the Go bindings of a Gravity Bridge contract from its sources at the
+# given git ref. It's run by `go generate ./solwrappers/...` (or `make gen`) from
+# solwrappers/versions.
+#
+# Usage: generate.sh <git-ref> <contract.sol> <output-dir>
+#
+# Set GRAVITY_BRIDGE_DIR to use a local checkout instead of cloning
+# GRAVITY_BRIDGE_REPO. solc must be installed.
+
+set -euo pipefail
+
+ref=$1
+contract=$2
+out=$(cd "$3" && pwd)
+repo=${GRAVITY_BRIDGE_REPO:-https://github.com/umee-network/Gravity-Bridge.git}
+
+src=${GRAVITY_BRIDGE_DIR:-}
+if [ -z "$src" ]; then
+  src=$(mktemp -d)
+  trap 'rm -rf "$src"' EXIT
+
+  git clone --quiet --depth 1 --branch "$ref" "$repo" "$src"
+fi
+
+cd "$src/solidity/contracts"
+
+echo "--> Generating $contract bindings ($ref) in $out"
+go run github.com/ethereum/go-ethereum/cmd/abigen@v1.10.26 \
+  --pkg wrappers \
+  --sol "$contract" \
+  --out "$out/wrapper.go"
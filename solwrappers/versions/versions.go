@@ -0,0 +1,252 @@
+// Package versions registers the Gravity.sol versions peggo has bindings for,
+// so the version of a deployment can be selected, or detected, at runtime.
+//
+// Each version lives in its own bindings package under solwrappers, generated
+// from the Gravity Bridge sources at a given git ref; see generate.sh. Only the
+// ABI of a version is used at runtime, to encode the submitBatch and
+// updateValset calls: the contract reads and the events are still decoded with
+// the v1 bindings, so a version is only registered if its events and view
+// functions are the same as v1's.
+//
+// Only v1 is registered: it's the only Gravity.sol release deployed for Umee,
+// and the bindings of any other release, whose events or view functions differ
+// from v1's, couldn't be registered without per-version reads. A new version
+// is added with its go:generate directive and a register call in init.
+package versions
+
+// Gravity.sol v1
+//go:generate ../generate.sh module/v1.5.3-umee-4 Gravity.sol ../Gravity.sol
+
+// ERC20 bindings, shared by all versions
+//go:generate ../generate.sh module/v1.5.3-umee-4 CosmosToken.sol ../CosmosToken.sol
+//go:generate ../generate.sh module/v1.5.3-umee-4 ReentrantERC20.sol ../ReentrantERC20.sol
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	gravityv1 "github.com/umee-network/peggo/solwrappers/Gravity.sol"
+)
+
+// Auto selects the version by detecting it from the deployed contract code.
+const Auto = "auto"
+
+// Version is a Gravity.sol version with bindings in this repository.
+type Version struct {
+	Name string
+	ABI  abi.ABI
+	Bin  string
+
+	// RewardRecipientArg is the name of the submitBatch argument the batch
+	// fees are paid to, or empty if they're paid to the sender of the tx.
+	RewardRecipientArg string
+}
+
+// versions holds the known versions, oldest first.
+var versions []Version
+
+func init() {
+	register("v1", gravityv1.GravityMetaData, "")
+}
+
+func register(name string, metadata *bind.MetaData, rewardRecipientArg string) {
+	v, err := New(name, metadata, rewardRecipientArg)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	if len(versions) > 0 {
+		if err := compatible(versions[0].ABI, v.ABI); err != nil {
+			panic(fmt.Sprintf("Gravity.sol %s can't be read with the %s bindings: %s", name, versions[0].Name, err))
+		}
+	}
+
+	versions = append(versions, v)
+}
+
+// New returns the version with the given bindings, without registering it. If
+// rewardRecipientArg is set, it must name an address argument of submitBatch.
+func New(name string, metadata *bind.MetaData, rewardRecipientArg string) (Version, error) {
+	parsed, err := metadata.GetAbi()
+	if err != nil {
+		return Version{}, fmt.Errorf("invalid Gravity.sol %s ABI: %w", name, err)
+	}
+
+	v := Version{
+		Name:               name,
+		ABI:                *parsed,
+		Bin:                metadata.Bin,
+		RewardRecipientArg: rewardRecipientArg,
+	}
+
+	if rewardRecipientArg != "" {
+		if _, err := v.rewardRecipientIndex(); err != nil {
+			return Version{}, err
+		}
+	}
+
+	return v, nil
+}
+
+// compatible returns an error if the events or the view functions of base are
+// missing or different in other.
+func compatible(base, other abi.ABI) error {
+	for name, event := range base.Events {
+		if e, ok := other.Events[name]; !ok || e.ID != event.ID {
+			return fmt.Errorf("event %s differs", name)
+		}
+	}
+
+	for name, method := range base.Methods {
+		if !method.IsConstant() {
+			continue
+		}
+
+		if m, ok := other.Methods[name]; !ok || !bytes.Equal(m.ID, method.ID) || !sameTypes(m.Outputs, method.Outputs) {
+			return fmt.Errorf("function %s differs", name)
+		}
+	}
+
+	return nil
+}
+
+func sameTypes(a, b abi.Arguments) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].Type.String() != b[i].Type.String() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Names returns the names of the known versions, oldest first.
+func Names() []string {
+	names := make([]string, 0, len(versions))
+	for _, v := range versions {
+		names = append(names, v.Name)
+	}
+
+	return names
+}
+
+// Latest returns the most recent known version.
+func Latest() Version {
+	return versions[len(versions)-1]
+}
+
+// Get returns the version with the given name.
+func Get(name string) (Version, error) {
+	for _, v := range versions {
+		if v.Name == name {
+			return v, nil
+		}
+	}
+
+	return Version{}, fmt.Errorf(
+		"unknown Gravity.sol version %q; expected one of %s",
+		name, strings.Join(Names(), ", "),
+	)
+}
+
+// Detect returns the most recent version whose functions are all implemented
+// by the deployed contract code.
+func Detect(code []byte) (Version, error) {
+	if len(code) == 0 {
+		return Version{}, fmt.Errorf("no contract code deployed")
+	}
+
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].matches(code) {
+			return versions[i], nil
+		}
+	}
+
+	return Version{}, fmt.Errorf(
+		"the deployed contract doesn't match any known Gravity.sol version (%s)",
+		strings.Join(Names(), ", "),
+	)
+}
+
+// DirectedRewards returns whether the batch fees can be paid to another address
+// than the relayer's, i.e. the sender of the submitBatch tx.
+func (v Version) DirectedRewards() bool {
+	return v.RewardRecipientArg != ""
+}
+
+// RewardRecipient returns the address an encoded submitBatch call pays the
+// batch fees to, on a version with directed rewards.
+func (v Version) RewardRecipient(txData []byte) (common.Address, error) {
+	i, err := v.rewardRecipientIndex()
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	method := v.ABI.Methods["submitBatch"]
+	if len(txData) < 4 || !bytes.Equal(txData[:4], method.ID) {
+		return common.Address{}, fmt.Errorf("not a submitBatch call")
+	}
+
+	args, err := method.Inputs.Unpack(txData[4:])
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to decode the submitBatch call: %w", err)
+	}
+
+	return args[i].(common.Address), nil
+}
+
+// rewardRecipientIndex returns the position of the reward recipient among the
+// submitBatch arguments.
+func (v Version) rewardRecipientIndex() (int, error) {
+	if v.RewardRecipientArg == "" {
+		return 0, fmt.Errorf("no reward recipient in Gravity.sol %s, which pays the batch fees to the tx sender", v.Name)
+	}
+
+	for i, arg := range v.ABI.Methods["submitBatch"].Inputs {
+		if arg.Name == v.RewardRecipientArg {
+			if arg.Type.T != abi.AddressTy {
+				break
+			}
+
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf(
+		"the submitBatch of Gravity.sol %s has no %s address argument to pay the batch fees to",
+		v.Name, v.RewardRecipientArg,
+	)
+}
+
+// matches returns whether the selectors of all the version functions appear in
+// the code, as the function dispatcher pushes each of them.
+func (v Version) matches(code []byte) bool {
+	for _, method := range v.ABI.Methods {
+		if !bytes.Contains(code, pushSelector(method.ID)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// pushSelector returns the instruction pushing a function selector: PUSH4, or
+// a shorter PUSH when the selector has leading zero bytes.
+func pushSelector(selector []byte) []byte {
+	trimmed := bytes.TrimLeft(selector, "\x00")
+	if len(trimmed) == 0 {
+		trimmed = []byte{0}
+	}
+
+	const push1 = 0x60
+	return append([]byte{push1 + byte(len(trimmed)-1)}, trimmed...)
+}
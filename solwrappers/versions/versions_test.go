@@ -0,0 +1,126 @@
+package versions
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	gravityv1 "github.com/umee-network/peggo/solwrappers/Gravity.sol"
+)
+
+func TestGet(t *testing.T) {
+	v, err := Get("v1")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", v.Name)
+	assert.Contains(t, v.ABI.Methods, "submitBatch")
+	assert.False(t, v.DirectedRewards())
+
+	_, err = Get("v0")
+	assert.Error(t, err)
+}
+
+func TestDetect(t *testing.T) {
+	// the creation code embeds the runtime code, hence the dispatcher
+	v, err := Detect(common.FromHex(Latest().Bin))
+	require.NoError(t, err)
+	assert.Equal(t, Latest().Name, v.Name)
+
+	_, err = Detect(nil)
+	assert.Error(t, err)
+
+	_, err = Detect(common.FromHex("0x6080604052"))
+	assert.Error(t, err)
+}
+
+func TestRegister(t *testing.T) {
+	registered := versions
+	defer func() { versions = registered }()
+
+	v1 := Latest()
+
+	// v2 adds a function to v1, so its dispatcher pushes one more selector
+	v2ABI := `[{"type":"function","name":"submitBatchV2","stateMutability":"nonpayable","inputs":[],"outputs":[]},` +
+		strings.TrimPrefix(strings.TrimSpace(gravityv1.GravityMetaData.ABI), "[")
+	parsed, err := abi.JSON(strings.NewReader(v2ABI))
+	require.NoError(t, err)
+	v2Bin := v1.Bin + common.Bytes2Hex(pushSelector(parsed.Methods["submitBatchV2"].ID))
+
+	register("v2", &bind.MetaData{ABI: v2ABI, Bin: v2Bin}, "")
+	assert.Equal(t, []string{"v1", "v2"}, Names())
+	assert.Equal(t, "v2", Latest().Name)
+
+	v, err := Get("v2")
+	require.NoError(t, err)
+	assert.Contains(t, v.ABI.Methods, "submitBatchV2")
+
+	v, err = Detect(common.FromHex(v1.Bin))
+	require.NoError(t, err)
+	assert.Equal(t, "v1", v.Name)
+
+	v, err = Detect(common.FromHex(v2Bin))
+	require.NoError(t, err)
+	assert.Equal(t, "v2", v.Name)
+
+	// a version whose view functions differ from v1's can't be read
+	assert.Panics(t, func() {
+		register("v3", &bind.MetaData{ABI: `[
+			{"type":"function","name":"state_lastValsetNonce","stateMutability":"view","inputs":[],
+			 "outputs":[{"name":"","type":"uint256"}]}
+		]`}, "")
+	})
+}
+
+func TestRewardRecipient(t *testing.T) {
+	metadata := &bind.MetaData{ABI: `[
+		{"type":"function","name":"submitBatch","stateMutability":"nonpayable",
+		 "inputs":[{"name":"_batchNonce","type":"uint256"},{"name":"_rewardRecipient","type":"address"}],"outputs":[]}
+	]`}
+
+	v, err := New("v2", metadata, "_rewardRecipient")
+	require.NoError(t, err)
+	assert.True(t, v.DirectedRewards())
+
+	recipient := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	txData, err := v.ABI.Pack("submitBatch", big.NewInt(1), recipient)
+	require.NoError(t, err)
+
+	got, err := v.RewardRecipient(txData)
+	require.NoError(t, err)
+	assert.Equal(t, recipient, got)
+
+	_, err = v.RewardRecipient([]byte{1, 2, 3, 4})
+	assert.Error(t, err)
+
+	// the argument must be an address of submitBatch
+	_, err = New("v2", metadata, "_batchNonce")
+	assert.Error(t, err)
+	_, err = New("v2", metadata, "_relayer")
+	assert.Error(t, err)
+
+	_, err = Latest().RewardRecipient(txData)
+	assert.Error(t, err)
+}
+
+func TestCompatible(t *testing.T) {
+	base := Latest().ABI
+	assert.NoError(t, compatible(base, base))
+
+	other, err := abi.JSON(strings.NewReader(`[
+		{"type":"function","name":"state_lastValsetNonce","stateMutability":"view","inputs":[],
+		 "outputs":[{"name":"","type":"uint256"}]}
+	]`))
+	require.NoError(t, err)
+	assert.Error(t, compatible(base, other))
+}
+
+func TestPushSelector(t *testing.T) {
+	assert.Equal(t, []byte{0x63, 0x12, 0x34, 0x56, 0x78}, pushSelector([]byte{0x12, 0x34, 0x56, 0x78}))
+	assert.Equal(t, []byte{0x61, 0x56, 0x78}, pushSelector([]byte{0x00, 0x00, 0x56, 0x78}))
+	assert.Equal(t, []byte{0x60, 0x00}, pushSelector([]byte{0x00, 0x00, 0x00, 0x00}))
+}